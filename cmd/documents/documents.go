@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -16,18 +17,66 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/trussle/fsys"
+	"github.com/trussle/snowy/pkg/admin"
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/authors"
+	"github.com/trussle/snowy/pkg/checkpoints"
 	"github.com/trussle/snowy/pkg/contents"
+	"github.com/trussle/snowy/pkg/events"
 	"github.com/trussle/snowy/pkg/journals"
 	"github.com/trussle/snowy/pkg/ledgers"
+	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/snowy/pkg/openapi"
+	"github.com/trussle/snowy/pkg/outbox"
+	"github.com/trussle/snowy/pkg/ratelimit"
 	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/rpc"
 	"github.com/trussle/snowy/pkg/status"
 	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/snowy/pkg/trace"
 	"github.com/trussle/snowy/pkg/ui"
+	"github.com/trussle/snowy/pkg/webhooks"
 )
 
 const (
 	defaultFilesystem  = "remote"
 	defaultPersistence = "real"
+	defaultBlobStore   = "filesystem"
+	defaultBlobRoot    = "blobs"
+
+	defaultCompressionEncoding     = "none"
+	defaultCompressionContentTypes = "text/*,application/json,application/xml"
+
+	defaultEncryptionKeyFile = ""
+
+	defaultCheckpointKeyFile  = ""
+	defaultCheckpointInterval = time.Hour
+
+	defaultQuotaEnforce  = false
+	defaultQuotaCacheTTL = time.Minute
+
+	defaultRateLimitLedgers  = ""
+	defaultRateLimitContents = ""
+	defaultRateLimitJournals = ""
+
+	defaultEventsEnabled = true
+	defaultEventsHistory = 1024
+
+	defaultWebhooksEnabled     = false
+	defaultWebhooksInterval    = 5 * time.Second
+	defaultWebhooksMaxAttempts = 8
+	defaultWebhooksTimeout     = 10 * time.Second
+	defaultWebhooksBackoff     = 30 * time.Second
+
+	defaultOutboxSinks     = ""
+	defaultOutboxInterval  = time.Second
+	defaultOutboxBatchSize = 500
+
+	defaultOpenAPIPath = "/openapi.json"
+
+	defaultTraceExporter = "none"
+	defaultTraceFile     = ""
 
 	defaultAWSEncryption           = false
 	defaultAWSKMSKey               = ""
@@ -45,19 +94,68 @@ const (
 	defaultDBName     = "postgres"
 	defaultDBSSLMode  = "disable"
 
+	defaultAuthProviders   = ""
+	defaultAuthAPIKeyFile  = ""
+	defaultAuthHMACFile    = ""
+	defaultAuthJWKSFile    = ""
+	defaultAuthJWTIssuer   = ""
+	defaultAuthJWTAudience = ""
+	defaultAuthStatusOpen  = true
+	defaultAuthAdmins      = ""
+	defaultAuthAdminGroups = ""
+	defaultTenantRequired  = false
+
 	defaultMetricsRegistration = true
+	defaultMetricsInterval     = time.Minute
 	defaultUILocal             = false
 )
 
 func runDocuments(args []string) error {
+	// Sub commands of the documents command
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "verify":
+			return runVerify(args[1:])
+		case "export":
+			return runExport(args[1:])
+		case "import":
+			return runImport(args[1:])
+		case "ingest":
+			return runIngest(args[1:])
+		}
+	}
+
 	// flags for the documents command
 	var (
 		flags = flagset.NewFlagSet("documents", flag.ExitOnError)
 
 		debug                   = flags.Bool("debug", false, "debug logging")
 		apiAddr                 = flags.String("api", defaultAPIAddr, "listen address for query API")
+		grpcAPIAddr             = flags.String("grpc.api", defaultGRPCAPIAddr, "listen address for gRPC API")
 		filesystem              = flags.String("filesystem", defaultFilesystem, "type of filesystem backing (local, remote, virtual, nop)")
 		datastore               = flags.String("persistence", defaultPersistence, "type of persistence backing (real, virtual, nop)")
+		blobStore               = flags.String("blobstore", defaultBlobStore, "type of blob store backing (filesystem, local)")
+		blobRoot                = flags.String("blobstore.root", defaultBlobRoot, "root directory for the local blob store")
+		compressionEncoding     = flags.String("compression.encoding", defaultCompressionEncoding, "encoding used to compress content at rest (none, gzip, zstd)")
+		compressionContentTypes = flags.String("compression.types", defaultCompressionContentTypes, "comma separated list of content types to compress at rest")
+		encryptionKeyFile       = flags.String("encryption.keyfile", defaultEncryptionKeyFile, "key file holding the hex encoded master key used to encrypt content at rest (empty disables encryption)")
+		checkpointKeyFile       = flags.String("checkpoint.keyfile", defaultCheckpointKeyFile, "key file holding the hex encoded Ed25519 seed used to sign checkpoints (empty disables checkpoints)")
+		checkpointInterval      = flags.Duration("checkpoint.interval", defaultCheckpointInterval, "interval between checkpoints of the ledgers")
+		quotaEnforce            = flags.Bool("quota.enforce", defaultQuotaEnforce, "enforce the storage quotas of authors and tenants on writes")
+		quotaCacheTTL           = flags.Duration("quota.cache-ttl", defaultQuotaCacheTTL, "duration the usage of a quota is cached for, before it's computed from the ledgers again (the cache is per replica, so with N replicas a quota can be overrun by up to N times until the caches expire)")
+		rateLimitLedgers        = flags.String("ratelimit.ledgers", defaultRateLimitLedgers, "rate limit of the ledgers API per client as requests per second with an optional burst (rate:burst) (empty disables the limit)")
+		rateLimitContents       = flags.String("ratelimit.contents", defaultRateLimitContents, "rate limit of the contents API per client as requests per second with an optional burst (rate:burst) (empty disables the limit)")
+		rateLimitJournals       = flags.String("ratelimit.journals", defaultRateLimitJournals, "rate limit of the journals API per client as requests per second with an optional burst (rate:burst) (empty disables the limit)")
+		eventsEnabled           = flags.Bool("events.enabled", defaultEventsEnabled, "serve a stream of the ledger events, shared between replicas through the datastore when it's real")
+		eventsHistory           = flags.Int("events.history", defaultEventsHistory, "number of recent ledger events kept, so that streams can resume from them")
+		webhooksEnabled         = flags.Bool("webhooks.enabled", defaultWebhooksEnabled, "deliver the changes of the ledgers to the webhooks")
+		webhooksInterval        = flags.Duration("webhooks.interval", defaultWebhooksInterval, "interval between looking for pending webhook deliveries")
+		webhooksMaxAttempts     = flags.Int("webhooks.max-attempts", defaultWebhooksMaxAttempts, "number of attempts at a webhook delivery, before it's dead")
+		webhooksTimeout         = flags.Duration("webhooks.timeout", defaultWebhooksTimeout, "timeout of a single attempt at a webhook delivery")
+		webhooksBackoff         = flags.Duration("webhooks.backoff", defaultWebhooksBackoff, "delay before retrying a failed webhook delivery, which doubles with every attempt")
+		outboxSinks             = flags.String("outbox.sinks", defaultOutboxSinks, "comma separated list of sinks the changes of the ledgers are relayed to (stdout, file:<path>, http(s)://<url>)")
+		outboxInterval          = flags.Duration("outbox.interval", defaultOutboxInterval, "interval between relaying the changes of the ledgers to the sinks")
+		outboxBatchSize         = flags.Int("outbox.batch-size", defaultOutboxBatchSize, "number of changes of the ledgers relayed to a sink at a time")
 		awsEncryption           = flags.Bool("aws.encryption", defaultAWSEncryption, "AWS configuration encryption")
 		awsKMSKey               = flags.String("aws.kmskey", defaultAWSKMSKey, "AWS configuration KMS Key")
 		awsServerSideEncryption = flags.String("aws.sse", defaultAWSServerSideEncryption, "AWS configuration ServerSideEncryption")
@@ -72,11 +170,24 @@ func runDocuments(args []string) error {
 		dbPassword              = flags.String("db.password", defaultDBPassword, "Password for connecting to the datastore")
 		dbName                  = flags.String("db.name", defaultDBName, "Name of the database with in the datastore")
 		dbSSLMode               = flags.String("db.sslmode", defaultDBSSLMode, "SSL mode for connecting to the datastore")
+		authProviders           = flags.String("auth.providers", defaultAuthProviders, "comma separated list of auth providers to authenticate requests with, in order (apikey, hmac, jwt) (empty disables auth)")
+		authAPIKeyFile          = flags.String("auth.apikey.file", defaultAuthAPIKeyFile, "file of principals and their API keys for the apikey provider")
+		authHMACFile            = flags.String("auth.hmac.file", defaultAuthHMACFile, "file of principals and their hex encoded secrets for the hmac provider")
+		authJWKSFile            = flags.String("auth.jwt.jwks", defaultAuthJWKSFile, "JWKS file of the keys to validate tokens with for the jwt provider")
+		authJWTIssuer           = flags.String("auth.jwt.issuer", defaultAuthJWTIssuer, "expected issuer of tokens for the jwt provider (empty skips the check)")
+		authJWTAudience         = flags.String("auth.jwt.audience", defaultAuthJWTAudience, "expected audience of tokens for the jwt provider (empty skips the check)")
+		authStatusOpen          = flags.Bool("auth.status.open", defaultAuthStatusOpen, "allow requests to the health and readiness checks and the OpenAPI document without authentication")
+		authAdmins              = flags.String("auth.admins", defaultAuthAdmins, "comma separated list of principals allowed to use the admin API (erase, quotas, export and import) and to read the statistics")
+		authAdminGroups         = flags.String("auth.admin-groups", defaultAuthAdminGroups, "comma separated list of groups whose principals are allowed to use the admin API and to read the statistics")
+		tenantRequired          = flags.Bool("tenant.required", defaultTenantRequired, "reject requests that don't name a tenant, either by the X-Snowy-Tenant header or the tenant of the principal")
+		traceExporter           = flags.String("trace.exporter", defaultTraceExporter, "exporter of the tracing spans of requests (none, stdout, file)")
+		traceFile               = flags.String("trace.file", defaultTraceFile, "file the tracing spans are written to as lines of JSON, for the file exporter")
 		metricsRegistration     = flags.Bool("metrics.registration", defaultMetricsRegistration, "Registration of metrics on launch")
+		metricsInterval         = flags.Duration("metrics.interval", defaultMetricsInterval, "interval between refreshing the gauges of the total ledgers, resources and bytes")
 		uiLocal                 = flags.Bool("ui.local", defaultUILocal, "Ignores embedded files and goes straight to the filesystem")
 	)
 
-	flags.Usage = usageFor(flags, "documents [flags]")
+	flags.Usage = usageFor(flags, "documents [verify] [flags]")
 	if err := flags.Parse(args); err != nil {
 		return nil
 	}
@@ -93,6 +204,26 @@ func runDocuments(args []string) error {
 		logger = level.NewFilter(logger, logLevel)
 	}
 
+	// Tracing setup.
+	exporterConfig, err := trace.BuildExporterConfig(
+		trace.WithExporterName(*traceExporter),
+		trace.WithExporterPath(*traceFile),
+	)
+	if err != nil {
+		return errors.Wrap(err, "trace exporter config")
+	}
+
+	exporter, err := trace.NewExporter(exporterConfig)
+	if err != nil {
+		return errors.Wrap(err, "trace exporter")
+	}
+	defer exporter.Close()
+
+	tracer := trace.NewTracer(
+		trace.WithExporter(exporter),
+		trace.WithLogger(log.With(logger, "component", "trace")),
+	)
+
 	// Instrumentation
 	connectedClients := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "snowy_documents",
@@ -115,6 +246,63 @@ func runDocuments(args []string) error {
 		Help:      "API request duration in seconds.",
 		Buckets:   prometheus.DefBuckets,
 	}, []string{"method", "path", "status_code"})
+	quotaUsage := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "snowy_documents",
+		Name:      "quota_usage",
+		Help:      "Usage of the quotas by tenant, author and usage (bytes, resources, revisions_today).",
+	}, []string{"tenant", "author", "usage"})
+	rateLimitRequests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "snowy_documents",
+		Name:      "ratelimit_requests_total",
+		Help:      "The total number of rate limited requests by route and result (allowed, limited).",
+	}, []string{"route", "result"})
+	storeDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "snowy_documents",
+		Name:      "store_operation_duration_seconds",
+		Help:      "Store operation duration in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+	storeErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "snowy_documents",
+		Name:      "store_operation_errors_total",
+		Help:      "The total number of store operation errors.",
+	}, []string{"operation"})
+	filesystemDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "snowy_documents",
+		Name:      "filesystem_operation_duration_seconds",
+		Help:      "Filesystem operation duration in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+	filesystemErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "snowy_documents",
+		Name:      "filesystem_operation_errors_total",
+		Help:      "The total number of filesystem operation errors.",
+	}, []string{"operation"})
+	filesystemBytesRead := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "snowy_documents",
+		Name:      "filesystem_bytes_read_total",
+		Help:      "The total number of bytes read from the filesystem.",
+	})
+	filesystemBytesWritten := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "snowy_documents",
+		Name:      "filesystem_bytes_written_total",
+		Help:      "The total number of bytes written to the filesystem.",
+	})
+	totalLedgers := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "snowy_documents",
+		Name:      "ledgers",
+		Help:      "Number of ledgers of the default tenant.",
+	})
+	totalResources := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "snowy_documents",
+		Name:      "resources",
+		Help:      "Number of resources of the default tenant.",
+	})
+	totalBytes := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "snowy_documents",
+		Name:      "resource_bytes",
+		Help:      "Bytes of the resources of the default tenant.",
+	})
 
 	if *metricsRegistration {
 		prometheus.MustRegister(
@@ -122,6 +310,17 @@ func runDocuments(args []string) error {
 			writerBytes,
 			writerRecords,
 			apiDuration,
+			quotaUsage,
+			rateLimitRequests,
+			storeDuration,
+			storeErrors,
+			filesystemDuration,
+			filesystemErrors,
+			filesystemBytesRead,
+			filesystemBytesWritten,
+			totalLedgers,
+			totalResources,
+			totalBytes,
 		)
 	}
 
@@ -135,6 +334,16 @@ func runDocuments(args []string) error {
 	}
 	level.Debug(logger).Log("API", fmt.Sprintf("%s://%s", apiNetwork, apiAddress))
 
+	grpcAPINetwork, grpcAPIAddress, err := parseAddr(*grpcAPIAddr, defaultGRPCAPIPort)
+	if err != nil {
+		return err
+	}
+	grpcAPIListener, err := net.Listen(grpcAPINetwork, grpcAPIAddress)
+	if err != nil {
+		return err
+	}
+	level.Debug(logger).Log("gRPC API", fmt.Sprintf("%s://%s", grpcAPINetwork, grpcAPIAddress))
+
 	// Filesystem setup.
 	remoteConfig, err := fsys.BuildConfig(
 		fsys.WithEncryption(*awsEncryption),
@@ -162,6 +371,26 @@ func runDocuments(args []string) error {
 	if err != nil {
 		return errors.Wrap(err, "filesystem")
 	}
+	fsys = repository.NewInstrumentedFilesystem(fsys,
+		filesystemDuration, filesystemErrors,
+		filesystemBytesRead, filesystemBytesWritten,
+	)
+
+	// Blob store setup.
+	blobConfig, err := repository.BuildBlobConfig(
+		repository.WithBlobStore(*blobStore),
+		repository.WithBlobFilesystem(fsys),
+		repository.WithBlobRoot(*blobRoot),
+	)
+	if err != nil {
+		return errors.Wrap(err, "blob store config")
+	}
+
+	blobs, err := repository.NewBlobStore(blobConfig)
+	if err != nil {
+		return errors.Wrap(err, "blob store")
+	}
+	blobs = repository.NewTracedBlobStore(blobs, tracer)
 
 	// Persistence setup.
 	realConfig, err := store.BuildConfig(
@@ -187,16 +416,158 @@ func runDocuments(args []string) error {
 	if err != nil {
 		return errors.Wrap(err, "store")
 	}
+	// The store is traced outside of the instrumentation, so that the store
+	// can still be bound to the context of a request.
+	dataStore = store.NewTracedStore(store.NewInstrumentedStore(dataStore, storeDuration, storeErrors), tracer)
+
+	// Outbox setup.
+	outboxSinkList, err := outbox.ParseSinks(*outboxSinks)
+	if err != nil {
+		return errors.Wrap(err, "outbox sinks")
+	}
 
 	// Repository setup
-	repository := repository.NewRealRepository(fsys, dataStore, log.With(logger, "component", "repository"))
+	compression, err := repository.BuildCompression(
+		repository.WithCompressionEncoding(*compressionEncoding),
+		repository.WithCompressionContentTypes(strings.Split(*compressionContentTypes, ",")),
+	)
+	if err != nil {
+		return errors.Wrap(err, "compression config")
+	}
+
+	repositoryOptions := []repository.Option{
+		repository.WithCompression(compression),
+	}
+	if *encryptionKeyFile != "" {
+		keys, err := repository.NewLocalKeyProvider(*encryptionKeyFile)
+		if err != nil {
+			return errors.Wrap(err, "encryption key provider")
+		}
+		repositoryOptions = append(repositoryOptions, repository.WithEncryption(keys))
+	}
+	if *checkpointKeyFile != "" {
+		signer, err := repository.NewLocalSigner(*checkpointKeyFile)
+		if err != nil {
+			return errors.Wrap(err, "checkpoint signer")
+		}
+		repositoryOptions = append(repositoryOptions, repository.WithCheckpoints(signer))
+	}
+	if *quotaEnforce {
+		repositoryOptions = append(repositoryOptions, repository.WithQuotas(*quotaCacheTTL, quotaUsage))
+	}
+	if *webhooksEnabled {
+		repositoryOptions = append(repositoryOptions, repository.WithWebhooks())
+	}
+
+	// Events setup.
+	var (
+		eventBus    events.Bus
+		ledgersOpts []ledgers.APIOption
+	)
+	if *eventsEnabled {
+		if *datastore == "real" {
+			eventBus, err = events.NewPostgresBus(realConfig, dataStore, *eventsHistory, log.With(logger, "component", "events"))
+			if err != nil {
+				return errors.Wrap(err, "events")
+			}
+		} else {
+			eventBus = events.NewLocalBus(*eventsHistory)
+		}
+		repositoryOptions = append(repositoryOptions, repository.WithEvents(eventBus))
+		ledgersOpts = append(ledgersOpts, ledgers.WithEvents(eventBus))
+	}
+
+	// The statistics that are periodically reported are of the default tenant.
+	statisticsQuery := repository.BuildEmptyQuery()
+
+	repository := repository.NewTracedRepository(
+		repository.NewRealRepository(blobs, dataStore, log.With(logger, "component", "repository"), repositoryOptions...),
+		tracer,
+	)
 	defer func() {
 		if err := repository.Close(); err != nil {
 			level.Error(logger).Log("err", err.Error())
 		}
 	}()
 
+	// Auth setup.
+	authConfig, err := auth.Build(
+		auth.WithProviders(strings.Split(*authProviders, ",")),
+		auth.WithAPIKeyFile(*authAPIKeyFile),
+		auth.WithHMACFile(*authHMACFile),
+		auth.WithJWT(*authJWKSFile, *authJWTIssuer, *authJWTAudience),
+	)
+	if err != nil {
+		return errors.Wrap(err, "auth config")
+	}
+
+	authProviderList, err := auth.New(authConfig)
+	if err != nil {
+		return errors.Wrap(err, "auth")
+	}
+
+	admins := auth.Admins{
+		Principals: strings.Split(*authAdmins, ","),
+		Groups:     strings.Split(*authAdminGroups, ","),
+	}
+	ledgersOpts = append(ledgersOpts, ledgers.WithAdmins(admins))
+
+	// Rate limiting setup.
+	// The gRPC services are limited the same as the REST APIs they mirror,
+	// matched by the full method of the call rather than the path.
+	var rateLimitOpts, grpcRateLimitOpts []ratelimit.MiddlewareOption
+	for _, route := range []struct {
+		name, limit, method string
+	}{
+		{"ledgers", *rateLimitLedgers, rpc.MethodPrefixLedgers},
+		{"contents", *rateLimitContents, rpc.MethodPrefixContents},
+		{"journals", *rateLimitJournals, ""},
+	} {
+		limit, ok, err := ratelimit.ParseLimit(route.limit)
+		if err != nil {
+			return errors.Wrap(err, "rate limit")
+		}
+		if !ok {
+			continue
+		}
+		rateLimitOpts = append(rateLimitOpts, ratelimit.WithRoute(route.name, "/"+route.name+"/", limit))
+		if route.method != "" {
+			grpcRateLimitOpts = append(grpcRateLimitOpts, ratelimit.WithRoute(route.name, route.method, limit))
+		}
+	}
+
 	// Execution group.
+	// Both the REST and gRPC APIs are authenticated and scoped to a tenant
+	// the same way. The tenant is resolved after authentication, as the tenant
+	// of the principal takes precedence over the tenant header.
+	// Only the health and readiness checks are open, the statistics of the
+	// status API are scoped to the tenant like every other API.
+	openPaths := []string{
+		"/status" + status.APIPathLivenessQuery,
+		"/status" + status.APIPathReadinessQuery,
+		defaultOpenAPIPath,
+	}
+	authenticate := func(handler http.Handler) http.Handler {
+		var tenantOpts []tenant.MiddlewareOption
+		for _, path := range openPaths {
+			tenantOpts = append(tenantOpts, tenant.WithOpenPath(path))
+		}
+		if *tenantRequired {
+			tenantOpts = append(tenantOpts, tenant.WithRequired())
+		}
+		handler = tenant.NewMiddleware(handler, log.With(logger, "component", "tenant"), tenantOpts...)
+		if authConfig.Enabled() {
+			var opts []auth.MiddlewareOption
+			if *authStatusOpen {
+				for _, path := range openPaths {
+					opts = append(opts, auth.WithOpenPath(path))
+				}
+			}
+			handler = auth.NewMiddleware(handler, authProviderList, log.With(logger, "component", "auth"), opts...)
+		}
+		return handler
+	}
+
 	g := gexec.NewGroup()
 	gexec.Block(g)
 	{
@@ -216,7 +587,7 @@ func runDocuments(args []string) error {
 				for {
 					select {
 					case <-dst:
-						stats, err := repository.LedgerStatistics()
+						stats, err := repository.LedgerStatistics(statisticsQuery)
 						if err != nil {
 							level.Error(logger).Log("err", err)
 							return
@@ -234,13 +605,87 @@ func runDocuments(args []string) error {
 			close(cancel)
 		})
 	}
+	{
+		// Report the totals of the ledgers to the gauges periodically.
+		reporter := store.NewReporter(dataStore,
+			totalLedgers, totalResources, totalBytes,
+			log.With(logger, "component", "reporter"),
+			store.WithReporterInterval(*metricsInterval),
+		)
+		g.Add(func() error {
+			return reporter.Run()
+		}, func(error) {
+			reporter.Stop()
+		})
+	}
+	if *checkpointKeyFile != "" {
+		// Checkpoint the ledgers periodically.
+		cancel := make(chan struct{})
+		g.Add(func() error {
+			ticker := time.NewTicker(*checkpointInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					checkpoint, err := repository.Checkpoint()
+					if err != nil {
+						level.Error(logger).Log("action", "checkpoint", "err", err.Error())
+						continue
+					}
+					level.Debug(logger).Log("action", "checkpoint", "tree_size", checkpoint.TreeSize)
+				case <-cancel:
+					return nil
+				}
+			}
+		}, func(error) {
+			close(cancel)
+		})
+	}
+	if eventBus != nil {
+		// Events are shared between the replicas by the bus.
+		g.Add(func() error {
+			return eventBus.Run()
+		}, func(error) {
+			eventBus.Stop()
+		})
+	}
+	{
+		// Relay the changes of the ledgers from the outbox to the sinks. Only
+		// one of the replicas relays the outbox at a time.
+		relay := outbox.NewRelay(dataStore, outboxSinkList,
+			log.With(logger, "component", "outbox_relay"),
+			outbox.WithInterval(*outboxInterval),
+			outbox.WithBatchSize(*outboxBatchSize),
+		)
+		g.Add(func() error {
+			return relay.Run()
+		}, func(error) {
+			relay.Stop()
+		})
+	}
+	if *webhooksEnabled {
+		// Deliver the changes of the ledgers to the webhooks.
+		worker := webhooks.NewWorker(repository,
+			log.With(logger, "component", "webhooks_worker"),
+			webhooks.WithInterval(*webhooksInterval),
+			webhooks.WithMaxAttempts(*webhooksMaxAttempts),
+			webhooks.WithTimeout(*webhooksTimeout),
+			webhooks.WithBackoff(*webhooksBackoff),
+		)
+		g.Add(func() error {
+			return worker.Run()
+		}, func(error) {
+			worker.Stop()
+		})
+	}
 	{
 		g.Add(func() error {
 			contentsAPI := contents.NewAPI(repository,
 				log.With(logger, "component", "contents_api"),
 				connectedClients.WithLabelValues("contents"),
 				writerBytes, writerRecords,
-				apiDuration,
+				metrics.NewPrefixedHistogramVec("/contents", apiDuration),
 			)
 			defer contentsAPI.Close()
 
@@ -248,30 +693,104 @@ func runDocuments(args []string) error {
 			mux.Handle("/ledgers/", http.StripPrefix("/ledgers", ledgers.NewAPI(repository,
 				log.With(logger, "component", "ledgers_api"),
 				connectedClients.WithLabelValues("ledgers"),
-				apiDuration,
+				metrics.NewPrefixedHistogramVec("/ledgers", apiDuration),
+				ledgersOpts...,
 			)))
 			mux.Handle("/contents/", http.StripPrefix("/contents", contentsAPI))
 			mux.Handle("/journals/", http.StripPrefix("/journals", journals.NewAPI(repository,
 				log.With(logger, "component", "journals_api"),
 				connectedClients.WithLabelValues("journals"),
 				writerBytes, writerRecords,
-				apiDuration,
+				metrics.NewPrefixedHistogramVec("/journals", apiDuration),
+			)))
+			// The admin API works across the resources of the tenant, so it's
+			// only open to the admins when auth is enabled.
+			mux.Handle("/admin/", auth.NewAdminMiddleware(http.StripPrefix("/admin", admin.NewAPI(repository,
+				log.With(logger, "component", "admin_api"),
+				connectedClients.WithLabelValues("admin"),
+				metrics.NewPrefixedHistogramVec("/admin", apiDuration),
+			)), admins, log.With(logger, "component", "admin_auth")))
+			mux.Handle("/authors/", http.StripPrefix("/authors", authors.NewAPI(repository,
+				log.With(logger, "component", "authors_api"),
+				connectedClients.WithLabelValues("authors"),
+				metrics.NewPrefixedHistogramVec("/authors", apiDuration),
+			)))
+			mux.Handle("/checkpoints/", http.StripPrefix("/checkpoints", checkpoints.NewAPI(repository,
+				log.With(logger, "component", "checkpoints_api"),
+				connectedClients.WithLabelValues("checkpoints"),
+				metrics.NewPrefixedHistogramVec("/checkpoints", apiDuration),
+			)))
+			mux.Handle("/webhooks/", http.StripPrefix("/webhooks", webhooks.NewAPI(repository,
+				log.With(logger, "component", "webhooks_api"),
+				connectedClients.WithLabelValues("webhooks"),
+				metrics.NewPrefixedHistogramVec("/webhooks", apiDuration),
 			)))
 			mux.Handle("/status/", http.StripPrefix("/status", status.NewAPI(
 				log.With(logger, "component", "status_api"),
 				connectedClients.WithLabelValues("status"),
-				apiDuration,
+				metrics.NewPrefixedHistogramVec("/status", apiDuration),
+				status.WithStatistics(repository),
+				status.WithAdmins(admins),
 			)))
 			mux.Handle("/ui/", ui.NewAPI(*uiLocal, log.With(logger, "component", "ui")))
 
+			openAPI, err := openapi.NewAPI(openapi.NewDocument("snowy", version,
+				openapi.WithPaths("/ledgers", ledgers.OpenAPI()),
+				openapi.WithPaths("/contents", contents.OpenAPI()),
+				openapi.WithPaths("/journals", journals.OpenAPI()),
+				openapi.WithPaths("/status", status.OpenAPI()),
+			),
+				log.With(logger, "component", "openapi_api"),
+				connectedClients.WithLabelValues("openapi"),
+				apiDuration,
+			)
+			if err != nil {
+				return err
+			}
+			mux.Handle(defaultOpenAPIPath, openAPI)
+
 			registerMetrics(mux)
 			registerProfile(mux)
 
-			return http.Serve(apiListener, mux)
+			// Requests are rate limited after authentication, so that the
+			// principal can be limited rather than the remote IP.
+			var handler http.Handler = mux
+			if len(rateLimitOpts) > 0 {
+				opts := append(rateLimitOpts, ratelimit.WithMetrics(rateLimitRequests))
+				handler = ratelimit.NewMiddleware(handler, log.With(logger, "component", "ratelimit"), opts...)
+			}
+
+			// Every request is given an id and a span before authentication,
+			// so that rejected requests can be correlated too.
+			return http.Serve(apiListener, trace.NewMiddleware(authenticate(handler), tracer, log.With(logger, "component", "trace")))
 		}, func(error) {
 			apiListener.Close()
 		})
 	}
+	{
+		// Calls are rate limited after authentication, the same as the REST
+		// API. The buckets aren't shared with the REST API, so a client is
+		// limited per API.
+		middleware := authenticate
+		if len(grpcRateLimitOpts) > 0 {
+			middleware = func(handler http.Handler) http.Handler {
+				opts := append(grpcRateLimitOpts, ratelimit.WithMetrics(rateLimitRequests))
+				return authenticate(ratelimit.NewMiddleware(handler, log.With(logger, "component", "grpc_ratelimit"), opts...))
+			}
+		}
+		api := rpc.NewAPI(repository,
+			log.With(logger, "component", "grpc_api"),
+			connectedClients.WithLabelValues("grpc"),
+			writerBytes, writerRecords,
+			apiDuration,
+			rpc.WithMiddleware(middleware),
+		)
+		g.Add(func() error {
+			return api.Serve(grpcAPIListener)
+		}, func(error) {
+			api.Stop()
+		})
+	}
 	gexec.Interrupt(g)
 	return g.Run()
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SimonRichardson/flagset"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/client"
+	"github.com/trussle/uuid"
+)
+
+const (
+	defaultExportURL    = "http://localhost:8080"
+	defaultExportOutput = "-"
+)
+
+func runExport(args []string) error {
+	// flags for the export command
+	var (
+		flags = flagset.NewFlagSet("export", flag.ExitOnError)
+
+		apiURL     = flags.String("api", defaultExportURL, "URL of the documents query API")
+		apiKey     = flags.String("api.key", "", "API key to authenticate with")
+		tenantID   = flags.String("tenant", "", "tenant to export the ledgers of")
+		output     = flags.String("output", defaultExportOutput, "file to write the archive to, or - for stdout")
+		resourceID = flags.String("resource_id", "", "only export the resource")
+		tags       = flags.String("tags", "", "only export resources with any of the comma separated tags")
+		authorID   = flags.String("author_id", "", "only export resources with revisions by the author")
+		since      = flags.String("since", "", "only export revisions created at or after the time (RFC3339)")
+		until      = flags.String("until", "", "only export revisions created before the time (RFC3339)")
+		timeout    = flags.Duration("timeout", 0, "timeout for the export, zero for no timeout")
+	)
+
+	flags.Usage = usageFor(flags, "documents export [flags]")
+	if err := flags.Parse(args); err != nil {
+		return nil
+	}
+
+	var (
+		query client.ExportQuery
+		err   error
+	)
+	if *resourceID != "" {
+		if query.ResourceID, err = uuid.Parse(*resourceID); err != nil {
+			return errorFor(flags, "documents export [flags]", errors.Wrap(err, "resource_id"))
+		}
+	}
+	if *tags != "" {
+		query.Tags = strings.Split(*tags, ",")
+	}
+	query.AuthorID = *authorID
+	if *since != "" {
+		if query.Since, err = time.Parse(time.RFC3339, *since); err != nil {
+			return errorFor(flags, "documents export [flags]", errors.Wrap(err, "since"))
+		}
+	}
+	if *until != "" {
+		if query.Until, err = time.Parse(time.RFC3339, *until); err != nil {
+			return errorFor(flags, "documents export [flags]", errors.Wrap(err, "until"))
+		}
+	}
+
+	c, err := newClient(*apiURL, *apiKey, *tenantID, *timeout)
+	if err != nil {
+		return err
+	}
+
+	archive, err := c.Admin().Export(context.Background(), query)
+	if err != nil {
+		return errors.Wrap(err, "export request")
+	}
+	defer archive.Close()
+
+	var w io.Writer = os.Stdout
+	if *output != defaultExportOutput {
+		file, err := os.Create(*output)
+		if err != nil {
+			return errors.Wrap(err, "output")
+		}
+		defer file.Close()
+		w = file
+	}
+
+	n, err := io.Copy(w, archive)
+	if err != nil {
+		if *output != defaultExportOutput {
+			os.Remove(*output)
+		}
+		return errors.Wrap(err, "export response")
+	}
+
+	t := tabwriter.NewWriter(os.Stderr, 0, 0, 1, ' ', tabwriter.Debug)
+	fmt.Fprintf(t, "Output \tBytes \t\n")
+	fmt.Fprintf(t, "%s \t%d \t\n", *output, n)
+	t.Flush()
+
+	return nil
+}
+
+// newClient creates a client of the API, that's authenticated with the API
+// key and scoped to the tenant, if there are any.
+func newClient(apiURL, apiKey, tenantID string, timeout time.Duration) (*client.Client, error) {
+	opts := []client.Option{
+		client.WithHTTPClient(&http.Client{Timeout: timeout}),
+	}
+	if apiKey != "" {
+		opts = append(opts, client.WithAPIKey(apiKey))
+	}
+	if tenantID != "" {
+		opts = append(opts, client.WithTenant(tenantID))
+	}
+	return client.New(apiURL, opts...)
+}
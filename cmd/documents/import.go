@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/SimonRichardson/flagset"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultImportURL   = "http://localhost:8080"
+	defaultImportInput = "-"
+)
+
+func runImport(args []string) error {
+	// flags for the import command
+	var (
+		flags = flagset.NewFlagSet("import", flag.ExitOnError)
+
+		apiURL   = flags.String("api", defaultImportURL, "URL of the documents query API")
+		apiKey   = flags.String("api.key", "", "API key to authenticate with")
+		tenantID = flags.String("tenant", "", "tenant to import the ledgers in to")
+		input    = flags.String("input", defaultImportInput, "file to read the archive from, or - for stdin")
+		timeout  = flags.Duration("timeout", 0, "timeout for the import, zero for no timeout")
+	)
+
+	flags.Usage = usageFor(flags, "documents import [flags]")
+	if err := flags.Parse(args); err != nil {
+		return nil
+	}
+
+	// A file can be sent again if the request is retried, where as stdin can
+	// only be read once.
+	var r io.Reader = os.Stdin
+	if *input != defaultImportInput {
+		file, err := os.Open(*input)
+		if err != nil {
+			return errors.Wrap(err, "input")
+		}
+		defer file.Close()
+		r = file
+	}
+
+	c, err := newClient(*apiURL, *apiKey, *tenantID, *timeout)
+	if err != nil {
+		return err
+	}
+
+	summary, err := c.Admin().Import(context.Background(), r)
+	if err != nil {
+		return errors.Wrap(err, "import request")
+	}
+
+	t := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+	fmt.Fprintf(t, "Ledgers \tDuplicates \tBlobs \tBytes \tMissing \t\n")
+	fmt.Fprintf(t, "%d \t%d \t%d \t%d \t%d \t\n", summary.Ledgers, summary.Duplicates, summary.Blobs, summary.Bytes, summary.Missing)
+	t.Flush()
+
+	return nil
+}
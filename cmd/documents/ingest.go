@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SimonRichardson/flagset"
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/ingest"
+)
+
+const (
+	defaultIngestURL      = "http://localhost:8080"
+	defaultIngestState    = ".snowy-ingest.json"
+	defaultIngestInterval = 10 * time.Second
+	defaultIngestSettle   = 5 * time.Second
+	defaultIngestTimeout  = time.Minute
+)
+
+func runIngest(args []string) error {
+	// flags for the ingest command
+	var (
+		flags = flagset.NewFlagSet("ingest", flag.ExitOnError)
+
+		apiURL   = flags.String("api", defaultIngestURL, "URL of the documents query API")
+		apiKey   = flags.String("api.key", "", "API key to authenticate with")
+		tenantID = flags.String("tenant", "", "tenant to ingest the files in to")
+		dir      = flags.String("dir", "", "directory of the files to ingest")
+		rules    = flags.String("rules", "", "json file of the rules that map the files to their metadata")
+		state    = flags.String("state", "", "file to keep what has been ingested in, defaults to "+defaultIngestState+" in the directory")
+		watch    = flags.Bool("watch", false, "keep scanning the directory for new and changed files")
+		interval = flags.Duration("interval", defaultIngestInterval, "how often the directory is scanned when watching")
+		settle   = flags.Duration("settle", defaultIngestSettle, "how long a file has to be left unmodified before it's ingested")
+		timeout  = flags.Duration("timeout", defaultIngestTimeout, "timeout for every request")
+	)
+
+	flags.Usage = usageFor(flags, "documents ingest [flags]")
+	if err := flags.Parse(args); err != nil {
+		return nil
+	}
+
+	if *dir == "" {
+		return errorFor(flags, "documents ingest [flags]", errors.New("dir is required"))
+	}
+	if info, err := os.Stat(*dir); err != nil {
+		return errors.Wrap(err, "dir")
+	} else if !info.IsDir() {
+		return errors.Errorf("dir %q is not a directory", *dir)
+	}
+
+	opts := []ingest.IngesterOption{
+		ingest.WithInterval(*interval),
+		ingest.WithSettle(*settle),
+	}
+	if *rules != "" {
+		file, err := os.Open(*rules)
+		if err != nil {
+			return errors.Wrap(err, "rules")
+		}
+		res, err := ingest.ReadRules(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, ingest.WithRules(res))
+	}
+
+	if *state == "" {
+		*state = filepath.Join(*dir, defaultIngestState)
+	}
+	ingested, err := ingest.LoadState(*state)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient(*apiURL, *apiKey, *tenantID, *timeout)
+	if err != nil {
+		return err
+	}
+
+	var logger log.Logger
+	{
+		logger = log.NewLogfmtLogger(os.Stderr)
+		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+	}
+
+	ingester := ingest.NewIngester(c.Journals(), *dir, ingested, logger, opts...)
+
+	if *watch {
+		go func() {
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+			<-signals
+			ingester.Stop()
+		}()
+		return ingester.Run()
+	}
+
+	summary, err := ingester.Scan(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "ingest")
+	}
+
+	t := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+	fmt.Fprintf(t, "Inserted \tAppended \tUnchanged \tSkipped \tPending \tFailed \t\n")
+	fmt.Fprintf(t, "%d \t%d \t%d \t%d \t%d \t%d \t\n", summary.Inserted, summary.Appended, summary.Unchanged, summary.Skipped, summary.Pending, summary.Failed)
+	t.Flush()
+
+	if summary.Failed > 0 {
+		return errors.Errorf("%d files failed to ingest", summary.Failed)
+	}
+	return nil
+}
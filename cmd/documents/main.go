@@ -28,12 +28,14 @@ const (
 var version = "dev"
 
 const (
-	defaultAPIPort = 8080
-	defaultAddr    = "0.0.0.0:0"
+	defaultAPIPort     = 8080
+	defaultGRPCAPIPort = 8081
+	defaultAddr        = "0.0.0.0:0"
 )
 
 var (
-	defaultAPIAddr = fmt.Sprintf("tcp://0.0.0.0:%d", defaultAPIPort)
+	defaultAPIAddr     = fmt.Sprintf("tcp://0.0.0.0:%d", defaultAPIPort)
+	defaultGRPCAPIAddr = fmt.Sprintf("tcp://0.0.0.0:%d", defaultGRPCAPIPort)
 )
 
 type command func([]string) error
@@ -76,6 +78,10 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "\n")
 	fmt.Fprintf(os.Stderr, "MODES\n")
 	fmt.Fprintf(os.Stderr, "  documents       Documents query service\n")
+	fmt.Fprintf(os.Stderr, "  documents verify  Verify the hash chain of a ledger\n")
+	fmt.Fprintf(os.Stderr, "  documents export  Export ledgers and content to an archive\n")
+	fmt.Fprintf(os.Stderr, "  documents import  Import ledgers and content from an archive\n")
+	fmt.Fprintf(os.Stderr, "  documents ingest  Ingest the files of a directory\n")
 	fmt.Fprintf(os.Stderr, "\n")
 	fmt.Fprintf(os.Stderr, "VERSION\n")
 	fmt.Fprintf(os.Stderr, "  %s (%s)\n", version, runtime.Version())
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SimonRichardson/flagset"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/ledgers"
+)
+
+const (
+	defaultVerifyURL     = "http://localhost:8080"
+	defaultVerifyTimeout = 30 * time.Second
+)
+
+type verification struct {
+	ResourceID string `json:"resource_id"`
+	Revisions  int    `json:"revisions"`
+	Valid      bool   `json:"valid"`
+	BrokenID   string `json:"broken_id"`
+	Reason     string `json:"reason"`
+}
+
+func runVerify(args []string) error {
+	// flags for the verify command
+	var (
+		flags = flagset.NewFlagSet("verify", flag.ExitOnError)
+
+		apiURL     = flags.String("api", defaultVerifyURL, "URL of the documents query API")
+		resourceID = flags.String("resource_id", "", "resource id of the ledger to verify")
+		timeout    = flags.Duration("timeout", defaultVerifyTimeout, "timeout for the verify request")
+	)
+
+	flags.Usage = usageFor(flags, "documents verify [flags]")
+	if err := flags.Parse(args); err != nil {
+		return nil
+	}
+
+	if *resourceID == "" {
+		return errorFor(flags, "documents verify [flags]", errors.New("resource_id is required"))
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(*apiURL, "/") + "/ledgers" + ledgers.APIPathVerifyQuery)
+	if err != nil {
+		return errors.Wrap(err, "api url")
+	}
+	u.RawQuery = url.Values{"resource_id": []string{*resourceID}}.Encode()
+
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return errors.Wrap(err, "verify request")
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errors.Errorf("ledger %s not found", *resourceID)
+	default:
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var res verification
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return errors.Wrap(err, "verify response")
+	}
+
+	t := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+	fmt.Fprintf(t, "Resource ID \tRevisions \tValid \tBroken ID \tReason \t\n")
+	fmt.Fprintf(t, "%s \t%d \t%t \t%s \t%s \t\n", res.ResourceID, res.Revisions, res.Valid, res.BrokenID, res.Reason)
+	t.Flush()
+
+	if !res.Valid {
+		return errors.Errorf("chain broken at revision %s: %s", res.BrokenID, res.Reason)
+	}
+	return nil
+}
@@ -7,10 +7,13 @@ import (
 
 	prompt "github.com/c-bata/go-prompt"
 	colorable "github.com/mattn/go-colorable"
+	"github.com/trussle/snowy/pkg/client"
 )
 
 const (
 	helpTemplate = "This is a wrapper around the 'snowy' repository."
+
+	defaultBase = "http://0.0.0.0:8080"
 )
 
 var list = commands{
@@ -23,10 +26,16 @@ var list = commands{
 }
 
 func main() {
+	c, err := client.New(defaultBase)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	p := prompt.New(
-		execute(client{
-			base: "http://0.0.0.0:8080",
-			out:  colorable.NewColorableStdout(),
+		execute(session{
+			client: c,
+			out:    colorable.NewColorableStdout(),
 		}),
 		complete(list),
 		prompt.OptionTitle("snowy: interactive Snowy client (type \"q\" to exit)"),
@@ -37,7 +46,7 @@ func main() {
 	p.Run()
 }
 
-func execute(c client) func(string) {
+func execute(c session) func(string) {
 	return func(s string) {
 		switch a := strings.TrimSpace(s); a {
 		case "":
@@ -53,7 +62,7 @@ func execute(c client) func(string) {
 	}
 }
 
-func execRequest(c client, s string) {
+func execRequest(c session, s string) {
 	parts := strings.Split(s, " ")
 	switch parts[0] {
 	case "help":
@@ -75,12 +84,12 @@ func execRequest(c client, s string) {
 		validateOptions(parts[1:], func(s string) bool {
 			return !strings.HasPrefix(s, "-")
 		})
-		c.ledger(parts[1])
+		c.ledger(argument(parts))
 	case "ledgers":
 		validateOptions(parts[1:], func(s string) bool {
 			return !strings.HasPrefix(s, "-")
 		})
-		c.ledgers(parts[1])
+		c.ledgers(argument(parts))
 	}
 }
 
@@ -120,3 +129,10 @@ func validateOptions(options []string, fn func(string) bool) {
 		}
 	}
 }
+
+func argument(parts []string) string {
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
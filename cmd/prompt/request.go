@@ -1,18 +1,18 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
+	"github.com/trussle/snowy/pkg/client"
 	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
 )
 
 var (
@@ -24,104 +24,78 @@ var (
 	whitef = color.New(color.FgWhite).SprintfFunc()
 )
 
-type client struct {
-	base string
-	out  io.Writer
+type session struct {
+	client *client.Client
+	out    io.Writer
 }
 
-func (c client) health(statusOnly bool) {
-	endpoint := "status/health"
+func (c session) health(statusOnly bool) {
+	var (
+		endpoint = "status/health"
+		check    = c.client.Health
+	)
 	if statusOnly {
 		endpoint = "status/ready"
+		check = c.client.Ready
 	}
-	res, err := http.Get(fmt.Sprintf("%s/%s", c.base, endpoint))
-	if err != nil {
+	if err := check(context.Background()); err != nil {
 		c.output(redf("Error response %q with error %s", endpoint, white(err.Error())))
 		return
 	}
 
-	defer res.Body.Close()
-	if c.errors(res, err) {
-		return
-	}
-
 	c.output(greenf("valid %s", white(endpoint)))
 }
 
-func (c client) ledger(id string) {
-	res, err := http.Get(fmt.Sprintf("%s/ledgers/?resource_id=%s", c.base, id))
-	if err != nil {
-		defer res.Body.Close()
-	}
-	if c.errors(res, err) {
+func (c session) ledger(id string) {
+	resourceID, ok := c.resourceID(id)
+	if !ok {
 		return
 	}
 
-	endpoint := res.Request.URL.String()
-	body, err := ioutil.ReadAll(res.Body)
+	ledger, err := c.client.Ledgers().Select(context.Background(), resourceID, client.Query{})
 	if err != nil {
-		c.output(redf("Error response %q with error %s", endpoint, white(err.Error())))
-		return
-	}
-
-	var ledger models.Ledger
-	if err := json.Unmarshal(body, &ledger); err != nil {
-		c.output(redf("Error response %q with error %s", endpoint, white(err.Error())))
+		c.output(redf("Error requesting %q with error %s", "ledgers", white(err.Error())))
 		return
 	}
 
 	c.outputLedger(ledger)
 }
 
-func (c client) ledgers(id string) {
-	res, err := http.Get(fmt.Sprintf("%s/ledgers/revisions/?resource_id=%s", c.base, id))
-	if err != nil {
-		defer res.Body.Close()
-	}
-	if c.errors(res, err) {
+func (c session) ledgers(id string) {
+	resourceID, ok := c.resourceID(id)
+	if !ok {
 		return
 	}
 
-	endpoint := res.Request.URL.String()
-	body, err := ioutil.ReadAll(res.Body)
+	ledgers, err := c.client.Ledgers().Revisions(context.Background(), resourceID, client.Query{})
 	if err != nil {
-		c.output(redf("Error response %q with error %s", endpoint, white(err.Error())))
-		return
-	}
-
-	var ledgers []models.Ledger
-	if err := json.Unmarshal(body, &ledgers); err != nil {
-		c.output(redf("Error response %q with error %s", endpoint, white(err.Error())))
+		c.output(redf("Error requesting %q with error %s", "ledgers/revisions", white(err.Error())))
 		return
 	}
 
 	c.outputLedgers(ledgers)
 }
 
-func (c client) output(s string) {
-	fmt.Fprintln(c.out, s)
-}
-
-func (c client) errors(res *http.Response, err error) bool {
-	endpoint := res.Request.URL.String()
+func (c session) resourceID(id string) (uuid.UUID, bool) {
+	resourceID, err := uuid.Parse(id)
 	if err != nil {
-		c.output(redf("Error requesting %q with error %s", endpoint, white(err.Error())))
-		return true
-	}
-	if code := res.StatusCode; code != 200 {
-		c.output(redf("Error requesting %q with status code %s", endpoint, white(code)))
-		return true
+		c.output(redf("Invalid resource id %q", white(id)))
+		return uuid.Empty, false
 	}
-	return false
+	return resourceID, true
+}
+
+func (c session) output(s string) {
+	fmt.Fprintln(c.out, s)
 }
 
-func (c client) outputLedger(ledger models.Ledger) {
+func (c session) outputLedger(ledger models.Ledger) {
 	c.outputLedgers([]models.Ledger{
 		ledger,
 	})
 }
 
-func (c client) outputLedgers(ledgers []models.Ledger) {
+func (c session) outputLedgers(ledgers []models.Ledger) {
 	data := [][]string{}
 	for _, ledger := range ledgers {
 		data = append(data, []string{
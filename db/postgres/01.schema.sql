@@ -1,6 +1,7 @@
 CREATE TABLE IF NOT EXISTS ledgers (
   id                      UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
   parent_id               UUID NOT NULL,
+  tenant_id               TEXT NOT NULL DEFAULT '',
   resource_id             UUID NOT NULL,
   resource_address        TEXT NOT NULL,
   resource_size           BIGINT NOT NULL,
@@ -9,5 +10,155 @@ CREATE TABLE IF NOT EXISTS ledgers (
   name                    TEXT NOT NULL,
   tags                    TEXT[] NOT NULL,
   created_on              TIMESTAMPTZ NOT NULL,
-  deleted_on              TIMESTAMPTZ NOT NULL
+  deleted_on              TIMESTAMPTZ NOT NULL,
+  hash                    TEXT NOT NULL DEFAULT '',
+  signature               BYTEA NOT NULL DEFAULT '',
+  signature_key_id        TEXT NOT NULL DEFAULT ''
+);
+-- Columns that were added after the ledgers table was first created.
+ALTER TABLE ledgers ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE ledgers ADD COLUMN IF NOT EXISTS hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE ledgers ADD COLUMN IF NOT EXISTS signature BYTEA NOT NULL DEFAULT '';
+ALTER TABLE ledgers ADD COLUMN IF NOT EXISTS signature_key_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS ledgers_tenant_id_resource_id ON ledgers (tenant_id, resource_id);
+CREATE TABLE IF NOT EXISTS ledger_keys (
+  tenant_id               TEXT NOT NULL DEFAULT '',
+  resource_id             UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+  resource_address        TEXT NOT NULL,
+  key_id                  TEXT NOT NULL,
+  wrapped_key             BYTEA NOT NULL,
+  created_on              TIMESTAMPTZ NOT NULL,
+  destroyed_on            TIMESTAMPTZ NOT NULL
+);
+-- Keys were keyed by the address alone, prefixed with the tenant, and shared
+-- by every resource that referred to the content. Split out the tenant and
+-- give every resource its own copy of the key.
+ALTER TABLE ledger_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE ledger_keys ADD COLUMN IF NOT EXISTS resource_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE ledger_keys DROP CONSTRAINT IF EXISTS ledger_keys_pkey;
+UPDATE ledger_keys
+SET    tenant_id = substring(resource_address FROM '^(.*):[^:]*$'),
+       resource_address = substring(resource_address FROM '[^:]*$')
+WHERE  resource_address LIKE '%:%';
+CREATE UNIQUE INDEX IF NOT EXISTS ledger_keys_tenant_id_resource_id ON ledger_keys (tenant_id, resource_id, resource_address);
+INSERT INTO ledger_keys
+       (tenant_id, resource_id, resource_address, key_id, wrapped_key, created_on, destroyed_on)
+SELECT DISTINCT ON (l.tenant_id, l.resource_id, k.resource_address)
+       l.tenant_id, l.resource_id, k.resource_address, k.key_id, k.wrapped_key, k.created_on, k.destroyed_on
+FROM   ledgers l
+JOIN   ledger_keys k
+ON     k.tenant_id = l.tenant_id
+       AND k.resource_address = l.resource_address
+       AND k.resource_id = '00000000-0000-0000-0000-000000000000'
+ON CONFLICT (tenant_id, resource_id, resource_address) DO NOTHING;
+CREATE TABLE IF NOT EXISTS ledger_leaves (
+  leaf_index              BIGINT PRIMARY KEY,
+  ledger_id               UUID NOT NULL UNIQUE,
+  hash                    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ledger_tree_nodes (
+  level                   INT NOT NULL,
+  node_index              BIGINT NOT NULL,
+  hash                    BYTEA NOT NULL,
+  PRIMARY KEY (level, node_index)
+);
+CREATE TABLE IF NOT EXISTS ledger_checkpoints (
+  tree_size               BIGINT PRIMARY KEY,
+  root_hash               BYTEA NOT NULL,
+  key_id                  TEXT NOT NULL,
+  signature               BYTEA NOT NULL,
+  created_on              TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE IF NOT EXISTS author_keys (
+  tenant_id               TEXT NOT NULL DEFAULT '',
+  author_id               TEXT NOT NULL,
+  key_id                  TEXT NOT NULL,
+  public_key              BYTEA NOT NULL,
+  created_on              TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (tenant_id, author_id, key_id)
+);
+-- Author keys were keyed by author alone, before tenants.
+ALTER TABLE author_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE author_keys DROP CONSTRAINT IF EXISTS author_keys_pkey;
+ALTER TABLE author_keys ADD PRIMARY KEY (tenant_id, author_id, key_id);
+CREATE TABLE IF NOT EXISTS ledger_acls (
+  id                      BIGSERIAL PRIMARY KEY,
+  tenant_id               TEXT NOT NULL DEFAULT '',
+  resource_id             UUID NOT NULL,
+  owner                   TEXT NOT NULL,
+  readers                 TEXT[] NOT NULL,
+  writers                 TEXT[] NOT NULL,
+  updated_by              TEXT NOT NULL,
+  created_on              TIMESTAMPTZ NOT NULL
+);
+ALTER TABLE ledger_acls ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '';
+-- Access control lists were keyed by resource alone, so take the tenant from
+-- the ledgers of the resource.
+UPDATE ledger_acls a
+SET    tenant_id = l.tenant_id
+FROM   ledgers l
+WHERE  l.resource_id = a.resource_id
+       AND a.tenant_id = ''
+       AND l.tenant_id <> '';
+DROP INDEX IF EXISTS ledger_acls_resource_id;
+CREATE INDEX IF NOT EXISTS ledger_acls_tenant_id_resource_id ON ledger_acls (tenant_id, resource_id, id);
+CREATE TABLE IF NOT EXISTS ledger_quotas (
+  tenant_id               TEXT NOT NULL DEFAULT '',
+  author_id               TEXT NOT NULL DEFAULT '',
+  max_bytes               BIGINT NOT NULL DEFAULT 0,
+  max_resources           BIGINT NOT NULL DEFAULT 0,
+  max_revisions_per_day   BIGINT NOT NULL DEFAULT 0,
+  updated_on              TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (tenant_id, author_id)
+);
+CREATE TABLE IF NOT EXISTS webhooks (
+  id                      UUID PRIMARY KEY,
+  tenant_id               TEXT NOT NULL DEFAULT '',
+  url                     TEXT NOT NULL,
+  secret                  TEXT NOT NULL,
+  resource_id             UUID NOT NULL,
+  tag                     TEXT NOT NULL DEFAULT '',
+  author_id               TEXT NOT NULL DEFAULT '',
+  events                  TEXT[] NOT NULL,
+  owner                   TEXT NOT NULL DEFAULT '',
+  created_on              TIMESTAMPTZ NOT NULL
+);
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS webhooks_tenant_id ON webhooks (tenant_id);
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id                      UUID PRIMARY KEY,
+  webhook_id              UUID NOT NULL,
+  tenant_id               TEXT NOT NULL DEFAULT '',
+  event                   TEXT NOT NULL,
+  payload                 BYTEA NOT NULL,
+  status                  TEXT NOT NULL,
+  attempts                INTEGER NOT NULL DEFAULT 0,
+  last_error              TEXT NOT NULL DEFAULT '',
+  next_attempt_on         TIMESTAMPTZ NOT NULL,
+  created_on              TIMESTAMPTZ NOT NULL,
+  delivered_on            TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_status_next_attempt_on ON webhook_deliveries (status, next_attempt_on);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_tenant_id_status ON webhook_deliveries (tenant_id, status, created_on);
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+  id                      BIGSERIAL PRIMARY KEY,
+  delivery_id             UUID NOT NULL,
+  attempted_on            TIMESTAMPTZ NOT NULL,
+  status_code             INTEGER NOT NULL,
+  error                   TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS webhook_attempts_delivery_id ON webhook_attempts (delivery_id, id);
+CREATE TABLE IF NOT EXISTS ledger_outbox (
+  sequence                BIGSERIAL PRIMARY KEY,
+  event                   TEXT NOT NULL,
+  ledger_id               UUID NOT NULL,
+  tenant_id               TEXT NOT NULL DEFAULT '',
+  resource_id             UUID NOT NULL,
+  payload                 BYTEA NOT NULL,
+  created_on              TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ledger_outbox_checkpoints (
+  sink                    TEXT PRIMARY KEY,
+  sequence                BIGINT NOT NULL,
+  updated_on              TIMESTAMPTZ NOT NULL
 );
@@ -1 +1,2 @@
-CREATE INDEX ledgers_tags ON ledgers USING GIN(tags);
+CREATE INDEX IF NOT EXISTS ledgers_tags ON ledgers USING GIN(tags);
+CREATE INDEX IF NOT EXISTS ledgers_author_id ON ledgers (author_id);
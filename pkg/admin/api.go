@@ -0,0 +1,353 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/trussle/snowy/pkg/archive"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/snowy/pkg/trace"
+)
+
+// These are the admin API URL paths.
+const (
+	APIPathEraseQuery       = "/erase/"
+	APIPathSelectQuotaQuery = "/quotas/"
+	APIPathUpdateQuotaQuery = "/quotas/"
+	APIPathExportQuery      = "/export/"
+	APIPathImportQuery      = "/import/"
+)
+
+// API serves the admin API
+type API struct {
+	handler    *mux.Router
+	repository repository.Repository
+	logger     log.Logger
+	clients    metrics.Gauge
+	duration   metrics.HistogramVec
+	errors     errs.Error
+}
+
+// NewAPI creates a API with correct dependencies.
+func NewAPI(repository repository.Repository, logger log.Logger,
+	clients metrics.Gauge,
+	duration metrics.HistogramVec,
+) *API {
+	api := &API{
+		repository: repository,
+		logger:     logger,
+		clients:    clients,
+		duration:   duration,
+		errors:     errs.NewError(logger),
+	}
+	{
+		router := mux.NewRouter().StrictSlash(true)
+		router.Methods("POST").Path(APIPathEraseQuery).HandlerFunc(api.handleErase)
+		router.Methods("GET").Path(APIPathSelectQuotaQuery).HandlerFunc(api.handleSelectQuota)
+		router.Methods("PUT").Path(APIPathUpdateQuotaQuery).HandlerFunc(api.handleUpdateQuota)
+		router.Methods("GET").Path(APIPathExportQuery).HandlerFunc(api.handleExport)
+		router.Methods("POST").Path(APIPathImportQuery).HandlerFunc(api.handleImport)
+		router.NotFoundHandler = http.HandlerFunc(api.errors.NotFound)
+
+		api.handler = router
+	}
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	level.Info(trace.Logger(r.Context(), a.logger)).Log("url", r.URL.String())
+
+	iw := &interceptingWriter{http.StatusOK, w}
+	w = iw
+
+	// Metrics
+	a.clients.Inc()
+	defer a.clients.Dec()
+
+	defer func(begin time.Time) {
+		a.duration.WithLabelValues(
+			r.Method,
+			metrics.Route(a.handler, r),
+			strconv.Itoa(iw.code),
+		).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) handleErase(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp EraseQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	var ledgers []models.Ledger
+	if qp.AuthorID != "" {
+		ledgers, err = a.repo(r).EraseAuthorLedgers(qp.AuthorID, options)
+	} else {
+		var ledger models.Ledger
+		if ledger, err = a.repo(r).EraseLedger(qp.ResourceID, options); err == nil {
+			ledgers = []models.Ledger{ledger}
+		}
+	}
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		if repository.ErrNotSupported(err) {
+			a.errors.NotSupported(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	level.Info(a.logger).Log("action", "erase", "resource_id", qp.ResourceID.String(), "author_id", qp.AuthorID, "erased", len(ledgers))
+
+	// Make sure we collect the tombstones for the result.
+	qr := EraseQueryResult{Errors: a.errors, Params: qp}
+	qr.Ledgers = ledgers
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleSelectQuota(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp QuotaQueryParams
+	if err := qp.DecodeFrom(r.URL, queryOptional); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	quota, err := a.repo(r).SelectQuota(qp.AuthorID, options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the quota for the result.
+	qr := QuotaQueryResult{Errors: a.errors, Params: qp}
+	qr.Quota = quota
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleUpdateQuota(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp QuotaQueryParams
+	if err := qp.DecodeFrom(r.URL, queryOptional); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	input, err := ingestQuota(r.Body)
+	if err != nil {
+		a.errors.InvalidInput(w, r, err)
+		return
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	quota, err := a.repo(r).UpdateQuota(models.Quota{
+		AuthorID:           qp.AuthorID,
+		MaxBytes:           input.MaxBytes,
+		MaxResources:       input.MaxResources,
+		MaxRevisionsPerDay: input.MaxRevisionsPerDay,
+	}, options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	level.Info(a.logger).Log("action", "quota", "author_id", qp.AuthorID, "max_bytes", quota.MaxBytes, "max_resources", quota.MaxResources, "max_revisions_per_day", quota.MaxRevisionsPerDay)
+
+	// Make sure we collect the quota for the result.
+	qr := QuotaQueryResult{Errors: a.errors, Params: qp}
+	qr.Quota = quota
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleExport(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp ExportQueryParams
+	if err := qp.DecodeFrom(r.URL, queryOptional); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	// The admin API is only open to the admins, who export every resource of
+	// the tenant regardless of the access control lists, so that the archive
+	// is a whole backup of the tenant.
+	options, err := repository.BuildQuery(
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	export, err := archive.NewExport(a.repo(r), archive.Filter{
+		ResourceID: qp.ResourceID,
+		Tags:       qp.Tags,
+		AuthorID:   qp.AuthorID,
+		Since:      qp.Since,
+		Until:      qp.Until,
+	}, options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Once the archive is being written, the status can't be changed, so an
+	// error is only logged, leaving the archive incomplete.
+	w.Header().Set(httpHeaderContentType, defaultArchiveContentType)
+	w.Header().Set(httpHeaderContentDisposition, fmt.Sprintf("attachment; filename=snowy-%s.tar", begin.UTC().Format("20060102T150405Z")))
+	w.Header().Set(httpHeaderLedgers, strconv.Itoa(export.Ledgers()))
+
+	summary, err := export.Write(w)
+	if err != nil {
+		level.Error(trace.Logger(r.Context(), a.logger)).Log("action", "export", "err", err.Error())
+		return
+	}
+
+	level.Info(a.logger).Log("action", "export", "ledgers", summary.Ledgers, "blobs", summary.Blobs, "bytes", summary.Bytes, "missing", summary.Missing, "duration", time.Since(begin).String())
+}
+
+func (a *API) handleImport(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	summary, err := archive.Import(r.Body, a.repo(r), options)
+	if err != nil {
+		switch {
+		case archive.ErrInvalid(err):
+			a.errors.BadRequest(w, r, err.Error())
+		case repository.ErrQuotaExceeded(err):
+			a.errors.QuotaExceeded(w, r, err.Error())
+		default:
+			a.errors.InternalServerError(w, r, err.Error())
+		}
+		return
+	}
+
+	level.Info(a.logger).Log("action", "import", "ledgers", summary.Ledgers, "duplicates", summary.Duplicates, "blobs", summary.Blobs, "bytes", summary.Bytes, "missing", summary.Missing, "duration", time.Since(begin).String())
+
+	// Make sure we collect the summary for the result.
+	qr := ImportQueryResult{Errors: a.errors}
+	qr.Summary = summary
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func ingestQuota(reader io.ReadCloser) (models.QuotaInput, error) {
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return models.QuotaInput{}, err
+	}
+
+	if len(bytes) < 1 {
+		return models.QuotaInput{}, errors.New("no body content")
+	}
+
+	var input models.QuotaInput
+	if err = json.Unmarshal(bytes, &input); err != nil {
+		return models.QuotaInput{}, err
+	}
+	if err = models.ValidateQuotaInput(input); err != nil {
+		return models.QuotaInput{}, err
+	}
+	return input, nil
+}
+
+type interceptingWriter struct {
+	code int
+	http.ResponseWriter
+}
+
+func (iw *interceptingWriter) WriteHeader(code int) {
+	iw.code = code
+	iw.ResponseWriter.WriteHeader(code)
+}
+
+// repo returns the repository bound to the context of the request, so that
+// the calls to the repository are traced as part of the request.
+func (a *API) repo(r *http.Request) repository.Repository {
+	return repository.WithContext(r.Context(), a.repository)
+}
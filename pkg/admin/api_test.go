@@ -0,0 +1,791 @@
+package admin
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/archive"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+	"github.com/trussle/uuid"
+)
+
+func TestEraseAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("erase with no resource_id or author_id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/erase/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		resp, err := http.Post(fmt.Sprintf("%s/erase/", server.URL), "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("erase with resource_id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(uid uuid.UUID, name, authorID string) bool {
+			var (
+				clients  = metricMocks.NewMockGauge(ctrl)
+				duration = metricMocks.NewMockHistogramVec(ctrl)
+				observer = metricMocks.NewMockObserver(ctrl)
+				repo     = repoMocks.NewMockRepository(ctrl)
+
+				api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+				server = httptest.NewServer(api)
+			)
+			defer server.Close()
+
+			tombstone, err := models.BuildLedger(
+				models.WithResourceID(uid),
+				models.WithName(name),
+				models.WithAuthorID(authorID),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
+
+			duration.EXPECT().WithLabelValues("POST", "/erase/", "200").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+			repo.EXPECT().EraseLedger(uid, repository.Query{}).Times(1).Return(tombstone, nil)
+
+			resp, err := http.Post(fmt.Sprintf("%s/erase/?resource_id=%s", server.URL, uid), "application/json", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var ledgers []struct {
+				ResourceID string `json:"resource_id"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&ledgers); err != nil {
+				t.Fatal(err)
+			}
+
+			return resp.StatusCode == http.StatusOK &&
+				len(ledgers) == 1 &&
+				ledgers[0].ResourceID == uid.String()
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("erase with resource_id not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+
+			uid = uuid.MustNew()
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/erase/", "404").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().EraseLedger(uid, repository.Query{}).Times(1).Return(models.Ledger{}, errNotFound{errors.New("failure")})
+
+		resp, err := http.Post(fmt.Sprintf("%s/erase/?resource_id=%s", server.URL, uid), "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusNotFound, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("erase without encryption", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+
+			uid = uuid.MustNew()
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/erase/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().EraseLedger(uid, repository.Query{}).Times(1).Return(models.Ledger{}, errNotSupported{errors.New("failure")})
+
+		resp, err := http.Post(fmt.Sprintf("%s/erase/?resource_id=%s", server.URL, uid), "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("erase with author_id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(a, b uuid.UUID) bool {
+			var (
+				clients  = metricMocks.NewMockGauge(ctrl)
+				duration = metricMocks.NewMockHistogramVec(ctrl)
+				observer = metricMocks.NewMockObserver(ctrl)
+				repo     = repoMocks.NewMockRepository(ctrl)
+
+				api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+				server = httptest.NewServer(api)
+			)
+			defer server.Close()
+
+			var tombstones []models.Ledger
+			for _, uid := range []uuid.UUID{a, b} {
+				tombstone, err := models.BuildLedger(
+					models.WithResourceID(uid),
+					models.WithAuthorID("author"),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tombstones = append(tombstones, tombstone)
+			}
+
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
+
+			duration.EXPECT().WithLabelValues("POST", "/erase/", "200").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+			repo.EXPECT().EraseAuthorLedgers("author", repository.Query{}).Times(1).Return(tombstones, nil)
+
+			resp, err := http.Post(fmt.Sprintf("%s/erase/?author_id=author", server.URL), "application/json", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var ledgers []json.RawMessage
+			if err := json.NewDecoder(resp.Body).Decode(&ledgers); err != nil {
+				t.Fatal(err)
+			}
+
+			return resp.StatusCode == http.StatusOK &&
+				len(ledgers) == 2
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("erase with repo failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/erase/", "500").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().EraseAuthorLedgers("author", repository.Query{}).Times(1).Return(nil, errors.New("failure"))
+
+		resp, err := http.Post(fmt.Sprintf("%s/erase/?author_id=author", server.URL), "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusInternalServerError, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func TestQuotaAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("select quota", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/quotas/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().SelectQuota("author", repository.Query{}).Times(1).Return(models.Quota{
+			AuthorID: "author",
+			MaxBytes: 100,
+			Usage: models.QuotaUsage{
+				Bytes: 10,
+			},
+		}, nil)
+
+		resp, err := http.Get(fmt.Sprintf("%s/quotas/?author_id=author", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var quota struct {
+			AuthorID string `json:"author_id"`
+			MaxBytes int64  `json:"max_bytes"`
+			Usage    struct {
+				Bytes int64 `json:"bytes"`
+			} `json:"usage"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&quota); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "author", quota.AuthorID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := int64(100), quota.MaxBytes; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int64(10), quota.Usage.Bytes; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("update quota", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("PUT", "/quotas/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		quota := models.Quota{
+			MaxResources:       5,
+			MaxRevisionsPerDay: 10,
+		}
+		repo.EXPECT().UpdateQuota(quota, repository.Query{}).Times(1).Return(quota, nil)
+
+		body := strings.NewReader(`{"max_resources":5,"max_revisions_per_day":10}`)
+		req, err := http.NewRequest("PUT", fmt.Sprintf("%s/quotas/", server.URL), body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("update quota with negative limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("PUT", "/quotas/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		body := strings.NewReader(`{"max_bytes":-1}`)
+		req, err := http.NewRequest("PUT", fmt.Sprintf("%s/quotas/?author_id=author", server.URL), body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func TestExportAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("export", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithID(uuid.MustNew()),
+			models.WithAuthorID("author"),
+			models.WithResourceAddress("address"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := models.BuildContent(
+			models.WithAddress("address"),
+			models.WithSize(4),
+			models.WithReader(ioutil.NopCloser(strings.NewReader("body"))),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/export/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		authorID := "author"
+		repo.EXPECT().SelectResources(repository.Query{AuthorID: &authorID}).Times(1).Return([]uuid.UUID{doc.ResourceID()}, nil)
+		repo.EXPECT().SelectLedgers(doc.ResourceID(), repository.Query{}).Times(1).Return([]models.Ledger{doc}, nil)
+		repo.EXPECT().SelectRevisionContent(doc, repository.Query{}).Times(1).Return(content, nil)
+
+		resp, err := http.Get(fmt.Sprintf("%s/export/?author_id=author", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "1", resp.Header.Get("X-Ledgers"); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		var (
+			names  []string
+			reader = tar.NewReader(resp.Body)
+		)
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, header.Name)
+		}
+		if expected, actual := []string{archive.HeaderName, archive.ManifestName, archive.BlobPrefix + "address"}, names; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("export with invalid time range", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/export/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		resp, err := http.Get(fmt.Sprintf("%s/export/?since=yesterday", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("export with repo failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/export/", "500").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().SelectResources(repository.Query{}).Times(1).Return(nil, errors.New("failure"))
+
+		resp, err := http.Get(fmt.Sprintf("%s/export/", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusInternalServerError, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func TestImportAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("import", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		address, err := models.ContentAddress([]byte("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithID(uuid.MustNew()),
+			models.WithAuthorID("author"),
+			models.WithResourceAddress(address),
+			models.WithResourceSize(4),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/import/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().ImportLedgers(gomock.Any(), gomock.Any(), repository.Query{}).Times(1).Return([]models.Ledger{doc}, nil)
+
+		body := importArchive(t, []models.Ledger{doc}, map[string]string{address: "body"})
+		resp, err := http.Post(fmt.Sprintf("%s/import/", server.URL), "application/x-tar", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		var summary archive.ImportSummary
+		if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := (archive.ImportSummary{Ledgers: 1, Blobs: 1, Bytes: 4}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("import with corrupt blob", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithID(uuid.MustNew()),
+			models.WithResourceAddress("address"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/import/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		body := importArchive(t, []models.Ledger{doc}, map[string]string{"address": "body"})
+		resp, err := http.Post(fmt.Sprintf("%s/import/", server.URL), "application/x-tar", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("import with repo failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithID(uuid.MustNew()),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/import/", "500").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().ImportLedgers(gomock.Any(), gomock.Any(), repository.Query{}).Times(1).Return(nil, errors.New("failure"))
+
+		body := importArchive(t, []models.Ledger{doc}, nil)
+		resp, err := http.Post(fmt.Sprintf("%s/import/", server.URL), "application/x-tar", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusInternalServerError, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+// importArchive writes an archive of the ledgers and the blobs by address.
+func importArchive(t *testing.T, ledgers []models.Ledger, blobs map[string]string) io.Reader {
+	var (
+		manifest bytes.Buffer
+		encoder  = json.NewEncoder(&manifest)
+	)
+	for _, doc := range ledgers {
+		if err := encoder.Encode(archive.NewRecord(doc)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header, err := json.Marshal(archive.Header{Version: archive.Version, Ledgers: len(ledgers)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		buf    bytes.Buffer
+		writer = tar.NewWriter(&buf)
+	)
+	write := func(name string, body []byte) {
+		if err := writer.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(body)),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(archive.HeaderName, header)
+	write(archive.ManifestName, manifest.Bytes())
+	for address, body := range blobs {
+		write(archive.BlobPrefix+address, []byte(body))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+type errNotFound struct {
+	err error
+}
+
+func (e errNotFound) Error() string {
+	return e.err.Error()
+}
+
+func (e errNotFound) NotFound() bool {
+	return true
+}
+
+type errNotSupported struct {
+	err error
+}
+
+func (e errNotSupported) Error() string {
+	return e.err.Error()
+}
+
+func (e errNotSupported) NotSupported() bool {
+	return true
+}
@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/archive"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+const (
+	defaultContentType = "application/json"
+)
+
+// EraseQueryParams defines all the dimensions of a query. Either a resource_id
+// or a author_id is required, but not both.
+type EraseQueryParams struct {
+	ResourceID uuid.UUID `json:"resource_id"`
+	AuthorID   string    `json:"author_id"`
+}
+
+// DecodeFrom populates a EraseQueryParams from a URL.
+func (qp *EraseQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	var (
+		err        error
+		resourceID = u.Query().Get("resource_id")
+		authorID   = u.Query().Get("author_id")
+	)
+	if resourceID != "" && authorID != "" {
+		return errors.New("error reading 'resource_id' and 'author_id', only one is expected")
+	}
+	if rb == queryRequired && resourceID == "" && authorID == "" {
+		return errors.New("error reading 'resource_id' or 'author_id' (required) query")
+	}
+	if resourceID != "" {
+		if qp.ResourceID, err = uuid.Parse(resourceID); err != nil {
+			return errors.Wrap(err, "error parsing 'resource_id' query")
+		}
+	}
+	qp.AuthorID = authorID
+
+	return nil
+}
+
+// EraseQueryResult contains statistics about the query.
+type EraseQueryResult struct {
+	Errors   errs.Error
+	Params   EraseQueryParams `json:"query"`
+	Duration string           `json:"duration"`
+	Ledgers  []models.Ledger  `json:"ledgers"`
+}
+
+// EncodeTo encodes the EraseQueryResult to the HTTP response writer.
+func (qr *EraseQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	// Make sure that we encode empty ledgers correctly (i.e. they're not
+	// null in the json output)
+	docs := qr.Ledgers
+	if qr.Ledgers == nil {
+		docs = make([]models.Ledger, 0)
+	}
+
+	if err := json.NewEncoder(w).Encode(docs); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// QuotaQueryParams defines all the dimensions of a query. If the author_id
+// is empty, then the query is for the quota of the whole tenant.
+type QuotaQueryParams struct {
+	AuthorID string `json:"author_id"`
+}
+
+// DecodeFrom populates a QuotaQueryParams from a URL.
+func (qp *QuotaQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	qp.AuthorID = u.Query().Get("author_id")
+
+	return nil
+}
+
+// QuotaQueryResult contains statistics about the query.
+type QuotaQueryResult struct {
+	Errors   errs.Error
+	Params   QuotaQueryParams `json:"query"`
+	Duration string           `json:"duration"`
+	Quota    models.Quota     `json:"quota"`
+}
+
+// EncodeTo encodes the QuotaQueryResult to the HTTP response writer.
+func (qr *QuotaQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(qr.Quota); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ExportQueryParams defines all the dimensions of a query. Every one of them
+// is optional, without any of them the whole tenant is exported.
+type ExportQueryParams struct {
+	ResourceID uuid.UUID `json:"resource_id"`
+	Tags       []string  `json:"tags"`
+	AuthorID   string    `json:"author_id"`
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+}
+
+// DecodeFrom populates a ExportQueryParams from a URL.
+func (qp *ExportQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	var (
+		err        error
+		resourceID = u.Query().Get("resource_id")
+	)
+	if resourceID != "" {
+		if qp.ResourceID, err = uuid.Parse(resourceID); err != nil {
+			return errors.Wrap(err, "error parsing 'resource_id' query")
+		}
+	}
+	if tags := u.Query().Get("tags"); tags != "" {
+		qp.Tags = strings.Split(tags, ",")
+	}
+	qp.AuthorID = u.Query().Get("author_id")
+
+	if since := u.Query().Get("since"); since != "" {
+		if qp.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return errors.Wrap(err, "error parsing 'since' query")
+		}
+	}
+	if until := u.Query().Get("until"); until != "" {
+		if qp.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return errors.Wrap(err, "error parsing 'until' query")
+		}
+	}
+	if !qp.Since.IsZero() && !qp.Until.IsZero() && !qp.Since.Before(qp.Until) {
+		return errors.New("error reading 'since' and 'until', 'since' has to be before 'until'")
+	}
+
+	return nil
+}
+
+// ImportQueryResult contains statistics about the query.
+type ImportQueryResult struct {
+	Errors   errs.Error
+	Duration string                `json:"duration"`
+	Summary  archive.ImportSummary `json:"summary"`
+}
+
+// EncodeTo encodes the ImportQueryResult to the HTTP response writer.
+func (qr *ImportQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(qr.Summary); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+const (
+	httpHeaderContentType        = "Content-Type"
+	httpHeaderContentDisposition = "Content-Disposition"
+	httpHeaderDuration           = "X-Duration"
+	httpHeaderLedgers            = "X-Ledgers"
+
+	defaultArchiveContentType = "application/x-tar"
+)
+
+type queryBehavior int
+
+const (
+	queryRequired queryBehavior = iota
+	queryOptional
+)
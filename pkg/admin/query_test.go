@@ -0,0 +1,196 @@
+package admin
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+func TestEraseQueryParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DecodeFrom with required empty url", func(t *testing.T) {
+		var (
+			qp EraseQueryParams
+
+			u, err = url.Parse("")
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with optional empty url", func(t *testing.T) {
+		var (
+			qp EraseQueryParams
+
+			u, err = url.Parse("")
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryOptional)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with invalid resource_id", func(t *testing.T) {
+		var (
+			qp EraseQueryParams
+
+			u, err = url.Parse("/?resource_id=bad")
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with resource_id", func(t *testing.T) {
+		fn := func(uid uuid.UUID) bool {
+			var (
+				qp EraseQueryParams
+
+				u, err = url.Parse(fmt.Sprintf("/?resource_id=%s", uid))
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = qp.DecodeFrom(u, queryRequired); err != nil {
+				t.Fatal(err)
+			}
+
+			return qp.ResourceID.Equals(uid) && qp.AuthorID == ""
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("DecodeFrom with author_id", func(t *testing.T) {
+		fn := func(uid uuid.UUID) bool {
+			var (
+				qp EraseQueryParams
+
+				u, err = url.Parse(fmt.Sprintf("/?author_id=%s", uid))
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = qp.DecodeFrom(u, queryRequired); err != nil {
+				t.Fatal(err)
+			}
+
+			return qp.ResourceID.Zero() && qp.AuthorID == uid.String()
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("DecodeFrom with resource_id and author_id", func(t *testing.T) {
+		var (
+			qp EraseQueryParams
+
+			u, err = url.Parse(fmt.Sprintf("/?resource_id=%s&author_id=author", uuid.MustNew()))
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestExportQueryParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DecodeFrom with empty url", func(t *testing.T) {
+		var qp ExportQueryParams
+
+		u, err := url.Parse("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := qp.DecodeFrom(u, queryOptional); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("DecodeFrom with every query", func(t *testing.T) {
+		var (
+			qp         ExportQueryParams
+			resourceID = uuid.MustNew()
+		)
+
+		u, err := url.Parse(fmt.Sprintf("/?resource_id=%s&tags=a,b&author_id=alice&since=2017-01-01T00:00:00Z&until=2017-02-01T00:00:00Z", resourceID))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := qp.DecodeFrom(u, queryOptional); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := resourceID.String(), qp.ResourceID.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "a,b", strings.Join(qp.Tags, ","); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "alice", qp.AuthorID; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := time.Date(2017, 2, 1, 0, 0, 0, 0, time.UTC), qp.Until; !expected.Equal(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with invalid queries", func(t *testing.T) {
+		for _, query := range []string{
+			"/?resource_id=bad",
+			"/?since=yesterday",
+			"/?until=tomorrow",
+			"/?since=2017-02-01T00:00:00Z&until=2017-01-01T00:00:00Z",
+		} {
+			var qp ExportQueryParams
+
+			u, err := url.Parse(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := qp.DecodeFrom(u, queryOptional); err == nil {
+				t.Errorf("%s: expected an error", query)
+			}
+		}
+	})
+}
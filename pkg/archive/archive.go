@@ -0,0 +1,122 @@
+// Package archive writes the ledgers and content of a tenant to a portable
+// archive, and imports them again, to move the ledgers between deployments or
+// to take and restore a logical backup. An archive is a tar of a header, a
+// manifest of the ledgers as newline delimited json, and the content of the
+// ledgers by address.
+package archive
+
+import (
+	"time"
+
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+// Version is the version of the archive format that's written. Archives of
+// any other version are rejected when they're read.
+const Version = 1
+
+// These are the names of the entries of an archive. The header is always the
+// first entry, then the manifest, then a blob for every distinct content
+// address that could be read.
+const (
+	HeaderName   = "snowy.json"
+	ManifestName = "ledgers.ndjson"
+	BlobPrefix   = "blobs/"
+)
+
+// Header describes the archive.
+type Header struct {
+	Version   int       `json:"version"`
+	CreatedOn time.Time `json:"created_on"`
+	Ledgers   int       `json:"ledgers"`
+}
+
+// Record is a line of the manifest, a ledger with every field that's needed
+// to insert the ledger again exactly as it was. The times are kept at full
+// precision, as the hash chain of the ledgers covers them.
+type Record struct {
+	ID                  uuid.UUID `json:"id"`
+	ParentID            uuid.UUID `json:"parent_id"`
+	Name                string    `json:"name"`
+	ResourceID          uuid.UUID `json:"resource_id"`
+	ResourceAddress     string    `json:"resource_address"`
+	ResourceSize        int64     `json:"resource_size"`
+	ResourceContentType string    `json:"resource_content_type"`
+	AuthorID            string    `json:"author_id"`
+	Tags                []string  `json:"tags"`
+	CreatedOn           time.Time `json:"created_on"`
+	DeletedOn           time.Time `json:"deleted_on"`
+	Signature           []byte    `json:"signature,omitempty"`
+	SignatureKeyID      string    `json:"signature_key_id,omitempty"`
+}
+
+// NewRecord creates the Record of a ledger.
+func NewRecord(doc models.Ledger) Record {
+	tags := doc.Tags()
+	if tags == nil {
+		tags = make([]string, 0)
+	}
+
+	return Record{
+		ID:                  doc.ID(),
+		ParentID:            doc.ParentID(),
+		Name:                doc.Name(),
+		ResourceID:          doc.ResourceID(),
+		ResourceAddress:     doc.ResourceAddress(),
+		ResourceSize:        doc.ResourceSize(),
+		ResourceContentType: doc.ResourceContentType(),
+		AuthorID:            doc.AuthorID(),
+		Tags:                tags,
+		CreatedOn:           doc.CreatedOn(),
+		DeletedOn:           doc.DeletedOn(),
+		Signature:           doc.Signature(),
+		SignatureKeyID:      doc.SignatureKeyID(),
+	}
+}
+
+// Ledger creates the ledger of the Record, with in the tenant.
+func (r Record) Ledger(tenantID string) (models.Ledger, error) {
+	return models.BuildLedger(
+		models.WithID(r.ID),
+		models.WithParentID(r.ParentID),
+		models.WithTenantID(tenantID),
+		models.WithName(r.Name),
+		models.WithResourceID(r.ResourceID),
+		models.WithResourceAddress(r.ResourceAddress),
+		models.WithResourceSize(r.ResourceSize),
+		models.WithResourceContentType(r.ResourceContentType),
+		models.WithAuthorID(r.AuthorID),
+		models.WithTags(r.Tags),
+		models.WithCreatedOn(r.CreatedOn),
+		models.WithDeletedOn(r.DeletedOn),
+		models.WithSignature(r.SignatureKeyID, r.Signature),
+	)
+}
+
+type invalid interface {
+	Invalid() bool
+}
+
+type errInvalid struct {
+	err error
+}
+
+func (e errInvalid) Error() string {
+	return e.err.Error()
+}
+
+func (e errInvalid) Invalid() bool {
+	return true
+}
+
+// ErrInvalid tests to see if the error passed is an invalid error or not,
+// which is when an archive is malformed, incomplete or corrupt.
+func ErrInvalid(err error) bool {
+	if err != nil {
+		if _, ok := err.(invalid); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/uuid"
+)
+
+// Filter selects the ledgers to export. Resources are selected by id, or by
+// having a revision by the author and with any of the tags. Every revision of
+// a selected resource, created with in the time range, is exported. A zero
+// value of any of the fields doesn't filter.
+type Filter struct {
+	ResourceID uuid.UUID
+	Tags       []string
+	AuthorID   string
+
+	// Since is inclusive and Until is exclusive.
+	Since, Until time.Time
+}
+
+func (f Filter) contains(doc models.Ledger) bool {
+	createdOn := doc.CreatedOn()
+	if !f.Since.IsZero() && createdOn.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !createdOn.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// ExportSummary reports what was written to an archive.
+type ExportSummary struct {
+	Ledgers int   `json:"ledgers"`
+	Blobs   int   `json:"blobs"`
+	Bytes   int64 `json:"bytes"`
+
+	// Missing is the number of content addresses that were left out, as the
+	// content was erased or is missing from the blob store. The ledgers that
+	// refer to the content are still written.
+	Missing int `json:"missing"`
+}
+
+// Export is the ledgers selected for an archive, ready to be written.
+type Export struct {
+	repository repository.Repository
+	options    repository.Query
+	ledgers    []models.Ledger
+}
+
+// NewExport selects the ledgers of the filter from the repository, with in
+// the tenant of the options. If the options have a principal, then only the
+// resources the principal can read are selected. The ledgers are selected up
+// front, so that any error selecting them is known before anything is written.
+func NewExport(repo repository.Repository, filter Filter, options repository.Query) (*Export, error) {
+	query := repository.Query{
+		Tags:      filter.Tags,
+		Principal: options.Principal,
+		Tenant:    options.Tenant,
+	}
+	if filter.AuthorID != "" {
+		query.AuthorID = &filter.AuthorID
+	}
+
+	var resourceIDs []uuid.UUID
+	if filter.ResourceID.Zero() {
+		var err error
+		if resourceIDs, err = repo.SelectResources(query); err != nil {
+			return nil, err
+		}
+	} else {
+		matched, err := repo.SelectLedgers(filter.ResourceID, query)
+		if err != nil && !repository.ErrForbidden(err) {
+			return nil, err
+		}
+		if len(matched) > 0 {
+			resourceIDs = []uuid.UUID{filter.ResourceID}
+		}
+	}
+
+	// Every revision of the resource is selected, not only the ones that
+	// matched, so that the history of the resource is kept whole.
+	revisions := repository.Query{
+		Principal: options.Principal,
+		Tenant:    options.Tenant,
+	}
+
+	var ledgers []models.Ledger
+	for _, resourceID := range resourceIDs {
+		docs, err := repo.SelectLedgers(resourceID, revisions)
+		if err != nil {
+			// Resources the principal can't read are left out, rather than
+			// failing the whole export.
+			if repository.ErrForbidden(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, doc := range docs {
+			if filter.contains(doc) {
+				ledgers = append(ledgers, doc)
+			}
+		}
+	}
+
+	return &Export{
+		repository: repo,
+		options:    revisions,
+		ledgers:    ledgers,
+	}, nil
+}
+
+// Ledgers returns the number of ledgers that are exported.
+func (e *Export) Ledgers() int {
+	return len(e.ledgers)
+}
+
+// Write writes the archive to the writer. If writing fails part way, then the
+// archive is left incomplete, which is detected when it's read.
+func (e *Export) Write(w io.Writer) (ExportSummary, error) {
+	var (
+		now     = time.Now().UTC()
+		archive = tar.NewWriter(w)
+		summary = ExportSummary{Ledgers: len(e.ledgers)}
+	)
+
+	header, err := json.Marshal(Header{
+		Version:   Version,
+		CreatedOn: now,
+		Ledgers:   len(e.ledgers),
+	})
+	if err != nil {
+		return summary, err
+	}
+	if err = writeEntry(archive, HeaderName, now, bytes.NewReader(header), int64(len(header))); err != nil {
+		return summary, err
+	}
+
+	var manifest bytes.Buffer
+	encoder := json.NewEncoder(&manifest)
+	for _, doc := range e.ledgers {
+		if err = encoder.Encode(NewRecord(doc)); err != nil {
+			return summary, err
+		}
+	}
+	if err = writeEntry(archive, ManifestName, now, &manifest, int64(manifest.Len())); err != nil {
+		return summary, err
+	}
+
+	// Content is only stored once per address, so it's only written once.
+	written := make(map[string]struct{})
+	for _, doc := range e.ledgers {
+		address := doc.ResourceAddress()
+		if _, ok := written[address]; ok || address == "" {
+			continue
+		}
+		written[address] = struct{}{}
+
+		content, err := e.repository.SelectRevisionContent(doc, e.options)
+		if err != nil {
+			if repository.ErrErased(err) || repository.ErrNotFound(err) {
+				summary.Missing++
+				continue
+			}
+			return summary, err
+		}
+
+		err = writeEntry(archive, BlobPrefix+address, now, content.Reader(), content.Size())
+		content.Reader().Close()
+		if err != nil {
+			return summary, errors.Wrapf(err, "content %q", address)
+		}
+
+		summary.Blobs++
+		summary.Bytes += content.Size()
+	}
+
+	return summary, archive.Close()
+}
+
+func writeEntry(archive *tar.Writer, name string, modTime time.Time, r io.Reader, size int64) error {
+	if err := archive.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(archive, r)
+	return err
+}
@@ -0,0 +1,306 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/trussle/fsys"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/uuid"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("archive", func(t *testing.T) {
+		var (
+			repo = newRepository()
+			now  = time.Now()
+			a    = put(t, repo, "acme", uuid.UUID{}, "alice", []string{"red"}, now.Add(-time.Hour), "a")
+			b    = put(t, repo, "acme", a.ResourceID(), "bob", nil, now, "b")
+			c    = put(t, repo, "acme", uuid.UUID{}, "bob", nil, now, "a")
+		)
+		put(t, repo, "other", uuid.UUID{}, "alice", nil, now, "c")
+
+		entries, summary := export(t, repo, Filter{}, "acme")
+
+		if expected, actual := (ExportSummary{Ledgers: 3, Blobs: 2, Bytes: 2}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		var header Header
+		if err := json.Unmarshal(entries[0].body, &header); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := HeaderName, entries[0].name; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := Version, header.Version; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		if expected, actual := ManifestName, entries[1].name; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		records := manifest(t, entries[1].body)
+		if expected, actual := []uuid.UUID{a.ID(), b.ID(), c.ID()}, ids(records); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := a.ID(), records[1].ParentID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := a.CreatedOn(), records[0].CreatedOn; !expected.Equal(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		blobs := make(map[string]string)
+		for _, entry := range entries[2:] {
+			blobs[entry.name] = string(entry.body)
+		}
+		if expected, actual := map[string]string{
+			BlobPrefix + a.ResourceAddress(): "a",
+			BlobPrefix + b.ResourceAddress(): "b",
+		}, blobs; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		var (
+			repo = newRepository()
+			now  = time.Now()
+			a    = put(t, repo, "", uuid.UUID{}, "alice", []string{"red"}, now.Add(-time.Hour), "a")
+			b    = put(t, repo, "", a.ResourceID(), "bob", nil, now, "b")
+			c    = put(t, repo, "", uuid.UUID{}, "bob", []string{"blue"}, now, "c")
+		)
+
+		for _, test := range []struct {
+			name     string
+			filter   Filter
+			expected []uuid.UUID
+		}{
+			{"resource", Filter{ResourceID: c.ResourceID()}, []uuid.UUID{c.ID()}},
+			{"author", Filter{AuthorID: "alice"}, []uuid.UUID{a.ID(), b.ID()}},
+			{"tags", Filter{Tags: []string{"blue"}}, []uuid.UUID{c.ID()}},
+			{"since", Filter{Since: now}, []uuid.UUID{b.ID(), c.ID()}},
+			{"until", Filter{Until: now}, []uuid.UUID{a.ID()}},
+			{"none", Filter{AuthorID: "eve"}, nil},
+		} {
+			entries, _ := export(t, repo, test.filter, "")
+			if expected, actual := test.expected, ids(manifest(t, entries[1].body)); !reflect.DeepEqual(expected, actual) {
+				t.Errorf("%s: expected: %v, actual: %v", test.name, expected, actual)
+			}
+		}
+	})
+
+	t.Run("principal only exports readable resources", func(t *testing.T) {
+		var (
+			repo = newRepository()
+			now  = time.Now()
+			a    = put(t, repo, "", uuid.UUID{}, "alice", nil, now, "a")
+			b    = put(t, repo, "", uuid.UUID{}, "bob", nil, now, "b")
+		)
+
+		options, err := repository.BuildQuery(repository.WithQueryPrincipal("bob", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e, err := NewExport(repo, Filter{}, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, e.Ledgers(); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		e, err = NewExport(repo, Filter{ResourceID: a.ResourceID()}, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, e.Ledgers(); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		e, err = NewExport(repo, Filter{ResourceID: b.ResourceID()}, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, e.Ledgers(); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("erased content is missing", func(t *testing.T) {
+		var (
+			repo = newErasableRepository(t)
+			a    = put(t, repo, "", uuid.UUID{}, "alice", nil, time.Now(), "a")
+		)
+		if _, err := repo.EraseLedger(a.ResourceID(), repository.Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, summary := export(t, repo, Filter{}, "")
+
+		if expected, actual := (ExportSummary{Ledgers: 2, Missing: 1}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 2, len(entries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+type entry struct {
+	name string
+	body []byte
+}
+
+func newRepository(opts ...repository.Option) repository.Repository {
+	return repository.NewRealRepository(
+		repository.NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+		store.NewVirtualStore(),
+		log.NewNopLogger(),
+		opts...,
+	)
+}
+
+// newErasableRepository creates a repository with encryption, so that its
+// content can be erased.
+func newErasableRepository(t *testing.T) repository.Repository {
+	file, err := ioutil.TempFile("", "snowy-key-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(hex.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := repository.NewLocalKeyProvider(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newRepository(repository.WithEncryption(keys))
+}
+
+// put inserts a ledger of the body, or appends it to the resource if there
+// is one.
+func put(t *testing.T, repo repository.Repository, tenant string, resourceID uuid.UUID, authorID string, tags []string, createdOn time.Time, body string) models.Ledger {
+	options := repository.Query{Tenant: tenant}
+
+	content, err := models.BuildContent(
+		models.WithContentBytes([]byte(body)),
+		models.WithSize(int64(len(body))),
+		models.WithContentType("text/plain"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err = repo.PutContent(content, options); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []models.DocOption{
+		models.WithTenantID(tenant),
+		models.WithName("name"),
+		models.WithAuthorID(authorID),
+		models.WithTags(tags),
+		models.WithResourceAddress(content.Address()),
+		models.WithResourceSize(content.Size()),
+		models.WithResourceContentType(content.ContentType()),
+		models.WithCreatedOn(createdOn),
+	}
+	if resourceID.Zero() {
+		opts = append(opts, models.WithNewResourceID())
+	} else {
+		opts = append(opts, models.WithResourceID(resourceID))
+	}
+	doc, err := models.BuildLedger(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resourceID.Zero() {
+		doc, err = repo.InsertLedger(doc)
+	} else {
+		doc, err = repo.AppendLedger(resourceID, doc, options)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func export(t *testing.T, repo repository.Repository, filter Filter, tenant string) ([]entry, ExportSummary) {
+	e, err := NewExport(repo, filter, repository.Query{Tenant: tenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	summary, err := e.Write(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		entries []entry
+		reader  = tar.NewReader(&buf)
+	)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry{name: header.Name, body: body})
+	}
+	return entries, summary
+}
+
+func manifest(t *testing.T, body []byte) []Record {
+	var (
+		records []Record
+		scanner = bufio.NewScanner(bytes.NewReader(body))
+	)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func ids(records []Record) []uuid.UUID {
+	var res []uuid.UUID
+	for _, record := range records {
+		res = append(res, record.ID)
+	}
+	return res
+}
@@ -0,0 +1,253 @@
+package archive
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+)
+
+// ImportSummary reports what was read from an archive.
+type ImportSummary struct {
+	Ledgers int   `json:"ledgers"`
+	Blobs   int   `json:"blobs"`
+	Bytes   int64 `json:"bytes"`
+
+	// Duplicates is the number of ledgers that were skipped, as they already
+	// exist, so that importing the same archive again changes nothing.
+	Duplicates int `json:"duplicates"`
+
+	// Missing is the number of content addresses that the ledgers refer to,
+	// but that aren't in the archive, as the content was erased or missing
+	// when it was exported. The ledgers that refer to the content are still
+	// imported.
+	Missing int `json:"missing"`
+}
+
+// Import reads the archive and imports the content and the ledgers into the
+// repository, with in the tenant of the options. Every blob is verified
+// against its address as it's spooled, and nothing is imported until the
+// whole archive has been read, so that a corrupt or incomplete archive imports
+// nothing at all. The ledgers are then imported with in one transaction, along
+// with only the content that they refer to. The ledgers keep their ids, parent
+// ids and times, and parents are imported before their children, so that the
+// history of every resource is restored as it was.
+func Import(r io.Reader, repo repository.Repository, options repository.Query) (ImportSummary, error) {
+	var (
+		summary ImportSummary
+		archive = tar.NewReader(r)
+	)
+
+	var header Header
+	if err := readEntry(archive, HeaderName, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&header)
+	}); err != nil {
+		return summary, err
+	}
+	if header.Version != Version {
+		return summary, errInvalid{errors.Errorf("unsupported version %d, expected %d", header.Version, Version)}
+	}
+
+	var records []Record
+	if err := readEntry(archive, ManifestName, func(r io.Reader) error {
+		var err error
+		records, err = readManifest(r)
+		return err
+	}); err != nil {
+		return summary, err
+	}
+	if header.Ledgers != len(records) {
+		return summary, errInvalid{errors.Errorf("expected %d ledgers, got %d", header.Ledgers, len(records))}
+	}
+
+	ordered, err := orderRecords(records)
+	if err != nil {
+		return summary, err
+	}
+	docs := make([]models.Ledger, len(ordered))
+	for k, record := range ordered {
+		if docs[k], err = record.Ledger(options.Tenant); err != nil {
+			return summary, errInvalid{errors.Wrapf(err, "ledger %q", record.ID)}
+		}
+	}
+
+	contentTypes := make(map[string]string)
+	for _, record := range records {
+		if record.ResourceAddress != "" {
+			contentTypes[record.ResourceAddress] = record.ResourceContentType
+		}
+	}
+
+	// Rather than holding the blobs in memory, they're all spooled to one
+	// temporary file, so that they're only stored once the archive is known
+	// to be complete.
+	spool, err := ioutil.TempFile("", "snowy-import-")
+	if err != nil {
+		return summary, err
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	var contents []models.Content
+	for {
+		entry, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return summary, errInvalid{errors.Wrap(err, "blob")}
+		}
+
+		address := strings.TrimPrefix(entry.Name, BlobPrefix)
+		if _, ok := contentTypes[address]; !ok || address == entry.Name {
+			return summary, errInvalid{errors.Errorf("unexpected entry %q", entry.Name)}
+		}
+
+		size, err := spoolBlob(spool, address, archive)
+		if err != nil {
+			return summary, err
+		}
+
+		content, err := models.BuildContent(
+			models.WithAddress(address),
+			models.WithSize(size),
+			models.WithContentType(contentTypes[address]),
+			models.WithReader(ioutil.NopCloser(io.NewSectionReader(spool, summary.Bytes, size))),
+		)
+		if err != nil {
+			return summary, err
+		}
+		delete(contentTypes, address)
+		contents = append(contents, content)
+
+		summary.Blobs++
+		summary.Bytes += size
+	}
+	summary.Missing = len(contentTypes)
+
+	imported, err := repo.ImportLedgers(docs, contents, options)
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			return summary, errInvalid{errors.Wrap(err, "a parent is in neither the archive nor the tenant")}
+		}
+		return summary, err
+	}
+	summary.Ledgers = len(imported)
+	summary.Duplicates = len(docs) - len(imported)
+
+	return summary, nil
+}
+
+// readEntry reads the next entry of the archive, which must have the name.
+func readEntry(archive *tar.Reader, name string, fn func(io.Reader) error) error {
+	entry, err := archive.Next()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return errInvalid{errors.Wrap(err, name)}
+	}
+	if entry.Name != name {
+		return errInvalid{errors.Errorf("expected %q, got %q", name, entry.Name)}
+	}
+	if err = fn(archive); err != nil {
+		return errInvalid{errors.Wrap(err, name)}
+	}
+	return nil
+}
+
+func readManifest(r io.Reader) ([]Record, error) {
+	var (
+		records []Record
+		seen    = make(map[string]struct{})
+		decoder = json.NewDecoder(r)
+	)
+	for {
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			if err == io.EOF {
+				return records, nil
+			}
+			return nil, err
+		}
+		if record.ID.Zero() || record.ResourceID.Zero() {
+			return nil, errors.Errorf("ledger %d has no id", len(records))
+		}
+		if _, ok := seen[record.ID.String()]; ok {
+			return nil, errors.Errorf("ledger %q is repeated", record.ID)
+		}
+		seen[record.ID.String()] = struct{}{}
+		records = append(records, record)
+	}
+}
+
+// spoolBlob verifies the body against the address as it's appended to the
+// spool, returning the size of the body.
+func spoolBlob(spool io.Writer, address string, body io.Reader) (int64, error) {
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, hash), body)
+	if err != nil {
+		return 0, errInvalid{errors.Wrapf(err, "blob %q", address)}
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != address {
+		return 0, errInvalid{errors.Errorf("blob %q has the address %q", address, actual)}
+	}
+	return size, nil
+}
+
+// orderRecords orders the records so that every parent in the archive comes
+// before its children, keeping the order of the manifest otherwise.
+func orderRecords(records []Record) ([]Record, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	var (
+		ordered = make([]Record, 0, len(records))
+		byID    = make(map[string]Record, len(records))
+		state   = make(map[string]int, len(records))
+	)
+	for _, record := range records {
+		byID[record.ID.String()] = record
+	}
+
+	var visit func(Record) error
+	visit = func(record Record) error {
+		id := record.ID.String()
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			return errInvalid{errors.Errorf("ledger %q is its own ancestor", record.ID)}
+		}
+
+		state[id] = visiting
+		if parent, ok := byID[record.ParentID.String()]; ok {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+
+		ordered = append(ordered, record)
+		return nil
+	}
+
+	for _, record := range records {
+		if err := visit(record); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,245 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/uuid"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		var (
+			source = newRepository()
+			now    = time.Now()
+			a      = put(t, source, "acme", uuid.UUID{}, "alice", []string{"red"}, now.Add(-time.Hour), "a")
+			b      = put(t, source, "acme", a.ResourceID(), "bob", nil, now, "b")
+			c      = put(t, source, "acme", uuid.UUID{}, "bob", nil, now, "a")
+		)
+
+		entries, _ := export(t, source, Filter{}, "acme")
+
+		target := newRepository()
+		summary, err := Import(archiveOf(t, entries), target, repository.Query{Tenant: "other"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := (ImportSummary{Ledgers: 3, Blobs: 2, Bytes: 2}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		for _, doc := range []models.Ledger{a, c} {
+			options := repository.Query{Tenant: "other"}
+
+			verification, err := target.VerifyLedger(doc.ResourceID(), options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := true, verification.Valid; expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+
+			content, err := target.SelectContent(doc.ResourceID(), options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content.Reader().Close()
+		}
+
+		docs, err := target.SelectLedgers(a.ResourceID(), repository.Query{Tenant: "other"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ledgers []uuid.UUID
+		for _, doc := range docs {
+			ledgers = append(ledgers, doc.ID())
+		}
+		if expected, actual := []uuid.UUID{a.ID(), b.ID()}, ledgers; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := a.ID(), docs[1].ParentID(); !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := a.CreatedOn(), docs[0].CreatedOn(); !expected.Equal(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("import again skips duplicates", func(t *testing.T) {
+		var (
+			source = newRepository()
+			a      = put(t, source, "", uuid.UUID{}, "alice", nil, time.Now(), "a")
+		)
+		put(t, source, "", a.ResourceID(), "alice", nil, time.Now(), "b")
+
+		entries, _ := export(t, source, Filter{}, "")
+
+		target := newRepository()
+		if _, err := Import(archiveOf(t, entries), target, repository.Query{}); err != nil {
+			t.Fatal(err)
+		}
+		summary, err := Import(archiveOf(t, entries), target, repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := (ImportSummary{Duplicates: 2, Blobs: 2, Bytes: 2}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("children before parents", func(t *testing.T) {
+		var (
+			source = newRepository()
+			a      = put(t, source, "", uuid.UUID{}, "alice", nil, time.Now(), "a")
+			b      = put(t, source, "", a.ResourceID(), "alice", nil, time.Now(), "b")
+		)
+
+		entries, _ := export(t, source, Filter{}, "")
+		entries[1].body = encodeRecords(t, NewRecord(b), NewRecord(a))
+
+		target := newRepository()
+		if _, err := Import(archiveOf(t, entries), target, repository.Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		verification, err := target.VerifyLedger(a.ResourceID(), repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := true, verification.Valid; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		var (
+			source = newRepository()
+			a      = put(t, source, "", uuid.UUID{}, "alice", nil, time.Now(), "a")
+			b      = put(t, source, "", a.ResourceID(), "alice", nil, time.Now(), "a")
+		)
+
+		entries, _ := export(t, source, Filter{}, "")
+		header, _ := json.Marshal(Header{Version: Version, Ledgers: 1})
+		entries[0].body = header
+		entries[1].body = encodeRecords(t, NewRecord(b))
+
+		target := newRepository()
+		_, err := Import(archiveOf(t, entries), target, repository.Query{})
+		if expected, actual := true, ErrInvalid(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t (%v)", expected, actual, err)
+		}
+
+		resourceIDs, err := target.SelectResources(repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(resourceIDs); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("missing content", func(t *testing.T) {
+		var (
+			source = newErasableRepository(t)
+			a      = put(t, source, "", uuid.UUID{}, "alice", nil, time.Now(), "a")
+		)
+		if _, err := source.EraseLedger(a.ResourceID(), repository.Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, _ := export(t, source, Filter{}, "")
+
+		summary, err := Import(archiveOf(t, entries), newRepository(), repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := (ImportSummary{Ledgers: 2, Missing: 1}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		source := newRepository()
+		put(t, source, "", uuid.UUID{}, "alice", nil, time.Now(), "a")
+
+		valid, _ := export(t, source, Filter{}, "")
+
+		for _, test := range []struct {
+			name   string
+			modify func([]entry) []entry
+		}{
+			{"empty", func(entries []entry) []entry {
+				return nil
+			}},
+			{"version", func(entries []entry) []entry {
+				header, _ := json.Marshal(Header{Version: Version + 1, Ledgers: 1})
+				return append([]entry{{HeaderName, header}}, entries[1:]...)
+			}},
+			{"no manifest", func(entries []entry) []entry {
+				return entries[:1]
+			}},
+			{"ledger count", func(entries []entry) []entry {
+				header, _ := json.Marshal(Header{Version: Version, Ledgers: 2})
+				return append([]entry{{HeaderName, header}}, entries[1:]...)
+			}},
+			{"corrupt blob", func(entries []entry) []entry {
+				return append(entries[:2:2], entry{entries[2].name, []byte("b")})
+			}},
+			{"unexpected entry", func(entries []entry) []entry {
+				return append(entries[:2:2], entry{"other", []byte("a")})
+			}},
+		} {
+			target := newRepository()
+
+			_, err := Import(archiveOf(t, test.modify(valid)), target, repository.Query{})
+			if expected, actual := true, ErrInvalid(err); expected != actual {
+				t.Errorf("%s: expected: %t, actual: %t (%v)", test.name, expected, actual, err)
+			}
+
+			resourceIDs, err := target.SelectResources(repository.Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := 0, len(resourceIDs); expected != actual {
+				t.Errorf("%s: expected: %d, actual: %d", test.name, expected, actual)
+			}
+		}
+	})
+}
+
+func archiveOf(t *testing.T, entries []entry) *bytes.Buffer {
+	var (
+		buf     bytes.Buffer
+		archive = tar.NewWriter(&buf)
+	)
+	for _, entry := range entries {
+		if err := writeEntry(archive, entry.name, time.Now(), bytes.NewReader(entry.body), int64(len(entry.body))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func encodeRecords(t *testing.T, records ...Record) []byte {
+	var (
+		buf     bytes.Buffer
+		encoder = json.NewEncoder(&buf)
+	)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	errs "github.com/trussle/snowy/pkg/http"
+)
+
+// Admins are the principals that are allowed to administer the tenants, by
+// the id of the principal or by any of the groups the principal belongs to.
+type Admins struct {
+	Principals []string
+	Groups     []string
+}
+
+// Authorize checks that the principal of the context is an admin. If there is
+// no principal, then authentication is disabled and the request is allowed.
+func (a Admins) Authorize(ctx context.Context) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, id := range a.Principals {
+		if id != "" && id == principal.ID {
+			return nil
+		}
+	}
+	for _, group := range principal.Groups {
+		if group != "" && contains(a.Groups, group) {
+			return nil
+		}
+	}
+	return errForbidden{errors.Errorf("principal %q is not an admin", principal.ID)}
+}
+
+type adminMiddleware struct {
+	next   http.Handler
+	admins Admins
+	logger log.Logger
+	errors errs.Error
+}
+
+// NewAdminMiddleware creates a http.Handler that only passes requests on to
+// the next handler if the principal of the request is one of the admins.
+func NewAdminMiddleware(next http.Handler, admins Admins, logger log.Logger) http.Handler {
+	return &adminMiddleware{
+		next:   next,
+		admins: admins,
+		logger: logger,
+		errors: errs.NewError(logger),
+	}
+}
+
+func (m *adminMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := m.admins.Authorize(r.Context()); err != nil {
+		level.Warn(m.logger).Log("path", r.URL.Path, "err", err.Error())
+		m.errors.Forbidden(w, r, err.Error())
+		return
+	}
+	m.next.ServeHTTP(w, r)
+}
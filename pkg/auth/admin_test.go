@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var (
+		next = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		handler = NewAdminMiddleware(next, Admins{
+			Principals: []string{"alice"},
+			Groups:     []string{"ops"},
+		}, log.NewNopLogger())
+	)
+
+	for name, test := range map[string]struct {
+		principal *Principal
+		expected  int
+	}{
+		"no principal":    {nil, http.StatusOK},
+		"admin principal": {&Principal{ID: "alice"}, http.StatusOK},
+		"admin group":     {&Principal{ID: "bob", Groups: []string{"dev", "ops"}}, http.StatusOK},
+		"not an admin":    {&Principal{ID: "bob", Groups: []string{"dev"}}, http.StatusForbidden},
+		"empty principal": {&Principal{Groups: []string{""}}, http.StatusForbidden},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/admin/export/", nil)
+			if test.principal != nil {
+				r = r.WithContext(WithPrincipal(context.Background(), *test.principal))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if expected, actual := test.expected, w.Code; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+		})
+	}
+
+	t.Run("no admins", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/admin/export/", nil)
+		r = r.WithContext(WithPrincipal(context.Background(), Principal{ID: "alice"}))
+
+		w := httptest.NewRecorder()
+		NewAdminMiddleware(next, Admins{}, log.NewNopLogger()).ServeHTTP(w, r)
+
+		if expected, actual := http.StatusForbidden, w.Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const httpHeaderAPIKey = "X-Api-Key"
+
+type apiKeyProvider struct {
+	keys map[string]Principal
+}
+
+// NewAPIKeyProvider creates a Provider that authenticates requests with a
+// static API key in the X-Api-Key header. The keys are read from a file, where
+// each line holds a principal, its key, optionally a comma separated list of
+// the groups it belongs to ("-" for none) and optionally its tenant ("*" for
+// any), separated by whitespace. Blank lines and lines starting with a # are ignored.
+func NewAPIKeyProvider(path string) (Provider, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read api keys")
+	}
+
+	keys := make(map[string]Principal, len(lines))
+	for k, fields := range lines {
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, errors.Errorf("invalid api key entry %d", k+1)
+		}
+		keys[fields[1]] = Principal{
+			ID:     fields[0],
+			Groups: readGroups(fields[2:]),
+			Tenant: readTenant(fields),
+		}
+	}
+	return &apiKeyProvider{keys}, nil
+}
+
+func (p *apiKeyProvider) Name() string {
+	return "apikey"
+}
+
+func (p *apiKeyProvider) Authenticate(r *http.Request) (Principal, error) {
+	key := r.Header.Get(httpHeaderAPIKey)
+	if key == "" {
+		return Principal{}, errNoCredentials{errors.New("no api key")}
+	}
+
+	for k, principal := range p.keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return principal, nil
+		}
+	}
+	return Principal{}, errors.New("unknown api key")
+}
+
+// readLines reads the non empty, non comment lines of a file, splitting each
+// line in to its whitespace separated fields.
+func readLines(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var res [][]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		res = append(res, strings.Fields(line))
+	}
+	return res, scanner.Err()
+}
+
+// readGroups reads the optional comma separated groups field of a line, where
+// "-" is no groups.
+func readGroups(fields []string) []string {
+	if len(fields) == 0 || fields[0] == "-" {
+		return nil
+	}
+
+	var res []string
+	for _, v := range strings.Split(fields[0], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// readTenant reads the optional tenant field of a line, which follows the
+// principal, its credential and its groups.
+func readTenant(fields []string) string {
+	if len(fields) < 4 {
+		return ""
+	}
+	return fields[3]
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAPIKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	file, cleanup := tempFile(t, "# principals\nbob key-1\n\nalice key-2 auditors,admins\ncarol key-4 - acme\n")
+	defer cleanup()
+
+	p, err := NewAPIKeyProvider(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no credentials", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		_, err := p.Authenticate(r)
+		if expected, actual := true, ErrNoCredentials(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("known key", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(httpHeaderAPIKey, "key-2")
+
+		principal, err := p.Authenticate(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "alice", principal.ID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := []string{"auditors", "admins"}, principal.Groups; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("key with tenant", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(httpHeaderAPIKey, "key-4")
+
+		principal, err := p.Authenticate(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "acme", principal.Tenant; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := 0, len(principal.Groups); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(httpHeaderAPIKey, "key-3")
+
+		_, err := p.Authenticate(r)
+		if err == nil || ErrNoCredentials(err) {
+			t.Errorf("expected invalid credentials, actual: %v", err)
+		}
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		file, cleanup := tempFile(t, "bob\n")
+		defer cleanup()
+
+		if _, err := NewAPIKeyProvider(file); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	errs "github.com/trussle/snowy/pkg/http"
+)
+
+// Principal is the identity that a request has been authenticated as. The ID
+// is expected to match the author_id of any ledgers the principal writes. The
+// groups the principal belongs to are used by access control lists. If the
+// principal belongs to a tenant, then every request it makes is scoped to the
+// tenant. A principal without a tenant is scoped to the default tenant, unless
+// its tenant is AnyTenant, in which case it may name any tenant.
+type Principal struct {
+	ID       string
+	Groups   []string
+	Tenant   string
+	Provider string
+}
+
+// AnyTenant is the tenant of a principal that may access every tenant, by
+// naming it in the request.
+const AnyTenant = "*"
+
+// Provider authenticates a request, resolving the principal that made it.
+type Provider interface {
+
+	// Name returns the name of the provider.
+	Name() string
+
+	// Authenticate resolves the principal of the request. If the request
+	// doesn't carry any credentials for the provider, then a no credentials
+	// error is returned, so that the next provider can be tried.
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+type contextKey int
+
+const principalKey contextKey = iota
+
+// WithPrincipal returns a copy of the context with the principal attached.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext returns the principal attached to the context, if there
+// is one.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
+
+// AuthorizeAuthor checks that the principal of the context is allowed to write
+// as the author. If there is no principal, then authentication is disabled
+// and the write is allowed.
+func AuthorizeAuthor(ctx context.Context, authorID string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if principal.ID != authorID {
+		return errForbidden{errors.Errorf("principal %q can not write as author %q", principal.ID, authorID)}
+	}
+	return nil
+}
+
+type middleware struct {
+	next      http.Handler
+	providers []Provider
+	open      []string
+	logger    log.Logger
+	errors    errs.Error
+}
+
+// MiddlewareOption defines a option for configuring the middleware.
+type MiddlewareOption func(*middleware)
+
+// WithOpenPath allows any request with a path that has the prefix through,
+// without requiring authentication.
+func WithOpenPath(prefix string) MiddlewareOption {
+	return func(m *middleware) {
+		m.open = append(m.open, prefix)
+	}
+}
+
+// NewMiddleware creates a http.Handler that authenticates every request using
+// the providers, in order, before passing the request on to the next handler
+// with the principal attached to the request context.
+func NewMiddleware(next http.Handler, providers []Provider, logger log.Logger, opts ...MiddlewareOption) http.Handler {
+	m := &middleware{
+		next:      next,
+		providers: providers,
+		logger:    logger,
+		errors:    errs.NewError(logger),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, prefix := range m.open {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			m.next.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	for _, provider := range m.providers {
+		principal, err := provider.Authenticate(r)
+		if err != nil {
+			if ErrNoCredentials(err) {
+				continue
+			}
+			level.Warn(m.logger).Log("provider", provider.Name(), "err", err.Error())
+			m.errors.Unauthorized(w, r, "invalid credentials")
+			return
+		}
+
+		principal.Provider = provider.Name()
+		m.next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		return
+	}
+
+	m.errors.Unauthorized(w, r, "missing credentials")
+}
+
+type noCredentials interface {
+	NoCredentials() bool
+}
+
+type errNoCredentials struct {
+	err error
+}
+
+func (e errNoCredentials) Error() string {
+	return e.err.Error()
+}
+
+func (e errNoCredentials) NoCredentials() bool {
+	return true
+}
+
+// ErrNoCredentials tests to see if the error passed is a no credentials error
+// or not.
+func ErrNoCredentials(err error) bool {
+	if err != nil {
+		if _, ok := err.(noCredentials); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type forbidden interface {
+	Forbidden() bool
+}
+
+type errForbidden struct {
+	err error
+}
+
+func (e errForbidden) Error() string {
+	return e.err.Error()
+}
+
+func (e errForbidden) Forbidden() bool {
+	return true
+}
+
+// ErrForbidden tests to see if the error passed is a forbidden error or not.
+func ErrForbidden(err error) bool {
+	if err != nil {
+		if _, ok := err.(forbidden); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestAuthorizeAuthor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no principal", func(t *testing.T) {
+		if err := AuthorizeAuthor(context.Background(), "bob"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("matching principal", func(t *testing.T) {
+		ctx := WithPrincipal(context.Background(), Principal{ID: "bob"})
+		if err := AuthorizeAuthor(ctx, "bob"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("mismatched principal", func(t *testing.T) {
+		ctx := WithPrincipal(context.Background(), Principal{ID: "alice"})
+		err := AuthorizeAuthor(ctx, "bob")
+		if expected, actual := true, ErrForbidden(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	file, cleanup := tempFile(t, "bob secret-key\n")
+	defer cleanup()
+
+	provider, err := NewAPIKeyProvider(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var principal Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := NewMiddleware(next, []Provider{provider}, log.NewNopLogger(), WithOpenPath("/status/"))
+
+	t.Run("missing credentials", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		handler.ServeHTTP(w, r)
+
+		if expected, actual := http.StatusUnauthorized, w.Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		r.Header.Set(httpHeaderAPIKey, "bad-key")
+		handler.ServeHTTP(w, r)
+
+		if expected, actual := http.StatusUnauthorized, w.Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("valid credentials", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		r.Header.Set(httpHeaderAPIKey, "secret-key")
+		handler.ServeHTTP(w, r)
+
+		if expected, actual := http.StatusOK, w.Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := (Principal{ID: "bob", Provider: "apikey"}), principal; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("open path", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/status/health", nil)
+		handler.ServeHTTP(w, r)
+
+		if expected, actual := http.StatusOK, w.Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func tempFile(t *testing.T, contents string) (string, func()) {
+	file, err := ioutil.TempFile("", "snowy-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name(), func() {
+		os.Remove(file.Name())
+	}
+}
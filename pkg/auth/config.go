@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Config encapsulates the requirements for generating the Providers
+type Config struct {
+	providers   []string
+	apiKeyFile  string
+	hmacFile    string
+	jwksFile    string
+	jwtIssuer   string
+	jwtAudience string
+}
+
+// Option defines a option for generating a auth Config
+type Option func(*Config) error
+
+// Build ingests configuration options to then yield a Config and return an
+// error if it fails during setup.
+func Build(opts ...Option) (*Config, error) {
+	var config Config
+	for _, opt := range opts {
+		err := opt(&config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// WithProviders adds the names of the providers to use, in the order they're
+// tried (apikey, hmac, jwt).
+func WithProviders(providers []string) Option {
+	return func(config *Config) error {
+		for _, v := range providers {
+			if v = strings.TrimSpace(v); v != "" {
+				config.providers = append(config.providers, v)
+			}
+		}
+		return nil
+	}
+}
+
+// WithAPIKeyFile adds the file of API keys to use for the apikey provider.
+func WithAPIKeyFile(path string) Option {
+	return func(config *Config) error {
+		config.apiKeyFile = path
+		return nil
+	}
+}
+
+// WithHMACFile adds the file of shared secrets to use for the hmac provider.
+func WithHMACFile(path string) Option {
+	return func(config *Config) error {
+		config.hmacFile = path
+		return nil
+	}
+}
+
+// WithJWT adds the JWKS file, issuer and audience to use for the jwt provider.
+func WithJWT(jwksFile, issuer, audience string) Option {
+	return func(config *Config) error {
+		config.jwksFile = jwksFile
+		config.jwtIssuer = issuer
+		config.jwtAudience = audience
+		return nil
+	}
+}
+
+// Enabled returns if any providers are configured.
+func (c *Config) Enabled() bool {
+	return len(c.providers) > 0
+}
+
+// New creates the Providers from a configuration or returns error if on
+// failure.
+func New(config *Config) ([]Provider, error) {
+	res := make([]Provider, 0, len(config.providers))
+	for _, name := range config.providers {
+		var (
+			provider Provider
+			err      error
+		)
+		switch strings.ToLower(name) {
+		case "apikey":
+			if config.apiKeyFile == "" {
+				return nil, errors.New("apikey provider requires a key file")
+			}
+			provider, err = NewAPIKeyProvider(config.apiKeyFile)
+		case "hmac":
+			if config.hmacFile == "" {
+				return nil, errors.New("hmac provider requires a secrets file")
+			}
+			provider, err = NewHMACProvider(config.hmacFile)
+		case "jwt":
+			if config.jwksFile == "" {
+				return nil, errors.New("jwt provider requires a jwks file")
+			}
+			provider, err = NewJWTProvider(config.jwksFile, config.jwtIssuer, config.jwtAudience)
+		default:
+			err = errors.Errorf("unexpected auth provider %q", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, provider)
+	}
+	return res, nil
+}
@@ -0,0 +1,74 @@
+package auth
+
+import "testing"
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled", func(t *testing.T) {
+		config, err := Build(WithProviders([]string{""}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := false, config.Enabled(); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		providers, err := New(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(providers); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("providers", func(t *testing.T) {
+		keys, cleanupKeys := tempFile(t, "bob key\n")
+		defer cleanupKeys()
+		secrets, cleanupSecrets := tempFile(t, "bob 736563726574\n")
+		defer cleanupSecrets()
+
+		config, err := Build(
+			WithProviders([]string{"hmac", " apikey"}),
+			WithAPIKeyFile(keys),
+			WithHMACFile(secrets),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		providers, err := New(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, v := range providers {
+			names = append(names, v.Name())
+		}
+		if expected, actual := "hmac,apikey", names[0]+","+names[1]; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		config, err := Build(WithProviders([]string{"jwt"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(config); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		config, err := Build(WithProviders([]string{"basic"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(config); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
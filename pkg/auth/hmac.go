@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	httpHeaderAuthorization = "Authorization"
+	httpHeaderTimestamp     = "X-Snowy-Timestamp"
+	httpHeaderContentSHA256 = "X-Snowy-Content-SHA256"
+
+	hmacScheme = "HMAC-SHA256"
+
+	defaultHMACSkew = 5 * time.Minute
+)
+
+type hmacProvider struct {
+	secrets map[string][]byte
+	groups  map[string][]string
+	tenants map[string]string
+	skew    time.Duration
+	now     func() time.Time
+}
+
+// NewHMACProvider creates a Provider that authenticates requests signed with
+// a shared secret. The secrets are read from a file, where each line holds a
+// principal, its hex encoded secret, optionally a comma separated list of the
+// groups it belongs to ("-" for none) and optionally its tenant, separated by
+// whitespace.
+//
+// A signed request carries the unix time it was signed at in the
+// X-Snowy-Timestamp header, the hex encoded SHA-256 of its body in the
+// X-Snowy-Content-SHA256 header and the signature in the Authorization
+// header, as "HMAC-SHA256 <principal>:<hex signature>". See SignRequest for
+// what is signed. The body isn't read to authenticate the request, instead it
+// is checked against the signed digest as it is read, so that a body that
+// doesn't match fails to be read to the end.
+func NewHMACProvider(path string) (Provider, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read hmac secrets")
+	}
+
+	var (
+		secrets = make(map[string][]byte, len(lines))
+		groups  = make(map[string][]string, len(lines))
+		tenants = make(map[string]string, len(lines))
+	)
+	for k, fields := range lines {
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, errors.Errorf("invalid hmac secret entry %d", k+1)
+		}
+		secret, err := hex.DecodeString(fields[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid hmac secret entry %d", k+1)
+		}
+		secrets[fields[0]] = secret
+		groups[fields[0]] = readGroups(fields[2:])
+		tenants[fields[0]] = readTenant(fields)
+	}
+	return &hmacProvider{
+		secrets: secrets,
+		groups:  groups,
+		tenants: tenants,
+		skew:    defaultHMACSkew,
+		now:     time.Now,
+	}, nil
+}
+
+func (p *hmacProvider) Name() string {
+	return "hmac"
+}
+
+func (p *hmacProvider) Authenticate(r *http.Request) (Principal, error) {
+	header := r.Header.Get(httpHeaderAuthorization)
+	if !strings.HasPrefix(header, hmacScheme+" ") {
+		return Principal{}, errNoCredentials{errors.New("no hmac signature")}
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(header, hmacScheme+" "), ":", 2)
+	if len(parts) != 2 {
+		return Principal{}, errors.New("malformed hmac signature")
+	}
+	principal, signature := parts[0], parts[1]
+
+	secret, ok := p.secrets[principal]
+	if !ok {
+		return Principal{}, errors.Errorf("unknown hmac principal %q", principal)
+	}
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(httpHeaderTimestamp), 10, 64)
+	if err != nil {
+		return Principal{}, errors.Wrap(err, "invalid hmac timestamp")
+	}
+	if skew := p.now().Sub(time.Unix(timestamp, 0)); skew > p.skew || skew < -p.skew {
+		return Principal{}, errors.New("hmac timestamp outside of the allowed skew")
+	}
+
+	digest := r.Header.Get(httpHeaderContentSHA256)
+	sum, err := hex.DecodeString(digest)
+	if err != nil || len(sum) != sha256.Size {
+		return Principal{}, errors.New("invalid hmac content digest")
+	}
+
+	expected := sign(r, secret, timestamp, digest)
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return Principal{}, errors.Wrap(err, "invalid hmac signature")
+	}
+	if !hmac.Equal(expected, actual) {
+		return Principal{}, errors.New("invalid hmac signature")
+	}
+
+	if r.Body != nil {
+		r.Body = &digestReader{
+			ReadCloser: r.Body,
+			hash:       sha256.New(),
+			sum:        sum,
+		}
+	}
+	return Principal{
+		ID:     principal,
+		Groups: p.groups[principal],
+		Tenant: p.tenants[principal],
+	}, nil
+}
+
+// SignRequest signs the request with the shared secret of the principal, by
+// setting the X-Snowy-Timestamp, X-Snowy-Content-SHA256 and Authorization
+// headers. The signature is a HMAC-SHA256 over the method, the request URI,
+// the timestamp and the hex encoded SHA-256 of the body, each separated by a
+// new line.
+func SignRequest(r *http.Request, principal string, secret []byte, now time.Time) error {
+	// The body has to be read to be digested, so replace it so that it can be
+	// read again.
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return errors.Wrap(err, "unable to read body")
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	sum := sha256.Sum256(body)
+
+	var (
+		timestamp = now.Unix()
+		digest    = hex.EncodeToString(sum[:])
+	)
+	r.Header.Set(httpHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	r.Header.Set(httpHeaderContentSHA256, digest)
+	r.Header.Set(httpHeaderAuthorization, fmt.Sprintf("%s %s:%s", hmacScheme, principal, hex.EncodeToString(sign(r, secret, timestamp, digest))))
+	return nil
+}
+
+func sign(r *http.Request, secret []byte, timestamp int64, digest string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s", r.Method, r.URL.RequestURI(), timestamp, digest)
+	return mac.Sum(nil)
+}
+
+// digestReader checks that the body matches the digest it was signed with,
+// once the body has been read to the end.
+type digestReader struct {
+	io.ReadCloser
+	hash hash.Hash
+	sum  []byte
+}
+
+func (r *digestReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(r.hash.Sum(nil), r.sum) {
+		return n, errors.New("body does not match the hmac content digest")
+	}
+	return n, err
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHMACProvider(t *testing.T) {
+	t.Parallel()
+
+	file, cleanup := tempFile(t, "# principals\nbob 736563726574\n")
+	defer cleanup()
+
+	p, err := NewHMACProvider(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	p.(*hmacProvider).now = func() time.Time { return now }
+
+	t.Run("no credentials", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		_, err := p.Authenticate(r)
+		if expected, actual := true, ErrNoCredentials(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/ledgers/?resource_id=1", bytes.NewBufferString("body"))
+		if err := SignRequest(r, "bob", []byte("secret"), now); err != nil {
+			t.Fatal(err)
+		}
+
+		principal, err := p.Authenticate(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "bob", principal.ID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "body", string(body); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/ledgers/", bytes.NewBufferString("body"))
+		if err := SignRequest(r, "bob", []byte("secret"), now); err != nil {
+			t.Fatal(err)
+		}
+		r.Body = ioutil.NopCloser(bytes.NewBufferString("other"))
+
+		// The body is only checked as it's read, so that it isn't buffered.
+		if _, err := p.Authenticate(r); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ioutil.ReadAll(r.Body); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("tampered content digest", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/ledgers/", bytes.NewBufferString("body"))
+		if err := SignRequest(r, "bob", []byte("secret"), now); err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256([]byte("other"))
+		r.Header.Set(httpHeaderContentSHA256, hex.EncodeToString(sum[:]))
+		r.Body = ioutil.NopCloser(bytes.NewBufferString("other"))
+
+		if _, err := p.Authenticate(r); err == nil || ErrNoCredentials(err) {
+			t.Errorf("expected invalid credentials, actual: %v", err)
+		}
+	})
+
+	t.Run("missing content digest", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		if err := SignRequest(r, "bob", []byte("secret"), now); err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Del(httpHeaderContentSHA256)
+
+		if _, err := p.Authenticate(r); err == nil || ErrNoCredentials(err) {
+			t.Errorf("expected invalid credentials, actual: %v", err)
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		if err := SignRequest(r, "bob", []byte("other"), now); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := p.Authenticate(r); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("skewed timestamp", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		if err := SignRequest(r, "bob", []byte("secret"), now.Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := p.Authenticate(r); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+)
+
+const bearerScheme = "Bearer"
+
+type jwtProvider struct {
+	keys     map[string]jwk
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTProvider creates a Provider that authenticates requests with a JWT
+// bearer token, validated against the keys of a local JWKS file. The sub claim
+// of the token is used as the principal, the groups claim as the groups it
+// belongs to and the tenant claim as its tenant. If the issuer or audience are not empty, then the iss and aud
+// claims of the token must match them.
+//
+// Tokens signed with RS256, ES256 and EdDSA (Ed25519) are supported.
+func NewJWTProvider(jwksPath, issuer, audience string) (Provider, error) {
+	bytes, err := ioutil.ReadFile(jwksPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read jwks")
+	}
+
+	keys, err := parseJWKS(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &jwtProvider{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}, nil
+}
+
+func (p *jwtProvider) Name() string {
+	return "jwt"
+}
+
+func (p *jwtProvider) Authenticate(r *http.Request) (Principal, error) {
+	header := r.Header.Get(httpHeaderAuthorization)
+	if !strings.HasPrefix(header, bearerScheme+" ") {
+		return Principal{}, errNoCredentials{errors.New("no bearer token")}
+	}
+
+	claims, err := p.verify(strings.TrimSpace(strings.TrimPrefix(header, bearerScheme+" ")))
+	if err != nil {
+		return Principal{}, err
+	}
+	return Principal{
+		ID:     claims.Subject,
+		Groups: claims.Groups,
+		Tenant: claims.Tenant,
+	}, nil
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt int64       `json:"exp"`
+	NotBefore int64       `json:"nbf"`
+	Groups    []string    `json:"groups"`
+	Tenant    string      `json:"tenant"`
+}
+
+// jwtAudience is either a single audience or a list of audiences.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return err
+	}
+	*a = jwtAudience(multiple)
+	return nil
+}
+
+func (p *jwtProvider) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return jwtClaims{}, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return jwtClaims{}, errors.Wrap(err, "malformed token header")
+	}
+
+	key, ok := p.keys[header.KeyID]
+	if !ok {
+		return jwtClaims{}, errors.Errorf("unknown key %q", header.KeyID)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return jwtClaims{}, errors.Wrap(err, "malformed token signature")
+	}
+	if err := key.verify(header.Algorithm, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return jwtClaims{}, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return jwtClaims{}, errors.Wrap(err, "malformed token claims")
+	}
+
+	now := p.now().Unix()
+	if claims.ExpiresAt == 0 || now >= claims.ExpiresAt {
+		return jwtClaims{}, errors.New("token has expired")
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return jwtClaims{}, errors.New("token is not valid yet")
+	}
+	if p.issuer != "" && claims.Issuer != p.issuer {
+		return jwtClaims{}, errors.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if p.audience != "" && !contains(claims.Audience, p.audience) {
+		return jwtClaims{}, errors.New("unexpected audience")
+	}
+	if claims.Subject == "" {
+		return jwtClaims{}, errors.New("token has no subject")
+	}
+	return claims, nil
+}
+
+// jwk is a public key from a JWKS, along with the algorithm it's for.
+type jwk struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+func (k jwk) verify(algorithm string, data, signature []byte) error {
+	if algorithm != k.algorithm {
+		return errors.Errorf("unexpected algorithm %q", algorithm)
+	}
+
+	switch key := k.key.(type) {
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], signature); err != nil {
+			return errors.New("invalid token signature")
+		}
+	case *ecdsa.PublicKey:
+		if len(signature) != 64 {
+			return errors.New("invalid token signature")
+		}
+		sum := sha256.Sum256(data)
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(key, sum[:], r, s) {
+			return errors.New("invalid token signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, signature) {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return errors.New("unsupported key")
+	}
+	return nil
+}
+
+func parseJWKS(b []byte) (map[string]jwk, error) {
+	var jwks struct {
+		Keys []struct {
+			KeyID string `json:"kid"`
+			Type  string `json:"kty"`
+			Curve string `json:"crv"`
+			N     string `json:"n"`
+			E     string `json:"e"`
+			X     string `json:"x"`
+			Y     string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &jwks); err != nil {
+		return nil, errors.Wrap(err, "malformed jwks")
+	}
+
+	res := make(map[string]jwk, len(jwks.Keys))
+	for _, v := range jwks.Keys {
+		switch {
+		case v.Type == "RSA":
+			n, err := decodeBigInt(v.N)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid key %q", v.KeyID)
+			}
+			e, err := decodeBigInt(v.E)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid key %q", v.KeyID)
+			}
+			res[v.KeyID] = jwk{"RS256", &rsa.PublicKey{N: n, E: int(e.Int64())}}
+
+		case v.Type == "EC" && v.Curve == "P-256":
+			x, err := decodeBigInt(v.X)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid key %q", v.KeyID)
+			}
+			y, err := decodeBigInt(v.Y)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid key %q", v.KeyID)
+			}
+			if !elliptic.P256().IsOnCurve(x, y) {
+				return nil, errors.Errorf("invalid key %q", v.KeyID)
+			}
+			res[v.KeyID] = jwk{"ES256", &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}
+
+		case v.Type == "OKP" && v.Curve == "Ed25519":
+			x, err := base64.RawURLEncoding.DecodeString(v.X)
+			if err != nil || len(x) != ed25519.PublicKeySize {
+				return nil, errors.Errorf("invalid key %q", v.KeyID)
+			}
+			res[v.KeyID] = jwk{"EdDSA", ed25519.PublicKey(x)}
+
+		default:
+			return nil, errors.Errorf("unsupported key %q", v.KeyID)
+		}
+	}
+	return res, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestJWTProvider(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kid": "rsa", "kty": "RSA", "n": encodeBigInt(rsaKey.N), "e": encodeBigInt(big.NewInt(int64(rsaKey.E)))},
+			{"kid": "ec", "kty": "EC", "crv": "P-256", "x": encodeBigInt(ecKey.X), "y": encodeBigInt(ecKey.Y)},
+			{"kid": "ed", "kty": "OKP", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(edPublic)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, cleanup := tempFile(t, string(jwks))
+	defer cleanup()
+
+	p, err := NewJWTProvider(file, "snowy-issuer", "snowy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	p.(*jwtProvider).now = func() time.Time { return now }
+
+	claims := func(sub string) map[string]interface{} {
+		return map[string]interface{}{
+			"sub": sub,
+			"iss": "snowy-issuer",
+			"aud": []string{"other", "snowy"},
+			"exp": now.Add(time.Minute).Unix(),
+		}
+	}
+
+	signers := map[string]func(data []byte) []byte{
+		"RS256": func(data []byte) []byte {
+			sum := sha256.Sum256(data)
+			sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sum[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return sig
+		},
+		"ES256": func(data []byte) []byte {
+			sum := sha256.Sum256(data)
+			r, s, err := ecdsa.Sign(rand.Reader, ecKey, sum[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig := make([]byte, 64)
+			rb, sb := r.Bytes(), s.Bytes()
+			copy(sig[32-len(rb):32], rb)
+			copy(sig[64-len(sb):], sb)
+			return sig
+		},
+		"EdDSA": func(data []byte) []byte {
+			return ed25519.Sign(edPrivate, data)
+		},
+	}
+	kids := map[string]string{"RS256": "rsa", "ES256": "ec", "EdDSA": "ed"}
+
+	for alg, sign := range signers {
+		alg, sign := alg, sign
+		t.Run(alg, func(t *testing.T) {
+			token := signToken(t, alg, kids[alg], claims("bob"), sign)
+			principal, err := p.Authenticate(bearer(token))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := "bob", principal.ID; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		})
+	}
+
+	t.Run("groups", func(t *testing.T) {
+		c := claims("bob")
+		c["groups"] = []string{"auditors"}
+
+		token := signToken(t, "EdDSA", "ed", c, signers["EdDSA"])
+		principal, err := p.Authenticate(bearer(token))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := []string{"auditors"}, principal.Groups; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("tenant", func(t *testing.T) {
+		c := claims("bob")
+		c["tenant"] = "acme"
+
+		token := signToken(t, "EdDSA", "ed", c, signers["EdDSA"])
+		principal, err := p.Authenticate(bearer(token))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "acme", principal.Tenant; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		_, err := p.Authenticate(httptest.NewRequest("GET", "/", nil))
+		if expected, actual := true, ErrNoCredentials(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		c := claims("bob")
+		c["exp"] = now.Add(-time.Minute).Unix()
+
+		token := signToken(t, "EdDSA", "ed", c, signers["EdDSA"])
+		if _, err := p.Authenticate(bearer(token)); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("unexpected issuer", func(t *testing.T) {
+		c := claims("bob")
+		c["iss"] = "other"
+
+		token := signToken(t, "EdDSA", "ed", c, signers["EdDSA"])
+		if _, err := p.Authenticate(bearer(token)); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("unexpected audience", func(t *testing.T) {
+		c := claims("bob")
+		c["aud"] = "other"
+
+		token := signToken(t, "EdDSA", "ed", c, signers["EdDSA"])
+		if _, err := p.Authenticate(bearer(token)); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("algorithm mismatch", func(t *testing.T) {
+		token := signToken(t, "EdDSA", "rsa", claims("bob"), signers["EdDSA"])
+		if _, err := p.Authenticate(bearer(token)); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("tampered claims", func(t *testing.T) {
+		token := signToken(t, "EdDSA", "ed", claims("bob"), signers["EdDSA"])
+		forged := signToken(t, "EdDSA", "ed", claims("alice"), signers["EdDSA"])
+
+		parts, forgedParts := splitToken(token), splitToken(forged)
+		if _, err := p.Authenticate(bearer(parts[0] + "." + forgedParts[1] + "." + parts[2])); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func signToken(t *testing.T, alg, kid string, claims map[string]interface{}, sign func([]byte) []byte) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return data + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(data)))
+}
+
+func splitToken(token string) []string {
+	return strings.Split(token, ".")
+}
+
+func bearer(token string) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(httpHeaderAuthorization, bearerScheme+" "+token)
+	return r
+}
+
+func encodeBigInt(v *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(v.Bytes())
+}
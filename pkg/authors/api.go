@@ -0,0 +1,199 @@
+package authors
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/auth"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/snowy/pkg/trace"
+)
+
+// These are the authors API URL paths.
+const (
+	APIPathKeysQuery = "/keys/"
+)
+
+// API serves the authors API
+type API struct {
+	handler    *mux.Router
+	repository repository.Repository
+	logger     log.Logger
+	clients    metrics.Gauge
+	duration   metrics.HistogramVec
+	errors     errs.Error
+}
+
+// NewAPI creates a API with correct dependencies.
+func NewAPI(repository repository.Repository, logger log.Logger,
+	clients metrics.Gauge,
+	duration metrics.HistogramVec,
+) *API {
+	api := &API{
+		repository: repository,
+		logger:     logger,
+		clients:    clients,
+		duration:   duration,
+		errors:     errs.NewError(logger),
+	}
+	{
+		router := mux.NewRouter().StrictSlash(true)
+		router.Methods("GET").Path(APIPathKeysQuery).HandlerFunc(api.handleSelectKeys)
+		router.Methods("POST").Path(APIPathKeysQuery).HandlerFunc(api.handleInsertKey)
+		router.NotFoundHandler = http.HandlerFunc(api.errors.NotFound)
+
+		api.handler = router
+	}
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	level.Info(trace.Logger(r.Context(), a.logger)).Log("url", r.URL.String())
+
+	iw := &interceptingWriter{http.StatusOK, w}
+	w = iw
+
+	// Metrics
+	a.clients.Inc()
+	defer a.clients.Dec()
+
+	defer func(begin time.Time) {
+		a.duration.WithLabelValues(
+			r.Method,
+			metrics.Route(a.handler, r),
+			strconv.Itoa(iw.code),
+		).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) handleSelectKeys(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp KeysQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	keys, err := a.repo(r).SelectAuthorKeys(qp.AuthorID, options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the keys for the result.
+	qr := KeysQueryResult{Errors: a.errors, Params: qp}
+	qr.Keys = keys
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleInsertKey(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	input, err := ingestAuthorKey(r.Body)
+	if err != nil {
+		a.errors.InvalidInput(w, r, err)
+		return
+	}
+
+	if err = auth.AuthorizeAuthor(r.Context(), input.AuthorID); err != nil {
+		a.errors.Forbidden(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	key, err := a.repo(r).InsertAuthorKey(input.AuthorID, input.PublicKey, options)
+	if err != nil {
+		if repository.ErrInvalidSignature(err) {
+			a.errors.InvalidSignature(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	level.Info(a.logger).Log("action", "insert key", "author_id", key.AuthorID, "key_id", key.KeyID)
+
+	// Make sure we collect the key for the result.
+	qr := InsertKeyQueryResult{Errors: a.errors}
+	qr.Key = key
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+type interceptingWriter struct {
+	code int
+	http.ResponseWriter
+}
+
+func (iw *interceptingWriter) WriteHeader(code int) {
+	iw.code = code
+	iw.ResponseWriter.WriteHeader(code)
+}
+
+func ingestAuthorKey(reader io.ReadCloser) (models.AuthorKeyInput, error) {
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return models.AuthorKeyInput{}, err
+	}
+
+	if len(bytes) < 1 {
+		return models.AuthorKeyInput{}, errors.New("no body content")
+	}
+
+	var input models.AuthorKeyInput
+	if err = json.Unmarshal(bytes, &input); err != nil {
+		return models.AuthorKeyInput{}, err
+	}
+	if err = models.ValidateAuthorKeyInput(input); err != nil {
+		return models.AuthorKeyInput{}, err
+	}
+	return input, nil
+}
+
+// repo returns the repository bound to the context of the request, so that
+// the calls to the repository are traced as part of the request.
+func (a *API) repo(r *http.Request) repository.Repository {
+	return repository.WithContext(r.Context(), a.repository)
+}
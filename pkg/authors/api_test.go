@@ -0,0 +1,250 @@
+package authors
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/harness/matchers"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestKeysAPI(t *testing.T) {
+	t.Parallel()
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("get with no author_id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/keys/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		resp, err := http.Get(fmt.Sprintf("%s/keys/", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("get with author_id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/keys/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().SelectAuthorKeys("author", repository.Query{}).Return([]models.AuthorKey{
+			{AuthorID: "author", KeyID: "key", PublicKey: publicKey},
+		}, nil).Times(1)
+
+		resp, err := http.Get(fmt.Sprintf("%s/keys/?author_id=author", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		var keys []struct {
+			KeyID     string `json:"key_id"`
+			PublicKey []byte `json:"public_key"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(keys); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := []byte(publicKey), keys[0].PublicKey; !bytes.Equal(expected, actual) {
+			t.Errorf("expected: %x, actual: %x", expected, actual)
+		}
+	})
+
+	t.Run("post with invalid public key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/keys/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		b, err := json.Marshal(models.AuthorKeyInput{
+			AuthorID:  "author",
+			PublicKey: []byte("key"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(fmt.Sprintf("%s/keys/", server.URL), "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("post with public key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/keys/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().InsertAuthorKey("author", []byte(publicKey), repository.Query{}).Return(models.AuthorKey{
+			AuthorID:  "author",
+			KeyID:     "key",
+			PublicKey: publicKey,
+		}, nil).Times(1)
+
+		b, err := json.Marshal(models.AuthorKeyInput{
+			AuthorID:  "author",
+			PublicKey: publicKey,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(fmt.Sprintf("%s/keys/", server.URL), "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		var key struct {
+			KeyID string `json:"key_id"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&key); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "key", key.KeyID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("post with repo failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/keys/", "500").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().InsertAuthorKey("author", []byte(publicKey), repository.Query{}).Return(models.AuthorKey{}, errors.New("bad")).Times(1)
+
+		b, err := json.Marshal(models.AuthorKeyInput{
+			AuthorID:  "author",
+			PublicKey: publicKey,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(fmt.Sprintf("%s/keys/", server.URL), "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusInternalServerError, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
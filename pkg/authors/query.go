@@ -0,0 +1,87 @@
+package authors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/models"
+)
+
+const (
+	defaultContentType = "application/json"
+)
+
+// KeysQueryParams defines all the dimensions of a query.
+type KeysQueryParams struct {
+	AuthorID string `json:"author_id"`
+}
+
+// DecodeFrom populates a KeysQueryParams from a URL.
+func (qp *KeysQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	authorID := u.Query().Get("author_id")
+	if rb == queryRequired && authorID == "" {
+		return errors.New("error reading 'author_id' (required) query")
+	}
+	qp.AuthorID = authorID
+
+	return nil
+}
+
+// KeysQueryResult contains statistics about the query.
+type KeysQueryResult struct {
+	Errors   errs.Error
+	Params   KeysQueryParams    `json:"query"`
+	Duration string             `json:"duration"`
+	Keys     []models.AuthorKey `json:"keys"`
+}
+
+// EncodeTo encodes the KeysQueryResult to the HTTP response writer.
+func (qr *KeysQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+	w.Header().Set(httpHeaderQueryAuthorID, qr.Params.AuthorID)
+
+	// Make sure that we encode empty keys correctly (i.e. they're not null in
+	// the json output)
+	keys := qr.Keys
+	if qr.Keys == nil {
+		keys = make([]models.AuthorKey, 0)
+	}
+
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// InsertKeyQueryResult contains statistics about the query.
+type InsertKeyQueryResult struct {
+	Errors   errs.Error
+	Duration string           `json:"duration"`
+	Key      models.AuthorKey `json:"key"`
+}
+
+// EncodeTo encodes the InsertKeyQueryResult to the HTTP response writer.
+func (qr *InsertKeyQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(qr.Key); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+const (
+	httpHeaderContentType   = "Content-Type"
+	httpHeaderDuration      = "X-Duration"
+	httpHeaderQueryAuthorID = "X-Query-Author-Id"
+)
+
+type queryBehavior int
+
+const (
+	queryRequired queryBehavior = iota
+	queryOptional
+)
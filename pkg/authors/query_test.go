@@ -0,0 +1,40 @@
+package authors
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestKeysQueryParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DecodeFrom with required empty url", func(t *testing.T) {
+		var qp KeysQueryParams
+
+		u, err := url.Parse("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with author_id", func(t *testing.T) {
+		var qp KeysQueryParams
+
+		u, err := url.Parse("/?author_id=author")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := qp.DecodeFrom(u, queryRequired); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "author", qp.AuthorID; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
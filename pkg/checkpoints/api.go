@@ -0,0 +1,202 @@
+package checkpoints
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/trussle/snowy/pkg/auth"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/snowy/pkg/trace"
+)
+
+// These are the checkpoints API URL paths.
+const (
+	APIPathCheckpointQuery = "/"
+	APIPathProofQuery      = "/proof/"
+	APIPathKeyQuery        = "/key/"
+)
+
+// API serves the checkpoints API
+type API struct {
+	handler    *mux.Router
+	repository repository.Repository
+	logger     log.Logger
+	clients    metrics.Gauge
+	duration   metrics.HistogramVec
+	errors     errs.Error
+}
+
+// NewAPI creates a API with correct dependencies.
+func NewAPI(repository repository.Repository, logger log.Logger,
+	clients metrics.Gauge,
+	duration metrics.HistogramVec,
+) *API {
+	api := &API{
+		repository: repository,
+		logger:     logger,
+		clients:    clients,
+		duration:   duration,
+		errors:     errs.NewError(logger),
+	}
+	{
+		router := mux.NewRouter().StrictSlash(true)
+		router.Methods("GET").Path(APIPathCheckpointQuery).HandlerFunc(api.handleCheckpoint)
+		router.Methods("GET").Path(APIPathProofQuery).HandlerFunc(api.handleProof)
+		router.Methods("GET").Path(APIPathKeyQuery).HandlerFunc(api.handleKey)
+		router.NotFoundHandler = http.HandlerFunc(api.errors.NotFound)
+
+		api.handler = router
+	}
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	level.Info(trace.Logger(r.Context(), a.logger)).Log("url", r.URL.String())
+
+	iw := &interceptingWriter{http.StatusOK, w}
+	w = iw
+
+	// Metrics
+	a.clients.Inc()
+	defer a.clients.Dec()
+
+	defer func(begin time.Time) {
+		a.duration.WithLabelValues(
+			r.Method,
+			metrics.Route(a.handler, r),
+			strconv.Itoa(iw.code),
+		).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) handleCheckpoint(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp CheckpointQueryParams
+	if err := qp.DecodeFrom(r.URL, queryOptional); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	checkpoint, err := a.repo(r).SelectCheckpoint(qp.TreeSize)
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	qr := CheckpointQueryResult{Errors: a.errors, Params: qp}
+	qr.Checkpoint = checkpoint
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleProof(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp ProofQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := a.buildQuery(r)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	proof, err := a.repo(r).SelectLedgerProof(qp.LedgerID, qp.TreeSize, qp.FromTreeSize, options)
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	qr := ProofQueryResult{Errors: a.errors, Params: qp}
+	qr.Proof = proof
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleKey(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	key, err := a.repo(r).SelectCheckpointKey()
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	qr := KeyQueryResult{Errors: a.errors}
+	qr.Key = key
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+type interceptingWriter struct {
+	code int
+	http.ResponseWriter
+}
+
+func (iw *interceptingWriter) WriteHeader(code int) {
+	iw.code = code
+	iw.ResponseWriter.WriteHeader(code)
+}
+
+// buildQuery returns the query of the request, so that only the proofs of the
+// ledgers in the tenant that the principal of the request can read are
+// served.
+func (a *API) buildQuery(r *http.Request) (repository.Query, error) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	return repository.BuildQuery(
+		repository.WithQueryPrincipal(principal.ID, principal.Groups),
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+}
+
+// repo returns the repository bound to the context of the request, so that
+// the calls to the repository are traced as part of the request.
+func (a *API) repo(r *http.Request) repository.Repository {
+	return repository.WithContext(r.Context(), a.repository)
+}
@@ -0,0 +1,315 @@
+package checkpoints
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/fsys"
+	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/merkle"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/uuid"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestCheckpointAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("select with invalid tree_size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		resp, err := http.Get(fmt.Sprintf("%s/?tree_size=bad", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("select with no checkpoint", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/", "404").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().SelectCheckpoint(int64(0)).Return(models.Checkpoint{}, errNotFound{errors.New("failure")})
+
+		resp, err := http.Get(fmt.Sprintf("%s/", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusNotFound, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("select with error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/", "500").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().SelectCheckpoint(int64(3)).Return(models.Checkpoint{}, errors.New("bad"))
+
+		resp, err := http.Get(fmt.Sprintf("%s/?tree_size=3", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusInternalServerError, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("proof of unreadable ledger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := auth.WithPrincipal(r.Context(), auth.Principal{ID: "other"})
+				api.ServeHTTP(w, r.WithContext(ctx))
+			}))
+
+			ledgerID = uuid.MustNew()
+			query    = repository.Query{Principal: &repository.Principal{ID: "other"}}
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/proof/", "403").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().SelectLedgerProof(ledgerID, int64(0), int64(0), query).Return(models.LedgerProof{}, errForbidden{errors.New("forbidden")})
+
+		resp, err := http.Get(fmt.Sprintf("%s/proof/?ledger_id=%s", server.URL, ledgerID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusForbidden, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func TestCheckpointEndToEnd(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		clients  = metricMocks.NewMockGauge(ctrl)
+		duration = metricMocks.NewMockHistogramVec(ctrl)
+		observer = metricMocks.NewMockObserver(ctrl)
+	)
+
+	clients.EXPECT().Inc().AnyTimes()
+	clients.EXPECT().Dec().AnyTimes()
+	duration.EXPECT().WithLabelValues("GET", gomock.Any(), "200").Return(observer).AnyTimes()
+	observer.EXPECT().Observe(matchers.MatchAnyFloat64()).AnyTimes()
+
+	signer, err := repository.NewLocalSigner(writeKeyFile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		repo = repository.NewRealRepository(
+			repository.NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+			store.NewVirtualStore(),
+			log.NewNopLogger(),
+			repository.WithCheckpoints(signer),
+		)
+		api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+		server = httptest.NewServer(api)
+	)
+	defer server.Close()
+
+	var ids []uuid.UUID
+	for k := 0; k < 5; k++ {
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName(fmt.Sprintf("name-%d", k)),
+			models.WithCreatedOn(time.Now().Add(time.Duration(k)*time.Second)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = repo.InsertLedger(doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc, err = repo.SelectLedger(doc.ResourceID(), repository.Query{}); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, doc.ID())
+
+		if k == 1 || k == 4 {
+			if _, err = repo.Checkpoint(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var key struct {
+		PublicKey string `json:"public_key"`
+	}
+	getJSON(t, fmt.Sprintf("%s/key/", server.URL), &key)
+
+	publicKey, err := hex.DecodeString(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var checkpoint merkle.Checkpoint
+	getJSON(t, fmt.Sprintf("%s/", server.URL), &checkpoint)
+
+	if expected, actual := int64(5), checkpoint.TreeSize; expected != actual {
+		t.Errorf("expected: %d, actual: %d", expected, actual)
+	}
+	if err := checkpoint.Verify(ed25519.PublicKey(publicKey)); err != nil {
+		t.Error(err)
+	}
+
+	var proof merkle.Proof
+	getJSON(t, fmt.Sprintf("%s/proof/?ledger_id=%s&from_tree_size=2", server.URL, ids[0]), &proof)
+
+	if proof.FromCheckpoint == nil {
+		t.Fatal("expected from checkpoint")
+	}
+	if err := proof.Verify(ed25519.PublicKey(publicKey)); err != nil {
+		t.Error(err)
+	}
+}
+
+func getJSON(t *testing.T, url string, v interface{}) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+		t.Fatalf("expected: %d, actual: %d", expected, actual)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeKeyFile(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "key")
+	seed := hex.EncodeToString(make([]byte, ed25519.SeedSize))
+	if err := ioutil.WriteFile(path, []byte(seed), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+type errNotFound struct {
+	err error
+}
+
+func (e errNotFound) Error() string {
+	return e.err.Error()
+}
+
+func (e errNotFound) NotFound() bool {
+	return true
+}
+
+type errForbidden struct {
+	err error
+}
+
+func (e errForbidden) Error() string {
+	return e.err.Error()
+}
+
+func (e errForbidden) Forbidden() bool {
+	return true
+}
@@ -0,0 +1,189 @@
+package checkpoints
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/merkle"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+const (
+	defaultContentType = "application/json"
+)
+
+// CheckpointQueryParams defines all the dimensions of a query. The tree_size
+// is optional, with the latest checkpoint being used if it's not provided.
+type CheckpointQueryParams struct {
+	TreeSize int64 `json:"tree_size"`
+}
+
+// DecodeFrom populates a CheckpointQueryParams from a URL.
+func (qp *CheckpointQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	treeSize, err := decodeTreeSize(u, "tree_size", rb)
+	if err != nil {
+		return err
+	}
+	qp.TreeSize = treeSize
+
+	return nil
+}
+
+// CheckpointQueryResult contains statistics about the query.
+type CheckpointQueryResult struct {
+	Errors     errs.Error
+	Params     CheckpointQueryParams `json:"query"`
+	Duration   string                `json:"duration"`
+	Checkpoint models.Checkpoint     `json:"checkpoint"`
+}
+
+// EncodeTo encodes the CheckpointQueryResult to the HTTP response writer.
+func (qr *CheckpointQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(encodeCheckpoint(qr.Checkpoint)); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ProofQueryParams defines all the dimensions of a query. The ledger_id is
+// required, where as the tree_size (defaults to the latest checkpoint) and
+// from_tree_size are optional.
+type ProofQueryParams struct {
+	LedgerID     uuid.UUID `json:"ledger_id"`
+	TreeSize     int64     `json:"tree_size"`
+	FromTreeSize int64     `json:"from_tree_size"`
+}
+
+// DecodeFrom populates a ProofQueryParams from a URL.
+func (qp *ProofQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	var (
+		err      error
+		ledgerID = u.Query().Get("ledger_id")
+	)
+	if rb == queryRequired && ledgerID == "" {
+		return errors.New("error reading 'ledger_id' (required) query")
+	}
+	if ledgerID != "" {
+		if qp.LedgerID, err = uuid.Parse(ledgerID); err != nil {
+			return errors.Wrap(err, "error parsing 'ledger_id' query")
+		}
+	}
+
+	if qp.TreeSize, err = decodeTreeSize(u, "tree_size", queryOptional); err != nil {
+		return err
+	}
+	if qp.FromTreeSize, err = decodeTreeSize(u, "from_tree_size", queryOptional); err != nil {
+		return err
+	}
+	if qp.TreeSize != 0 && qp.FromTreeSize > qp.TreeSize {
+		return errors.New("error reading 'from_tree_size' query, expected it to not be after 'tree_size'")
+	}
+
+	return nil
+}
+
+// ProofQueryResult contains statistics about the query.
+type ProofQueryResult struct {
+	Errors   errs.Error
+	Params   ProofQueryParams   `json:"query"`
+	Duration string             `json:"duration"`
+	Proof    models.LedgerProof `json:"proof"`
+}
+
+// EncodeTo encodes the ProofQueryResult to the HTTP response writer.
+func (qr *ProofQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	proof := merkle.Proof{
+		LedgerID:   qr.Proof.LedgerID.String(),
+		LedgerHash: qr.Proof.LedgerHash,
+		LeafIndex:  qr.Proof.LeafIndex,
+		Checkpoint: encodeCheckpoint(qr.Proof.Checkpoint),
+		Inclusion:  merkle.EncodeHashes(qr.Proof.Inclusion),
+	}
+	if qr.Proof.FromCheckpoint != nil {
+		from := encodeCheckpoint(*qr.Proof.FromCheckpoint)
+		proof.FromCheckpoint = &from
+		proof.Consistency = merkle.EncodeHashes(qr.Proof.Consistency)
+	}
+
+	if err := json.NewEncoder(w).Encode(proof); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// KeyQueryResult contains statistics about the query.
+type KeyQueryResult struct {
+	Errors   errs.Error
+	Duration string               `json:"duration"`
+	Key      models.CheckpointKey `json:"key"`
+}
+
+// EncodeTo encodes the KeyQueryResult to the HTTP response writer.
+func (qr *KeyQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(struct {
+		KeyID     string `json:"key_id"`
+		Algorithm string `json:"algorithm"`
+		PublicKey string `json:"public_key"`
+	}{
+		KeyID:     qr.Key.KeyID,
+		Algorithm: "ed25519",
+		PublicKey: hex.EncodeToString(qr.Key.PublicKey),
+	}); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func encodeCheckpoint(checkpoint models.Checkpoint) merkle.Checkpoint {
+	return merkle.Checkpoint{
+		TreeSize:  checkpoint.TreeSize,
+		RootHash:  hex.EncodeToString(checkpoint.RootHash),
+		Timestamp: checkpoint.CreatedOn,
+		KeyID:     checkpoint.KeyID,
+		Signature: base64.StdEncoding.EncodeToString(checkpoint.Signature),
+	}
+}
+
+func decodeTreeSize(u *url.URL, name string, rb queryBehavior) (int64, error) {
+	value := u.Query().Get(name)
+	if value == "" {
+		if rb == queryRequired {
+			return 0, errors.Errorf("error reading '%s' (required) query", name)
+		}
+		return 0, nil
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error parsing '%s' query", name)
+	}
+	if size < 1 {
+		return 0, errors.Errorf("error parsing '%s' query, expected a positive size", name)
+	}
+	return size, nil
+}
+
+const (
+	httpHeaderContentType = "Content-Type"
+	httpHeaderDuration    = "X-Duration"
+)
+
+type queryBehavior int
+
+const (
+	queryRequired queryBehavior = iota
+	queryOptional
+)
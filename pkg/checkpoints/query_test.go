@@ -0,0 +1,131 @@
+package checkpoints
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/trussle/uuid"
+)
+
+func TestCheckpointQueryParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DecodeFrom with empty url", func(t *testing.T) {
+		var qp CheckpointQueryParams
+
+		u, err := url.Parse("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := qp.DecodeFrom(u, queryOptional); err != nil {
+			t.Error(err)
+		}
+		if expected, actual := int64(0), qp.TreeSize; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with tree_size", func(t *testing.T) {
+		var qp CheckpointQueryParams
+
+		u, err := url.Parse("/?tree_size=12")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := qp.DecodeFrom(u, queryOptional); err != nil {
+			t.Error(err)
+		}
+		if expected, actual := int64(12), qp.TreeSize; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with invalid tree_size", func(t *testing.T) {
+		for _, v := range []string{"bad", "0", "-1"} {
+			var qp CheckpointQueryParams
+
+			u, err := url.Parse("/?tree_size=" + v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = qp.DecodeFrom(u, queryOptional)
+			if expected, actual := false, err == nil; expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+	})
+}
+
+func TestProofQueryParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DecodeFrom with required empty url", func(t *testing.T) {
+		var qp ProofQueryParams
+
+		u, err := url.Parse("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with invalid ledger_id", func(t *testing.T) {
+		var qp ProofQueryParams
+
+		u, err := url.Parse("/?ledger_id=bad")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with from_tree_size after tree_size", func(t *testing.T) {
+		var qp ProofQueryParams
+
+		u, err := url.Parse("/?ledger_id=" + uuid.MustNew().String() + "&tree_size=2&from_tree_size=3")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom", func(t *testing.T) {
+		var (
+			qp  ProofQueryParams
+			uid = uuid.MustNew()
+		)
+
+		u, err := url.Parse("/?ledger_id=" + uid.String() + "&tree_size=5&from_tree_size=2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := qp.DecodeFrom(u, queryRequired); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := uid, qp.LedgerID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := int64(5), qp.TreeSize; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := int64(2), qp.FromTreeSize; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
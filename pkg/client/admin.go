@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// These are the admin API URL paths.
+const (
+	adminExportPath = "/admin/export/"
+	adminImportPath = "/admin/import/"
+)
+
+const defaultArchiveContentType = "application/x-tar"
+
+// Admin is the client of the admin endpoints.
+type Admin struct {
+	client *Client
+}
+
+// ExportQuery defines the optional dimensions of an export, without any of
+// them the whole tenant is exported.
+type ExportQuery struct {
+	ResourceID   uuid.UUID
+	Tags         []string
+	AuthorID     string
+	Since, Until time.Time
+}
+
+func (q ExportQuery) values() url.Values {
+	values := make(url.Values)
+	if !q.ResourceID.Zero() {
+		values.Set("resource_id", q.ResourceID.String())
+	}
+	if len(q.Tags) > 0 {
+		values.Set("tags", strings.Join(q.Tags, ","))
+	}
+	if q.AuthorID != "" {
+		values.Set("author_id", q.AuthorID)
+	}
+	if !q.Since.IsZero() {
+		values.Set("since", q.Since.Format(time.RFC3339))
+	}
+	if !q.Until.IsZero() {
+		values.Set("until", q.Until.Format(time.RFC3339))
+	}
+	return values
+}
+
+// Export streams an archive of the ledgers and content of the tenant, in
+// the format of the archive package. The archive has to be closed once it's
+// read.
+func (a *Admin) Export(ctx context.Context, query ExportQuery) (io.ReadCloser, error) {
+	res, err := a.client.do(ctx, request{
+		method: "GET",
+		path:   adminExportPath,
+		query:  query.values(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// ImportSummary reports what was imported from an archive. Duplicates are the
+// ledgers that already existed and were skipped, and Missing are the content
+// addresses that the archive has no content for.
+type ImportSummary struct {
+	Ledgers    int   `json:"ledgers"`
+	Blobs      int   `json:"blobs"`
+	Bytes      int64 `json:"bytes"`
+	Duplicates int   `json:"duplicates"`
+	Missing    int   `json:"missing"`
+}
+
+// Import streams an archive, in the format of the archive package, to the API
+// to import in to the tenant. Importing is idempotent, so if the reader can
+// seek, then the request is retried by seeking back, otherwise it's only
+// attempted once.
+func (a *Admin) Import(ctx context.Context, r io.Reader) (ImportSummary, error) {
+	body, retry := replayable(r)
+	res, err := a.client.do(ctx, request{
+		method:     "POST",
+		path:       adminImportPath,
+		header:     http.Header{httpHeaderContentType: []string{defaultArchiveContentType}},
+		body:       body,
+		noRetry:    !retry,
+		idempotent: true,
+	})
+	if err != nil {
+		return ImportSummary{}, err
+	}
+
+	var summary ImportSummary
+	err = decodeJSON(res, &summary)
+	return summary, err
+}
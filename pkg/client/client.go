@@ -0,0 +1,366 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/tenant"
+)
+
+const (
+	defaultRetries = 3
+	defaultBackoff = 100 * time.Millisecond
+	defaultTimeout = 30 * time.Second
+
+	httpHeaderAPIKey        = "X-Api-Key"
+	httpHeaderAuthorization = "Authorization"
+	httpHeaderContentType   = "Content-Type"
+	httpHeaderRetryAfter    = "Retry-After"
+	httpHeaderLastEventID   = "Last-Event-ID"
+
+	defaultContentType = "application/json"
+)
+
+// Client is a client of the REST API of snowy, with a typed client for each
+// of the ledgers, contents, journals and admin endpoints.
+type Client struct {
+	base    *url.URL
+	client  *http.Client
+	header  http.Header
+	sign    func(*http.Request) error
+	retries int
+	backoff time.Duration
+}
+
+// Option defines a option for configuring the Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the http client that requests are sent with.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithAPIKey authenticates requests with the API key, for the apikey
+// provider.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.header.Set(httpHeaderAPIKey, key)
+	}
+}
+
+// WithBearerToken authenticates requests with the token, for the jwt
+// provider.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.header.Set(httpHeaderAuthorization, "Bearer "+token)
+	}
+}
+
+// WithHMAC signs requests with the shared secret of the principal, for the
+// hmac provider. The body of a request has to be read to be signed, so
+// uploads are buffered rather than streamed.
+func WithHMAC(principal string, secret []byte) Option {
+	return func(c *Client) {
+		c.sign = func(r *http.Request) error {
+			return auth.SignRequest(r, principal, secret, time.Now())
+		}
+	}
+}
+
+// WithTenant scopes every request to the tenant.
+func WithTenant(tenantID string) Option {
+	return func(c *Client) {
+		c.header.Set(tenant.HTTPHeaderTenant, tenantID)
+	}
+}
+
+// WithRetries sets how many times a request is retried, when the request
+// fails or the server is unavailable. Writes that aren't idempotent are only
+// retried when the server never received them. Zero disables retries.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithBackoff sets how long to wait before the first retry, doubling for
+// every retry after.
+func WithBackoff(backoff time.Duration) Option {
+	return func(c *Client) {
+		c.backoff = backoff
+	}
+}
+
+// New creates a Client for the API at the base URL.
+func New(base string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(base, "/"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid base url %q", base)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("invalid base url %q, expected http or https", base)
+	}
+
+	client := &Client{
+		base:    u,
+		client:  &http.Client{Timeout: defaultTimeout},
+		header:  make(http.Header),
+		retries: defaultRetries,
+		backoff: defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client, nil
+}
+
+// Ledgers returns the client of the ledgers endpoints.
+func (c *Client) Ledgers() *Ledgers {
+	return &Ledgers{c}
+}
+
+// Contents returns the client of the contents endpoints.
+func (c *Client) Contents() *Contents {
+	return &Contents{c}
+}
+
+// Journals returns the client of the journals endpoints.
+func (c *Client) Journals() *Journals {
+	return &Journals{c}
+}
+
+// Admin returns the client of the admin endpoints.
+func (c *Client) Admin() *Admin {
+	return &Admin{c}
+}
+
+// Health checks that the API is healthy.
+func (c *Client) Health(ctx context.Context) error {
+	return c.status(ctx, "/status/health")
+}
+
+// Ready checks that the API is ready to serve requests.
+func (c *Client) Ready(ctx context.Context) error {
+	return c.status(ctx, "/status/ready")
+}
+
+func (c *Client) status(ctx context.Context, path string) error {
+	res, err := c.do(ctx, request{method: "GET", path: path})
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// Query defines the optional dimensions of a select query.
+type Query struct {
+	Tags     []string
+	AuthorID string
+}
+
+func (q Query) values(resourceID string) url.Values {
+	values := make(url.Values)
+	if resourceID != "" {
+		values.Set("resource_id", resourceID)
+	}
+	if len(q.Tags) > 0 {
+		values.Set("query.tags", strings.Join(q.Tags, ","))
+	}
+	if q.AuthorID != "" {
+		values.Set("query.author_id", q.AuthorID)
+	}
+	return values
+}
+
+// request describes a request to the API. The body is called for every
+// attempt, so that a retried request can send the body again.
+type request struct {
+	method        string
+	path          string
+	query         url.Values
+	header        http.Header
+	body          func() (io.Reader, error)
+	contentLength int64
+	noRetry       bool
+
+	// idempotent is set for writes that can be sent again without changing
+	// the outcome, like content that's stored by its address. Reads are
+	// always idempotent.
+	idempotent bool
+}
+
+// do sends the request, retrying with backoff when the request fails or the
+// server is unavailable. Writes that aren't idempotent are only retried when
+// the server never received them. A response that isn't successful is
+// returned as an *Error.
+func (c *Client) do(ctx context.Context, req request) (*http.Response, error) {
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		res, err := c.send(ctx, req)
+		if err == nil && res.StatusCode >= 200 && res.StatusCode < 300 {
+			return res, nil
+		}
+
+		wait := backoff
+		if err == nil {
+			wait = retryAfter(res, backoff)
+			err = decodeError(res)
+		}
+		if req.noRetry || attempt >= c.retries || !retryable(err, req.idempotent || req.method == "GET") {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	u := *c.base
+	u.Path = u.Path + req.path
+	if req.query != nil {
+		u.RawQuery = req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		var err error
+		if body, err = req.body(); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := http.NewRequest(req.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(ctx)
+	if req.contentLength > 0 {
+		r.ContentLength = req.contentLength
+	}
+	for k, v := range c.header {
+		r.Header[k] = v
+	}
+	for k, v := range req.header {
+		r.Header[k] = v
+	}
+	if c.sign != nil {
+		if err := c.sign(r); err != nil {
+			return nil, err
+		}
+	}
+	return c.client.Do(r)
+}
+
+// decodeJSON decodes the body of the response in to v.
+func decodeJSON(res *http.Response, v interface{}) error {
+	defer res.Body.Close()
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "invalid response")
+	}
+	return nil
+}
+
+// jsonBody returns the body of v encoded as json, for every attempt.
+func jsonBody(v interface{}) (func() (io.Reader, error), int64, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, 0, err
+	}
+	return func() (io.Reader, error) {
+		return strings.NewReader(string(b)), nil
+	}, int64(len(b)), nil
+}
+
+// replayable returns the reader for every attempt, seeking back to where the
+// reader started for every attempt after the first. A request with a reader
+// that can't seek can't be retried.
+func replayable(r io.Reader) (func() (io.Reader, error), bool) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return func() (io.Reader, error) { return r, nil }, false
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return func() (io.Reader, error) { return r, nil }, false
+	}
+	return func() (io.Reader, error) {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}, true
+}
+
+// retryAfter returns how long to wait before retrying, using the Retry-After
+// header of the response if there is one.
+func retryAfter(res *http.Response, backoff time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(res.Header.Get(httpHeaderRetryAfter)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return backoff
+}
+
+// retryable checks if the request can be retried. An idempotent request is
+// retried when it failed to be sent or the server was unavailable. Any other
+// request may have already been written by the server in those cases, so it's
+// only retried when the server provably never received it, which is when the
+// connection was refused or the request was rate limited.
+func retryable(err error, idempotent bool) bool {
+	if e, ok := err.(*Error); ok {
+		switch e.Code {
+		case http.StatusTooManyRequests:
+			return true
+		case http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return idempotent
+		}
+		return false
+	}
+	return idempotent || refused(err)
+}
+
+// refused checks if the error is from failing to connect to the server, in
+// which case nothing of the request was sent.
+func refused(err error) bool {
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
+	}
+	e, ok := err.(*net.OpError)
+	return ok && e.Op == "dial"
+}
+
+// decodeError decodes the error of the response, as written by the API.
+func decodeError(res *http.Response) error {
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrap(err, "invalid response")
+	}
+
+	e := &Error{Code: res.StatusCode}
+	if err := json.Unmarshal(b, e); err != nil || e.Description == "" {
+		e.Description = strings.TrimSpace(string(b))
+	}
+	e.Code = res.StatusCode
+	return e
+}
@@ -0,0 +1,648 @@
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/fsys"
+	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/admin"
+	"github.com/trussle/snowy/pkg/archive"
+	"github.com/trussle/snowy/pkg/contents"
+	"github.com/trussle/snowy/pkg/journals"
+	"github.com/trussle/snowy/pkg/ledgers"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/status"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/uuid"
+)
+
+func newServer(t *testing.T, ctrl *gomock.Controller) (*Client, func()) {
+	var (
+		clients  = metricMocks.NewMockGauge(ctrl)
+		bytes    = metricMocks.NewMockCounter(ctrl)
+		records  = metricMocks.NewMockCounter(ctrl)
+		duration = metricMocks.NewMockHistogramVec(ctrl)
+		observer = metricMocks.NewMockObserver(ctrl)
+		repo     = repository.NewRealRepository(
+			repository.NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+			store.NewVirtualStore(),
+			log.NewNopLogger(),
+		)
+	)
+
+	clients.EXPECT().Inc().AnyTimes()
+	clients.EXPECT().Dec().AnyTimes()
+	bytes.EXPECT().Add(gomock.Any()).AnyTimes()
+	records.EXPECT().Inc().AnyTimes()
+	duration.EXPECT().WithLabelValues(gomock.Any(), gomock.Any(), gomock.Any()).Return(observer).AnyTimes()
+	observer.EXPECT().Observe(matchers.MatchAnyFloat64()).AnyTimes()
+
+	contentsAPI := contents.NewAPI(repo, log.NewNopLogger(), clients, bytes, records, duration)
+
+	mux := http.NewServeMux()
+	mux.Handle("/ledgers/", http.StripPrefix("/ledgers", ledgers.NewAPI(repo, log.NewNopLogger(), clients, duration)))
+	mux.Handle("/contents/", http.StripPrefix("/contents", contentsAPI))
+	mux.Handle("/journals/", http.StripPrefix("/journals", journals.NewAPI(repo, log.NewNopLogger(), clients, bytes, records, duration)))
+	mux.Handle("/status/", http.StripPrefix("/status", status.NewAPI(log.NewNopLogger(), clients, duration)))
+	mux.Handle("/admin/", http.StripPrefix("/admin", admin.NewAPI(repo, log.NewNopLogger(), clients, duration)))
+
+	server := httptest.NewServer(mux)
+
+	client, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, func() {
+		server.Close()
+		contentsAPI.Close()
+	}
+}
+
+func ledgerInput(address string) models.LedgerInput {
+	return models.LedgerInput{
+		Name:                "name",
+		ResourceAddress:     address,
+		ResourceSize:        1,
+		ResourceContentType: "application/octet-stream",
+		AuthorID:            "author",
+		Tags:                []string{"a", "b"},
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New("ftp://example.com"); err == nil {
+		t.Errorf("expected error for invalid scheme")
+	}
+	if _, err := New("http://example.com/"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLedgers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, stop := newServer(t, ctrl)
+	defer stop()
+
+	var (
+		ctx     = context.Background()
+		ledgers = client.Ledgers()
+	)
+
+	t.Run("insert append then select revisions", func(t *testing.T) {
+		resourceID, err := ledgers.Insert(ctx, ledgerInput("a"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ledgers.Append(ctx, resourceID, ledgerInput("b")); err != nil {
+			t.Fatal(err)
+		}
+
+		head, err := ledgers.Select(ctx, resourceID, Query{Tags: []string{"a"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := resourceID, head.ResourceID(); !expected.Equals(actual) {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+
+		revisions, err := ledgers.Revisions(ctx, resourceID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(revisions); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		verification, err := ledgers.Verify(ctx, resourceID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := true, verification.Valid; expected != actual {
+			t.Errorf("expected: %t, actual: %t, reason: %s", expected, actual, verification.Reason)
+		}
+	})
+
+	t.Run("fork", func(t *testing.T) {
+		resourceID, err := ledgers.Insert(ctx, ledgerInput("a"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		forkedID, err := ledgers.Fork(ctx, resourceID, ledgerInput("b"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resourceID.Equals(forkedID) {
+			t.Errorf("expected: a new resource, actual: %s", forkedID)
+		}
+
+		forks, err := ledgers.ForkRevisions(ctx, forkedID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(forks); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("acl", func(t *testing.T) {
+		resourceID, err := ledgers.Insert(ctx, ledgerInput("a"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		acl, err := ledgers.UpdateACL(ctx, resourceID, models.ACLInput{
+			Owner:   "author",
+			Readers: []string{"reader"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := resourceID, acl.ResourceID; !expected.Equals(actual) {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+
+		acl, err = ledgers.ACL(ctx, resourceID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := []string{"reader"}, acl.Readers; len(actual) != 1 || expected[0] != actual[0] {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("select not found", func(t *testing.T) {
+		_, err := ledgers.Select(ctx, uuid.MustNew(), Query{})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t, err: %v", expected, actual, err)
+		}
+	})
+
+	t.Run("insert with invalid input", func(t *testing.T) {
+		input := ledgerInput("a")
+		input.Name = ""
+
+		_, err := ledgers.Insert(ctx, input)
+		if expected, actual := true, ErrBadRequest(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t, err: %v", expected, actual, err)
+		}
+		if err != nil && err.(*Error).Description == "" {
+			t.Errorf("expected: description of the error")
+		}
+		if expected, actual := true, ErrType(err, "invalid_input"); expected != actual {
+			t.Errorf("expected: %t, actual: %t, err: %v", expected, actual, err)
+		}
+		if err != nil {
+			fields := err.(*Error).Fields
+			if len(fields) != 1 || fields[0].Field != "name" {
+				t.Errorf("expected: name to be invalid, actual: %v", fields)
+			}
+		}
+	})
+}
+
+func TestContents(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, stop := newServer(t, ctrl)
+	defer stop()
+
+	var (
+		ctx  = context.Background()
+		body = []byte("snowy content")
+	)
+
+	content, err := client.Contents().Put(ctx, bytes.NewReader(body), int64(len(body)), "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := int64(len(body)), content.Size(); expected != actual {
+		t.Errorf("expected: %d, actual: %d", expected, actual)
+	}
+
+	input := ledgerInput(content.Address())
+	input.ResourceContentType = content.ContentType()
+
+	resourceID, err := client.Ledgers().Insert(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("select", func(t *testing.T) {
+		download, err := client.Contents().Select(ctx, resourceID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer download.Body.Close()
+
+		b, err := ioutil.ReadAll(download.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := body, b; !bytes.Equal(expected, actual) {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "text/plain", download.ContentType; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		archive, err := client.Contents().Revisions(ctx, resourceID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(archive.File); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := content.Address(), archive.File[0].Name; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		archive, err := client.Contents().Multiple(ctx, []uuid.UUID{resourceID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(archive.File); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func TestJournals(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, stop := newServer(t, ctrl)
+	defer stop()
+
+	var (
+		ctx      = context.Background()
+		journals = client.Journals()
+		body     = "snowy journal"
+	)
+
+	resourceID, address, err := journals.Insert(ctx, strings.NewReader(body), int64(len(body)), "text/plain", ledgerInput(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := models.ContentAddress([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := want, address; expected != actual {
+		t.Errorf("expected: %q, actual: %q", expected, actual)
+	}
+
+	appendedID, _, err := journals.Append(ctx, resourceID, strings.NewReader(body+"!"), int64(len(body)+1), "text/plain", ledgerInput(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	download, err := client.Contents().Select(ctx, appendedID, Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer download.Body.Close()
+
+	b, err := ioutil.ReadAll(download.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := body+"!", string(b); expected != actual {
+		t.Errorf("expected: %q, actual: %q", expected, actual)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, stop := newServer(t, ctrl)
+	defer stop()
+
+	if err := client.Health(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, stop := newServer(t, ctrl)
+	defer stop()
+
+	ctx := context.Background()
+
+	content, err := client.Contents().Put(ctx, strings.NewReader("a"), 1, "application/octet-stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Ledgers().Insert(ctx, ledgerInput(content.Address())); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := client.Admin().Export(ctx, ExportQuery{AuthorID: "author"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		names  []string
+		reader = tar.NewReader(bytes.NewReader(exported))
+	)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	if expected, actual := []string{archive.HeaderName, archive.ManifestName, archive.BlobPrefix + content.Address()}, names; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+
+	// Importing the export again skips the ledgers that already exist.
+	summary, err := client.Admin().Import(ctx, bytes.NewReader(exported))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := (ImportSummary{Blobs: 1, Bytes: 1, Duplicates: 1}), summary; expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries unavailable", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			b, _ := ioutil.ReadAll(r.Body)
+			w.Write([]byte(`{"address":"` + string(b) + `","size":5}`))
+		}))
+		defer server.Close()
+
+		client, err := New(server.URL, WithBackoff(time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := client.Contents().Put(context.Background(), strings.NewReader("snowy"), 5, "text/plain")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int32(3), atomic.LoadInt32(&attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "snowy", content.Address(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client, err := New(server.URL, WithRetries(2), WithBackoff(time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = client.Health(context.Background())
+		if expected, actual := http.StatusServiceUnavailable, err.(*Error).Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int32(3), atomic.LoadInt32(&attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("backs off transport errors", func(t *testing.T) {
+		// Nothing is listening once the server is closed, so every attempt
+		// fails before there's a response.
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		client, err := New(server.URL, WithRetries(2), WithBackoff(20*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		begin := time.Now()
+		if err := client.Health(context.Background()); err == nil {
+			t.Errorf("expected error")
+		}
+		if expected, actual := 60*time.Millisecond, time.Since(begin); actual < expected {
+			t.Errorf("expected: >= %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"description":"forbidden","code":403}`))
+		}))
+		defer server.Close()
+
+		client, err := New(server.URL, WithBackoff(time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = client.Health(context.Background())
+		if expected, actual := true, ErrForbidden(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := "forbidden", err.(*Error).Description; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := int32(1), atomic.LoadInt32(&attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("does not retry writes the server received", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client, err := New(server.URL, WithBackoff(time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := client.Ledgers().Insert(context.Background(), models.LedgerInput{Name: "name"}); err == nil {
+			t.Errorf("expected error")
+		}
+		if _, _, err := client.Journals().Insert(context.Background(), strings.NewReader("snowy"), 5, "text/plain", models.LedgerInput{}); err == nil {
+			t.Errorf("expected error")
+		}
+		if expected, actual := int32(2), atomic.LoadInt32(&attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("retries rate limited writes", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 2 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`{"resource_id":"` + uuid.MustNew().String() + `"}`))
+		}))
+		defer server.Close()
+
+		client, err := New(server.URL, WithBackoff(time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := client.Ledgers().Insert(context.Background(), models.LedgerInput{Name: "name"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int32(2), atomic.LoadInt32(&attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("retries writes that were never sent", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		client, err := New(server.URL, WithRetries(2), WithBackoff(20*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		begin := time.Now()
+		if _, err := client.Ledgers().Insert(context.Background(), models.LedgerInput{Name: "name"}); err == nil {
+			t.Errorf("expected error")
+		}
+		if expected, actual := 60*time.Millisecond, time.Since(begin); actual < expected {
+			t.Errorf("expected: >= %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("retries a journal with the whole content", func(t *testing.T) {
+		body := bytes.Repeat([]byte("snowy"), 1<<16)
+
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The first attempt is rejected before the content is read.
+			if atomic.AddInt32(&attempts, 1) < 2 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			file, _, err := r.FormFile("content")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			if b, err := ioutil.ReadAll(file); err != nil || !bytes.Equal(body, b) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"resource_id":"` + uuid.MustNew().String() + `"}`))
+		}))
+		defer server.Close()
+
+		client, err := New(server.URL, WithBackoff(time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := client.Journals().Insert(context.Background(), bytes.NewReader(body), int64(len(body)), "text/plain", models.LedgerInput{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int32(2), atomic.LoadInt32(&attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("does not retry a stream", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client, err := New(server.URL, WithBackoff(time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A pipe can't seek, so the body can't be sent again.
+		r, w := io.Pipe()
+		go func() {
+			w.Write([]byte("snowy"))
+			w.Close()
+		}()
+
+		if _, err := client.Contents().Put(context.Background(), r, 5, "text/plain"); err == nil {
+			t.Errorf("expected error")
+		}
+		if expected, actual := int32(1), atomic.LoadInt32(&attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
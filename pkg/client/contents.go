@@ -0,0 +1,128 @@
+package client
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+// These are the contents API URL paths.
+const (
+	contentsPath          = "/contents/"
+	contentsMultiplePath  = "/contents/multiple/"
+	contentsRevisionsPath = "/contents/revisions/"
+)
+
+// Contents is the client of the contents endpoints.
+type Contents struct {
+	client *Client
+}
+
+// Download is the content of a resource. The body has to be closed once it's
+// read.
+type Download struct {
+	ContentType string
+	// Size is the size of the body, or -1 if it's unknown.
+	Size int64
+	Body io.ReadCloser
+}
+
+// Put streams the content to the API, returning the stored content. The size
+// of the content is required up front. Content is stored by its address, so
+// storing it again changes nothing, and if the reader can seek, then the
+// request is retried by seeking back, otherwise it's only attempted once.
+func (c *Contents) Put(ctx context.Context, r io.Reader, size int64, contentType string) (models.Content, error) {
+	if size < 1 {
+		return models.Content{}, errors.New("content is empty")
+	}
+
+	body, retry := replayable(r)
+	res, err := c.client.do(ctx, request{
+		method:        "POST",
+		path:          contentsPath,
+		header:        http.Header{httpHeaderContentType: []string{contentType}},
+		body:          body,
+		contentLength: size,
+		noRetry:       !retry,
+		idempotent:    true,
+	})
+	if err != nil {
+		return models.Content{}, err
+	}
+
+	var content models.Content
+	err = decodeJSON(res, &content)
+	return content, err
+}
+
+// Select returns the content of the head revision of the resource.
+func (c *Contents) Select(ctx context.Context, resourceID uuid.UUID, query Query) (Download, error) {
+	res, err := c.client.do(ctx, request{
+		method: "GET",
+		path:   contentsPath,
+		query:  query.values(resourceID.String()),
+	})
+	if err != nil {
+		return Download{}, err
+	}
+
+	return Download{
+		ContentType: res.Header.Get(httpHeaderContentType),
+		Size:        res.ContentLength,
+		Body:        res.Body,
+	}, nil
+}
+
+// Revisions returns the content of every revision of the resource, as a zip
+// archive with a file per content address.
+func (c *Contents) Revisions(ctx context.Context, resourceID uuid.UUID, query Query) (*zip.Reader, error) {
+	return c.selectArchive(ctx, request{
+		method: "GET",
+		path:   contentsRevisionsPath,
+		query:  query.values(resourceID.String()),
+	})
+}
+
+// Multiple returns the content of the head revision of each resource, as a
+// zip archive with a file per content address.
+func (c *Contents) Multiple(ctx context.Context, resourceIDs []uuid.UUID) (*zip.Reader, error) {
+	idents := make([]string, len(resourceIDs))
+	for k, v := range resourceIDs {
+		idents[k] = v.String()
+	}
+
+	return c.selectArchive(ctx, request{
+		method: "GET",
+		path:   contentsMultiplePath,
+		query:  url.Values{"resource_ids": []string{strings.Join(idents, ",")}},
+	})
+}
+
+// selectArchive reads the zip archive of the response, which has to be read
+// in full as the archive index is at the end.
+func (c *Contents) selectArchive(ctx context.Context, req request) (*zip.Reader, error) {
+	res, err := c.client.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid response")
+	}
+	archive, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid archive")
+	}
+	return archive, nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is the error of a request that the API didn't serve successfully,
+// decoded from the body of the response.
+type Error struct {
+	Description string `json:"description"`
+	Code        int    `json:"code"`
+
+	// Type is the machine readable code of the error, for example
+	// "resource_not_found", along with the id of the request and the
+	// invalid fields of the input, if any.
+	Type      string       `json:"type"`
+	RequestID string       `json:"request_id"`
+	Fields    []FieldError `json:"errors"`
+}
+
+// FieldError describes why a field of the input is invalid.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("%d %s", e.Code, http.StatusText(e.Code))
+	}
+	return fmt.Sprintf("%d %s: %s", e.Code, http.StatusText(e.Code), e.Description)
+}
+
+// ErrBadRequest tests to see if the error passed is a bad request error or
+// not.
+func ErrBadRequest(err error) bool {
+	return errCode(err, http.StatusBadRequest)
+}
+
+// ErrUnauthorized tests to see if the error passed is a unauthorized error or
+// not.
+func ErrUnauthorized(err error) bool {
+	return errCode(err, http.StatusUnauthorized)
+}
+
+// ErrForbidden tests to see if the error passed is a forbidden error or not.
+func ErrForbidden(err error) bool {
+	return errCode(err, http.StatusForbidden)
+}
+
+// ErrNotFound tests to see if the error passed is a not found error or not.
+func ErrNotFound(err error) bool {
+	return errCode(err, http.StatusNotFound)
+}
+
+// ErrErased tests to see if the error passed is a erased (gone) error or not.
+func ErrErased(err error) bool {
+	return errCode(err, http.StatusGone)
+}
+
+// ErrPreconditionFailed tests to see if the error passed is a precondition
+// failed error or not, which is when writing a revision of an erased resource.
+func ErrPreconditionFailed(err error) bool {
+	return errCode(err, http.StatusPreconditionFailed)
+}
+
+// ErrQuotaExceeded tests to see if the error passed is a quota exceeded
+// (payload too large) error or not, which includes content over the size
+// limit.
+func ErrQuotaExceeded(err error) bool {
+	return errCode(err, http.StatusRequestEntityTooLarge)
+}
+
+// ErrType tests to see if the error passed is an error of the type or not,
+// for example "invalid_input".
+func ErrType(err error, typ string) bool {
+	if err != nil {
+		if e, ok := err.(*Error); ok {
+			return e.Type == typ
+		}
+	}
+	return false
+}
+
+// ErrTooManyRequests tests to see if the error passed is a too many requests
+// error or not, either from rate limiting or the daily quota.
+func ErrTooManyRequests(err error) bool {
+	return errCode(err, http.StatusTooManyRequests)
+}
+
+func errCode(err error, code int) bool {
+	if err != nil {
+		if e, ok := err.(*Error); ok {
+			return e.Code == code
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+// These are the journals API URL paths.
+const (
+	journalsPath = "/journals/"
+)
+
+const (
+	contentFormFile  = "content"
+	documentFormFile = "document"
+)
+
+// Journals is the client of the journals endpoints, which write the content
+// and the ledger of a resource in one request.
+type Journals struct {
+	client *Client
+}
+
+// Insert inserts the content along with a new ledger, returning the id of the
+// new resource and the address the content was stored at. The resource fields of the ledger are set from the content.
+// The content is streamed as part of a multipart request, so the request is
+// only retried if the reader can seek, and as a retry could insert the
+// resource twice, only if the server never received it.
+func (j *Journals) Insert(ctx context.Context, r io.Reader, size int64, contentType string, input models.LedgerInput) (uuid.UUID, string, error) {
+	return j.write(ctx, "POST", uuid.Empty, r, size, contentType, input)
+}
+
+// Append appends the content along with a new revision to the ledger of the
+// resource, returning the address the content was stored at.
+func (j *Journals) Append(ctx context.Context, resourceID uuid.UUID, r io.Reader, size int64, contentType string, input models.LedgerInput) (uuid.UUID, string, error) {
+	return j.write(ctx, "PUT", resourceID, r, size, contentType, input)
+}
+
+func (j *Journals) write(ctx context.Context, method string, resourceID uuid.UUID, r io.Reader, size int64, contentType string, input models.LedgerInput) (uuid.UUID, string, error) {
+	if size < 1 {
+		return uuid.Empty, "", errors.New("content is empty")
+	}
+
+	document, err := json.Marshal(input)
+	if err != nil {
+		return uuid.Empty, "", err
+	}
+
+	var (
+		content, retry = replayable(r)
+		boundary       = multipart.NewWriter(nil).Boundary()
+
+		// attempt is the body of the last attempt, which may still be
+		// reading the content, so it's stopped before the content is read
+		// again or given back to the caller.
+		attempt *multipartReader
+	)
+	defer func() {
+		if attempt != nil {
+			attempt.stop()
+		}
+	}()
+
+	req := request{
+		method: method,
+		path:   journalsPath,
+		header: http.Header{
+			httpHeaderContentType: []string{"multipart/form-data; boundary=" + boundary},
+		},
+		body: func() (io.Reader, error) {
+			if attempt != nil {
+				attempt.stop()
+			}
+			reader, err := content()
+			if err != nil {
+				return nil, err
+			}
+			attempt = multipartBody(boundary, reader, size, contentType, document)
+			return attempt, nil
+		},
+		noRetry: !retry,
+	}
+	if !resourceID.Zero() {
+		req.query = Query{}.values(resourceID.String())
+	}
+
+	res, err := j.client.do(ctx, req)
+	if err != nil {
+		return uuid.Empty, "", err
+	}
+
+	var result struct {
+		ResourceID      uuid.UUID `json:"resource_id"`
+		ResourceAddress string    `json:"resource_address"`
+	}
+	err = decodeJSON(res, &result)
+	return result.ResourceID, result.ResourceAddress, err
+}
+
+// multipartReader reads the multipart body as it's written by a goroutine.
+type multipartReader struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+// stop stops the goroutine writing the body, waiting for it to return, so
+// that the content is no longer read.
+func (r *multipartReader) stop() {
+	r.CloseWithError(errors.New("request finished"))
+	<-r.done
+}
+
+// multipartBody streams the multipart body of the content and the document,
+// each part with the content type and length the API requires.
+func multipartBody(boundary string, content io.Reader, size int64, contentType string, document []byte) *multipartReader {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		writer := multipart.NewWriter(pw)
+		if err := writer.SetBoundary(boundary); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		part, err := writer.CreatePart(partHeader(contentFormFile, contentType, size))
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.CopyN(part, content, size); err != nil {
+			pw.CloseWithError(errors.Wrap(err, "unable to read content"))
+			return
+		}
+
+		part, err = writer.CreatePart(partHeader(documentFormFile, defaultContentType, int64(len(document))))
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := part.Write(document); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		pw.CloseWithError(writer.Close())
+	}()
+	return &multipartReader{pr, done}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func partHeader(name, contentType string, size int64) textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(name), quoteEscaper.Replace(name)))
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Length", strconv.FormatInt(size, 10))
+	return h
+}
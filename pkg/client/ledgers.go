@@ -0,0 +1,275 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/events"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+// These are the ledgers API URL paths.
+const (
+	ledgersPath              = "/ledgers/"
+	ledgersRevisionsPath     = "/ledgers/revisions/"
+	ledgersForkPath          = "/ledgers/fork/"
+	ledgersForkRevisionsPath = "/ledgers/fork/revisions/"
+	ledgersVerifyPath        = "/ledgers/verify/"
+	ledgersACLPath           = "/ledgers/acl/"
+	ledgersACLRevisionsPath  = "/ledgers/acl/revisions/"
+	ledgersStatisticsPath    = "/ledgers/statistics/"
+	ledgersEventsPath        = "/ledgers/events/"
+)
+
+// Ledgers is the client of the ledgers endpoints.
+type Ledgers struct {
+	client *Client
+}
+
+// Verification is the result of verifying the hash chain of a ledger.
+type Verification struct {
+	ResourceID uuid.UUID `json:"resource_id"`
+	Revisions  int       `json:"revisions"`
+	Valid      bool      `json:"valid"`
+	BrokenID   string    `json:"broken_id"`
+	Reason     string    `json:"reason"`
+}
+
+// Statistics are the statistics of the ledgers of a tenant.
+type Statistics struct {
+	Tenant       string `json:"tenant"`
+	TotalLedgers int    `json:"total_ledgers"`
+}
+
+// Select returns the head ledger of the resource.
+func (l *Ledgers) Select(ctx context.Context, resourceID uuid.UUID, query Query) (models.Ledger, error) {
+	res, err := l.client.do(ctx, request{
+		method: "GET",
+		path:   ledgersPath,
+		query:  query.values(resourceID.String()),
+	})
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	var ledger models.Ledger
+	err = decodeJSON(res, &ledger)
+	return ledger, err
+}
+
+// Revisions returns every revision of the ledger of the resource.
+func (l *Ledgers) Revisions(ctx context.Context, resourceID uuid.UUID, query Query) ([]models.Ledger, error) {
+	return l.selectLedgers(ctx, ledgersRevisionsPath, resourceID, query)
+}
+
+// ForkRevisions returns every ledger forked from the resource.
+func (l *Ledgers) ForkRevisions(ctx context.Context, resourceID uuid.UUID) ([]models.Ledger, error) {
+	return l.selectLedgers(ctx, ledgersForkRevisionsPath, resourceID, Query{})
+}
+
+func (l *Ledgers) selectLedgers(ctx context.Context, path string, resourceID uuid.UUID, query Query) ([]models.Ledger, error) {
+	res, err := l.client.do(ctx, request{
+		method: "GET",
+		path:   path,
+		query:  query.values(resourceID.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ledgers []models.Ledger
+	err = decodeJSON(res, &ledgers)
+	return ledgers, err
+}
+
+// Insert inserts a new ledger, returning the id of the new resource.
+func (l *Ledgers) Insert(ctx context.Context, input models.LedgerInput) (uuid.UUID, error) {
+	return l.writeLedger(ctx, "POST", ledgersPath, uuid.Empty, input)
+}
+
+// Append appends a new revision to the ledger of the resource.
+func (l *Ledgers) Append(ctx context.Context, resourceID uuid.UUID, input models.LedgerInput) (uuid.UUID, error) {
+	return l.writeLedger(ctx, "PUT", ledgersPath, resourceID, input)
+}
+
+// Fork forks the ledger of the resource, returning the id of the new
+// resource.
+func (l *Ledgers) Fork(ctx context.Context, resourceID uuid.UUID, input models.LedgerInput) (uuid.UUID, error) {
+	return l.writeLedger(ctx, "PUT", ledgersForkPath, resourceID, input)
+}
+
+func (l *Ledgers) writeLedger(ctx context.Context, method, path string, resourceID uuid.UUID, input models.LedgerInput) (uuid.UUID, error) {
+	body, contentLength, err := jsonBody(input)
+	if err != nil {
+		return uuid.Empty, err
+	}
+
+	req := request{
+		method:        method,
+		path:          path,
+		header:        http.Header{httpHeaderContentType: []string{defaultContentType}},
+		body:          body,
+		contentLength: contentLength,
+	}
+	if !resourceID.Zero() {
+		req.query = Query{}.values(resourceID.String())
+	}
+
+	res, err := l.client.do(ctx, req)
+	if err != nil {
+		return uuid.Empty, err
+	}
+	return decodeResourceID(res)
+}
+
+// Verify verifies the hash chain of the ledger of the resource.
+func (l *Ledgers) Verify(ctx context.Context, resourceID uuid.UUID) (Verification, error) {
+	res, err := l.client.do(ctx, request{
+		method: "GET",
+		path:   ledgersVerifyPath,
+		query:  Query{}.values(resourceID.String()),
+	})
+	if err != nil {
+		return Verification{}, err
+	}
+
+	var verification Verification
+	err = decodeJSON(res, &verification)
+	return verification, err
+}
+
+// ACL returns the access control list of the resource.
+func (l *Ledgers) ACL(ctx context.Context, resourceID uuid.UUID) (models.ACL, error) {
+	res, err := l.client.do(ctx, request{
+		method: "GET",
+		path:   ledgersACLPath,
+		query:  Query{}.values(resourceID.String()),
+	})
+	if err != nil {
+		return models.ACL{}, err
+	}
+
+	var acl models.ACL
+	err = decodeJSON(res, &acl)
+	return acl, err
+}
+
+// UpdateACL updates the access control list of the resource.
+func (l *Ledgers) UpdateACL(ctx context.Context, resourceID uuid.UUID, input models.ACLInput) (models.ACL, error) {
+	body, contentLength, err := jsonBody(input)
+	if err != nil {
+		return models.ACL{}, err
+	}
+
+	res, err := l.client.do(ctx, request{
+		method:        "PUT",
+		path:          ledgersACLPath,
+		query:         Query{}.values(resourceID.String()),
+		header:        http.Header{httpHeaderContentType: []string{defaultContentType}},
+		body:          body,
+		contentLength: contentLength,
+	})
+	if err != nil {
+		return models.ACL{}, err
+	}
+
+	var acl models.ACL
+	err = decodeJSON(res, &acl)
+	return acl, err
+}
+
+// ACLRevisions returns every revision of the access control list of the
+// resource.
+func (l *Ledgers) ACLRevisions(ctx context.Context, resourceID uuid.UUID) ([]models.ACL, error) {
+	res, err := l.client.do(ctx, request{
+		method: "GET",
+		path:   ledgersACLRevisionsPath,
+		query:  Query{}.values(resourceID.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var acls []models.ACL
+	err = decodeJSON(res, &acls)
+	return acls, err
+}
+
+// Statistics returns the statistics of the ledgers of the tenant, which only
+// the admins can read.
+func (l *Ledgers) Statistics(ctx context.Context) (Statistics, error) {
+	res, err := l.client.do(ctx, request{
+		method: "GET",
+		path:   ledgersStatisticsPath,
+	})
+	if err != nil {
+		return Statistics{}, err
+	}
+
+	var statistics Statistics
+	err = decodeJSON(res, &statistics)
+	return statistics, err
+}
+
+// Events streams the ledger events that match the query to fn, starting
+// after the last event id if it's not empty. The stream runs until the
+// context is done, the server closes the stream or fn returns an error. The
+// events stream is never retried, the caller reconnects with the id of the
+// last event it saw.
+func (l *Ledgers) Events(ctx context.Context, resourceID uuid.UUID, query Query, lastEventID string, fn func(events.Event) error) error {
+	var id string
+	if !resourceID.Zero() {
+		id = resourceID.String()
+	}
+
+	req := request{
+		method:  "GET",
+		path:    ledgersEventsPath,
+		query:   query.values(id),
+		noRetry: true,
+	}
+	if lastEventID != "" {
+		req.header = http.Header{httpHeaderLastEventID: []string{lastEventID}}
+	}
+
+	res, err := l.client.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			// Ids and types are also in the data, and comments are heartbeats.
+			continue
+		}
+
+		var event events.Event
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &event); err != nil {
+			return errors.Wrap(err, "invalid event")
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// decodeResourceID decodes the resource id of the response of a write.
+func decodeResourceID(res *http.Response) (uuid.UUID, error) {
+	var result struct {
+		ResourceID uuid.UUID `json:"resource_id"`
+	}
+	err := decodeJSON(res, &result)
+	return result.ResourceID, err
+}
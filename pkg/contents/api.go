@@ -10,10 +10,13 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/gorilla/mux"
+	"github.com/trussle/snowy/pkg/auth"
 	errs "github.com/trussle/snowy/pkg/http"
 	"github.com/trussle/snowy/pkg/metrics"
 	"github.com/trussle/snowy/pkg/models"
 	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/snowy/pkg/trace"
 )
 
 // These are the query API URL paths.
@@ -26,7 +29,7 @@ const (
 
 // API serves the query API
 type API struct {
-	handler        http.Handler
+	handler        *mux.Router
 	repository     repository.Repository
 	action         chan func()
 	stop           chan chan struct{}
@@ -77,7 +80,7 @@ func (a *API) Close() {
 }
 
 func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	level.Info(a.logger).Log("url", r.URL.String())
+	level.Info(trace.Logger(r.Context(), a.logger)).Log("url", r.URL.String())
 
 	iw := &interceptingWriter{http.StatusOK, w}
 	w = iw
@@ -89,7 +92,7 @@ func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer func(begin time.Time) {
 		a.duration.WithLabelValues(
 			r.Method,
-			r.URL.Path,
+			metrics.Route(a.handler, r),
 			strconv.Itoa(iw.code),
 		).Observe(time.Since(begin).Seconds())
 	}(time.Now())
@@ -106,13 +109,16 @@ func (a *API) handleSelect(w http.ResponseWriter, r *http.Request) {
 	// Validate user input.
 	var qp SelectQueryParams
 	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
-		a.errors.Error(w, err.Error(), http.StatusBadRequest)
+		a.errors.BadRequest(w, r, err.Error())
 		return
 	}
 
 	options, err := repository.BuildQuery(
 		repository.WithQueryTags(qp.Tags),
 		repository.WithQueryAuthorID(qp.AuthorID),
+		repository.WithQueryAcceptEncoding(r.Header.Get("Accept-Encoding")),
+		principalQuery(r),
+		tenantQuery(r),
 	)
 	if err != nil {
 		a.errors.BadRequest(w, r, err.Error())
@@ -120,15 +126,25 @@ func (a *API) handleSelect(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var (
-		notFound      = make(chan struct{})
+		notFound      = make(chan error)
+		gone          = make(chan error)
+		forbidden     = make(chan error)
 		internalError = make(chan error)
 		result        = make(chan models.Content)
 	)
 	go func() {
-		content, err := a.repository.SelectContent(qp.ResourceID, options)
+		content, err := a.repo(r).SelectContent(qp.ResourceID, options)
 		if err != nil {
 			if repository.ErrNotFound(err) {
-				notFound <- struct{}{}
+				notFound <- err
+				return
+			}
+			if repository.ErrErased(err) {
+				gone <- err
+				return
+			}
+			if repository.ErrForbidden(err) {
+				forbidden <- err
 				return
 			}
 			internalError <- err
@@ -138,10 +154,14 @@ func (a *API) handleSelect(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	select {
-	case <-notFound:
-		a.errors.Error(w, "not found", http.StatusNotFound)
+	case err := <-notFound:
+		a.errors.ResourceNotFound(w, r, err.Error())
+	case err := <-gone:
+		a.errors.Erased(w, r, err.Error())
+	case err := <-forbidden:
+		a.errors.Forbidden(w, r, err.Error())
 	case err := <-internalError:
-		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+		a.errors.InternalServerError(w, r, err.Error())
 	case content := <-result:
 		// Make sure we collect the content for the result.
 		qr := SelectQueryResult{Errors: a.errors, Params: qp}
@@ -164,13 +184,24 @@ func (a *API) handleInsert(w http.ResponseWriter, r *http.Request) {
 	// Validate user input.
 	var qp InsertQueryParams
 	if err := qp.DecodeFrom(r.URL, r.Header, queryRequired); err != nil {
-		a.errors.Error(w, err.Error(), http.StatusBadRequest)
+		if errContentTooLarge(err) {
+			a.errors.PayloadTooLarge(w, r, err.Error())
+			return
+		}
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(tenantQuery(r))
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
 		return
 	}
 
 	var (
 		internalError   = make(chan error)
 		badRequestError = make(chan error)
+		quotaError      = make(chan error)
 		result          = make(chan models.Content)
 	)
 	a.action <- func() {
@@ -180,8 +211,12 @@ func (a *API) handleInsert(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		res, err := a.repository.PutContent(content)
+		res, err := a.repo(r).PutContent(content, options)
 		if err != nil {
+			if repository.ErrQuotaExceeded(err) {
+				quotaError <- err
+				return
+			}
 			internalError <- err
 			return
 		}
@@ -194,9 +229,15 @@ func (a *API) handleInsert(w http.ResponseWriter, r *http.Request) {
 
 	select {
 	case err := <-internalError:
-		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+		a.errors.InternalServerError(w, r, err.Error())
 	case err := <-badRequestError:
-		a.errors.Error(w, err.Error(), http.StatusBadRequest)
+		if errContentTooLarge(err) {
+			a.errors.PayloadTooLarge(w, r, err.Error())
+			return
+		}
+		a.errors.InvalidInput(w, r, err)
+	case err := <-quotaError:
+		a.errors.QuotaExceeded(w, r, err.Error())
 	case content := <-result:
 		// Make sure we collect the content for the result.
 		qr := InsertQueryResult{Errors: a.errors, Params: qp}
@@ -221,6 +262,16 @@ func (a *API) handleMultiple(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	options, err := repository.BuildQuery(
+		repository.WithQueryAuthorID(""),
+		principalQuery(r),
+		tenantQuery(r),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
 	var (
 		internalError = make(chan error)
 		result        = make(chan []models.Content)
@@ -228,7 +279,7 @@ func (a *API) handleMultiple(w http.ResponseWriter, r *http.Request) {
 	go func() {
 		contents := make([]models.Content, len(qp.ResourceIDs))
 		for k, v := range qp.ResourceIDs {
-			c, err := a.repository.SelectContent(v, repository.BuildEmptyQuery())
+			c, err := a.repo(r).SelectContent(v, options)
 			if err != nil {
 				internalError <- err
 				return
@@ -240,7 +291,15 @@ func (a *API) handleMultiple(w http.ResponseWriter, r *http.Request) {
 
 	select {
 	case err := <-internalError:
-		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+		if repository.ErrErased(err) {
+			a.errors.Erased(w, r, err.Error())
+			return
+		}
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
 	case contents := <-result:
 		// Make sure we collect the content for the result.
 		qr := MultipleQueryResult{Errors: a.errors, Params: qp}
@@ -268,6 +327,8 @@ func (a *API) handleSelectRevisions(w http.ResponseWriter, r *http.Request) {
 	options, err := repository.BuildQuery(
 		repository.WithQueryTags(qp.Tags),
 		repository.WithQueryAuthorID(qp.AuthorID),
+		principalQuery(r),
+		tenantQuery(r),
 	)
 	if err != nil {
 		a.errors.BadRequest(w, r, err.Error())
@@ -279,7 +340,7 @@ func (a *API) handleSelectRevisions(w http.ResponseWriter, r *http.Request) {
 		result        = make(chan []models.Content)
 	)
 	go func() {
-		contents, err := a.repository.SelectContents(qp.ResourceID, options)
+		contents, err := a.repo(r).SelectContents(qp.ResourceID, options)
 		if err != nil {
 			internalError <- err
 			return
@@ -289,7 +350,15 @@ func (a *API) handleSelectRevisions(w http.ResponseWriter, r *http.Request) {
 
 	select {
 	case err := <-internalError:
-		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+		if repository.ErrErased(err) {
+			a.errors.Erased(w, r, err.Error())
+			return
+		}
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
 	case contents := <-result:
 		// Make sure we collect the content for the result.
 		qr := SelectRevisionsQueryResult{Errors: a.errors, Params: qp}
@@ -347,3 +416,22 @@ func ingestContent(file io.Reader, header ContentHeader) (models.Content, int, e
 	}
 	return content, len(bytes), err
 }
+
+// principalQuery returns the query option for the principal of the request,
+// so that the access control list of the resource is checked.
+func principalQuery(r *http.Request) repository.QueryOption {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	return repository.WithQueryPrincipal(principal.ID, principal.Groups)
+}
+
+// tenantQuery returns the query option for the tenant of the request, so that
+// the repository is scoped to the tenant.
+func tenantQuery(r *http.Request) repository.QueryOption {
+	return repository.WithQueryTenant(tenant.FromContext(r.Context()))
+}
+
+// repo returns the repository bound to the context of the request, so that
+// the calls to the repository are traced as part of the request.
+func (a *API) repo(r *http.Request) repository.Repository {
+	return repository.WithContext(r.Context(), a.repository)
+}
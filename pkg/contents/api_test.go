@@ -15,6 +15,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/trussle/harness/generators"
 	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/metrics"
 	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
 	"github.com/trussle/snowy/pkg/models"
 	"github.com/trussle/snowy/pkg/repository"
@@ -263,6 +264,59 @@ func TestGetAPI(t *testing.T) {
 			t.Error(err)
 		}
 	})
+
+	t.Run("get with resource_id but erased", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(uid uuid.UUID, bytes []byte) bool {
+			var (
+				clients      = metricMocks.NewMockGauge(ctrl)
+				writtenBytes = metricMocks.NewMockCounter(ctrl)
+				records      = metricMocks.NewMockCounter(ctrl)
+				duration     = metricMocks.NewMockHistogramVec(ctrl)
+				observer     = metricMocks.NewMockObserver(ctrl)
+				repo         = repoMocks.NewMockRepository(ctrl)
+
+				api    = NewAPI(repo, log.NewNopLogger(), clients, writtenBytes, records, duration)
+				server = httptest.NewServer(api)
+
+				content, err = models.BuildContent(
+					models.WithSize(int64(len(bytes))),
+					models.WithBytes(bytes),
+				)
+			)
+			defer func() { api.Close(); server.Close() }()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
+
+			duration.EXPECT().WithLabelValues("GET", "/", "410").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+			repo.EXPECT().SelectContent(uid, Query()).Times(1).Return(content, errErased{errors.New("failure")})
+
+			resp, err := http.Get(fmt.Sprintf("%s?resource_id=%s", server.URL, uid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if expected, actual := http.StatusGone, resp.StatusCode; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+
+			return true
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
 }
 
 func TestSelectRevisionsAPI(t *testing.T) {
@@ -480,7 +534,7 @@ func TestPutAPI(t *testing.T) {
 		clients.EXPECT().Inc().Times(1)
 		clients.EXPECT().Dec().Times(1)
 
-		duration.EXPECT().WithLabelValues("POST", "/", "400").Return(observer).Times(1)
+		duration.EXPECT().WithLabelValues("POST", "/", "413").Return(observer).Times(1)
 		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
 		resp, err := http.Post(server.URL, "plain/text", bytes.NewBuffer(b))
@@ -489,7 +543,7 @@ func TestPutAPI(t *testing.T) {
 		}
 		defer resp.Body.Close()
 
-		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+		if expected, actual := http.StatusRequestEntityTooLarge, resp.StatusCode; expected != actual {
 			t.Errorf("expected: %d, actual: %d", expected, actual)
 		}
 	})
@@ -516,7 +570,7 @@ func TestPutAPI(t *testing.T) {
 		clients.EXPECT().Inc().Times(1)
 		clients.EXPECT().Dec().Times(1)
 
-		duration.EXPECT().WithLabelValues("POST", "/", "400").Return(observer).Times(1)
+		duration.EXPECT().WithLabelValues("POST", "/", "413").Return(observer).Times(1)
 		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
 		req, err := http.NewRequest("POST", server.URL, bytes.NewBuffer(b))
@@ -533,7 +587,7 @@ func TestPutAPI(t *testing.T) {
 		}
 		defer resp.Body.Close()
 
-		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+		if expected, actual := http.StatusRequestEntityTooLarge, resp.StatusCode; expected != actual {
 			t.Errorf("expected: %d, actual: %d", expected, actual)
 		}
 	})
@@ -572,7 +626,7 @@ func TestPutAPI(t *testing.T) {
 			duration.EXPECT().WithLabelValues("POST", "/", "500").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-			repo.EXPECT().PutContent(Content(content)).Return(models.Content{}, errors.New("failure")).Times(1)
+			repo.EXPECT().PutContent(Content(content), repository.Query{}).Return(models.Content{}, errors.New("failure")).Times(1)
 
 			resp, err := http.Post(server.URL, "plain/text", bytes.NewBuffer(b))
 			if err != nil {
@@ -628,7 +682,7 @@ func TestPutAPI(t *testing.T) {
 			records.EXPECT().Inc().Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-			repo.EXPECT().PutContent(Content(content)).Return(content, nil).Times(1)
+			repo.EXPECT().PutContent(Content(content), repository.Query{}).Return(content, nil).Times(1)
 
 			resp, err := http.Post(server.URL, "plain/text", bytes.NewBuffer(b))
 			if err != nil {
@@ -672,7 +726,7 @@ func TestNotFoundAPI(t *testing.T) {
 			clients.EXPECT().Inc().Times(1)
 			clients.EXPECT().Dec().Times(1)
 
-			duration.EXPECT().WithLabelValues("GET", fmt.Sprintf("/%s", resource), "404").Return(observer).Times(1)
+			duration.EXPECT().WithLabelValues("GET", metrics.UnmatchedRoute, "404").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
 			resp, err := http.Get(fmt.Sprintf("%s/%s", server.URL, resource))
@@ -884,6 +938,18 @@ func (e errNotFound) NotFound() bool {
 	return true
 }
 
+type errErased struct {
+	err error
+}
+
+func (e errErased) Error() string {
+	return e.err.Error()
+}
+
+func (e errErased) Erased() bool {
+	return true
+}
+
 type contentMatcher struct {
 	content models.Content
 }
@@ -0,0 +1,77 @@
+package contents
+
+import "github.com/trussle/snowy/pkg/openapi"
+
+const (
+	defaultAnyContentType = "*/*"
+	defaultZipContentType = "application/zip"
+)
+
+// OpenAPI describes the routes of the API, relative to where the API is
+// mounted.
+func OpenAPI() openapi.Paths {
+	var (
+		tags    = []string{"contents"}
+		content = openapi.StringFormat("binary")
+		archive = openapi.Response{
+			Description: "A zip archive with a file per content address",
+			Content: map[string]openapi.MediaType{
+				defaultZipContentType: {Schema: content},
+			},
+		}
+		insert = openapi.Operation{
+			Summary:    "Insert a content",
+			Tags:       tags,
+			Parameters: InsertQueryParams{}.Parameters(queryRequired),
+			RequestBody: &openapi.RequestBody{
+				Required: true,
+				Content: map[string]openapi.MediaType{
+					defaultAnyContentType: {Schema: content},
+				},
+			},
+			Responses: openapi.Responses(openapi.Response{
+				Description: "The content",
+				Content:     openapi.JSON(openapi.Content()),
+			}, "400", "413", "500"),
+		}
+		post, put = insert, insert
+	)
+	post.OperationID, put.OperationID = "insertContent", "putContent"
+
+	return openapi.Paths{
+		APIPathSelectQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectContent",
+				Summary:     "Select the content of the head ledger of a resource",
+				Tags:        tags,
+				Parameters:  SelectQueryParams{}.Parameters(queryRequired),
+				Responses: openapi.Responses(openapi.Response{
+					Description: "The content, with the content type of the content",
+					Content: map[string]openapi.MediaType{
+						defaultAnyContentType: {Schema: content},
+					},
+				}, "400", "403", "404", "410", "500"),
+			},
+			"post": post,
+			"put":  put,
+		},
+		APIPathMultipleQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectMultipleContents",
+				Summary:     "Select the content of the head ledger of each resource",
+				Tags:        tags,
+				Parameters:  MultipleQueryParams{}.Parameters(queryRequired),
+				Responses:   openapi.Responses(archive, "400", "403", "410", "500"),
+			},
+		},
+		APIPathSelectRevisionsQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectContentRevisions",
+				Summary:     "Select the content of every revision of a resource",
+				Tags:        tags,
+				Parameters:  SelectQueryParams{}.Parameters(queryRequired),
+				Responses:   openapi.Responses(archive, "400", "403", "410", "500"),
+			},
+		},
+	}
+}
@@ -0,0 +1,125 @@
+package contents
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	errs "github.com/trussle/snowy/pkg/http"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/openapi"
+	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+)
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	paths := OpenAPI()
+
+	t.Run("routes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := NewAPI(repoMocks.NewMockRepository(ctrl), log.NewNopLogger(),
+			metricMocks.NewMockGauge(ctrl),
+			metricMocks.NewMockCounter(ctrl),
+			metricMocks.NewMockCounter(ctrl),
+			metricMocks.NewMockHistogramVec(ctrl),
+		)
+		defer api.Close()
+
+		routes, err := openapi.MuxRoutes(api.handler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := openapi.CheckRoutes(paths, routes); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		header := http.Header{
+			"Content-Type":   []string{"application/octet-stream"},
+			"Content-Length": []string{"1"},
+		}
+
+		for route, decode := range map[string]func(*url.URL) error{
+			"GET " + APIPathSelectQuery: func(u *url.URL) error {
+				var qp SelectQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"PUT " + APIPathInsertQuery: func(u *url.URL) error {
+				var qp InsertQueryParams
+				return qp.DecodeFrom(u, header, queryRequired)
+			},
+			"POST " + APIPathInsertQuery: func(u *url.URL) error {
+				var qp InsertQueryParams
+				return qp.DecodeFrom(u, header, queryRequired)
+			},
+			"GET " + APIPathMultipleQuery: func(u *url.URL) error {
+				var qp MultipleQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"GET " + APIPathSelectRevisionsQuery: func(u *url.URL) error {
+				var qp SelectQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+		} {
+			op := operation(t, paths, route)
+			if err := openapi.CheckParameters(op.Parameters, decode); err != nil {
+				t.Errorf("%s: %v", route, err)
+			}
+		}
+	})
+
+	t.Run("responses", func(t *testing.T) {
+		content, err := models.BuildContent(
+			models.WithAddress("address"),
+			models.WithSize(1),
+			models.WithContentType("application/octet-stream"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, method := range []string{"PUT", "POST"} {
+			route := method + " " + APIPathInsertQuery
+			op := operation(t, paths, route)
+
+			w := httptest.NewRecorder()
+			qr := InsertQueryResult{Errors: errs.NewError(log.NewNopLogger()), Content: content}
+			qr.EncodeTo(w)
+
+			if err := openapi.Validate(op.Responses["200"].Content[defaultContentType].Schema, w.Body.Bytes()); err != nil {
+				t.Errorf("%s: %v", route, err)
+			}
+		}
+	})
+
+	t.Run("error responses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		errs.NewError(log.NewNopLogger()).NotFound(w, nil)
+
+		media, ok := operation(t, paths, "GET "+APIPathSelectQuery).Responses["404"].Content[w.Header().Get("Content-Type")]
+		if !ok {
+			t.Fatalf("404 response of %q not described", w.Header().Get("Content-Type"))
+		}
+		if err := openapi.Validate(media.Schema, w.Body.Bytes()); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func operation(t *testing.T, paths openapi.Paths, route string) openapi.Operation {
+	parts := strings.SplitN(route, " ", 2)
+	op, ok := paths[parts[1]][strings.ToLower(parts[0])]
+	if !ok {
+		t.Fatalf("route %q not described", route)
+	}
+	return op
+}
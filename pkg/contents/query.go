@@ -13,6 +13,7 @@ import (
 	"github.com/pkg/errors"
 	errs "github.com/trussle/snowy/pkg/http"
 	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/openapi"
 	"github.com/trussle/uuid"
 )
 
@@ -64,6 +65,15 @@ func (qp *SelectQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads.
+func (SelectQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("resource_id", "id of the resource", rb == queryRequired, openapi.StringFormat("uuid")),
+		openapi.Query("query.tags", "comma separated tags the ledger has to have", false, openapi.String()),
+		openapi.Query("query.author_id", "author the ledger has to be written by", false, openapi.String()),
+	}
+}
+
 // SelectQueryResult contains statistics about the query.
 type SelectQueryResult struct {
 	Errors   errs.Error
@@ -78,6 +88,10 @@ func (qr *SelectQueryResult) EncodeTo(w http.ResponseWriter) {
 	w.Header().Set(httpHeaderResourceID, qr.Params.ResourceID.String())
 	w.Header().Set(httpHeaderContentType, qr.Content.ContentType())
 	w.Header().Set(httpHeaderContentLength, strconv.FormatInt(qr.Content.Size(), 10))
+	w.Header().Set(httpHeaderVary, "Accept-Encoding")
+	if encoding := qr.Content.ContentEncoding(); encoding != "" {
+		w.Header().Set(httpHeaderContentEncoding, encoding)
+	}
 
 	bytes, err := qr.Content.Bytes()
 	if err != nil {
@@ -114,7 +128,7 @@ func (qp *InsertQueryParams) DecodeFrom(u *url.URL, h http.Header, rb queryBehav
 		if err != nil {
 			return errors.New("error parsing 'content-length' (required) query")
 		} else if size > defaultMaxContentLength {
-			return errors.Errorf("error request body too large")
+			return errTooLarge{errors.Errorf("error request body too large")}
 		} else if size < 1 {
 			return errors.Errorf("error request body is empty")
 		}
@@ -125,6 +139,14 @@ func (qp *InsertQueryParams) DecodeFrom(u *url.URL, h http.Header, rb queryBehav
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads, the content type
+// is described by the request body.
+func (InsertQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Header(httpHeaderContentLength, "size of the content", rb == queryRequired, openapi.Integer()),
+	}
+}
+
 // ContentType returns the content-type from the header
 func (qp InsertQueryParams) ContentType() string { return qp.contentType }
 
@@ -185,6 +207,35 @@ func (qr *SelectRevisionsQueryResult) EncodeTo(w http.ResponseWriter) {
 	writer.Flush()
 }
 
+type contentTooLarge interface {
+	ContentTooLarge() bool
+}
+
+type errTooLarge struct {
+	err error
+}
+
+func (e errTooLarge) Error() string {
+	return e.err.Error()
+}
+
+func (e errTooLarge) ContentTooLarge() bool {
+	return true
+}
+
+// errContentTooLarge tests to see if the error passed is a content too large
+// error or not. The body is also limited by a http.MaxBytesReader, which only
+// reports the limit by the message of the error.
+func errContentTooLarge(err error) bool {
+	if err != nil {
+		if _, ok := err.(contentTooLarge); ok {
+			return true
+		}
+		return err.Error() == "http: request body too large"
+	}
+	return false
+}
+
 // MultipleQueryParams defines all the dimensions of a query.
 type MultipleQueryParams struct {
 	ResourceIDs []uuid.UUID `json:"resource_ids"`
@@ -214,6 +265,13 @@ func (qp *MultipleQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads.
+func (MultipleQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("resource_ids", "comma separated ids of the resources", rb == queryRequired, openapi.Array(openapi.StringFormat("uuid"))),
+	}
+}
+
 // MultipleQueryResult contains statistics about the query.
 type MultipleQueryResult struct {
 	Errors   errs.Error
@@ -261,6 +319,8 @@ const (
 	httpHeaderQueryAuthorID           = "X-Query-Author-ID"
 	httpHeaderContentType             = "Content-Type"
 	httpHeaderContentLength           = "Content-Length"
+	httpHeaderContentEncoding         = "Content-Encoding"
+	httpHeaderVary                    = "Vary"
 	httpHeaderContentDisposition      = "Content-Disposition"
 	httpHeaderContentTransferEncoding = "Content-Transfer-Encoding"
 )
@@ -0,0 +1,165 @@
+package events
+
+import (
+	"sync"
+)
+
+const (
+	defaultHistorySize = 1024
+)
+
+// Publisher publishes the events of the ledgers.
+type Publisher interface {
+
+	// Publish publishes the event to every subscriber.
+	Publish(Event) error
+}
+
+// Bus is an event bus, that every subscriber receives the published events
+// from.
+type Bus interface {
+	Publisher
+
+	// Subscribe subscribes to the events that are published from now on. If
+	// the last event id is one of the recent events, then the events after it
+	// are part of the subscription as a backlog, otherwise the subscription
+	// is reset.
+	Subscribe(lastEventID string) *Subscription
+
+	// Run runs the bus, until it's stopped.
+	Run() error
+
+	// Stop stops the bus.
+	Stop()
+}
+
+// Subscription represents the events a subscriber receives. The backlog is
+// the events that were missed since the last event id. If the last event id
+// is no longer one of the recent events, then the events that were missed
+// can't be known, so the subscription is a reset and the subscriber has to
+// read the ledgers again. The channel is closed if the subscriber falls too
+// far behind, in which case the subscriber should resubscribe from the last
+// event it received.
+type Subscription struct {
+	Backlog []Event
+	Reset   bool
+	C       <-chan Event
+
+	c      chan Event
+	bus    *localBus
+	closed bool
+}
+
+// Close stops the subscription receiving any more events.
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s)
+}
+
+// localBus dispatches the events to the subscribers with in the process,
+// keeping the recent events so that subscribers can resume.
+type localBus struct {
+	mutex       sync.Mutex
+	history     []Event
+	size        int
+	subscribers map[*Subscription]struct{}
+	stop        chan chan struct{}
+}
+
+// NewLocalBus creates a Bus that only dispatches the events with in the
+// process, keeping the last size events so that subscribers can resume.
+func NewLocalBus(size int) Bus {
+	return newLocalBus(size)
+}
+
+func newLocalBus(size int) *localBus {
+	if size < 1 {
+		size = defaultHistorySize
+	}
+	return &localBus{
+		size:        size,
+		subscribers: make(map[*Subscription]struct{}),
+		stop:        make(chan chan struct{}),
+	}
+}
+
+func (b *localBus) Publish(event Event) error {
+	b.dispatch(event)
+	return nil
+}
+
+func (b *localBus) Subscribe(lastEventID string) *Subscription {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c := make(chan Event, b.size)
+	s := &Subscription{
+		C:   c,
+		c:   c,
+		bus: b,
+	}
+	if lastEventID != "" {
+		s.Reset = true
+		for k, event := range b.history {
+			if event.ID == lastEventID {
+				s.Backlog = append(make([]Event, 0), b.history[k+1:]...)
+				s.Reset = false
+				break
+			}
+		}
+	}
+
+	b.subscribers[s] = struct{}{}
+	return s
+}
+
+func (b *localBus) Run() error {
+	for {
+		select {
+		case c := <-b.stop:
+			close(c)
+			return nil
+		}
+	}
+}
+
+func (b *localBus) Stop() {
+	c := make(chan struct{})
+	b.stop <- c
+	<-c
+}
+
+// dispatch records the event and sends it to every subscriber. Subscribers
+// that can't keep up are dropped, rather than holding up everyone else.
+func (b *localBus) dispatch(event Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.history = append(b.history, event)
+	if len(b.history) > b.size {
+		b.history = append(make([]Event, 0, b.size), b.history[len(b.history)-b.size:]...)
+	}
+
+	for s := range b.subscribers {
+		select {
+		case s.c <- event:
+		default:
+			b.close(s)
+		}
+	}
+}
+
+func (b *localBus) unsubscribe(s *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.close(s)
+}
+
+func (b *localBus) close(s *Subscription) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(b.subscribers, s)
+	close(s.c)
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLocalBus(t *testing.T) {
+	t.Parallel()
+
+	newEvent := func(id int) Event {
+		return Event{ID: fmt.Sprintf("%d", id), Type: "insert"}
+	}
+
+	t.Run("publish", func(t *testing.T) {
+		bus := NewLocalBus(10)
+
+		subscription := bus.Subscribe("")
+		defer subscription.Close()
+
+		if err := bus.Publish(newEvent(1)); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := "1", (<-subscription.C).ID; expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+
+	t.Run("resume from last event id", func(t *testing.T) {
+		bus := NewLocalBus(10)
+
+		for i := 0; i < 3; i++ {
+			if err := bus.Publish(newEvent(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		subscription := bus.Subscribe("0")
+		defer subscription.Close()
+
+		if expected, actual := 2, len(subscription.Backlog); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "1", subscription.Backlog[0].ID; expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+		if expected, actual := false, subscription.Reset; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("resume from forgotten event id", func(t *testing.T) {
+		bus := NewLocalBus(2)
+
+		for i := 0; i < 3; i++ {
+			if err := bus.Publish(newEvent(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		subscription := bus.Subscribe("0")
+		defer subscription.Close()
+
+		if expected, actual := 0, len(subscription.Backlog); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := true, subscription.Reset; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("slow subscribers are dropped", func(t *testing.T) {
+		bus := NewLocalBus(2)
+
+		subscription := bus.Subscribe("")
+		defer subscription.Close()
+
+		for i := 0; i < 3; i++ {
+			if err := bus.Publish(newEvent(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var received int
+		for range subscription.C {
+			received++
+		}
+		if expected, actual := 2, received; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	event := Event{
+		TenantID:   "acme",
+		ResourceID: "resource",
+		AuthorID:   "author",
+		Tags:       []string{"a", "b"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{"tenant", Filter{TenantID: "acme"}, true},
+		{"other tenant", Filter{}, false},
+		{"resource", Filter{TenantID: "acme", ResourceID: "resource"}, true},
+		{"other resource", Filter{TenantID: "acme", ResourceID: "other"}, false},
+		{"author", Filter{TenantID: "acme", AuthorID: "author"}, true},
+		{"other author", Filter{TenantID: "acme", AuthorID: "other"}, false},
+		{"tags", Filter{TenantID: "acme", Tags: []string{"b", "c"}}, true},
+		{"other tags", Filter{TenantID: "acme", Tags: []string{"c"}}, false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if expected, actual := tc.expected, tc.filter.Matches(event); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/trussle/snowy/pkg/models"
+)
+
+// Event represents a change to a ledger. The ID is the id of the ledger
+// revision that was written, which is the same on every replica, so it can be
+// used to resume a stream on any of them.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"event"`
+	TenantID   string          `json:"tenant"`
+	ResourceID string          `json:"resource_id"`
+	AuthorID   string          `json:"author_id"`
+	Tags       []string        `json:"tags"`
+	CreatedOn  time.Time       `json:"created_on"`
+	Ledger     json.RawMessage `json:"ledger"`
+}
+
+// NewEvent creates an Event of the type (insert, append, fork, delete) for
+// the ledger revision that was written.
+func NewEvent(eventType string, doc models.Ledger) (Event, error) {
+	ledger, err := json.Marshal(doc)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:         doc.ID().String(),
+		Type:       eventType,
+		TenantID:   doc.TenantID(),
+		ResourceID: doc.ResourceID().String(),
+		AuthorID:   doc.AuthorID(),
+		Tags:       doc.Tags(),
+		CreatedOn:  time.Now(),
+		Ledger:     ledger,
+	}, nil
+}
+
+// Filter selects the events of a stream. The events are always scoped to the
+// tenant, but every other empty field matches every event. Tags match if the
+// event has any of the tags.
+type Filter struct {
+	TenantID   string
+	ResourceID string
+	AuthorID   string
+	Tags       []string
+}
+
+// Matches checks if the event should be part of the stream.
+func (f Filter) Matches(event Event) bool {
+	if f.TenantID != event.TenantID {
+		return false
+	}
+	if f.ResourceID != "" && f.ResourceID != event.ResourceID {
+		return false
+	}
+	if f.AuthorID != "" && f.AuthorID != event.AuthorID {
+		return false
+	}
+	return len(f.Tags) == 0 || intersection(f.Tags, event.Tags)
+}
+
+func intersection(a, b []string) bool {
+	m := map[string]struct{}{}
+	for _, v := range a {
+		m[v] = struct{}{}
+	}
+	for _, v := range b {
+		if _, ok := m[v]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package events
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/uuid"
+)
+
+const (
+	defaultChannel = "snowy_ledger_events"
+
+	// maxPayloadSize is the largest payload that postgres allows for a
+	// notification, which is just under 8000 bytes.
+	maxPayloadSize = 7999
+
+	defaultMinReconnectInterval = 10 * time.Second
+	defaultMaxReconnectInterval = time.Minute
+)
+
+// postgresBus publishes the events through postgres LISTEN/NOTIFY, so that
+// every replica that shares the database receives the events of every other
+// replica. Events are only dispatched once they come back from postgres,
+// so that every replica sees the events in the same order.
+type postgresBus struct {
+	*localBus
+	config  *store.RealConfig
+	db      *sql.DB
+	ledgers store.Store
+	logger  log.Logger
+}
+
+// NewPostgresBus creates a Bus that publishes the events through the postgres
+// database of the config, keeping the last size events so that subscribers
+// can resume. Events that are too large for a notification are published
+// without their ledger, which every replica then loads from the store.
+func NewPostgresBus(config *store.RealConfig, ledgers store.Store, size int, logger log.Logger) (Bus, error) {
+	db, err := sql.Open("postgres", store.ConnectionString(config))
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresBus{
+		localBus: newLocalBus(size),
+		config:   config,
+		db:       db,
+		ledgers:  ledgers,
+		logger:   logger,
+	}, nil
+}
+
+func (b *postgresBus) Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxPayloadSize {
+		// Only the reference to the ledger revision is sent, as postgres
+		// refuses the notification otherwise, and the listeners load the
+		// ledger from the store.
+		if payload, err = json.Marshal(reference(event)); err != nil {
+			return err
+		}
+		if len(payload) > maxPayloadSize {
+			return errors.Errorf("event %q is too large to publish (%d bytes)", event.ID, len(payload))
+		}
+	}
+
+	if _, err := b.db.Exec(`SELECT pg_notify($1, $2);`, defaultChannel, string(payload)); err != nil {
+		return errors.Wrap(err, "unable to notify")
+	}
+	return nil
+}
+
+func (b *postgresBus) Run() error {
+	listener := pq.NewListener(
+		store.ConnectionString(b.config),
+		defaultMinReconnectInterval,
+		defaultMaxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				level.Error(b.logger).Log("action", "listen", "err", err.Error())
+			}
+		},
+	)
+	defer listener.Close()
+
+	if err := listener.Listen(defaultChannel); err != nil {
+		return errors.Wrap(err, "unable to listen")
+	}
+
+	for {
+		select {
+		case notification := <-listener.Notify:
+			// A nil notification means the connection was re-established, so
+			// any events in between have been missed.
+			if notification == nil {
+				level.Warn(b.logger).Log("action", "listen", "reconnected", true)
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				level.Error(b.logger).Log("action", "listen", "err", err.Error())
+				continue
+			}
+			if len(event.Ledger) == 0 {
+				var err error
+				if event, err = b.load(event); err != nil {
+					level.Error(b.logger).Log("action", "load", "err", err.Error())
+					continue
+				}
+			}
+			b.dispatch(event)
+
+		case c := <-b.stop:
+			close(c)
+			return b.db.Close()
+		}
+	}
+}
+
+// load loads the ledger of an event that was published as a reference,
+// returning the event as it was before it was published.
+func (b *postgresBus) load(event Event) (Event, error) {
+	id, err := uuid.Parse(event.ID)
+	if err != nil {
+		return Event{}, errors.Wrapf(err, "event %q", event.ID)
+	}
+	query, err := store.BuildQuery(store.WithQueryTenant(event.TenantID))
+	if err != nil {
+		return Event{}, err
+	}
+
+	entity, err := b.ledgers.SelectEntity(id, query)
+	if err != nil {
+		return Event{}, errors.Wrapf(err, "event %q", event.ID)
+	}
+	doc, err := models.BuildLedger(
+		models.WithID(entity.ID),
+		models.WithParentID(entity.ParentID),
+		models.WithTenantID(entity.TenantID),
+		models.WithName(entity.Name),
+		models.WithResourceID(entity.ResourceID),
+		models.WithResourceAddress(entity.ResourceAddress),
+		models.WithResourceSize(entity.ResourceSize),
+		models.WithResourceContentType(entity.ResourceContentType),
+		models.WithAuthorID(entity.AuthorID),
+		models.WithTags(entity.Tags),
+		models.WithCreatedOn(entity.CreatedOn),
+		models.WithDeletedOn(entity.DeletedOn),
+		models.WithSignature(entity.SignatureKeyID, entity.Signature),
+	)
+	if err != nil {
+		return Event{}, err
+	}
+
+	loaded, err := NewEvent(event.Type, doc)
+	if err != nil {
+		return Event{}, err
+	}
+	loaded.CreatedOn = event.CreatedOn
+	return loaded, nil
+}
+
+// reference returns the event without the ledger, or anything else that's
+// loaded with the ledger, so that it's small enough to notify.
+func reference(event Event) Event {
+	return Event{
+		ID:        event.ID,
+		Type:      event.Type,
+		TenantID:  event.TenantID,
+		CreatedOn: event.CreatedOn,
+	}
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/uuid"
+)
+
+func TestPostgresBusReference(t *testing.T) {
+	t.Parallel()
+
+	var tags []string
+	for i := 0; i < 1000; i++ {
+		tags = append(tags, fmt.Sprintf("tag-%04d", i))
+	}
+
+	var (
+		ledgers = store.NewVirtualStore()
+		bus     = &postgresBus{
+			localBus: newLocalBus(10),
+			ledgers:  ledgers,
+			logger:   log.NewNopLogger(),
+		}
+		entity = store.Entity{
+			ID:                  uuid.MustNew(),
+			TenantID:            "acme",
+			Name:                "name",
+			ResourceID:          uuid.MustNew(),
+			ResourceAddress:     "address",
+			ResourceSize:        1,
+			ResourceContentType: "application/octet-stream",
+			AuthorID:            "author",
+			Tags:                tags,
+			CreatedOn:           time.Now().UTC().Truncate(time.Second),
+		}
+	)
+	if err := ledgers.Insert(entity); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := models.BuildLedger(
+		models.WithID(entity.ID),
+		models.WithTenantID(entity.TenantID),
+		models.WithName(entity.Name),
+		models.WithResourceID(entity.ResourceID),
+		models.WithResourceAddress(entity.ResourceAddress),
+		models.WithResourceSize(entity.ResourceSize),
+		models.WithResourceContentType(entity.ResourceContentType),
+		models.WithAuthorID(entity.AuthorID),
+		models.WithTags(entity.Tags),
+		models.WithCreatedOn(entity.CreatedOn),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := NewEvent("insert", doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reference is small enough to notify", func(t *testing.T) {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(payload) <= maxPayloadSize {
+			t.Fatalf("expected event larger than %d bytes, actual: %d", maxPayloadSize, len(payload))
+		}
+
+		if payload, err = json.Marshal(reference(event)); err != nil {
+			t.Fatal(err)
+		}
+		if len(payload) > maxPayloadSize {
+			t.Errorf("expected reference no larger than %d bytes, actual: %d", maxPayloadSize, len(payload))
+		}
+	})
+
+	t.Run("load", func(t *testing.T) {
+		var ref Event
+		payload, err := json.Marshal(reference(event))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(payload, &ref); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := bus.load(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := event.ResourceID, loaded.ResourceID; expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+		if expected, actual := event.Tags, loaded.Tags; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %d tags, actual: %d tags", len(expected), len(actual))
+		}
+		if expected, actual := event.CreatedOn, loaded.CreatedOn; !expected.Equal(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		var expected, actual models.Ledger
+		if err := json.Unmarshal(event.Ledger, &expected); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(loaded.Ledger, &actual); err != nil {
+			t.Fatal(err)
+		}
+		if expected.ID() != actual.ID() || expected.Name() != actual.Name() {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("load from another tenant", func(t *testing.T) {
+		ref := reference(event)
+		ref.TenantID = "other"
+
+		if _, err := bus.load(ref); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -3,11 +3,71 @@ package http
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 )
 
+const (
+	defaultContentType = "application/problem+json"
+
+	httpHeaderRequestID = "X-Request-ID"
+
+	// sanitizedDescription replaces the description of every internal error,
+	// so that the internals of the store or filesystem aren't sent to clients.
+	sanitizedDescription = "internal server error"
+)
+
+// Code is the machine readable code of an error, so that clients can act on
+// the kind of error without parsing the description.
+type Code string
+
+// These are the codes of the errors of the APIs.
+const (
+	CodeInvalidQuery       Code = "invalid_query"
+	CodeInvalidInput       Code = "invalid_input"
+	CodeInvalidSignature   Code = "invalid_signature"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not_found"
+	CodeResourceNotFound   Code = "resource_not_found"
+	CodeResourceErased     Code = "resource_erased"
+	CodePreconditionFailed Code = "precondition_failed"
+	CodeContentTooLarge    Code = "content_too_large"
+	CodeQuotaExceeded      Code = "quota_exceeded"
+	CodeTooManyRequests    Code = "too_many_requests"
+	CodeNotSupported       Code = "not_supported"
+	CodeInternal           Code = "internal_error"
+)
+
+// Problem is the body of an error, as a RFC 7807 problem details object. The
+// description and code members are kept along side, for clients that predate
+// the problem details.
+type Problem struct {
+	Type        Code         `json:"type"`
+	Title       string       `json:"title"`
+	Status      int          `json:"status"`
+	Detail      string       `json:"detail"`
+	Instance    string       `json:"instance,omitempty"`
+	RequestID   string       `json:"request_id,omitempty"`
+	Fields      []FieldError `json:"errors,omitempty"`
+	Description string       `json:"description"`
+	Code        int          `json:"code"`
+}
+
+// FieldError describes why a field of the input of a request is invalid.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// invalidFields is implemented by validation errors that know which fields of
+// the input are invalid.
+type invalidFields interface {
+	InvalidFields() map[string]string
+}
+
 // Error is a HTTP error type that allows the sending of errors correctly.
 type Error struct {
 	logger log.Logger
@@ -21,36 +81,167 @@ func NewError(logger log.Logger) Error {
 // Error replies to the request with the specified error message and HTTP code.
 // It does not otherwise end the request; the caller should ensure no further
 // writes are done to w.
-// The error message should be application/json.
+// The error code is derived from the HTTP code, and the message of internal
+// errors is logged, but not sent.
 func (e Error) Error(w http.ResponseWriter, err string, code int) {
-	level.Error(e.logger).Log("err", err, "code", code)
+	e.Problem(w, nil, code, codeOf(code), err)
+}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.WriteHeader(code)
-
-	if err := json.NewEncoder(w).Encode(struct {
-		Description string `json:"description"`
-		Code        int    `json:"code"`
-	}{
-		Description: err,
-		Code:        code,
-	}); err != nil {
-		panic(err)
-	}
+// Problem replies to the request with a problem of the error code. Internal
+// errors, those with a 5xx status, are logged with the message and sent with a
+// sanitized description instead.
+func (e Error) Problem(w http.ResponseWriter, r *http.Request, status int, code Code, err string) {
+	e.encode(w, r, status, code, err, nil)
 }
 
-// NotFound replies to the request with an HTTP 404 not found error.
+// NotFound replies to the request with an HTTP 404 not found error, for a
+// route that doesn't exist.
 func (e Error) NotFound(w http.ResponseWriter, r *http.Request) {
-	e.Error(w, "not found", http.StatusNotFound)
+	e.Problem(w, r, http.StatusNotFound, CodeNotFound, "not found")
+}
+
+// ResourceNotFound replies to the request with an HTTP 404 not found error,
+// for a resource that doesn't exist.
+func (e Error) ResourceNotFound(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusNotFound, CodeResourceNotFound, err)
+}
+
+// Erased replies to the request with an HTTP 410 gone error, for a resource
+// that was erased.
+func (e Error) Erased(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusGone, CodeResourceErased, err)
 }
 
-// BadRequest to the request with an HTTP 400 bad request error.
+// BadRequest to the request with an HTTP 400 bad request error, for a query
+// that can't be decoded.
 func (e Error) BadRequest(w http.ResponseWriter, r *http.Request, err string) {
-	e.Error(w, err, http.StatusBadRequest)
+	e.Problem(w, r, http.StatusBadRequest, CodeInvalidQuery, err)
+}
+
+// PreconditionFailed replies to the request with an HTTP 412 precondition
+// failed error, for a write that the state of the resource doesn't allow, such
+// as a revision of an erased resource.
+func (e Error) PreconditionFailed(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusPreconditionFailed, CodePreconditionFailed, err)
+}
+
+// InvalidInput replies to the request with an HTTP 400 bad request error, for
+// a body that can't be decoded or validated. If the error knows which fields
+// are invalid, then they're sent along with the error.
+func (e Error) InvalidInput(w http.ResponseWriter, r *http.Request, err error) {
+	var fields []FieldError
+	if invalid, ok := err.(invalidFields); ok {
+		for field, reason := range invalid.InvalidFields() {
+			fields = append(fields, FieldError{Field: field, Reason: reason})
+		}
+		sort.Slice(fields, func(i, j int) bool {
+			return fields[i].Field < fields[j].Field
+		})
+	}
+	e.encode(w, r, http.StatusBadRequest, CodeInvalidInput, err.Error(), fields)
+}
+
+// InvalidSignature replies to the request with an HTTP 400 bad request error,
+// for a signed input where the signature doesn't match.
+func (e Error) InvalidSignature(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusBadRequest, CodeInvalidSignature, err)
 }
 
 // InternalServerError to the request with an HTTP 500 bad request error.
 func (e Error) InternalServerError(w http.ResponseWriter, r *http.Request, err string) {
-	e.Error(w, err, http.StatusInternalServerError)
+	e.Problem(w, r, http.StatusInternalServerError, CodeInternal, err)
+}
+
+// Unauthorized replies to the request with an HTTP 401 unauthorized error.
+func (e Error) Unauthorized(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusUnauthorized, CodeUnauthorized, err)
+}
+
+// Forbidden replies to the request with an HTTP 403 forbidden error.
+func (e Error) Forbidden(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusForbidden, CodeForbidden, err)
+}
+
+// PayloadTooLarge replies to the request with an HTTP 413 payload too large
+// error, for content over the size limit.
+func (e Error) PayloadTooLarge(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusRequestEntityTooLarge, CodeContentTooLarge, err)
+}
+
+// QuotaExceeded replies to the request with an HTTP 413 payload too large
+// error, for a write that would go over the quota of the tenant.
+func (e Error) QuotaExceeded(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusRequestEntityTooLarge, CodeQuotaExceeded, err)
+}
+
+// TooManyRequests replies to the request with an HTTP 429 too many requests
+// error.
+func (e Error) TooManyRequests(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusTooManyRequests, CodeTooManyRequests, err)
+}
+
+// NotSupported replies to the request with an HTTP 400 bad request error, for
+// a request that the configuration of the server doesn't support.
+func (e Error) NotSupported(w http.ResponseWriter, r *http.Request, err string) {
+	e.Problem(w, r, http.StatusBadRequest, CodeNotSupported, err)
+}
+
+func (e Error) encode(w http.ResponseWriter, r *http.Request, status int, code Code, err string, fields []FieldError) {
+	problem := Problem{
+		Type:   code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err,
+		Fields: fields,
+		Code:   status,
+	}
+	if r != nil {
+		problem.Instance = r.URL.Path
+		problem.RequestID = r.Header.Get(httpHeaderRequestID)
+	}
+
+	logger := log.With(e.logger, "code", status, "type", code)
+	if problem.RequestID != "" {
+		logger = log.With(logger, "request_id", problem.RequestID)
+	}
+	if status >= http.StatusInternalServerError {
+		level.Error(logger).Log("err", err)
+		problem.Detail = sanitizedDescription
+	} else {
+		level.Info(logger).Log("err", err)
+	}
+	problem.Description = problem.Detail
+
+	w.Header().Set("Content-Type", defaultContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		panic(err)
+	}
+}
+
+// codeOf returns the error code of a HTTP code, for errors that only have the
+// HTTP code.
+func codeOf(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidQuery
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeResourceNotFound
+	case http.StatusGone:
+		return CodeResourceErased
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return CodeContentTooLarge
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	default:
+		return CodeInternal
+	}
 }
@@ -2,39 +2,56 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"testing/quick"
 
 	"github.com/go-kit/kit/log"
 )
 
+type errFields map[string]string
+
+func (e errFields) Error() string                    { return "invalid fields" }
+func (e errFields) InvalidFields() map[string]string { return e }
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	if expected, actual := defaultContentType, w.Header().Get("Content-Type"); expected != actual {
+		t.Errorf("expected: %q, actual: %q", expected, actual)
+	}
+
+	var res Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
 func TestError(t *testing.T) {
 	t.Parallel()
 
 	t.Run("writes error", func(t *testing.T) {
-		fn := func(desc string, code int) bool {
-			w := httptest.NewRecorder()
+		fn := func(desc string, code uint8) bool {
+			var (
+				w      = httptest.NewRecorder()
+				status = http.StatusBadRequest + int(code%100)
+			)
 
 			e := NewError(log.NewNopLogger())
-			e.Error(w, desc, code)
+			e.Error(w, desc, status)
 
-			var res struct {
-				Description string `json:"description"`
-				Code        int    `json:"code"`
-			}
-
-			b := w.Body.Bytes()
-			if err := json.Unmarshal(b, &res); err != nil {
-				t.Fatal(err)
-			}
-
-			if expected, actual := w.Code, code; expected != actual {
+			if expected, actual := w.Code, status; expected != actual {
 				t.Fatalf("expected: %d, actual: %d", expected, actual)
 			}
 
-			return res.Description == desc && res.Code == code
+			res := decodeProblem(t, w)
+			return res.Description == desc &&
+				res.Detail == desc &&
+				res.Code == status &&
+				res.Status == status &&
+				res.Type == codeOf(status)
 		}
 
 		if err := quick.Check(fn, nil); err != nil {
@@ -42,78 +59,176 @@ func TestError(t *testing.T) {
 		}
 	})
 
-	t.Run("writes not found", func(t *testing.T) {
-		fn := func() bool {
-			w := httptest.NewRecorder()
+	t.Run("writes sanitized internal error", func(t *testing.T) {
+		w := httptest.NewRecorder()
 
-			e := NewError(log.NewNopLogger())
-			e.NotFound(w, nil)
+		e := NewError(log.NewNopLogger())
+		e.Error(w, "pq: relation \"ledgers\" does not exist", http.StatusInternalServerError)
 
-			var res struct {
-				Description string `json:"description"`
-				Code        int    `json:"code"`
-			}
+		res := decodeProblem(t, w)
+		if expected, actual := sanitizedDescription, res.Description; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := sanitizedDescription, res.Detail; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := CodeInternal, res.Type; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
 
-			b := w.Body.Bytes()
-			if err := json.Unmarshal(b, &res); err != nil {
-				t.Fatal(err)
-			}
+	t.Run("writes request", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/ledgers/?resource_id=bad", nil)
+		r.Header.Set("X-Request-ID", "abc")
 
-			return res.Description == "not found" && res.Code == http.StatusNotFound
-		}
+		w := httptest.NewRecorder()
 
-		if err := quick.Check(fn, nil); err != nil {
-			t.Error(err)
+		e := NewError(log.NewNopLogger())
+		e.BadRequest(w, r, "bad resource_id")
+
+		res := decodeProblem(t, w)
+		if expected, actual := "abc", res.RequestID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "/ledgers/", res.Instance; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "Bad Request", res.Title; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
 		}
 	})
 
-	t.Run("writes bad request", func(t *testing.T) {
-		fn := func(desc string) bool {
-			w := httptest.NewRecorder()
-
-			e := NewError(log.NewNopLogger())
-			e.BadRequest(w, nil, desc)
+	t.Run("writes invalid input", func(t *testing.T) {
+		w := httptest.NewRecorder()
 
-			var res struct {
-				Description string `json:"description"`
-				Code        int    `json:"code"`
-			}
+		e := NewError(log.NewNopLogger())
+		e.InvalidInput(w, nil, errFields{
+			"name":      "is empty",
+			"author_id": "is empty",
+		})
 
-			b := w.Body.Bytes()
-			if err := json.Unmarshal(b, &res); err != nil {
-				t.Fatal(err)
-			}
+		if expected, actual := http.StatusBadRequest, w.Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
 
-			return res.Description == desc && res.Code == http.StatusBadRequest
+		res := decodeProblem(t, w)
+		if expected, actual := CodeInvalidInput, res.Type; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
 		}
+		if expected, actual := []FieldError{
+			{Field: "author_id", Reason: "is empty"},
+			{Field: "name", Reason: "is empty"},
+		}, res.Fields; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
 
-		if err := quick.Check(fn, nil); err != nil {
-			t.Error(err)
+	t.Run("writes invalid input without fields", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		e := NewError(log.NewNopLogger())
+		e.InvalidInput(w, nil, errors.New("unexpected end of JSON input"))
+
+		res := decodeProblem(t, w)
+		if expected, actual := "unexpected end of JSON input", res.Detail; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := 0, len(res.Fields); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
 		}
 	})
 
-	t.Run("writes internal server error", func(t *testing.T) {
-		fn := func(desc string) bool {
+	for name, tc := range map[string]struct {
+		fn     func(Error, http.ResponseWriter, string)
+		status int
+		code   Code
+	}{
+		"not found": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.NotFound(w, nil) },
+			status: http.StatusNotFound,
+			code:   CodeNotFound,
+		},
+		"resource not found": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.ResourceNotFound(w, nil, desc) },
+			status: http.StatusNotFound,
+			code:   CodeResourceNotFound,
+		},
+		"erased": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.Erased(w, nil, desc) },
+			status: http.StatusGone,
+			code:   CodeResourceErased,
+		},
+		"precondition failed": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.PreconditionFailed(w, nil, desc) },
+			status: http.StatusPreconditionFailed,
+			code:   CodePreconditionFailed,
+		},
+		"bad request": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.BadRequest(w, nil, desc) },
+			status: http.StatusBadRequest,
+			code:   CodeInvalidQuery,
+		},
+		"invalid signature": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.InvalidSignature(w, nil, desc) },
+			status: http.StatusBadRequest,
+			code:   CodeInvalidSignature,
+		},
+		"internal server error": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.InternalServerError(w, nil, desc) },
+			status: http.StatusInternalServerError,
+			code:   CodeInternal,
+		},
+		"unauthorized": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.Unauthorized(w, nil, desc) },
+			status: http.StatusUnauthorized,
+			code:   CodeUnauthorized,
+		},
+		"forbidden": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.Forbidden(w, nil, desc) },
+			status: http.StatusForbidden,
+			code:   CodeForbidden,
+		},
+		"payload too large": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.PayloadTooLarge(w, nil, desc) },
+			status: http.StatusRequestEntityTooLarge,
+			code:   CodeContentTooLarge,
+		},
+		"quota exceeded": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.QuotaExceeded(w, nil, desc) },
+			status: http.StatusRequestEntityTooLarge,
+			code:   CodeQuotaExceeded,
+		},
+		"too many requests": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.TooManyRequests(w, nil, desc) },
+			status: http.StatusTooManyRequests,
+			code:   CodeTooManyRequests,
+		},
+		"not supported": {
+			fn:     func(e Error, w http.ResponseWriter, desc string) { e.NotSupported(w, nil, desc) },
+			status: http.StatusBadRequest,
+			code:   CodeNotSupported,
+		},
+	} {
+		tc := tc
+		t.Run("writes "+name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 
-			e := NewError(log.NewNopLogger())
-			e.InternalServerError(w, nil, desc)
+			tc.fn(NewError(log.NewNopLogger()), w, "description")
 
-			var res struct {
-				Description string `json:"description"`
-				Code        int    `json:"code"`
+			if expected, actual := tc.status, w.Code; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
 			}
 
-			b := w.Body.Bytes()
-			if err := json.Unmarshal(b, &res); err != nil {
-				t.Fatal(err)
+			res := decodeProblem(t, w)
+			if expected, actual := tc.code, res.Type; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
 			}
-
-			return res.Description == desc && res.Code == http.StatusInternalServerError
-		}
-
-		if err := quick.Check(fn, nil); err != nil {
-			t.Error(err)
-		}
-	})
+			if expected, actual := tc.status, res.Code; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+			if res.Description == "" || res.Description != res.Detail {
+				t.Errorf("expected: description to match detail, actual: %q, %q", res.Description, res.Detail)
+			}
+		})
+	}
 }
@@ -0,0 +1,381 @@
+// Package ingest journals the files of a directory as resources, so that
+// files that are dropped in to a shared folder are stored without any scripts.
+// A new file is journaled as a new resource, and a file that's changed since
+// it was last ingested is appended as a revision of its resource. What has
+// been ingested is kept in a state file, so every run is incremental.
+package ingest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+
+	// sniffLen is how much of a file is read to find its content type, when
+	// the extension isn't known.
+	sniffLen = 512
+)
+
+// Journal writes the content and the ledger of a resource in one request, as
+// the journals API does.
+type Journal interface {
+	Insert(ctx context.Context, r io.Reader, size int64, contentType string, input models.LedgerInput) (resourceID uuid.UUID, address string, err error)
+	Append(ctx context.Context, resourceID uuid.UUID, r io.Reader, size int64, contentType string, input models.LedgerInput) (uuid.UUID, string, error)
+}
+
+// Summary reports what a scan of the directory did.
+type Summary struct {
+	Inserted  int
+	Appended  int
+	Unchanged int
+
+	// Skipped are the files that aren't ingested, as they match no rule, are
+	// ignored by a rule or are empty.
+	Skipped int
+
+	// Pending are the files that were modified too recently, which are left
+	// for the next scan, as they may still be being written.
+	Pending int
+
+	// Failed are the files that couldn't be ingested. They're tried again on
+	// the next scan.
+	Failed int
+}
+
+// Ingester ingests the files of a directory.
+type Ingester struct {
+	journal  Journal
+	root     string
+	state    *State
+	rules    []Rule
+	interval time.Duration
+	settle   time.Duration
+	logger   log.Logger
+	stop     chan chan struct{}
+}
+
+// IngesterOption defines a option for configuring the ingester.
+type IngesterOption func(*Ingester)
+
+// WithRules sets the rules that map the files to the metadata of their
+// resources. Without any rules, every file is ingested.
+func WithRules(rules []Rule) IngesterOption {
+	return func(i *Ingester) {
+		i.rules = rules
+	}
+}
+
+// WithInterval sets how often the directory is scanned when it's watched.
+func WithInterval(interval time.Duration) IngesterOption {
+	return func(i *Ingester) {
+		i.interval = interval
+	}
+}
+
+// WithSettle sets how long a file has to be left unmodified before it's
+// ingested, so that files that are still being written are left alone.
+func WithSettle(settle time.Duration) IngesterOption {
+	return func(i *Ingester) {
+		i.settle = settle
+	}
+}
+
+// NewIngester creates a Ingester with correct dependencies.
+func NewIngester(journal Journal, root string, state *State, logger log.Logger, opts ...IngesterOption) *Ingester {
+	i := &Ingester{
+		journal:  journal,
+		root:     root,
+		state:    state,
+		interval: defaultInterval,
+		logger:   logger,
+		stop:     make(chan chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if i.interval <= 0 {
+		i.interval = defaultInterval
+	}
+	return i
+}
+
+// Run scans the directory straight away and then periodically, until the
+// ingester is stopped.
+func (i *Ingester) Run() error {
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	i.scan()
+	for {
+		select {
+		case <-ticker.C:
+			i.scan()
+		case c := <-i.stop:
+			close(c)
+			return nil
+		}
+	}
+}
+
+// Stop stops the ingester, waiting for any scan in flight.
+func (i *Ingester) Stop() {
+	c := make(chan struct{})
+	i.stop <- c
+	<-c
+}
+
+func (i *Ingester) scan() {
+	summary, err := i.Scan(context.Background())
+	if err != nil {
+		level.Error(i.logger).Log("action", "scan", "err", err.Error())
+		return
+	}
+	if summary.Inserted > 0 || summary.Appended > 0 || summary.Failed > 0 {
+		level.Info(i.logger).Log("action", "scan", "inserted", summary.Inserted, "appended", summary.Appended, "failed", summary.Failed, "pending", summary.Pending)
+	}
+}
+
+// Scan walks the directory once, ingesting every file that's new or changed
+// since it was last ingested. A file that fails is logged and counted, so
+// that one file doesn't hold up the rest, and the state is saved after every
+// file that's ingested.
+func (i *Ingester) Scan(ctx context.Context) (Summary, error) {
+	var summary Summary
+
+	files, err := i.walk()
+	if err != nil {
+		return summary, err
+	}
+
+	for _, name := range files {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		ingested, err := i.ingest(ctx, name)
+		if err != nil {
+			level.Warn(i.logger).Log("action", "ingest", "file", name, "err", err.Error())
+			summary.Failed++
+			continue
+		}
+		switch ingested {
+		case fileInserted:
+			summary.Inserted++
+		case fileAppended:
+			summary.Appended++
+		case fileUnchanged:
+			summary.Unchanged++
+		case fileSkipped:
+			summary.Skipped++
+		case filePending:
+			summary.Pending++
+		}
+	}
+	return summary, nil
+}
+
+// walk returns the path of every file of the directory, relative to the
+// directory, with forward slashes. Hidden files and directories, sidecars and
+// the state file are left out.
+func (i *Ingester) walk() ([]string, error) {
+	statePath, err := filepath.Abs(i.state.Path())
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.Walk(i.root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if file != i.root && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if abs, err := filepath.Abs(file); err == nil && abs == statePath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(i.root, file)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(files))
+	for _, name := range files {
+		names[name] = struct{}{}
+	}
+
+	res := files[:0]
+	for _, name := range files {
+		if strings.HasSuffix(name, SidecarExt) {
+			if _, ok := names[strings.TrimSuffix(name, SidecarExt)]; ok {
+				continue
+			}
+		}
+		res = append(res, name)
+	}
+	return res, nil
+}
+
+type fileResult int
+
+const (
+	fileInserted fileResult = iota
+	fileAppended
+	fileUnchanged
+	fileSkipped
+	filePending
+)
+
+// ingest journals the file if it's new or changed, then records it in the
+// state.
+func (i *Ingester) ingest(ctx context.Context, name string) (fileResult, error) {
+	metadata, ok := metadataFor(i.rules, name)
+	if !ok {
+		return fileSkipped, nil
+	}
+
+	file := filepath.Join(i.root, filepath.FromSlash(name))
+	sidecar, err := readSidecar(file)
+	if err != nil {
+		return 0, err
+	}
+	metadata = metadata.merge(sidecar)
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() == 0 {
+		return fileSkipped, nil
+	}
+	if i.settle > 0 && time.Since(info.ModTime()) < i.settle {
+		return filePending, nil
+	}
+
+	previous, known := i.state.Files[name]
+	if known && previous.Size == info.Size() && previous.ModTime.Equal(info.ModTime()) && previous.Metadata.equals(metadata) {
+		return fileUnchanged, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	address, contentType, err := inspect(f, name, metadata.ContentType)
+	if err != nil {
+		return 0, err
+	}
+
+	current := FileState{
+		ResourceID: previous.ResourceID,
+		Address:    address,
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		Metadata:   metadata,
+		IngestedOn: previous.IngestedOn,
+	}
+
+	// Only the modification time changed, so there is no new revision.
+	if known && previous.Address == address && previous.Metadata.equals(metadata) {
+		i.state.Files[name] = current
+		return fileUnchanged, i.state.Save()
+	}
+
+	input := models.LedgerInput{
+		Name:     metadata.Name,
+		AuthorID: metadata.AuthorID,
+		Tags:     metadata.Tags,
+	}
+
+	var (
+		result = fileInserted
+		stored string
+	)
+	if known {
+		result = fileAppended
+		_, stored, err = i.journal.Append(ctx, previous.ResourceID, f, info.Size(), contentType, input)
+	} else {
+		current.ResourceID, stored, err = i.journal.Insert(ctx, f, info.Size(), contentType, input)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	current.IngestedOn = time.Now().UTC()
+
+	// The file changed after it was hashed, so what was stored isn't what the
+	// state would say it is. The resource is kept with what was stored, but
+	// with out the modification time, so that the next scan hashes the file
+	// again and appends it if it's changed.
+	if stored != address {
+		current.Address = stored
+		current.ModTime = time.Time{}
+		i.state.Files[name] = current
+		if err = i.state.Save(); err != nil {
+			return 0, err
+		}
+		return 0, errors.Errorf("file %q changed while it was ingested", name)
+	}
+
+	i.state.Files[name] = current
+	return result, i.state.Save()
+}
+
+// inspect returns the content address of the file and its content type,
+// leaving the file at the start again. The content type is the one that's
+// given, or found from the extension of the name, or else from the content.
+func inspect(f *os.File, name, contentType string) (string, string, error) {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if contentType == "" {
+		head := make([]byte, sniffLen)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return "", "", err
+		}
+		contentType = http.DetectContentType(head[:n])
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			return "", "", err
+		}
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), contentType, nil
+}
@@ -0,0 +1,324 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+type write struct {
+	resourceID  uuid.UUID
+	body        string
+	contentType string
+	input       models.LedgerInput
+}
+
+type recordingJournal struct {
+	mutex  sync.Mutex
+	fail   bool
+	writes []write
+
+	// rewrite changes the content as it's written, as if the file changed
+	// after it was hashed.
+	rewrite func([]byte) []byte
+}
+
+func (j *recordingJournal) Insert(ctx context.Context, r io.Reader, size int64, contentType string, input models.LedgerInput) (uuid.UUID, string, error) {
+	return j.write(uuid.MustNew(), r, contentType, input)
+}
+
+func (j *recordingJournal) Append(ctx context.Context, resourceID uuid.UUID, r io.Reader, size int64, contentType string, input models.LedgerInput) (uuid.UUID, string, error) {
+	return j.write(resourceID, r, contentType, input)
+}
+
+func (j *recordingJournal) write(resourceID uuid.UUID, r io.Reader, contentType string, input models.LedgerInput) (uuid.UUID, string, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.fail {
+		return uuid.Empty, "", errors.New("failed")
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return uuid.Empty, "", err
+	}
+	if j.rewrite != nil {
+		body = j.rewrite(body)
+	}
+	address, err := models.ContentAddress(body)
+	if err != nil {
+		return uuid.Empty, "", err
+	}
+	j.writes = append(j.writes, write{resourceID, string(body), contentType, input})
+	return resourceID, address, nil
+}
+
+func newDir(t *testing.T, files map[string]string) (string, func()) {
+	dir, err := ioutil.TempDir("", "ingest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, files)
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, body := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newIngester(t *testing.T, journal Journal, dir string, opts ...IngesterOption) *Ingester {
+	state, err := LoadState(filepath.Join(dir, ".snowy-ingest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewIngester(journal, dir, state, log.NewNopLogger(), opts...)
+}
+
+func scan(t *testing.T, ingester *Ingester) Summary {
+	summary, err := ingester.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return summary
+}
+
+func TestIngester(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inserts new files", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{
+			"a.txt":                  "a",
+			"scans/b.pdf":            "%PDF-b",
+			"scans/b.pdf.snowy.json": `{"name":"invoice","tags":["invoice"],"author_id":"alice"}`,
+			"empty":                  "",
+			".hidden/c.txt":          "c",
+		})
+		defer remove()
+
+		journal := &recordingJournal{}
+		summary := scan(t, newIngester(t, journal, dir))
+
+		if expected, actual := (Summary{Inserted: 2, Skipped: 1}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 2, len(journal.writes); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := (models.LedgerInput{Name: "a.txt"}), journal.writes[0].input; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := (models.LedgerInput{
+			Name:     "invoice",
+			AuthorID: "alice",
+			Tags:     []string{"invoice"},
+		}), journal.writes[1].input; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "application/pdf", journal.writes[1].contentType; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("runs are incremental", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{
+			"a.txt": "a",
+			"b.txt": "b",
+		})
+		defer remove()
+
+		journal := &recordingJournal{}
+		scan(t, newIngester(t, journal, dir))
+
+		// Change the content of a file, and only touch the other, then run
+		// again with the state that was saved.
+		writeFiles(t, dir, map[string]string{"a.txt": "aa", "c.txt": "c"})
+		future := time.Now().Add(time.Minute)
+		if err := os.Chtimes(filepath.Join(dir, "b.txt"), future, future); err != nil {
+			t.Fatal(err)
+		}
+
+		summary := scan(t, newIngester(t, journal, dir))
+
+		if expected, actual := (Summary{Inserted: 1, Appended: 1, Unchanged: 1}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 4, len(journal.writes); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := journal.writes[0].resourceID, journal.writes[2].resourceID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "aa", journal.writes[2].body; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		summary = scan(t, newIngester(t, journal, dir))
+		if expected, actual := (Summary{Unchanged: 3}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("changed sidecar appends a revision", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{
+			"a.txt":            "a",
+			"a.txt.snowy.json": `{"tags":["red"]}`,
+		})
+		defer remove()
+
+		var (
+			journal  = &recordingJournal{}
+			ingester = newIngester(t, journal, dir)
+		)
+		scan(t, ingester)
+
+		writeFiles(t, dir, map[string]string{"a.txt.snowy.json": `{"tags":["blue"]}`})
+
+		if expected, actual := (Summary{Appended: 1}), scan(t, ingester); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"blue"}, journal.writes[1].input.Tags; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("json files named after files are ingested", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{
+			"report":      "report",
+			"report.json": `{"total":1}`,
+		})
+		defer remove()
+
+		journal := &recordingJournal{}
+		if expected, actual := (Summary{Inserted: 2}), scan(t, newIngester(t, journal, dir)); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{
+			"invoices/a.pdf": "a",
+			"invoices/b.tmp": "b",
+			"other/c.txt":    "c",
+		})
+		defer remove()
+
+		journal := &recordingJournal{}
+		summary := scan(t, newIngester(t, journal, dir, WithRules([]Rule{
+			{Pattern: "*.tmp", Ignore: true},
+			{Pattern: "invoices/*", Tags: []string{"invoice"}, AuthorID: "scanner", ContentType: "application/pdf"},
+		})))
+
+		if expected, actual := (Summary{Inserted: 1, Skipped: 2}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := (models.LedgerInput{
+			Name:     "invoices/a.pdf",
+			AuthorID: "scanner",
+			Tags:     []string{"invoice"},
+		}), journal.writes[0].input; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("recently modified files are pending", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{"a.txt": "a"})
+		defer remove()
+
+		journal := &recordingJournal{}
+		summary := scan(t, newIngester(t, journal, dir, WithSettle(time.Hour)))
+
+		if expected, actual := (Summary{Pending: 1}), summary; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("failed files are tried again", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{"a.txt": "a"})
+		defer remove()
+
+		var (
+			journal  = &recordingJournal{fail: true}
+			ingester = newIngester(t, journal, dir)
+		)
+		if expected, actual := (Summary{Failed: 1}), scan(t, ingester); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		journal.fail = false
+		if expected, actual := (Summary{Inserted: 1}), scan(t, ingester); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("files changed while ingested are looked at again", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{"a.txt": "a"})
+		defer remove()
+
+		var (
+			journal = &recordingJournal{
+				rewrite: func(body []byte) []byte {
+					return append(body, 'b')
+				},
+			}
+			ingester = newIngester(t, journal, dir)
+		)
+		if expected, actual := (Summary{Failed: 1}), scan(t, ingester); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		// The file now holds what was stored, so there's nothing to append.
+		journal.rewrite = nil
+		writeFiles(t, dir, map[string]string{"a.txt": "ab"})
+
+		if expected, actual := (Summary{Unchanged: 1}), scan(t, ingester); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 1, len(journal.writes); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("run and stop", func(t *testing.T) {
+		dir, remove := newDir(t, map[string]string{"a.txt": "a"})
+		defer remove()
+
+		var (
+			journal  = &recordingJournal{}
+			ingester = newIngester(t, journal, dir, WithInterval(time.Millisecond))
+			done     = make(chan struct{})
+		)
+		go func() {
+			ingester.Run()
+			close(done)
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		ingester.Stop()
+		<-done
+
+		journal.mutex.Lock()
+		defer journal.mutex.Unlock()
+		if expected, actual := 1, len(journal.writes); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package ingest
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SidecarExt is the extension of a sidecar, the metadata of a file that's
+// kept next to it, as "scan.pdf.snowy.json" is the sidecar of "scan.pdf".
+// Sidecars aren't ingested themselves. The extension is particular to snowy,
+// so that a JSON file that happens to be named after another file, such as
+// "report.json" next to "report", is still ingested.
+const SidecarExt = ".snowy.json"
+
+// Metadata is the metadata of the resource of a file. A zero value of any of
+// the fields is left to a default: the name is the path of the file, the
+// content type is found from the extension or the content of the file, and
+// the tags and the author are left empty.
+type Metadata struct {
+	Name        string   `json:"name,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	AuthorID    string   `json:"author_id,omitempty"`
+	ContentType string   `json:"content_type,omitempty"`
+}
+
+// merge returns the metadata, with any field that's set in other taking
+// precedence.
+func (m Metadata) merge(other Metadata) Metadata {
+	if other.Name != "" {
+		m.Name = other.Name
+	}
+	if len(other.Tags) > 0 {
+		m.Tags = other.Tags
+	}
+	if other.AuthorID != "" {
+		m.AuthorID = other.AuthorID
+	}
+	if other.ContentType != "" {
+		m.ContentType = other.ContentType
+	}
+	return m
+}
+
+func (m Metadata) equals(other Metadata) bool {
+	return m.Name == other.Name &&
+		m.AuthorID == other.AuthorID &&
+		m.ContentType == other.ContentType &&
+		len(m.Tags) == len(other.Tags) &&
+		(len(m.Tags) == 0 || reflect.DeepEqual(m.Tags, other.Tags))
+}
+
+// Rule maps the files that match the pattern to the metadata of their
+// resources, or ignores them. The pattern is matched as path.Match does,
+// against the path of the file relative to the directory, or against the base
+// name of the file if the pattern has no slashes.
+type Rule struct {
+	Pattern     string   `json:"pattern"`
+	Ignore      bool     `json:"ignore,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	AuthorID    string   `json:"author_id,omitempty"`
+	ContentType string   `json:"content_type,omitempty"`
+}
+
+func (r Rule) match(name string) bool {
+	if !strings.Contains(r.Pattern, "/") {
+		name = path.Base(name)
+	}
+	ok, err := path.Match(r.Pattern, name)
+	return err == nil && ok
+}
+
+// ReadRules reads a json array of rules, making sure that every pattern is
+// valid.
+func ReadRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, errors.Wrap(err, "rules")
+	}
+	for k, rule := range rules {
+		if rule.Pattern == "" {
+			return nil, errors.Errorf("rule %d has no pattern", k)
+		}
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "rule %d", k)
+		}
+	}
+	return rules, nil
+}
+
+// metadataFor returns the metadata of the file from the first rule that
+// matches it. If there are rules, then a file that matches none of them, or
+// that matches a rule that ignores it, isn't ingested.
+func metadataFor(rules []Rule, name string) (Metadata, bool) {
+	if len(rules) == 0 {
+		return Metadata{Name: name}, true
+	}
+	for _, rule := range rules {
+		if !rule.match(name) {
+			continue
+		}
+		if rule.Ignore {
+			return Metadata{}, false
+		}
+		return Metadata{
+			Name:        name,
+			Tags:        rule.Tags,
+			AuthorID:    rule.AuthorID,
+			ContentType: rule.ContentType,
+		}, true
+	}
+	return Metadata{}, false
+}
+
+// readSidecar reads the sidecar of the file, if there is one.
+func readSidecar(file string) (Metadata, error) {
+	var metadata Metadata
+
+	b, err := ioutil.ReadFile(file + SidecarExt)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return metadata, nil
+		}
+		return metadata, err
+	}
+	if err = json.Unmarshal(b, &metadata); err != nil {
+		return metadata, errors.Wrap(err, "sidecar")
+	}
+	return metadata, nil
+}
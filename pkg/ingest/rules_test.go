@@ -0,0 +1,75 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadRules(t *testing.T) {
+	t.Parallel()
+
+	t.Run("read", func(t *testing.T) {
+		rules, err := ReadRules(strings.NewReader(`[
+			{"pattern": "*.tmp", "ignore": true},
+			{"pattern": "invoices/*.pdf", "tags": ["invoice"], "author_id": "scanner"}
+		]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := []Rule{
+			{Pattern: "*.tmp", Ignore: true},
+			{Pattern: "invoices/*.pdf", Tags: []string{"invoice"}, AuthorID: "scanner"},
+		}, rules; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	for name, input := range map[string]string{
+		"invalid json":    `{`,
+		"missing pattern": `[{"tags": ["invoice"]}]`,
+		"bad pattern":     `[{"pattern": "["}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadRules(strings.NewReader(input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestMetadataFor(t *testing.T) {
+	t.Parallel()
+
+	rules := []Rule{
+		{Pattern: "*.tmp", Ignore: true},
+		{Pattern: "invoices/*", Tags: []string{"invoice"}},
+		{Pattern: "*.pdf", AuthorID: "scanner"},
+	}
+
+	for _, test := range []struct {
+		name     string
+		expected Metadata
+		ok       bool
+	}{
+		{"invoices/a.pdf", Metadata{Name: "invoices/a.pdf", Tags: []string{"invoice"}}, true},
+		{"other/b.pdf", Metadata{Name: "other/b.pdf", AuthorID: "scanner"}, true},
+		{"invoices/c.tmp", Metadata{}, false},
+		{"other/d.txt", Metadata{}, false},
+	} {
+		metadata, ok := metadataFor(rules, test.name)
+		if expected, actual := test.ok, ok; expected != actual {
+			t.Errorf("%s: expected: %t, actual: %t", test.name, expected, actual)
+		}
+		if expected, actual := test.expected, metadata; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected: %v, actual: %v", test.name, expected, actual)
+		}
+	}
+
+	if expected, actual := (Metadata{Name: "a.txt"}), func() Metadata {
+		metadata, _ := metadataFor(nil, "a.txt")
+		return metadata
+	}(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
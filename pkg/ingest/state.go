@@ -0,0 +1,83 @@
+package ingest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/uuid"
+)
+
+// State is what has been ingested, kept in a file so that a later run only
+// ingests what's new or changed.
+type State struct {
+	path  string
+	Files map[string]FileState `json:"files"`
+}
+
+// FileState is the last revision that was ingested for a file, by the path of
+// the file relative to the directory.
+type FileState struct {
+	ResourceID uuid.UUID `json:"resource_id"`
+	Address    string    `json:"address"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mod_time"`
+	Metadata   Metadata  `json:"metadata"`
+	IngestedOn time.Time `json:"ingested_on"`
+}
+
+// LoadState reads the state from the file. If the file doesn't exist, then the
+// state is empty, as nothing has been ingested yet.
+func LoadState(path string) (*State, error) {
+	state := &State{
+		path:  path,
+		Files: make(map[string]FileState),
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, err
+	}
+	if err = json.Unmarshal(b, state); err != nil {
+		return nil, errors.Wrapf(err, "state %q", path)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]FileState)
+	}
+	return state, nil
+}
+
+// Path returns the path of the file that the state is saved to.
+func (s *State) Path() string {
+	return s.path
+}
+
+// Save writes the state to its file. The state is written to a temporary file
+// first, then renamed, so that the file is never left half written.
+func (s *State) Save() error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
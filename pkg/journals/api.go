@@ -11,10 +11,12 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/auth"
 	errs "github.com/trussle/snowy/pkg/http"
 	"github.com/trussle/snowy/pkg/metrics"
 	"github.com/trussle/snowy/pkg/models"
 	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
 )
 
 // These are the query API URL paths.
@@ -30,7 +32,7 @@ const (
 
 // API serves the query API
 type API struct {
-	handler        http.Handler
+	handler        *mux.Router
 	repository     repository.Repository
 	logger         log.Logger
 	clients        metrics.Gauge
@@ -76,7 +78,7 @@ func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer func(begin time.Time) {
 		a.duration.WithLabelValues(
 			r.Method,
-			r.URL.Path,
+			metrics.Route(a.handler, r),
 			strconv.Itoa(iw.code),
 		).Observe(time.Since(begin).Seconds())
 	}(time.Now())
@@ -100,6 +102,8 @@ func (a *API) handleInsert(w http.ResponseWriter, r *http.Request) {
 	var (
 		internalError   = make(chan error)
 		badRequestError = make(chan error)
+		forbiddenError  = make(chan error)
+		quotaError      = make(chan error)
 		result          = make(chan models.Ledger)
 	)
 	go func() {
@@ -141,13 +145,44 @@ func (a *API) handleInsert(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if _, err = a.repository.PutContent(content); err != nil {
+		if err = auth.AuthorizeAuthor(r.Context(), ledger.AuthorID()); err != nil {
+			forbiddenError <- err
+			return
+		}
+
+		options, err := repository.BuildQuery(
+			repository.WithQueryAuthorID(ledger.AuthorID()),
+			repository.WithQueryTenant(tenant.FromContext(r.Context())),
+		)
+		if err != nil {
+			badRequestError <- err
+			return
+		}
+
+		if err = models.WithTenantID(options.Tenant)(&ledger); err != nil {
+			badRequestError <- err
+			return
+		}
+
+		if _, err = a.repo(r).PutContent(content, options); err != nil {
+			if repository.ErrQuotaExceeded(err) {
+				quotaError <- err
+				return
+			}
 			internalError <- err
 			return
 		}
 
-		ledgerResult, err := a.repository.InsertLedger(ledger)
+		ledgerResult, err := a.repo(r).InsertLedger(ledger)
 		if err != nil {
+			if repository.ErrInvalidSignature(err) {
+				badRequestError <- err
+				return
+			}
+			if repository.ErrQuotaExceeded(err) || repository.ErrDailyQuotaExceeded(err) {
+				quotaError <- err
+				return
+			}
 			internalError <- err
 			return
 		}
@@ -160,13 +195,26 @@ func (a *API) handleInsert(w http.ResponseWriter, r *http.Request) {
 
 	select {
 	case err := <-internalError:
-		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+		a.errors.InternalServerError(w, r, err.Error())
 	case err := <-badRequestError:
-		a.errors.Error(w, err.Error(), http.StatusBadRequest)
+		if repository.ErrInvalidSignature(err) {
+			a.errors.InvalidSignature(w, r, err.Error())
+			return
+		}
+		a.errors.InvalidInput(w, r, err)
+	case err := <-forbiddenError:
+		a.errors.Forbidden(w, r, err.Error())
+	case err := <-quotaError:
+		if repository.ErrDailyQuotaExceeded(err) {
+			a.errors.TooManyRequests(w, r, err.Error())
+			return
+		}
+		a.errors.QuotaExceeded(w, r, err.Error())
 	case resource := <-result:
 		// Make sure we collect the content for the result.
 		qr := InsertQueryResult{Params: qp}
 		qr.ResourceID = resource.ResourceID()
+		qr.ResourceAddress = resource.ResourceAddress()
 
 		// Finish
 		qr.Duration = time.Since(begin).String()
@@ -190,6 +238,9 @@ func (a *API) handleAppend(w http.ResponseWriter, r *http.Request) {
 	var (
 		internalError   = make(chan error)
 		badRequestError = make(chan error)
+		forbiddenError  = make(chan error)
+		quotaError      = make(chan error)
+		erasedError     = make(chan error)
 		result          = make(chan models.Ledger)
 	)
 	go func() {
@@ -231,13 +282,49 @@ func (a *API) handleAppend(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if _, err = a.repository.PutContent(content); err != nil {
+		if err = auth.AuthorizeAuthor(r.Context(), ledger.AuthorID()); err != nil {
+			forbiddenError <- err
+			return
+		}
+
+		principal, _ := auth.PrincipalFromContext(r.Context())
+		options, err := repository.BuildQuery(
+			repository.WithQueryAuthorID(ledger.AuthorID()),
+			repository.WithQueryPrincipal(principal.ID, principal.Groups),
+			repository.WithQueryTenant(tenant.FromContext(r.Context())),
+		)
+		if err != nil {
+			badRequestError <- err
+			return
+		}
+
+		if _, err = a.repo(r).PutContent(content, options); err != nil {
+			if repository.ErrQuotaExceeded(err) {
+				quotaError <- err
+				return
+			}
 			internalError <- err
 			return
 		}
 
-		ledgerResult, err := a.repository.AppendLedger(qp.ResourceID, ledger)
+		ledgerResult, err := a.repo(r).AppendLedger(qp.ResourceID, ledger, options)
 		if err != nil {
+			if repository.ErrInvalidSignature(err) {
+				badRequestError <- err
+				return
+			}
+			if repository.ErrQuotaExceeded(err) || repository.ErrDailyQuotaExceeded(err) {
+				quotaError <- err
+				return
+			}
+			if repository.ErrForbidden(err) {
+				forbiddenError <- err
+				return
+			}
+			if repository.ErrErased(err) {
+				erasedError <- err
+				return
+			}
 			internalError <- err
 			return
 		}
@@ -250,13 +337,28 @@ func (a *API) handleAppend(w http.ResponseWriter, r *http.Request) {
 
 	select {
 	case err := <-internalError:
-		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+		a.errors.InternalServerError(w, r, err.Error())
 	case err := <-badRequestError:
-		a.errors.Error(w, err.Error(), http.StatusBadRequest)
+		if repository.ErrInvalidSignature(err) {
+			a.errors.InvalidSignature(w, r, err.Error())
+			return
+		}
+		a.errors.InvalidInput(w, r, err)
+	case err := <-forbiddenError:
+		a.errors.Forbidden(w, r, err.Error())
+	case err := <-erasedError:
+		a.errors.PreconditionFailed(w, r, err.Error())
+	case err := <-quotaError:
+		if repository.ErrDailyQuotaExceeded(err) {
+			a.errors.TooManyRequests(w, r, err.Error())
+			return
+		}
+		a.errors.QuotaExceeded(w, r, err.Error())
 	case resource := <-result:
 		// Make sure we collect the content for the result.
 		qr := AppendQueryResult{Params: qp}
 		qr.ResourceID = resource.ResourceID()
+		qr.ResourceAddress = resource.ResourceAddress()
 
 		// Finish
 		qr.Duration = time.Since(begin).String()
@@ -329,6 +431,13 @@ func ingestLedger(reader io.ReadCloser, content models.Content, header ContentHe
 		models.WithResourceContentType(content.ContentType()),
 		models.WithAuthorID(input.AuthorID),
 		models.WithTags(input.Tags),
-		models.WithCreatedOn(time.Now()),
+		models.WithCreatedOn(input.CreatedOnTime(time.Now())),
+		models.WithSignature(input.SignatureKeyID, input.Signature),
 	)
 }
+
+// repo returns the repository bound to the context of the request, so that
+// the calls to the repository are traced as part of the request.
+func (a *API) repo(r *http.Request) repository.Repository {
+	return repository.WithContext(r.Context(), a.repository)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/golang/mock/gomock"
 	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
 	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
 	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
 	"github.com/trussle/uuid"
 )
@@ -170,7 +171,7 @@ func TestDocumentation_Flow(t *testing.T) {
 		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
 		repo.EXPECT().PutContent(Content(inputContent)).Return(outputContent, nil).Times(1)
-		repo.EXPECT().AppendLedger(uid, Ledger(inputDoc)).Return(outputDoc, nil).Times(1)
+		repo.EXPECT().AppendLedger(uid, Ledger(inputDoc), repository.Query{}).Return(outputDoc, nil).Times(1)
 
 		docBytes, err := json.Marshal(struct {
 			Name     string   `json:"name"`
@@ -22,8 +22,10 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	"github.com/trussle/harness/generators"
+	"github.com/trussle/snowy/pkg/metrics"
 	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
 	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
 	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
 	"github.com/trussle/uuid"
 )
@@ -377,7 +379,7 @@ func TestPostAPI(t *testing.T) {
 			writtenBytes.EXPECT().Add(float64(len(conBytes))).Times(1)
 			records.EXPECT().Inc().Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().PutContent(Content(content)).Return(content, nil).Times(1)
+			repo.EXPECT().PutContent(Content(content), repository.Query{AuthorID: &authorID}).Return(content, nil).Times(1)
 			repo.EXPECT().InsertLedger(Ledger(doc)).Return(doc, nil).Times(1)
 
 			docBytes, err := json.Marshal(struct {
@@ -480,7 +482,7 @@ func TestPostAPI(t *testing.T) {
 
 			duration.EXPECT().WithLabelValues("POST", "/", "500").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().PutContent(Content(content)).Return(content, nil).Times(1)
+			repo.EXPECT().PutContent(Content(content), repository.Query{AuthorID: &authorID}).Return(content, nil).Times(1)
 			repo.EXPECT().InsertLedger(Ledger(doc)).Return(doc, errors.New("bad")).Times(1)
 
 			docBytes, err := json.Marshal(struct {
@@ -562,7 +564,7 @@ func TestPostAPI(t *testing.T) {
 
 			duration.EXPECT().WithLabelValues("POST", "/", "500").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().PutContent(Content(content)).Return(content, errors.New("bad")).Times(1)
+			repo.EXPECT().PutContent(Content(content), repository.Query{AuthorID: &authorID}).Return(content, errors.New("bad")).Times(1)
 
 			docBytes, err := json.Marshal(struct {
 				Name     string   `json:"name"`
@@ -957,8 +959,8 @@ func TestPutAPI(t *testing.T) {
 			writtenBytes.EXPECT().Add(float64(len(conBytes))).Times(1)
 			records.EXPECT().Inc().Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().PutContent(Content(content)).Return(content, nil).Times(1)
-			repo.EXPECT().AppendLedger(resourceID, Ledger(doc)).Return(doc, nil).Times(1)
+			repo.EXPECT().PutContent(Content(content), repository.Query{AuthorID: &authorID}).Return(content, nil).Times(1)
+			repo.EXPECT().AppendLedger(resourceID, Ledger(doc), repository.Query{AuthorID: &authorID}).Return(doc, nil).Times(1)
 
 			docBytes, err := json.Marshal(struct {
 				Name     string   `json:"name"`
@@ -1060,8 +1062,8 @@ func TestPutAPI(t *testing.T) {
 
 			duration.EXPECT().WithLabelValues("PUT", "/", "500").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().PutContent(Content(content)).Return(content, nil).Times(1)
-			repo.EXPECT().AppendLedger(resourceID, Ledger(doc)).Return(doc, errors.New("bad")).Times(1)
+			repo.EXPECT().PutContent(Content(content), repository.Query{AuthorID: &authorID}).Return(content, nil).Times(1)
+			repo.EXPECT().AppendLedger(resourceID, Ledger(doc), repository.Query{AuthorID: &authorID}).Return(doc, errors.New("bad")).Times(1)
 
 			docBytes, err := json.Marshal(struct {
 				Name     string   `json:"name"`
@@ -1142,7 +1144,7 @@ func TestPutAPI(t *testing.T) {
 
 			duration.EXPECT().WithLabelValues("PUT", "/", "500").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().PutContent(Content(content)).Return(content, errors.New("bad")).Times(1)
+			repo.EXPECT().PutContent(Content(content), repository.Query{AuthorID: &authorID}).Return(content, errors.New("bad")).Times(1)
 
 			docBytes, err := json.Marshal(struct {
 				Name     string   `json:"name"`
@@ -1212,7 +1214,7 @@ func TestNotFoundAPI(t *testing.T) {
 			clients.EXPECT().Inc().Times(1)
 			clients.EXPECT().Dec().Times(1)
 
-			duration.EXPECT().WithLabelValues("GET", fmt.Sprintf("/%s", resource), "404").Return(observer).Times(1)
+			duration.EXPECT().WithLabelValues("GET", metrics.UnmatchedRoute, "404").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
 			resp, err := http.Get(fmt.Sprintf("%s/%s", server.URL, resource))
@@ -0,0 +1,60 @@
+package journals
+
+import "github.com/trussle/snowy/pkg/openapi"
+
+const (
+	defaultJSONContentType = "application/json"
+)
+
+// OpenAPI describes the routes of the API, relative to where the API is
+// mounted.
+func OpenAPI() openapi.Paths {
+	var (
+		tags  = []string{"journals"}
+		input = &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				defaultContentType: {Schema: formSchema()},
+			},
+		}
+		resource = openapi.Response{
+			Description: "The id of the resource and the address of its content",
+			Content: map[string]openapi.MediaType{
+				defaultJSONContentType: {Schema: openapi.Resource()},
+			},
+		}
+	)
+
+	return openapi.Paths{
+		APIPathInsertQuery: openapi.PathItem{
+			"post": {
+				OperationID: "insertJournal",
+				Summary:     "Insert the content and ledger of a new resource",
+				Tags:        tags,
+				Parameters:  InsertQueryParams{}.Parameters(queryRequired),
+				RequestBody: input,
+				Responses:   openapi.Responses(resource, "400", "403", "413", "429", "500"),
+			},
+			"put": {
+				OperationID: "appendJournal",
+				Summary:     "Append the content and ledger of a revision to a resource",
+				Tags:        tags,
+				Parameters:  AppendQueryParams{}.Parameters(queryRequired),
+				RequestBody: input,
+				Responses:   openapi.Responses(resource, "400", "403", "412", "413", "429", "500"),
+			},
+		},
+	}
+}
+
+// formSchema describes the multipart form, the content part has to have a
+// Content-Type and Content-Length header.
+func formSchema() openapi.Schema {
+	content := openapi.StringFormat("binary")
+	content.Description = "the content, with a Content-Type and Content-Length header"
+
+	return openapi.Object(map[string]openapi.Schema{
+		contentFormFile:  content,
+		documentFormFile: openapi.LedgerInput(),
+	})
+}
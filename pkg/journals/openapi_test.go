@@ -0,0 +1,102 @@
+package journals
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	errs "github.com/trussle/snowy/pkg/http"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/openapi"
+	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+	"github.com/trussle/uuid"
+)
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	paths := OpenAPI()
+
+	t.Run("routes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := NewAPI(repoMocks.NewMockRepository(ctrl), log.NewNopLogger(),
+			metricMocks.NewMockGauge(ctrl),
+			metricMocks.NewMockCounter(ctrl),
+			metricMocks.NewMockCounter(ctrl),
+			metricMocks.NewMockHistogramVec(ctrl),
+		)
+
+		routes, err := openapi.MuxRoutes(api.handler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := openapi.CheckRoutes(paths, routes); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		header := http.Header{"Content-Type": []string{defaultContentType}}
+
+		for route, decode := range map[string]func(*url.URL) error{
+			"POST " + APIPathInsertQuery: func(u *url.URL) error {
+				var qp InsertQueryParams
+				return qp.DecodeFrom(u, header, queryRequired)
+			},
+			"PUT " + APIPathAppendQuery: func(u *url.URL) error {
+				var qp AppendQueryParams
+				return qp.DecodeFrom(u, header, queryRequired)
+			},
+		} {
+			op := operation(t, paths, route)
+			if _, ok := op.RequestBody.Content[defaultContentType]; !ok {
+				t.Errorf("%s: expected %q request body", route, defaultContentType)
+			}
+			if err := openapi.CheckParameters(op.Parameters, decode); err != nil {
+				t.Errorf("%s: %v", route, err)
+			}
+		}
+	})
+
+	t.Run("responses", func(t *testing.T) {
+		var (
+			errors     = errs.NewError(log.NewNopLogger())
+			resourceID = uuid.MustNew()
+		)
+
+		for route, encode := range map[string]func(http.ResponseWriter){
+			"POST " + APIPathInsertQuery: func(w http.ResponseWriter) {
+				qr := InsertQueryResult{Errors: errors, ResourceID: resourceID}
+				qr.EncodeTo(w)
+			},
+			"PUT " + APIPathAppendQuery: func(w http.ResponseWriter) {
+				qr := AppendQueryResult{Errors: errors, ResourceID: resourceID}
+				qr.EncodeTo(w)
+			},
+		} {
+			op := operation(t, paths, route)
+
+			w := httptest.NewRecorder()
+			encode(w)
+
+			if err := openapi.Validate(op.Responses["200"].Content[defaultJSONContentType].Schema, w.Body.Bytes()); err != nil {
+				t.Errorf("%s: %v", route, err)
+			}
+		}
+	})
+}
+
+func operation(t *testing.T, paths openapi.Paths, route string) openapi.Operation {
+	parts := strings.SplitN(route, " ", 2)
+	op, ok := paths[parts[1]][strings.ToLower(parts[0])]
+	if !ok {
+		t.Fatalf("route %q not described", route)
+	}
+	return op
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/pkg/errors"
 	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/openapi"
 	"github.com/trussle/uuid"
 )
 
@@ -37,12 +38,19 @@ func (qp *InsertQueryParams) DecodeFrom(u *url.URL, h http.Header, rb queryBehav
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads, the content type
+// is described by the request body.
+func (InsertQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return nil
+}
+
 // InsertQueryResult contains statistics about the query.
 type InsertQueryResult struct {
 	Errors     errs.Error
 	Params     InsertQueryParams `json:"query"`
-	Duration   string            `json:"duration"`
-	ResourceID uuid.UUID         `json:"resource_id"`
+	Duration        string            `json:"duration"`
+	ResourceID      uuid.UUID         `json:"resource_id"`
+	ResourceAddress string            `json:"resource_address"`
 }
 
 // EncodeTo encodes the InsertQueryResult to the HTTP response writer.
@@ -51,9 +59,11 @@ func (qr *InsertQueryResult) EncodeTo(w http.ResponseWriter) {
 	w.Header().Set(httpHeaderDuration, qr.Duration)
 
 	if err := json.NewEncoder(w).Encode(struct {
-		ResourceID uuid.UUID `json:"resource_id"`
+		ResourceID      uuid.UUID `json:"resource_id"`
+		ResourceAddress string    `json:"resource_address"`
 	}{
-		ResourceID: qr.ResourceID,
+		ResourceID:      qr.ResourceID,
+		ResourceAddress: qr.ResourceAddress,
 	}); err != nil {
 		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
 	}
@@ -129,12 +139,20 @@ func (qp *AppendQueryParams) DecodeFrom(u *url.URL, h http.Header, rb queryBehav
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads.
+func (AppendQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("resource_id", "id of the resource", rb == queryRequired, openapi.StringFormat("uuid")),
+	}
+}
+
 // AppendQueryResult contains statistics about the query.
 type AppendQueryResult struct {
 	Errors     errs.Error
 	Params     AppendQueryParams `json:"query"`
-	Duration   string            `json:"duration"`
-	ResourceID uuid.UUID         `json:"resource_id"`
+	Duration        string            `json:"duration"`
+	ResourceID      uuid.UUID         `json:"resource_id"`
+	ResourceAddress string            `json:"resource_address"`
 }
 
 // EncodeTo encodes the AppendQueryResult to the HTTP response writer.
@@ -144,9 +162,11 @@ func (qr *AppendQueryResult) EncodeTo(w http.ResponseWriter) {
 	w.Header().Set(httpHeaderResourceID, qr.Params.ResourceID.String())
 
 	if err := json.NewEncoder(w).Encode(struct {
-		ResourceID uuid.UUID `json:"resource_id"`
+		ResourceID      uuid.UUID `json:"resource_id"`
+		ResourceAddress string    `json:"resource_address"`
 	}{
-		ResourceID: qr.ResourceID,
+		ResourceID:      qr.ResourceID,
+		ResourceAddress: qr.ResourceAddress,
 	}); err != nil {
 		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
 	}
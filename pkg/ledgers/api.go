@@ -12,10 +12,14 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/gorilla/mux"
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/events"
 	errs "github.com/trussle/snowy/pkg/http"
 	"github.com/trussle/snowy/pkg/metrics"
 	"github.com/trussle/snowy/pkg/models"
 	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/snowy/pkg/trace"
 )
 
 // These are the query API URL paths.
@@ -26,22 +30,52 @@ const (
 	APIPathSelectRevisionsQuery = "/revisions/"
 	APIPathForkQuery            = "/fork/"
 	APIPathForkRevisionsQuery   = "/fork/revisions/"
+	APIPathVerifyQuery          = "/verify/"
+	APIPathSelectACLQuery       = "/acl/"
+	APIPathUpdateACLQuery       = "/acl/"
+	APIPathACLRevisionsQuery    = "/acl/revisions/"
+	APIPathStatisticsQuery      = "/statistics/"
+	APIPathEventsQuery          = "/events/"
 )
 
 // API serves the query API
 type API struct {
-	handler    http.Handler
+	handler    *mux.Router
 	repository repository.Repository
 	logger     log.Logger
 	clients    metrics.Gauge
 	duration   metrics.HistogramVec
 	errors     errs.Error
+	events     events.Bus
+	heartbeat  time.Duration
+	admins     auth.Admins
+}
+
+// APIOption defines a option for configuring the API.
+type APIOption func(*API)
+
+// WithEvents serves a stream of the ledger events of the bus, as server-sent
+// events.
+func WithEvents(bus events.Bus) APIOption {
+	return func(a *API) {
+		a.events = bus
+	}
+}
+
+// WithAdmins sets the principals that can read the statistics of the ledgers.
+// The statistics are across every resource of the tenant, regardless of who
+// can read them, so they're only open to the admins when auth is enabled.
+func WithAdmins(admins auth.Admins) APIOption {
+	return func(a *API) {
+		a.admins = admins
+	}
 }
 
 // NewAPI creates a API with correct dependencies.
 func NewAPI(repository repository.Repository, logger log.Logger,
 	clients metrics.Gauge,
 	duration metrics.HistogramVec,
+	opts ...APIOption,
 ) *API {
 	api := &API{
 		repository: repository,
@@ -49,6 +83,10 @@ func NewAPI(repository repository.Repository, logger log.Logger,
 		clients:    clients,
 		duration:   duration,
 		errors:     errs.NewError(logger),
+		heartbeat:  defaultHeartbeat,
+	}
+	for _, opt := range opts {
+		opt(api)
 	}
 	{
 		router := mux.NewRouter().StrictSlash(true)
@@ -58,6 +96,14 @@ func NewAPI(repository repository.Repository, logger log.Logger,
 		router.Methods("GET").Path(APIPathSelectRevisionsQuery).HandlerFunc(api.handleSelectRevisions)
 		router.Methods("PUT").Path(APIPathForkQuery).HandlerFunc(api.handleFork)
 		router.Methods("GET").Path(APIPathForkRevisionsQuery).HandlerFunc(api.handleForkRevisions)
+		router.Methods("GET").Path(APIPathVerifyQuery).HandlerFunc(api.handleVerify)
+		router.Methods("GET").Path(APIPathSelectACLQuery).HandlerFunc(api.handleSelectACL)
+		router.Methods("PUT").Path(APIPathUpdateACLQuery).HandlerFunc(api.handleUpdateACL)
+		router.Methods("GET").Path(APIPathACLRevisionsQuery).HandlerFunc(api.handleACLRevisions)
+		router.Methods("GET").Path(APIPathStatisticsQuery).HandlerFunc(api.handleStatistics)
+		if api.events != nil {
+			router.Methods("GET").Path(APIPathEventsQuery).HandlerFunc(api.handleEvents)
+		}
 		router.NotFoundHandler = http.HandlerFunc(api.errors.NotFound)
 
 		api.handler = router
@@ -66,7 +112,7 @@ func NewAPI(repository repository.Repository, logger log.Logger,
 }
 
 func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	level.Info(a.logger).Log("url", r.URL.String())
+	level.Info(trace.Logger(r.Context(), a.logger)).Log("url", r.URL.String())
 
 	iw := &interceptingWriter{http.StatusOK, w}
 	w = iw
@@ -78,7 +124,7 @@ func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer func(begin time.Time) {
 		a.duration.WithLabelValues(
 			r.Method,
-			r.URL.Path,
+			metrics.Route(a.handler, r),
 			strconv.Itoa(iw.code),
 		).Observe(time.Since(begin).Seconds())
 	}(time.Now())
@@ -102,16 +148,22 @@ func (a *API) handleSelect(w http.ResponseWriter, r *http.Request) {
 	options, err := repository.BuildQuery(
 		repository.WithQueryTags(qp.Tags),
 		repository.WithQueryAuthorID(qp.AuthorID),
+		principalQuery(r),
+		tenantQuery(r),
 	)
 	if err != nil {
 		a.errors.BadRequest(w, r, err.Error())
 		return
 	}
 
-	doc, err := a.repository.SelectLedger(qp.ResourceID, options)
+	doc, err := a.repo(r).SelectLedger(qp.ResourceID, options)
 	if err != nil {
 		if repository.ErrNotFound(err) {
-			a.errors.NotFound(w, r)
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
 			return
 		}
 		a.errors.InternalServerError(w, r, err.Error())
@@ -144,12 +196,34 @@ func (a *API) handleInsert(w http.ResponseWriter, r *http.Request) {
 		return models.WithNewResourceID()
 	})
 	if err != nil {
+		a.errors.InvalidInput(w, r, err)
+		return
+	}
+
+	if err = auth.AuthorizeAuthor(r.Context(), doc.AuthorID()); err != nil {
+		a.errors.Forbidden(w, r, err.Error())
+		return
+	}
+
+	if err = models.WithTenantID(tenant.FromContext(r.Context()))(&doc); err != nil {
 		a.errors.BadRequest(w, r, err.Error())
 		return
 	}
 
-	resource, err := a.repository.InsertLedger(doc)
+	resource, err := a.repo(r).InsertLedger(doc)
 	if err != nil {
+		if repository.ErrInvalidSignature(err) {
+			a.errors.InvalidSignature(w, r, err.Error())
+			return
+		}
+		if repository.ErrQuotaExceeded(err) {
+			a.errors.QuotaExceeded(w, r, err.Error())
+			return
+		}
+		if repository.ErrDailyQuotaExceeded(err) {
+			a.errors.TooManyRequests(w, r, err.Error())
+			return
+		}
 		a.errors.InternalServerError(w, r, err.Error())
 		return
 	}
@@ -179,13 +253,44 @@ func (a *API) handleAppend(w http.ResponseWriter, r *http.Request) {
 	doc, err := ingestLedger(r.Body, func() models.DocOption {
 		return models.WithResourceID(qp.ResourceID)
 	})
+	if err != nil {
+		a.errors.InvalidInput(w, r, err)
+		return
+	}
+
+	if err = auth.AuthorizeAuthor(r.Context(), doc.AuthorID()); err != nil {
+		a.errors.Forbidden(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(principalQuery(r), tenantQuery(r))
 	if err != nil {
 		a.errors.BadRequest(w, r, err.Error())
 		return
 	}
 
-	resource, err := a.repository.AppendLedger(qp.ResourceID, doc)
+	resource, err := a.repo(r).AppendLedger(qp.ResourceID, doc, options)
 	if err != nil {
+		if repository.ErrInvalidSignature(err) {
+			a.errors.InvalidSignature(w, r, err.Error())
+			return
+		}
+		if repository.ErrQuotaExceeded(err) {
+			a.errors.QuotaExceeded(w, r, err.Error())
+			return
+		}
+		if repository.ErrDailyQuotaExceeded(err) {
+			a.errors.TooManyRequests(w, r, err.Error())
+			return
+		}
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
+		if repository.ErrErased(err) {
+			a.errors.PreconditionFailed(w, r, err.Error())
+			return
+		}
 		a.errors.InternalServerError(w, r, err.Error())
 		return
 	}
@@ -215,13 +320,44 @@ func (a *API) handleFork(w http.ResponseWriter, r *http.Request) {
 	doc, err := ingestLedger(r.Body, func() models.DocOption {
 		return models.WithNewResourceID()
 	})
+	if err != nil {
+		a.errors.InvalidInput(w, r, err)
+		return
+	}
+
+	if err = auth.AuthorizeAuthor(r.Context(), doc.AuthorID()); err != nil {
+		a.errors.Forbidden(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(principalQuery(r), tenantQuery(r))
 	if err != nil {
 		a.errors.BadRequest(w, r, err.Error())
 		return
 	}
 
-	resource, err := a.repository.ForkLedger(qp.ResourceID, doc)
+	resource, err := a.repo(r).ForkLedger(qp.ResourceID, doc, options)
 	if err != nil {
+		if repository.ErrInvalidSignature(err) {
+			a.errors.InvalidSignature(w, r, err.Error())
+			return
+		}
+		if repository.ErrQuotaExceeded(err) {
+			a.errors.QuotaExceeded(w, r, err.Error())
+			return
+		}
+		if repository.ErrDailyQuotaExceeded(err) {
+			a.errors.TooManyRequests(w, r, err.Error())
+			return
+		}
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
+		if repository.ErrErased(err) {
+			a.errors.PreconditionFailed(w, r, err.Error())
+			return
+		}
 		a.errors.InternalServerError(w, r, err.Error())
 		return
 	}
@@ -251,14 +387,20 @@ func (a *API) handleSelectRevisions(w http.ResponseWriter, r *http.Request) {
 	options, err := repository.BuildQuery(
 		repository.WithQueryTags(qp.Tags),
 		repository.WithQueryAuthorID(qp.AuthorID),
+		principalQuery(r),
+		tenantQuery(r),
 	)
 	if err != nil {
 		a.errors.BadRequest(w, r, err.Error())
 		return
 	}
 
-	ledgers, err := a.repository.SelectLedgers(qp.ResourceID, options)
+	ledgers, err := a.repo(r).SelectLedgers(qp.ResourceID, options)
 	if err != nil {
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
 		a.errors.InternalServerError(w, r, err.Error())
 		return
 	}
@@ -285,8 +427,18 @@ func (a *API) handleForkRevisions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ledgers, err := a.repository.SelectForkLedgers(qp.ResourceID)
+	options, err := repository.BuildQuery(principalQuery(r), tenantQuery(r))
 	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	ledgers, err := a.repo(r).SelectForkLedgers(qp.ResourceID, options)
+	if err != nil {
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
 		a.errors.InternalServerError(w, r, err.Error())
 		return
 	}
@@ -300,6 +452,209 @@ func (a *API) handleForkRevisions(w http.ResponseWriter, r *http.Request) {
 	qr.EncodeTo(w)
 }
 
+func (a *API) handleVerify(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp VerifyQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(tenantQuery(r))
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	verification, err := a.repo(r).VerifyLedger(qp.ResourceID, options)
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the verification for the result.
+	qr := VerifyQueryResult{Errors: a.errors, Params: qp}
+	qr.Verification = verification
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleStatistics(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	if err := a.admins.Authorize(r.Context()); err != nil {
+		a.errors.Forbidden(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(tenantQuery(r))
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	statistics, err := a.repo(r).LedgerStatistics(options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the statistics for the result.
+	qr := StatisticsQueryResult{Errors: a.errors, Tenant: options.Tenant}
+	qr.Statistics = statistics
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleSelectACL(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp ACLQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(principalQuery(r), tenantQuery(r))
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	acl, err := a.repo(r).SelectACL(qp.ResourceID, options)
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the acl for the result.
+	qr := ACLQueryResult{Errors: a.errors, Params: qp}
+	qr.ACL = acl
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleUpdateACL(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp ACLQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	input, err := ingestACL(r.Body)
+	if err != nil {
+		a.errors.InvalidInput(w, r, err)
+		return
+	}
+
+	options, err := repository.BuildQuery(principalQuery(r), tenantQuery(r))
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	acl, err := a.repo(r).UpdateACL(qp.ResourceID, models.ACL{
+		ResourceID: qp.ResourceID,
+		Owner:      input.Owner,
+		Readers:    input.Readers,
+		Writers:    input.Writers,
+	}, options)
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the acl for the result.
+	qr := ACLQueryResult{Errors: a.errors, Params: qp}
+	qr.ACL = acl
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleACLRevisions(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp ACLQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(principalQuery(r), tenantQuery(r))
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	acls, err := a.repo(r).SelectACLRevisions(qp.ResourceID, options)
+	if err != nil {
+		if repository.ErrForbidden(err) {
+			a.errors.Forbidden(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the acls for the result.
+	qr := ACLRevisionsQueryResult{Errors: a.errors, Params: qp}
+	qr.ACLs = acls
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
 type interceptingWriter struct {
 	code int
 	http.ResponseWriter
@@ -310,6 +665,14 @@ func (iw *interceptingWriter) WriteHeader(code int) {
 	iw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush flushes the underlying writer, so that streams can be served through
+// the interceptingWriter.
+func (iw *interceptingWriter) Flush() {
+	if flusher, ok := iw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func ingestLedger(reader io.ReadCloser, fn func() models.DocOption) (models.Ledger, error) {
 	bytes, err := ioutil.ReadAll(reader)
 	if err != nil {
@@ -336,6 +699,46 @@ func ingestLedger(reader io.ReadCloser, fn func() models.DocOption) (models.Ledg
 		models.WithResourceContentType(input.ResourceContentType),
 		models.WithAuthorID(input.AuthorID),
 		models.WithTags(input.Tags),
-		models.WithCreatedOn(time.Now()),
+		models.WithCreatedOn(input.CreatedOnTime(time.Now())),
+		models.WithSignature(input.SignatureKeyID, input.Signature),
 	)
 }
+
+func ingestACL(reader io.ReadCloser) (models.ACLInput, error) {
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return models.ACLInput{}, err
+	}
+
+	if len(bytes) < 1 {
+		return models.ACLInput{}, errors.New("no body content")
+	}
+
+	var input models.ACLInput
+	if err = json.Unmarshal(bytes, &input); err != nil {
+		return models.ACLInput{}, err
+	}
+	if err = models.ValidateACLInput(input); err != nil {
+		return models.ACLInput{}, err
+	}
+	return input, nil
+}
+
+// principalQuery returns the query option for the principal of the request,
+// so that the access control list of the resource is checked.
+func principalQuery(r *http.Request) repository.QueryOption {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	return repository.WithQueryPrincipal(principal.ID, principal.Groups)
+}
+
+// tenantQuery returns the query option for the tenant of the request, so that
+// the repository is scoped to the tenant.
+func tenantQuery(r *http.Request) repository.QueryOption {
+	return repository.WithQueryTenant(tenant.FromContext(r.Context()))
+}
+
+// repo returns the repository bound to the context of the request, so that
+// the calls to the repository are traced as part of the request.
+func (a *API) repo(r *http.Request) repository.Repository {
+	return repository.WithContext(r.Context(), a.repository)
+}
@@ -178,7 +178,7 @@ func TestDocumentation_Flow(t *testing.T) {
 		duration.EXPECT().WithLabelValues("PUT", "/", "200").Return(observer).Times(1)
 		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-		repo.EXPECT().AppendLedger(uid, Ledger(inputDoc)).Return(outputDoc, nil).Times(1)
+		repo.EXPECT().AppendLedger(uid, Ledger(inputDoc), repository.Query{}).Return(outputDoc, nil).Times(1)
 
 		b, err := json.Marshal(struct {
 			Name     string   `json:"name"`
@@ -207,7 +207,7 @@ func TestDocumentation_Flow(t *testing.T) {
 		duration.EXPECT().WithLabelValues("PUT", "/fork/", "200").Return(observer).Times(1)
 		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-		repo.EXPECT().ForkLedger(uid, Ledger(inputDoc)).Return(outputForkDoc, nil).Times(1)
+		repo.EXPECT().ForkLedger(uid, Ledger(inputDoc), repository.Query{}).Return(outputForkDoc, nil).Times(1)
 
 		b, err := json.Marshal(struct {
 			Name     string   `json:"name"`
@@ -13,15 +13,19 @@ import (
 	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/golang/mock/gomock"
 	"github.com/trussle/harness/generators"
 	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/metrics"
 	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
 	"github.com/trussle/snowy/pkg/models"
 	"github.com/trussle/snowy/pkg/repository"
 	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+	"github.com/trussle/snowy/pkg/tenant"
 	"github.com/trussle/uuid"
 )
 
@@ -651,6 +655,232 @@ func TestPostAPI(t *testing.T) {
 			t.Error(err)
 		}
 	})
+	t.Run("post with signed body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+
+			createdOn = time.Date(2018, 5, 10, 20, 0, 35, 0, time.UTC)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+		repo.EXPECT().InsertLedger(gomock.Any()).Do(func(doc models.Ledger) {
+			if expected, actual := "key", doc.SignatureKeyID(); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+			if expected, actual := "signature", string(doc.Signature()); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+			if expected, actual := createdOn, doc.CreatedOn(); !expected.Equal(actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}).Return(models.Ledger{}, nil).Times(1)
+
+		b, err := json.Marshal(models.LedgerInput{
+			Name:           "name",
+			AuthorID:       "author",
+			CreatedOn:      createdOn.Format(time.RFC3339),
+			Signature:      []byte("signature"),
+			SignatureKeyID: "key",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("post with body but with invalid signature", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+		repo.EXPECT().InsertLedger(gomock.Any()).Return(models.Ledger{}, errInvalidSignature{errors.New("bad")}).Times(1)
+
+		b, err := json.Marshal(models.LedgerInput{
+			Name:           "name",
+			AuthorID:       "author",
+			CreatedOn:      time.Now().Format(time.RFC3339),
+			Signature:      []byte("signature"),
+			SignatureKeyID: "key",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("post with body but with quota exceeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/", "413").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+		repo.EXPECT().InsertLedger(gomock.Any()).Return(models.Ledger{}, errQuotaExceeded{errors.New("bad")}).Times(1)
+
+		b, err := json.Marshal(models.LedgerInput{
+			Name:      "name",
+			AuthorID:  "author",
+			CreatedOn: time.Now().Format(time.RFC3339),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusRequestEntityTooLarge, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("post with body but with daily quota exceeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/", "429").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+		repo.EXPECT().InsertLedger(gomock.Any()).Return(models.Ledger{}, errDailyQuotaExceeded{errors.New("bad")}).Times(1)
+
+		b, err := json.Marshal(models.LedgerInput{
+			Name:      "name",
+			AuthorID:  "author",
+			CreatedOn: time.Now().Format(time.RFC3339),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusTooManyRequests, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("post with body but with forbidden author", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := auth.WithPrincipal(r.Context(), auth.Principal{ID: "other"})
+				api.ServeHTTP(w, r.WithContext(ctx))
+			}))
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("POST", "/", "403").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		b, err := json.Marshal(models.LedgerInput{
+			Name:     "name",
+			AuthorID: "author",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusForbidden, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
 }
 
 func TestPutAPI(t *testing.T) {
@@ -910,7 +1140,7 @@ func TestPutAPI(t *testing.T) {
 
 			duration.EXPECT().WithLabelValues("PUT", "/", "200").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().AppendLedger(resourceID, Ledger(doc)).Return(doc, nil).Times(1)
+			repo.EXPECT().AppendLedger(resourceID, Ledger(doc), repository.Query{}).Return(doc, nil).Times(1)
 
 			b, err := json.Marshal(struct {
 				Name     string   `json:"name"`
@@ -989,7 +1219,7 @@ func TestPutAPI(t *testing.T) {
 
 			duration.EXPECT().WithLabelValues("PUT", "/", "500").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().AppendLedger(resourceID, Ledger(doc)).Return(doc, errors.New("bad")).Times(1)
+			repo.EXPECT().AppendLedger(resourceID, Ledger(doc), repository.Query{}).Return(doc, errors.New("bad")).Times(1)
 
 			b, err := json.Marshal(struct {
 				Name     string   `json:"name"`
@@ -1021,6 +1251,73 @@ func TestPutAPI(t *testing.T) {
 			t.Error(err)
 		}
 	})
+
+	t.Run("put with body of erased resource", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		fn := func(resourceID uuid.UUID, name, authorID string, tags generators.ASCIISlice) bool {
+			if len(name) == 0 || len(authorID) == 0 {
+				return true
+			}
+
+			doc, err := models.BuildLedger(
+				models.WithName(name),
+				models.WithAuthorID(authorID),
+				models.WithTags(tags),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
+
+			duration.EXPECT().WithLabelValues("PUT", "/", "412").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+			repo.EXPECT().AppendLedger(resourceID, Ledger(doc), repository.Query{}).Return(doc, errErased{errors.New("bad")}).Times(1)
+
+			b, err := json.Marshal(struct {
+				Name     string   `json:"name"`
+				AuthorID string   `json:"author_id"`
+				Tags     []string `json:"tags"`
+			}{
+				Name:     name,
+				AuthorID: authorID,
+				Tags:     tags,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := Put(fmt.Sprintf("%s?resource_id=%s", server.URL, resourceID), "application/json", bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if expected, actual := http.StatusPreconditionFailed, resp.StatusCode; expected != actual {
+				t.Fatalf("expected: %d, actual: %d", expected, actual)
+			}
+
+			return true
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
 }
 
 func TestSelectRevisionsAPI(t *testing.T) {
@@ -1515,7 +1812,7 @@ func TestForkAPI(t *testing.T) {
 
 			duration.EXPECT().WithLabelValues("PUT", "/fork/", "200").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().ForkLedger(resourceID, Ledger(doc)).Return(doc, nil).Times(1)
+			repo.EXPECT().ForkLedger(resourceID, Ledger(doc), repository.Query{}).Return(doc, nil).Times(1)
 
 			b, err := json.Marshal(struct {
 				Name     string   `json:"name"`
@@ -1594,7 +1891,7 @@ func TestForkAPI(t *testing.T) {
 
 			duration.EXPECT().WithLabelValues("PUT", "/fork/", "500").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
-			repo.EXPECT().ForkLedger(resourceID, Ledger(doc)).Return(doc, errors.New("bad")).Times(1)
+			repo.EXPECT().ForkLedger(resourceID, Ledger(doc), repository.Query{}).Return(doc, errors.New("bad")).Times(1)
 
 			b, err := json.Marshal(struct {
 				Name     string   `json:"name"`
@@ -1737,7 +2034,7 @@ func TestForkRevisionsAPI(t *testing.T) {
 			duration.EXPECT().WithLabelValues("GET", "/fork/revisions/", "200").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-			repo.EXPECT().SelectForkLedgers(uid).Times(1).Return(docs, nil)
+			repo.EXPECT().SelectForkLedgers(uid, repository.Query{}).Times(1).Return(docs, nil)
 
 			resp, err := http.Get(fmt.Sprintf("%s/fork/revisions/?resource_id=%s", server.URL, uid))
 			if err != nil {
@@ -1787,7 +2084,7 @@ func TestForkRevisionsAPI(t *testing.T) {
 			duration.EXPECT().WithLabelValues("GET", "/fork/revisions/", "200").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-			repo.EXPECT().SelectForkLedgers(uid).Times(1).Return(docs, nil)
+			repo.EXPECT().SelectForkLedgers(uid, repository.Query{}).Times(1).Return(docs, nil)
 
 			resp, err := http.Get(fmt.Sprintf("%s/fork/revisions/?resource_id=%s", server.URL, uid))
 			if err != nil {
@@ -1837,7 +2134,7 @@ func TestForkRevisionsAPI(t *testing.T) {
 			duration.EXPECT().WithLabelValues("GET", "/fork/revisions/", "500").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-			repo.EXPECT().SelectForkLedgers(uid).Times(1).Return(docs, errors.New("bad"))
+			repo.EXPECT().SelectForkLedgers(uid, repository.Query{}).Times(1).Return(docs, errors.New("bad"))
 
 			resp, err := http.Get(fmt.Sprintf("%s/fork/revisions/?resource_id=%s", server.URL, uid))
 			if err != nil {
@@ -1858,14 +2155,14 @@ func TestForkRevisionsAPI(t *testing.T) {
 	})
 }
 
-func TestNotFoundAPI(t *testing.T) {
+func TestVerifyAPI(t *testing.T) {
 	t.Parallel()
 
-	t.Run("get", func(t *testing.T) {
+	t.Run("get with no resource_id", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		fn := func(resource generators.ASCII) bool {
+		fn := func() bool {
 			var (
 				clients  = metricMocks.NewMockGauge(ctrl)
 				duration = metricMocks.NewMockHistogramVec(ctrl)
@@ -1880,15 +2177,19 @@ func TestNotFoundAPI(t *testing.T) {
 			clients.EXPECT().Inc().Times(1)
 			clients.EXPECT().Dec().Times(1)
 
-			duration.EXPECT().WithLabelValues("GET", fmt.Sprintf("/%s", resource), "404").Return(observer).Times(1)
+			duration.EXPECT().WithLabelValues("GET", "/verify/", "400").Return(observer).Times(1)
 			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-			resp, err := http.Get(fmt.Sprintf("%s/%s", server.URL, resource))
+			resp, err := http.Get(fmt.Sprintf("%s/verify/", server.URL))
 			if err != nil {
 				t.Error(err)
 			}
 			defer resp.Body.Close()
 
+			if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+
 			return true
 		}
 
@@ -1896,46 +2197,711 @@ func TestNotFoundAPI(t *testing.T) {
 			t.Error(err)
 		}
 	})
-}
 
-func Put(url string, contentType string, body io.Reader) (resp *http.Response, err error) {
-	req, err := http.NewRequest("PUT", url, body)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", contentType)
-	return http.DefaultClient.Do(req)
-}
+	t.Run("get with resource_id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
 
-type ledgerMatcher struct {
-	doc models.Ledger
-}
+		fn := func(uid uuid.UUID, revisions uint8) bool {
+			var (
+				clients  = metricMocks.NewMockGauge(ctrl)
+				duration = metricMocks.NewMockHistogramVec(ctrl)
+				observer = metricMocks.NewMockObserver(ctrl)
+				repo     = repoMocks.NewMockRepository(ctrl)
 
-func (m ledgerMatcher) Matches(x interface{}) bool {
-	d, ok := x.(models.Ledger)
-	if !ok {
-		return false
-	}
+				api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+				server = httptest.NewServer(api)
 
-	return m.doc.Name() == d.Name() &&
-		m.doc.AuthorID() == d.AuthorID() &&
-		reflect.DeepEqual(m.doc.Tags(), d.Tags())
-}
+				verification = models.LedgerVerification{
+					ResourceID: uid,
+					Revisions:  int(revisions),
+					Valid:      true,
+				}
+			)
+			defer server.Close()
 
-func (ledgerMatcher) String() string {
-	return "is ledger"
-}
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
 
-func Ledger(doc models.Ledger) gomock.Matcher { return ledgerMatcher{doc} }
+			duration.EXPECT().WithLabelValues("GET", "/verify/", "200").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
 
-type errNotFound struct {
-	err error
-}
+			repo.EXPECT().VerifyLedger(uid, repository.Query{}).Times(1).Return(verification, nil)
 
-func (e errNotFound) Error() string {
-	return e.err.Error()
-}
+			resp, err := http.Get(fmt.Sprintf("%s/verify/?resource_id=%s", server.URL, uid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
 
-func (e errNotFound) NotFound() bool {
+			if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+
+			var res map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			return res["resource_id"] == uid.String() &&
+				res["revisions"] == float64(revisions) &&
+				res["valid"] == true &&
+				res["broken_id"] == nil
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("get with resource_id and broken chain", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(uid, brokenID uuid.UUID) bool {
+			var (
+				clients  = metricMocks.NewMockGauge(ctrl)
+				duration = metricMocks.NewMockHistogramVec(ctrl)
+				observer = metricMocks.NewMockObserver(ctrl)
+				repo     = repoMocks.NewMockRepository(ctrl)
+
+				api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+				server = httptest.NewServer(api)
+
+				verification = models.LedgerVerification{
+					ResourceID: uid,
+					Revisions:  2,
+					BrokenID:   brokenID,
+					Reason:     "hash mismatch",
+				}
+			)
+			defer server.Close()
+
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
+
+			duration.EXPECT().WithLabelValues("GET", "/verify/", "200").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+			repo.EXPECT().VerifyLedger(uid, repository.Query{}).Times(1).Return(verification, nil)
+
+			resp, err := http.Get(fmt.Sprintf("%s/verify/?resource_id=%s", server.URL, uid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var res map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			return res["valid"] == false &&
+				res["broken_id"] == brokenID.String() &&
+				res["reason"] == "hash mismatch"
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("get with resource_id but repo not found failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(uid uuid.UUID) bool {
+			var (
+				clients  = metricMocks.NewMockGauge(ctrl)
+				duration = metricMocks.NewMockHistogramVec(ctrl)
+				observer = metricMocks.NewMockObserver(ctrl)
+				repo     = repoMocks.NewMockRepository(ctrl)
+
+				api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+				server = httptest.NewServer(api)
+			)
+			defer server.Close()
+
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
+
+			duration.EXPECT().WithLabelValues("GET", "/verify/", "404").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+			repo.EXPECT().VerifyLedger(uid, repository.Query{}).Times(1).Return(models.LedgerVerification{}, errNotFound{errors.New("failure")})
+
+			resp, err := http.Get(fmt.Sprintf("%s/verify/?resource_id=%s", server.URL, uid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if expected, actual := http.StatusNotFound, resp.StatusCode; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+
+			return true
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestNotFoundAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("get", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(resource generators.ASCII) bool {
+			var (
+				clients  = metricMocks.NewMockGauge(ctrl)
+				duration = metricMocks.NewMockHistogramVec(ctrl)
+				observer = metricMocks.NewMockObserver(ctrl)
+				repo     = repoMocks.NewMockRepository(ctrl)
+
+				api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+				server = httptest.NewServer(api)
+			)
+			defer server.Close()
+
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
+
+			duration.EXPECT().WithLabelValues("GET", metrics.UnmatchedRoute, "404").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+			resp, err := http.Get(fmt.Sprintf("%s/%s", server.URL, resource))
+			if err != nil {
+				t.Error(err)
+			}
+			defer resp.Body.Close()
+
+			return true
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func Put(url string, contentType string, body io.Reader) (resp *http.Response, err error) {
+	req, err := http.NewRequest("PUT", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return http.DefaultClient.Do(req)
+}
+
+type ledgerMatcher struct {
+	doc models.Ledger
+}
+
+func (m ledgerMatcher) Matches(x interface{}) bool {
+	d, ok := x.(models.Ledger)
+	if !ok {
+		return false
+	}
+
+	return m.doc.Name() == d.Name() &&
+		m.doc.AuthorID() == d.AuthorID() &&
+		reflect.DeepEqual(m.doc.Tags(), d.Tags())
+}
+
+func (ledgerMatcher) String() string {
+	return "is ledger"
+}
+
+func Ledger(doc models.Ledger) gomock.Matcher { return ledgerMatcher{doc} }
+
+func TestACLAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("get with no resource_id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/acl/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		resp, err := http.Get(fmt.Sprintf("%s/acl/", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("get with resource_id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(uid uuid.UUID, owner string) bool {
+			var (
+				clients  = metricMocks.NewMockGauge(ctrl)
+				duration = metricMocks.NewMockHistogramVec(ctrl)
+				observer = metricMocks.NewMockObserver(ctrl)
+				repo     = repoMocks.NewMockRepository(ctrl)
+
+				api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+				server = httptest.NewServer(api)
+
+				acl = models.ACL{
+					ResourceID: uid,
+					Owner:      owner,
+					Readers:    []string{"group:auditors"},
+				}
+			)
+			defer server.Close()
+
+			clients.EXPECT().Inc().Times(1)
+			clients.EXPECT().Dec().Times(1)
+
+			duration.EXPECT().WithLabelValues("GET", "/acl/", "200").Return(observer).Times(1)
+			observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+			repo.EXPECT().SelectACL(uid, repository.Query{}).Times(1).Return(acl, nil)
+
+			resp, err := http.Get(fmt.Sprintf("%s/acl/?resource_id=%s", server.URL, uid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+
+			var res struct {
+				ResourceID string   `json:"resource_id"`
+				Owner      string   `json:"owner"`
+				Readers    []string `json:"readers"`
+				Writers    []string `json:"writers"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			return res.ResourceID == uid.String() &&
+				res.Owner == owner &&
+				reflect.DeepEqual(res.Readers, acl.Readers) &&
+				len(res.Writers) == 0
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("get with resource_id but forbidden", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := auth.WithPrincipal(r.Context(), auth.Principal{ID: "other", Groups: []string{"group"}})
+				api.ServeHTTP(w, r.WithContext(ctx))
+			}))
+
+			uid   = uuid.MustNew()
+			query = repository.Query{
+				Principal: &repository.Principal{ID: "other", Groups: []string{"group"}},
+			}
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/acl/", "403").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().SelectACL(uid, query).Times(1).Return(models.ACL{}, errForbidden{errors.New("forbidden")})
+
+		resp, err := http.Get(fmt.Sprintf("%s/acl/?resource_id=%s", server.URL, uid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusForbidden, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("put with body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+
+			uid = uuid.MustNew()
+			acl = models.ACL{
+				ResourceID: uid,
+				Owner:      "owner",
+				Readers:    []string{"reader"},
+				Writers:    []string{"writer"},
+			}
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("PUT", "/acl/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().UpdateACL(uid, acl, repository.Query{}).Times(1).Return(acl, nil)
+
+		b, err := json.Marshal(models.ACLInput{
+			Owner:   acl.Owner,
+			Readers: acl.Readers,
+			Writers: acl.Writers,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("PUT", fmt.Sprintf("%s/acl/?resource_id=%s", server.URL, uid), bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("put with invalid body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("PUT", "/acl/", "400").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		req, err := http.NewRequest("PUT", fmt.Sprintf("%s/acl/?resource_id=%s", server.URL, uuid.MustNew()), strings.NewReader(`{"readers":["reader"]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("get revisions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+
+			uid = uuid.MustNew()
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/acl/revisions/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().SelectACLRevisions(uid, repository.Query{}).Times(1).Return([]models.ACL{
+			{ResourceID: uid, Owner: "owner"},
+			{ResourceID: uid, Owner: "other", UpdatedBy: "owner"},
+		}, nil)
+
+		resp, err := http.Get(fmt.Sprintf("%s/acl/revisions/?resource_id=%s", server.URL, uid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		var res []map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(res); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+type errNotFound struct {
+	err error
+}
+
+func (e errNotFound) Error() string {
+	return e.err.Error()
+}
+
+func (e errNotFound) NotFound() bool {
+	return true
+}
+
+type errInvalidSignature struct {
+	err error
+}
+
+func (e errInvalidSignature) Error() string {
+	return e.err.Error()
+}
+
+func (e errInvalidSignature) InvalidSignature() bool {
+	return true
+}
+
+type errErased struct {
+	err error
+}
+
+func (e errErased) Error() string {
+	return e.err.Error()
+}
+
+func (e errErased) Erased() bool {
+	return true
+}
+
+type errForbidden struct {
+	err error
+}
+
+func (e errForbidden) Error() string {
+	return e.err.Error()
+}
+
+func (e errForbidden) Forbidden() bool {
+	return true
+}
+
+func TestStatisticsAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("get statistics", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/statistics/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().LedgerStatistics(repository.Query{}).Times(1).Return(models.LedgerStatistics{
+			TotalLedgers: 3,
+		}, nil)
+
+		resp, err := http.Get(fmt.Sprintf("%s/statistics/", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		var res map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := float64(3), res["total_ledgers"]; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("get statistics for tenant", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(tenant.NewMiddleware(api, log.NewNopLogger()))
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/statistics/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().LedgerStatistics(repository.Query{Tenant: "acme"}).Times(1).Return(models.LedgerStatistics{
+			TotalLedgers: 1,
+		}, nil)
+
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/statistics/", server.URL), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(tenant.HTTPHeaderTenant, "acme")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		var res map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "acme", res["tenant"]; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := float64(1), res["total_ledgers"]; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("get statistics without being an admin", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration, WithAdmins(auth.Admins{Principals: []string{"admin"}}))
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := auth.WithPrincipal(r.Context(), auth.Principal{ID: "other"})
+				api.ServeHTTP(w, r.WithContext(ctx))
+			}))
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/statistics/", "403").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		resp, err := http.Get(fmt.Sprintf("%s/statistics/", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusForbidden, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+type errQuotaExceeded struct {
+	err error
+}
+
+func (e errQuotaExceeded) Error() string {
+	return e.err.Error()
+}
+
+func (e errQuotaExceeded) QuotaExceeded() bool {
+	return true
+}
+
+type errDailyQuotaExceeded struct {
+	err error
+}
+
+func (e errDailyQuotaExceeded) Error() string {
+	return e.err.Error()
+}
+
+func (e errDailyQuotaExceeded) DailyQuotaExceeded() bool {
 	return true
 }
@@ -0,0 +1,180 @@
+package ledgers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/trussle/snowy/pkg/events"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/uuid"
+)
+
+const (
+	// defaultHeartbeat is how often a comment is sent on an idle stream, so
+	// that proxies don't close the connection.
+	defaultHeartbeat = 15 * time.Second
+
+	defaultEventStreamContentType = "text/event-stream"
+
+	httpHeaderLastEventID = "Last-Event-ID"
+
+	// resetEventType is the type of the event sent when the stream can't be
+	// resumed from the last event id, as the event is too old.
+	resetEventType = "reset"
+)
+
+func (a *API) handleEvents(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp SelectQueryParams
+	if err := qp.DecodeFrom(r.URL, queryOptional); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(
+		principalQuery(r),
+		tenantQuery(r),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	filter := events.Filter{
+		TenantID: tenant.FromContext(r.Context()),
+		AuthorID: qp.AuthorID,
+		Tags:     qp.Tags,
+	}
+	if !qp.ResourceID.Zero() {
+		filter.ResourceID = qp.ResourceID.String()
+
+		if options.Principal != nil {
+			if err := a.repo(r).Authorize(qp.ResourceID, models.AccessRead, options); err != nil {
+				if repository.ErrForbidden(err) {
+					a.errors.Forbidden(w, r, err.Error())
+					return
+				}
+				a.errors.InternalServerError(w, r, err.Error())
+				return
+			}
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		a.errors.InternalServerError(w, r, "streaming is not supported")
+		return
+	}
+
+	subscription := a.events.Subscribe(r.Header.Get(httpHeaderLastEventID))
+	defer subscription.Close()
+
+	w.Header().Set(httpHeaderContentType, defaultEventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(event events.Event) error {
+		if !filter.Matches(event) || !a.canRead(event, options) {
+			return nil
+		}
+		if err := writeEvent(w, event); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	// The events since the last event id are no longer known, so rather than
+	// carrying on as if nothing was missed, the client is told to read the
+	// ledgers again.
+	if subscription.Reset {
+		if err := writeReset(w, r.Header.Get(httpHeaderLastEventID)); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+
+	for _, event := range subscription.Backlog {
+		if err := send(event); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(a.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscription.C:
+			// The subscription is closed if the client can't keep up, in which
+			// case the client has to reconnect with the last event id.
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// canRead checks that the principal of the stream can read the resource of
+// the event.
+func (a *API) canRead(event events.Event, options repository.Query) bool {
+	if options.Principal == nil {
+		return true
+	}
+
+	resourceID, err := uuid.Parse(event.ResourceID)
+	if err != nil {
+		return false
+	}
+	if err := a.repository.Authorize(resourceID, models.AccessRead, options); err != nil {
+		if !repository.ErrForbidden(err) {
+			level.Error(a.logger).Log("action", "events", "resource_id", event.ResourceID, "err", err.Error())
+		}
+		return false
+	}
+	return true
+}
+
+// writeReset writes the reset event, which has no id so that the client
+// resumes from the same last event id if it reconnects before any other
+// event.
+func writeReset(w http.ResponseWriter, lastEventID string) error {
+	data, err := json.Marshal(struct {
+		LastEventID string `json:"last_event_id"`
+	}{
+		LastEventID: lastEventID,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", resetEventType, data)
+	return err
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
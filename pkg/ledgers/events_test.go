@@ -0,0 +1,287 @@
+package ledgers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/events"
+	"github.com/trussle/snowy/pkg/metrics"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+	"github.com/trussle/uuid"
+)
+
+func TestEventsAPI(t *testing.T) {
+	t.Parallel()
+
+	newEvent := func(resourceID uuid.UUID) events.Event {
+		return events.Event{
+			ID:         uuid.MustNew().String(),
+			Type:       "insert",
+			ResourceID: resourceID.String(),
+			Ledger:     []byte(`{}`),
+		}
+	}
+
+	// readEvent reads the lines of the next event from the stream, skipping
+	// any heartbeats.
+	readEvent := func(t *testing.T, reader *bufio.Reader) []string {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "" && len(lines) > 0:
+				return lines
+			case line == "" || strings.HasPrefix(line, ":"):
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	t.Run("not found without a bus", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration)
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", metrics.UnmatchedRoute, "404").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		resp, err := http.Get(fmt.Sprintf("%s/events/", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusNotFound, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("stream filtered by resource", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+			bus      = events.NewLocalBus(10)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration, WithEvents(bus))
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/events/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		resourceID := uuid.MustNew()
+		resp, err := http.Get(fmt.Sprintf("%s/events/?resource_id=%s", server.URL, resourceID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "text/event-stream", resp.Header.Get("Content-Type"); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		expected := newEvent(resourceID)
+		for _, event := range []events.Event{newEvent(uuid.MustNew()), expected} {
+			if err := bus.Publish(event); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		lines := readEvent(t, bufio.NewReader(resp.Body))
+		if expected, actual := "id: "+expected.ID, lines[0]; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "event: insert", lines[1]; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("resume from last event id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+			bus      = events.NewLocalBus(10)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration, WithEvents(bus))
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/events/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		var (
+			first  = newEvent(uuid.MustNew())
+			second = newEvent(uuid.MustNew())
+		)
+		for _, event := range []events.Event{first, second} {
+			if err := bus.Publish(event); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/events/", server.URL), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Last-Event-ID", first.ID)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		lines := readEvent(t, bufio.NewReader(resp.Body))
+		if expected, actual := "id: "+second.ID, lines[0]; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("resume from forgotten event id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+			bus      = events.NewLocalBus(1)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration, WithEvents(bus))
+			server = httptest.NewServer(api)
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/events/", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		var (
+			first  = newEvent(uuid.MustNew())
+			second = newEvent(uuid.MustNew())
+		)
+		for _, event := range []events.Event{first, second} {
+			if err := bus.Publish(event); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/events/", server.URL), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Last-Event-ID", first.ID)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		lines := readEvent(t, bufio.NewReader(resp.Body))
+		if expected, actual := []string{
+			"event: reset",
+			fmt.Sprintf(`data: {"last_event_id":%q}`, first.ID),
+		}, lines; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("stream of forbidden resource", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+			bus      = events.NewLocalBus(10)
+
+			api    = NewAPI(repo, log.NewNopLogger(), clients, duration, WithEvents(bus))
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := auth.WithPrincipal(r.Context(), auth.Principal{ID: "other"})
+				api.ServeHTTP(w, r.WithContext(ctx))
+			}))
+
+			uid   = uuid.MustNew()
+			query = repository.Query{
+				Principal: &repository.Principal{ID: "other"},
+			}
+		)
+		defer server.Close()
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", "/events/", "403").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		repo.EXPECT().Authorize(uid, models.AccessRead, query).Times(1).Return(errForbidden{errors.New("forbidden")})
+
+		resp, err := http.Get(fmt.Sprintf("%s/events/?resource_id=%s", server.URL, uid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusForbidden, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
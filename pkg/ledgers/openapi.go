@@ -0,0 +1,158 @@
+package ledgers
+
+import "github.com/trussle/snowy/pkg/openapi"
+
+// OpenAPI describes the routes of the API, relative to where the API is
+// mounted. The events route is only served when the API is created with
+// WithEvents.
+func OpenAPI() openapi.Paths {
+	var (
+		tags     = []string{"ledgers"}
+		ledger   = openapi.Response{Description: "The ledger", Content: openapi.JSON(openapi.Ledger())}
+		ledgers  = openapi.Response{Description: "The ledgers", Content: openapi.JSON(openapi.Array(openapi.Ledger()))}
+		resource = openapi.Response{Description: "The id of the resource", Content: openapi.JSON(openapi.ResourceID())}
+		acl      = openapi.Response{Description: "The access control list", Content: openapi.JSON(openapi.ACL())}
+		input    = &openapi.RequestBody{Required: true, Content: openapi.JSON(openapi.LedgerInput())}
+	)
+
+	return openapi.Paths{
+		APIPathSelectQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectLedger",
+				Summary:     "Select the head ledger of a resource",
+				Tags:        tags,
+				Parameters:  SelectQueryParams{}.Parameters(queryRequired),
+				Responses:   openapi.Responses(ledger, "400", "403", "404", "500"),
+			},
+			"post": {
+				OperationID: "insertLedger",
+				Summary:     "Insert the ledger of a new resource",
+				Tags:        tags,
+				Parameters:  InsertQueryParams{}.Parameters(queryRequired),
+				RequestBody: input,
+				Responses:   openapi.Responses(resource, "400", "403", "413", "429", "500"),
+			},
+			"put": {
+				OperationID: "appendLedger",
+				Summary:     "Append a revision to the ledger of a resource",
+				Tags:        tags,
+				Parameters:  AppendQueryParams{}.Parameters(queryRequired),
+				RequestBody: input,
+				Responses:   openapi.Responses(resource, "400", "403", "412", "413", "429", "500"),
+			},
+		},
+		APIPathSelectRevisionsQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectLedgerRevisions",
+				Summary:     "Select every revision of the ledger of a resource",
+				Tags:        tags,
+				Parameters:  SelectQueryParams{}.Parameters(queryRequired),
+				Responses:   openapi.Responses(ledgers, "400", "403", "500"),
+			},
+		},
+		APIPathForkQuery: openapi.PathItem{
+			"put": {
+				OperationID: "forkLedger",
+				Summary:     "Fork the ledger of a resource in to a new resource",
+				Tags:        tags,
+				Parameters:  ForkQueryParams{}.Parameters(queryRequired),
+				RequestBody: input,
+				Responses:   openapi.Responses(resource, "400", "403", "412", "413", "429", "500"),
+			},
+		},
+		APIPathForkRevisionsQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectForkRevisions",
+				Summary:     "Select the ledgers forked from a resource",
+				Tags:        tags,
+				Parameters:  SelectQueryParams{}.Parameters(queryRequired),
+				Responses:   openapi.Responses(ledgers, "400", "403", "500"),
+			},
+		},
+		APIPathVerifyQuery: openapi.PathItem{
+			"get": {
+				OperationID: "verifyLedger",
+				Summary:     "Verify the hash chain of the ledger of a resource",
+				Tags:        tags,
+				Parameters:  VerifyQueryParams{}.Parameters(queryRequired),
+				Responses: openapi.Responses(openapi.Response{
+					Description: "The verification",
+					Content:     openapi.JSON(verificationSchema()),
+				}, "400", "404", "500"),
+			},
+		},
+		APIPathSelectACLQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectACL",
+				Summary:     "Select the access control list of a resource",
+				Tags:        tags,
+				Parameters:  ACLQueryParams{}.Parameters(queryRequired),
+				Responses:   openapi.Responses(acl, "400", "403", "404", "500"),
+			},
+			"put": {
+				OperationID: "updateACL",
+				Summary:     "Update the access control list of a resource",
+				Tags:        tags,
+				Parameters:  ACLQueryParams{}.Parameters(queryRequired),
+				RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(openapi.ACLInput())},
+				Responses:   openapi.Responses(acl, "400", "403", "404", "500"),
+			},
+		},
+		APIPathACLRevisionsQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectACLRevisions",
+				Summary:     "Select every revision of the access control list of a resource",
+				Tags:        tags,
+				Parameters:  ACLQueryParams{}.Parameters(queryRequired),
+				Responses: openapi.Responses(openapi.Response{
+					Description: "The access control lists",
+					Content:     openapi.JSON(openapi.Array(openapi.ACL())),
+				}, "400", "403", "500"),
+			},
+		},
+		APIPathStatisticsQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectLedgerStatistics",
+				Summary:     "Select the statistics of the ledgers of the tenant",
+				Tags:        tags,
+				Responses: openapi.Responses(openapi.Response{
+					Description: "The statistics",
+					Content:     openapi.JSON(statisticsSchema()),
+				}, "400", "403", "500"),
+			},
+		},
+		APIPathEventsQuery: openapi.PathItem{
+			"get": {
+				OperationID: "streamLedgerEvents",
+				Summary:     "Stream the ledger events as server-sent events",
+				Tags:        tags,
+				Parameters: append(SelectQueryParams{}.Parameters(queryOptional),
+					openapi.Header(httpHeaderLastEventID, "id of the last event seen, to resume the stream after", false, openapi.String()),
+				),
+				Responses: openapi.Responses(openapi.Response{
+					Description: "The stream of events, starting with a reset event if the last event id is too old to resume after",
+					Content: map[string]openapi.MediaType{
+						defaultEventStreamContentType: {Schema: openapi.String()},
+					},
+				}, "400", "403", "500"),
+			},
+		},
+	}
+}
+
+func verificationSchema() openapi.Schema {
+	return openapi.Object(map[string]openapi.Schema{
+		"resource_id": openapi.StringFormat("uuid"),
+		"revisions":   openapi.Integer(),
+		"valid":       openapi.Boolean(),
+		"broken_id":   openapi.StringFormat("uuid"),
+		"reason":      openapi.String(),
+	}, "broken_id", "reason")
+}
+
+func statisticsSchema() openapi.Schema {
+	return openapi.Object(map[string]openapi.Schema{
+		"tenant":        openapi.String(),
+		"total_ledgers": openapi.Integer(),
+	})
+}
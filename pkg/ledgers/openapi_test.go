@@ -0,0 +1,231 @@
+package ledgers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/snowy/pkg/events"
+	errs "github.com/trussle/snowy/pkg/http"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/openapi"
+	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+	"github.com/trussle/uuid"
+)
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	paths := OpenAPI()
+
+	t.Run("routes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		api := NewAPI(repoMocks.NewMockRepository(ctrl), log.NewNopLogger(),
+			metricMocks.NewMockGauge(ctrl),
+			metricMocks.NewMockHistogramVec(ctrl),
+			WithEvents(events.NewLocalBus(1)),
+		)
+
+		routes, err := openapi.MuxRoutes(api.handler)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := openapi.CheckRoutes(paths, routes); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		header := http.Header{"Content-Type": []string{defaultContentType}}
+
+		for route, decode := range map[string]func(*url.URL) error{
+			"GET " + APIPathSelectQuery: func(u *url.URL) error {
+				var qp SelectQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"POST " + APIPathInsertQuery: func(u *url.URL) error {
+				var qp InsertQueryParams
+				return qp.DecodeFrom(u, header, queryRequired)
+			},
+			"PUT " + APIPathAppendQuery: func(u *url.URL) error {
+				var qp AppendQueryParams
+				return qp.DecodeFrom(u, header, queryRequired)
+			},
+			"GET " + APIPathSelectRevisionsQuery: func(u *url.URL) error {
+				var qp SelectQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"PUT " + APIPathForkQuery: func(u *url.URL) error {
+				var qp ForkQueryParams
+				return qp.DecodeFrom(u, header, queryRequired)
+			},
+			"GET " + APIPathForkRevisionsQuery: func(u *url.URL) error {
+				var qp SelectQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"GET " + APIPathVerifyQuery: func(u *url.URL) error {
+				var qp VerifyQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"GET " + APIPathSelectACLQuery: func(u *url.URL) error {
+				var qp ACLQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"PUT " + APIPathUpdateACLQuery: func(u *url.URL) error {
+				var qp ACLQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"GET " + APIPathACLRevisionsQuery: func(u *url.URL) error {
+				var qp ACLQueryParams
+				return qp.DecodeFrom(u, queryRequired)
+			},
+			"GET " + APIPathEventsQuery: func(u *url.URL) error {
+				var qp SelectQueryParams
+				return qp.DecodeFrom(u, queryOptional)
+			},
+		} {
+			op := operation(t, paths, route)
+			if err := openapi.CheckParameters(op.Parameters, decode); err != nil {
+				t.Errorf("%s: %v", route, err)
+			}
+		}
+	})
+
+	t.Run("responses", func(t *testing.T) {
+		var (
+			errors     = errs.NewError(log.NewNopLogger())
+			resourceID = uuid.MustNew()
+			ledger     = buildLedger(t, resourceID)
+			acl        = models.ACL{
+				ResourceID: resourceID,
+				Owner:      "owner",
+				CreatedOn:  time.Now(),
+			}
+		)
+
+		for route, encode := range map[string]func(http.ResponseWriter){
+			"GET " + APIPathSelectQuery: func(w http.ResponseWriter) {
+				qr := SelectQueryResult{Errors: errors, Ledger: ledger}
+				qr.EncodeTo(w)
+			},
+			"POST " + APIPathInsertQuery: func(w http.ResponseWriter) {
+				qr := InsertQueryResult{Errors: errors, ResourceID: resourceID}
+				qr.EncodeTo(w)
+			},
+			"PUT " + APIPathAppendQuery: func(w http.ResponseWriter) {
+				qr := AppendQueryResult{Errors: errors, ResourceID: resourceID}
+				qr.EncodeTo(w)
+			},
+			"GET " + APIPathSelectRevisionsQuery: func(w http.ResponseWriter) {
+				qr := SelectRevisionsQueryResult{Errors: errors, Ledgers: []models.Ledger{ledger}}
+				qr.EncodeTo(w)
+			},
+			"PUT " + APIPathForkQuery: func(w http.ResponseWriter) {
+				qr := ForkQueryResult{Errors: errors, ResourceID: resourceID}
+				qr.EncodeTo(w)
+			},
+			"GET " + APIPathForkRevisionsQuery: func(w http.ResponseWriter) {
+				qr := SelectRevisionsQueryResult{Errors: errors}
+				qr.EncodeTo(w)
+			},
+			"GET " + APIPathVerifyQuery: func(w http.ResponseWriter) {
+				qr := VerifyQueryResult{Errors: errors, Verification: models.LedgerVerification{
+					ResourceID: resourceID,
+					BrokenID:   uuid.MustNew(),
+					Reason:     "broken",
+				}}
+				qr.EncodeTo(w)
+			},
+			"GET " + APIPathSelectACLQuery: func(w http.ResponseWriter) {
+				qr := ACLQueryResult{Errors: errors, ACL: acl}
+				qr.EncodeTo(w)
+			},
+			"GET " + APIPathACLRevisionsQuery: func(w http.ResponseWriter) {
+				qr := ACLRevisionsQueryResult{Errors: errors, ACLs: []models.ACL{acl}}
+				qr.EncodeTo(w)
+			},
+			"GET " + APIPathStatisticsQuery: func(w http.ResponseWriter) {
+				qr := StatisticsQueryResult{Errors: errors, Tenant: "acme"}
+				qr.EncodeTo(w)
+			},
+		} {
+			op := operation(t, paths, route)
+
+			w := httptest.NewRecorder()
+			encode(w)
+
+			if err := openapi.Validate(op.Responses["200"].Content[defaultContentType].Schema, w.Body.Bytes()); err != nil {
+				t.Errorf("%s: %v", route, err)
+			}
+		}
+	})
+
+	t.Run("error responses", func(t *testing.T) {
+		var (
+			errors = errs.NewError(log.NewNopLogger())
+			r      = httptest.NewRequest("POST", APIPathInsertQuery, nil)
+		)
+		r.Header.Set("X-Request-ID", "id")
+
+		for route, encode := range map[string]func(http.ResponseWriter) string{
+			"GET " + APIPathSelectQuery: func(w http.ResponseWriter) string {
+				errors.ResourceNotFound(w, r, "not found")
+				return "404"
+			},
+			"POST " + APIPathInsertQuery: func(w http.ResponseWriter) string {
+				errors.InvalidInput(w, r, models.ValidateLedgerInput(models.LedgerInput{}))
+				return "400"
+			},
+			"PUT " + APIPathAppendQuery: func(w http.ResponseWriter) string {
+				errors.InternalServerError(w, r, "pq: relation does not exist")
+				return "500"
+			},
+		} {
+			w := httptest.NewRecorder()
+			code := encode(w)
+
+			media, ok := operation(t, paths, route).Responses[code].Content[w.Header().Get("Content-Type")]
+			if !ok {
+				t.Fatalf("%s: %s response of %q not described", route, code, w.Header().Get("Content-Type"))
+			}
+			if err := openapi.Validate(media.Schema, w.Body.Bytes()); err != nil {
+				t.Errorf("%s: %v", route, err)
+			}
+		}
+	})
+}
+
+func operation(t *testing.T, paths openapi.Paths, route string) openapi.Operation {
+	parts := strings.SplitN(route, " ", 2)
+	op, ok := paths[parts[1]][strings.ToLower(parts[0])]
+	if !ok {
+		t.Fatalf("route %q not described", route)
+	}
+	return op
+}
+
+func buildLedger(t *testing.T, resourceID uuid.UUID) models.Ledger {
+	ledger, err := models.BuildLedger(
+		models.WithID(uuid.MustNew()),
+		models.WithResourceID(resourceID),
+		models.WithName("name"),
+		models.WithResourceAddress("address"),
+		models.WithResourceSize(1),
+		models.WithResourceContentType("application/octet-stream"),
+		models.WithAuthorID("author"),
+		models.WithTags([]string{"a"}),
+		models.WithCreatedOn(time.Now()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ledger
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	errs "github.com/trussle/snowy/pkg/http"
 	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/openapi"
 	"github.com/trussle/uuid"
 )
 
@@ -53,6 +54,15 @@ func (qp *SelectQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads.
+func (SelectQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("resource_id", "id of the resource", rb == queryRequired, openapi.StringFormat("uuid")),
+		openapi.Query("query.tags", "comma separated tags the ledger has to have", false, openapi.String()),
+		openapi.Query("query.author_id", "author the ledger has to be written by", false, openapi.String()),
+	}
+}
+
 // SelectQueryResult contains statistics about the query.
 type SelectQueryResult struct {
 	Errors   errs.Error
@@ -88,6 +98,12 @@ func (qp *InsertQueryParams) DecodeFrom(u *url.URL, h http.Header, rb queryBehav
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads, the content type
+// is described by the request body.
+func (InsertQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return nil
+}
+
 // InsertQueryResult contains statistics about the query.
 type InsertQueryResult struct {
 	Errors     errs.Error
@@ -166,6 +182,13 @@ func (qp *AppendQueryParams) DecodeFrom(u *url.URL, h http.Header, rb queryBehav
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads.
+func (AppendQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("resource_id", "id of the resource", rb == queryRequired, openapi.StringFormat("uuid")),
+	}
+}
+
 // AppendQueryResult contains statistics about the query.
 type AppendQueryResult struct {
 	Errors     errs.Error
@@ -217,6 +240,13 @@ func (qp *ForkQueryParams) DecodeFrom(u *url.URL, h http.Header, rb queryBehavio
 	return nil
 }
 
+// Parameters describes the parameters that DecodeFrom reads.
+func (ForkQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("resource_id", "id of the resource", rb == queryRequired, openapi.StringFormat("uuid")),
+	}
+}
+
 // ForkQueryResult contains statistics about the query.
 type ForkQueryResult struct {
 	Errors     errs.Error
@@ -240,6 +270,176 @@ func (qr *ForkQueryResult) EncodeTo(w http.ResponseWriter) {
 	}
 }
 
+// StatisticsQueryResult contains statistics about the ledgers of a tenant.
+type StatisticsQueryResult struct {
+	Errors     errs.Error
+	Tenant     string                  `json:"tenant"`
+	Duration   string                  `json:"duration"`
+	Statistics models.LedgerStatistics `json:"statistics"`
+}
+
+// EncodeTo encodes the StatisticsQueryResult to the HTTP response writer.
+func (qr *StatisticsQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(struct {
+		Tenant       string `json:"tenant"`
+		TotalLedgers int    `json:"total_ledgers"`
+	}{
+		Tenant:       qr.Tenant,
+		TotalLedgers: qr.Statistics.TotalLedgers,
+	}); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// VerifyQueryParams defines all the dimensions of a query.
+type VerifyQueryParams struct {
+	ResourceID uuid.UUID `json:"resource_id"`
+}
+
+// DecodeFrom populates a VerifyQueryParams from a URL.
+func (qp *VerifyQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	// Required depending on the query behavior
+	var (
+		err        error
+		resourceID = u.Query().Get("resource_id")
+	)
+	if rb == queryRequired && resourceID == "" {
+		return errors.New("error reading 'resource_id' (required) query")
+	}
+	if resourceID != "" {
+		if qp.ResourceID, err = uuid.Parse(resourceID); err != nil {
+			return errors.Wrap(err, "error parsing 'resource_id' (required) query")
+		}
+	}
+
+	return nil
+}
+
+// Parameters describes the parameters that DecodeFrom reads.
+func (VerifyQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("resource_id", "id of the resource", rb == queryRequired, openapi.StringFormat("uuid")),
+	}
+}
+
+// VerifyQueryResult contains statistics about the query.
+type VerifyQueryResult struct {
+	Errors       errs.Error
+	Params       VerifyQueryParams         `json:"query"`
+	Duration     string                    `json:"duration"`
+	Verification models.LedgerVerification `json:"verification"`
+}
+
+// EncodeTo encodes the VerifyQueryResult to the HTTP response writer.
+func (qr *VerifyQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+	w.Header().Set(httpHeaderResourceID, qr.Params.ResourceID.String())
+
+	var (
+		verification = qr.Verification
+		brokenID     string
+	)
+	if !verification.Valid {
+		brokenID = verification.BrokenID.String()
+	}
+
+	if err := json.NewEncoder(w).Encode(struct {
+		ResourceID uuid.UUID `json:"resource_id"`
+		Revisions  int       `json:"revisions"`
+		Valid      bool      `json:"valid"`
+		BrokenID   string    `json:"broken_id,omitempty"`
+		Reason     string    `json:"reason,omitempty"`
+	}{
+		ResourceID: verification.ResourceID,
+		Revisions:  verification.Revisions,
+		Valid:      verification.Valid,
+		BrokenID:   brokenID,
+		Reason:     verification.Reason,
+	}); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ACLQueryParams defines all the dimensions of a query.
+type ACLQueryParams struct {
+	ResourceID uuid.UUID `json:"resource_id"`
+}
+
+// DecodeFrom populates a ACLQueryParams from a URL.
+func (qp *ACLQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	// Required depending on the query behavior
+	var (
+		err        error
+		resourceID = u.Query().Get("resource_id")
+	)
+	if rb == queryRequired && resourceID == "" {
+		return errors.New("error reading 'resource_id' (required) query")
+	}
+	if resourceID != "" {
+		if qp.ResourceID, err = uuid.Parse(resourceID); err != nil {
+			return errors.Wrap(err, "error parsing 'resource_id' (required) query")
+		}
+	}
+
+	return nil
+}
+
+// Parameters describes the parameters that DecodeFrom reads.
+func (ACLQueryParams) Parameters(rb queryBehavior) []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("resource_id", "id of the resource", rb == queryRequired, openapi.StringFormat("uuid")),
+	}
+}
+
+// ACLQueryResult contains statistics about the query.
+type ACLQueryResult struct {
+	Errors   errs.Error
+	Params   ACLQueryParams `json:"query"`
+	Duration string         `json:"duration"`
+	ACL      models.ACL     `json:"acl"`
+}
+
+// EncodeTo encodes the ACLQueryResult to the HTTP response writer.
+func (qr *ACLQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+	w.Header().Set(httpHeaderResourceID, qr.Params.ResourceID.String())
+
+	if err := json.NewEncoder(w).Encode(qr.ACL); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ACLRevisionsQueryResult contains statistics about the query.
+type ACLRevisionsQueryResult struct {
+	Errors   errs.Error
+	Params   ACLQueryParams `json:"query"`
+	Duration string         `json:"duration"`
+	ACLs     []models.ACL   `json:"acls"`
+}
+
+// EncodeTo encodes the ACLRevisionsQueryResult to the HTTP response writer.
+func (qr *ACLRevisionsQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+	w.Header().Set(httpHeaderResourceID, qr.Params.ResourceID.String())
+
+	// Make sure that we encode empty acls correctly (i.e. they're not null in
+	// the json output)
+	acls := qr.ACLs
+	if qr.ACLs == nil {
+		acls = make([]models.ACL, 0)
+	}
+
+	if err := json.NewEncoder(w).Encode(acls); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 const (
 	httpHeaderContentType   = "Content-Type"
 	httpHeaderDuration      = "X-Duration"
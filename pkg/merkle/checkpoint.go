@@ -0,0 +1,153 @@
+package merkle
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+)
+
+const treeHeadVersion = "snowy-tree-head-v1"
+
+// TreeHead represents the head of the tree at a checkpoint, which is what is
+// signed.
+type TreeHead struct {
+	TreeSize  int64
+	RootHash  []byte
+	Timestamp time.Time
+}
+
+// Bytes returns the canonical encoding of the tree head that is signed. The
+// timestamp is encoded at millisecond precision.
+func (h TreeHead) Bytes() []byte {
+	return []byte(fmt.Sprintf("%s\n%d\n%s\n%d\n",
+		treeHeadVersion,
+		h.TreeSize,
+		hex.EncodeToString(h.RootHash),
+		h.Timestamp.UnixNano()/int64(time.Millisecond),
+	))
+}
+
+// VerifyTreeHead verifies the signature of the tree head against the public
+// key.
+func VerifyTreeHead(head TreeHead, signature []byte, publicKey ed25519.PublicKey) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return errors.Errorf("invalid public key size %d", len(publicKey))
+	}
+	if !ed25519.Verify(publicKey, head.Bytes(), signature) {
+		return errors.New("invalid tree head signature")
+	}
+	return nil
+}
+
+// Checkpoint is the wire format of a signed tree head.
+type Checkpoint struct {
+	TreeSize  int64     `json:"tree_size"`
+	RootHash  string    `json:"root_hash"`
+	Timestamp time.Time `json:"timestamp"`
+	KeyID     string    `json:"key_id"`
+	Signature string    `json:"signature"`
+}
+
+// TreeHead returns the tree head of the checkpoint.
+func (c Checkpoint) TreeHead() (TreeHead, error) {
+	root, err := hex.DecodeString(c.RootHash)
+	if err != nil {
+		return TreeHead{}, errors.Wrap(err, "invalid root hash")
+	}
+	return TreeHead{
+		TreeSize:  c.TreeSize,
+		RootHash:  root,
+		Timestamp: c.Timestamp,
+	}, nil
+}
+
+// Verify verifies the signature of the checkpoint against the public key.
+func (c Checkpoint) Verify(publicKey ed25519.PublicKey) error {
+	head, err := c.TreeHead()
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	return VerifyTreeHead(head, signature, publicKey)
+}
+
+// Proof is the wire format of the proof that a ledger is included in a
+// checkpoint, along with an optional proof that a earlier checkpoint is
+// consistent with it.
+type Proof struct {
+	LedgerID       string      `json:"ledger_id"`
+	LedgerHash     string      `json:"ledger_hash"`
+	LeafIndex      int64       `json:"leaf_index"`
+	Checkpoint     Checkpoint  `json:"checkpoint"`
+	Inclusion      []string    `json:"inclusion"`
+	FromCheckpoint *Checkpoint `json:"from_checkpoint,omitempty"`
+	Consistency    []string    `json:"consistency,omitempty"`
+}
+
+// Verify verifies the signatures of the checkpoints, that the ledger is
+// included in the checkpoint and that the earlier checkpoint, if there is one,
+// is consistent with the checkpoint.
+func (p Proof) Verify(publicKey ed25519.PublicKey) error {
+	if err := p.Checkpoint.Verify(publicKey); err != nil {
+		return err
+	}
+	head, err := p.Checkpoint.TreeHead()
+	if err != nil {
+		return err
+	}
+
+	inclusion, err := decodeHashes(p.Inclusion)
+	if err != nil {
+		return err
+	}
+	leaf := LedgerLeafHash(p.LedgerID, p.LedgerHash)
+	if err := VerifyInclusion(leaf, p.LeafIndex, head.TreeSize, inclusion, head.RootHash); err != nil {
+		return err
+	}
+
+	if p.FromCheckpoint == nil {
+		return nil
+	}
+
+	if err := p.FromCheckpoint.Verify(publicKey); err != nil {
+		return err
+	}
+	from, err := p.FromCheckpoint.TreeHead()
+	if err != nil {
+		return err
+	}
+
+	consistency, err := decodeHashes(p.Consistency)
+	if err != nil {
+		return err
+	}
+	return VerifyConsistency(from.TreeSize, head.TreeSize, from.RootHash, head.RootHash, consistency)
+}
+
+// EncodeHashes encodes the hashes for the wire format.
+func EncodeHashes(hashes [][]byte) []string {
+	res := make([]string, len(hashes))
+	for k, v := range hashes {
+		res[k] = hex.EncodeToString(v)
+	}
+	return res
+}
+
+func decodeHashes(hashes []string) ([][]byte, error) {
+	res := make([][]byte, len(hashes))
+	for k, v := range hashes {
+		hash, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid proof hash")
+		}
+		res[k] = hash
+	}
+	return res, nil
+}
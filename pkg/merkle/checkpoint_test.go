@@ -0,0 +1,126 @@
+package merkle
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func signedCheckpoint(t *testing.T, key ed25519.PrivateKey, hashes [][]byte) Checkpoint {
+	head := TreeHead{
+		TreeSize:  int64(len(hashes)),
+		RootHash:  RootHash(hashes),
+		Timestamp: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	return Checkpoint{
+		TreeSize:  head.TreeSize,
+		RootHash:  hex.EncodeToString(head.RootHash),
+		Timestamp: head.Timestamp,
+		KeyID:     "key",
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, head.Bytes())),
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("verify", func(t *testing.T) {
+		checkpoint := signedCheckpoint(t, key, leaves(5))
+		if err := checkpoint.Verify(pub); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("verify with other key", func(t *testing.T) {
+		other, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkpoint := signedCheckpoint(t, key, leaves(5))
+		if expected, actual := false, checkpoint.Verify(other) == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("verify tampered tree size", func(t *testing.T) {
+		checkpoint := signedCheckpoint(t, key, leaves(5))
+		checkpoint.TreeSize++
+
+		if expected, actual := false, checkpoint.Verify(pub) == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
+
+func TestProof(t *testing.T) {
+	t.Parallel()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		ledgerID   = "ledger-2"
+		ledgerHash = "hash-2"
+		hashes     = leaves(7)
+	)
+	hashes[2] = LedgerLeafHash(ledgerID, ledgerHash)
+
+	proof := func(t *testing.T) Proof {
+		inclusion, err := InclusionProof(hashes, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		consistency, err := ConsistencyProof(hashes, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		from := signedCheckpoint(t, key, hashes[:3])
+		return Proof{
+			LedgerID:       ledgerID,
+			LedgerHash:     ledgerHash,
+			LeafIndex:      2,
+			Checkpoint:     signedCheckpoint(t, key, hashes),
+			Inclusion:      EncodeHashes(inclusion),
+			FromCheckpoint: &from,
+			Consistency:    EncodeHashes(consistency),
+		}
+	}
+
+	t.Run("verify", func(t *testing.T) {
+		if err := proof(t).Verify(pub); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("verify tampered ledger hash", func(t *testing.T) {
+		p := proof(t)
+		p.LedgerHash = "tampered"
+
+		if expected, actual := false, p.Verify(pub) == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("verify inconsistent checkpoint", func(t *testing.T) {
+		p := proof(t)
+		from := signedCheckpoint(t, key, leaves(3))
+		p.FromCheckpoint = &from
+
+		if expected, actual := false, p.Verify(pub) == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package merkle
+
+import (
+	"github.com/pkg/errors"
+)
+
+// NodeID identifies a complete subtree of the tree, made up of the 1<<Level
+// leaves starting at the leaf Index<<Level. The leaves themselves are the
+// nodes at level zero.
+type NodeID struct {
+	Level int
+	Index int64
+}
+
+// Node is the hash of a complete subtree of the tree. Once a subtree is
+// complete its hash never changes, so the nodes can be stored as the tree
+// grows, and the root hash and proofs of any size of the tree made from a few
+// of them, rather than from every leaf.
+type Node struct {
+	NodeID
+	Hash []byte
+}
+
+// Nodes are the hashes of complete subtrees of the tree, by their id.
+type Nodes map[NodeID][]byte
+
+// NewNodes creates Nodes from the nodes.
+func NewNodes(nodes []Node) Nodes {
+	res := make(Nodes, len(nodes))
+	for _, v := range nodes {
+		res[v.NodeID] = v.Hash
+	}
+	return res
+}
+
+// RootNodes returns the ids of the nodes needed for the root hash of the tree
+// of the size. They are also the nodes needed to append to the tree.
+func RootNodes(size int64) []NodeID {
+	return span{0, size}.nodes()
+}
+
+// InclusionNodes returns the ids of the nodes needed for the proof that the
+// leaf at the index is included in the tree of the size.
+func InclusionNodes(index, size int64) []NodeID {
+	if index < 0 || index >= size {
+		return nil
+	}
+	return spanNodes(inclusionSpans(span{0, size}, index))
+}
+
+// ConsistencyNodes returns the ids of the nodes needed for the proof that the
+// tree of the first size is a prefix of the tree of the second size.
+func ConsistencyNodes(size1, size2 int64) []NodeID {
+	if size1 <= 0 || size1 > size2 {
+		return nil
+	}
+	return spanNodes(consistencySpans(span{0, size2}, size1, true))
+}
+
+// RootHash returns the root hash of the tree of the size, from the nodes of
+// RootNodes.
+func (n Nodes) RootHash(size int64) ([]byte, error) {
+	if size <= 0 {
+		return RootHash(nil), nil
+	}
+	return n.hash(span{0, size})
+}
+
+// InclusionProof returns the audit path proving that the leaf at the index is
+// included in the tree of the size, from the nodes of InclusionNodes.
+func (n Nodes) InclusionProof(index, size int64) ([][]byte, error) {
+	if index < 0 || index >= size {
+		return nil, errors.Errorf("leaf index %d out of range for tree size %d", index, size)
+	}
+	return n.hashes(inclusionSpans(span{0, size}, index))
+}
+
+// ConsistencyProof returns the proof that the tree of the first size is a
+// prefix of the tree of the second size, from the nodes of ConsistencyNodes.
+func (n Nodes) ConsistencyProof(size1, size2 int64) ([][]byte, error) {
+	if size1 < 0 || size1 > size2 {
+		return nil, errors.Errorf("tree size %d out of range for tree size %d", size1, size2)
+	}
+	if size1 == 0 {
+		return nil, nil
+	}
+	return n.hashes(consistencySpans(span{0, size2}, size1, true))
+}
+
+// Append returns the nodes of the subtrees that are completed by appending
+// the leaf hashes to the tree of the size, from the nodes of RootNodes. The
+// nodes are added, so that the root hash of the new tree can be made from
+// them.
+func (n Nodes) Append(size int64, leaves [][]byte) ([]Node, error) {
+	var (
+		end = size + int64(len(leaves))
+		res = make([]Node, 0, 2*len(leaves))
+	)
+	for k, v := range leaves {
+		res = append(res, Node{NodeID{0, size + int64(k)}, v})
+		n[res[len(res)-1].NodeID] = v
+	}
+
+	for level := 1; int64(1)<<uint(level) <= end; level++ {
+		for index := size >> uint(level); (index+1)<<uint(level) <= end; index++ {
+			left, err := n.node(NodeID{level - 1, index << 1})
+			if err != nil {
+				return nil, err
+			}
+			right, err := n.node(NodeID{level - 1, index<<1 + 1})
+			if err != nil {
+				return nil, err
+			}
+
+			node := Node{NodeID{level, index}, NodeHash(left, right)}
+			n[node.NodeID] = node.Hash
+			res = append(res, node)
+		}
+	}
+	return res, nil
+}
+
+func (n Nodes) hashes(spans []span) ([][]byte, error) {
+	res := make([][]byte, len(spans))
+	for k, v := range spans {
+		hash, err := n.hash(v)
+		if err != nil {
+			return nil, err
+		}
+		res[k] = hash
+	}
+	return res, nil
+}
+
+// hash returns the hash of the subtree of the span, which is either a node or
+// is made up of the nodes of the span.
+func (n Nodes) hash(s span) ([]byte, error) {
+	if id, ok := s.node(); ok {
+		return n.node(id)
+	}
+
+	k := s.begin + split(s.end-s.begin)
+	left, err := n.hash(span{s.begin, k})
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.hash(span{k, s.end})
+	if err != nil {
+		return nil, err
+	}
+	return NodeHash(left, right), nil
+}
+
+func (n Nodes) node(id NodeID) ([]byte, error) {
+	hash, ok := n[id]
+	if !ok {
+		return nil, errors.Errorf("node %d at level %d not found", id.Index, id.Level)
+	}
+	return hash, nil
+}
+
+// node returns the id of the node of the span, if the span is a complete
+// subtree. The spans of the tree always start at a multiple of their size
+// when they are complete.
+func (s span) node() (NodeID, bool) {
+	size := s.end - s.begin
+	if size <= 0 || size&(size-1) != 0 {
+		return NodeID{}, false
+	}
+
+	level := 0
+	for int64(1)<<uint(level) < size {
+		level++
+	}
+	return NodeID{level, s.begin >> uint(level)}, true
+}
+
+// nodes returns the ids of the nodes that the hash of the span is made up of.
+func (s span) nodes() []NodeID {
+	if s.end <= s.begin {
+		return nil
+	}
+	if id, ok := s.node(); ok {
+		return []NodeID{id}
+	}
+
+	k := s.begin + split(s.end-s.begin)
+	return append(span{s.begin, k}.nodes(), span{k, s.end}.nodes()...)
+}
+
+func spanNodes(spans []span) []NodeID {
+	var res []NodeID
+	for _, v := range spans {
+		res = append(res, v.nodes()...)
+	}
+	return res
+}
@@ -0,0 +1,141 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+// appendNodes appends the leaves to the nodes in batches of the size, only
+// handing each append the nodes of RootNodes.
+func appendNodes(t *testing.T, leaves [][]byte, batch int) Nodes {
+	stored := make(Nodes)
+	for size := 0; size < len(leaves); size += batch {
+		end := size + batch
+		if end > len(leaves) {
+			end = len(leaves)
+		}
+
+		nodes, err := only(stored, RootNodes(int64(size)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		added, err := nodes.Append(int64(size), leaves[size:end])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range added {
+			stored[v.NodeID] = v.Hash
+		}
+	}
+	return stored
+}
+
+// only returns the nodes of the ids, so that nothing else is used.
+func only(nodes Nodes, ids []NodeID) (Nodes, error) {
+	res := make(Nodes, len(ids))
+	for _, id := range ids {
+		hash, err := nodes.node(id)
+		if err != nil {
+			return nil, err
+		}
+		res[id] = hash
+	}
+	return res, nil
+}
+
+func TestNodes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("root hash", func(t *testing.T) {
+		for _, batch := range []int{1, 3, 64} {
+			var (
+				leaves = leaves(33)
+				stored = appendNodes(t, leaves, batch)
+			)
+			for size := 0; size <= len(leaves); size++ {
+				nodes, err := only(stored, RootNodes(int64(size)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				root, err := nodes.RootHash(int64(size))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if expected, actual := RootHash(leaves[:size]), root; !bytes.Equal(expected, actual) {
+					t.Errorf("batch %d, size %d: expected: %x, actual: %x", batch, size, expected, actual)
+				}
+			}
+		}
+	})
+
+	t.Run("inclusion proof", func(t *testing.T) {
+		var (
+			leaves = leaves(33)
+			stored = appendNodes(t, leaves, 5)
+		)
+		for size := 1; size <= len(leaves); size++ {
+			for index := 0; index < size; index++ {
+				nodes, err := only(stored, InclusionNodes(int64(index), int64(size)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				proof, err := nodes.InclusionProof(int64(index), int64(size))
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, err := InclusionProof(leaves[:size], int64(index))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !equalHashes(expected, proof) {
+					t.Errorf("index %d, size %d: expected: %x, actual: %x", index, size, expected, proof)
+				}
+			}
+		}
+	})
+
+	t.Run("consistency proof", func(t *testing.T) {
+		var (
+			leaves = leaves(33)
+			stored = appendNodes(t, leaves, 7)
+		)
+		for size := 1; size <= len(leaves); size++ {
+			for first := 0; first <= size; first++ {
+				nodes, err := only(stored, ConsistencyNodes(int64(first), int64(size)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				proof, err := nodes.ConsistencyProof(int64(first), int64(size))
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, err := ConsistencyProof(leaves[:size], int64(first))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !equalHashes(expected, proof) {
+					t.Errorf("first %d, size %d: expected: %x, actual: %x", first, size, expected, proof)
+				}
+			}
+		}
+	})
+
+	t.Run("missing node", func(t *testing.T) {
+		_, err := make(Nodes).InclusionProof(0, 2)
+		if expected, actual := true, err != nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
+
+func equalHashes(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !bytes.Equal(a[k], b[k]) {
+			return false
+		}
+	}
+	return true
+}
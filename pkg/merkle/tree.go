@@ -0,0 +1,235 @@
+// Package merkle implements the Merkle tree used for checkpointing the
+// ledgers, along with the verification of the inclusion and consistency proofs
+// that are served for the checkpoints. The tree follows RFC 6962, so the proofs
+// can be verified offline without any access to the ledgers.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	leafPrefix byte = 0
+	nodePrefix byte = 1
+)
+
+// LeafHash returns the hash of the leaf data.
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// LedgerLeafHash returns the hash of the leaf for a ledger, which is made up
+// of the ledger id and the hash of the ledger.
+func LedgerLeafHash(ledgerID, ledgerHash string) []byte {
+	return LeafHash([]byte(ledgerID + ":" + ledgerHash))
+}
+
+// NodeHash returns the hash of a interior node from the left and right child
+// hashes.
+func NodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// RootHash returns the root hash of the tree made up of the leaf hashes.
+func RootHash(leaves [][]byte) []byte {
+	switch n := len(leaves); n {
+	case 0:
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	default:
+		k := int(split(int64(n)))
+		return NodeHash(RootHash(leaves[:k]), RootHash(leaves[k:]))
+	}
+}
+
+// InclusionProof returns the audit path proving that the leaf at the index is
+// included in the tree made up of the leaf hashes.
+func InclusionProof(leaves [][]byte, index int64) ([][]byte, error) {
+	size := int64(len(leaves))
+	if index < 0 || index >= size {
+		return nil, errors.Errorf("leaf index %d out of range for tree size %d", index, size)
+	}
+	return spanHashes(leaves, inclusionSpans(span{0, size}, index)), nil
+}
+
+// ConsistencyProof returns the proof that the tree made up of the first size
+// leaf hashes is a prefix of the tree made up of all the leaf hashes.
+func ConsistencyProof(leaves [][]byte, size int64) ([][]byte, error) {
+	if size < 0 || size > int64(len(leaves)) {
+		return nil, errors.Errorf("tree size %d out of range for tree size %d", size, len(leaves))
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	return spanHashes(leaves, consistencySpans(span{0, int64(len(leaves))}, size, true)), nil
+}
+
+// span is the range of leaves from begin up to, but not including, end, of a
+// subtree of the tree.
+type span struct {
+	begin, end int64
+}
+
+// inclusionSpans returns the subtrees whose hashes make up the audit path of
+// the leaf at the index, in the order of the proof.
+func inclusionSpans(s span, index int64) []span {
+	if s.end-s.begin <= 1 {
+		return nil
+	}
+
+	k := s.begin + split(s.end-s.begin)
+	if index < k {
+		return append(inclusionSpans(span{s.begin, k}, index), span{k, s.end})
+	}
+	return append(inclusionSpans(span{k, s.end}, index), span{s.begin, k})
+}
+
+// consistencySpans returns the subtrees whose hashes make up the proof that
+// the tree of the size is a prefix of the subtree, in the order of the proof.
+func consistencySpans(s span, size int64, complete bool) []span {
+	if size == s.end {
+		if complete {
+			return nil
+		}
+		return []span{s}
+	}
+
+	k := s.begin + split(s.end-s.begin)
+	if size <= k {
+		return append(consistencySpans(span{s.begin, k}, size, complete), span{k, s.end})
+	}
+	return append(consistencySpans(span{k, s.end}, size, false), span{s.begin, k})
+}
+
+func spanHashes(leaves [][]byte, spans []span) [][]byte {
+	res := make([][]byte, len(spans))
+	for k, v := range spans {
+		res[k] = RootHash(leaves[v.begin:v.end])
+	}
+	return res
+}
+
+// VerifyInclusion verifies that the leaf hash at the index is included in the
+// tree of the size with the root hash.
+func VerifyInclusion(leafHash []byte, index, size int64, proof [][]byte, root []byte) error {
+	if index < 0 || index >= size {
+		return errors.Errorf("leaf index %d out of range for tree size %d", index, size)
+	}
+
+	var (
+		fn, sn = index, size - 1
+		r      = leafHash
+	)
+	for _, p := range proof {
+		if sn == 0 {
+			return errors.New("inclusion proof too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 {
+		return errors.New("inclusion proof too short")
+	}
+	if !bytes.Equal(r, root) {
+		return errors.New("inclusion proof does not match root hash")
+	}
+	return nil
+}
+
+// VerifyConsistency verifies that the tree of the first size with the first
+// root hash is a prefix of the tree of the second size with the second root
+// hash.
+func VerifyConsistency(size1, size2 int64, root1, root2 []byte, proof [][]byte) error {
+	switch {
+	case size1 < 0 || size1 > size2:
+		return errors.Errorf("tree size %d out of range for tree size %d", size1, size2)
+	case size1 == size2:
+		if len(proof) != 0 {
+			return errors.New("consistency proof should be empty")
+		}
+		if !bytes.Equal(root1, root2) {
+			return errors.New("root hashes do not match")
+		}
+		return nil
+	case size1 == 0:
+		if len(proof) != 0 {
+			return errors.New("consistency proof should be empty")
+		}
+		return nil
+	case len(proof) == 0:
+		return errors.New("consistency proof is empty")
+	}
+
+	// If the first tree is complete, then its root is the starting point of
+	// the proof.
+	if size1&(size1-1) == 0 {
+		proof = append([][]byte{root1}, proof...)
+	}
+
+	fn, sn := size1-1, size2-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return errors.New("consistency proof too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 {
+		return errors.New("consistency proof too short")
+	}
+	if !bytes.Equal(fr, root1) {
+		return errors.New("consistency proof does not match first root hash")
+	}
+	if !bytes.Equal(sr, root2) {
+		return errors.New("consistency proof does not match second root hash")
+	}
+	return nil
+}
+
+// split returns the largest power of two smaller than n.
+func split(n int64) int64 {
+	var k int64 = 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
@@ -0,0 +1,166 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"testing/quick"
+)
+
+func leaves(n int) [][]byte {
+	res := make([][]byte, n)
+	for i := range res {
+		res[i] = LeafHash([]byte(fmt.Sprintf("leaf-%d", i)))
+	}
+	return res
+}
+
+func TestRootHash(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		sum := sha256.Sum256(nil)
+		if expected, actual := sum[:], RootHash(nil); !bytes.Equal(expected, actual) {
+			t.Errorf("expected: %x, actual: %x", expected, actual)
+		}
+	})
+
+	t.Run("single", func(t *testing.T) {
+		leaves := leaves(1)
+		if expected, actual := leaves[0], RootHash(leaves); !bytes.Equal(expected, actual) {
+			t.Errorf("expected: %x, actual: %x", expected, actual)
+		}
+	})
+
+	t.Run("unbalanced", func(t *testing.T) {
+		var (
+			leaves   = leaves(3)
+			expected = NodeHash(NodeHash(leaves[0], leaves[1]), leaves[2])
+		)
+		if actual := RootHash(leaves); !bytes.Equal(expected, actual) {
+			t.Errorf("expected: %x, actual: %x", expected, actual)
+		}
+	})
+
+	t.Run("known answer", func(t *testing.T) {
+		// Leaf and node hashes are domain separated, as described by RFC 6962.
+		var (
+			leaf     = LeafHash(nil)
+			expected = "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"
+		)
+		if actual := hex.EncodeToString(leaf); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+}
+
+func TestInclusion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("verify all leaves", func(t *testing.T) {
+		for size := 1; size <= 33; size++ {
+			var (
+				leaves = leaves(size)
+				root   = RootHash(leaves)
+			)
+			for index := range leaves {
+				proof, err := InclusionProof(leaves, int64(index))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := VerifyInclusion(leaves[index], int64(index), int64(size), proof, root); err != nil {
+					t.Errorf("size %d, index %d: %v", size, index, err)
+				}
+			}
+		}
+	})
+
+	t.Run("verify tampered proof", func(t *testing.T) {
+		fn := func(size, index uint8) bool {
+			n := int(size%32) + 2
+			i := int(index) % n
+
+			var (
+				leaves = leaves(n)
+				root   = RootHash(leaves)
+			)
+			proof, err := InclusionProof(leaves, int64(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tampered := LeafHash([]byte("tampered"))
+			if VerifyInclusion(tampered, int64(i), int64(n), proof, root) == nil {
+				return false
+			}
+			if VerifyInclusion(leaves[i], int64((i+1)%n), int64(n), proof, root) == nil {
+				return false
+			}
+			return VerifyInclusion(leaves[i], int64(i), int64(n), proof[:len(proof)-1], root) != nil
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		if _, err := InclusionProof(leaves(2), 2); err == nil {
+			t.Errorf("expected error")
+		}
+		if err := VerifyInclusion(nil, 2, 2, nil, nil); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
+
+func TestConsistency(t *testing.T) {
+	t.Parallel()
+
+	t.Run("verify all sizes", func(t *testing.T) {
+		for size := 1; size <= 33; size++ {
+			var (
+				leaves = leaves(size)
+				root   = RootHash(leaves)
+			)
+			for first := 0; first <= size; first++ {
+				proof, err := ConsistencyProof(leaves, int64(first))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := VerifyConsistency(int64(first), int64(size), RootHash(leaves[:first]), root, proof); err != nil {
+					t.Errorf("first %d, size %d: %v", first, size, err)
+				}
+			}
+		}
+	})
+
+	t.Run("verify tampered proof", func(t *testing.T) {
+		fn := func(size, first uint8) bool {
+			n := int(size%32) + 2
+			m := int(first)%(n-1) + 1
+
+			var (
+				leaves = leaves(n)
+				root   = RootHash(leaves)
+			)
+			proof, err := ConsistencyProof(leaves, int64(m))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// A different history for the first tree.
+			other := append([][]byte{LeafHash([]byte("tampered"))}, leaves[1:m]...)
+			if VerifyConsistency(int64(m), int64(n), RootHash(other), root, proof) == nil {
+				return false
+			}
+			return VerifyConsistency(int64(m), int64(n), RootHash(leaves[:m]), LeafHash(nil), proof) != nil
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -17,6 +17,20 @@ type Gauge interface {
 	// Dec decrements the Gauge by 1. Use Sub to decrement it by arbitrary
 	// values.
 	Dec()
+
+	// Set sets the Gauge to an arbitrary value.
+	Set(float64)
+}
+
+// GaugeVec is a Collector that bundles a set of Gauges that all share the same
+// Desc, but have different values for their variable labels. This is used if
+// you want to count the same thing partitioned by various dimensions. Create
+// instances with NewGaugeVec.
+type GaugeVec interface {
+
+	// WithLabelValues works as GetMetricWithLabelValues, but panics where
+	// GetMetricWithLabelValues would have returned an error.
+	WithLabelValues(...string) prometheus.Gauge
 }
 
 // HistogramVec is a Collector that bundles a set of Histograms that all share the
@@ -45,3 +59,15 @@ type Counter interface {
 	// 0.
 	Add(float64)
 }
+
+// CounterVec is a Collector that bundles a set of Counters that all share the
+// same Desc, but have different values for their variable labels. This is
+// used if you want to count the same thing partitioned by various dimensions
+// (e.g. number of HTTP requests, partitioned by response code and method).
+// Create instances with NewCounterVec.
+type CounterVec interface {
+
+	// WithLabelValues works as GetMetricWithLabelValues, but panics where
+	// GetMetricWithLabelValues would have returned an error.
+	WithLabelValues(...string) prometheus.Counter
+}
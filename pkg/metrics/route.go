@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnmatchedRoute is the route label of the requests that match no route.
+const UnmatchedRoute = "unmatched"
+
+// Route returns the path template of the route of the router that matches the
+// request, to label the request with. Labelling by the template rather than
+// the path bounds the labels by the routes of the router, instead of by the
+// paths that are requested.
+func Route(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if !router.Match(r, &match) || match.Route == nil {
+		return UnmatchedRoute
+	}
+	template, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return UnmatchedRoute
+	}
+	return template
+}
+
+type prefixedHistogramVec struct {
+	prefix string
+	vec    HistogramVec
+}
+
+// NewPrefixedHistogramVec creates a HistogramVec that prefixes the path label
+// with the prefix, for an API that is served under the prefix. The labels are
+// expected to be the method, path and status code, as with the duration of
+// every API. Unmatched routes aren't prefixed.
+func NewPrefixedHistogramVec(prefix string, vec HistogramVec) HistogramVec {
+	return prefixedHistogramVec{
+		prefix: prefix,
+		vec:    vec,
+	}
+}
+
+func (v prefixedHistogramVec) WithLabelValues(values ...string) prometheus.Observer {
+	if len(values) > 1 && strings.HasPrefix(values[1], "/") {
+		labels := append([]string(nil), values...)
+		labels[1] = v.prefix + labels[1]
+		values = labels
+	}
+	return v.vec.WithLabelValues(values...)
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/trussle/snowy/pkg/metrics/mocks"
+)
+
+func TestRoute(t *testing.T) {
+	t.Parallel()
+
+	router := mux.NewRouter().StrictSlash(true)
+	router.Methods("GET").Path("/").HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	router.Methods("GET").Path("/revisions/").HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	for _, tc := range []struct {
+		method, path, route string
+	}{
+		{"GET", "/", "/"},
+		{"GET", "/?resource_id=abc", "/"},
+		{"GET", "/revisions/", "/revisions/"},
+		{"GET", "/3c6a1f6e-d3e5-4d4b-9d0a-6d1bc1a0a7f2", UnmatchedRoute},
+		{"GET", "/revisions/abc/def", UnmatchedRoute},
+	} {
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		if expected, actual := tc.route, Route(router, r); expected != actual {
+			t.Errorf("%s %s expected: %q, actual: %q", tc.method, tc.path, expected, actual)
+		}
+	}
+}
+
+func TestPrefixedHistogramVec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prefixes route", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			vec      = mocks.NewMockHistogramVec(ctrl)
+			observer = mocks.NewMockObserver(ctrl)
+		)
+
+		vec.EXPECT().WithLabelValues("GET", "/ledgers/revisions/", "200").Return(observer).Times(1)
+
+		NewPrefixedHistogramVec("/ledgers", vec).WithLabelValues("GET", "/revisions/", "200")
+	})
+
+	t.Run("unmatched route isn't prefixed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			vec      = mocks.NewMockHistogramVec(ctrl)
+			observer = mocks.NewMockObserver(ctrl)
+		)
+
+		vec.EXPECT().WithLabelValues("GET", UnmatchedRoute, "404").Return(observer).Times(1)
+
+		NewPrefixedHistogramVec("/ledgers", vec).WithLabelValues("GET", UnmatchedRoute, "404")
+	})
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// Access represents the level of access that is required on a resource.
+type Access int
+
+const (
+	// AccessRead allows the ledgers and content of a resource to be read.
+	AccessRead Access = iota
+
+	// AccessWrite allows revisions to be appended to a resource, or the
+	// resource to be forked. It implies AccessRead.
+	AccessWrite
+
+	// AccessOwner allows the access control list of a resource to be
+	// modified. It implies AccessWrite.
+	AccessOwner
+)
+
+const (
+	// ACLGroupPrefix is the prefix of access control list entries that refer
+	// to a group of principals, rather than a single principal.
+	ACLGroupPrefix = "group:"
+
+	// ACLEveryone is the access control list entry that refers to every
+	// principal.
+	ACLEveryone = "*"
+)
+
+// ACL represents the access control list of a resource. Each entry is either
+// the id of a principal, a group prefixed with "group:" or "*" for every
+// principal.
+type ACL struct {
+	ResourceID uuid.UUID
+	Owner      string
+	Readers    []string
+	Writers    []string
+	UpdatedBy  string
+	CreatedOn  time.Time
+}
+
+// Allows checks if the principal, with the groups it belongs to, has the
+// access to the resource.
+func (a ACL) Allows(principalID string, groups []string, access Access) bool {
+	if aclMatches(a.Owner, principalID, groups) {
+		return true
+	}
+
+	switch access {
+	case AccessRead:
+		return aclContains(a.Readers, principalID, groups) ||
+			aclContains(a.Writers, principalID, groups)
+	case AccessWrite:
+		return aclContains(a.Writers, principalID, groups)
+	}
+	return false
+}
+
+// MarshalJSON converts a ACL into a serialisable json format
+func (a ACL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ResourceID string   `json:"resource_id"`
+		Owner      string   `json:"owner"`
+		Readers    []string `json:"readers"`
+		Writers    []string `json:"writers"`
+		UpdatedBy  string   `json:"updated_by"`
+		CreatedOn  string   `json:"created_on"`
+	}{
+		ResourceID: a.ResourceID.String(),
+		Owner:      a.Owner,
+		Readers:    append(make([]string, 0), a.Readers...),
+		Writers:    append(make([]string, 0), a.Writers...),
+		UpdatedBy:  a.UpdatedBy,
+		CreatedOn:  a.CreatedOn.Format(time.RFC3339),
+	})
+}
+
+// UnmarshalJSON converts a serialisable json format into a ACL
+func (a *ACL) UnmarshalJSON(b []byte) error {
+	var res struct {
+		ResourceID uuid.UUID `json:"resource_id"`
+		Owner      string    `json:"owner"`
+		Readers    []string  `json:"readers"`
+		Writers    []string  `json:"writers"`
+		UpdatedBy  string    `json:"updated_by"`
+		CreatedOn  time.Time `json:"created_on"`
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return err
+	}
+
+	a.ResourceID = res.ResourceID
+	a.Owner = res.Owner
+	a.Readers = res.Readers
+	a.Writers = res.Writers
+	a.UpdatedBy = res.UpdatedBy
+	a.CreatedOn = res.CreatedOn
+	return nil
+}
+
+func aclContains(entries []string, principalID string, groups []string) bool {
+	for _, entry := range entries {
+		if aclMatches(entry, principalID, groups) {
+			return true
+		}
+	}
+	return false
+}
+
+func aclMatches(entry, principalID string, groups []string) bool {
+	switch {
+	case entry == ACLEveryone:
+		return true
+	case strings.HasPrefix(entry, ACLGroupPrefix):
+		group := strings.TrimPrefix(entry, ACLGroupPrefix)
+		for _, v := range groups {
+			if v == group {
+				return true
+			}
+		}
+		return false
+	}
+	return entry != "" && entry == principalID
+}
+
+// ACLInput takes values from json and places them into a unverified model.
+type ACLInput struct {
+	Owner   string   `json:"owner"`
+	Readers []string `json:"readers"`
+	Writers []string `json:"writers"`
+}
+
+// ValidateACLInput validates input of the ACLInput
+func ValidateACLInput(input ACLInput) error {
+	if len(strings.TrimSpace(input.Owner)) == 0 {
+		return errors.New("input.owner is empty")
+	}
+
+	for name, entries := range map[string][]string{
+		"readers": input.Readers,
+		"writers": input.Writers,
+	} {
+		for k, entry := range entries {
+			if len(strings.TrimSpace(entry)) == 0 || entry == ACLGroupPrefix {
+				return fmt.Errorf("input.%s[%d] is empty", name, k)
+			}
+		}
+	}
+
+	return nil
+}
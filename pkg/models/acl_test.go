@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/trussle/uuid"
+)
+
+func TestACL(t *testing.T) {
+	t.Parallel()
+
+	acl := ACL{
+		ResourceID: uuid.MustNew(),
+		Owner:      "owner",
+		Readers:    []string{"reader", "group:auditors"},
+		Writers:    []string{"writer"},
+	}
+
+	for _, tc := range []struct {
+		name        string
+		principalID string
+		groups      []string
+		access      Access
+		expected    bool
+	}{
+		{"owner can modify", "owner", nil, AccessOwner, true},
+		{"owner can write", "owner", nil, AccessWrite, true},
+		{"reader can read", "reader", nil, AccessRead, true},
+		{"reader can not write", "reader", nil, AccessWrite, false},
+		{"writer can read", "writer", nil, AccessRead, true},
+		{"writer can write", "writer", nil, AccessWrite, true},
+		{"writer can not modify", "writer", nil, AccessOwner, false},
+		{"group can read", "other", []string{"auditors"}, AccessRead, true},
+		{"group can not write", "other", []string{"auditors"}, AccessWrite, false},
+		{"other can not read", "other", []string{"others"}, AccessRead, false},
+		{"empty principal can not read", "", nil, AccessRead, false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if expected, actual := tc.expected, acl.Allows(tc.principalID, tc.groups, tc.access); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		})
+	}
+
+	t.Run("everyone can read", func(t *testing.T) {
+		acl := ACL{Owner: "owner", Readers: []string{ACLEveryone}}
+		if expected, actual := true, acl.Allows("other", nil, AccessRead); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		b, err := json.Marshal(ACL{ResourceID: acl.ResourceID, Owner: "owner"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res map[string]interface{}
+		if err := json.Unmarshal(b, &res); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(res["readers"].([]interface{})); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := acl.ResourceID.String(), res["resource_id"]; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("json round trip", func(t *testing.T) {
+		b, err := json.Marshal(acl)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res ACL
+		if err := json.Unmarshal(b, &res); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := acl.ResourceID, res.ResourceID; !expected.Equals(actual) {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+		if expected, actual := acl.Readers, res.Readers; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestValidateACLInput(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name  string
+		input ACLInput
+		valid bool
+	}{
+		{"valid", ACLInput{Owner: "owner", Readers: []string{"group:a"}, Writers: []string{"b"}}, true},
+		{"no owner", ACLInput{Readers: []string{"a"}}, false},
+		{"empty reader", ACLInput{Owner: "owner", Readers: []string{" "}}, false},
+		{"empty group", ACLInput{Owner: "owner", Writers: []string{"group:"}}, false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateACLInput(tc.input)
+			if expected, actual := tc.valid, err == nil; expected != actual {
+				t.Errorf("expected: %t, actual: %t, err: %v", expected, actual, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// AuthorKey represents a public key that has been registered for an author,
+// which is used to verify the signatures of the ledgers by the author.
+type AuthorKey struct {
+	AuthorID  string
+	KeyID     string
+	PublicKey []byte
+	CreatedOn time.Time
+}
+
+// MarshalJSON converts a AuthorKey into a serialisable json format
+func (k AuthorKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		AuthorID  string `json:"author_id"`
+		KeyID     string `json:"key_id"`
+		Algorithm string `json:"algorithm"`
+		PublicKey []byte `json:"public_key"`
+		CreatedOn string `json:"created_on"`
+	}{
+		AuthorID:  k.AuthorID,
+		KeyID:     k.KeyID,
+		Algorithm: "ed25519",
+		PublicKey: k.PublicKey,
+		CreatedOn: k.CreatedOn.Format(time.RFC3339),
+	})
+}
+
+// AuthorKeyInput takes values from json and places them into a unverified
+// model.
+type AuthorKeyInput struct {
+	AuthorID  string `json:"author_id"`
+	PublicKey []byte `json:"public_key"`
+}
+
+// ValidateAuthorKeyInput validates input of the AuthorKeyInput
+func ValidateAuthorKeyInput(input AuthorKeyInput) error {
+	if len(strings.TrimSpace(input.AuthorID)) == 0 {
+		return errors.New("input.author_id is empty")
+	}
+
+	if len(input.PublicKey) != ed25519.PublicKeySize {
+		return errors.New("input.public_key is not a Ed25519 public key")
+	}
+
+	return nil
+}
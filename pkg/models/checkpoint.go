@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// Checkpoint represents a signed tree head, which covers the first TreeSize
+// ledgers that were appended to the checkpoint tree.
+type Checkpoint struct {
+	TreeSize  int64
+	RootHash  []byte
+	KeyID     string
+	Signature []byte
+	CreatedOn time.Time
+}
+
+// CheckpointKey represents the public key that checkpoints are signed with.
+type CheckpointKey struct {
+	KeyID     string
+	PublicKey []byte
+}
+
+// LedgerProof represents the proof that a ledger is included in a checkpoint.
+// If FromCheckpoint is set, then Consistency proves that the earlier
+// checkpoint is consistent with the checkpoint.
+type LedgerProof struct {
+	LedgerID   uuid.UUID
+	LedgerHash string
+	LeafIndex  int64
+	Checkpoint Checkpoint
+	Inclusion  [][]byte
+
+	FromCheckpoint *Checkpoint
+	Consistency    [][]byte
+}
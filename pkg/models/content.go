@@ -12,10 +12,11 @@ import (
 // Content is abstraction over a potential underlying file, which has additional
 // meta data information that could be useful.
 type Content struct {
-	address     string
-	size        int64
-	contentType string
-	reader      io.ReadCloser
+	address         string
+	size            int64
+	contentType     string
+	contentEncoding string
+	reader          io.ReadCloser
 }
 
 // Address returns the content addressable value
@@ -33,6 +34,12 @@ func (c Content) ContentType() string {
 	return c.contentType
 }
 
+// ContentEncoding returns the encoding of the content body. If the body isn't
+// encoded, then it returns an empty string.
+func (c Content) ContentEncoding() string {
+	return c.contentEncoding
+}
+
 // Bytes returns the body of the content as a slice of bytes.
 func (c Content) Bytes() ([]byte, error) {
 	if c.reader == nil {
@@ -129,6 +136,14 @@ func WithContentType(contentType string) ContentOption {
 	}
 }
 
+// WithContentEncoding adds a ContentEncoding to the content
+func WithContentEncoding(contentEncoding string) ContentOption {
+	return func(content *Content) error {
+		content.contentEncoding = contentEncoding
+		return nil
+	}
+}
+
 // WithBytes adds a body to the content
 func WithBytes(b []byte) ContentOption {
 	return func(content *Content) error {
@@ -2,7 +2,6 @@ package models
 
 import (
 	"encoding/json"
-	"errors"
 	"strings"
 	"time"
 
@@ -14,6 +13,7 @@ import (
 type Ledger struct {
 	id                   uuid.UUID
 	parentID             uuid.UUID
+	tenantID             string
 	name                 string
 	resourceID           uuid.UUID
 	resourceAddress      string
@@ -22,6 +22,8 @@ type Ledger struct {
 	authorID             string
 	tags                 []string
 	createdOn, deletedOn time.Time
+	signature            []byte
+	signatureKeyID       string
 }
 
 // ID returns the id of the ledger resource, this is the unique identifier
@@ -35,6 +37,12 @@ func (d Ledger) ParentID() uuid.UUID {
 	return d.parentID
 }
 
+// TenantID returns the id of the tenant the ledger belongs to. The tenant
+// isn't part of the encoded ledger, as it's never shared between tenants.
+func (d Ledger) TenantID() string {
+	return d.tenantID
+}
+
 // ResourceID returns the id associated with the ledger resource.
 func (d Ledger) ResourceID() uuid.UUID {
 	return d.resourceID
@@ -80,6 +88,18 @@ func (d Ledger) DeletedOn() time.Time {
 	return d.deletedOn
 }
 
+// Signature returns the signature of the author over the canonical form of
+// the ledger, see SigningBytes. Ledgers aren't required to be signed, in which
+// case the signature is empty.
+func (d Ledger) Signature() []byte {
+	return d.signature
+}
+
+// SignatureKeyID returns the id of the author key that signed the ledger.
+func (d Ledger) SignatureKeyID() string {
+	return d.signatureKeyID
+}
+
 // MarshalJSON converts a UUID into a serialisable json format
 func (d Ledger) MarshalJSON() ([]byte, error) {
 	tags := d.tags
@@ -87,7 +107,14 @@ func (d Ledger) MarshalJSON() ([]byte, error) {
 		tags = make([]string, 0)
 	}
 
+	var id string
+	if !d.id.Zero() {
+		id = d.id.String()
+	}
+
 	return json.Marshal(struct {
+		ID                  string    `json:"id,omitempty"`
+		ParentID            uuid.UUID `json:"parent_id"`
 		Name                string    `json:"name"`
 		ResourceID          uuid.UUID `json:"resource_id"`
 		ResourceAddress     string    `json:"resource_address"`
@@ -97,7 +124,11 @@ func (d Ledger) MarshalJSON() ([]byte, error) {
 		Tags                []string  `json:"tags"`
 		CreatedOn           string    `json:"created_on"`
 		DeletedOn           string    `json:"deleted_on"`
+		Signature           []byte    `json:"signature,omitempty"`
+		SignatureKeyID      string    `json:"signature_key_id,omitempty"`
 	}{
+		ID:                  id,
+		ParentID:            d.parentID,
 		Name:                d.name,
 		ResourceID:          d.resourceID,
 		ResourceAddress:     d.resourceAddress,
@@ -107,12 +138,16 @@ func (d Ledger) MarshalJSON() ([]byte, error) {
 		Tags:                tags,
 		CreatedOn:           d.createdOn.Format(time.RFC3339),
 		DeletedOn:           d.deletedOn.Format(time.RFC3339),
+		Signature:           d.signature,
+		SignatureKeyID:      d.signatureKeyID,
 	})
 }
 
 // UnmarshalJSON unserialises the json format and converts it into a Ledger
 func (d *Ledger) UnmarshalJSON(b []byte) error {
 	var res struct {
+		ID                  string    `json:"id"`
+		ParentID            uuid.UUID `json:"parent_id"`
 		Name                string    `json:"name"`
 		ResourceID          uuid.UUID `json:"resource_id"`
 		ResourceAddress     string    `json:"resource_address"`
@@ -122,6 +157,8 @@ func (d *Ledger) UnmarshalJSON(b []byte) error {
 		Tags                []string  `json:"tags"`
 		CreatedOn           string    `json:"created_on"`
 		DeletedOn           string    `json:"deleted_on"`
+		Signature           []byte    `json:"signature"`
+		SignatureKeyID      string    `json:"signature_key_id"`
 	}
 	if err := json.Unmarshal(b, &res); err != nil {
 		return err
@@ -129,6 +166,13 @@ func (d *Ledger) UnmarshalJSON(b []byte) error {
 
 	var err error
 
+	if res.ID != "" {
+		if d.id, err = uuid.Parse(res.ID); err != nil {
+			return err
+		}
+	}
+
+	d.parentID = res.ParentID
 	d.name = res.Name
 	d.resourceID = res.ResourceID
 	d.resourceAddress = res.ResourceAddress
@@ -136,6 +180,8 @@ func (d *Ledger) UnmarshalJSON(b []byte) error {
 	d.resourceContentType = res.ResourceContentType
 	d.authorID = res.AuthorID
 	d.tags = res.Tags
+	d.signature = res.Signature
+	d.signatureKeyID = res.SignatureKeyID
 
 	d.createdOn, err = time.Parse(time.RFC3339, res.CreatedOn)
 	if err != nil {
@@ -231,6 +277,14 @@ func WithResourceContentType(resourceContentType string) DocOption {
 	}
 }
 
+// WithTenantID adds a TenantID to the ledger
+func WithTenantID(tenantID string) DocOption {
+	return func(doc *Ledger) error {
+		doc.tenantID = tenantID
+		return nil
+	}
+}
+
 // WithAuthorID adds a AuthorID to the ledger
 func WithAuthorID(authorID string) DocOption {
 	return func(doc *Ledger) error {
@@ -263,6 +317,16 @@ func WithDeletedOn(deletedOn time.Time) DocOption {
 	}
 }
 
+// WithSignature adds a Signature to the ledger, along with the id of the
+// author key that made it.
+func WithSignature(keyID string, signature []byte) DocOption {
+	return func(doc *Ledger) error {
+		doc.signatureKeyID = keyID
+		doc.signature = signature
+		return nil
+	}
+}
+
 // LedgerInput takes values from json and places them into a unverified model.
 type LedgerInput struct {
 	Name                string   `json:"name"`
@@ -271,17 +335,46 @@ type LedgerInput struct {
 	ResourceContentType string   `json:"resource_content_type"`
 	AuthorID            string   `json:"author_id"`
 	Tags                []string `json:"tags"`
+
+	// CreatedOn, Signature and SignatureKeyID are only required when the
+	// ledger is signed by the author, as the signature covers the created_on
+	// time.
+	CreatedOn      string `json:"created_on"`
+	Signature      []byte `json:"signature"`
+	SignatureKeyID string `json:"signature_key_id"`
 }
 
-// ValidateLedgerInput validates input of the LedgerInput
+// CreatedOnTime returns the created on time of a signed input, otherwise now
+// is returned, as the time is set by the server.
+func (input LedgerInput) CreatedOnTime(now time.Time) time.Time {
+	if len(input.Signature) > 0 {
+		if createdOn, err := time.Parse(time.RFC3339, input.CreatedOn); err == nil {
+			return createdOn
+		}
+	}
+	return now
+}
+
+// ValidateLedgerInput validates input of the LedgerInput, returning a
+// ValidationError of every invalid field.
 func ValidateLedgerInput(input LedgerInput) error {
+	var res ValidationError
 	if len(strings.TrimSpace(input.Name)) == 0 {
-		return errors.New("input.name is empty")
+		res.Add("name", "is empty")
 	}
 
 	if len(strings.TrimSpace(input.AuthorID)) == 0 {
-		return errors.New("input.author_id is empty")
+		res.Add("author_id", "is empty")
 	}
 
-	return nil
+	if len(input.Signature) > 0 {
+		if len(strings.TrimSpace(input.SignatureKeyID)) == 0 {
+			res.Add("signature_key_id", "is empty")
+		}
+		if _, err := time.Parse(time.RFC3339, input.CreatedOn); err != nil {
+			res.Add("created_on", "is not a valid time")
+		}
+	}
+
+	return res.Err()
 }
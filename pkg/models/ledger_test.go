@@ -107,6 +107,36 @@ func TestLedger(t *testing.T) {
 		}
 	})
 
+	t.Run("json marshal with ids and signature", func(t *testing.T) {
+		fn := func(id, parentID uuid.UUID, keyID string, signature []byte) bool {
+			input := Ledger{
+				id:             id,
+				parentID:       parentID,
+				signature:      signature,
+				signatureKeyID: keyID,
+			}
+
+			bytes, err := json.Marshal(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var output Ledger
+			if err = json.Unmarshal(bytes, &output); err != nil {
+				t.Fatal(err)
+			}
+
+			return output.ID().Equals(id) &&
+				output.ParentID().Equals(parentID) &&
+				string(output.Signature()) == string(signature) &&
+				output.SignatureKeyID() == keyID
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
 	t.Run("json marshal with empty tags", func(t *testing.T) {
 		fn := func(id, parentID uuid.UUID,
 			name string,
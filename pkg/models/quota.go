@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Quota represents the storage limits of an author, or of a whole tenant when
+// the author is empty, along with the current usage. A limit of zero means
+// that there is no limit.
+type Quota struct {
+	AuthorID           string
+	MaxBytes           int64
+	MaxResources       int64
+	MaxRevisionsPerDay int64
+	Usage              QuotaUsage
+}
+
+// QuotaUsage represents how much of a quota has been used. The revisions only
+// count the revisions created since the start of the current day (UTC).
+type QuotaUsage struct {
+	Bytes          int64 `json:"bytes"`
+	Resources      int64 `json:"resources"`
+	RevisionsToday int64 `json:"revisions_today"`
+}
+
+// Limited returns if the quota has any limits set.
+func (q Quota) Limited() bool {
+	return q.MaxBytes > 0 || q.MaxResources > 0 || q.MaxRevisionsPerDay > 0
+}
+
+// MarshalJSON converts a Quota into a serialisable json format
+func (q Quota) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		AuthorID           string     `json:"author_id"`
+		MaxBytes           int64      `json:"max_bytes"`
+		MaxResources       int64      `json:"max_resources"`
+		MaxRevisionsPerDay int64      `json:"max_revisions_per_day"`
+		Usage              QuotaUsage `json:"usage"`
+	}{
+		AuthorID:           q.AuthorID,
+		MaxBytes:           q.MaxBytes,
+		MaxResources:       q.MaxResources,
+		MaxRevisionsPerDay: q.MaxRevisionsPerDay,
+		Usage:              q.Usage,
+	})
+}
+
+// QuotaInput takes values from json and places them into a unverified model.
+type QuotaInput struct {
+	MaxBytes           int64 `json:"max_bytes"`
+	MaxResources       int64 `json:"max_resources"`
+	MaxRevisionsPerDay int64 `json:"max_revisions_per_day"`
+}
+
+// ValidateQuotaInput validates input of the QuotaInput
+func ValidateQuotaInput(input QuotaInput) error {
+	if input.MaxBytes < 0 {
+		return errors.New("input.max_bytes is negative")
+	}
+	if input.MaxResources < 0 {
+		return errors.New("input.max_resources is negative")
+	}
+	if input.MaxRevisionsPerDay < 0 {
+		return errors.New("input.max_revisions_per_day is negative")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateQuotaInput(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		input    QuotaInput
+		expected bool
+	}{
+		{"no limits", QuotaInput{}, true},
+		{"limits", QuotaInput{MaxBytes: 1, MaxResources: 2, MaxRevisionsPerDay: 3}, true},
+		{"negative bytes", QuotaInput{MaxBytes: -1}, false},
+		{"negative resources", QuotaInput{MaxResources: -1}, false},
+		{"negative revisions", QuotaInput{MaxRevisionsPerDay: -1}, false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if expected, actual := tc.expected, ValidateQuotaInput(tc.input) == nil; expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestQuotaMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(Quota{
+		AuthorID: "author",
+		MaxBytes: 100,
+		Usage: QuotaUsage{
+			Bytes:          10,
+			RevisionsToday: 2,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"author_id":"author","max_bytes":100,"max_resources":0,"max_revisions_per_day":0,"usage":{"bytes":10,"resources":0,"revisions_today":2}}`
+	if actual := string(b); expected != actual {
+		t.Errorf("expected: %s, actual: %s", expected, actual)
+	}
+}
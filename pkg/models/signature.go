@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+const signingVersion = "snowy-ledger-signature-v1"
+
+// SigningBytes returns the canonical form of the ledger that an author signs.
+// Each field is prefixed with its length as a big endian uint64, in the order
+// of: version, name, resource address, number of tags, the sorted tags, parent
+// id and created on. The created on time is formatted as RFC3339 in UTC, so
+// that it matches the precision that the ledger is read back with.
+func (d Ledger) SigningBytes() []byte {
+	tags := make([]string, len(d.tags))
+	copy(tags, d.tags)
+	sort.Strings(tags)
+
+	var buf []byte
+	buf = appendSigningField(buf, signingVersion)
+	buf = appendSigningField(buf, d.name)
+	buf = appendSigningField(buf, d.resourceAddress)
+	buf = appendSigningField(buf, strconv.Itoa(len(tags)))
+	for _, tag := range tags {
+		buf = appendSigningField(buf, tag)
+	}
+	buf = appendSigningField(buf, d.parentID.String())
+	buf = appendSigningField(buf, d.createdOn.UTC().Format(time.RFC3339))
+	return buf
+}
+
+// VerifySignature verifies the signature of the ledger against the public key
+// of the author. It returns an error if the ledger isn't signed.
+func (d Ledger) VerifySignature(publicKey ed25519.PublicKey) error {
+	if len(d.signature) == 0 {
+		return errors.New("ledger is not signed")
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("invalid public key size")
+	}
+	if !ed25519.Verify(publicKey, d.SigningBytes(), d.signature) {
+		return errors.New("invalid ledger signature")
+	}
+	return nil
+}
+
+func appendSigningField(buf []byte, field string) []byte {
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(field)))
+	return append(append(buf, size[:]...), field...)
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"crypto/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/trussle/harness/generators"
+	"github.com/trussle/uuid"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestLedgerSignature(t *testing.T) {
+	t.Parallel()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(t *testing.T, opts ...DocOption) Ledger {
+		doc, err := BuildLedger(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = WithSignature("key", ed25519.Sign(key, doc.SigningBytes()))(&doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+
+	t.Run("sign then verify", func(t *testing.T) {
+		fn := func(parentID uuid.UUID, name, resourceAddress string, tags generators.ASCIISlice) bool {
+			doc := sign(t,
+				WithParentID(parentID),
+				WithName(name),
+				WithResourceAddress(resourceAddress),
+				WithTags(tags.Slice()),
+				WithCreatedOn(time.Now()),
+			)
+			return doc.VerifySignature(pub) == nil
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("verify with tags in a different order", func(t *testing.T) {
+		doc := sign(t,
+			WithName("name"),
+			WithTags([]string{"a", "b"}),
+		)
+		if err := WithTags([]string{"b", "a"})(&doc); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := doc.VerifySignature(pub); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("verify with created_on read back", func(t *testing.T) {
+		now := time.Now()
+		doc := sign(t,
+			WithName("name"),
+			WithCreatedOn(now),
+		)
+		if err := WithCreatedOn(now.Truncate(time.Second).In(time.FixedZone("other", 3600)))(&doc); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := doc.VerifySignature(pub); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("verify tampered", func(t *testing.T) {
+		for name, opt := range map[string]DocOption{
+			"name":             WithName("other"),
+			"resource_address": WithResourceAddress("other"),
+			"tags":             WithTags([]string{"other"}),
+			"parent_id":        WithParentID(uuid.MustNew()),
+			"created_on":       WithCreatedOn(time.Now().Add(time.Hour)),
+		} {
+			doc := sign(t,
+				WithName("name"),
+				WithResourceAddress("address"),
+				WithTags([]string{"a"}),
+				WithCreatedOn(time.Now()),
+			)
+			if err := opt(&doc); err != nil {
+				t.Fatal(err)
+			}
+
+			if expected, actual := false, doc.VerifySignature(pub) == nil; expected != actual {
+				t.Errorf("%s expected: %t, actual: %t", name, expected, actual)
+			}
+		}
+	})
+
+	t.Run("verify unsigned", func(t *testing.T) {
+		doc, err := BuildLedger(WithName("name"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := false, doc.VerifySignature(pub) == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
+
+func TestValidateLedgerInput(t *testing.T) {
+	t.Parallel()
+
+	t.Run("signed", func(t *testing.T) {
+		err := ValidateLedgerInput(LedgerInput{
+			Name:           "name",
+			AuthorID:       "author",
+			CreatedOn:      time.Now().Format(time.RFC3339),
+			Signature:      []byte("signature"),
+			SignatureKeyID: "key",
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("signed without key id", func(t *testing.T) {
+		err := ValidateLedgerInput(LedgerInput{
+			Name:      "name",
+			AuthorID:  "author",
+			CreatedOn: time.Now().Format(time.RFC3339),
+			Signature: []byte("signature"),
+		})
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("signed without created_on", func(t *testing.T) {
+		err := ValidateLedgerInput(LedgerInput{
+			Name:           "name",
+			AuthorID:       "author",
+			Signature:      []byte("signature"),
+			SignatureKeyID: "key",
+		})
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		err := ValidateLedgerInput(LedgerInput{
+			Signature: []byte("signature"),
+		})
+
+		invalid, ok := err.(interface {
+			InvalidFields() map[string]string
+		})
+		if !ok {
+			t.Fatalf("expected: invalid fields, actual: %v", err)
+		}
+		if expected, actual := map[string]string{
+			"name":             "is empty",
+			"author_id":        "is empty",
+			"signature_key_id": "is empty",
+			"created_on":       "is not a valid time",
+		}, invalid.InvalidFields(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "input.name is empty, input.author_id is empty, input.signature_key_id is empty, input.created_on is not a valid time", err.Error(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("created_on of unsigned input is ignored", func(t *testing.T) {
+		var (
+			now   = time.Now()
+			input = LedgerInput{
+				CreatedOn: now.Add(-time.Hour).Format(time.RFC3339),
+			}
+		)
+		if expected, actual := now, input.CreatedOnTime(now); !expected.Equal(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
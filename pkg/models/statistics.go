@@ -1,6 +1,44 @@
 package models
 
+import "time"
+
 // LedgerStatistics represents a series of statistics about the ledgers
 type LedgerStatistics struct {
 	TotalLedgers int
+
+	// TotalResources is the number of distinct resources, TotalBytes is the
+	// size of the distinct content, as the same content is only stored once.
+	TotalResources int
+	TotalBytes     int64
+	ContentTypes   map[string]int64
+
+	// Revisions is the distribution of the revisions per resource.
+	Revisions []RevisionBucket
+
+	// Authors and Tags are the ones with the most ledgers, most first.
+	Authors []LedgerCount
+	Tags    []LedgerCount
+
+	// Inserts are the ledgers inserted with in windows of time, up to now.
+	Inserts []InsertWindow
+}
+
+// RevisionBucket is the number of resources that have between Min and Max
+// revisions, inclusive. A Max of zero has no upper bound.
+type RevisionBucket struct {
+	Min, Max  int
+	Resources int
+}
+
+// LedgerCount is the number of ledgers of an author or a tag.
+type LedgerCount struct {
+	Name    string
+	Ledgers int
+}
+
+// InsertWindow is the number of ledgers inserted with in the window of time,
+// up to now.
+type InsertWindow struct {
+	Window  time.Duration
+	Ledgers int
 }
@@ -0,0 +1,17 @@
+package models
+
+import "regexp"
+
+const (
+	// MaxTenantIDLength is the longest a tenant id can be.
+	MaxTenantIDLength = 64
+)
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidTenantID checks to see if the tenant id is valid. Tenant ids are used
+// as part of paths and keys, so they're restricted to lowercase letters,
+// digits, hyphens and underscores.
+func ValidTenantID(tenantID string) bool {
+	return len(tenantID) <= MaxTenantIDLength && tenantIDPattern.MatchString(tenantID)
+}
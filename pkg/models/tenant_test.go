@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidTenantID(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		tenantID string
+		expected bool
+	}{
+		{"simple", "acme", true},
+		{"digits", "0123", true},
+		{"hyphens and underscores", "acme-corp_eu", true},
+		{"max length", strings.Repeat("a", MaxTenantIDLength), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", MaxTenantIDLength+1), false},
+		{"uppercase", "Acme", false},
+		{"leading hyphen", "-acme", false},
+		{"path", "../acme", false},
+		{"colon", "acme:corp", false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if expected, actual := tc.expected, ValidTenantID(tc.tenantID); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		})
+	}
+}
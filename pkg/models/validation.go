@@ -0,0 +1,42 @@
+package models
+
+import "strings"
+
+// ValidationError is the error of an input that isn't valid, holding every
+// field that's invalid along with the reason why.
+type ValidationError struct {
+	fields  []string
+	reasons []string
+}
+
+// Add adds an invalid field of the input.
+func (e *ValidationError) Add(field, reason string) {
+	e.fields = append(e.fields, field)
+	e.reasons = append(e.reasons, reason)
+}
+
+// Err returns the ValidationError if any field is invalid, otherwise nil.
+func (e *ValidationError) Err() error {
+	if len(e.fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// InvalidFields returns the reason of every invalid field, keyed by the name
+// of the field in the input.
+func (e *ValidationError) InvalidFields() map[string]string {
+	res := make(map[string]string, len(e.fields))
+	for k, field := range e.fields {
+		res[field] = e.reasons[k]
+	}
+	return res
+}
+
+func (e *ValidationError) Error() string {
+	res := make([]string, len(e.fields))
+	for k, field := range e.fields {
+		res[k] = "input." + field + " " + e.reasons[k]
+	}
+	return strings.Join(res, ", ")
+}
@@ -0,0 +1,16 @@
+package models
+
+import "github.com/trussle/uuid"
+
+// LedgerVerification represents the result of verifying the hash chain of a
+// ledger, from the root revision through to the head revision.
+type LedgerVerification struct {
+	ResourceID uuid.UUID
+	Revisions  int
+	Valid      bool
+
+	// BrokenID is the id of the first revision where the chain is broken and
+	// Reason describes why. Both are empty if the chain is valid.
+	BrokenID uuid.UUID
+	Reason   string
+}
@@ -0,0 +1,262 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// These are the events of a ledger that a webhook can subscribe to.
+const (
+	WebhookEventInsert = "insert"
+	WebhookEventAppend = "append"
+	WebhookEventFork   = "fork"
+	WebhookEventDelete = "delete"
+)
+
+// These are the statuses of a webhook delivery.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+)
+
+// Webhook represents a subscription to the changes of the ledgers with in a
+// tenant. The ledgers can be filtered by resource id, tag and author, where
+// an empty filter matches every ledger. If there are no events, then every
+// event is delivered. The owner is the principal that created the webhook,
+// which is the only principal that can see or delete it.
+type Webhook struct {
+	ID         uuid.UUID
+	URL        string
+	Secret     string
+	ResourceID uuid.UUID
+	Tag        string
+	AuthorID   string
+	Events     []string
+	Owner      string
+	CreatedOn  time.Time
+}
+
+// Matches checks if the event of the ledger should be delivered to the
+// webhook.
+func (w Webhook) Matches(event string, doc Ledger) bool {
+	if !w.ResourceID.Zero() && !w.ResourceID.Equals(doc.ResourceID()) {
+		return false
+	}
+	if w.AuthorID != "" && w.AuthorID != doc.AuthorID() {
+		return false
+	}
+	if w.Tag != "" && !containsString(doc.Tags(), w.Tag) {
+		return false
+	}
+	return len(w.Events) == 0 || containsString(w.Events, event)
+}
+
+// MarshalJSON converts a Webhook into a serialisable json format. The secret
+// is never serialised, so that it can't be read back once set.
+func (w Webhook) MarshalJSON() ([]byte, error) {
+	var resourceID string
+	if !w.ResourceID.Zero() {
+		resourceID = w.ResourceID.String()
+	}
+	return json.Marshal(struct {
+		ID         string   `json:"id"`
+		URL        string   `json:"url"`
+		ResourceID string   `json:"resource_id"`
+		Tag        string   `json:"tag"`
+		AuthorID   string   `json:"author_id"`
+		Events     []string `json:"events"`
+		Owner      string   `json:"owner"`
+		CreatedOn  string   `json:"created_on"`
+	}{
+		ID:         w.ID.String(),
+		URL:        w.URL,
+		ResourceID: resourceID,
+		Tag:        w.Tag,
+		AuthorID:   w.AuthorID,
+		Events:     append(make([]string, 0), w.Events...),
+		Owner:      w.Owner,
+		CreatedOn:  w.CreatedOn.Format(time.RFC3339),
+	})
+}
+
+// WebhookDelivery represents an event that is to be delivered to a webhook.
+// The URL and Secret are those of the webhook, which are only populated for
+// pending deliveries that are due.
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	Event         string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptOn time.Time
+	CreatedOn     time.Time
+	DeliveredOn   time.Time
+	URL           string
+	Secret        string
+}
+
+// MarshalJSON converts a WebhookDelivery into a serialisable json format
+func (d WebhookDelivery) MarshalJSON() ([]byte, error) {
+	var deliveredOn string
+	if !d.DeliveredOn.IsZero() {
+		deliveredOn = d.DeliveredOn.Format(time.RFC3339)
+	}
+	return json.Marshal(struct {
+		ID            string          `json:"id"`
+		WebhookID     string          `json:"webhook_id"`
+		Event         string          `json:"event"`
+		Payload       json.RawMessage `json:"payload"`
+		Status        string          `json:"status"`
+		Attempts      int             `json:"attempts"`
+		LastError     string          `json:"last_error"`
+		NextAttemptOn string          `json:"next_attempt_on"`
+		CreatedOn     string          `json:"created_on"`
+		DeliveredOn   string          `json:"delivered_on"`
+	}{
+		ID:            d.ID.String(),
+		WebhookID:     d.WebhookID.String(),
+		Event:         d.Event,
+		Payload:       json.RawMessage(d.Payload),
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		NextAttemptOn: d.NextAttemptOn.Format(time.RFC3339),
+		CreatedOn:     d.CreatedOn.Format(time.RFC3339),
+		DeliveredOn:   deliveredOn,
+	})
+}
+
+// WebhookAttempt represents a single attempt at delivering an event to a
+// webhook. StatusCode is zero if no response was received.
+type WebhookAttempt struct {
+	DeliveryID  uuid.UUID
+	AttemptedOn time.Time
+	StatusCode  int
+	Error       string
+}
+
+// MarshalJSON converts a WebhookAttempt into a serialisable json format
+func (a WebhookAttempt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		DeliveryID  string `json:"delivery_id"`
+		AttemptedOn string `json:"attempted_on"`
+		StatusCode  int    `json:"status_code"`
+		Error       string `json:"error"`
+	}{
+		DeliveryID:  a.DeliveryID.String(),
+		AttemptedOn: a.AttemptedOn.Format(time.RFC3339),
+		StatusCode:  a.StatusCode,
+		Error:       a.Error,
+	})
+}
+
+// WebhookInput takes values from json and places them into a unverified model.
+type WebhookInput struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	ResourceID string   `json:"resource_id"`
+	Tag        string   `json:"tag"`
+	AuthorID   string   `json:"author_id"`
+	Events     []string `json:"events"`
+}
+
+// ValidateWebhookInput validates input of the WebhookInput
+func ValidateWebhookInput(input WebhookInput) error {
+	if len(strings.TrimSpace(input.URL)) == 0 {
+		return errors.New("input.url is empty")
+	}
+	u, err := url.Parse(input.URL)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return errors.New("input.url is not an absolute url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("input.url is not a http or https url")
+	}
+	if PrivateHost(u.Hostname()) {
+		return errors.New("input.url is not a public host")
+	}
+
+	if len(input.Secret) == 0 {
+		return errors.New("input.secret is empty")
+	}
+
+	if input.ResourceID != "" {
+		if _, err := uuid.Parse(input.ResourceID); err != nil {
+			return errors.New("input.resource_id is not a valid uuid")
+		}
+	}
+
+	for k, event := range input.Events {
+		switch event {
+		case WebhookEventInsert, WebhookEventAppend, WebhookEventFork, WebhookEventDelete:
+		default:
+			return fmt.Errorf("input.events[%d] is not a known event", k)
+		}
+	}
+
+	return nil
+}
+
+// PrivateHost checks if the host is, or is an address of, this host or the
+// private network, which webhooks can't be delivered to. Host names that
+// aren't addresses can still resolve to private addresses, so the addresses
+// are checked again when a delivery is sent.
+func PrivateHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && PrivateIP(ip)
+}
+
+// PrivateIP checks if the address is a loopback, link local, unspecified or
+// private address.
+func PrivateIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+var privateNetworks = func() []*net.IPNet {
+	var res []*net.IPNet
+	for _, cidr := range []string{
+		"10.0.0.0/8",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"100.64.0.0/10",
+		"fc00::/7",
+	} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, network)
+	}
+	return res
+}()
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
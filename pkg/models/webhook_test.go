@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/trussle/uuid"
+)
+
+func TestValidateWebhookInput(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		input    WebhookInput
+		expected bool
+	}{
+		{"valid", WebhookInput{URL: "https://example.com/hook", Secret: "secret"}, true},
+		{"events", WebhookInput{URL: "http://example.com", Secret: "secret", Events: []string{"insert", "delete"}}, true},
+		{"resource id", WebhookInput{URL: "http://example.com", Secret: "secret", ResourceID: uuid.MustNew().String()}, true},
+		{"empty url", WebhookInput{Secret: "secret"}, false},
+		{"relative url", WebhookInput{URL: "/hook", Secret: "secret"}, false},
+		{"unknown scheme", WebhookInput{URL: "ftp://example.com", Secret: "secret"}, false},
+		{"empty secret", WebhookInput{URL: "http://example.com"}, false},
+		{"invalid resource id", WebhookInput{URL: "http://example.com", Secret: "secret", ResourceID: "bad"}, false},
+		{"unknown event", WebhookInput{URL: "http://example.com", Secret: "secret", Events: []string{"update"}}, false},
+		{"localhost", WebhookInput{URL: "http://localhost:8080/hook", Secret: "secret"}, false},
+		{"loopback", WebhookInput{URL: "http://127.0.0.1/hook", Secret: "secret"}, false},
+		{"loopback ipv6", WebhookInput{URL: "http://[::1]/hook", Secret: "secret"}, false},
+		{"link local", WebhookInput{URL: "http://169.254.169.254/latest/meta-data", Secret: "secret"}, false},
+		{"private", WebhookInput{URL: "https://10.0.0.1/hook", Secret: "secret"}, false},
+		{"private ipv6", WebhookInput{URL: "https://[fd00::1]/hook", Secret: "secret"}, false},
+		{"unspecified", WebhookInput{URL: "http://0.0.0.0/hook", Secret: "secret"}, false},
+		{"public address", WebhookInput{URL: "https://93.184.216.34/hook", Secret: "secret"}, true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if expected, actual := tc.expected, ValidateWebhookInput(tc.input) == nil; expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	t.Parallel()
+
+	resourceID := uuid.MustNew()
+	doc, err := BuildLedger(
+		WithResourceID(resourceID),
+		WithAuthorID("author"),
+		WithTags([]string{"a", "b"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		webhook  Webhook
+		event    string
+		expected bool
+	}{
+		{"everything", Webhook{}, WebhookEventInsert, true},
+		{"resource id", Webhook{ResourceID: resourceID}, WebhookEventInsert, true},
+		{"other resource id", Webhook{ResourceID: uuid.MustNew()}, WebhookEventInsert, false},
+		{"author", Webhook{AuthorID: "author"}, WebhookEventAppend, true},
+		{"other author", Webhook{AuthorID: "other"}, WebhookEventAppend, false},
+		{"tag", Webhook{Tag: "b"}, WebhookEventFork, true},
+		{"other tag", Webhook{Tag: "c"}, WebhookEventFork, false},
+		{"event", Webhook{Events: []string{WebhookEventDelete}}, WebhookEventDelete, true},
+		{"other event", Webhook{Events: []string{WebhookEventDelete}}, WebhookEventInsert, false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if expected, actual := tc.expected, tc.webhook.Matches(tc.event, doc); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestWebhookMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(Webhook{
+		ID:     uuid.MustNew(),
+		URL:    "http://example.com",
+		Secret: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := string(b); strings.Contains(actual, "secret") {
+		t.Errorf("expected no secret, actual: %s", actual)
+	}
+}
@@ -0,0 +1,84 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/metrics"
+)
+
+const (
+	defaultContentType = "application/json"
+)
+
+// API serves the OpenAPI document.
+type API struct {
+	body     []byte
+	logger   log.Logger
+	clients  metrics.Gauge
+	duration metrics.HistogramVec
+	errors   errs.Error
+}
+
+// NewAPI creates a API with the correct dependencies. The document is encoded
+// once up front, as it doesn't change.
+func NewAPI(doc Document, logger log.Logger,
+	clients metrics.Gauge,
+	duration metrics.HistogramVec,
+) (*API, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &API{
+		body:     body,
+		logger:   logger,
+		clients:  clients,
+		duration: duration,
+		errors:   errs.NewError(logger),
+	}, nil
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	level.Info(a.logger).Log("method", r.Method, "url", r.URL.String())
+
+	iw := &interceptingWriter{http.StatusOK, w}
+	w = iw
+
+	// Metrics
+	a.clients.Inc()
+	defer a.clients.Dec()
+
+	defer func(begin time.Time) {
+		a.duration.WithLabelValues(
+			r.Method,
+			r.URL.Path,
+			strconv.Itoa(iw.code),
+		).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	if r.Method != "GET" {
+		a.errors.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", defaultContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(a.body)
+}
+
+type interceptingWriter struct {
+	code int
+	http.ResponseWriter
+}
+
+func (iw *interceptingWriter) WriteHeader(code int) {
+	iw.code = code
+	iw.ResponseWriter.WriteHeader(code)
+}
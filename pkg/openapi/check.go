@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// CheckRoutes checks that the paths describe exactly the routes, each as the
+// upper case method followed by the path, so that the document can't drift
+// from the routes of an API.
+func CheckRoutes(paths Paths, routes []string) error {
+	var (
+		described = paths.Routes()
+		served    = append([]string(nil), routes...)
+	)
+	sort.Strings(served)
+
+	if missing := difference(served, described); len(missing) > 0 {
+		return errors.Errorf("routes not described: %s", strings.Join(missing, ", "))
+	}
+	if unknown := difference(described, served); len(unknown) > 0 {
+		return errors.Errorf("routes described but not served: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// MuxRoutes returns every route of the router, each as the upper case method
+// followed by the path template.
+func MuxRoutes(router *mux.Router) ([]string, error) {
+	var res []string
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			res = append(res, strings.ToUpper(method)+" "+path)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// CheckParameters checks that the decoder of the query accepts a query with
+// only the required query parameters, and rejects a query that is missing
+// any one of them, so that the parameters can't drift from the decoder.
+func CheckParameters(params []Parameter, decode func(*url.URL) error) error {
+	if err := decode(exampleURL(params, "", true)); err != nil {
+		return errors.Wrap(err, "every parameter")
+	}
+	if err := decode(exampleURL(params, "", false)); err != nil {
+		return errors.Wrap(err, "required parameters")
+	}
+	for _, param := range params {
+		if param.In != "query" || !param.Required {
+			continue
+		}
+		if err := decode(exampleURL(params, param.Name, false)); err == nil {
+			return errors.Errorf("parameter %q is described as required, but it's optional", param.Name)
+		}
+	}
+	return nil
+}
+
+// exampleURL returns a URL with an example of every query parameter, except
+// for the named one. Optional parameters are only included if all is true.
+func exampleURL(params []Parameter, except string, all bool) *url.URL {
+	values := make(url.Values)
+	for _, param := range params {
+		if param.In != "query" || param.Name == except || (!param.Required && !all) {
+			continue
+		}
+		values.Set(param.Name, Example(param.Schema))
+	}
+	return &url.URL{Path: "/", RawQuery: values.Encode()}
+}
+
+// Example returns an example value of the schema, as it would be in a query.
+func Example(schema Schema) string {
+	switch schema.Type {
+	case "integer":
+		return "1"
+	case "boolean":
+		return "true"
+	case "array":
+		if schema.Items != nil {
+			return Example(*schema.Items)
+		}
+	case "string":
+		switch schema.Format {
+		case "uuid":
+			return "b8fea624-4231-4ddc-b2cc-4b6a41831b03"
+		case "date-time":
+			return "2018-05-10T20:00:35Z"
+		}
+	}
+	return "example"
+}
+
+// difference returns the values of a that aren't in b, both sorted.
+func difference(a, b []string) []string {
+	var res []string
+	for _, v := range a {
+		if i := sort.SearchStrings(b, v); i == len(b) || b[i] != v {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+func TestCheckRoutes(t *testing.T) {
+	t.Parallel()
+
+	paths := Paths{
+		"/":         PathItem{"get": {}, "put": {}},
+		"/history/": PathItem{"get": {}},
+	}
+
+	router := mux.NewRouter()
+	router.Methods("GET").Path("/").HandlerFunc(http.NotFound)
+	router.Methods("PUT").Path("/").HandlerFunc(http.NotFound)
+	router.Methods("GET").Path("/history/").HandlerFunc(http.NotFound)
+
+	routes, err := MuxRoutes(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("described", func(t *testing.T) {
+		if err := CheckRoutes(paths, routes); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("not described", func(t *testing.T) {
+		if err := CheckRoutes(paths, append(routes, "POST /")); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("not served", func(t *testing.T) {
+		if err := CheckRoutes(paths, routes[1:]); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestCheckParameters(t *testing.T) {
+	t.Parallel()
+
+	decode := func(u *url.URL) error {
+		if u.Query().Get("resource_id") == "" {
+			return errors.New("missing resource_id")
+		}
+		return nil
+	}
+
+	t.Run("matching", func(t *testing.T) {
+		params := []Parameter{
+			Query("resource_id", "", true, StringFormat("uuid")),
+			Query("query.tags", "", false, String()),
+		}
+		if err := CheckParameters(params, decode); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("optional described as required", func(t *testing.T) {
+		params := []Parameter{
+			Query("resource_id", "", true, StringFormat("uuid")),
+			Query("query.tags", "", true, String()),
+		}
+		if err := CheckParameters(params, decode); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("required described as optional", func(t *testing.T) {
+		params := []Parameter{
+			Query("resource_id", "", false, StringFormat("uuid")),
+		}
+		if err := CheckParameters(params, decode); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// Version is the version of the OpenAPI specification the documents are
+// written against.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document, with only the parts of the specification
+// the API makes use of.
+type Document struct {
+	OpenAPI string `json:"openapi"`
+	Info    Info   `json:"info"`
+	Paths   Paths  `json:"paths"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Paths holds the operations of every path, keyed by the path.
+type Paths map[string]PathItem
+
+// PathItem holds the operations of a path, keyed by the lower case method.
+type PathItem map[string]Operation
+
+// Operation describes a single route.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter describes a query or header parameter of an operation.
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+	Explode     *bool  `json:"explode,omitempty"`
+	Schema      Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the body of a media type.
+type MediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+// Schema describes the shape of a value, with only the parts of the JSON
+// schema dialect of the specification the API makes use of.
+type Schema struct {
+	Type                 string            `json:"type,omitempty"`
+	Format               string            `json:"format,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	Items                *Schema           `json:"items,omitempty"`
+	Properties           map[string]Schema `json:"properties,omitempty"`
+	Required             []string          `json:"required,omitempty"`
+	AdditionalProperties *bool             `json:"additionalProperties,omitempty"`
+	Nullable             bool              `json:"nullable,omitempty"`
+}
+
+// Option defines a option for building the Document.
+type Option func(*Document)
+
+// WithPaths adds the paths of an API, mounted at the prefix.
+func WithPaths(prefix string, paths Paths) Option {
+	return func(d *Document) {
+		prefix = strings.TrimSuffix(prefix, "/")
+		for path, item := range paths {
+			d.Paths[prefix+path] = item
+		}
+	}
+}
+
+// NewDocument creates a Document of the API with the paths of every option.
+func NewDocument(title, version string, opts ...Option) Document {
+	doc := Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   title,
+			Version: version,
+		},
+		Paths: make(Paths),
+	}
+	for _, opt := range opts {
+		opt(&doc)
+	}
+	return doc
+}
+
+// Routes returns every route of the paths, as the upper case method followed
+// by the path, sorted.
+func (p Paths) Routes() []string {
+	var res []string
+	for path, item := range p {
+		for method := range item {
+			res = append(res, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Query returns a query parameter. Array parameters are comma separated, as
+// that's how the APIs decode them.
+func Query(name, description string, required bool, schema Schema) Parameter {
+	param := Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Required:    required,
+		Schema:      schema,
+	}
+	if schema.Type == "array" {
+		explode := false
+		param.Explode = &explode
+	}
+	return param
+}
+
+// Header returns a header parameter.
+func Header(name, description string, required bool, schema Schema) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "header",
+		Description: description,
+		Required:    required,
+		Schema:      schema,
+	}
+}
+
+// String returns a string schema.
+func String() Schema {
+	return Schema{Type: "string"}
+}
+
+// StringFormat returns a string schema of the format.
+func StringFormat(format string) Schema {
+	return Schema{Type: "string", Format: format}
+}
+
+// Integer returns a integer schema.
+func Integer() Schema {
+	return Schema{Type: "integer", Format: "int64"}
+}
+
+// Boolean returns a boolean schema.
+func Boolean() Schema {
+	return Schema{Type: "boolean"}
+}
+
+// Array returns an array schema of the items.
+func Array(items Schema) Schema {
+	return Schema{Type: "array", Items: &items}
+}
+
+// Object returns an object schema of the properties, where every property is
+// required unless it's listed as optional. No other properties are allowed.
+func Object(properties map[string]Schema, optional ...string) Schema {
+	var required []string
+	for name := range properties {
+		if !contains(optional, name) {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+
+	additional := false
+	return Schema{
+		Type:                 "object",
+		Properties:           properties,
+		Required:             required,
+		AdditionalProperties: &additional,
+	}
+}
+
+// Problem returns the content of a application/problem+json body of the
+// schema.
+func Problem(schema Schema) map[string]MediaType {
+	return map[string]MediaType{
+		"application/problem+json": {Schema: schema},
+	}
+}
+
+// JSON returns the content of a application/json body of the schema.
+func JSON(schema Schema) map[string]MediaType {
+	return map[string]MediaType{
+		"application/json": {Schema: schema},
+	}
+}
+
+// Error is the schema of the body of every error response, a problem details
+// object with the description and code kept along side.
+func Error() Schema {
+	return Object(map[string]Schema{
+		"type":       String(),
+		"title":      String(),
+		"status":     Integer(),
+		"detail":     String(),
+		"instance":   String(),
+		"request_id": String(),
+		"errors": Array(Object(map[string]Schema{
+			"field":  String(),
+			"reason": String(),
+		})),
+		"description": String(),
+		"code":        Integer(),
+	}, "instance", "request_id", "errors")
+}
+
+// Responses returns the responses of an operation, the successful response
+// along with the error responses of the status codes.
+func Responses(ok Response, codes ...string) map[string]Response {
+	res := map[string]Response{
+		"200": ok,
+	}
+	for _, code := range codes {
+		res[code] = Response{
+			Description: errorDescriptions[code],
+			Content:     Problem(Error()),
+		}
+	}
+	return res
+}
+
+var errorDescriptions = map[string]string{
+	"400": "Bad request",
+	"403": "Forbidden",
+	"404": "Not found",
+	"410": "Erased",
+	"412": "Precondition failed",
+	"413": "Quota exceeded",
+	"429": "Daily quota exceeded",
+	"500": "Internal server error",
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
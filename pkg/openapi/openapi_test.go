@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with paths", func(t *testing.T) {
+		doc := NewDocument("snowy", "dev",
+			WithPaths("/ledgers/", Paths{
+				"/": PathItem{"get": {}, "post": {}},
+			}),
+			WithPaths("/status", Paths{
+				"/health": PathItem{"get": {}},
+			}),
+		)
+
+		if expected, actual := []string{
+			"GET /ledgers/",
+			"GET /status/health",
+			"POST /ledgers/",
+		}, doc.Paths.Routes(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		doc := NewDocument("snowy", "dev", WithPaths("/ledgers", Paths{
+			"/": PathItem{"get": {
+				OperationID: "selectLedger",
+				Parameters:  []Parameter{Query("ids", "", true, Array(String()))},
+				Responses:   Responses(Response{Description: "ok"}, "404"),
+			}},
+		}))
+
+		b, err := json.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res struct {
+			OpenAPI string `json:"openapi"`
+			Paths   map[string]map[string]struct {
+				Parameters []struct {
+					Explode *bool `json:"explode"`
+				} `json:"parameters"`
+				Responses map[string]json.RawMessage `json:"responses"`
+			} `json:"paths"`
+		}
+		if err := json.Unmarshal(b, &res); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := Version, res.OpenAPI; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		op := res.Paths["/ledgers/"]["get"]
+		if len(op.Parameters) != 1 || op.Parameters[0].Explode == nil || *op.Parameters[0].Explode {
+			t.Errorf("expected array query parameter to not explode, got %s", b)
+		}
+		if expected, actual := 2, len(op.Responses); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
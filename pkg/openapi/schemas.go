@@ -0,0 +1,86 @@
+package openapi
+
+// These are the schemas of the models, as they're encoded by the API.
+
+// Ledger is the schema of a ledger revision.
+func Ledger() Schema {
+	return Object(map[string]Schema{
+		"id":                    StringFormat("uuid"),
+		"parent_id":             StringFormat("uuid"),
+		"name":                  String(),
+		"resource_id":           StringFormat("uuid"),
+		"resource_address":      String(),
+		"resource_size":         Integer(),
+		"resource_content_type": String(),
+		"author_id":             String(),
+		"tags":                  Array(String()),
+		"created_on":            StringFormat("date-time"),
+		"deleted_on":            StringFormat("date-time"),
+		"signature":             StringFormat("byte"),
+		"signature_key_id":      String(),
+	}, "id", "signature", "signature_key_id")
+}
+
+// LedgerInput is the schema of the ledger of a write. The resource fields are
+// set from the content for the journals API.
+func LedgerInput() Schema {
+	return Object(map[string]Schema{
+		"name":                  String(),
+		"resource_address":      String(),
+		"resource_size":         Integer(),
+		"resource_content_type": String(),
+		"author_id":             String(),
+		"tags":                  Array(String()),
+		"created_on":            StringFormat("date-time"),
+		"signature":             StringFormat("byte"),
+		"signature_key_id":      String(),
+	}, "resource_address", "resource_size", "resource_content_type", "tags",
+		"created_on", "signature", "signature_key_id")
+}
+
+// Content is the schema of a stored content.
+func Content() Schema {
+	return Object(map[string]Schema{
+		"address":      String(),
+		"size":         Integer(),
+		"content_type": String(),
+	})
+}
+
+// ACL is the schema of the access control list of a resource.
+func ACL() Schema {
+	return Object(map[string]Schema{
+		"resource_id": StringFormat("uuid"),
+		"owner":       String(),
+		"readers":     Array(String()),
+		"writers":     Array(String()),
+		"updated_by":  String(),
+		"created_on":  StringFormat("date-time"),
+	})
+}
+
+// ACLInput is the schema of an update to the access control list of a
+// resource.
+func ACLInput() Schema {
+	return Object(map[string]Schema{
+		"owner":   String(),
+		"readers": Array(String()),
+		"writers": Array(String()),
+	}, "readers", "writers")
+}
+
+// ResourceID is the schema of the result of a write.
+func ResourceID() Schema {
+	return Object(map[string]Schema{
+		"resource_id": StringFormat("uuid"),
+	})
+}
+
+// Resource is the schema of the result of a write of content, along with the
+// address the content was stored at.
+func Resource() Schema {
+	return Object(map[string]Schema{
+		"resource_id":      StringFormat("uuid"),
+		"resource_address": String(),
+	})
+}
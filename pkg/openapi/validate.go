@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/uuid"
+)
+
+// Validate validates the JSON against the schema, so that the responses of
+// the API can be checked against the document.
+func Validate(schema Schema, b []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return errors.Wrap(err, "invalid json")
+	}
+	return validate(schema, value, "$")
+}
+
+func validate(schema Schema, value interface{}, path string) error {
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return errors.Errorf("%s: expected %s, got null", path, schema.Type)
+	}
+
+	switch schema.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return errors.Errorf("%s: expected string, got %T", path, value)
+		}
+		return validateFormat(schema.Format, s, path)
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return errors.Errorf("%s: expected integer, got %v", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return errors.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	case "array":
+		values, ok := value.([]interface{})
+		if !ok {
+			return errors.Errorf("%s: expected array, got %T", path, value)
+		}
+		if schema.Items == nil {
+			return nil
+		}
+		for k, v := range values {
+			if err := validate(*schema.Items, v, path+"["+strconv.Itoa(k)+"]"); err != nil {
+				return err
+			}
+		}
+	case "object":
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("%s: expected object, got %T", path, value)
+		}
+		for _, name := range schema.Required {
+			if _, ok := values[name]; !ok {
+				return errors.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		for name, v := range values {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return errors.Errorf("%s: unexpected property %q", path, name)
+				}
+				continue
+			}
+			if err := validate(property, v, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "":
+		// Any value is valid.
+	default:
+		return errors.Errorf("%s: unknown schema type %q", path, schema.Type)
+	}
+	return nil
+}
+
+func validateFormat(format, s, path string) error {
+	switch format {
+	case "uuid":
+		if _, err := uuid.Parse(s); err != nil {
+			return errors.Errorf("%s: expected uuid, got %q", path, s)
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return errors.Errorf("%s: expected date-time, got %q", path, s)
+		}
+	}
+	return nil
+}
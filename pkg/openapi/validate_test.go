@@ -0,0 +1,39 @@
+package openapi
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	schema := Object(map[string]Schema{
+		"id":      StringFormat("uuid"),
+		"size":    Integer(),
+		"tags":    Array(String()),
+		"created": StringFormat("date-time"),
+		"valid":   Boolean(),
+	}, "valid")
+
+	t.Run("valid", func(t *testing.T) {
+		body := `{"id":"b8fea624-4231-4ddc-b2cc-4b6a41831b03","size":1,"tags":["a"],"created":"2018-05-10T20:00:35Z"}`
+		if err := Validate(schema, []byte(body)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	for name, body := range map[string]string{
+		"invalid json":       `{`,
+		"missing property":   `{"id":"b8fea624-4231-4ddc-b2cc-4b6a41831b03","size":1,"tags":[]}`,
+		"unexpected":         `{"id":"b8fea624-4231-4ddc-b2cc-4b6a41831b03","size":1,"tags":[],"created":"2018-05-10T20:00:35Z","other":1}`,
+		"invalid uuid":       `{"id":"nope","size":1,"tags":[],"created":"2018-05-10T20:00:35Z"}`,
+		"invalid date-time":  `{"id":"b8fea624-4231-4ddc-b2cc-4b6a41831b03","size":1,"tags":[],"created":"yesterday"}`,
+		"fractional integer": `{"id":"b8fea624-4231-4ddc-b2cc-4b6a41831b03","size":1.5,"tags":[],"created":"2018-05-10T20:00:35Z"}`,
+		"invalid items":      `{"id":"b8fea624-4231-4ddc-b2cc-4b6a41831b03","size":1,"tags":[1],"created":"2018-05-10T20:00:35Z"}`,
+		"null":               `null`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := Validate(schema, []byte(body)); err == nil {
+				t.Errorf("expected error for %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/trussle/snowy/pkg/store"
+)
+
+const (
+	defaultInterval  = time.Second
+	defaultBatchSize = 500
+)
+
+// Relay publishes the records of the outbox to the sinks. Every sink keeps a
+// checkpoint of the last record it received, which is only moved on once a
+// batch is written, so every record is delivered at least once. Records that
+// every sink has received are removed from the outbox. Every replica runs a
+// relay, but only one of them relays the outbox at a time.
+type Relay struct {
+	store     store.Store
+	sinks     []Sink
+	interval  time.Duration
+	batchSize int
+	logger    log.Logger
+	stop      chan chan struct{}
+}
+
+// RelayOption defines a option for configuring the relay.
+type RelayOption func(*Relay)
+
+// WithInterval sets how often the relay looks for new records.
+func WithInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) {
+		r.interval = interval
+	}
+}
+
+// WithBatchSize sets how many records are written to a sink at a time.
+func WithBatchSize(batchSize int) RelayOption {
+	return func(r *Relay) {
+		r.batchSize = batchSize
+	}
+}
+
+// NewRelay creates a Relay with correct dependencies. If there are no sinks,
+// then the relay only removes the records, so that the outbox doesn't grow.
+func NewRelay(store store.Store, sinks []Sink, logger log.Logger, opts ...RelayOption) *Relay {
+	r := &Relay{
+		store:     store,
+		sinks:     sinks,
+		interval:  defaultInterval,
+		batchSize: defaultBatchSize,
+		logger:    logger,
+		stop:      make(chan chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.batchSize < 1 {
+		r.batchSize = defaultBatchSize
+	}
+	return r
+}
+
+// Run relays the records periodically, until the relay is stopped. The sinks
+// are closed once it's stopped.
+func (r *Relay) Run() error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.relay(); err != nil {
+				level.Error(r.logger).Log("action", "relay", "err", err.Error())
+			}
+		case c := <-r.stop:
+			for _, sink := range r.sinks {
+				if err := sink.Close(); err != nil {
+					level.Error(r.logger).Log("action", "close", "sink", sink.Name(), "err", err.Error())
+				}
+			}
+			close(c)
+			return nil
+		}
+	}
+}
+
+// Stop stops the relay, waiting for any records in flight.
+func (r *Relay) Stop() {
+	c := make(chan struct{})
+	r.stop <- c
+	<-c
+}
+
+// relay relays the outbox, unless another replica is already relaying it, in
+// which case the pass is skipped.
+func (r *Relay) relay() error {
+	locked, err := r.store.LockOutboxRelay(r.relaySinks)
+	if err == nil && !locked {
+		level.Debug(r.logger).Log("action", "relay", "skipped", "locked")
+	}
+	return err
+}
+
+// relaySinks writes the new records to every sink, then removes the records
+// that every sink has received. A sink that fails doesn't hold up the others,
+// but the records it hasn't received are kept.
+func (r *Relay) relaySinks() error {
+	if len(r.sinks) == 0 {
+		return r.prune()
+	}
+
+	var through int64 = -1
+	for _, sink := range r.sinks {
+		sequence, err := r.relaySink(sink)
+		if err != nil {
+			level.Warn(r.logger).Log("action", "relay", "sink", sink.Name(), "sequence", sequence, "err", err.Error())
+		}
+		if through < 0 || sequence < through {
+			through = sequence
+		}
+	}
+
+	if through > 0 {
+		return r.store.DeleteOutbox(through)
+	}
+	return nil
+}
+
+// relaySink writes the records after the checkpoint of the sink, a batch at a
+// time, returning the new checkpoint of the sink.
+func (r *Relay) relaySink(sink Sink) (int64, error) {
+	checkpoint, err := r.store.SelectOutboxCheckpoint(sink.Name())
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		records, err := r.store.SelectOutbox(checkpoint, r.batchSize)
+		if err != nil || len(records) == 0 {
+			return checkpoint, err
+		}
+
+		batch := make([]Record, len(records))
+		for k, v := range records {
+			batch[k] = newRecord(v)
+		}
+		if err := sink.Write(batch); err != nil {
+			return checkpoint, err
+		}
+
+		sequence := records[len(records)-1].Sequence
+		if err := r.store.InsertOutboxCheckpoint(sink.Name(), sequence); err != nil {
+			return checkpoint, err
+		}
+		checkpoint = sequence
+
+		if len(records) < r.batchSize {
+			return checkpoint, nil
+		}
+	}
+}
+
+// prune removes a batch of records, when there are no sinks to receive them.
+func (r *Relay) prune() error {
+	records, err := r.store.SelectOutbox(0, r.batchSize)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	return r.store.DeleteOutbox(records[len(records)-1].Sequence)
+}
@@ -0,0 +1,203 @@
+package outbox
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/uuid"
+)
+
+type recordingSink struct {
+	mutex   sync.Mutex
+	name    string
+	fail    bool
+	records []Record
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Write(records []Record) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.fail {
+		return errors.New("failed")
+	}
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func newStore(t *testing.T, n int) store.Store {
+	s := store.NewVirtualStore()
+	for i := 0; i < n; i++ {
+		if err := s.Insert(store.Entity{ResourceID: uuid.MustNew(), Name: "name"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestRelay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("relays every record in batches", func(t *testing.T) {
+		var (
+			s     = newStore(t, 5)
+			sink  = &recordingSink{name: "sink"}
+			relay = NewRelay(s, []Sink{sink}, log.NewNopLogger(), WithBatchSize(2))
+		)
+
+		if err := relay.relay(); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := 5, len(sink.records); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		for k, v := range sink.records {
+			if expected, actual := int64(k+1), v.Sequence; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+			if expected, actual := store.OutboxEventInsert, v.Event; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		}
+
+		checkpoint, err := s.SelectOutboxCheckpoint("sink")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(5), checkpoint; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		records, err := s.SelectOutbox(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("resumes from the checkpoint", func(t *testing.T) {
+		var (
+			s     = newStore(t, 2)
+			sink  = &recordingSink{name: "sink"}
+			relay = NewRelay(s, []Sink{sink}, log.NewNopLogger())
+		)
+
+		if err := relay.relay(); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Insert(store.Entity{ResourceID: uuid.MustNew()}); err != nil {
+			t.Fatal(err)
+		}
+		if err := relay.relay(); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := 3, len(sink.records); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int64(3), sink.records[2].Sequence; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("failed sink keeps the records", func(t *testing.T) {
+		var (
+			s      = newStore(t, 3)
+			good   = &recordingSink{name: "good"}
+			failed = &recordingSink{name: "failed", fail: true}
+			relay  = NewRelay(s, []Sink{good, failed}, log.NewNopLogger())
+		)
+
+		if err := relay.relay(); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 3, len(good.records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		records, err := s.SelectOutbox(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 3, len(records); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+
+		// Once the sink recovers it receives every record, without the other
+		// sink receiving them again.
+		failed.fail = false
+		if err := relay.relay(); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 3, len(failed.records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 3, len(good.records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		records, err = s.SelectOutbox(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("only one replica relays at a time", func(t *testing.T) {
+		var (
+			s     = newStore(t, 3)
+			sink  = &recordingSink{name: "sink"}
+			relay = NewRelay(s, []Sink{sink}, log.NewNopLogger())
+		)
+
+		// Another replica is relaying the outbox.
+		locked, err := s.LockOutboxRelay(relay.relay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := true, locked; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := 0, len(sink.records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		if err := relay.relay(); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 3, len(sink.records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("no sinks prunes the records", func(t *testing.T) {
+		var (
+			s     = newStore(t, 3)
+			relay = NewRelay(s, nil, log.NewNopLogger())
+		)
+
+		if err := relay.relay(); err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := s.SelectOutbox(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
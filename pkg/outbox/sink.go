@@ -0,0 +1,219 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/store"
+)
+
+const (
+	defaultContentType = "application/x-ndjson"
+	defaultHTTPTimeout = 10 * time.Second
+
+	// maxErrorBody is how much of the body of a failed response is kept as
+	// the error.
+	maxErrorBody = 512
+)
+
+// Record represents a change to a ledger as it's written to a sink, one JSON
+// object per line. The sequence always increases, so sinks can use it to
+// remove any duplicates.
+type Record struct {
+	Sequence   int64           `json:"sequence"`
+	Event      string          `json:"event"`
+	LedgerID   string          `json:"ledger_id"`
+	TenantID   string          `json:"tenant"`
+	ResourceID string          `json:"resource_id"`
+	CreatedOn  time.Time       `json:"created_on"`
+	Ledger     json.RawMessage `json:"ledger"`
+}
+
+func newRecord(record store.OutboxRecord) Record {
+	return Record{
+		Sequence:   record.Sequence,
+		Event:      record.Event,
+		LedgerID:   record.LedgerID.String(),
+		TenantID:   record.TenantID,
+		ResourceID: record.ResourceID.String(),
+		CreatedOn:  record.CreatedOn,
+		Ledger:     record.Payload,
+	}
+}
+
+// Sink receives the records of the outbox. A batch of records is either
+// written or an error is returned, in which case the whole batch is written
+// again later, so a sink may receive the same record more than once.
+type Sink interface {
+
+	// Name identifies the sink, which the checkpoint of the sink is kept
+	// under. It should stay the same between restarts.
+	Name() string
+
+	// Write writes a batch of records, in the order of the sequence.
+	Write([]Record) error
+
+	// Close closes the sink.
+	Close() error
+}
+
+// ParseSink creates a Sink from a description of it, which is either "stdout",
+// "file:<path>" or a http(s) URL.
+func ParseSink(s string) (Sink, error) {
+	switch {
+	case s == "stdout":
+		return NewWriterSink("stdout", os.Stdout), nil
+	case strings.HasPrefix(s, "file:"):
+		return NewFileSink(strings.TrimPrefix(s, "file:"))
+	case strings.HasPrefix(s, "http://"), strings.HasPrefix(s, "https://"):
+		return NewHTTPSink(s, defaultHTTPTimeout), nil
+	default:
+		return nil, errors.Errorf("invalid sink %q", s)
+	}
+}
+
+// ParseSinks creates the sinks from a comma separated list of descriptions,
+// see ParseSink. Empty descriptions are ignored.
+func ParseSinks(s string) ([]Sink, error) {
+	var res []Sink
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		sink, err := ParseSink(v)
+		if err != nil {
+			for _, sink := range res {
+				sink.Close()
+			}
+			return nil, err
+		}
+		res = append(res, sink)
+	}
+	return res, nil
+}
+
+// encode writes the records as newline delimited JSON.
+func encode(w io.Writer, records []Record) error {
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type writerSink struct {
+	mutex sync.Mutex
+	name  string
+	w     io.Writer
+}
+
+// NewWriterSink creates a Sink that writes the records to the writer as
+// newline delimited JSON.
+func NewWriterSink(name string, w io.Writer) Sink {
+	return &writerSink{
+		name: name,
+		w:    w,
+	}
+}
+
+func (s *writerSink) Name() string { return s.name }
+
+func (s *writerSink) Write(records []Record) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return encode(s.w, records)
+}
+
+func (s *writerSink) Close() error { return nil }
+
+type fileSink struct {
+	mutex sync.Mutex
+	path  string
+	file  *os.File
+}
+
+// NewFileSink creates a Sink that appends the records to the file as newline
+// delimited JSON. Every batch is synced to disk before it's acknowledged.
+func NewFileSink(path string) (Sink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open file sink")
+	}
+	return &fileSink{
+		path: path,
+		file: file,
+	}, nil
+}
+
+func (s *fileSink) Name() string { return "file:" + s.path }
+
+func (s *fileSink) Write(records []Record) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w := bufio.NewWriter(s.file)
+	if err := encode(w, records); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *fileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.file.Close()
+}
+
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates a Sink that posts every batch of records to the URL as
+// newline delimited JSON. Any 2xx response acknowledges the batch.
+func NewHTTPSink(url string, timeout time.Duration) Sink {
+	return &httpSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *httpSink) Name() string { return s.url }
+
+func (s *httpSink) Write(records []Record) error {
+	var buf bytes.Buffer
+	if err := encode(&buf, records); err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, defaultContentType, &buf)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error { return nil }
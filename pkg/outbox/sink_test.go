@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func decode(t *testing.T, b []byte) []Record {
+	var res []Record
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, record)
+	}
+	return res
+}
+
+func records() []Record {
+	return []Record{
+		{Sequence: 1, Event: "insert", Ledger: json.RawMessage(`{"name":"a"}`)},
+		{Sequence: 2, Event: "append", Ledger: json.RawMessage(`{"name":"b"}`)},
+	}
+}
+
+func TestParseSink(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "outbox.ndjson")
+	for _, test := range []struct {
+		input, name string
+	}{
+		{"stdout", "stdout"},
+		{"file:" + path, "file:" + path},
+		{"http://example.com/outbox", "http://example.com/outbox"},
+		{"https://example.com/outbox", "https://example.com/outbox"},
+	} {
+		sink, err := ParseSink(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := test.name, sink.Name(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		sink.Close()
+	}
+
+	if _, err := ParseSink("kafka://example.com"); err == nil {
+		t.Errorf("expected error for invalid sink")
+	}
+
+	sinks, err := ParseSinks("stdout, ,http://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := 2, len(sinks); expected != actual {
+		t.Errorf("expected: %d, actual: %d", expected, actual)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf  bytes.Buffer
+		sink = NewWriterSink("buffer", &buf)
+	)
+	if err := sink.Write(records()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := decode(t, buf.Bytes())
+	if expected, actual := 2, len(got); expected != actual {
+		t.Fatalf("expected: %d, actual: %d", expected, actual)
+	}
+	if expected, actual := `{"name":"b"}`, string(got[1].Ledger); expected != actual {
+		t.Errorf("expected: %s, actual: %s", expected, actual)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "outbox.ndjson")
+
+	// Every sink appends to the file, so the records survive a restart.
+	for i := 0; i < 2; i++ {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Write(records()); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := 4, len(decode(t, b)); expected != actual {
+		t.Errorf("expected: %d, actual: %d", expected, actual)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("posts the records", func(t *testing.T) {
+		var (
+			contentType string
+			body        []byte
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			body, _ = ioutil.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		sink := NewHTTPSink(server.URL, time.Second)
+		if err := sink.Write(records()); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := defaultContentType, contentType; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := 2, len(decode(t, body)); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("failed response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		sink := NewHTTPSink(server.URL, time.Second)
+		if err := sink.Write(records()); err == nil {
+			t.Errorf("expected error for failed response")
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// bucket is a token bucket, which holds up to burst tokens and is refilled at
+// the rate of tokens per second. Every request takes a single token from the
+// bucket.
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	return &bucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+}
+
+// take attempts to take a token from the bucket. If there are no tokens left,
+// it returns false along with how long to wait until a token is available.
+func (b *bucket) take(now time.Time) (bool, time.Duration) {
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, b.wait(1 - b.tokens)
+}
+
+// remaining returns the number of whole tokens left in the bucket.
+func (b *bucket) remaining() int {
+	return int(math.Floor(b.tokens))
+}
+
+// reset returns how long until the bucket is full again.
+func (b *bucket) reset() time.Duration {
+	return b.wait(float64(b.limit.Burst) - b.tokens)
+}
+
+// full returns if the bucket would be full at the time, so that forgetting
+// about the bucket makes no difference.
+func (b *bucket) full(now time.Time) bool {
+	elapsed := now.Sub(b.last).Seconds()
+	return b.tokens+elapsed*b.limit.Rate >= float64(b.limit.Burst)
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.last = now
+	}
+}
+
+func (b *bucket) wait(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / b.limit.Rate * float64(time.Second))
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucket(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bursts then limits", func(t *testing.T) {
+		var (
+			now = time.Now()
+			b   = newBucket(Limit{Rate: 1, Burst: 3}, now)
+		)
+
+		for i := 0; i < 3; i++ {
+			if allowed, _ := b.take(now); !allowed {
+				t.Fatalf("expected request %d to be allowed", i)
+			}
+		}
+
+		allowed, retry := b.take(now)
+		if expected, actual := false, allowed; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := time.Second, retry; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 3*time.Second, b.reset(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("refills at the rate", func(t *testing.T) {
+		var (
+			now = time.Now()
+			b   = newBucket(Limit{Rate: 2, Burst: 2}, now)
+		)
+
+		b.take(now)
+		b.take(now)
+
+		if allowed, _ := b.take(now.Add(250 * time.Millisecond)); allowed {
+			t.Errorf("expected request to be limited")
+		}
+		if allowed, _ := b.take(now.Add(500 * time.Millisecond)); !allowed {
+			t.Errorf("expected request to be allowed")
+		}
+		if expected, actual := 0, b.remaining(); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("never overfills", func(t *testing.T) {
+		var (
+			now = time.Now()
+			b   = newBucket(Limit{Rate: 10, Burst: 5}, now)
+		)
+
+		b.take(now.Add(time.Hour))
+		if expected, actual := 4, b.remaining(); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := true, b.full(now.Add(time.Hour+time.Second)); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/auth"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/metrics"
+)
+
+// These are the headers that describe the rate limit of a request.
+const (
+	HTTPHeaderRetryAfter         = "Retry-After"
+	HTTPHeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HTTPHeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HTTPHeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+const (
+	defaultSweepInterval = time.Minute
+)
+
+// Limit is the rate of a token bucket, allowing bursts of up to Burst
+// requests and then Rate requests per second after that.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimit parses a limit in the form of "rate:burst", where the rate is the
+// number of requests per second. If the burst is missing, then the burst is
+// the rate rounded up. An empty string is no limit.
+func ParseLimit(s string) (Limit, bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Limit{}, false, nil
+	}
+
+	parts := strings.SplitN(s, ":", 2)
+	rate, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return Limit{}, false, errors.Wrapf(err, "error parsing rate of limit %q", s)
+	}
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return Limit{}, false, errors.Errorf("invalid rate of limit %q", s)
+	}
+
+	burst := int(math.Ceil(rate))
+	if len(parts) > 1 {
+		if burst, err = strconv.Atoi(parts[1]); err != nil {
+			return Limit{}, false, errors.Wrapf(err, "error parsing burst of limit %q", s)
+		}
+		if burst < 1 {
+			return Limit{}, false, errors.Errorf("invalid burst of limit %q", s)
+		}
+	}
+
+	return Limit{
+		Rate:  rate,
+		Burst: burst,
+	}, true, nil
+}
+
+type route struct {
+	name   string
+	prefix string
+	limit  Limit
+}
+
+type middleware struct {
+	next     http.Handler
+	routes   []route
+	requests metrics.CounterVec
+	logger   log.Logger
+	errors   errs.Error
+
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+// MiddlewareOption defines a option for configuring the middleware.
+type MiddlewareOption func(*middleware)
+
+// WithRoute limits the requests with a path that has the prefix. The name of
+// the route is used to report the metrics of the route. Every client gets a
+// bucket per route, so that hammering one route doesn't affect another.
+func WithRoute(name, prefix string, limit Limit) MiddlewareOption {
+	return func(m *middleware) {
+		m.routes = append(m.routes, route{
+			name:   name,
+			prefix: prefix,
+			limit:  limit,
+		})
+	}
+}
+
+// WithMetrics reports every request that is limited by a route to the
+// counter, partitioned by route and result (allowed, limited).
+func WithMetrics(requests metrics.CounterVec) MiddlewareOption {
+	return func(m *middleware) {
+		m.requests = requests
+	}
+}
+
+// NewMiddleware creates a http.Handler that rate limits every request, before
+// passing the request on to the next handler. Requests are limited per route
+// and per client, where the client is the authenticated principal or the
+// remote IP when there isn't one. Requests that don't match a route aren't
+// limited.
+func NewMiddleware(next http.Handler, logger log.Logger, opts ...MiddlewareOption) http.Handler {
+	m := &middleware{
+		next:    next,
+		logger:  logger,
+		errors:  errs.NewError(logger),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, ok := m.match(r)
+	if !ok {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	client := clientKey(r)
+	allowed, remaining, reset, retry := m.take(route, client)
+
+	w.Header().Set(HTTPHeaderRateLimitLimit, strconv.Itoa(route.limit.Burst))
+	w.Header().Set(HTTPHeaderRateLimitRemaining, strconv.Itoa(remaining))
+	w.Header().Set(HTTPHeaderRateLimitReset, strconv.Itoa(seconds(reset)))
+
+	if !allowed {
+		m.observe(route, "limited")
+
+		level.Warn(m.logger).Log("route", route.name, "client", client)
+		w.Header().Set(HTTPHeaderRetryAfter, strconv.Itoa(seconds(retry)))
+		m.errors.TooManyRequests(w, r, fmt.Sprintf("rate limit exceeded for %s, retry in %d seconds", route.name, seconds(retry)))
+		return
+	}
+
+	m.observe(route, "allowed")
+	m.next.ServeHTTP(w, r)
+}
+
+func (m *middleware) match(r *http.Request) (route, bool) {
+	for _, route := range m.routes {
+		if strings.HasPrefix(r.URL.Path, route.prefix) {
+			return route, true
+		}
+	}
+	return route{}, false
+}
+
+// take takes a token from the bucket of the client for the route, returning
+// if the request is allowed, the tokens remaining, how long until the bucket
+// is full and how long until the next token if the request isn't allowed.
+func (m *middleware) take(route route, client string) (bool, int, time.Duration, time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	index := route.name + ":" + client
+	b, ok := m.buckets[index]
+	if !ok {
+		b = newBucket(route.limit, now)
+		m.buckets[index] = b
+	}
+
+	allowed, retry := b.take(now)
+	return allowed, b.remaining(), b.reset(), retry
+}
+
+// sweep forgets about the buckets that have filled up again, so that clients
+// that have gone away don't keep using memory.
+func (m *middleware) sweep(now time.Time) {
+	if now.Sub(m.swept) < defaultSweepInterval {
+		return
+	}
+	m.swept = now
+
+	for index, b := range m.buckets {
+		if b.full(now) {
+			delete(m.buckets, index)
+		}
+	}
+}
+
+func (m *middleware) observe(route route, result string) {
+	if m.requests == nil {
+		return
+	}
+	m.requests.WithLabelValues(route.name, result).Inc()
+}
+
+// clientKey returns the key that identifies the client of the request, which
+// is the authenticated principal or the remote IP when there isn't one.
+func clientKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.ID != "" {
+		if principal.Tenant != "" {
+			return "principal:" + principal.Tenant + ":" + principal.ID
+		}
+		return "principal:" + principal.ID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds rounds the duration up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
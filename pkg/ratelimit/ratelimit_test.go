@@ -0,0 +1,179 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/trussle/snowy/pkg/auth"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+)
+
+func TestParseLimit(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		input string
+		limit Limit
+		ok    bool
+		err   bool
+	}{
+		{"", Limit{}, false, false},
+		{"10", Limit{Rate: 10, Burst: 10}, true, false},
+		{"0.5", Limit{Rate: 0.5, Burst: 1}, true, false},
+		{"10:20", Limit{Rate: 10, Burst: 20}, true, false},
+		{"0", Limit{}, false, true},
+		{"-1:2", Limit{}, false, true},
+		{"10:0", Limit{}, false, true},
+		{"ten", Limit{}, false, true},
+		{"10:twenty", Limit{}, false, true},
+	} {
+		limit, ok, err := ParseLimit(tc.input)
+		if expected, actual := tc.err, err != nil; expected != actual {
+			t.Errorf("%q expected: %t, actual: %t", tc.input, expected, actual)
+		}
+		if expected, actual := tc.ok, ok; expected != actual {
+			t.Errorf("%q expected: %t, actual: %t", tc.input, expected, actual)
+		}
+		if expected, actual := tc.limit, limit; expected != actual {
+			t.Errorf("%q expected: %v, actual: %v", tc.input, expected, actual)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	newMiddleware := func(now time.Time, opts ...MiddlewareOption) *middleware {
+		m := NewMiddleware(next, log.NewNopLogger(), opts...).(*middleware)
+		m.now = func() time.Time { return now }
+		return m
+	}
+
+	serve := func(m http.Handler, r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("unmatched routes aren't limited", func(t *testing.T) {
+		m := newMiddleware(time.Now(), WithRoute("ledgers", "/ledgers/", Limit{Rate: 1, Burst: 1}))
+
+		for i := 0; i < 3; i++ {
+			w := serve(m, httptest.NewRequest("GET", "/status/", nil))
+			if expected, actual := http.StatusOK, w.Code; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+			if expected, actual := "", w.Header().Get(HTTPHeaderRateLimitLimit); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		}
+	})
+
+	t.Run("limits with headers", func(t *testing.T) {
+		m := newMiddleware(time.Now(), WithRoute("contents", "/contents/", Limit{Rate: 0.5, Burst: 2}))
+
+		for _, remaining := range []string{"1", "0"} {
+			w := serve(m, httptest.NewRequest("POST", "/contents/multiple/", nil))
+			if expected, actual := http.StatusOK, w.Code; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+			if expected, actual := "2", w.Header().Get(HTTPHeaderRateLimitLimit); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+			if expected, actual := remaining, w.Header().Get(HTTPHeaderRateLimitRemaining); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		}
+
+		w := serve(m, httptest.NewRequest("POST", "/contents/multiple/", nil))
+		if expected, actual := http.StatusTooManyRequests, w.Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "2", w.Header().Get(HTTPHeaderRetryAfter); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "4", w.Header().Get(HTTPHeaderRateLimitReset); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("clients and routes have their own buckets", func(t *testing.T) {
+		m := newMiddleware(time.Now(),
+			WithRoute("ledgers", "/ledgers/", Limit{Rate: 1, Burst: 1}),
+			WithRoute("journals", "/journals/", Limit{Rate: 1, Burst: 1}),
+		)
+
+		request := func(path, remoteAddr string, principal *auth.Principal) *http.Request {
+			r := httptest.NewRequest("GET", path, nil)
+			r.RemoteAddr = remoteAddr
+			if principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), *principal))
+			}
+			return r
+		}
+
+		for _, tc := range []struct {
+			name string
+			r    *http.Request
+			code int
+		}{
+			{"first ip", request("/ledgers/", "10.0.0.1:1234", nil), http.StatusOK},
+			{"first ip other port", request("/ledgers/", "10.0.0.1:5678", nil), http.StatusTooManyRequests},
+			{"first ip other route", request("/journals/", "10.0.0.1:1234", nil), http.StatusOK},
+			{"second ip", request("/ledgers/", "10.0.0.2:1234", nil), http.StatusOK},
+			{"principal", request("/ledgers/", "10.0.0.1:1234", &auth.Principal{ID: "alice"}), http.StatusOK},
+			{"principal again", request("/ledgers/", "10.0.0.3:1234", &auth.Principal{ID: "alice"}), http.StatusTooManyRequests},
+			{"principal in tenant", request("/ledgers/", "10.0.0.1:1234", &auth.Principal{ID: "alice", Tenant: "acme"}), http.StatusOK},
+		} {
+			w := serve(m, tc.r)
+			if expected, actual := tc.code, w.Code; expected != actual {
+				t.Errorf("%s expected: %d, actual: %d", tc.name, expected, actual)
+			}
+		}
+	})
+
+	t.Run("sweeps full buckets", func(t *testing.T) {
+		now := time.Now()
+		m := newMiddleware(now, WithRoute("ledgers", "/ledgers/", Limit{Rate: 1, Burst: 1}))
+
+		serve(m, httptest.NewRequest("GET", "/ledgers/", nil))
+		if expected, actual := 1, len(m.buckets); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		m.now = func() time.Time { return now.Add(2 * defaultSweepInterval) }
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		r.RemoteAddr = "10.0.0.9:1234"
+		serve(m, r)
+		if expected, actual := 1, len(m.buckets); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			requests = metricMocks.NewMockCounterVec(ctrl)
+			allowed  = prometheus.NewCounter(prometheus.CounterOpts{Name: "allowed"})
+			limited  = prometheus.NewCounter(prometheus.CounterOpts{Name: "limited"})
+			m        = newMiddleware(time.Now(), WithRoute("ledgers", "/ledgers/", Limit{Rate: 1, Burst: 1}), WithMetrics(requests))
+		)
+
+		requests.EXPECT().WithLabelValues("ledgers", "allowed").Return(allowed).Times(1)
+		requests.EXPECT().WithLabelValues("ledgers", "limited").Return(limited).Times(1)
+
+		serve(m, httptest.NewRequest("GET", "/ledgers/", nil))
+		serve(m, httptest.NewRequest("GET", "/ledgers/", nil))
+	})
+}
@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/fsys"
+)
+
+const (
+	// addressLength is the length of a hex encoded SHA-256 address.
+	addressLength = sha256.Size * 2
+
+	// blobTenantsDir is the directory that the blobs of each tenant are kept
+	// under, so that no tenant can see the blobs of another.
+	blobTenantsDir = "tenants"
+)
+
+// BlobOptions holds the meta data associated with a blob when it's put into
+// the BlobStore. If a ContentEncoding is supplied, the content is encoded
+// before being written and if a Cipher is supplied the (encoded) content is
+// then encrypted. The address is always computed over the original content.
+type BlobOptions struct {
+	ContentType     string
+	ContentEncoding string
+	Cipher          Cipher
+}
+
+// BlobInfo describes a blob with in the BlobStore. Size is the size of the
+// content before it was encoded and StoredSize is the size of the content as
+// it is stored.
+type BlobInfo struct {
+	Address         string
+	Size            int64
+	StoredSize      int64
+	ContentType     string
+	ContentEncoding string
+	Encrypted       bool
+}
+
+// Blob is a readable blob from the BlobStore, the caller is expected to close
+// the blob once finished with it. Reading a blob yields the content as it is
+// stored, which is encoded using the Info().ContentEncoding and encrypted if
+// Info().Encrypted is set.
+type Blob interface {
+	io.ReadCloser
+
+	// Info returns the information about the blob.
+	Info() BlobInfo
+}
+
+// BlobStore is a content-addressed store of immutable blobs. The address of
+// each blob is computed from the blob contents, so putting the same content
+// twice yields the same address.
+type BlobStore interface {
+
+	// Put consumes the reader and stores the content, returning the address
+	// of the content. If the content already exists, then the content is not
+	// written again.
+	Put(reader io.Reader, options BlobOptions) (string, error)
+
+	// Get returns the blob corresponding to the address. If no blob exists it
+	// will return a not found error.
+	Get(address string) (Blob, error)
+
+	// Stat returns the information about the blob corresponding to the
+	// address. If no blob exists it will return a not found error.
+	Stat(address string) (BlobInfo, error)
+
+	// Delete removes the blob corresponding to the address. If no blob exists
+	// it will return a not found error.
+	Delete(address string) error
+
+	// List walks all the blobs with in the store, calling fn for each blob. If
+	// fn returns an error, the walk is stopped and the error is returned.
+	List(fn func(BlobInfo) error) error
+
+	// Tenant returns a BlobStore that keeps the blobs of the tenant apart from
+	// every other tenant. The empty tenant is the store itself.
+	Tenant(tenantID string) BlobStore
+}
+
+// BlobConfig encapsulates the requirements for generating a BlobStore
+type BlobConfig struct {
+	name       string
+	filesystem fsys.Filesystem
+	root       string
+}
+
+// BlobOption defines a option for generating a BlobConfig
+type BlobOption func(*BlobConfig) error
+
+// BuildBlobConfig ingests configuration options to then yield a BlobConfig and
+// return an error if it fails during setup.
+func BuildBlobConfig(opts ...BlobOption) (*BlobConfig, error) {
+	var config BlobConfig
+	for _, opt := range opts {
+		err := opt(&config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// WithBlobStore adds a type of blob store to use for the configuration.
+func WithBlobStore(name string) BlobOption {
+	return func(config *BlobConfig) error {
+		config.name = name
+		return nil
+	}
+}
+
+// WithBlobFilesystem adds a filesystem to use for the filesystem blob store.
+func WithBlobFilesystem(fs fsys.Filesystem) BlobOption {
+	return func(config *BlobConfig) error {
+		config.filesystem = fs
+		return nil
+	}
+}
+
+// WithBlobRoot adds a root directory to use for the local blob store.
+func WithBlobRoot(root string) BlobOption {
+	return func(config *BlobConfig) error {
+		config.root = root
+		return nil
+	}
+}
+
+// NewBlobStore creates a BlobStore from a configuration or returns error if on
+// failure.
+func NewBlobStore(config *BlobConfig) (blobs BlobStore, err error) {
+	switch strings.ToLower(config.name) {
+	case "filesystem":
+		if config.filesystem == nil {
+			return nil, errors.New("filesystem blob store requires a filesystem")
+		}
+		blobs = NewFilesystemBlobStore(config.filesystem)
+	case "local":
+		if strings.TrimSpace(config.root) == "" {
+			return nil, errors.New("local blob store requires a root directory")
+		}
+		blobs = NewLocalBlobStore(config.root)
+	default:
+		err = errors.Errorf("unexpected blob store type %q", config.name)
+	}
+	return
+}
+
+// ValidAddress checks to see if the address is a valid content address.
+func ValidAddress(address string) bool {
+	if len(address) != addressLength {
+		return false
+	}
+	_, err := hex.DecodeString(address)
+	return err == nil && strings.ToLower(address) == address
+}
+
+func validateAddress(address string) error {
+	if !ValidAddress(address) {
+		return errors.Errorf("invalid address %q", address)
+	}
+	return nil
+}
+
+// blobMeta is the meta data of a blob. Blobs carry their meta data in a
+// header, the blobs stored before the header existed have it stored alongside
+// them instead.
+type blobMeta struct {
+	ContentType     string `json:"content_type"`
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	Encrypted       bool   `json:"encrypted,omitempty"`
+	Size            int64  `json:"size,omitempty"`
+}
+
+// blobMagic starts the header of every blob, which tells them apart from the
+// blobs without a header.
+const blobMagic = "snowy\x00b1"
+
+// The header is the magic, the size of the content before it was encoded, the
+// length of the meta data and then the meta data encoded as JSON.
+const (
+	blobSizeOffset   = int64(len(blobMagic))
+	blobHeaderLength = blobSizeOffset + 8 + 4
+)
+
+// writeBlob writes the header followed by the content of the reader, encoded
+// according to the options, to the file. The size is only known once all the
+// content has been written, so it's filled in to the header last. Writing the
+// meta data in to the blob means that the blob can be published in one step,
+// without a window where it's visible with out its meta data.
+func writeBlob(file *os.File, reader io.Reader, options BlobOptions) (string, error) {
+	meta, err := json.Marshal(blobMeta{
+		ContentType:     options.ContentType,
+		ContentEncoding: options.ContentEncoding,
+		Encrypted:       options.Cipher != nil,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	header := make([]byte, blobHeaderLength, blobHeaderLength+int64(len(meta)))
+	copy(header, blobMagic)
+	binary.BigEndian.PutUint32(header[blobSizeOffset+8:], uint32(len(meta)))
+	if _, err = file.Write(append(header, meta...)); err != nil {
+		return "", err
+	}
+
+	encoder, err := newBlobWriter(file, options)
+	if err != nil {
+		return "", err
+	}
+
+	hw := newHashingWriter()
+	if _, err = io.Copy(io.MultiWriter(encoder, hw), reader); err != nil {
+		return "", err
+	}
+	if err = encoder.Close(); err != nil {
+		return "", err
+	}
+
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(hw.Size()))
+	if _, err = file.WriteAt(size, blobSizeOffset); err != nil {
+		return "", err
+	}
+	if err = file.Sync(); err != nil {
+		return "", err
+	}
+	return hw.Address(), nil
+}
+
+// readBlobHeader reads the header of a blob from the reader, returning the
+// meta data and the length of the header. If the blob doesn't start with a
+// header, then false is returned along with the bytes that were read, so that
+// they can be read again as part of the content.
+func readBlobHeader(r io.Reader) (meta blobMeta, length int64, read []byte, ok bool, err error) {
+	header := make([]byte, blobHeaderLength)
+	n, err := io.ReadFull(r, header)
+	if err == io.EOF || err == io.ErrUnexpectedEOF || (err == nil && string(header[:blobSizeOffset]) != blobMagic) {
+		return blobMeta{}, 0, header[:n], false, nil
+	} else if err != nil {
+		return blobMeta{}, 0, nil, false, err
+	}
+
+	bytes := make([]byte, binary.BigEndian.Uint32(header[blobSizeOffset+8:]))
+	if _, err = io.ReadFull(r, bytes); err != nil {
+		return blobMeta{}, 0, nil, false, errors.Wrap(err, "unable to read header")
+	}
+	if err = json.Unmarshal(bytes, &meta); err != nil {
+		return blobMeta{}, 0, nil, false, errors.Wrap(err, "unable to read header")
+	}
+	meta.Size = int64(binary.BigEndian.Uint64(header[blobSizeOffset:]))
+	return meta, blobHeaderLength + int64(len(bytes)), nil, true, nil
+}
+
+// newBlobWriter wraps the writer, so that everything written is encoded and
+// then encrypted according to the options.
+func newBlobWriter(w io.Writer, options BlobOptions) (io.WriteCloser, error) {
+	if options.Cipher == nil {
+		return newEncoder(w, options.ContentEncoding)
+	}
+
+	encrypter, err := options.Cipher.Encrypt(w)
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := newEncoder(encrypter, options.ContentEncoding)
+	if err != nil {
+		return nil, err
+	}
+	return multiCloser{encoder, encrypter}, nil
+}
+
+// multiCloser writes to the first writer and closes all the writers in order.
+type multiCloser []io.WriteCloser
+
+func (m multiCloser) Write(p []byte) (int, error) {
+	return m[0].Write(p)
+}
+
+func (m multiCloser) Close() error {
+	for _, v := range m {
+		if err := v.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashingWriter computes the content address of everything written to it.
+type hashingWriter struct {
+	hash hash.Hash
+	size int64
+}
+
+func newHashingWriter() *hashingWriter {
+	return &hashingWriter{
+		hash: sha256.New(),
+	}
+}
+
+func (w *hashingWriter) Write(p []byte) (int, error) {
+	n, err := w.hash.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *hashingWriter) Address() string {
+	return hex.EncodeToString(w.hash.Sum(nil))
+}
+
+func (w *hashingWriter) Size() int64 {
+	return w.size
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/fsys"
+	"github.com/trussle/uuid"
+)
+
+const (
+	filesystemTempPrefix    = "tmp-"
+	filesystemMetaExtension = ".meta"
+)
+
+// filesystemBlobStore adapts a fsys.Filesystem into a BlobStore. Blobs are
+// stored flat by their address, which keeps the layout compatible with the
+// contents that were written before the BlobStore existed. Blobs carry their
+// meta data in a header, the encoded or encrypted blobs stored before the header
+// existed have their meta data stored next to them instead.
+type filesystemBlobStore struct {
+	fs     fsys.Filesystem
+	prefix string
+}
+
+// NewFilesystemBlobStore creates a BlobStore that backs on to a fsys
+// Filesystem implementation (local, remote, virtual, nop).
+func NewFilesystemBlobStore(fs fsys.Filesystem) BlobStore {
+	return &filesystemBlobStore{
+		fs: fs,
+	}
+}
+
+// Put consumes the reader, writing it to a local temporary file first, as the
+// size of the content is only known once all of it has been read. The blob is
+// then copied to a temporary file in the filesystem, before it's renamed to
+// the content address. Every blob carries its own meta data, so a concurrent
+// put of the same content replacing the blob always leaves a whole blob.
+func (s *filesystemBlobStore) Put(reader io.Reader, options BlobOptions) (address string, err error) {
+	var spool *os.File
+	if spool, err = ioutil.TempFile("", "snowy-blob-"); err != nil {
+		return
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	if address, err = writeBlob(spool, reader, options); err != nil {
+		return
+	}
+
+	// Content already exists, so throw away what we've just written.
+	if s.fs.Exists(s.path(address)) {
+		return
+	}
+
+	if _, err = spool.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+
+	var id uuid.UUID
+	if id, err = uuid.New(); err != nil {
+		return
+	}
+
+	tmpPath := s.path(filesystemTempPrefix + id.String())
+
+	var file fsys.File
+	file, err = s.fs.Create(tmpPath)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			s.fs.Remove(tmpPath)
+		}
+	}()
+
+	if err = file.SetContentType(options.ContentType); err != nil {
+		file.Close()
+		return
+	}
+	if _, err = io.Copy(file, spool); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+
+	err = s.fs.Rename(tmpPath, s.path(address))
+	return
+}
+
+// Get returns the blob corresponding to the address.
+func (s *filesystemBlobStore) Get(address string) (Blob, error) {
+	file, err := s.fs.Open(s.path(address))
+	if err != nil {
+		if fsys.ErrNotFound(err) {
+			return nil, errNotFound{err}
+		}
+		return nil, err
+	}
+
+	size := file.Size()
+
+	meta, length, read, ok, err := readBlobHeader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	info := BlobInfo{
+		Address:         address,
+		Size:            meta.Size,
+		StoredSize:      size - length,
+		ContentType:     meta.ContentType,
+		ContentEncoding: meta.ContentEncoding,
+		Encrypted:       meta.Encrypted,
+	}
+	if ok {
+		return &filesystemBlob{
+			Reader: file,
+			Closer: file,
+			info:   info,
+		}, nil
+	}
+
+	// The blob doesn't have a header, so it's either identity encoded or has
+	// its meta data stored alongside it.
+	legacy, err := s.getMeta(address)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	info.Size = size
+	info.ContentType = file.ContentType()
+	if legacy != nil {
+		info.Size = legacy.Size
+		info.ContentEncoding = legacy.ContentEncoding
+		info.Encrypted = legacy.Encrypted
+	}
+
+	return &filesystemBlob{
+		Reader: io.MultiReader(bytes.NewReader(read), file),
+		Closer: file,
+		info:   info,
+	}, nil
+}
+
+// Stat returns the information about the blob corresponding to the address.
+func (s *filesystemBlobStore) Stat(address string) (BlobInfo, error) {
+	blob, err := s.Get(address)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	defer blob.Close()
+
+	return blob.Info(), nil
+}
+
+// Delete removes the blob corresponding to the address.
+func (s *filesystemBlobStore) Delete(address string) error {
+	if !s.fs.Exists(s.path(address)) {
+		return errNotFound{os.ErrNotExist}
+	}
+	if metaPath := s.path(address + filesystemMetaExtension); s.fs.Exists(metaPath) {
+		if err := s.fs.Remove(metaPath); err != nil {
+			return err
+		}
+	}
+	return s.fs.Remove(s.path(address))
+}
+
+// List walks all the blobs with in the filesystem, skipping the blobs of any
+// other tenant.
+func (s *filesystemBlobStore) List(fn func(BlobInfo) error) error {
+	return s.fs.Walk("", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info != nil && info.IsDir() {
+			return nil
+		}
+
+		path = filepath.ToSlash(path)
+		if s.prefix == "" && strings.HasPrefix(path, blobTenantsDir+"/") {
+			return nil
+		}
+		if !strings.HasPrefix(path, s.prefix) {
+			return nil
+		}
+
+		address := filepath.Base(path)
+		if !ValidAddress(address) {
+			return nil
+		}
+
+		blobInfo, err := s.Stat(address)
+		if err != nil {
+			return err
+		}
+		return fn(blobInfo)
+	})
+}
+
+// Tenant returns a BlobStore that keeps the blobs of the tenant under the
+// "tenants/<tenant>/" prefix of the filesystem.
+func (s *filesystemBlobStore) Tenant(tenantID string) BlobStore {
+	if tenantID == "" {
+		return s
+	}
+	return &filesystemBlobStore{
+		fs:     s.fs,
+		prefix: s.prefix + blobTenantsDir + "/" + tenantID + "/",
+	}
+}
+
+// path returns the path of the name with in the filesystem.
+func (s *filesystemBlobStore) path(name string) string {
+	return s.prefix + name
+}
+
+// getMeta returns the meta data for the address, if there is no meta data then
+// it returns nil.
+func (s *filesystemBlobStore) getMeta(address string) (*blobMeta, error) {
+	metaPath := s.path(address + filesystemMetaExtension)
+	if !s.fs.Exists(metaPath) {
+		return nil, nil
+	}
+
+	file, err := s.fs.Open(metaPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var meta blobMeta
+	if err := json.NewDecoder(file).Decode(&meta); err != nil {
+		return nil, errors.Wrap(err, "unable to read meta data")
+	}
+	return &meta, nil
+}
+
+type filesystemBlob struct {
+	io.Reader
+	io.Closer
+	info BlobInfo
+}
+
+func (b *filesystemBlob) Info() BlobInfo {
+	return b.info
+}
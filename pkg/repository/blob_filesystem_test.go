@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/trussle/fsys"
+	"github.com/trussle/snowy/pkg/models"
+)
+
+func TestFilesystemBlobStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("put then get", func(t *testing.T) {
+		fn := func(body []byte, contentType string) bool {
+			blobs := NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+
+			address, err := blobs.Put(bytes.NewReader(body), BlobOptions{
+				ContentType: contentType,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := models.ContentAddress(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := want, address; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+
+			blob, err := blobs.Get(address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer blob.Close()
+
+			b, err := ioutil.ReadAll(blob)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info := blob.Info()
+			return reflect.DeepEqual(b, body) &&
+				info.Address == address &&
+				info.Size == int64(len(body)) &&
+				info.ContentType == contentType
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("put stores flat by address", func(t *testing.T) {
+		fs := fsys.NewVirtualFilesystem()
+
+		address, err := NewFilesystemBlobStore(fs).Put(bytes.NewReader([]byte("hello")), BlobOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := true, fs.Exists(address); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("put same content twice", func(t *testing.T) {
+		blobs := NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+
+		fn := func(body []byte) bool {
+			a, err := blobs.Put(bytes.NewReader(body), BlobOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := blobs.Put(bytes.NewReader(body), BlobOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return a == b
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("get blob without header", func(t *testing.T) {
+		fs := fsys.NewVirtualFilesystem()
+		body := []byte("hello")
+
+		address, err := models.ContentAddress(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		file, err := fs.Create(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = file.SetContentType("text/plain"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = file.Write(body); err != nil {
+			t.Fatal(err)
+		}
+		if err = file.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		blob, err := NewFilesystemBlobStore(fs).Get(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer blob.Close()
+
+		b, err := ioutil.ReadAll(blob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := body, b; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		want := BlobInfo{
+			Address:     address,
+			Size:        5,
+			StoredSize:  5,
+			ContentType: "text/plain",
+		}
+		if expected, actual := want, blob.Info(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		_, err := NewFilesystemBlobStore(fsys.NewVirtualFilesystem()).Get("missing")
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		blobs := NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+
+		address, err := blobs.Put(bytes.NewReader([]byte("hello")), BlobOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = blobs.Delete(address); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = blobs.Stat(address)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		blobs := NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+
+		want := make(map[string]struct{})
+		for _, body := range []string{"a", "b", "c"} {
+			address, err := blobs.Put(bytes.NewReader([]byte(body)), BlobOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want[address] = struct{}{}
+		}
+
+		got := make(map[string]struct{})
+		if err := blobs.List(func(info BlobInfo) error {
+			got[info.Address] = struct{}{}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := want, got; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("tenant", func(t *testing.T) {
+		var (
+			blobs  = NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+			tenant = blobs.Tenant("acme")
+		)
+
+		a, err := blobs.Put(bytes.NewReader([]byte("a")), BlobOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := tenant.Put(bytes.NewReader([]byte("b")), BlobOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = blobs.Stat(b)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		_, err = tenant.Stat(a)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := blobs, blobs.Tenant(""); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		for store, want := range map[BlobStore]string{blobs: a, tenant: b} {
+			var got []string
+			if err := store.List(func(info BlobInfo) error {
+				got = append(got, info.Address)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := []string{want}, got; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+	})
+}
+
+func TestNewBlobStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("filesystem", func(t *testing.T) {
+		config, err := BuildBlobConfig(
+			WithBlobStore("filesystem"),
+			WithBlobFilesystem(fsys.NewVirtualFilesystem()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = NewBlobStore(config); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("local", func(t *testing.T) {
+		config, err := BuildBlobConfig(
+			WithBlobStore("local"),
+			WithBlobRoot("blobs"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = NewBlobStore(config); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("local without root", func(t *testing.T) {
+		config, err := BuildBlobConfig(
+			WithBlobStore("local"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = NewBlobStore(config)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		config, err := BuildBlobConfig(
+			WithBlobStore("invalid"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = NewBlobStore(config)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	localTempDir       = "tmp"
+	localMetaExtension = ".meta"
+	localShardLength   = 2
+	localShardDepth    = 2
+)
+
+// localBlobStore stores blobs on the local disk, sharding the directories by
+// the prefix of the address, so that no single directory grows too large.
+// A blob with the address "abcdef..." is stored as "<root>/ab/cd/abcdef...".
+// Blobs stored before they carried a header have their meta data stored as
+// "<root>/ab/cd/abcdef....meta".
+type localBlobStore struct {
+	root string
+}
+
+// NewLocalBlobStore creates a BlobStore that stores blobs on the local disk
+// under the root directory.
+func NewLocalBlobStore(root string) BlobStore {
+	return &localBlobStore{
+		root: root,
+	}
+}
+
+// Put consumes the reader, writing it to a temporary file first. Once all the
+// content has been written and synced, the file is linked to the content
+// address. Linking fails if the address already exists, so only one put of the
+// same content publishes its blob and partially written blobs are never
+// visible.
+func (s *localBlobStore) Put(reader io.Reader, options BlobOptions) (address string, err error) {
+	tmpDir := filepath.Join(s.root, localTempDir)
+	if err = os.MkdirAll(tmpDir, 0755); err != nil {
+		return
+	}
+
+	var file *os.File
+	file, err = ioutil.TempFile(tmpDir, "blob-")
+	if err != nil {
+		return
+	}
+	defer func() {
+		// Clean up the temporary file, as it's either been linked or thrown
+		// away.
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	if address, err = writeBlob(file, reader, options); err != nil {
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+
+	dir := s.dir(address)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+
+	if err = os.Link(file.Name(), s.path(address)); err != nil {
+		// Content already exists, so nothing else is required.
+		if os.IsExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	err = syncDir(dir)
+	return
+}
+
+// Get returns the blob corresponding to the address.
+func (s *localBlobStore) Get(address string) (Blob, error) {
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(s.path(address))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errNotFound{err}
+		}
+		return nil, err
+	}
+
+	info, err := s.info(address, file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &localBlob{
+		File: file,
+		info: info,
+	}, nil
+}
+
+// Stat returns the information about the blob corresponding to the address.
+func (s *localBlobStore) Stat(address string) (BlobInfo, error) {
+	blob, err := s.Get(address)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	defer blob.Close()
+
+	return blob.Info(), nil
+}
+
+// info reads the information about the blob from its header, leaving the file
+// at the start of the content. Blobs without a header have their meta data
+// read from the meta file alongside them.
+func (s *localBlobStore) info(address string, file *os.File) (BlobInfo, error) {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return BlobInfo{}, err
+	}
+
+	meta, length, _, ok, err := readBlobHeader(file)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	if !ok {
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return BlobInfo{}, err
+		}
+
+		meta = blobMeta{
+			Size: fileInfo.Size(),
+		}
+		if bytes, err := ioutil.ReadFile(s.path(address) + localMetaExtension); err == nil {
+			if err = json.Unmarshal(bytes, &meta); err != nil {
+				return BlobInfo{}, errors.Wrap(err, "unable to read meta data")
+			}
+		} else if !os.IsNotExist(err) {
+			return BlobInfo{}, err
+		}
+	}
+
+	return BlobInfo{
+		Address:         address,
+		Size:            meta.Size,
+		StoredSize:      fileInfo.Size() - length,
+		ContentType:     meta.ContentType,
+		ContentEncoding: meta.ContentEncoding,
+		Encrypted:       meta.Encrypted,
+	}, nil
+}
+
+// Delete removes the blob corresponding to the address.
+func (s *localBlobStore) Delete(address string) error {
+	if err := validateAddress(address); err != nil {
+		return err
+	}
+
+	path := s.path(address)
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return errNotFound{err}
+		}
+		return err
+	}
+	if err := os.Remove(path + localMetaExtension); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return syncDir(s.dir(address))
+}
+
+// List walks all the blobs with in the store, skipping the blobs of the
+// tenants.
+func (s *localBlobStore) List(fn func(BlobInfo) error) error {
+	var (
+		tmpDir     = filepath.Join(s.root, localTempDir)
+		tenantsDir = filepath.Join(s.root, blobTenantsDir)
+	)
+	err := filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path == tmpDir || path == tenantsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		address := filepath.Base(path)
+		if !ValidAddress(address) {
+			return nil
+		}
+
+		blobInfo, err := s.Stat(address)
+		if err != nil {
+			return err
+		}
+		return fn(blobInfo)
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Tenant returns a BlobStore that keeps the blobs of the tenant under the
+// "<root>/tenants/<tenant>" directory.
+func (s *localBlobStore) Tenant(tenantID string) BlobStore {
+	if tenantID == "" {
+		return s
+	}
+	return NewLocalBlobStore(filepath.Join(s.root, blobTenantsDir, tenantID))
+}
+
+func (s *localBlobStore) dir(address string) string {
+	parts := []string{s.root}
+	for i := 0; i < localShardDepth; i++ {
+		offset := i * localShardLength
+		parts = append(parts, address[offset:offset+localShardLength])
+	}
+	return filepath.Join(parts...)
+}
+
+func (s *localBlobStore) path(address string) string {
+	return filepath.Join(s.dir(address), address)
+}
+
+type localBlob struct {
+	*os.File
+	info BlobInfo
+}
+
+func (b *localBlob) Info() BlobInfo {
+	return b.info
+}
+
+// syncDir makes sure that any links or removals with in the directory are
+// persisted.
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
@@ -0,0 +1,356 @@
+package repository
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/quick"
+
+	"github.com/trussle/snowy/pkg/models"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("put then get", func(t *testing.T) {
+		fn := func(body []byte, contentType string) bool {
+			root, cleanup := tempDir(t)
+			defer cleanup()
+
+			blobs := NewLocalBlobStore(root)
+
+			address, err := blobs.Put(bytes.NewReader(body), BlobOptions{
+				ContentType: contentType,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := models.ContentAddress(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := want, address; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+
+			blob, err := blobs.Get(address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer blob.Close()
+
+			b, err := ioutil.ReadAll(blob)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info := blob.Info()
+			return reflect.DeepEqual(b, body) &&
+				info.Address == address &&
+				info.Size == int64(len(body)) &&
+				info.ContentType == contentType
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("put shards by address", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		blobs := NewLocalBlobStore(root)
+
+		address, err := blobs.Put(bytes.NewReader([]byte("hello")), BlobOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path := filepath.Join(root, address[0:2], address[2:4], address)
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+
+		files, err := ioutil.ReadDir(filepath.Join(root, localTempDir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(files); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("put same content twice", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		blobs := NewLocalBlobStore(root)
+
+		fn := func(body []byte) bool {
+			a, err := blobs.Put(bytes.NewReader(body), BlobOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := blobs.Put(bytes.NewReader(body), BlobOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return a == b
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("put same content concurrently", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		blobs := NewLocalBlobStore(root)
+		body := bytes.Repeat([]byte("hello"), 1024)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			options := BlobOptions{ContentType: "text/plain"}
+			if i%2 == 0 {
+				options.ContentEncoding = EncodingGzip
+			}
+
+			wg.Add(1)
+			go func(options BlobOptions) {
+				defer wg.Done()
+				if _, err := blobs.Put(bytes.NewReader(body), options); err != nil {
+					t.Error(err)
+				}
+			}(options)
+		}
+		wg.Wait()
+
+		address, err := models.ContentAddress(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		blob, err := blobs.Get(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := newDecoder(blob, blob.Info().ContentEncoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer decoded.Close()
+
+		b, err := ioutil.ReadAll(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := body, b; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %d bytes, actual: %d bytes", len(expected), len(actual))
+		}
+	})
+
+	t.Run("get blob without header", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		blobs := NewLocalBlobStore(root)
+		body := []byte("hello")
+
+		address, err := models.ContentAddress(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path := filepath.Join(root, address[0:2], address[2:4], address)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path, body, 0644); err != nil {
+			t.Fatal(err)
+		}
+		meta := []byte(`{"content_type":"text/plain","size":5}`)
+		if err = ioutil.WriteFile(path+localMetaExtension, meta, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		blob, err := blobs.Get(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer blob.Close()
+
+		b, err := ioutil.ReadAll(blob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := body, b; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		want := BlobInfo{
+			Address:     address,
+			Size:        5,
+			StoredSize:  5,
+			ContentType: "text/plain",
+		}
+		if expected, actual := want, blob.Info(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("get with invalid address", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		_, err := NewLocalBlobStore(root).Get("../../etc/passwd")
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		address, err := models.ContentAddress([]byte("missing"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = NewLocalBlobStore(root).Get(address)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		blobs := NewLocalBlobStore(root)
+
+		address, err := blobs.Put(bytes.NewReader([]byte("hello")), BlobOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = blobs.Delete(address); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = blobs.Stat(address)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		err = blobs.Delete(address)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		blobs := NewLocalBlobStore(root)
+
+		want := make(map[string]struct{})
+		for _, body := range []string{"a", "b", "c"} {
+			address, err := blobs.Put(bytes.NewReader([]byte(body)), BlobOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want[address] = struct{}{}
+		}
+
+		got := make(map[string]struct{})
+		if err := blobs.List(func(info BlobInfo) error {
+			got[info.Address] = struct{}{}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := want, got; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("list with missing root", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		blobs := NewLocalBlobStore(filepath.Join(root, "missing"))
+		err := blobs.List(func(info BlobInfo) error {
+			t.Errorf("unexpected blob %q", info.Address)
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("tenant", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		var (
+			blobs  = NewLocalBlobStore(root)
+			tenant = blobs.Tenant("acme")
+		)
+
+		a, err := blobs.Put(bytes.NewReader([]byte("a")), BlobOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := tenant.Put(bytes.NewReader([]byte("b")), BlobOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = blobs.Stat(b)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		_, err = tenant.Stat(a)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := blobs, blobs.Tenant(""); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		for store, want := range map[BlobStore]string{blobs: a, tenant: b} {
+			var got []string
+			if err := store.List(func(info BlobInfo) error {
+				got = append(got, info.Address)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := []string{want}, got; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+	})
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "snowy-blobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() {
+		os.RemoveAll(dir)
+	}
+}
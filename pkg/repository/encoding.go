@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// These are the content encodings that are supported when storing content.
+const (
+	EncodingIdentity = ""
+	EncodingGzip     = "gzip"
+	EncodingZstd     = "zstd"
+)
+
+// Compression describes how content should be compressed at rest. Only content
+// with a content type that matches the allow list is compressed.
+type Compression struct {
+	Encoding     string
+	ContentTypes []string
+}
+
+// CompressionOption defines a option for generating a Compression
+type CompressionOption func(*Compression) error
+
+// BuildCompression ingests configuration options to then yield a Compression
+// and return an error if it fails during setup.
+func BuildCompression(opts ...CompressionOption) (Compression, error) {
+	var config Compression
+	for _, opt := range opts {
+		err := opt(&config)
+		if err != nil {
+			return Compression{}, err
+		}
+	}
+	return config, nil
+}
+
+// WithCompressionEncoding adds the encoding to use when compressing content.
+// Accepted values are "none", "gzip" and "zstd".
+func WithCompressionEncoding(encoding string) CompressionOption {
+	return func(config *Compression) error {
+		switch e := strings.ToLower(strings.TrimSpace(encoding)); e {
+		case "", "none", "identity":
+			config.Encoding = EncodingIdentity
+		case EncodingGzip, EncodingZstd:
+			config.Encoding = e
+		default:
+			return errors.Errorf("unexpected compression encoding %q", encoding)
+		}
+		return nil
+	}
+}
+
+// WithCompressionContentTypes adds the allow list of content types that should
+// be compressed. A content type can be a wildcard, for example "text/*".
+func WithCompressionContentTypes(contentTypes []string) CompressionOption {
+	return func(config *Compression) error {
+		config.ContentTypes = nil
+		for _, v := range contentTypes {
+			if s := strings.ToLower(strings.TrimSpace(v)); s != "" {
+				config.ContentTypes = append(config.ContentTypes, s)
+			}
+		}
+		return nil
+	}
+}
+
+// EncodingFor returns the encoding that should be used for the content type.
+func (c Compression) EncodingFor(contentType string) string {
+	if c.Encoding == EncodingIdentity {
+		return EncodingIdentity
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return EncodingIdentity
+	}
+
+	for _, v := range c.ContentTypes {
+		if v == mediaType || v == "*/*" {
+			return c.Encoding
+		}
+		if strings.HasSuffix(v, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(v, "*")) {
+			return c.Encoding
+		}
+	}
+	return EncodingIdentity
+}
+
+// ParseAcceptEncoding parses a Accept-Encoding header, returning all the
+// encodings that are acceptable to the client.
+func ParseAcceptEncoding(header string) []string {
+	var res []string
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+
+		encoding := strings.ToLower(strings.TrimSpace(params[0]))
+		if encoding == "" {
+			continue
+		}
+
+		accepted := true
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil && q <= 0 {
+				accepted = false
+			}
+		}
+		if accepted {
+			res = append(res, encoding)
+		}
+	}
+	return res
+}
+
+func acceptsEncoding(accepted []string, encoding string) bool {
+	for _, v := range accepted {
+		if v == encoding || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// newEncoder wraps the writer so that everything written to it is encoded
+// using the encoding.
+func newEncoder(w io.Writer, encoding string) (io.WriteCloser, error) {
+	switch encoding {
+	case EncodingIdentity:
+		return nopWriteCloser{w}, nil
+	case EncodingGzip:
+		return gzip.NewWriter(w), nil
+	case EncodingZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, errors.Errorf("unexpected content encoding %q", encoding)
+	}
+}
+
+// newDecoder wraps the reader so that everything read from it is decoded
+// using the encoding. Closing the decoder also closes the reader.
+func newDecoder(r io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case EncodingIdentity:
+		return r, nil
+	case EncodingGzip:
+		reader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder{reader, func() error {
+			reader.Close()
+			return r.Close()
+		}}, nil
+	case EncodingZstd:
+		reader, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder{reader, func() error {
+			reader.Close()
+			return r.Close()
+		}}, nil
+	default:
+		return nil, errors.Errorf("unexpected content encoding %q", encoding)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+type decoder struct {
+	io.Reader
+	close func() error
+}
+
+func (d decoder) Close() error { return d.close() }
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+func TestBuildCompression(t *testing.T) {
+	t.Parallel()
+
+	t.Run("build", func(t *testing.T) {
+		for _, encoding := range []string{"none", "gzip", "zstd", "GZIP", ""} {
+			_, err := BuildCompression(
+				WithCompressionEncoding(encoding),
+			)
+			if err != nil {
+				t.Errorf("unexpected error for %q: %v", encoding, err)
+			}
+		}
+	})
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		_, err := BuildCompression(
+			WithCompressionEncoding("brotli"),
+		)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
+
+func TestCompressionEncodingFor(t *testing.T) {
+	t.Parallel()
+
+	compression, err := BuildCompression(
+		WithCompressionEncoding("gzip"),
+		WithCompressionContentTypes([]string{"text/*", " application/json ", ""}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for contentType, want := range map[string]string{
+		"text/plain":                      EncodingGzip,
+		"text/html; charset=utf-8":        EncodingGzip,
+		"application/json":                EncodingGzip,
+		"Application/JSON":                EncodingGzip,
+		"application/octet-stream":        EncodingIdentity,
+		"image/png":                       EncodingIdentity,
+		"":                                EncodingIdentity,
+		"application/json; charset=utf-8": EncodingGzip,
+	} {
+		if expected, actual := want, compression.EncodingFor(contentType); expected != actual {
+			t.Errorf("%q expected: %q, actual: %q", contentType, expected, actual)
+		}
+	}
+
+	t.Run("none", func(t *testing.T) {
+		compression, err := BuildCompression(
+			WithCompressionEncoding("none"),
+			WithCompressionContentTypes([]string{"*/*"}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := EncodingIdentity, compression.EncodingFor("text/plain"); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+}
+
+func TestParseAcceptEncoding(t *testing.T) {
+	t.Parallel()
+
+	for header, want := range map[string][]string{
+		"":                       nil,
+		"gzip":                   {"gzip"},
+		"gzip, deflate, br":      {"gzip", "deflate", "br"},
+		"zstd;q=1.0, gzip;q=0":   {"zstd"},
+		"GZIP ; q=0.5 , *;q=0.1": {"gzip", "*"},
+	} {
+		if expected, actual := want, ParseAcceptEncoding(header); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%q expected: %v, actual: %v", header, expected, actual)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	t.Parallel()
+
+	for _, encoding := range []string{EncodingIdentity, EncodingGzip, EncodingZstd} {
+		encoding := encoding
+		t.Run("round trip "+encoding, func(t *testing.T) {
+			fn := func(body []byte) bool {
+				buf := new(bytes.Buffer)
+				encoder, err := newEncoder(buf, encoding)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err = encoder.Write(body); err != nil {
+					t.Fatal(err)
+				}
+				if err = encoder.Close(); err != nil {
+					t.Fatal(err)
+				}
+
+				decoder, err := newDecoder(ioutil.NopCloser(buf), encoding)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer decoder.Close()
+
+				b, err := ioutil.ReadAll(decoder)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return bytes.Equal(b, body)
+			}
+
+			if err := quick.Check(fn, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		if _, err := newEncoder(new(bytes.Buffer), "bad"); err == nil {
+			t.Errorf("expected error")
+		}
+		if _, err := newDecoder(ioutil.NopCloser(new(bytes.Buffer)), "bad"); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
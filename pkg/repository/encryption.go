@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// dataKeySize is the size of the data keys, which are AES-256 keys.
+	dataKeySize = 32
+
+	encryptionChunkSize         = 64 * 1024
+	encryptionVersion      byte = 1
+	encryptionChunkPartial byte = 0
+	encryptionChunkFinal   byte = 1
+)
+
+var encryptionMagic = []byte("SNWE")
+
+// Cipher encrypts and decrypts content using a data key.
+type Cipher interface {
+
+	// Encrypt wraps the writer so that everything written to it is encrypted.
+	// The writer must be closed to write the final chunk.
+	Encrypt(w io.Writer) (io.WriteCloser, error)
+
+	// Decrypt wraps the reader so that everything read from it is decrypted.
+	// Closing the reader also closes the underlying reader.
+	Decrypt(r io.ReadCloser) (io.ReadCloser, error)
+}
+
+// NewDataKey generates a new random data key.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// NewCipher creates a Cipher that uses AES-GCM with the data key. The content
+// is split into chunks, so that content can be streamed without holding all
+// of it in memory. Each chunk is authenticated along with its position and
+// whether it is the final chunk, so chunks can't be reordered or truncated.
+func NewCipher(key []byte) (Cipher, error) {
+	if len(key) != dataKeySize {
+		return nil, errors.Errorf("invalid data key size %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aesCipher{aead}, nil
+}
+
+type aesCipher struct {
+	aead cipher.AEAD
+}
+
+func (c aesCipher) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	header := append(append(append([]byte{}, encryptionMagic...), encryptionVersion), nonce...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		aead:   c.aead,
+		writer: w,
+		nonce:  nonce,
+		buffer: make([]byte, 0, encryptionChunkSize),
+	}, nil
+}
+
+func (c aesCipher) Decrypt(r io.ReadCloser) (io.ReadCloser, error) {
+	header := make([]byte, len(encryptionMagic)+1+c.aead.NonceSize())
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "unable to read encryption header")
+	}
+	if !bytes.Equal(header[:len(encryptionMagic)], encryptionMagic) {
+		return nil, errors.New("invalid encryption header")
+	}
+	if version := header[len(encryptionMagic)]; version != encryptionVersion {
+		return nil, errors.Errorf("unexpected encryption version %d", version)
+	}
+
+	return &decryptReader{
+		aead:   c.aead,
+		reader: r,
+		nonce:  header[len(encryptionMagic)+1:],
+	}, nil
+}
+
+type encryptWriter struct {
+	aead    cipher.AEAD
+	writer  io.Writer
+	nonce   []byte
+	counter uint64
+	buffer  []byte
+	closed  bool
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed writer")
+	}
+
+	var n int
+	for len(p) > 0 {
+		if len(w.buffer) == encryptionChunkSize {
+			if err := w.flush(encryptionChunkPartial); err != nil {
+				return n, err
+			}
+		}
+
+		m := copy(w.buffer[len(w.buffer):cap(w.buffer)], p)
+		w.buffer = w.buffer[:len(w.buffer)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+func (w *encryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(encryptionChunkFinal)
+}
+
+func (w *encryptWriter) flush(flag byte) error {
+	sealed := w.aead.Seal(nil, chunkNonce(w.nonce, w.counter), w.buffer, chunkData(flag, w.counter))
+
+	header := make([]byte, 5)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err := w.writer.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(sealed); err != nil {
+		return err
+	}
+
+	w.counter++
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+type decryptReader struct {
+	aead    cipher.AEAD
+	reader  io.ReadCloser
+	nonce   []byte
+	counter uint64
+	buffer  []byte
+	done    bool
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.buffer) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.buffer)
+	r.buffer = r.buffer[n:]
+	return n, nil
+}
+
+func (r *decryptReader) Close() error {
+	return r.reader.Close()
+}
+
+func (r *decryptReader) next() error {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r.reader, header); err != nil {
+		if err == io.EOF {
+			return errors.Wrap(io.ErrUnexpectedEOF, "encrypted content is truncated")
+		}
+		return err
+	}
+
+	flag, size := header[0], binary.BigEndian.Uint32(header[1:])
+	if flag != encryptionChunkPartial && flag != encryptionChunkFinal {
+		return errors.Errorf("unexpected chunk flag %d", flag)
+	}
+	if size > uint32(encryptionChunkSize+r.aead.Overhead()) {
+		return errors.Errorf("chunk too large %d", size)
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, sealed); err != nil {
+		return err
+	}
+
+	plain, err := r.aead.Open(sealed[:0], chunkNonce(r.nonce, r.counter), sealed, chunkData(flag, r.counter))
+	if err != nil {
+		return errors.Wrap(err, "unable to decrypt content")
+	}
+
+	r.counter++
+	r.buffer = plain
+	r.done = flag == encryptionChunkFinal
+	return nil
+}
+
+// chunkNonce derives the nonce of a chunk from the nonce of the content, by
+// mixing in the chunk counter.
+func chunkNonce(nonce []byte, counter uint64) []byte {
+	res := make([]byte, len(nonce))
+	copy(res, nonce)
+
+	var c [8]byte
+	binary.BigEndian.PutUint64(c[:], counter)
+	offset := len(res) - len(c)
+	for i, v := range c {
+		res[offset+i] ^= v
+	}
+	return res
+}
+
+// chunkData is the additional data that is authenticated with each chunk.
+func chunkData(flag byte, counter uint64) []byte {
+	res := make([]byte, 9)
+	res[0] = flag
+	binary.BigEndian.PutUint64(res[1:], counter)
+	return res
+}
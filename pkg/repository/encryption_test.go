@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"testing/quick"
+)
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	encrypt := func(t *testing.T, cipher Cipher, body []byte) []byte {
+		buf := new(bytes.Buffer)
+		w, err := cipher.Encrypt(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write(body); err != nil {
+			t.Fatal(err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	decrypt := func(cipher Cipher, body []byte) ([]byte, error) {
+		r, err := cipher.Decrypt(ioutil.NopCloser(bytes.NewReader(body)))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	}
+
+	newCipher := func(t *testing.T) Cipher {
+		key, err := NewDataKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cipher, err := NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cipher
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		cipher := newCipher(t)
+
+		// Short random bodies can turn up in the ciphertext by chance, so every
+		// body starts with a marker that's too long for that to happen.
+		marker := []byte("snowy: plaintext that must not leak")
+
+		fn := func(random []byte) bool {
+			body := append(append([]byte{}, marker...), random...)
+
+			encrypted := encrypt(t, cipher, body)
+			if bytes.Contains(encrypted, marker) {
+				return false
+			}
+
+			decrypted, err := decrypt(cipher, encrypted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return bytes.Equal(body, decrypted)
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("round trip multiple chunks", func(t *testing.T) {
+		var (
+			cipher = newCipher(t)
+			body   = bytes.Repeat([]byte("snowy"), encryptionChunkSize)
+		)
+
+		decrypted, err := decrypt(cipher, encrypt(t, cipher, body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := true, bytes.Equal(body, decrypted); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		encrypted := encrypt(t, newCipher(t), []byte("hello"))
+
+		_, err := decrypt(newCipher(t), encrypted)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		cipher := newCipher(t)
+
+		encrypted := encrypt(t, cipher, []byte("hello"))
+		encrypted[len(encrypted)-1] ^= 0xff
+
+		_, err := decrypt(cipher, encrypted)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		var (
+			cipher    = newCipher(t)
+			body      = bytes.Repeat([]byte("snowy"), encryptionChunkSize)
+			encrypted = encrypt(t, cipher, body)
+		)
+
+		// Remove the final chunk, which leaves only complete chunks.
+		finalChunk := 5 + (len(body) % encryptionChunkSize) + 16
+		_, err := decrypt(cipher, encrypted[:len(encrypted)-finalChunk])
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := NewCipher([]byte("short"))
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/trussle/snowy/pkg/events"
+	"github.com/trussle/snowy/pkg/models"
+)
+
+// notify publishes the event to the event bus and queues a delivery of the
+// event to every webhook of the tenant that matches the ledger, and whose owner
+// can read the resource of the ledger. The ledger has
+// already been written, so failing to do either is logged rather than failing
+// the write.
+func (r *realRepository) notify(event string, doc models.Ledger) {
+	r.publish(event, doc)
+
+	if !r.webhooks {
+		return
+	}
+
+	webhooks, err := r.store.SelectWebhooks(doc.TenantID())
+	if err != nil {
+		level.Error(r.logger).Log("action", "notify", "event", event, "err", err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhookToModel(webhook).Matches(event, doc) {
+			continue
+		}
+
+		ok, err := r.canDeliver(webhook, doc)
+		if err != nil {
+			level.Error(r.logger).Log("action", "notify", "event", event, "webhook_id", webhook.ID.String(), "err", err.Error())
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if err := r.enqueueDelivery(webhook, event, doc, now); err != nil {
+			level.Error(r.logger).Log("action", "notify", "event", event, "webhook_id", webhook.ID.String(), "err", err.Error())
+		}
+	}
+}
+
+func (r *realRepository) publish(event string, doc models.Ledger) {
+	if r.events == nil {
+		return
+	}
+
+	e, err := events.NewEvent(event, doc)
+	if err == nil {
+		err = r.events.Publish(e)
+	}
+	if err != nil {
+		level.Error(r.logger).Log("action", "publish", "event", event, "err", err.Error())
+	}
+}
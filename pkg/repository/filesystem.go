@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/trussle/fsys"
+	"github.com/trussle/snowy/pkg/metrics"
+)
+
+type instrumentedFilesystem struct {
+	fsys.Filesystem
+	duration metrics.HistogramVec
+	errors   metrics.CounterVec
+	read     metrics.Counter
+	written  metrics.Counter
+}
+
+// NewInstrumentedFilesystem creates a fsys.Filesystem that records the latency
+// and the errors of creating, opening, renaming and removing files, by the
+// operation, along with the bytes read from and written to the files. Not
+// found errors are the expected result of opening a blob that doesn't exist,
+// so they aren't recorded as errors.
+func NewInstrumentedFilesystem(fs fsys.Filesystem,
+	duration metrics.HistogramVec,
+	errors metrics.CounterVec,
+	read, written metrics.Counter,
+) fsys.Filesystem {
+	return &instrumentedFilesystem{
+		Filesystem: fs,
+		duration:   duration,
+		errors:     errors,
+		read:       read,
+		written:    written,
+	}
+}
+
+func (f *instrumentedFilesystem) observe(operation string, begin time.Time, err error) {
+	f.duration.WithLabelValues(operation).Observe(time.Since(begin).Seconds())
+	if err != nil && !fsys.ErrNotFound(err) {
+		f.errors.WithLabelValues(operation).Inc()
+	}
+}
+
+func (f *instrumentedFilesystem) Create(path string) (file fsys.File, err error) {
+	defer func(begin time.Time) { f.observe("Create", begin, err) }(time.Now())
+
+	if file, err = f.Filesystem.Create(path); err != nil {
+		return nil, err
+	}
+	return instrumentedFile{file, f.read, f.written}, nil
+}
+
+func (f *instrumentedFilesystem) Open(path string) (file fsys.File, err error) {
+	defer func(begin time.Time) { f.observe("Open", begin, err) }(time.Now())
+
+	if file, err = f.Filesystem.Open(path); err != nil {
+		return nil, err
+	}
+	return instrumentedFile{file, f.read, f.written}, nil
+}
+
+func (f *instrumentedFilesystem) Rename(oldname, newname string) (err error) {
+	defer func(begin time.Time) { f.observe("Rename", begin, err) }(time.Now())
+
+	return f.Filesystem.Rename(oldname, newname)
+}
+
+func (f *instrumentedFilesystem) Remove(path string) (err error) {
+	defer func(begin time.Time) { f.observe("Remove", begin, err) }(time.Now())
+
+	return f.Filesystem.Remove(path)
+}
+
+// instrumentedFile counts the bytes read from and written to the file.
+type instrumentedFile struct {
+	fsys.File
+	read, written metrics.Counter
+}
+
+func (f instrumentedFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.read.Add(float64(n))
+	return n, err
+}
+
+func (f instrumentedFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	f.written.Add(float64(n))
+	return n, err
+}
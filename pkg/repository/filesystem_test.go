@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/fsys"
+	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/metrics/mocks"
+)
+
+func TestInstrumentedFilesystem(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records bytes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			duration = mocks.NewMockHistogramVec(ctrl)
+			errs     = mocks.NewMockCounterVec(ctrl)
+			observer = mocks.NewMockObserver(ctrl)
+			read     = mocks.NewMockCounter(ctrl)
+			written  = mocks.NewMockCounter(ctrl)
+			fs       = NewInstrumentedFilesystem(fsys.NewVirtualFilesystem(), duration, errs, read, written)
+			content  = []byte("hello")
+		)
+
+		duration.EXPECT().WithLabelValues("Create").Return(observer).Times(1)
+		duration.EXPECT().WithLabelValues("Open").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(2)
+		written.EXPECT().Add(float64(len(content))).Times(1)
+		read.EXPECT().Add(float64(len(content))).Times(1)
+		read.EXPECT().Add(float64(0)).AnyTimes()
+
+		file, err := fs.Create("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.Write(content); err != nil {
+			t.Fatal(err)
+		}
+		if err := file.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		file, err = fs.Open("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		b, err := ioutil.ReadAll(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := content, b; !bytes.Equal(expected, actual) {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+
+	t.Run("not found isn't an error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			duration = mocks.NewMockHistogramVec(ctrl)
+			errs     = mocks.NewMockCounterVec(ctrl)
+			observer = mocks.NewMockObserver(ctrl)
+			fs       = NewInstrumentedFilesystem(fsys.NewVirtualFilesystem(), duration, errs,
+				mocks.NewMockCounter(ctrl), mocks.NewMockCounter(ctrl),
+			)
+		)
+
+		duration.EXPECT().WithLabelValues("Open").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		if _, err := fs.Open("a"); !fsys.ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+	})
+}
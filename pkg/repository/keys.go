@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// KeyProvider wraps and unwraps data keys using a master key. The master key
+// never leaves the provider, so a key management service can be plugged in by
+// implementing the KeyProvider.
+type KeyProvider interface {
+
+	// KeyID returns the identifier of the master key that is used for wrapping
+	// new data keys.
+	KeyID() string
+
+	// WrapKey encrypts the data key with the master key.
+	WrapKey(key []byte) ([]byte, error)
+
+	// UnwrapKey decrypts the wrapped data key with the master key
+	// corresponding to the keyID. If the master key is unknown it will return
+	// an error.
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+type localKeyProvider struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLocalKeyProvider creates a KeyProvider from a master key held in a local
+// key file. The key file is expected to contain a hex encoded 32 byte key.
+func NewLocalKeyProvider(path string) (KeyProvider, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read key file")
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(bytes)))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decode key file")
+	}
+
+	return newLocalKeyProvider(key)
+}
+
+func newLocalKeyProvider(key []byte) (KeyProvider, error) {
+	if len(key) != dataKeySize {
+		return nil, errors.Errorf("invalid master key size %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The id is derived from the key, so that rotating the key file can be
+	// detected.
+	sum := sha256.Sum256(key)
+	return &localKeyProvider{
+		id:   "local:" + hex.EncodeToString(sum[:8]),
+		aead: aead,
+	}, nil
+}
+
+func (p *localKeyProvider) KeyID() string {
+	return p.id
+}
+
+func (p *localKeyProvider) WrapKey(key []byte) ([]byte, error) {
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return p.aead.Seal(nonce, nonce, key, []byte(p.id)), nil
+}
+
+func (p *localKeyProvider) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	if keyID != p.id {
+		return nil, errors.Errorf("unknown master key %q", keyID)
+	}
+
+	size := p.aead.NonceSize()
+	if len(wrapped) < size {
+		return nil, errors.New("invalid wrapped key")
+	}
+
+	key, err := p.aead.Open(nil, wrapped[:size], wrapped[size:], []byte(p.id))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to unwrap key")
+	}
+	return key, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"testing/quick"
+)
+
+func TestLocalKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	newProvider := func(t *testing.T) KeyProvider {
+		key, err := NewDataKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		provider, err := newLocalKeyProvider(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return provider
+	}
+
+	t.Run("wrap then unwrap", func(t *testing.T) {
+		provider := newProvider(t)
+
+		fn := func(key []byte) bool {
+			wrapped, err := provider.WrapKey(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			unwrapped, err := provider.UnwrapKey(provider.KeyID(), wrapped)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return bytes.Equal(key, unwrapped)
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unwrap with unknown key id", func(t *testing.T) {
+		provider := newProvider(t)
+
+		wrapped, err := provider.WrapKey([]byte("key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = provider.UnwrapKey("unknown", wrapped)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("unwrap with different master key", func(t *testing.T) {
+		wrapped, err := newProvider(t).WrapKey([]byte("key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		provider := newProvider(t)
+		_, err = provider.UnwrapKey(provider.KeyID(), wrapped)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		key, err := NewDataKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path := filepath.Join(root, "master.key")
+		if err = ioutil.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		a, err := NewLocalKeyProvider(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := newLocalKeyProvider(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := b.KeyID(), a.KeyID(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("invalid key file", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		path := filepath.Join(root, "master.key")
+		if err := ioutil.WriteFile(path, []byte("not a key"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := NewLocalKeyProvider(path)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
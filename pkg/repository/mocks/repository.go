@@ -10,6 +10,7 @@ import (
 	repository "github.com/trussle/snowy/pkg/repository"
 	uuid "github.com/trussle/uuid"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRepository is a mock of Repository interface
@@ -36,16 +37,54 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // AppendLedger mocks base method
-func (m *MockRepository) AppendLedger(arg0 uuid.UUID, arg1 models.Ledger) (models.Ledger, error) {
-	ret := m.ctrl.Call(m, "AppendLedger", arg0, arg1)
+func (m *MockRepository) AppendLedger(arg0 uuid.UUID, arg1 models.Ledger, arg2 repository.Query) (models.Ledger, error) {
+	ret := m.ctrl.Call(m, "AppendLedger", arg0, arg1, arg2)
 	ret0, _ := ret[0].(models.Ledger)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AppendLedger indicates an expected call of AppendLedger
-func (mr *MockRepositoryMockRecorder) AppendLedger(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendLedger", reflect.TypeOf((*MockRepository)(nil).AppendLedger), arg0, arg1)
+func (mr *MockRepositoryMockRecorder) AppendLedger(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendLedger", reflect.TypeOf((*MockRepository)(nil).AppendLedger), arg0, arg1, arg2)
+}
+
+// Authorize mocks base method
+func (m *MockRepository) Authorize(arg0 uuid.UUID, arg1 models.Access, arg2 repository.Query) error {
+	ret := m.ctrl.Call(m, "Authorize", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockRepositoryMockRecorder) Authorize(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockRepository)(nil).Authorize), arg0, arg1, arg2)
+}
+
+// Checkpoint mocks base method
+func (m *MockRepository) Checkpoint() (models.Checkpoint, error) {
+	ret := m.ctrl.Call(m, "Checkpoint")
+	ret0, _ := ret[0].(models.Checkpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkpoint indicates an expected call of Checkpoint
+func (mr *MockRepositoryMockRecorder) Checkpoint() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkpoint", reflect.TypeOf((*MockRepository)(nil).Checkpoint))
+}
+
+// ClaimPendingDeliveries mocks base method
+func (m *MockRepository) ClaimPendingDeliveries(arg0 int, arg1 time.Duration) ([]models.WebhookDelivery, error) {
+	ret := m.ctrl.Call(m, "ClaimPendingDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingDeliveries indicates an expected call of ClaimPendingDeliveries
+func (mr *MockRepositoryMockRecorder) ClaimPendingDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingDeliveries", reflect.TypeOf((*MockRepository)(nil).ClaimPendingDeliveries), arg0, arg1)
 }
 
 // Close mocks base method
@@ -60,17 +99,81 @@ func (mr *MockRepositoryMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRepository)(nil).Close))
 }
 
+// DeleteWebhook mocks base method
+func (m *MockRepository) DeleteWebhook(arg0 uuid.UUID, arg1 repository.Query) error {
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook
+func (mr *MockRepositoryMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockRepository)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// EraseAuthorLedgers mocks base method
+func (m *MockRepository) EraseAuthorLedgers(arg0 string, arg1 repository.Query) ([]models.Ledger, error) {
+	ret := m.ctrl.Call(m, "EraseAuthorLedgers", arg0, arg1)
+	ret0, _ := ret[0].([]models.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EraseAuthorLedgers indicates an expected call of EraseAuthorLedgers
+func (mr *MockRepositoryMockRecorder) EraseAuthorLedgers(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseAuthorLedgers", reflect.TypeOf((*MockRepository)(nil).EraseAuthorLedgers), arg0, arg1)
+}
+
+// EraseLedger mocks base method
+func (m *MockRepository) EraseLedger(arg0 uuid.UUID, arg1 repository.Query) (models.Ledger, error) {
+	ret := m.ctrl.Call(m, "EraseLedger", arg0, arg1)
+	ret0, _ := ret[0].(models.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EraseLedger indicates an expected call of EraseLedger
+func (mr *MockRepositoryMockRecorder) EraseLedger(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseLedger", reflect.TypeOf((*MockRepository)(nil).EraseLedger), arg0, arg1)
+}
+
 // ForkLedger mocks base method
-func (m *MockRepository) ForkLedger(arg0 uuid.UUID, arg1 models.Ledger) (models.Ledger, error) {
-	ret := m.ctrl.Call(m, "ForkLedger", arg0, arg1)
+func (m *MockRepository) ForkLedger(arg0 uuid.UUID, arg1 models.Ledger, arg2 repository.Query) (models.Ledger, error) {
+	ret := m.ctrl.Call(m, "ForkLedger", arg0, arg1, arg2)
 	ret0, _ := ret[0].(models.Ledger)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ForkLedger indicates an expected call of ForkLedger
-func (mr *MockRepositoryMockRecorder) ForkLedger(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForkLedger", reflect.TypeOf((*MockRepository)(nil).ForkLedger), arg0, arg1)
+func (mr *MockRepositoryMockRecorder) ForkLedger(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForkLedger", reflect.TypeOf((*MockRepository)(nil).ForkLedger), arg0, arg1, arg2)
+}
+
+// ImportLedgers mocks base method
+func (m *MockRepository) ImportLedgers(arg0 []models.Ledger, arg1 []models.Content, arg2 repository.Query) ([]models.Ledger, error) {
+	ret := m.ctrl.Call(m, "ImportLedgers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportLedgers indicates an expected call of ImportLedgers
+func (mr *MockRepositoryMockRecorder) ImportLedgers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportLedgers", reflect.TypeOf((*MockRepository)(nil).ImportLedgers), arg0, arg1, arg2)
+}
+
+// InsertAuthorKey mocks base method
+func (m *MockRepository) InsertAuthorKey(arg0 string, arg1 []byte, arg2 repository.Query) (models.AuthorKey, error) {
+	ret := m.ctrl.Call(m, "InsertAuthorKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.AuthorKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertAuthorKey indicates an expected call of InsertAuthorKey
+func (mr *MockRepositoryMockRecorder) InsertAuthorKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuthorKey", reflect.TypeOf((*MockRepository)(nil).InsertAuthorKey), arg0, arg1, arg2)
 }
 
 // InsertLedger mocks base method
@@ -86,30 +189,133 @@ func (mr *MockRepositoryMockRecorder) InsertLedger(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLedger", reflect.TypeOf((*MockRepository)(nil).InsertLedger), arg0)
 }
 
+// InsertWebhook mocks base method
+func (m *MockRepository) InsertWebhook(arg0 models.Webhook, arg1 repository.Query) (models.Webhook, error) {
+	ret := m.ctrl.Call(m, "InsertWebhook", arg0, arg1)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWebhook indicates an expected call of InsertWebhook
+func (mr *MockRepositoryMockRecorder) InsertWebhook(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWebhook", reflect.TypeOf((*MockRepository)(nil).InsertWebhook), arg0, arg1)
+}
+
 // LedgerStatistics mocks base method
-func (m *MockRepository) LedgerStatistics() (models.LedgerStatistics, error) {
-	ret := m.ctrl.Call(m, "LedgerStatistics")
+func (m *MockRepository) LedgerStatistics(arg0 repository.Query) (models.LedgerStatistics, error) {
+	ret := m.ctrl.Call(m, "LedgerStatistics", arg0)
 	ret0, _ := ret[0].(models.LedgerStatistics)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LedgerStatistics indicates an expected call of LedgerStatistics
-func (mr *MockRepositoryMockRecorder) LedgerStatistics() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LedgerStatistics", reflect.TypeOf((*MockRepository)(nil).LedgerStatistics))
+func (mr *MockRepositoryMockRecorder) LedgerStatistics(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LedgerStatistics", reflect.TypeOf((*MockRepository)(nil).LedgerStatistics), arg0)
 }
 
 // PutContent mocks base method
-func (m *MockRepository) PutContent(arg0 models.Content) (models.Content, error) {
-	ret := m.ctrl.Call(m, "PutContent", arg0)
+func (m *MockRepository) PutContent(arg0 models.Content, arg1 repository.Query) (models.Content, error) {
+	ret := m.ctrl.Call(m, "PutContent", arg0, arg1)
 	ret0, _ := ret[0].(models.Content)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PutContent indicates an expected call of PutContent
-func (mr *MockRepositoryMockRecorder) PutContent(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutContent", reflect.TypeOf((*MockRepository)(nil).PutContent), arg0)
+func (mr *MockRepositoryMockRecorder) PutContent(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutContent", reflect.TypeOf((*MockRepository)(nil).PutContent), arg0, arg1)
+}
+
+// RecordDeliveryAttempt mocks base method
+func (m *MockRepository) RecordDeliveryAttempt(arg0 models.WebhookDelivery, arg1 models.WebhookAttempt) error {
+	ret := m.ctrl.Call(m, "RecordDeliveryAttempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDeliveryAttempt indicates an expected call of RecordDeliveryAttempt
+func (mr *MockRepositoryMockRecorder) RecordDeliveryAttempt(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeliveryAttempt", reflect.TypeOf((*MockRepository)(nil).RecordDeliveryAttempt), arg0, arg1)
+}
+
+// RedeliverDelivery mocks base method
+func (m *MockRepository) RedeliverDelivery(arg0 uuid.UUID, arg1 repository.Query) (models.WebhookDelivery, error) {
+	ret := m.ctrl.Call(m, "RedeliverDelivery", arg0, arg1)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverDelivery indicates an expected call of RedeliverDelivery
+func (mr *MockRepositoryMockRecorder) RedeliverDelivery(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverDelivery", reflect.TypeOf((*MockRepository)(nil).RedeliverDelivery), arg0, arg1)
+}
+
+// SelectACL mocks base method
+func (m *MockRepository) SelectACL(arg0 uuid.UUID, arg1 repository.Query) (models.ACL, error) {
+	ret := m.ctrl.Call(m, "SelectACL", arg0, arg1)
+	ret0, _ := ret[0].(models.ACL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectACL indicates an expected call of SelectACL
+func (mr *MockRepositoryMockRecorder) SelectACL(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectACL", reflect.TypeOf((*MockRepository)(nil).SelectACL), arg0, arg1)
+}
+
+// SelectACLRevisions mocks base method
+func (m *MockRepository) SelectACLRevisions(arg0 uuid.UUID, arg1 repository.Query) ([]models.ACL, error) {
+	ret := m.ctrl.Call(m, "SelectACLRevisions", arg0, arg1)
+	ret0, _ := ret[0].([]models.ACL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectACLRevisions indicates an expected call of SelectACLRevisions
+func (mr *MockRepositoryMockRecorder) SelectACLRevisions(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectACLRevisions", reflect.TypeOf((*MockRepository)(nil).SelectACLRevisions), arg0, arg1)
+}
+
+// SelectAuthorKeys mocks base method
+func (m *MockRepository) SelectAuthorKeys(arg0 string, arg1 repository.Query) ([]models.AuthorKey, error) {
+	ret := m.ctrl.Call(m, "SelectAuthorKeys", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuthorKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAuthorKeys indicates an expected call of SelectAuthorKeys
+func (mr *MockRepositoryMockRecorder) SelectAuthorKeys(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAuthorKeys", reflect.TypeOf((*MockRepository)(nil).SelectAuthorKeys), arg0, arg1)
+}
+
+// SelectCheckpoint mocks base method
+func (m *MockRepository) SelectCheckpoint(arg0 int64) (models.Checkpoint, error) {
+	ret := m.ctrl.Call(m, "SelectCheckpoint", arg0)
+	ret0, _ := ret[0].(models.Checkpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectCheckpoint indicates an expected call of SelectCheckpoint
+func (mr *MockRepositoryMockRecorder) SelectCheckpoint(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectCheckpoint", reflect.TypeOf((*MockRepository)(nil).SelectCheckpoint), arg0)
+}
+
+// SelectCheckpointKey mocks base method
+func (m *MockRepository) SelectCheckpointKey() (models.CheckpointKey, error) {
+	ret := m.ctrl.Call(m, "SelectCheckpointKey")
+	ret0, _ := ret[0].(models.CheckpointKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectCheckpointKey indicates an expected call of SelectCheckpointKey
+func (mr *MockRepositoryMockRecorder) SelectCheckpointKey() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectCheckpointKey", reflect.TypeOf((*MockRepository)(nil).SelectCheckpointKey))
 }
 
 // SelectContent mocks base method
@@ -138,17 +344,43 @@ func (mr *MockRepositoryMockRecorder) SelectContents(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectContents", reflect.TypeOf((*MockRepository)(nil).SelectContents), arg0, arg1)
 }
 
+// SelectDeliveries mocks base method
+func (m *MockRepository) SelectDeliveries(arg0 string, arg1 repository.Query) ([]models.WebhookDelivery, error) {
+	ret := m.ctrl.Call(m, "SelectDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectDeliveries indicates an expected call of SelectDeliveries
+func (mr *MockRepositoryMockRecorder) SelectDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectDeliveries", reflect.TypeOf((*MockRepository)(nil).SelectDeliveries), arg0, arg1)
+}
+
+// SelectDeliveryAttempts mocks base method
+func (m *MockRepository) SelectDeliveryAttempts(arg0 uuid.UUID, arg1 repository.Query) ([]models.WebhookAttempt, error) {
+	ret := m.ctrl.Call(m, "SelectDeliveryAttempts", arg0, arg1)
+	ret0, _ := ret[0].([]models.WebhookAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectDeliveryAttempts indicates an expected call of SelectDeliveryAttempts
+func (mr *MockRepositoryMockRecorder) SelectDeliveryAttempts(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectDeliveryAttempts", reflect.TypeOf((*MockRepository)(nil).SelectDeliveryAttempts), arg0, arg1)
+}
+
 // SelectForkLedgers mocks base method
-func (m *MockRepository) SelectForkLedgers(arg0 uuid.UUID) ([]models.Ledger, error) {
-	ret := m.ctrl.Call(m, "SelectForkLedgers", arg0)
+func (m *MockRepository) SelectForkLedgers(arg0 uuid.UUID, arg1 repository.Query) ([]models.Ledger, error) {
+	ret := m.ctrl.Call(m, "SelectForkLedgers", arg0, arg1)
 	ret0, _ := ret[0].([]models.Ledger)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SelectForkLedgers indicates an expected call of SelectForkLedgers
-func (mr *MockRepositoryMockRecorder) SelectForkLedgers(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectForkLedgers", reflect.TypeOf((*MockRepository)(nil).SelectForkLedgers), arg0)
+func (mr *MockRepositoryMockRecorder) SelectForkLedgers(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectForkLedgers", reflect.TypeOf((*MockRepository)(nil).SelectForkLedgers), arg0, arg1)
 }
 
 // SelectLedger mocks base method
@@ -164,6 +396,19 @@ func (mr *MockRepositoryMockRecorder) SelectLedger(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectLedger", reflect.TypeOf((*MockRepository)(nil).SelectLedger), arg0, arg1)
 }
 
+// SelectLedgerProof mocks base method
+func (m *MockRepository) SelectLedgerProof(arg0 uuid.UUID, arg1, arg2 int64, arg3 repository.Query) (models.LedgerProof, error) {
+	ret := m.ctrl.Call(m, "SelectLedgerProof", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.LedgerProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectLedgerProof indicates an expected call of SelectLedgerProof
+func (mr *MockRepositoryMockRecorder) SelectLedgerProof(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectLedgerProof", reflect.TypeOf((*MockRepository)(nil).SelectLedgerProof), arg0, arg1, arg2, arg3)
+}
+
 // SelectLedgers mocks base method
 func (m *MockRepository) SelectLedgers(arg0 uuid.UUID, arg1 repository.Query) ([]models.Ledger, error) {
 	ret := m.ctrl.Call(m, "SelectLedgers", arg0, arg1)
@@ -176,3 +421,94 @@ func (m *MockRepository) SelectLedgers(arg0 uuid.UUID, arg1 repository.Query) ([
 func (mr *MockRepositoryMockRecorder) SelectLedgers(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectLedgers", reflect.TypeOf((*MockRepository)(nil).SelectLedgers), arg0, arg1)
 }
+
+// SelectQuota mocks base method
+func (m *MockRepository) SelectQuota(arg0 string, arg1 repository.Query) (models.Quota, error) {
+	ret := m.ctrl.Call(m, "SelectQuota", arg0, arg1)
+	ret0, _ := ret[0].(models.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectQuota indicates an expected call of SelectQuota
+func (mr *MockRepositoryMockRecorder) SelectQuota(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectQuota", reflect.TypeOf((*MockRepository)(nil).SelectQuota), arg0, arg1)
+}
+
+// SelectResources mocks base method
+func (m *MockRepository) SelectResources(arg0 repository.Query) ([]uuid.UUID, error) {
+	ret := m.ctrl.Call(m, "SelectResources", arg0)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectResources indicates an expected call of SelectResources
+func (mr *MockRepositoryMockRecorder) SelectResources(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectResources", reflect.TypeOf((*MockRepository)(nil).SelectResources), arg0)
+}
+
+// SelectRevisionContent mocks base method
+func (m *MockRepository) SelectRevisionContent(arg0 models.Ledger, arg1 repository.Query) (models.Content, error) {
+	ret := m.ctrl.Call(m, "SelectRevisionContent", arg0, arg1)
+	ret0, _ := ret[0].(models.Content)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectRevisionContent indicates an expected call of SelectRevisionContent
+func (mr *MockRepositoryMockRecorder) SelectRevisionContent(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRevisionContent", reflect.TypeOf((*MockRepository)(nil).SelectRevisionContent), arg0, arg1)
+}
+
+// SelectWebhooks mocks base method
+func (m *MockRepository) SelectWebhooks(arg0 repository.Query) ([]models.Webhook, error) {
+	ret := m.ctrl.Call(m, "SelectWebhooks", arg0)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectWebhooks indicates an expected call of SelectWebhooks
+func (mr *MockRepositoryMockRecorder) SelectWebhooks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectWebhooks", reflect.TypeOf((*MockRepository)(nil).SelectWebhooks), arg0)
+}
+
+// UpdateACL mocks base method
+func (m *MockRepository) UpdateACL(arg0 uuid.UUID, arg1 models.ACL, arg2 repository.Query) (models.ACL, error) {
+	ret := m.ctrl.Call(m, "UpdateACL", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.ACL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateACL indicates an expected call of UpdateACL
+func (mr *MockRepositoryMockRecorder) UpdateACL(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateACL", reflect.TypeOf((*MockRepository)(nil).UpdateACL), arg0, arg1, arg2)
+}
+
+// UpdateQuota mocks base method
+func (m *MockRepository) UpdateQuota(arg0 models.Quota, arg1 repository.Query) (models.Quota, error) {
+	ret := m.ctrl.Call(m, "UpdateQuota", arg0, arg1)
+	ret0, _ := ret[0].(models.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateQuota indicates an expected call of UpdateQuota
+func (mr *MockRepositoryMockRecorder) UpdateQuota(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQuota", reflect.TypeOf((*MockRepository)(nil).UpdateQuota), arg0, arg1)
+}
+
+// VerifyLedger mocks base method
+func (m *MockRepository) VerifyLedger(arg0 uuid.UUID, arg1 repository.Query) (models.LedgerVerification, error) {
+	ret := m.ctrl.Call(m, "VerifyLedger", arg0, arg1)
+	ret0, _ := ret[0].(models.LedgerVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyLedger indicates an expected call of VerifyLedger
+func (mr *MockRepositoryMockRecorder) VerifyLedger(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyLedger", reflect.TypeOf((*MockRepository)(nil).VerifyLedger), arg0, arg1)
+}
@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/snowy/pkg/store"
+)
+
+// quotaCache caches the quotas and the usage of each author and tenant, so
+// that the usage doesn't have to be computed from the ledgers on every write.
+// The cached usage is kept up to date with the writes of this repository and
+// is recomputed once it expires, or the day changes. A write reserves its
+// usage before it's inserted, so that concurrent writes of the same process
+// can't each be let through against the same usage. The cache is per process
+// though, so the writes of other replicas are only seen once the cache
+// expires, and until then each replica can let an author write up to the whole
+// of their quota.
+type quotaCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	entries map[string]quotaEntry
+	usage   metrics.GaugeVec
+}
+
+type quotaEntry struct {
+	quota   store.Quota
+	usage   store.Usage
+	day     time.Time
+	expires time.Time
+}
+
+func newQuotaCache(ttl time.Duration, usage metrics.GaugeVec) *quotaCache {
+	return &quotaCache{
+		ttl:     ttl,
+		entries: make(map[string]quotaEntry),
+		usage:   usage,
+	}
+}
+
+func (c *quotaCache) get(tenant, authorID string, now time.Time) (quotaEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[quotaIndex(tenant, authorID)]
+	if !ok || !entry.fresh(now) {
+		return quotaEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *quotaCache) set(entry quotaEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[quotaIndex(entry.quota.TenantID, entry.quota.AuthorID)] = entry
+	c.observe(entry)
+}
+
+// reserve checks that adding the delta wouldn't exceed the quota of any of
+// the entries, before adding it to the usage of all of them at once. Checking
+// and adding under the same lock means that concurrent writes can't go over
+// the quota together. The cached entry is used over the one that's given, if
+// it's still fresh, as it includes the writes since the given one was read.
+func (c *quotaCache) reserve(entries []quotaEntry, delta store.Usage) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	for k, entry := range entries {
+		if cached, ok := c.entries[quotaIndex(entry.quota.TenantID, entry.quota.AuthorID)]; ok && cached.fresh(now) {
+			entries[k] = cached
+		}
+		if err := entries[k].check(delta); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		entry.usage = addUsage(entry.usage, delta)
+		c.entries[quotaIndex(entry.quota.TenantID, entry.quota.AuthorID)] = entry
+		c.observe(entry)
+	}
+	return nil
+}
+
+// add adds the delta to the cached usage, if there is any.
+func (c *quotaCache) add(tenant, authorID string, delta store.Usage) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	index := quotaIndex(tenant, authorID)
+	entry, ok := c.entries[index]
+	if !ok {
+		return
+	}
+
+	entry.usage = addUsage(entry.usage, delta)
+	c.entries[index] = entry
+	c.observe(entry)
+}
+
+func (c *quotaCache) remove(tenant, authorID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, quotaIndex(tenant, authorID))
+}
+
+func (c *quotaCache) observe(entry quotaEntry) {
+	if c.usage == nil || !entry.limited() {
+		return
+	}
+
+	tenant, authorID := entry.quota.TenantID, entry.quota.AuthorID
+	c.usage.WithLabelValues(tenant, authorID, "bytes").Set(float64(entry.usage.Bytes))
+	c.usage.WithLabelValues(tenant, authorID, "resources").Set(float64(entry.usage.Resources))
+	c.usage.WithLabelValues(tenant, authorID, "revisions_today").Set(float64(entry.usage.Revisions))
+}
+
+// check makes sure that adding the delta to the usage wouldn't exceed the
+// limits of the quota.
+func (e quotaEntry) check(delta store.Usage) error {
+	var (
+		quota = e.quota
+		usage = e.usage
+	)
+	if quota.MaxRevisionsPerDay > 0 && delta.Revisions > 0 &&
+		usage.Revisions+delta.Revisions > quota.MaxRevisionsPerDay {
+		return errDailyQuotaExceeded{fmt.Errorf(
+			"daily quota exceeded: %s has already created %d of %d revisions today",
+			quotaScope(quota), usage.Revisions, quota.MaxRevisionsPerDay,
+		)}
+	}
+	if quota.MaxResources > 0 && delta.Resources > 0 &&
+		usage.Resources+delta.Resources > quota.MaxResources {
+		return errQuotaExceeded{fmt.Errorf(
+			"quota exceeded: %s already has %d of %d resources",
+			quotaScope(quota), usage.Resources, quota.MaxResources,
+		)}
+	}
+	if quota.MaxBytes > 0 && delta.Bytes > 0 &&
+		usage.Bytes+delta.Bytes > quota.MaxBytes {
+		return errQuotaExceeded{fmt.Errorf(
+			"quota exceeded: storing %d bytes would exceed the limit of %d bytes for %s (%d bytes used)",
+			delta.Bytes, quota.MaxBytes, quotaScope(quota), usage.Bytes,
+		)}
+	}
+	return nil
+}
+
+// fresh checks that the usage of the entry can still be used, as it's neither
+// expired or from another day.
+func (e quotaEntry) fresh(now time.Time) bool {
+	return now.Before(e.expires) && e.day.Equal(startOfDay(now))
+}
+
+func (e quotaEntry) limited() bool {
+	return e.quota.MaxBytes > 0 || e.quota.MaxResources > 0 || e.quota.MaxRevisionsPerDay > 0
+}
+
+// checkQuota makes sure that writing the delta wouldn't exceed the quota of
+// the author, or the quota of the whole tenant.
+func (r *realRepository) checkQuota(tenant, authorID string, delta store.Usage) error {
+	if r.quotas == nil {
+		return nil
+	}
+
+	for _, scope := range quotaScopes(authorID) {
+		entry, err := r.quotaEntry(tenant, scope)
+		if err != nil {
+			return err
+		}
+		if err = entry.check(delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reserveQuota makes sure that writing the delta wouldn't exceed the quota of
+// the author, or the quota of the whole tenant, reserving the delta against
+// the cached usage of both. The returned function releases the reservation,
+// for when the write fails.
+func (r *realRepository) reserveQuota(tenant, authorID string, delta store.Usage) (func(), error) {
+	if r.quotas == nil {
+		return func() {}, nil
+	}
+
+	scopes := quotaScopes(authorID)
+	entries := make([]quotaEntry, len(scopes))
+	for k, scope := range scopes {
+		entry, err := r.quotaEntry(tenant, scope)
+		if err != nil {
+			return nil, err
+		}
+		entries[k] = entry
+	}
+	if err := r.quotas.reserve(entries, delta); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		release := store.Usage{
+			Bytes:     -delta.Bytes,
+			Resources: -delta.Resources,
+			Revisions: -delta.Revisions,
+		}
+		for _, scope := range scopes {
+			r.quotas.add(tenant, scope, release)
+		}
+	}, nil
+}
+
+func (r *realRepository) quotaEntry(tenant, authorID string) (quotaEntry, error) {
+	now := time.Now()
+	if entry, ok := r.quotas.get(tenant, authorID, now); ok {
+		return entry, nil
+	}
+
+	quota, err := r.store.SelectQuota(tenant, authorID)
+	if err != nil {
+		if !store.ErrNotFound(err) {
+			return quotaEntry{}, err
+		}
+		quota = store.Quota{
+			TenantID: tenant,
+			AuthorID: authorID,
+		}
+	}
+
+	entry := quotaEntry{
+		quota:   quota,
+		day:     startOfDay(now),
+		expires: now.Add(r.quotas.ttl),
+	}
+
+	// Computing the usage is only worth it, if there is something to check it
+	// against.
+	if entry.limited() {
+		if entry.usage, err = r.store.SelectUsage(store.Query{
+			Tenant:   tenant,
+			AuthorID: &authorID,
+		}, entry.day); err != nil {
+			return quotaEntry{}, err
+		}
+	}
+
+	r.quotas.set(entry)
+	return entry, nil
+}
+
+// quotaScopes returns the authors that have quotas that apply to a write by
+// the author, where the empty author is the quota of the whole tenant.
+func quotaScopes(authorID string) []string {
+	if authorID == "" {
+		return []string{""}
+	}
+	return []string{authorID, ""}
+}
+
+func quotaScope(quota store.Quota) string {
+	if quota.AuthorID != "" {
+		return fmt.Sprintf("author %q", quota.AuthorID)
+	}
+	if quota.TenantID != "" {
+		return fmt.Sprintf("tenant %q", quota.TenantID)
+	}
+	return "the default tenant"
+}
+
+func addUsage(usage, delta store.Usage) store.Usage {
+	return store.Usage{
+		Bytes:     usage.Bytes + delta.Bytes,
+		Resources: usage.Resources + delta.Resources,
+		Revisions: usage.Revisions + delta.Revisions,
+	}
+}
+
+func quotaIndex(tenant, authorID string) string {
+	return tenant + ":" + authorID
+}
+
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -1,43 +1,122 @@
 package repository
 
 import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
 	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
-	"github.com/trussle/fsys"
+	"github.com/trussle/snowy/pkg/events"
+	"github.com/trussle/snowy/pkg/merkle"
+	"github.com/trussle/snowy/pkg/metrics"
 	"github.com/trussle/snowy/pkg/models"
 	"github.com/trussle/snowy/pkg/store"
 	"github.com/trussle/uuid"
+	"golang.org/x/crypto/ed25519"
 )
 
 const (
 	defaultRootParentID = "00000000-0000-0000-0000-000000000000"
+
+	// defaultNodesBatchSize is the number of leaves that the nodes of the
+	// checkpoint tree are appended for at a time.
+	defaultNodesBatchSize = 10000
 )
 
 type realRepository struct {
-	fs     fsys.Filesystem
-	store  store.Store
-	logger log.Logger
+	blobs       BlobStore
+	store       store.Store
+	compression Compression
+	keys        KeyProvider
+	signer      Signer
+	quotas      *quotaCache
+	webhooks    bool
+	events      events.Publisher
+	logger      log.Logger
+}
+
+// Option defines a option for configuring a real repository.
+type Option func(*realRepository)
+
+// WithCompression configures the repository to compress content at rest.
+func WithCompression(compression Compression) Option {
+	return func(r *realRepository) {
+		r.compression = compression
+	}
+}
+
+// WithEncryption configures the repository to encrypt content at rest. Each
+// resource gets its own data key, which is wrapped by the KeyProvider and then
+// stored alongside the ledgers.
+func WithEncryption(keys KeyProvider) Option {
+	return func(r *realRepository) {
+		r.keys = keys
+	}
+}
+
+// WithCheckpoints configures the repository to sign checkpoints of the
+// ledgers with the Signer.
+func WithCheckpoints(signer Signer) Option {
+	return func(r *realRepository) {
+		r.signer = signer
+	}
+}
+
+// WithQuotas configures the repository to enforce the quotas of authors and
+// tenants. The usage is cached by each repository for the duration of the
+// ttl, so with many replicas the quotas are only enforced to within the ttl,
+// and is reported to the gauge, partitioned by tenant, author and usage.
+func WithQuotas(ttl time.Duration, usage metrics.GaugeVec) Option {
+	return func(r *realRepository) {
+		r.quotas = newQuotaCache(ttl, usage)
+	}
+}
+
+// WithWebhooks configures the repository to queue a delivery to every
+// matching webhook, when a ledger is inserted, appended, forked or erased.
+func WithWebhooks() Option {
+	return func(r *realRepository) {
+		r.webhooks = true
+	}
+}
+
+// WithEvents configures the repository to publish an event, when a ledger is
+// inserted, appended, forked or erased.
+func WithEvents(publisher events.Publisher) Option {
+	return func(r *realRepository) {
+		r.events = publisher
+	}
 }
 
-// NewRealRepository creates a store that backs on to a real filesystem, with the
-// correct dependencies.
-func NewRealRepository(fs fsys.Filesystem, store store.Store, logger log.Logger) Repository {
-	return &realRepository{
-		fs:     fs,
+// NewRealRepository creates a store that backs on to a real blob store, with
+// the correct dependencies.
+func NewRealRepository(blobs BlobStore, store store.Store, logger log.Logger, opts ...Option) Repository {
+	repository := &realRepository{
+		blobs:  blobs,
 		store:  store,
 		logger: logger,
 	}
+	for _, opt := range opts {
+		opt(repository)
+	}
+	return repository
 }
 
 // SelectLedger returns a Ledger corresponding to the resource ID. If no
 // ledger exists it will return an error.
 func (r *realRepository) SelectLedger(resourceID uuid.UUID, options Query) (models.Ledger, error) {
+	if err := r.authorize(resourceID, options, models.AccessRead); err != nil {
+		return models.Ledger{}, err
+	}
+
 	query, err := store.BuildQuery(
 		store.WithQueryTags(options.Tags),
 		store.WithQueryAuthorID(options.AuthorID),
+		store.WithQueryTenant(options.Tenant),
 	)
 	if err != nil {
 		return models.Ledger{}, err
@@ -54,6 +133,7 @@ func (r *realRepository) SelectLedger(resourceID uuid.UUID, options Query) (mode
 	return models.BuildLedger(
 		models.WithID(entity.ID),
 		models.WithParentID(entity.ParentID),
+		models.WithTenantID(entity.TenantID),
 		models.WithName(entity.Name),
 		models.WithResourceID(entity.ResourceID),
 		models.WithResourceAddress(entity.ResourceAddress),
@@ -63,6 +143,7 @@ func (r *realRepository) SelectLedger(resourceID uuid.UUID, options Query) (mode
 		models.WithTags(entity.Tags),
 		models.WithCreatedOn(entity.CreatedOn),
 		models.WithDeletedOn(entity.DeletedOn),
+		models.WithSignature(entity.SignatureKeyID, entity.Signature),
 	)
 }
 
@@ -74,53 +155,312 @@ func (r *realRepository) InsertLedger(doc models.Ledger) (models.Ledger, error)
 		return models.Ledger{}, err
 	}
 
-	return r.insertLedgerWithParentID(doc, parentID)
+	delta := store.Usage{
+		Bytes:     doc.ResourceSize(),
+		Resources: 1,
+		Revisions: 1,
+	}
+	release, err := r.reserveQuota(doc.TenantID(), doc.AuthorID(), delta)
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	// The author of a new resource owns it, from the moment it's inserted.
+	res, err := r.insertLedgerWithParentID(doc, parentID, store.ACL{
+		TenantID:   doc.TenantID(),
+		ResourceID: doc.ResourceID(),
+		Owner:      doc.AuthorID(),
+		UpdatedBy:  doc.AuthorID(),
+		CreatedOn:  time.Now(),
+	})
+	if err != nil {
+		release()
+		return models.Ledger{}, err
+	}
+
+	r.notify(models.WebhookEventInsert, res)
+
+	return res, nil
 }
 
 // AppendLedger adds a new ledger as a revision. If there is no head
-// ledger, it will return an error. If there is an error appending
-// ledgers into the repository then it will return an error.
-func (r *realRepository) AppendLedger(resourceID uuid.UUID, doc models.Ledger) (models.Ledger, error) {
+// ledger, it will return an error, and if the resource was erased it will
+// return a erased error. If there is an error appending ledgers into the
+// repository then it will return an error.
+func (r *realRepository) AppendLedger(resourceID uuid.UUID, doc models.Ledger, options Query) (models.Ledger, error) {
+	if err := r.authorize(resourceID, options, models.AccessWrite); err != nil {
+		return models.Ledger{}, err
+	}
+
 	// We don't care what we get back, just that it exists.
-	entity, err := r.SelectLedger(resourceID, Query{})
+	entity, err := r.SelectLedger(resourceID, Query{Tenant: options.Tenant})
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	// An erased resource is never written to again, so that its tombstone is
+	// always the head of the resource.
+	if err = checkErased(entity); err != nil {
+		return models.Ledger{}, err
+	}
+
+	if err = models.WithTenantID(options.Tenant)(&doc); err != nil {
+		return models.Ledger{}, err
+	}
+
+	delta := revisionUsage(entity, doc, false)
+	release, err := r.reserveQuota(options.Tenant, doc.AuthorID(), delta)
 	if err != nil {
 		return models.Ledger{}, err
 	}
 
-	return r.insertLedgerWithParentID(doc, entity.ID())
+	res, err := r.insertLedgerWithParentID(doc, entity.ID())
+	if err != nil {
+		release()
+		return models.Ledger{}, err
+	}
+	r.notify(models.WebhookEventAppend, res)
+
+	return res, nil
 }
 
 // ForkLedger adds a new ledger as a revision. If there is no head
-// ledger, it will return an error. If there is an error appending
-// ledgers into the repository then it will return an error.
-func (r *realRepository) ForkLedger(resourceID uuid.UUID, doc models.Ledger) (models.Ledger, error) {
+// ledger, it will return an error, and if the resource was erased it will
+// return a erased error. If there is an error appending ledgers into the
+// repository then it will return an error.
+func (r *realRepository) ForkLedger(resourceID uuid.UUID, doc models.Ledger, options Query) (models.Ledger, error) {
+	if err := r.authorize(resourceID, options, models.AccessWrite); err != nil {
+		return models.Ledger{}, err
+	}
+
 	// We don't care what we get back, just that it exists.
-	entity, err := r.SelectLedger(resourceID, Query{})
+	entity, err := r.SelectLedger(resourceID, Query{Tenant: options.Tenant})
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	// An erased resource is never written to again, so that its tombstone is
+	// always the head of the resource.
+	if err = checkErased(entity); err != nil {
+		return models.Ledger{}, err
+	}
+
+	if err = models.WithTenantID(options.Tenant)(&doc); err != nil {
+		return models.Ledger{}, err
+	}
+
+	delta := revisionUsage(entity, doc, !doc.ResourceID().Equals(resourceID))
+	release, err := r.reserveQuota(options.Tenant, doc.AuthorID(), delta)
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	res, err := r.insertLedgerWithParentID(doc, entity.ID())
+	if err != nil {
+		release()
+		return models.Ledger{}, err
+	}
+	r.notify(models.WebhookEventFork, res)
+
+	// The fork inherits the access control list of the resource it was forked
+	// from. Resources without one are left without one.
+	acl, err := r.store.SelectACL(resourceID, store.Query{Tenant: options.Tenant})
 	if err != nil {
+		if store.ErrNotFound(err) {
+			return res, nil
+		}
+		return models.Ledger{}, err
+	}
+
+	if res.ResourceID().Equals(resourceID) {
+		return res, nil
+	}
+
+	acl.ResourceID = res.ResourceID()
+	acl.UpdatedBy = res.AuthorID()
+	acl.CreatedOn = time.Now()
+	if err = r.store.InsertACL(acl); err != nil {
 		return models.Ledger{}, err
 	}
 
-	return r.insertLedgerWithParentID(doc, entity.ID())
+	return res, nil
 }
 
-func (r *realRepository) insertLedgerWithParentID(doc models.Ledger, parentID uuid.UUID) (models.Ledger, error) {
-	entity, err := store.BuildEntity(
-		store.WithParentID(parentID),
-		store.WithName(doc.Name()),
-		store.WithResourceID(doc.ResourceID()),
-		store.WithResourceAddress(doc.ResourceAddress()),
-		store.WithResourceSize(doc.ResourceSize()),
-		store.WithResourceContentType(doc.ResourceContentType()),
-		store.WithAuthorID(doc.AuthorID()),
-		store.WithTags(doc.Tags()),
-		store.WithCreatedOn(doc.CreatedOn()),
-		store.WithDeletedOn(time.Time{}),
+// ImportLedgers inserts the ledgers exactly as they were exported, keeping
+// their ids, parent ids and times, so that the history and hash chain of the
+// resources are restored as they were. The signatures aren't verified, as the
+// author keys aren't part of an export, and quotas aren't checked for
+// revisions that were created before. Ledgers that already exist, in any
+// tenant, are skipped, and only the content that the other ledgers refer to
+// is stored, so that content that was erased since isn't brought back. The
+// ledgers are inserted with in one transaction, so either all of them are
+// imported or none are. If the parent of a ledger is in neither the ledgers
+// nor the tenant it will return a not found error.
+func (r *realRepository) ImportLedgers(docs []models.Ledger, contents []models.Content, options Query) ([]models.Ledger, error) {
+	ids := make([]uuid.UUID, len(docs))
+	for k, doc := range docs {
+		if doc.ID().Zero() {
+			return nil, errors.New("ledger has no id")
+		}
+		ids[k] = doc.ID()
+	}
+
+	// The id of a ledger is unique across the tenants, so a ledger of another
+	// tenant is a duplicate as well.
+	existing, err := r.store.SelectLedgerIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := make(map[uuid.UUID]struct{}, len(existing))
+	for _, id := range existing {
+		duplicates[id] = struct{}{}
+	}
+
+	var (
+		res       = make([]models.Ledger, 0, len(docs))
+		imported  = make(map[uuid.UUID]struct{}, len(docs))
+		addresses = make(map[string]struct{}, len(docs))
+	)
+	for _, doc := range docs {
+		if _, ok := duplicates[doc.ID()]; ok {
+			continue
+		}
+
+		// The hash chain starts over at a missing parent, so the ledger would
+		// look like the first revision, rather than one that's lost its
+		// history.
+		if parentID := doc.ParentID(); !parentID.Zero() {
+			if _, ok := imported[parentID]; !ok {
+				if _, err = r.store.SelectEntity(parentID, store.Query{Tenant: options.Tenant}); store.ErrNotFound(err) {
+					return nil, errNotFound{errors.Errorf("parent %q of ledger %q not found", parentID, doc.ID())}
+				} else if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if err = models.WithTenantID(options.Tenant)(&doc); err != nil {
+			return nil, err
+		}
+		res = append(res, doc)
+		imported[doc.ID()] = struct{}{}
+		addresses[doc.ResourceAddress()] = struct{}{}
+	}
+	if len(res) == 0 {
+		return res, nil
+	}
+
+	// Store the content before the ledgers, so that a ledger never refers to
+	// content that isn't there.
+	for _, content := range contents {
+		if _, ok := addresses[content.Address()]; !ok {
+			continue
+		}
+		if _, err = r.PutContent(content, options); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		entities = make([]store.Entity, 0, len(res))
+		acls     []store.ACL
+		owned    = make(map[uuid.UUID]struct{})
 	)
+	for _, doc := range res {
+		entity, err := entityOf(doc)
+		if err != nil {
+			return nil, err
+		}
+		if err = r.grantKey(doc); err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+
+		// Access control lists aren't part of an export, so the author of the
+		// first revision that's imported owns the resource, as if it was
+		// inserted.
+		resourceID := doc.ResourceID()
+		if _, ok := owned[resourceID]; ok {
+			continue
+		}
+		owned[resourceID] = struct{}{}
+		if _, err = r.store.SelectACL(resourceID, store.Query{Tenant: options.Tenant}); err == nil {
+			continue
+		} else if !store.ErrNotFound(err) {
+			return nil, err
+		}
+		acls = append(acls, store.ACL{
+			TenantID:   options.Tenant,
+			ResourceID: resourceID,
+			Owner:      doc.AuthorID(),
+			UpdatedBy:  doc.AuthorID(),
+			CreatedOn:  time.Now(),
+		})
+	}
+
+	if err = r.store.InsertLedgers(entities, acls); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// revisionUsage returns the usage of a new revision of the head ledger. The
+// content is only counted if it's different from the content of the head.
+func revisionUsage(head, doc models.Ledger, newResource bool) store.Usage {
+	usage := store.Usage{
+		Revisions: 1,
+	}
+	if doc.ResourceAddress() != head.ResourceAddress() {
+		usage.Bytes = doc.ResourceSize()
+	}
+	if newResource {
+		usage.Resources = 1
+	}
+	return usage
+}
+
+func (r *realRepository) insertLedgerWithParentID(doc models.Ledger, parentID uuid.UUID, acls ...store.ACL) (models.Ledger, error) {
+	// Assign the id up front, so that the ledger that's returned (and any
+	// events of it) can be linked to.
+	id, err := uuid.New()
+	if err != nil {
+		return models.Ledger{}, err
+	}
+	if err = models.WithID(id)(&doc); err != nil {
+		return models.Ledger{}, err
+	}
+	if err = models.WithParentID(parentID)(&doc); err != nil {
+		return models.Ledger{}, err
+	}
+
+	// The signature covers the parent id, which is only known now.
+	if len(doc.Signature()) > 0 {
+		if err = r.verifySignature(doc); err != nil {
+			return models.Ledger{}, err
+		}
+	}
+
+	return r.insertLedger(doc, acls...)
+}
+
+// insertLedger inserts the ledger as it is, with its own id and parent id. Any
+// access control lists are inserted with in the same transaction as the
+// ledger, so that neither is ever stored without the other.
+func (r *realRepository) insertLedger(doc models.Ledger, acls ...store.ACL) (models.Ledger, error) {
+	entity, err := entityOf(doc)
 	if err != nil {
 		return models.Ledger{}, err
 	}
 
-	if err = r.store.Insert(entity); err != nil {
+	if err = r.grantKey(doc); err != nil {
+		return models.Ledger{}, err
+	}
+	if len(acls) > 0 {
+		err = r.store.InsertLedgers([]store.Entity{entity}, acls)
+	} else {
+		err = r.store.Insert(entity)
+	}
+	if err != nil {
 		return models.Ledger{}, err
 	}
 
@@ -128,6 +468,7 @@ func (r *realRepository) insertLedgerWithParentID(doc models.Ledger, parentID uu
 	return models.BuildLedger(
 		models.WithID(entity.ID),
 		models.WithParentID(entity.ParentID),
+		models.WithTenantID(entity.TenantID),
 		models.WithName(entity.Name),
 		models.WithResourceID(entity.ResourceID),
 		models.WithResourceAddress(entity.ResourceAddress),
@@ -137,6 +478,26 @@ func (r *realRepository) insertLedgerWithParentID(doc models.Ledger, parentID uu
 		models.WithTags(entity.Tags),
 		models.WithCreatedOn(entity.CreatedOn),
 		models.WithDeletedOn(entity.DeletedOn),
+		models.WithSignature(entity.SignatureKeyID, entity.Signature),
+	)
+}
+
+// entityOf builds the entity of the ledger, to store it as it is.
+func entityOf(doc models.Ledger) (store.Entity, error) {
+	return store.BuildEntity(
+		store.WithID(doc.ID()),
+		store.WithParentID(doc.ParentID()),
+		store.WithTenantID(doc.TenantID()),
+		store.WithName(doc.Name()),
+		store.WithResourceID(doc.ResourceID()),
+		store.WithResourceAddress(doc.ResourceAddress()),
+		store.WithResourceSize(doc.ResourceSize()),
+		store.WithResourceContentType(doc.ResourceContentType()),
+		store.WithAuthorID(doc.AuthorID()),
+		store.WithTags(doc.Tags()),
+		store.WithCreatedOn(doc.CreatedOn()),
+		store.WithDeletedOn(doc.DeletedOn()),
+		store.WithSignature(doc.SignatureKeyID(), doc.Signature()),
 	)
 }
 
@@ -145,9 +506,14 @@ func (r *realRepository) insertLedgerWithParentID(doc models.Ledger, parentID uu
 // an empty slice. If there is an error parsing the ledgers then it will
 // return an error.
 func (r *realRepository) SelectLedgers(resourceID uuid.UUID, options Query) ([]models.Ledger, error) {
+	if err := r.authorize(resourceID, options, models.AccessRead); err != nil {
+		return nil, err
+	}
+
 	query, err := store.BuildQuery(
 		store.WithQueryTags(options.Tags),
 		store.WithQueryAuthorID(options.AuthorID),
+		store.WithQueryTenant(options.Tenant),
 	)
 	if err != nil {
 		return nil, err
@@ -163,6 +529,7 @@ func (r *realRepository) SelectLedgers(resourceID uuid.UUID, options Query) ([]m
 		doc, err := models.BuildLedger(
 			models.WithID(entity.ID),
 			models.WithParentID(entity.ParentID),
+			models.WithTenantID(entity.TenantID),
 			models.WithName(entity.Name),
 			models.WithResourceID(entity.ResourceID),
 			models.WithResourceAddress(entity.ResourceAddress),
@@ -172,6 +539,7 @@ func (r *realRepository) SelectLedgers(resourceID uuid.UUID, options Query) ([]m
 			models.WithTags(entity.Tags),
 			models.WithCreatedOn(entity.CreatedOn),
 			models.WithDeletedOn(entity.DeletedOn),
+			models.WithSignature(entity.SignatureKeyID, entity.Signature),
 		)
 		if err != nil {
 			return nil, err
@@ -187,8 +555,19 @@ func (r *realRepository) SelectLedgers(resourceID uuid.UUID, options Query) ([]m
 // with some additional qualifiers. If no ledgers are found it will return
 // an empty slice. If there is an error parsing the ledgers then it will
 // return an error.
-func (r *realRepository) SelectForkLedgers(resourceID uuid.UUID) ([]models.Ledger, error) {
-	entities, err := r.store.SelectForkRevisions(resourceID)
+func (r *realRepository) SelectForkLedgers(resourceID uuid.UUID, options Query) ([]models.Ledger, error) {
+	if err := r.authorize(resourceID, options, models.AccessRead); err != nil {
+		return nil, err
+	}
+
+	query, err := store.BuildQuery(
+		store.WithQueryTenant(options.Tenant),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := r.store.SelectForkRevisions(resourceID, query)
 	if err != nil {
 		return nil, err
 	}
@@ -198,6 +577,7 @@ func (r *realRepository) SelectForkLedgers(resourceID uuid.UUID) ([]models.Ledge
 		doc, err := models.BuildLedger(
 			models.WithID(entity.ID),
 			models.WithParentID(entity.ParentID),
+			models.WithTenantID(entity.TenantID),
 			models.WithName(entity.Name),
 			models.WithResourceID(entity.ResourceID),
 			models.WithResourceAddress(entity.ResourceAddress),
@@ -207,6 +587,7 @@ func (r *realRepository) SelectForkLedgers(resourceID uuid.UUID) ([]models.Ledge
 			models.WithTags(entity.Tags),
 			models.WithCreatedOn(entity.CreatedOn),
 			models.WithDeletedOn(entity.DeletedOn),
+			models.WithSignature(entity.SignatureKeyID, entity.Signature),
 		)
 		if err != nil {
 			return nil, err
@@ -218,115 +599,752 @@ func (r *realRepository) SelectForkLedgers(resourceID uuid.UUID) ([]models.Ledge
 	return res, nil
 }
 
-func (r *realRepository) LedgerStatistics() (models.LedgerStatistics, error) {
-	stats, err := r.store.Statistics()
+// SelectResources returns the ids of all the resources that have a revision
+// by the author, and with any of the tags, of the query.
+func (r *realRepository) SelectResources(options Query) ([]uuid.UUID, error) {
+	query, err := store.BuildQuery(
+		store.WithQueryTags(options.Tags),
+		store.WithQueryAuthorID(options.AuthorID),
+		store.WithQueryTenant(options.Tenant),
+	)
 	if err != nil {
-		return models.LedgerStatistics{}, err
+		return nil, err
 	}
 
-	return models.LedgerStatistics{
-		TotalLedgers: stats.Total,
-	}, nil
+	return r.store.SelectResources(query)
 }
 
-// PutContent inserts content into the repository, this will make sure that
-// links to the content is managed by the ledger storage. If there is an error
-// during the saving of the content to the underlying storage it will then
-// return an error.
-func (r *realRepository) SelectContent(resourceID uuid.UUID, options Query) (content models.Content, err error) {
-	var doc models.Ledger
-	doc, err = r.SelectLedger(resourceID, options)
+func (r *realRepository) LedgerStatistics(options Query) (models.LedgerStatistics, error) {
+	stats, err := r.store.Statistics(store.Query{
+		Tags:     options.Tags,
+		AuthorID: options.AuthorID,
+		Tenant:   options.Tenant,
+	})
 	if err != nil {
-		level.Error(r.logger).Log("action", "content", "case", "get", "err", err.Error())
-		return
+		return models.LedgerStatistics{}, err
+	}
+
+	res := models.LedgerStatistics{
+		TotalLedgers:   stats.Total,
+		TotalResources: stats.Resources,
+		TotalBytes:     stats.Bytes,
+		ContentTypes:   stats.ContentTypes,
+		Revisions:      make([]models.RevisionBucket, len(stats.Revisions)),
+		Authors:        make([]models.LedgerCount, len(stats.Authors)),
+		Tags:           make([]models.LedgerCount, len(stats.Tags)),
+		Inserts:        make([]models.InsertWindow, len(stats.Inserts)),
+	}
+	for k, v := range stats.Revisions {
+		res.Revisions[k] = models.RevisionBucket{
+			Min:       v.Min,
+			Max:       v.Max,
+			Resources: v.Resources,
+		}
+	}
+	for k, v := range stats.Authors {
+		res.Authors[k] = models.LedgerCount{Name: v.Name, Ledgers: v.Ledgers}
 	}
+	for k, v := range stats.Tags {
+		res.Tags[k] = models.LedgerCount{Name: v.Name, Ledgers: v.Ledgers}
+	}
+	for k, v := range stats.Inserts {
+		res.Inserts[k] = models.InsertWindow{Window: v.Window, Ledgers: v.Ledgers}
+	}
+	return res, nil
+}
 
-	var file fsys.File
-	file, err = r.fs.Open(doc.ResourceAddress())
+// VerifyLedger recomputes the hash chain of a ledger, from the root revision
+// through to the head revision, reporting the first revision where the chain
+// is broken. If no ledger exists it will return an error.
+func (r *realRepository) VerifyLedger(resourceID uuid.UUID, options Query) (models.LedgerVerification, error) {
+	entities, err := r.store.SelectForkRevisions(resourceID, store.Query{Tenant: options.Tenant})
 	if err != nil {
-		level.Error(r.logger).Log("action", "content", "case", "open", "err", err.Error(), "resource", doc.ResourceAddress())
-		if fsys.ErrNotFound(err) {
-			err = errNotFound{err}
-			return
+		return models.LedgerVerification{}, err
+	}
+	if len(entities) == 0 {
+		return models.LedgerVerification{}, errNotFound{errors.New("not found")}
+	}
+
+	chain := chainEntities(entities)
+	res := models.LedgerVerification{
+		ResourceID: resourceID,
+		Revisions:  len(chain),
+	}
+
+	var parentHash string
+	for k, entity := range chain {
+		var reason string
+		switch {
+		case k == 0 && !entity.ParentID.Zero():
+			reason = "parent revision not found"
+		case entity.Hash == "":
+			reason = "missing hash"
+		case store.HashEntity(entity, parentHash) != entity.Hash:
+			reason = "hash mismatch"
 		}
-		return
+		if reason != "" {
+			res.BrokenID = entity.ID
+			res.Reason = reason
+			return res, nil
+		}
+		parentHash = entity.Hash
 	}
 
-	return models.BuildContent(
-		models.WithAddress(doc.ResourceAddress()),
-		models.WithSize(file.Size()),
-		models.WithContentType(doc.ResourceContentType()),
-		models.WithReader(file),
-	)
+	res.Valid = true
+	return res, nil
 }
 
-// PutContent inserts content into the repository. If there is an error
-// putting content into the repository then it will return an error.
-func (r *realRepository) PutContent(content models.Content) (res models.Content, err error) {
-	var bytes []byte
-	bytes, err = content.Bytes()
+// SelectACL returns the access control list of a resource. If no ledger
+// exists it will return an error.
+func (r *realRepository) SelectACL(resourceID uuid.UUID, options Query) (models.ACL, error) {
+	acl, err := r.effectiveACL(resourceID, options.Tenant)
 	if err != nil {
-		return
+		return models.ACL{}, err
 	}
-
-	if len(bytes) < 1 {
-		err = errors.Errorf("no content")
-		return
+	if err = checkAccess(acl, options, models.AccessRead); err != nil {
+		return models.ACL{}, err
 	}
+	return acl, nil
+}
 
-	// Content already exists, return out quickly.
-	if r.fs.Exists(content.Address()) {
-		res = content
-		return
+// UpdateACL replaces the access control list of a resource, which only the
+// owner of the resource can do. The change is recorded as a new revision of
+// the resource by the principal, so that it's part of the hash chain. If no
+// ledger exists it will return an error.
+func (r *realRepository) UpdateACL(resourceID uuid.UUID, acl models.ACL, options Query) (models.ACL, error) {
+	current, err := r.effectiveACL(resourceID, options.Tenant)
+	if err != nil {
+		return models.ACL{}, err
+	}
+	if err = checkAccess(current, options, models.AccessOwner); err != nil {
+		return models.ACL{}, err
 	}
 
-	var file fsys.File
-	file, err = r.fs.Create(content.Address())
+	head, err := r.SelectLedger(resourceID, Query{Tenant: options.Tenant})
 	if err != nil {
-		return
+		return models.ACL{}, err
 	}
 
-	if err = file.SetContentType(content.ContentType()); err != nil {
-		return
+	// The change is made by the principal, rather than the owner, who might
+	// not have made it. Without a principal the caller isn't known, so the
+	// change isn't attributed to anyone.
+	var updatedBy string
+	if options.Principal != nil {
+		updatedBy = options.Principal.ID
 	}
 
-	if _, err = file.Write(bytes); err != nil {
-		return
+	now := time.Now()
+	revision, err := models.BuildLedger(
+		models.WithTenantID(head.TenantID()),
+		models.WithName(head.Name()),
+		models.WithResourceID(head.ResourceID()),
+		models.WithResourceAddress(head.ResourceAddress()),
+		models.WithResourceSize(head.ResourceSize()),
+		models.WithResourceContentType(head.ResourceContentType()),
+		models.WithAuthorID(updatedBy),
+		models.WithTags(head.Tags()),
+		models.WithCreatedOn(now),
+		models.WithDeletedOn(head.DeletedOn()),
+	)
+	if err != nil {
+		return models.ACL{}, err
 	}
 
-	if err = file.Sync(); err != nil {
-		return
+	entity := store.ACL{
+		TenantID:   options.Tenant,
+		ResourceID: resourceID,
+		Owner:      acl.Owner,
+		Readers:    acl.Readers,
+		Writers:    acl.Writers,
+		UpdatedBy:  updatedBy,
+		CreatedOn:  now,
+	}
+	res, err := r.insertLedgerWithParentID(revision, head.ID(), entity)
+	if err != nil {
+		return models.ACL{}, err
 	}
+	r.notify(models.WebhookEventAppend, res)
+
+	level.Info(r.logger).Log("action", "acl", "resource_id", resourceID.String(), "updated_by", updatedBy)
 
-	return content, nil
+	return aclToModel(entity), nil
 }
 
-// PutContent inserts content into the repository, this will make sure that
-// links to the content is managed by the ledger storage. If there is an error
-// during the saving of the content to the underlying storage it will then
-// return an error.
-func (r *realRepository) SelectContents(resourceID uuid.UUID, options Query) (contents []models.Content, err error) {
-	var docs []models.Ledger
-	docs, err = r.SelectLedgers(resourceID, options)
+// SelectACLRevisions returns all the revisions of the access control list of
+// a resource, oldest first.
+func (r *realRepository) SelectACLRevisions(resourceID uuid.UUID, options Query) ([]models.ACL, error) {
+	acl, err := r.effectiveACL(resourceID, options.Tenant)
 	if err != nil {
-		return
+		return nil, err
 	}
-	if len(docs) == 0 {
-		contents = make([]models.Content, 0)
-		return
+	if err = checkAccess(acl, options, models.AccessRead); err != nil {
+		return nil, err
 	}
 
-	var (
+	acls, err := r.store.SelectACLRevisions(resourceID, store.Query{Tenant: options.Tenant})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models.ACL, len(acls))
+	for k, acl := range acls {
+		res[k] = aclToModel(acl)
+	}
+	return res, nil
+}
+
+// Authorize checks that the principal of the query has the access to the
+// resource.
+func (r *realRepository) Authorize(resourceID uuid.UUID, access models.Access, options Query) error {
+	return r.authorize(resourceID, options, access)
+}
+
+// authorize checks that the principal of the query has the access to the
+// resource. If there is no principal, then there is nothing to check against.
+func (r *realRepository) authorize(resourceID uuid.UUID, options Query, access models.Access) error {
+	if options.Principal == nil {
+		return nil
+	}
+
+	acl, err := r.effectiveACL(resourceID, options.Tenant)
+	if err != nil {
+		// Leave it to the caller to report a resource that doesn't exist.
+		if ErrNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return checkAccess(acl, options, access)
+}
+
+// effectiveACL returns the access control list of a resource with in the
+// tenant. Resources that were created before access control lists existed are
+// owned by the author of the head revision, but can be read and written by
+// anyone.
+func (r *realRepository) effectiveACL(resourceID uuid.UUID, tenant string) (models.ACL, error) {
+	head, err := r.SelectLedger(resourceID, Query{Tenant: tenant})
+	if err != nil {
+		return models.ACL{}, err
+	}
+
+	acl, err := r.store.SelectACL(resourceID, store.Query{Tenant: tenant})
+	if err == nil {
+		return aclToModel(acl), nil
+	}
+	if !store.ErrNotFound(err) {
+		return models.ACL{}, err
+	}
+	return models.ACL{
+		ResourceID: resourceID,
+		Owner:      head.AuthorID(),
+		Readers:    []string{models.ACLEveryone},
+		Writers:    []string{models.ACLEveryone},
+		CreatedOn:  head.CreatedOn(),
+	}, nil
+}
+
+func checkAccess(acl models.ACL, options Query, access models.Access) error {
+	principal := options.Principal
+	if principal == nil || acl.Allows(principal.ID, principal.Groups, access) {
+		return nil
+	}
+	return errForbidden{errors.Errorf("%q does not have access to resource %q", principal.ID, acl.ResourceID.String())}
+}
+
+func aclToModel(acl store.ACL) models.ACL {
+	return models.ACL{
+		ResourceID: acl.ResourceID,
+		Owner:      acl.Owner,
+		Readers:    acl.Readers,
+		Writers:    acl.Writers,
+		UpdatedBy:  acl.UpdatedBy,
+		CreatedOn:  acl.CreatedOn,
+	}
+}
+
+// InsertAuthorKey registers a Ed25519 public key for the author, which is
+// then used to verify the signatures of the ledgers by the author.
+func (r *realRepository) InsertAuthorKey(authorID string, publicKey []byte, options Query) (models.AuthorKey, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return models.AuthorKey{}, errInvalidSignature{errors.Errorf("invalid public key size %d", len(publicKey))}
+	}
+
+	keyID := signingKeyID(publicKey)
+	if err := r.store.InsertAuthorKey(store.AuthorKey{
+		TenantID:  options.Tenant,
+		AuthorID:  authorID,
+		KeyID:     keyID,
+		PublicKey: publicKey,
+		CreatedOn: time.Now(),
+	}); err != nil {
+		return models.AuthorKey{}, err
+	}
+
+	// The key could already have been registered, so return the one that is
+	// stored.
+	key, err := r.store.SelectAuthorKey(options.Tenant, authorID, keyID)
+	if err != nil {
+		return models.AuthorKey{}, err
+	}
+	return authorKeyToModel(key), nil
+}
+
+// SelectAuthorKeys returns all the public keys registered for the author.
+func (r *realRepository) SelectAuthorKeys(authorID string, options Query) ([]models.AuthorKey, error) {
+	keys, err := r.store.SelectAuthorKeys(options.Tenant, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models.AuthorKey, len(keys))
+	for k, key := range keys {
+		res[k] = authorKeyToModel(key)
+	}
+	return res, nil
+}
+
+// verifySignature verifies the signature of the ledger against the registered
+// key of the author.
+func (r *realRepository) verifySignature(doc models.Ledger) error {
+	key, err := r.store.SelectAuthorKey(doc.TenantID(), doc.AuthorID(), doc.SignatureKeyID())
+	if err != nil {
+		if store.ErrNotFound(err) {
+			return errInvalidSignature{errors.Errorf("unknown key %q for author %q", doc.SignatureKeyID(), doc.AuthorID())}
+		}
+		return err
+	}
+	if err := doc.VerifySignature(key.PublicKey); err != nil {
+		return errInvalidSignature{err}
+	}
+	return nil
+}
+
+func authorKeyToModel(key store.AuthorKey) models.AuthorKey {
+	return models.AuthorKey{
+		AuthorID:  key.AuthorID,
+		KeyID:     key.KeyID,
+		PublicKey: key.PublicKey,
+		CreatedOn: key.CreatedOn,
+	}
+}
+
+// Checkpoint appends all the new ledgers to the checkpoint tree and then
+// signs the new tree head. If there are no new ledgers, then the latest
+// checkpoint is returned.
+func (r *realRepository) Checkpoint() (models.Checkpoint, error) {
+	if r.signer == nil {
+		return models.Checkpoint{}, errors.New("checkpoints are not configured")
+	}
+
+	size, err := r.store.AppendLeaves()
+	if err != nil {
+		return models.Checkpoint{}, err
+	}
+	if size == 0 {
+		return models.Checkpoint{}, errNotFound{errors.New("no ledgers to checkpoint")}
+	}
+
+	if err = r.appendNodes(size); err != nil {
+		return models.Checkpoint{}, err
+	}
+
+	latest, err := r.store.SelectCheckpoint(0)
+	if err == nil && latest.TreeSize == size {
+		return checkpointToModel(latest), nil
+	} else if err != nil && !store.ErrNotFound(err) {
+		return models.Checkpoint{}, err
+	}
+
+	nodes, err := r.selectNodes(merkle.RootNodes(size))
+	if err != nil {
+		return models.Checkpoint{}, err
+	}
+	root, err := nodes.RootHash(size)
+	if err != nil {
+		return models.Checkpoint{}, err
+	}
+
+	head := merkle.TreeHead{
+		TreeSize:  size,
+		RootHash:  root,
+		Timestamp: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	signature, err := r.signer.Sign(head.Bytes())
+	if err != nil {
+		return models.Checkpoint{}, err
+	}
+
+	if err = r.store.InsertCheckpoint(store.Checkpoint{
+		TreeSize:  head.TreeSize,
+		RootHash:  head.RootHash,
+		KeyID:     r.signer.KeyID(),
+		Signature: signature,
+		CreatedOn: head.Timestamp,
+	}); err != nil {
+		return models.Checkpoint{}, err
+	}
+
+	level.Info(r.logger).Log("action", "checkpoint", "tree_size", size)
+
+	// Another checkpoint for the same tree size could have been inserted
+	// first, so return the one that is stored.
+	return r.SelectCheckpoint(size)
+}
+
+// SelectCheckpoint returns the checkpoint for the tree size, or the latest
+// checkpoint if the tree size is zero.
+func (r *realRepository) SelectCheckpoint(treeSize int64) (models.Checkpoint, error) {
+	checkpoint, err := r.store.SelectCheckpoint(treeSize)
+	if err != nil {
+		if store.ErrNotFound(err) {
+			return models.Checkpoint{}, errNotFound{err}
+		}
+		return models.Checkpoint{}, err
+	}
+	return checkpointToModel(checkpoint), nil
+}
+
+// SelectCheckpointKey returns the public key that checkpoints are signed with.
+func (r *realRepository) SelectCheckpointKey() (models.CheckpointKey, error) {
+	if r.signer == nil {
+		return models.CheckpointKey{}, errNotFound{errors.New("checkpoints are not configured")}
+	}
+	return models.CheckpointKey{
+		KeyID:     r.signer.KeyID(),
+		PublicKey: r.signer.PublicKey(),
+	}, nil
+}
+
+// SelectLedgerProof returns the proof that the ledger is included in the
+// checkpoint for the tree size, along with the proof that the checkpoint for
+// the fromTreeSize is consistent with it. The ledger has to be in the tenant
+// of the query and readable by the principal of the query. The proofs are
+// made from the stored nodes of the tree, rather than from every leaf.
+func (r *realRepository) SelectLedgerProof(ledgerID uuid.UUID, treeSize, fromTreeSize int64, options Query) (models.LedgerProof, error) {
+	entity, err := r.store.SelectEntity(ledgerID, store.Query{Tenant: options.Tenant})
+	if err != nil {
+		if store.ErrNotFound(err) {
+			return models.LedgerProof{}, errNotFound{err}
+		}
+		return models.LedgerProof{}, err
+	}
+	if err = r.authorize(entity.ResourceID, options, models.AccessRead); err != nil {
+		return models.LedgerProof{}, err
+	}
+
+	checkpoint, err := r.SelectCheckpoint(treeSize)
+	if err != nil {
+		return models.LedgerProof{}, err
+	}
+
+	leaf, err := r.store.SelectLeaf(ledgerID)
+	if err != nil {
+		if store.ErrNotFound(err) {
+			return models.LedgerProof{}, errNotFound{err}
+		}
+		return models.LedgerProof{}, err
+	}
+	if leaf.Index >= checkpoint.TreeSize {
+		return models.LedgerProof{}, errNotFound{errors.Errorf("ledger %s is not included in checkpoint %d", ledgerID, checkpoint.TreeSize)}
+	}
+
+	var (
+		from *models.Checkpoint
+		ids  = merkle.InclusionNodes(leaf.Index, checkpoint.TreeSize)
+	)
+	if fromTreeSize != 0 {
+		earlier, err := r.SelectCheckpoint(fromTreeSize)
+		if err != nil {
+			return models.LedgerProof{}, err
+		}
+		if earlier.TreeSize > checkpoint.TreeSize {
+			return models.LedgerProof{}, errors.Errorf("checkpoint %d is after checkpoint %d", earlier.TreeSize, checkpoint.TreeSize)
+		}
+		from = &earlier
+		ids = append(ids, merkle.ConsistencyNodes(from.TreeSize, checkpoint.TreeSize)...)
+	}
+
+	nodes, err := r.selectNodes(ids)
+	if err != nil {
+		return models.LedgerProof{}, err
+	}
+
+	inclusion, err := nodes.InclusionProof(leaf.Index, checkpoint.TreeSize)
+	if err != nil {
+		return models.LedgerProof{}, err
+	}
+
+	proof := models.LedgerProof{
+		LedgerID:   leaf.LedgerID,
+		LedgerHash: leaf.Hash,
+		LeafIndex:  leaf.Index,
+		Checkpoint: checkpoint,
+		Inclusion:  inclusion,
+	}
+
+	if from != nil {
+		consistency, err := nodes.ConsistencyProof(from.TreeSize, checkpoint.TreeSize)
+		if err != nil {
+			return models.LedgerProof{}, err
+		}
+
+		proof.FromCheckpoint = from
+		proof.Consistency = consistency
+	}
+
+	return proof, nil
+}
+
+// appendNodes inserts the nodes of the checkpoint tree for the leaves that
+// the nodes haven't been inserted for yet, up to the size, a batch of leaves
+// at a time. Replicas that append at the same time insert the same nodes.
+func (r *realRepository) appendNodes(size int64) error {
+	start, err := r.store.SelectNodesTreeSize()
+	if err != nil {
+		return err
+	}
+
+	for start < size {
+		end := start + defaultNodesBatchSize
+		if end > size {
+			end = size
+		}
+
+		leaves, err := r.store.SelectLeaves(start, end)
+		if err != nil {
+			return err
+		}
+		if int64(len(leaves)) != end-start {
+			return errors.Errorf("expected %d leaves, got %d", end-start, len(leaves))
+		}
+
+		nodes, err := r.selectNodes(merkle.RootNodes(start))
+		if err != nil {
+			return err
+		}
+
+		hashes := make([][]byte, len(leaves))
+		for k, leaf := range leaves {
+			hashes[k] = merkle.LedgerLeafHash(leaf.LedgerID.String(), leaf.Hash)
+		}
+		appended, err := nodes.Append(start, hashes)
+		if err != nil {
+			return err
+		}
+
+		res := make([]store.Node, len(appended))
+		for k, v := range appended {
+			res[k] = store.Node{Level: v.Level, Index: v.Index, Hash: v.Hash}
+		}
+		if err = r.store.InsertNodes(res); err != nil {
+			return err
+		}
+
+		start = end
+	}
+	return nil
+}
+
+// selectNodes returns the stored nodes of the checkpoint tree with the ids.
+func (r *realRepository) selectNodes(ids []merkle.NodeID) (merkle.Nodes, error) {
+	req := make([]store.NodeID, len(ids))
+	for k, v := range ids {
+		req[k] = store.NodeID{Level: v.Level, Index: v.Index}
+	}
+
+	nodes, err := r.store.SelectNodes(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(merkle.Nodes, len(nodes))
+	for _, v := range nodes {
+		res[merkle.NodeID{Level: v.Level, Index: v.Index}] = v.Hash
+	}
+	return res, nil
+}
+
+func checkpointToModel(checkpoint store.Checkpoint) models.Checkpoint {
+	return models.Checkpoint{
+		TreeSize:  checkpoint.TreeSize,
+		RootHash:  checkpoint.RootHash,
+		KeyID:     checkpoint.KeyID,
+		Signature: checkpoint.Signature,
+		CreatedOn: checkpoint.CreatedOn,
+	}
+}
+
+// chainEntities orders the entities by following the parent links back from
+// the head entity, so that the chain starts at the root.
+func chainEntities(entities []store.Entity) []store.Entity {
+	links := make(map[uuid.UUID]store.Entity, len(entities))
+	for _, entity := range entities {
+		links[entity.ID] = entity
+	}
+
+	var (
+		head  = entities[len(entities)-1]
+		chain = []store.Entity{head}
+	)
+	for len(chain) < len(entities) {
+		parent, ok := links[head.ParentID]
+		if !ok || head.ParentID.Zero() {
+			break
+		}
+		head = parent
+		chain = append(chain, head)
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// PutContent inserts content into the repository, this will make sure that
+// links to the content is managed by the ledger storage. If there is an error
+// during the saving of the content to the underlying storage it will then
+// return an error.
+func (r *realRepository) SelectContent(resourceID uuid.UUID, options Query) (content models.Content, err error) {
+	var doc models.Ledger
+	doc, err = r.SelectLedger(resourceID, options)
+	if err != nil {
+		level.Error(r.logger).Log("action", "content", "case", "get", "err", err.Error())
+		return
+	}
+
+	if err = checkErased(doc); err != nil {
+		return
+	}
+
+	var blob Blob
+	blob, err = r.openBlob(doc.TenantID(), doc.ResourceID(), doc.ResourceAddress())
+	if err != nil {
+		level.Error(r.logger).Log("action", "content", "case", "open", "err", err.Error(), "resource", doc.ResourceAddress())
+		return
+	}
+
+	return r.buildContent(doc, blob, options.AcceptEncoding)
+}
+
+// PutContent inserts content into the repository. If there is an error
+// putting content into the repository then it will return an error.
+func (r *realRepository) PutContent(content models.Content, query Query) (res models.Content, err error) {
+	reader := content.Reader()
+	if reader == nil {
+		err = errors.Errorf("no content")
+		return
+	}
+	defer reader.Close()
+
+	// The content isn't counted against the quota until a ledger refers to it,
+	// but there is no point storing content that can never be referred to.
+	var authorID string
+	if query.AuthorID != nil {
+		authorID = *query.AuthorID
+	}
+	if err = r.checkQuota(query.Tenant, authorID, store.Usage{
+		Bytes: content.Size(),
+	}); err != nil {
+		return
+	}
+
+	// Make sure that there is something to read, before storing it.
+	buffered := bufio.NewReader(reader)
+	if _, err = buffered.Peek(1); err != nil {
+		if err == io.EOF {
+			err = errors.Errorf("no content")
+		}
+		return
+	}
+
+	options := BlobOptions{
+		ContentType:     content.ContentType(),
+		ContentEncoding: r.compression.EncodingFor(content.ContentType()),
+	}
+
+	var source io.Reader = buffered
+	if r.keys != nil {
+		// The data key is looked up by address, so the address has to be known
+		// before any of the content can be encrypted. Rather than holding the
+		// content in memory, it's hashed as it's spooled to a temporary file.
+		var spool *os.File
+		if spool, err = ioutil.TempFile("", "snowy-content-"); err != nil {
+			return
+		}
+		defer func() {
+			spool.Close()
+			os.Remove(spool.Name())
+		}()
+
+		hw := newHashingWriter()
+		if _, err = io.Copy(io.MultiWriter(spool, hw), buffered); err != nil {
+			return
+		}
+		if _, err = spool.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+
+		address := hw.Address()
+		if expected := content.Address(); expected != "" && expected != address {
+			err = errors.Errorf("content address mismatch, expected %q, got %q", expected, address)
+			return
+		}
+
+		if options.Cipher, err = r.createCipher(query.Tenant, address); err != nil {
+			return
+		}
+		source = spool
+	}
+
+	var address string
+	address, err = r.blobs.Tenant(query.Tenant).Put(source, options)
+	if err != nil {
+		return
+	}
+
+	if expected := content.Address(); expected != "" && expected != address {
+		err = errors.Errorf("content address mismatch, expected %q, got %q", expected, address)
+		return
+	}
+
+	return models.BuildContent(
+		models.WithAddress(address),
+		models.WithSize(content.Size()),
+		models.WithContentType(content.ContentType()),
+	)
+}
+
+// PutContent inserts content into the repository, this will make sure that
+// links to the content is managed by the ledger storage. If there is an error
+// during the saving of the content to the underlying storage it will then
+// return an error.
+func (r *realRepository) SelectContents(resourceID uuid.UUID, options Query) (contents []models.Content, err error) {
+	var docs []models.Ledger
+	docs, err = r.SelectLedgers(resourceID, options)
+	if err != nil {
+		return
+	}
+	if len(docs) == 0 {
+		contents = make([]models.Content, 0)
+		return
+	}
+	for _, doc := range docs {
+		if err = checkErased(doc); err != nil {
+			return
+		}
+	}
+
+	var (
 		notFound      = make(chan struct{})
 		internalError = make(chan error)
 		result        = make(chan models.Content)
 	)
 	for _, k := range docs {
 		go func(doc models.Ledger) {
-			var file fsys.File
-			file, err = r.fs.Open(doc.ResourceAddress())
+			blob, err := r.openBlob(doc.TenantID(), doc.ResourceID(), doc.ResourceAddress())
 			if err != nil {
-				if fsys.ErrNotFound(err) {
+				if ErrNotFound(err) {
 					notFound <- struct{}{}
 					return
 				}
@@ -334,12 +1352,7 @@ func (r *realRepository) SelectContents(resourceID uuid.UUID, options Query) (co
 				return
 			}
 
-			content, err := models.BuildContent(
-				models.WithAddress(doc.ResourceAddress()),
-				models.WithSize(file.Size()),
-				models.WithContentType(doc.ResourceContentType()),
-				models.WithReader(file),
-			)
+			content, err := r.buildContent(doc, blob, options.AcceptEncoding)
 			if err != nil {
 				internalError <- err
 				return
@@ -362,6 +1375,446 @@ func (r *realRepository) SelectContents(resourceID uuid.UUID, options Query) (co
 	return res, nil
 }
 
+// SelectRevisionContent returns the content of a revision of a ledger. The
+// revision is expected to be one of the revisions of the tenant of the query.
+func (r *realRepository) SelectRevisionContent(doc models.Ledger, options Query) (models.Content, error) {
+	if err := r.authorize(doc.ResourceID(), options, models.AccessRead); err != nil {
+		return models.Content{}, err
+	}
+	if err := checkErased(doc); err != nil {
+		return models.Content{}, err
+	}
+	if err := models.WithTenantID(options.Tenant)(&doc); err != nil {
+		return models.Content{}, err
+	}
+
+	blob, err := r.openBlob(options.Tenant, doc.ResourceID(), doc.ResourceAddress())
+	if err != nil {
+		return models.Content{}, err
+	}
+	return r.buildContent(doc, blob, options.AcceptEncoding)
+}
+
+// buildContent creates the content for a ledger from the blob. If the blob is
+// encoded with an encoding that's acceptable, the blob is passed through as
+// is, otherwise the blob is decrypted and decoded transparently.
+func (r *realRepository) buildContent(doc models.Ledger, blob Blob, acceptEncoding []string) (models.Content, error) {
+	var (
+		info   = blob.Info()
+		reader = io.ReadCloser(blob)
+	)
+	if info.Encrypted {
+		cipher, err := r.selectCipher(doc.TenantID(), doc.ResourceID(), doc.ResourceAddress())
+		if err != nil {
+			blob.Close()
+			return models.Content{}, err
+		}
+		if reader, err = cipher.Decrypt(blob); err != nil {
+			blob.Close()
+			return models.Content{}, err
+		}
+	}
+
+	// The size of encrypted content is only known once decoded, as the stored
+	// size includes the encryption overhead.
+	if !info.Encrypted && info.ContentEncoding != EncodingIdentity && acceptsEncoding(acceptEncoding, info.ContentEncoding) {
+		return models.BuildContent(
+			models.WithAddress(doc.ResourceAddress()),
+			models.WithSize(info.StoredSize),
+			models.WithContentType(doc.ResourceContentType()),
+			models.WithContentEncoding(info.ContentEncoding),
+			models.WithReader(reader),
+		)
+	}
+
+	decoded, err := newDecoder(reader, info.ContentEncoding)
+	if err != nil {
+		reader.Close()
+		return models.Content{}, err
+	}
+
+	return models.BuildContent(
+		models.WithAddress(doc.ResourceAddress()),
+		models.WithSize(info.Size),
+		models.WithContentType(doc.ResourceContentType()),
+		models.WithReader(decoded),
+	)
+}
+
+// EraseLedger erases the content of a resource, by destroying the key
+// material of the resource for all the content referenced by its revisions.
+// Every resource has its own copy of the key, so other resources referencing
+// the same content can still read it, and the content is only removed once
+// no resource can. Erasing relies on the keys, so without encryption it
+// returns a not supported error.
+func (r *realRepository) EraseLedger(resourceID uuid.UUID, options Query) (models.Ledger, error) {
+	if err := r.checkErasable(); err != nil {
+		return models.Ledger{}, err
+	}
+
+	head, err := r.SelectLedger(resourceID, Query{Tenant: options.Tenant})
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	// Erasing is idempotent, so if it's already been erased, just return the
+	// tombstone.
+	if !head.DeletedOn().IsZero() {
+		return head, nil
+	}
+
+	docs, err := r.SelectLedgers(resourceID, Query{Tenant: options.Tenant})
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	var (
+		now       = time.Now()
+		addresses = make(map[string]struct{})
+	)
+	for _, doc := range docs {
+		address := doc.ResourceAddress()
+		if _, ok := addresses[address]; ok || address == "" {
+			continue
+		}
+		addresses[address] = struct{}{}
+
+		if err := r.eraseContent(options.Tenant, resourceID, address, now); err != nil {
+			return models.Ledger{}, err
+		}
+	}
+
+	tombstone, err := models.BuildLedger(
+		models.WithTenantID(head.TenantID()),
+		models.WithName(head.Name()),
+		models.WithResourceID(head.ResourceID()),
+		models.WithResourceAddress(head.ResourceAddress()),
+		models.WithResourceSize(head.ResourceSize()),
+		models.WithResourceContentType(head.ResourceContentType()),
+		models.WithAuthorID(head.AuthorID()),
+		models.WithTags(head.Tags()),
+		models.WithCreatedOn(now),
+		models.WithDeletedOn(now),
+	)
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	level.Info(r.logger).Log("action", "erase", "resource_id", resourceID.String(), "addresses", len(addresses))
+
+	res, err := r.insertLedgerWithParentID(tombstone, head.ID())
+	if err != nil {
+		return models.Ledger{}, err
+	}
+	r.notify(models.WebhookEventDelete, res)
+
+	return res, nil
+}
+
+// EraseAuthorLedgers erases the content of all the resources that have
+// revisions by the author, returning the tombstone revisions.
+func (r *realRepository) EraseAuthorLedgers(authorID string, options Query) ([]models.Ledger, error) {
+	if err := r.checkErasable(); err != nil {
+		return nil, err
+	}
+
+	resourceIDs, err := r.store.SelectAuthorResources(authorID, store.Query{Tenant: options.Tenant})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models.Ledger, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		tombstone, err := r.EraseLedger(resourceID, options)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, tombstone)
+	}
+	return res, nil
+}
+
+// checkErasable returns a not supported error if content can't be erased, as
+// there are no keys to destroy without encryption.
+func (r *realRepository) checkErasable() error {
+	if r.keys == nil {
+		return errNotSupported{errors.New("erasing content requires encryption")}
+	}
+	return nil
+}
+
+func (r *realRepository) eraseContent(tenant string, resourceID uuid.UUID, address string, erasedOn time.Time) error {
+	// Once the key of the resource is destroyed, the resource can never read
+	// the content again, even if the same content is stored again later.
+	if err := r.store.DestroyKey(tenant, resourceID, address, erasedOn); err != nil {
+		return err
+	}
+
+	// Any other resource that refers to the content has a key of its own, so
+	// the content is only removed once none of them can read it.
+	keys, err := r.store.SelectAddressKeys(tenant, address)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if !key.ResourceID.Zero() && !key.Destroyed() {
+			return nil
+		}
+	}
+
+	// Content that was stored before encryption was enabled has no keys, so
+	// it's only removed once no resource that hasn't been erased refers to it.
+	live, err := r.referenced(tenant, resourceID, address)
+	if err != nil || live {
+		return err
+	}
+
+	if err = r.store.DestroyKey(tenant, uuid.Empty, address, erasedOn); err != nil {
+		return err
+	}
+	if err = r.blobs.Tenant(tenant).Delete(address); err != nil && !ErrNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// referenced checks if any resource, other than the one passed, that hasn't
+// been erased has a revision of the content at the address.
+func (r *realRepository) referenced(tenant string, resourceID uuid.UUID, address string) (bool, error) {
+	resourceIDs, err := r.store.SelectAddressResources(tenant, address)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range resourceIDs {
+		if id.Equals(resourceID) {
+			continue
+		}
+		head, err := r.store.Select(id, store.Query{Tenant: tenant})
+		if err != nil {
+			if store.ErrNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		if head.DeletedOn.IsZero() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// openBlob returns the blob for the address. If the blob was removed when it
+// was erased, then it will return an erased error.
+func (r *realRepository) openBlob(tenant string, resourceID uuid.UUID, address string) (Blob, error) {
+	blob, err := r.blobs.Tenant(tenant).Get(address)
+	if err == nil || !ErrNotFound(err) {
+		return blob, err
+	}
+
+	// Look for the destroyed key of the resource, or of the content itself.
+	for _, id := range []uuid.UUID{resourceID, uuid.Empty} {
+		if key, keyErr := r.store.SelectKey(tenant, id, address); keyErr == nil && key.Destroyed() {
+			return nil, erasedContent(address, key.DestroyedOn)
+		}
+	}
+	return nil, err
+}
+
+// checkErased returns a erased error if the ledger is a erasure tombstone.
+func checkErased(doc models.Ledger) error {
+	if deletedOn := doc.DeletedOn(); !deletedOn.IsZero() {
+		return errErased{errors.Errorf("content of resource %q was erased on %s", doc.ResourceID().String(), deletedOn.Format(time.RFC3339))}
+	}
+	return nil
+}
+
+func erasedContent(address string, erasedOn time.Time) error {
+	return errErased{errors.Errorf("content %q was erased on %s", address, erasedOn.Format(time.RFC3339))}
+}
+
+// createCipher returns the cipher that the content at the address is stored
+// with, which the content keeps a key of its own for until resources refer to
+// it. The key of content that's already stored is reused, so that it can
+// still be read by every resource that refers to it, otherwise a new data key
+// is created.
+func (r *realRepository) createCipher(tenant, address string) (Cipher, error) {
+	key, err := r.contentKey(tenant, address)
+	switch {
+	case err == nil && key.ResourceID.Zero():
+		return r.selectCipher(tenant, uuid.Empty, address)
+	case err == nil:
+	case ErrNotFound(err):
+		if key, err = r.createKey(tenant, address); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	key.TenantID = tenant
+	key.ResourceID = uuid.Empty
+	key.ResourceAddress = address
+	key.CreatedOn = time.Now()
+	if err = r.store.InsertKey(key); err != nil {
+		return nil, err
+	}
+
+	// Select the key again, as another key may have been inserted for the
+	// content at the same time, in which case that key is used.
+	return r.selectCipher(tenant, uuid.Empty, address)
+}
+
+// createKey creates a new wrapped data key for the content at the address.
+// Any content that's stored was encrypted with a key that's been destroyed,
+// so it can never be read again and is removed, to be replaced.
+func (r *realRepository) createKey(tenant, address string) (store.Key, error) {
+	blobs := r.blobs.Tenant(tenant)
+	if info, err := blobs.Stat(address); err == nil && info.Encrypted {
+		if err = blobs.Delete(address); err != nil && !ErrNotFound(err) {
+			return store.Key{}, err
+		}
+	}
+
+	dataKey, err := NewDataKey()
+	if err != nil {
+		return store.Key{}, err
+	}
+	wrapped, err := r.keys.WrapKey(dataKey)
+	if err != nil {
+		return store.Key{}, err
+	}
+	return store.Key{
+		KeyID:      r.keys.KeyID(),
+		WrappedKey: wrapped,
+	}, nil
+}
+
+// contentKey returns the key that the content at the address is stored with,
+// which is the key of the content itself, or else the key of any resource that
+// still refers to it. If every key has been destroyed, or there is no key, it
+// will return a not found error.
+func (r *realRepository) contentKey(tenant, address string) (store.Key, error) {
+	keys, err := r.store.SelectAddressKeys(tenant, address)
+	if err != nil {
+		return store.Key{}, err
+	}
+	for _, key := range keys {
+		if key.ResourceID.Zero() && !key.Destroyed() {
+			return key, nil
+		}
+	}
+	for _, key := range keys {
+		if !key.Destroyed() {
+			return key, nil
+		}
+	}
+	return store.Key{}, errNotFound{errors.Errorf("no key for content %q", address)}
+}
+
+// grantKey gives the resource of the ledger its own copy of the key of its
+// content, so that the resource can read the content until it's erased. A
+// resource that's had its key destroyed is never given the key again.
+func (r *realRepository) grantKey(doc models.Ledger) error {
+	if r.keys == nil || doc.ResourceAddress() == "" || !doc.DeletedOn().IsZero() {
+		return nil
+	}
+
+	var (
+		tenant     = doc.TenantID()
+		resourceID = doc.ResourceID()
+		address    = doc.ResourceAddress()
+	)
+	if _, err := r.store.SelectKey(tenant, resourceID, address); err == nil || !store.ErrNotFound(err) {
+		return err
+	}
+
+	key, err := r.contentKey(tenant, address)
+	if err != nil {
+		// Content that isn't encrypted, or that's been erased, has no key.
+		if ErrNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	key.ResourceID = resourceID
+	key.CreatedOn = time.Now()
+	return r.store.InsertKey(key)
+}
+
+// selectCipher returns the cipher for the content of a resource, unwrapping
+// the stored data key.
+func (r *realRepository) selectCipher(tenant string, resourceID uuid.UUID, address string) (Cipher, error) {
+	if r.keys == nil {
+		return nil, errors.New("encrypted content requires a key provider")
+	}
+
+	key, err := r.store.SelectKey(tenant, resourceID, address)
+	if err != nil {
+		return nil, err
+	}
+	if key.Destroyed() {
+		return nil, erasedContent(address, key.DestroyedOn)
+	}
+
+	dataKey, err := r.keys.UnwrapKey(key.KeyID, key.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewCipher(dataKey)
+}
+
+// SelectQuota returns the quota of the author, along with the current usage.
+// If the author is empty, then the quota of the whole tenant is returned.
+func (r *realRepository) SelectQuota(authorID string, options Query) (models.Quota, error) {
+	quota, err := r.store.SelectQuota(options.Tenant, authorID)
+	if err != nil && !store.ErrNotFound(err) {
+		return models.Quota{}, err
+	}
+
+	usage, err := r.store.SelectUsage(store.Query{
+		Tenant:   options.Tenant,
+		AuthorID: &authorID,
+	}, startOfDay(time.Now()))
+	if err != nil {
+		return models.Quota{}, err
+	}
+
+	return models.Quota{
+		AuthorID:           authorID,
+		MaxBytes:           quota.MaxBytes,
+		MaxResources:       quota.MaxResources,
+		MaxRevisionsPerDay: quota.MaxRevisionsPerDay,
+		Usage: models.QuotaUsage{
+			Bytes:          usage.Bytes,
+			Resources:      usage.Resources,
+			RevisionsToday: usage.Revisions,
+		},
+	}, nil
+}
+
+// UpdateQuota replaces the quota of the author of the quota, or of the whole
+// tenant if the author is empty.
+func (r *realRepository) UpdateQuota(quota models.Quota, options Query) (models.Quota, error) {
+	if err := r.store.InsertQuota(store.Quota{
+		TenantID:           options.Tenant,
+		AuthorID:           quota.AuthorID,
+		MaxBytes:           quota.MaxBytes,
+		MaxResources:       quota.MaxResources,
+		MaxRevisionsPerDay: quota.MaxRevisionsPerDay,
+		UpdatedOn:          time.Now(),
+	}); err != nil {
+		return models.Quota{}, err
+	}
+
+	// Make sure the new limits are enforced straight away.
+	if r.quotas != nil {
+		r.quotas.remove(options.Tenant, quota.AuthorID)
+	}
+
+	return r.SelectQuota(quota.AuthorID, options)
+}
+
 // Close the underlying ledger store and returns an error if it fails.
 func (r *realRepository) Close() error {
 	return nil
@@ -1,8 +1,13 @@
 package repository
 
 import (
+	"bytes"
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"reflect"
+	"sync"
 	"testing"
 	"testing/quick"
 	"time"
@@ -10,10 +15,13 @@ import (
 	"github.com/go-kit/kit/log"
 	gomock "github.com/golang/mock/gomock"
 	"github.com/trussle/fsys"
+	"github.com/trussle/snowy/pkg/events"
+	"github.com/trussle/snowy/pkg/merkle"
 	"github.com/trussle/snowy/pkg/models"
 	"github.com/trussle/snowy/pkg/store"
 	storeMocks "github.com/trussle/snowy/pkg/store/mocks"
 	"github.com/trussle/uuid"
+	"golang.org/x/crypto/ed25519"
 )
 
 func TestSelectLedger(t *testing.T) {
@@ -27,7 +35,7 @@ func TestSelectLedger(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -55,7 +63,7 @@ func TestSelectLedger(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -83,7 +91,7 @@ func TestSelectLedger(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -137,11 +145,11 @@ func TestInsertLedger(t *testing.T) {
 
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
-				Insert(gomock.Eq(entity)).
+				InsertLedgers(Entities(entity), gomock.Any()).
 				Return(errNotFound{errors.New("not found")})
 
 			_, err := repo.InsertLedger(doc)
@@ -183,11 +191,17 @@ func TestInsertLedger(t *testing.T) {
 
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
+			// The owner is inserted along with the ledger.
 			mock.EXPECT().
-				Insert(gomock.Eq(entity)).
+				InsertLedgers(Entities(entity), gomock.Any()).
+				Do(func(entities []store.Entity, acls []store.ACL) {
+					if len(acls) != 1 || !acls[0].ResourceID.Equals(resourceID) || acls[0].Owner != authorID {
+						t.Errorf("expected owner %q of %q, actual: %v", authorID, resourceID, acls)
+					}
+				}).
 				Return(nil)
 
 			res, err := repo.InsertLedger(doc)
@@ -228,14 +242,14 @@ func TestAppendLedger(t *testing.T) {
 
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
 				Select(resourceID, store.Query{}).
 				Return(store.Entity{}, errNotFound{errors.New("not found")})
 
-			_, err := repo.AppendLedger(resourceID, doc)
+			_, err := repo.AppendLedger(resourceID, doc, Query{})
 			if expected, actual := true, ErrNotFound(err); expected != actual {
 				t.Errorf("expected: %t, actual: %t", expected, actual)
 			}
@@ -273,17 +287,17 @@ func TestAppendLedger(t *testing.T) {
 
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
 				Select(resourceID, store.Query{}).
 				Return(entity, nil)
 			mock.EXPECT().
-				Insert(entity).
+				Insert(Entity(entity)).
 				Return(errNotFound{errors.New("not found")})
 
-			_, err := repo.AppendLedger(resourceID, doc)
+			_, err := repo.AppendLedger(resourceID, doc, Query{})
 			if expected, actual := true, ErrNotFound(err); expected != actual {
 				t.Errorf("expected: %t, actual: %t", expected, actual)
 			}
@@ -321,17 +335,17 @@ func TestAppendLedger(t *testing.T) {
 
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
 				Select(resourceID, store.Query{}).
 				Return(entity, nil)
 			mock.EXPECT().
-				Insert(entity).
+				Insert(Entity(entity)).
 				Return(nil)
 
-			res, err := repo.AppendLedger(resourceID, doc)
+			res, err := repo.AppendLedger(resourceID, doc, Query{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -369,14 +383,14 @@ func TestForkLedger(t *testing.T) {
 
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
 				Select(resourceID, store.Query{}).
 				Return(store.Entity{}, errNotFound{errors.New("not found")})
 
-			_, err := repo.ForkLedger(resourceID, doc)
+			_, err := repo.ForkLedger(resourceID, doc, Query{})
 			if expected, actual := true, ErrNotFound(err); expected != actual {
 				t.Errorf("expected: %t, actual: %t", expected, actual)
 			}
@@ -414,17 +428,17 @@ func TestForkLedger(t *testing.T) {
 
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
 				Select(resourceID, store.Query{}).
 				Return(entity, nil)
 			mock.EXPECT().
-				Insert(entity).
+				Insert(Entity(entity)).
 				Return(errNotFound{errors.New("not found")})
 
-			_, err := repo.ForkLedger(resourceID, doc)
+			_, err := repo.ForkLedger(resourceID, doc, Query{})
 			if expected, actual := true, ErrNotFound(err); expected != actual {
 				t.Errorf("expected: %t, actual: %t", expected, actual)
 			}
@@ -476,7 +490,7 @@ func TestForkLedger(t *testing.T) {
 
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -485,8 +499,19 @@ func TestForkLedger(t *testing.T) {
 			mock.EXPECT().
 				Insert(Entity(forkedEntity)).
 				Return(nil)
+			mock.EXPECT().
+				SelectACL(resourceID, store.Query{}).
+				Return(store.ACL{ResourceID: resourceID, Owner: authorID, Readers: tags}, nil)
+			mock.EXPECT().
+				InsertACL(gomock.Any()).
+				Do(func(acl store.ACL) {
+					if !acl.ResourceID.Equals(forkedID) || acl.Owner != authorID || !reflect.DeepEqual(acl.Readers, tags) {
+						t.Errorf("expected inherited acl of %q, actual: %v", forkedID, acl)
+					}
+				}).
+				Return(nil)
 
-			res, err := repo.ForkLedger(resourceID, doc)
+			res, err := repo.ForkLedger(resourceID, doc, Query{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -515,7 +540,7 @@ func TestSelectLedgers(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -543,7 +568,7 @@ func TestSelectLedgers(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -571,7 +596,7 @@ func TestSelectLedgers(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -611,14 +636,14 @@ func TestSelectForkLedgers(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
-				SelectForkRevisions(uid).
+				SelectForkRevisions(uid, store.Query{}).
 				Return([]store.Entity{}, errNotFound{errors.New("not found")})
 
-			_, err := repo.SelectForkLedgers(uid)
+			_, err := repo.SelectForkLedgers(uid, Query{})
 			if expected, actual := false, err == nil; expected != actual {
 				t.Errorf("expected: %t, actual: %t", expected, actual)
 			}
@@ -639,14 +664,14 @@ func TestSelectForkLedgers(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
-				SelectForkRevisions(uid).
+				SelectForkRevisions(uid, store.Query{}).
 				Return([]store.Entity{}, errors.New("not found"))
 
-			_, err := repo.SelectForkLedgers(uid)
+			_, err := repo.SelectForkLedgers(uid, Query{})
 			if expected, actual := false, err == nil; expected != actual {
 				t.Errorf("expected: %t, actual: %t", expected, actual)
 			}
@@ -667,14 +692,14 @@ func TestSelectForkLedgers(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
-				SelectForkRevisions(uid).
+				SelectForkRevisions(uid, store.Query{}).
 				Return([]store.Entity{store.Entity{ID: id}}, nil)
 
-			doc, err := repo.SelectForkLedgers(uid)
+			doc, err := repo.SelectForkLedgers(uid, Query{})
 			if err != nil {
 				t.Error(err)
 			}
@@ -696,6 +721,224 @@ func TestSelectForkLedgers(t *testing.T) {
 	})
 }
 
+func TestVerifyLedger(t *testing.T) {
+	t.Parallel()
+
+	chain := func(n int) []store.Entity {
+		var (
+			res      []store.Entity
+			parent   store.Entity
+			resource = uuid.MustNew()
+			now      = time.Now()
+		)
+		for i := 0; i < n; i++ {
+			entity := store.Entity{
+				ID:         uuid.MustNew(),
+				ParentID:   parent.ID,
+				Name:       "name",
+				ResourceID: resource,
+				AuthorID:   "author",
+				CreatedOn:  now.Add(time.Duration(i) * time.Second),
+			}
+			entity.Hash = store.HashEntity(entity, parent.Hash)
+			res = append(res, entity)
+			parent = entity
+		}
+		return res
+	}
+
+	t.Run("verify with no ledger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(uid uuid.UUID) bool {
+			var (
+				mock = storeMocks.NewMockStore(ctrl)
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), mock, log.NewNopLogger())
+			)
+
+			mock.EXPECT().
+				SelectForkRevisions(uid, store.Query{}).
+				Return([]store.Entity{}, nil)
+
+			_, err := repo.VerifyLedger(uid, Query{})
+			if expected, actual := true, ErrNotFound(err); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+
+			return true
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("verify valid chain", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(uid uuid.UUID, size uint8) bool {
+			var (
+				mock     = storeMocks.NewMockStore(ctrl)
+				repo     = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), mock, log.NewNopLogger())
+				entities = chain(int(size%8) + 1)
+			)
+
+			mock.EXPECT().
+				SelectForkRevisions(uid, store.Query{}).
+				Return(entities, nil)
+
+			res, err := repo.VerifyLedger(uid, Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return res.Valid &&
+				res.Revisions == len(entities) &&
+				res.BrokenID.Zero() &&
+				res.Reason == ""
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("verify tampered chain", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			mock     = storeMocks.NewMockStore(ctrl)
+			repo     = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), mock, log.NewNopLogger())
+			entities = chain(4)
+		)
+
+		entities[1].Name = "tampered"
+
+		mock.EXPECT().
+			SelectForkRevisions(entities[3].ResourceID, store.Query{}).
+			Return(entities, nil)
+
+		res, err := repo.VerifyLedger(entities[3].ResourceID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := false, res.Valid; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := entities[1].ID, res.BrokenID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "hash mismatch", res.Reason; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("verify missing hash", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			mock     = storeMocks.NewMockStore(ctrl)
+			repo     = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), mock, log.NewNopLogger())
+			entities = chain(3)
+		)
+
+		entities[2].Hash = ""
+
+		mock.EXPECT().
+			SelectForkRevisions(entities[2].ResourceID, store.Query{}).
+			Return(entities, nil)
+
+		res, err := repo.VerifyLedger(entities[2].ResourceID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := entities[2].ID, res.BrokenID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "missing hash", res.Reason; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("verify missing parent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			mock     = storeMocks.NewMockStore(ctrl)
+			repo     = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), mock, log.NewNopLogger())
+			entities = chain(3)[1:]
+		)
+
+		mock.EXPECT().
+			SelectForkRevisions(entities[1].ResourceID, store.Query{}).
+			Return(entities, nil)
+
+		res, err := repo.VerifyLedger(entities[1].ResourceID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := entities[0].ID, res.BrokenID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "parent revision not found", res.Reason; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("verify inserted ledgers", func(t *testing.T) {
+		var (
+			repo = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+		)
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName("name"),
+			models.WithAuthorID("author"),
+			models.WithTags([]string{"b", "a"}),
+			models.WithCreatedOn(time.Now().Add(-time.Minute)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = repo.InsertLedger(doc); err != nil {
+			t.Fatal(err)
+		}
+
+		revision, err := models.BuildLedger(
+			models.WithResourceID(doc.ResourceID()),
+			models.WithName("revision"),
+			models.WithAuthorID("author"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = repo.AppendLedger(doc.ResourceID(), revision, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := repo.VerifyLedger(doc.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := true, res.Valid; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := 2, res.Revisions; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
 func TestSelectContent(t *testing.T) {
 	t.Parallel()
 
@@ -707,7 +950,7 @@ func TestSelectContent(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -736,7 +979,7 @@ func TestSelectContent(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -765,7 +1008,7 @@ func TestSelectContent(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -774,6 +1017,13 @@ func TestSelectContent(t *testing.T) {
 					ResourceID: uid,
 				}, nil)
 
+			mock.EXPECT().
+				SelectKey("", uid, "").
+				Return(store.Key{}, errors.New("not found"))
+			mock.EXPECT().
+				SelectKey("", uuid.Empty, "").
+				Return(store.Key{}, errors.New("not found"))
+
 			_, err := repo.SelectContent(uid, Query{})
 
 			if expected, actual := false, err == nil; expected != actual {
@@ -796,7 +1046,7 @@ func TestSelectContent(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			file, err := fsys.Create(uid.String())
@@ -841,7 +1091,7 @@ func TestSelectContent(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			file, err := fsys.Create(uid.String())
@@ -898,7 +1148,7 @@ func TestSelectContents(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -927,7 +1177,7 @@ func TestSelectContents(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -956,7 +1206,7 @@ func TestSelectContents(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			mock.EXPECT().
@@ -967,6 +1217,13 @@ func TestSelectContents(t *testing.T) {
 					},
 				}, nil)
 
+			mock.EXPECT().
+				SelectKey("", uid, "").
+				Return(store.Key{}, errors.New("not found"))
+			mock.EXPECT().
+				SelectKey("", uuid.Empty, "").
+				Return(store.Key{}, errors.New("not found"))
+
 			_, err := repo.SelectContents(uid, Query{})
 
 			if expected, actual := true, err == nil; expected != actual {
@@ -989,7 +1246,7 @@ func TestSelectContents(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			file, err := fsys.Create(uid.String())
@@ -1044,7 +1301,7 @@ func TestPutContent(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			content, err := models.BuildContent(
@@ -1055,7 +1312,7 @@ func TestPutContent(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			_, err = repo.PutContent(content)
+			_, err = repo.PutContent(content, Query{})
 
 			if expected, actual := false, err == nil; expected != actual {
 				t.Errorf("expected: %t, actual: %t", expected, actual)
@@ -1081,7 +1338,7 @@ func TestPutContent(t *testing.T) {
 			var (
 				fsys = fsys.NewVirtualFilesystem()
 				mock = storeMocks.NewMockStore(ctrl)
-				repo = NewRealRepository(fsys, mock, log.NewNopLogger())
+				repo = NewRealRepository(NewFilesystemBlobStore(fsys), mock, log.NewNopLogger())
 			)
 
 			content, err := models.BuildContent(
@@ -1093,7 +1350,7 @@ func TestPutContent(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			res, err := repo.PutContent(content)
+			res, err := repo.PutContent(content, Query{})
 
 			if expected, actual := true, err == nil; expected != actual {
 				t.Errorf("expected: %t, actual: %t", expected, actual)
@@ -1108,6 +1365,92 @@ func TestPutContent(t *testing.T) {
 			t.Error(err)
 		}
 	})
+
+	t.Run("put compressed content", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fn := func(uid uuid.UUID, body []byte) bool {
+			if len(body) < 1 {
+				return true
+			}
+
+			compression, err := BuildCompression(
+				WithCompressionEncoding("gzip"),
+				WithCompressionContentTypes([]string{"text/*"}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				blobs = NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+				mock  = storeMocks.NewMockStore(ctrl)
+				repo  = NewRealRepository(blobs, mock, log.NewNopLogger(), WithCompression(compression))
+			)
+
+			content, err := models.BuildContent(
+				models.WithContentBytes(body),
+				models.WithSize(int64(len(body))),
+				models.WithContentType("text/plain"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := repo.PutContent(content, Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := blobs.Stat(res.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := EncodingGzip, info.ContentEncoding; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+
+			mock.EXPECT().
+				Select(uid, store.Query{}).
+				Return(store.Entity{
+					ResourceID:          uid,
+					ResourceAddress:     res.Address(),
+					ResourceContentType: "text/plain",
+				}, nil).
+				Times(2)
+
+			decoded, err := repo.SelectContent(uid, Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := decoded.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := body, b; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+
+			encoded, err := repo.SelectContent(uid, Query{
+				AcceptEncoding: []string{EncodingGzip},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return content.Address() == res.Address() &&
+				decoded.ContentEncoding() == EncodingIdentity &&
+				decoded.Size() == int64(len(body)) &&
+				encoded.ContentEncoding() == EncodingGzip &&
+				encoded.Size() == info.StoredSize
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
 }
 
 type entityMatcher struct {
@@ -1131,3 +1474,1973 @@ func (entityMatcher) String() string {
 }
 
 func Entity(doc store.Entity) gomock.Matcher { return entityMatcher{doc} }
+
+type entitiesMatcher struct {
+	docs []store.Entity
+}
+
+func (m entitiesMatcher) Matches(x interface{}) bool {
+	d, ok := x.([]store.Entity)
+	if !ok || len(d) != len(m.docs) {
+		return false
+	}
+
+	for k, doc := range m.docs {
+		if !(entityMatcher{doc}).Matches(d[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (entitiesMatcher) String() string {
+	return "is entities"
+}
+
+func Entities(docs ...store.Entity) gomock.Matcher { return entitiesMatcher{docs} }
+
+func TestEncryptedContent(t *testing.T) {
+	t.Parallel()
+
+	newProvider := func(t *testing.T) KeyProvider {
+		key, err := NewDataKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		provider, err := newLocalKeyProvider(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return provider
+	}
+
+	t.Run("put then select", func(t *testing.T) {
+		fn := func(name, authorID string, body []byte) bool {
+			if len(body) < 1 {
+				return true
+			}
+
+			var (
+				blobs     = NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+				dataStore = store.NewVirtualStore()
+				repo      = NewRealRepository(blobs, dataStore, log.NewNopLogger(), WithEncryption(newProvider(t)))
+			)
+
+			content, err := models.BuildContent(
+				models.WithContentBytes(body),
+				models.WithSize(int64(len(body))),
+				models.WithContentType("application/octet-stream"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := repo.PutContent(content, Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := blobs.Stat(res.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := true, info.Encrypted; expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+
+			if _, err = dataStore.SelectKey("", uuid.Empty, res.Address()); err != nil {
+				t.Fatal(err)
+			}
+
+			doc, err := models.BuildLedger(
+				models.WithNewResourceID(),
+				models.WithName(name),
+				models.WithResourceAddress(res.Address()),
+				models.WithResourceSize(res.Size()),
+				models.WithResourceContentType(res.ContentType()),
+				models.WithAuthorID(authorID),
+				models.WithCreatedOn(time.Now()),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc, err = repo.InsertLedger(doc); err != nil {
+				t.Fatal(err)
+			}
+
+			selected, err := repo.SelectContent(doc.ResourceID(), Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := selected.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return reflect.DeepEqual(body, b) &&
+				selected.Size() == int64(len(body))
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("select without key provider", func(t *testing.T) {
+		var (
+			blobs     = NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+			dataStore = store.NewVirtualStore()
+			repo      = NewRealRepository(blobs, dataStore, log.NewNopLogger(), WithEncryption(newProvider(t)))
+		)
+
+		content, err := models.BuildContent(
+			models.WithContentBytes([]byte("hello")),
+			models.WithSize(5),
+			models.WithContentType("text/plain"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := repo.PutContent(content, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName("name"),
+			models.WithResourceAddress(res.Address()),
+			models.WithAuthorID("author"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc, err = repo.InsertLedger(doc); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = NewRealRepository(blobs, dataStore, log.NewNopLogger()).SelectContent(doc.ResourceID(), Query{})
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
+
+// encryption returns the option to encrypt the content with a new master key.
+func encryption(t *testing.T) Option {
+	key, err := NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := newLocalKeyProvider(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return WithEncryption(provider)
+}
+
+func TestEraseLedger(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T, repo Repository, authorID string, body []byte) models.Ledger {
+		content, err := models.BuildContent(
+			models.WithContentBytes(body),
+			models.WithSize(int64(len(body))),
+			models.WithContentType("text/plain"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := repo.PutContent(content, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName("name"),
+			models.WithResourceAddress(res.Address()),
+			models.WithResourceSize(res.Size()),
+			models.WithResourceContentType(res.ContentType()),
+			models.WithAuthorID(authorID),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err = repo.InsertLedger(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+
+	t.Run("erase", func(t *testing.T) {
+		var (
+			repo = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), encryption(t))
+
+			doc    = setup(t, repo, "author", []byte("hello"))
+			shared = setup(t, repo, "other", []byte("hello"))
+		)
+
+		head, err := repo.SelectLedger(doc.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tombstone, err := repo.EraseLedger(doc.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := false, tombstone.DeletedOn().IsZero(); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := head.ID(), tombstone.ParentID(); !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		// The ledgers are left in place.
+		ledgers, err := repo.SelectLedgers(doc.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(ledgers); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		_, err = repo.SelectContent(doc.ResourceID(), Query{})
+		if expected, actual := true, ErrErased(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		_, err = repo.SelectContents(doc.ResourceID(), Query{})
+		if expected, actual := true, ErrErased(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		// Every resource has its own key, so the content can still be read by
+		// the other resource.
+		content, err := repo.SelectContent(shared.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		content.Reader().Close()
+	})
+
+	t.Run("erase unencrypted", func(t *testing.T) {
+		var (
+			repo = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+			doc  = setup(t, repo, "author", []byte("hello"))
+		)
+
+		_, err := repo.EraseLedger(doc.ResourceID(), Query{})
+		if expected, actual := true, ErrNotSupported(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		_, err = repo.EraseAuthorLedgers("author", Query{})
+		if expected, actual := true, ErrNotSupported(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		content, err := repo.SelectContent(doc.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		content.Reader().Close()
+	})
+
+	t.Run("erase content stored before encryption", func(t *testing.T) {
+		var (
+			blobs     = NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+			dataStore = store.NewVirtualStore()
+			legacy    = NewRealRepository(blobs, dataStore, log.NewNopLogger())
+
+			doc    = setup(t, legacy, "author", []byte("hello"))
+			shared = setup(t, legacy, "other", []byte("hello"))
+
+			repo = NewRealRepository(blobs, dataStore, log.NewNopLogger(), encryption(t))
+		)
+
+		fork, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName("fork"),
+			models.WithResourceAddress(doc.ResourceAddress()),
+			models.WithResourceSize(doc.ResourceSize()),
+			models.WithResourceContentType(doc.ResourceContentType()),
+			models.WithAuthorID("author"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fork, err = legacy.ForkLedger(doc.ResourceID(), fork, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = repo.EraseLedger(doc.ResourceID(), Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = repo.SelectContent(doc.ResourceID(), Query{})
+		if expected, actual := true, ErrErased(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		// The content has no keys, so it's kept while other resources that
+		// haven't been erased, including forks, still refer to it.
+		for _, other := range []models.Ledger{shared, fork} {
+			content, err := repo.SelectContent(other.ResourceID(), Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			content.Reader().Close()
+		}
+
+		for _, other := range []models.Ledger{shared, fork} {
+			if _, err = repo.EraseLedger(other.ResourceID(), Query{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err = blobs.Tenant("").Get(doc.ResourceAddress()); !ErrNotFound(err) {
+			t.Errorf("expected not found, actual: %v", err)
+		}
+	})
+
+	t.Run("append after erase", func(t *testing.T) {
+		var (
+			repo = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), encryption(t))
+			doc  = setup(t, repo, "author", []byte("hello"))
+		)
+
+		if _, err := repo.EraseLedger(doc.ResourceID(), Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		revision, err := models.BuildLedger(
+			models.WithResourceID(doc.ResourceID()),
+			models.WithName("name"),
+			models.WithResourceAddress(doc.ResourceAddress()),
+			models.WithResourceSize(doc.ResourceSize()),
+			models.WithResourceContentType(doc.ResourceContentType()),
+			models.WithAuthorID("author"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = repo.AppendLedger(doc.ResourceID(), revision, Query{})
+		if expected, actual := true, ErrErased(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		_, err = repo.ForkLedger(doc.ResourceID(), revision, Query{})
+		if expected, actual := true, ErrErased(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		// The tombstone is still the head, so the content of every revision
+		// is still erased.
+		_, err = repo.SelectContents(doc.ResourceID(), Query{})
+		if expected, actual := true, ErrErased(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("erase then store again", func(t *testing.T) {
+		var (
+			repo = NewRealRepository(
+				NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+				store.NewVirtualStore(),
+				log.NewNopLogger(),
+				encryption(t),
+			)
+
+			doc    = setup(t, repo, "author", []byte("hello"))
+			shared = setup(t, repo, "other", []byte("hello"))
+		)
+
+		for _, resourceID := range []uuid.UUID{doc.ResourceID(), shared.ResourceID()} {
+			if _, err := repo.EraseLedger(resourceID, Query{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Every key of the content has been destroyed, so storing the same
+		// content again gets a new key, which the erased resources never get.
+		again := setup(t, repo, "author", []byte("hello"))
+
+		content, err := repo.SelectContent(again.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(content.Reader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "hello", string(body); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		revisions, err := repo.SelectLedgers(doc.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, revision := range revisions {
+			if !revision.DeletedOn().IsZero() {
+				continue
+			}
+			_, err = repo.SelectRevisionContent(revision, Query{})
+			if expected, actual := true, ErrErased(err); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		}
+	})
+
+	t.Run("erase not found", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), encryption(t))
+
+		_, err := repo.EraseLedger(uuid.MustNew(), Query{})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("erase author", func(t *testing.T) {
+		var (
+			repo = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), encryption(t))
+
+			a     = setup(t, repo, "author", []byte("a"))
+			b     = setup(t, repo, "author", []byte("b"))
+			other = setup(t, repo, "other", []byte("c"))
+		)
+
+		tombstones, err := repo.EraseAuthorLedgers("author", Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(tombstones); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		for _, doc := range []models.Ledger{a, b} {
+			_, err = repo.SelectContent(doc.ResourceID(), Query{})
+			if expected, actual := true, ErrErased(err); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		}
+
+		if _, err = repo.SelectContent(other.ResourceID(), Query{}); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	newSigner := func(t *testing.T) Signer {
+		signer, err := newLocalSigner(make([]byte, ed25519.SeedSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signer
+	}
+
+	insert := func(t *testing.T, repo Repository, n int) []models.Ledger {
+		res := make([]models.Ledger, n)
+		for k := range res {
+			doc, err := models.BuildLedger(
+				models.WithNewResourceID(),
+				models.WithName(fmt.Sprintf("name-%d", k)),
+				models.WithAuthorID("author"),
+				models.WithCreatedOn(time.Now().Add(time.Duration(k)*time.Second)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = repo.InsertLedger(doc); err != nil {
+				t.Fatal(err)
+			}
+			if res[k], err = repo.SelectLedger(doc.ResourceID(), Query{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return res
+	}
+
+	t.Run("checkpoint without signer", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+
+		_, err := repo.Checkpoint()
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("checkpoint without ledgers", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), WithCheckpoints(newSigner(t)))
+
+		_, err := repo.Checkpoint()
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("checkpoint", func(t *testing.T) {
+		var (
+			signer = newSigner(t)
+			repo   = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), WithCheckpoints(signer))
+		)
+
+		insert(t, repo, 3)
+
+		checkpoint, err := repo.Checkpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := int64(3), checkpoint.TreeSize; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := signer.KeyID(), checkpoint.KeyID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		head := merkle.TreeHead{
+			TreeSize:  checkpoint.TreeSize,
+			RootHash:  checkpoint.RootHash,
+			Timestamp: checkpoint.CreatedOn,
+		}
+		if err := merkle.VerifyTreeHead(head, checkpoint.Signature, signer.PublicKey()); err != nil {
+			t.Error(err)
+		}
+
+		// Nothing new to checkpoint, so the same checkpoint is returned.
+		again, err := repo.Checkpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := checkpoint.Signature, again.Signature; !bytes.Equal(expected, actual) {
+			t.Errorf("expected: %x, actual: %x", expected, actual)
+		}
+	})
+
+	t.Run("select ledger proof", func(t *testing.T) {
+		var (
+			signer = newSigner(t)
+			repo   = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), WithCheckpoints(signer))
+		)
+
+		docs := insert(t, repo, 3)
+		from, err := repo.Checkpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		insert(t, repo, 4)
+		checkpoint, err := repo.Checkpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		proof, err := repo.SelectLedgerProof(docs[1].ID(), 0, from.TreeSize, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := checkpoint.TreeSize, proof.Checkpoint.TreeSize; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		leaf := merkle.LedgerLeafHash(proof.LedgerID.String(), proof.LedgerHash)
+		if err := merkle.VerifyInclusion(leaf, proof.LeafIndex, checkpoint.TreeSize, proof.Inclusion, checkpoint.RootHash); err != nil {
+			t.Error(err)
+		}
+		if err := merkle.VerifyConsistency(from.TreeSize, checkpoint.TreeSize, from.RootHash, checkpoint.RootHash, proof.Consistency); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("select ledger proof not in checkpoint", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), WithCheckpoints(newSigner(t)))
+
+		insert(t, repo, 2)
+		if _, err := repo.Checkpoint(); err != nil {
+			t.Fatal(err)
+		}
+
+		docs := insert(t, repo, 1)
+		_, err := repo.SelectLedgerProof(docs[0].ID(), 0, 0, Query{})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("select ledger proof is per tenant and readers", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), WithCheckpoints(newSigner(t)))
+
+		docs := insert(t, repo, 1)
+		if _, err := repo.Checkpoint(); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := repo.SelectLedgerProof(docs[0].ID(), 0, 0, Query{Tenant: "other"})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		_, err = repo.SelectLedgerProof(docs[0].ID(), 0, 0, Query{Principal: &Principal{ID: "other"}})
+		if expected, actual := true, ErrForbidden(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if _, err = repo.SelectLedgerProof(docs[0].ID(), 0, 0, Query{Principal: &Principal{ID: "author"}}); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestSignedLedger(t *testing.T) {
+	t.Parallel()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRepository := func(t *testing.T) (Repository, models.AuthorKey) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+
+		authorKey, err := repo.InsertAuthorKey("author", pub, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo, authorKey
+	}
+
+	sign := func(t *testing.T, keyID string, opts ...models.DocOption) models.Ledger {
+		doc, err := models.BuildLedger(append([]models.DocOption{
+			models.WithName("name"),
+			models.WithAuthorID("author"),
+			models.WithResourceAddress("address"),
+			models.WithTags([]string{"a", "b"}),
+			models.WithCreatedOn(time.Now().Truncate(time.Second)),
+		}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = models.WithSignature(keyID, ed25519.Sign(key, doc.SigningBytes()))(&doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+
+	t.Run("insert author key", func(t *testing.T) {
+		repo, authorKey := newRepository(t)
+
+		// Registering the same key again returns the existing key.
+		again, err := repo.InsertAuthorKey("author", pub, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := authorKey.KeyID, again.KeyID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		keys, err := repo.SelectAuthorKeys("author", Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(keys); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("insert author key with invalid key", func(t *testing.T) {
+		repo, _ := newRepository(t)
+
+		_, err := repo.InsertAuthorKey("author", []byte("key"), Query{})
+		if expected, actual := true, ErrInvalidSignature(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert then append signed", func(t *testing.T) {
+		repo, authorKey := newRepository(t)
+
+		doc := sign(t, authorKey.KeyID,
+			models.WithNewResourceID(),
+			models.WithParentID(uuid.Empty),
+		)
+		if _, err := repo.InsertLedger(doc); err != nil {
+			t.Fatal(err)
+		}
+
+		head, err := repo.SelectLedger(doc.ResourceID(), Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := head.VerifySignature(pub); err != nil {
+			t.Error(err)
+		}
+
+		revision := sign(t, authorKey.KeyID,
+			models.WithResourceID(doc.ResourceID()),
+			models.WithParentID(head.ID()),
+		)
+		if _, err := repo.AppendLedger(doc.ResourceID(), revision, Query{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("append signed with wrong parent", func(t *testing.T) {
+		repo, authorKey := newRepository(t)
+
+		doc := sign(t, authorKey.KeyID, models.WithNewResourceID())
+		if _, err := repo.InsertLedger(doc); err != nil {
+			t.Fatal(err)
+		}
+
+		revision := sign(t, authorKey.KeyID,
+			models.WithResourceID(doc.ResourceID()),
+			models.WithParentID(uuid.MustNew()),
+		)
+		_, err := repo.AppendLedger(doc.ResourceID(), revision, Query{})
+		if expected, actual := true, ErrInvalidSignature(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert signed with unknown key", func(t *testing.T) {
+		repo, _ := newRepository(t)
+
+		_, err := repo.InsertLedger(sign(t, "unknown", models.WithNewResourceID()))
+		if expected, actual := true, ErrInvalidSignature(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert tampered", func(t *testing.T) {
+		repo, authorKey := newRepository(t)
+
+		doc := sign(t, authorKey.KeyID, models.WithNewResourceID())
+		if err := models.WithName("other")(&doc); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := repo.InsertLedger(doc)
+		if expected, actual := true, ErrInvalidSignature(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		if _, err := repo.SelectLedger(doc.ResourceID(), Query{}); !ErrNotFound(err) {
+			t.Errorf("expected not found, actual: %v", err)
+		}
+	})
+}
+
+func TestACL(t *testing.T) {
+	t.Parallel()
+
+	as := func(principalID string, groups ...string) Query {
+		query, err := BuildQuery(WithQueryPrincipal(principalID, groups))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return query
+	}
+
+	newLedger := func(t *testing.T, authorID string, opts ...models.DocOption) models.Ledger {
+		doc, err := models.BuildLedger(append([]models.DocOption{
+			models.WithName("name"),
+			models.WithAuthorID(authorID),
+			models.WithResourceAddress("address"),
+			models.WithCreatedOn(time.Now()),
+		}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+
+	newRepository := func(t *testing.T) (Repository, uuid.UUID) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+
+		doc, err := repo.InsertLedger(newLedger(t, "owner", models.WithNewResourceID()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo, doc.ResourceID()
+	}
+
+	t.Run("owner only by default", func(t *testing.T) {
+		repo, resourceID := newRepository(t)
+
+		if _, err := repo.SelectLedger(resourceID, as("owner")); err != nil {
+			t.Error(err)
+		}
+		if _, err := repo.SelectLedger(resourceID, BuildEmptyQuery()); err != nil {
+			t.Error(err)
+		}
+
+		for name, fn := range map[string]func() error{
+			"select": func() error {
+				_, err := repo.SelectLedger(resourceID, as("other"))
+				return err
+			},
+			"select revisions": func() error {
+				_, err := repo.SelectLedgers(resourceID, as("other"))
+				return err
+			},
+			"select fork revisions": func() error {
+				_, err := repo.SelectForkLedgers(resourceID, as("other"))
+				return err
+			},
+			"select content": func() error {
+				_, err := repo.SelectContent(resourceID, as("other"))
+				return err
+			},
+			"append": func() error {
+				_, err := repo.AppendLedger(resourceID, newLedger(t, "other", models.WithResourceID(resourceID)), as("other"))
+				return err
+			},
+			"fork": func() error {
+				_, err := repo.ForkLedger(resourceID, newLedger(t, "other", models.WithNewResourceID()), as("other"))
+				return err
+			},
+			"update acl": func() error {
+				_, err := repo.UpdateACL(resourceID, models.ACL{Owner: "other"}, as("other"))
+				return err
+			},
+		} {
+			if expected, actual := true, ErrForbidden(fn()); expected != actual {
+				t.Errorf("%s expected: %t, actual: %t", name, expected, actual)
+			}
+		}
+	})
+
+	t.Run("update acl", func(t *testing.T) {
+		repo, resourceID := newRepository(t)
+
+		acl, err := repo.UpdateACL(resourceID, models.ACL{
+			Owner:   "owner",
+			Readers: []string{"group:auditors"},
+			Writers: []string{"writer"},
+		}, as("owner"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "owner", acl.UpdatedBy; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		// The change is recorded as a revision of the resource.
+		docs, err := repo.SelectLedgers(resourceID, BuildEmptyQuery())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(docs); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		acls, err := repo.SelectACLRevisions(resourceID, as("writer"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(acls); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		if _, err := repo.SelectLedger(resourceID, as("other", "auditors")); err != nil {
+			t.Error(err)
+		}
+		_, err = repo.AppendLedger(resourceID, newLedger(t, "other", models.WithResourceID(resourceID)), as("other", "auditors"))
+		if expected, actual := true, ErrForbidden(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if _, err := repo.AppendLedger(resourceID, newLedger(t, "writer", models.WithResourceID(resourceID)), as("writer")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("update acl is attributed to the caller", func(t *testing.T) {
+		var (
+			publisher = &recordingPublisher{}
+			repo      = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), WithEvents(publisher))
+		)
+		doc, err := repo.InsertLedger(newLedger(t, "owner", models.WithNewResourceID()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, test := range []struct {
+			query    Query
+			expected string
+		}{
+			{as("owner"), "owner"},
+			{BuildEmptyQuery(), ""},
+		} {
+			acl, err := repo.UpdateACL(doc.ResourceID(), models.ACL{Owner: "other"}, test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := test.expected, acl.UpdatedBy; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+
+			head, err := repo.SelectLedger(doc.ResourceID(), BuildEmptyQuery())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := test.expected, head.AuthorID(); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+
+			event := publisher.events[len(publisher.events)-1]
+			if expected, actual := models.WebhookEventAppend, event.Type; expected != actual {
+				t.Errorf("expected: %s, actual: %s", expected, actual)
+			}
+			if expected, actual := head.ID().String(), event.ID; expected != actual {
+				t.Errorf("expected: %s, actual: %s", expected, actual)
+			}
+		}
+	})
+
+	t.Run("fork inherits acl", func(t *testing.T) {
+		repo, resourceID := newRepository(t)
+
+		if _, err := repo.UpdateACL(resourceID, models.ACL{
+			Owner:   "owner",
+			Writers: []string{"writer"},
+		}, as("owner")); err != nil {
+			t.Fatal(err)
+		}
+
+		fork, err := repo.ForkLedger(resourceID, newLedger(t, "writer", models.WithNewResourceID()), as("writer"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		acl, err := repo.SelectACL(fork.ResourceID(), as("writer"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "owner", acl.Owner; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := []string{"writer"}, acl.Writers; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("resource without acl", func(t *testing.T) {
+		var (
+			entities = store.NewVirtualStore()
+			repo     = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), entities, log.NewNopLogger())
+			entity   = store.Entity{
+				ParentID:   uuid.MustParse(defaultRootParentID),
+				Name:       "name",
+				ResourceID: uuid.MustNew(),
+				AuthorID:   "author",
+				CreatedOn:  time.Now(),
+			}
+		)
+		if err := entities.Insert(entity); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := repo.SelectLedger(entity.ResourceID, as("other")); err != nil {
+			t.Error(err)
+		}
+
+		acl, err := repo.SelectACL(entity.ResourceID, as("other"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "author", acl.Owner; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		_, err = repo.UpdateACL(entity.ResourceID, models.ACL{Owner: "other"}, as("other"))
+		if expected, actual := true, ErrForbidden(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("select acl not found", func(t *testing.T) {
+		repo, _ := newRepository(t)
+
+		_, err := repo.SelectACL(uuid.MustNew(), as("owner"))
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
+
+func TestTenants(t *testing.T) {
+	t.Parallel()
+
+	newProvider := func(t *testing.T) KeyProvider {
+		key, err := NewDataKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		provider, err := newLocalKeyProvider(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return provider
+	}
+
+	in := func(tenant string) Query {
+		query, err := BuildQuery(WithQueryTenant(tenant))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return query
+	}
+
+	// put stores the body with in the tenant, then inserts a ledger for it.
+	put := func(t *testing.T, repo Repository, tenant string, body []byte) models.Ledger {
+		content, err := models.BuildContent(
+			models.WithContentBytes(body),
+			models.WithSize(int64(len(body))),
+			models.WithContentType("application/octet-stream"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err = repo.PutContent(content, in(tenant))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithTenantID(tenant),
+			models.WithName("name"),
+			models.WithAuthorID("author"),
+			models.WithResourceAddress(content.Address()),
+			models.WithResourceSize(content.Size()),
+			models.WithResourceContentType(content.ContentType()),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err = repo.InsertLedger(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+
+	t.Run("ledgers are isolated", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+
+		doc := put(t, repo, "acme", []byte("body"))
+
+		if _, err := repo.SelectLedger(doc.ResourceID(), in("acme")); err != nil {
+			t.Error(err)
+		}
+		for _, tenant := range []string{"", "other"} {
+			_, err := repo.SelectLedger(doc.ResourceID(), in(tenant))
+			if expected, actual := true, ErrNotFound(err); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+
+			_, err = repo.SelectACL(doc.ResourceID(), in(tenant))
+			if expected, actual := true, ErrNotFound(err); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+
+			_, err = repo.AppendLedger(doc.ResourceID(), doc, in(tenant))
+			if expected, actual := true, ErrNotFound(err); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		}
+
+		revision, err := repo.AppendLedger(doc.ResourceID(), doc, in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "acme", revision.TenantID(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("content is isolated", func(t *testing.T) {
+		var (
+			fs   = fsys.NewVirtualFilesystem()
+			repo = NewRealRepository(NewFilesystemBlobStore(fs), store.NewVirtualStore(), log.NewNopLogger())
+		)
+
+		doc := put(t, repo, "acme", []byte("body"))
+
+		if expected, actual := true, fs.Exists("tenants/acme/"+doc.ResourceAddress()); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := false, fs.Exists(doc.ResourceAddress()); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		content, err := repo.SelectContent(doc.ResourceID(), in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content.Reader().Close()
+	})
+
+	t.Run("erasing is isolated", func(t *testing.T) {
+		repo := NewRealRepository(
+			NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+			store.NewVirtualStore(),
+			log.NewNopLogger(),
+			WithEncryption(newProvider(t)),
+		)
+
+		var (
+			a = put(t, repo, "acme", []byte("body"))
+			b = put(t, repo, "other", []byte("body"))
+		)
+		if expected, actual := a.ResourceAddress(), b.ResourceAddress(); expected != actual {
+			t.Fatalf("expected: %q, actual: %q", expected, actual)
+		}
+
+		if _, err := repo.EraseLedger(a.ResourceID(), in("acme")); err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := repo.SelectContent(b.ResourceID(), in("other"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content.Reader().Close()
+	})
+
+	t.Run("statistics are per tenant", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+
+		put(t, repo, "acme", []byte("a"))
+		put(t, repo, "acme", []byte("b"))
+		put(t, repo, "other", []byte("c"))
+
+		for tenant, total := range map[string]int{"acme": 2, "other": 1, "": 0} {
+			stats, err := repo.LedgerStatistics(in(tenant))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := total, stats.TotalLedgers; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+		}
+	})
+
+	t.Run("resources and revision content are per tenant", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger(), WithEncryption(newProvider(t)))
+
+		a := put(t, repo, "acme", []byte("a"))
+		put(t, repo, "acme", []byte("b"))
+		put(t, repo, "other", []byte("c"))
+
+		resourceIDs, err := repo.SelectResources(in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(resourceIDs); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		content, err := repo.SelectRevisionContent(a, in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(content.Reader())
+		content.Reader().Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "a", string(body); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		_, err = repo.SelectRevisionContent(a, in("other"))
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		tombstone, err := repo.EraseLedger(a.ResourceID(), in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = repo.SelectRevisionContent(tombstone, in("acme"))
+		if expected, actual := true, ErrErased(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("statistics are mapped from the store", func(t *testing.T) {
+		repo := NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+
+		put(t, repo, "acme", []byte("a"))
+		put(t, repo, "acme", []byte("bc"))
+
+		stats, err := repo.LedgerStatistics(in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, stats.TotalResources; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int64(3), stats.TotalBytes; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int64(3), stats.ContentTypes["application/octet-stream"]; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 2, stats.Revisions[0].Resources; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := []models.LedgerCount{{Name: "author", Ledgers: 2}}, stats.Authors; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := len(store.StatisticsWindows), len(stats.Inserts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 2, stats.Inserts[0].Ledgers; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	// contentOf returns the content of the ledger, as it's read from an
+	// export.
+	contentOf := func(t *testing.T, doc models.Ledger, body []byte) models.Content {
+		content, err := models.BuildContent(
+			models.WithAddress(doc.ResourceAddress()),
+			models.WithContentBytes(body),
+			models.WithSize(int64(len(body))),
+			models.WithContentType(doc.ResourceContentType()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+
+	t.Run("import keeps the ledger and is per tenant", func(t *testing.T) {
+		var (
+			source = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+			target = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+			doc    = put(t, source, "acme", []byte("body"))
+		)
+		revision, err := source.AppendLedger(doc.ResourceID(), doc, in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		docs := []models.Ledger{doc, revision}
+		res, err := target.ImportLedgers(docs, []models.Content{contentOf(t, doc, []byte("body"))}, in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := len(docs), len(res); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		for k, ledger := range docs {
+			if expected, actual := ledger.ID(), res[k].ID(); !expected.Equals(actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			if expected, actual := ledger.ParentID(), res[k].ParentID(); !expected.Equals(actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+
+		for _, tenant := range []string{"acme", "other"} {
+			res, err = target.ImportLedgers([]models.Ledger{revision}, nil, in(tenant))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := 0, len(res); expected != actual {
+				t.Errorf("%s: expected: %d, actual: %d", tenant, expected, actual)
+			}
+		}
+
+		content, err := target.SelectRevisionContent(revision, in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(content.Reader())
+		content.Reader().Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "body", string(body); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		acl, err := target.SelectACL(doc.ResourceID(), in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "author", acl.Owner; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		verification, err := target.VerifyLedger(doc.ResourceID(), in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := true, verification.Valid; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("import with a missing parent imports nothing", func(t *testing.T) {
+		var (
+			source = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+			blobs  = NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+			target = NewRealRepository(blobs, store.NewVirtualStore(), log.NewNopLogger())
+			doc    = put(t, source, "acme", []byte("body"))
+			other  = put(t, source, "acme", []byte("other"))
+		)
+		revision, err := source.AppendLedger(doc.ResourceID(), doc, in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = target.ImportLedgers(
+			[]models.Ledger{other, revision},
+			[]models.Content{contentOf(t, other, []byte("other"))},
+			in("acme"),
+		)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		resourceIDs, err := target.SelectResources(in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(resourceIDs); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if _, err = blobs.Tenant("acme").Stat(other.ResourceAddress()); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+	})
+
+	t.Run("import only stores the content of imported ledgers", func(t *testing.T) {
+		var (
+			source = NewRealRepository(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), store.NewVirtualStore(), log.NewNopLogger())
+			blobs  = NewFilesystemBlobStore(fsys.NewVirtualFilesystem())
+			target = NewRealRepository(blobs, store.NewVirtualStore(), log.NewNopLogger())
+			doc    = put(t, source, "acme", []byte("body"))
+		)
+
+		// The content of the ledger is missing, as if it was erased.
+		if _, err := target.ImportLedgers([]models.Ledger{doc}, nil, in("acme")); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := target.ImportLedgers([]models.Ledger{doc}, []models.Content{contentOf(t, doc, []byte("body"))}, in("acme"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(res); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if _, err = blobs.Tenant("acme").Stat(doc.ResourceAddress()); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+	})
+}
+
+func TestQuotas(t *testing.T) {
+	t.Parallel()
+
+	newRepository := func() Repository {
+		return NewRealRepository(
+			NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+			store.NewVirtualStore(),
+			log.NewNopLogger(),
+			WithQuotas(time.Minute, nil),
+		)
+	}
+
+	insert := func(repo Repository, authorID string, size int64) (models.Ledger, error) {
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName("name"),
+			models.WithAuthorID(authorID),
+			models.WithResourceAddress(uuid.MustNew().String()),
+			models.WithResourceSize(size),
+			models.WithResourceContentType("application/octet-stream"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo.InsertLedger(doc)
+	}
+
+	t.Run("no quota", func(t *testing.T) {
+		repo := newRepository()
+
+		for i := 0; i < 10; i++ {
+			if _, err := insert(repo, "author", 1024); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.UpdateQuota(models.Quota{AuthorID: "author", MaxBytes: 100}, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := insert(repo, "author", 60); err != nil {
+			t.Fatal(err)
+		}
+		_, err := insert(repo, "author", 60)
+		if expected, actual := true, ErrQuotaExceeded(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		// Other authors aren't limited by the quota.
+		if _, err := insert(repo, "other", 60); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.UpdateQuota(models.Quota{AuthorID: "author", MaxResources: 5}, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			wg       sync.WaitGroup
+			mutex    sync.Mutex
+			inserted int
+		)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, err := insert(repo, "author", 1)
+				if err != nil && !ErrQuotaExceeded(err) {
+					t.Error(err)
+				}
+				if err == nil {
+					mutex.Lock()
+					inserted++
+					mutex.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if expected, actual := 5, inserted; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("failed writes release their usage", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.UpdateQuota(models.Quota{AuthorID: "author", MaxResources: 1}, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName("name"),
+			models.WithAuthorID("author"),
+			models.WithResourceAddress(uuid.MustNew().String()),
+			models.WithResourceSize(1),
+			models.WithResourceContentType("application/octet-stream"),
+			models.WithCreatedOn(time.Now()),
+			models.WithSignature("unknown", []byte("signature")),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = repo.InsertLedger(doc)
+		if expected, actual := true, ErrInvalidSignature(err); expected != actual {
+			t.Fatalf("expected: %t, actual: %t", expected, actual)
+		}
+
+		if _, err := insert(repo, "author", 1); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("put content", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.UpdateQuota(models.Quota{MaxBytes: 4}, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := models.BuildContent(
+			models.WithContentBytes([]byte("too large")),
+			models.WithSize(9),
+			models.WithContentType("application/octet-stream"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = repo.PutContent(content, Query{})
+		if expected, actual := true, ErrQuotaExceeded(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("resources", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.UpdateQuota(models.Quota{MaxResources: 1}, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := insert(repo, "author", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = insert(repo, "other", 1)
+		if expected, actual := true, ErrQuotaExceeded(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		// Revisions of an existing resource are still allowed.
+		if _, err = repo.AppendLedger(doc.ResourceID(), doc, Query{}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("revisions per day", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.UpdateQuota(models.Quota{AuthorID: "author", MaxRevisionsPerDay: 2}, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := insert(repo, "author", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = repo.AppendLedger(doc.ResourceID(), doc, Query{}); err != nil {
+			t.Fatal(err)
+		}
+		_, err = repo.AppendLedger(doc.ResourceID(), doc, Query{})
+		if expected, actual := true, ErrDailyQuotaExceeded(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := false, ErrQuotaExceeded(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("quotas are scoped to the tenant", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.UpdateQuota(models.Quota{MaxResources: 1}, Query{Tenant: "acme"}); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if _, err := insert(repo, "author", 1); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	t.Run("select quota", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := insert(repo, "author", 10); err != nil {
+			t.Fatal(err)
+		}
+
+		quota, err := repo.UpdateQuota(models.Quota{AuthorID: "author", MaxBytes: 100}, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := models.Quota{
+			AuthorID: "author",
+			MaxBytes: 100,
+			Usage: models.QuotaUsage{
+				Bytes:          10,
+				Resources:      1,
+				RevisionsToday: 1,
+			},
+		}
+		if expected, actual := want, quota; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		quota, err = repo.SelectQuota("other", Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := false, quota.Limited(); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+
+	newRepository := func() Repository {
+		return NewRealRepository(
+			NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+			store.NewVirtualStore(),
+			log.NewNopLogger(),
+			WithWebhooks(),
+		)
+	}
+
+	insert := func(repo Repository, authorID string) models.Ledger {
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName("name"),
+			models.WithAuthorID(authorID),
+			models.WithResourceAddress(uuid.MustNew().String()),
+			models.WithResourceSize(1),
+			models.WithResourceContentType("application/octet-stream"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := repo.InsertLedger(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	t.Run("matching webhooks are delivered", func(t *testing.T) {
+		repo := newRepository()
+
+		webhook, err := repo.InsertWebhook(models.Webhook{
+			URL:      "http://example.com",
+			Secret:   "secret",
+			AuthorID: "author",
+		}, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc := insert(repo, "author")
+		insert(repo, "other")
+
+		deliveries, err := repo.ClaimPendingDeliveries(10, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+
+		delivery := deliveries[0]
+		if expected, actual := webhook.ID, delivery.WebhookID; !expected.Equals(actual) {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+		if expected, actual := "secret", delivery.Secret; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := models.WebhookEventInsert, delivery.Event; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := true, bytes.Contains(delivery.Payload, []byte(doc.ResourceID().String())); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("webhooks are scoped by tenant", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.InsertWebhook(models.Webhook{
+			URL:    "http://example.com",
+			Secret: "secret",
+		}, Query{Tenant: "acme"}); err != nil {
+			t.Fatal(err)
+		}
+
+		insert(repo, "author")
+
+		deliveries, err := repo.ClaimPendingDeliveries(10, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(deliveries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		webhooks, err := repo.SelectWebhooks(Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(webhooks); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("webhooks are owned by their principal", func(t *testing.T) {
+		var (
+			repo  = newRepository()
+			alice = Query{Principal: &Principal{ID: "alice"}}
+			bob   = Query{Principal: &Principal{ID: "bob"}}
+		)
+
+		webhook, err := repo.InsertWebhook(models.Webhook{
+			URL:    "http://example.com",
+			Secret: "secret",
+		}, alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "alice", webhook.Owner; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		webhooks, err := repo.SelectWebhooks(bob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(webhooks); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		err = repo.DeleteWebhook(webhook.ID, bob)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		insert(repo, "alice")
+
+		deliveries, err := repo.SelectDeliveries(models.DeliveryStatusPending, bob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(deliveries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		if webhooks, err = repo.SelectWebhooks(alice); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(webhooks); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if err = repo.DeleteWebhook(webhook.ID, alice); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("webhooks are only sent resources their owner can read", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.InsertWebhook(models.Webhook{
+			URL:    "http://example.com",
+			Secret: "secret",
+		}, Query{Principal: &Principal{ID: "alice"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		doc := insert(repo, "alice")
+		insert(repo, "bob")
+
+		deliveries, err := repo.ClaimPendingDeliveries(10, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := true, bytes.Contains(deliveries[0].Payload, []byte(doc.ResourceID().String())); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("webhooks are not sent resources read through the groups of their owner", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.InsertWebhook(models.Webhook{
+			URL:    "http://example.com",
+			Secret: "secret",
+		}, Query{Principal: &Principal{ID: "alice", Groups: []string{"auditors"}}}); err != nil {
+			t.Fatal(err)
+		}
+
+		doc := insert(repo, "bob")
+		if _, err := repo.UpdateACL(doc.ResourceID(), models.ACL{
+			Owner:   "bob",
+			Readers: []string{"group:auditors"},
+		}, Query{Principal: &Principal{ID: "bob"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		deliveries, err := repo.ClaimPendingDeliveries(10, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(deliveries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("delete webhook", func(t *testing.T) {
+		repo := newRepository()
+
+		err := repo.DeleteWebhook(uuid.MustNew(), Query{})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("redeliver dead delivery", func(t *testing.T) {
+		repo := newRepository()
+
+		if _, err := repo.InsertWebhook(models.Webhook{
+			URL:    "http://example.com",
+			Secret: "secret",
+			Events: []string{models.WebhookEventInsert},
+		}, Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		insert(repo, "author")
+
+		deliveries, err := repo.ClaimPendingDeliveries(10, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+
+		delivery := deliveries[0]
+		delivery.Status = models.DeliveryStatusDead
+		delivery.Attempts = 5
+		if err = repo.RecordDeliveryAttempt(delivery, models.WebhookAttempt{
+			AttemptedOn: time.Now(),
+			StatusCode:  500,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		dead, err := repo.SelectDeliveries(models.DeliveryStatusDead, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(dead); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+
+		attempts, err := repo.SelectDeliveryAttempts(delivery.ID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		_, err = repo.SelectDeliveryAttempts(delivery.ID, Query{Tenant: "acme"})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		redelivered, err := repo.RedeliverDelivery(delivery.ID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := models.DeliveryStatusPending, redelivered.Status; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := 0, redelivered.Attempts; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		deliveries, err = repo.ClaimPendingDeliveries(10, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(event events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestEvents(t *testing.T) {
+	t.Parallel()
+
+	var (
+		publisher = &recordingPublisher{}
+		repo      = NewRealRepository(
+			NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+			store.NewVirtualStore(),
+			log.NewNopLogger(),
+			WithEvents(publisher),
+			encryption(t),
+		)
+	)
+
+	doc, err := models.BuildLedger(
+		models.WithNewResourceID(),
+		models.WithName("name"),
+		models.WithAuthorID("author"),
+		models.WithTags([]string{"tag"}),
+		models.WithResourceAddress(uuid.MustNew().String()),
+		models.WithResourceContentType("application/octet-stream"),
+		models.WithCreatedOn(time.Now()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := repo.InsertLedger(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.EraseLedger(res.ResourceID(), Query{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, actual := 2, len(publisher.events); expected != actual {
+		t.Fatalf("expected: %d, actual: %d", expected, actual)
+	}
+
+	event := publisher.events[0]
+	if expected, actual := res.ID().String(), event.ID; expected != actual {
+		t.Errorf("expected: %s, actual: %s", expected, actual)
+	}
+	if expected, actual := models.WebhookEventInsert, event.Type; expected != actual {
+		t.Errorf("expected: %s, actual: %s", expected, actual)
+	}
+	if expected, actual := models.WebhookEventDelete, publisher.events[1].Type; expected != actual {
+		t.Errorf("expected: %s, actual: %s", expected, actual)
+	}
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/trussle/snowy/pkg/models"
 	"github.com/trussle/uuid"
 )
@@ -12,8 +14,21 @@ var (
 // Query allows you to specify different qualifiers when querying the
 // repository
 type Query struct {
-	Tags     []string
-	AuthorID *string
+	Tags           []string
+	AuthorID       *string
+	AcceptEncoding []string
+	Principal      *Principal
+
+	// Tenant scopes the query to a single tenant, the empty tenant is the
+	// default namespace.
+	Tenant string
+}
+
+// Principal is who a query is performed on behalf of, which is checked
+// against the access control list of the resource.
+type Principal struct {
+	ID     string
+	Groups []string
 }
 
 // Repository is an abstraction over the underlying persistence storage, that
@@ -30,14 +45,25 @@ type Repository interface {
 	InsertLedger(doc models.Ledger) (models.Ledger, error)
 
 	// AppendLedger adds a new ledger as a revision. If there is no head
-	// ledger, it will return an error. If there is an error appending
-	// ledgers into the repository then it will return an error.
-	AppendLedger(resourceID uuid.UUID, doc models.Ledger) (models.Ledger, error)
+	// ledger, it will return an error, and if the resource was erased it
+	// will return a erased error. If there is an error appending ledgers
+	// into the repository then it will return an error.
+	AppendLedger(resourceID uuid.UUID, doc models.Ledger, options Query) (models.Ledger, error)
 
 	// ForkLedger adds a new ledger as a branch revision. If there is no head
-	// ledger, it will return an error. If there is an error appending
-	// ledgers into the repository then it will return an error.
-	ForkLedger(resourceID uuid.UUID, doc models.Ledger) (models.Ledger, error)
+	// ledger, it will return an error, and if the resource was erased it
+	// will return a erased error. If there is an error appending ledgers
+	// into the repository then it will return an error.
+	ForkLedger(resourceID uuid.UUID, doc models.Ledger, options Query) (models.Ledger, error)
+
+	// ImportLedgers inserts the ledgers of an export exactly as they were,
+	// keeping their ids, parent ids and times, along with the content that
+	// they refer to, and returns the ledgers that were imported. Ledgers that
+	// already exist, in any tenant, are skipped. The ledgers are imported with
+	// in one transaction, and parents have to come before their children. If
+	// the parent of a ledger is in neither the ledgers nor the tenant, it will
+	// return a not found error.
+	ImportLedgers(docs []models.Ledger, contents []models.Content, options Query) ([]models.Ledger, error)
 
 	// SelectLedgers returns a set of Ledgers corresponding to a resourceID,
 	// with some additional qualifiers. If no ledgers are found it will return
@@ -48,10 +74,66 @@ type Repository interface {
 	// SelectForkLedgers adds a new ledger as a branch revision. If there is no
 	// head ledger, it will return an error. If there is an error appending
 	// ledgers into the repository then it will return an error.
-	SelectForkLedgers(resourceID uuid.UUID) ([]models.Ledger, error)
+	SelectForkLedgers(resourceID uuid.UUID, options Query) ([]models.Ledger, error)
+
+	// SelectResources returns the ids of all the resources that have a
+	// revision by the author, and with any of the tags, of the query. The
+	// resources are in the order they were first created.
+	SelectResources(options Query) ([]uuid.UUID, error)
 
 	// LedgerStatistics returns some statistics about the ledgers
-	LedgerStatistics() (models.LedgerStatistics, error)
+	LedgerStatistics(options Query) (models.LedgerStatistics, error)
+
+	// VerifyLedger recomputes the hash chain of a ledger, reporting the first
+	// revision where the chain is broken. If no ledger exists it will return
+	// an error.
+	VerifyLedger(resourceID uuid.UUID, options Query) (models.LedgerVerification, error)
+
+	// SelectACL returns the access control list of a resource. If no ledger
+	// exists it will return an error.
+	SelectACL(resourceID uuid.UUID, options Query) (models.ACL, error)
+
+	// UpdateACL replaces the access control list of a resource, recording the
+	// change as a new revision of the resource. If no ledger exists it will
+	// return an error.
+	UpdateACL(resourceID uuid.UUID, acl models.ACL, options Query) (models.ACL, error)
+
+	// Authorize checks that the principal of the query has the access to the
+	// resource. If there is no principal, then there is nothing to check.
+	Authorize(resourceID uuid.UUID, access models.Access, options Query) error
+
+	// SelectACLRevisions returns all the revisions of the access control list
+	// of a resource, oldest first.
+	SelectACLRevisions(resourceID uuid.UUID, options Query) ([]models.ACL, error)
+
+	// InsertAuthorKey registers a Ed25519 public key for the author, which is
+	// then used to verify the signatures of the ledgers by the author.
+	InsertAuthorKey(authorID string, publicKey []byte, options Query) (models.AuthorKey, error)
+
+	// SelectAuthorKeys returns all the public keys registered for the author.
+	SelectAuthorKeys(authorID string, options Query) ([]models.AuthorKey, error)
+
+	// Checkpoint appends all the new ledgers to the checkpoint tree and then
+	// signs the new tree head. If there are no new ledgers, then the latest
+	// checkpoint is returned.
+	Checkpoint() (models.Checkpoint, error)
+
+	// SelectCheckpoint returns the checkpoint for the tree size, or the latest
+	// checkpoint if the tree size is zero. If no checkpoint exists it will
+	// return an error.
+	SelectCheckpoint(treeSize int64) (models.Checkpoint, error)
+
+	// SelectCheckpointKey returns the public key that checkpoints are signed
+	// with. If checkpoints aren't configured it will return an error.
+	SelectCheckpointKey() (models.CheckpointKey, error)
+
+	// SelectLedgerProof returns the proof that the ledger is included in the
+	// checkpoint for the tree size (the latest if zero). If fromTreeSize is
+	// not zero, then the proof that the earlier checkpoint is consistent is
+	// also returned. If the ledger isn't in the tenant of the query it will
+	// return a not found error, and if the principal of the query can't read
+	// it, a forbidden error.
+	SelectLedgerProof(ledgerID uuid.UUID, treeSize, fromTreeSize int64, options Query) (models.LedgerProof, error)
 
 	// SelectContent returns a content corresponding to the resourceID. If no
 	// ledger or content exists, it will return an error.
@@ -59,12 +141,71 @@ type Repository interface {
 
 	// PutContent inserts content into the repository. If there is an error
 	// putting content into the repository then it will return an error.
-	PutContent(content models.Content) (models.Content, error)
+	PutContent(content models.Content, options Query) (models.Content, error)
 
 	// SelectContents returns a set of content corresponding to the resourceID. If no
 	// ledger or content exists, it will return an error.
 	SelectContents(resourceID uuid.UUID, options Query) ([]models.Content, error)
 
+	// SelectRevisionContent returns the content of a revision of a ledger,
+	// rather than of the head revision. If the content was erased it will
+	// return an error.
+	SelectRevisionContent(doc models.Ledger, options Query) (models.Content, error)
+
+	// EraseLedger erases the content of a resource, by destroying the key
+	// material of all the content referenced by the resource revisions. The
+	// ledgers are left in place and a tombstone revision is recorded. If no
+	// ledger exists it will return an error, and without encryption it will
+	// return a not supported error.
+	EraseLedger(resourceID uuid.UUID, options Query) (models.Ledger, error)
+
+	// EraseAuthorLedgers erases the content of all the resources that have
+	// revisions by the author, returning the tombstone revisions.
+	EraseAuthorLedgers(authorID string, options Query) ([]models.Ledger, error)
+
+	// SelectQuota returns the quota of the author, along with the current
+	// usage. If the author is empty, then the quota of the whole tenant is
+	// returned. If no quota has been set, then a quota without limits is
+	// returned.
+	SelectQuota(authorID string, options Query) (models.Quota, error)
+
+	// UpdateQuota replaces the quota of the author of the quota, or of the
+	// whole tenant if the author is empty.
+	UpdateQuota(quota models.Quota, options Query) (models.Quota, error)
+
+	// InsertWebhook inserts a webhook subscription, returning the webhook with
+	// its id.
+	InsertWebhook(webhook models.Webhook, options Query) (models.Webhook, error)
+
+	// SelectWebhooks returns all the webhook subscriptions.
+	SelectWebhooks(options Query) ([]models.Webhook, error)
+
+	// DeleteWebhook deletes a webhook subscription. If no webhook exists it
+	// will return an error.
+	DeleteWebhook(webhookID uuid.UUID, options Query) error
+
+	// ClaimPendingDeliveries claims up to limit deliveries, across all the
+	// tenants, that are due to be attempted. The deliveries aren't claimed
+	// again until the lease runs out, so they have to be attempted before
+	// then.
+	ClaimPendingDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+
+	// RecordDeliveryAttempt records an attempt at a delivery, along with the
+	// new state of the delivery.
+	RecordDeliveryAttempt(delivery models.WebhookDelivery, attempt models.WebhookAttempt) error
+
+	// SelectDeliveries returns all the deliveries with the status, oldest
+	// first.
+	SelectDeliveries(status string, options Query) ([]models.WebhookDelivery, error)
+
+	// SelectDeliveryAttempts returns all the attempts at a delivery, oldest
+	// first. If no delivery exists it will return an error.
+	SelectDeliveryAttempts(deliveryID uuid.UUID, options Query) ([]models.WebhookAttempt, error)
+
+	// RedeliverDelivery queues a delivery to be attempted again. If no
+	// delivery exists it will return an error.
+	RedeliverDelivery(deliveryID uuid.UUID, options Query) (models.WebhookDelivery, error)
+
 	// Close the underlying ledger store and returns an error if it fails.
 	Close() error
 }
@@ -105,6 +246,41 @@ func WithQueryAuthorID(authorID string) QueryOption {
 	}
 }
 
+// WithQueryAcceptEncoding adds the encodings that are acceptable to the client
+// from a Accept-Encoding header. If the content is stored using one of the
+// acceptable encodings, the content is returned without decoding it.
+func WithQueryAcceptEncoding(header string) QueryOption {
+	return func(query *Query) error {
+		query.AcceptEncoding = ParseAcceptEncoding(header)
+		return nil
+	}
+}
+
+// WithQueryPrincipal adds the principal, along with the groups it belongs to,
+// to the Query to use for the configuration. If the principal is empty, then
+// the access control lists of resources aren't checked.
+func WithQueryPrincipal(principalID string, groups []string) QueryOption {
+	return func(query *Query) error {
+		if principalID == "" {
+			query.Principal = nil
+		} else {
+			query.Principal = &Principal{
+				ID:     principalID,
+				Groups: groups,
+			}
+		}
+		return nil
+	}
+}
+
+// WithQueryTenant adds the tenant to the Query to use for the configuration.
+func WithQueryTenant(tenant string) QueryOption {
+	return func(query *Query) error {
+		query.Tenant = tenant
+		return nil
+	}
+}
+
 // BuildEmptyQuery creates a Query with empty values.
 func BuildEmptyQuery() Query {
 	return Query{
@@ -137,3 +313,191 @@ func ErrNotFound(err error) bool {
 	}
 	return false
 }
+
+type erased interface {
+	Erased() bool
+}
+
+type errErased struct {
+	err error
+}
+
+func (e errErased) Error() string {
+	return e.err.Error()
+}
+
+func (e errErased) Erased() bool {
+	return true
+}
+
+// ErrErased tests to see if the error passed is a erased error or not.
+func ErrErased(err error) bool {
+	if err != nil {
+		if _, ok := err.(erased); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type invalidSignature interface {
+	InvalidSignature() bool
+}
+
+type errInvalidSignature struct {
+	err error
+}
+
+func (e errInvalidSignature) Error() string {
+	return e.err.Error()
+}
+
+func (e errInvalidSignature) InvalidSignature() bool {
+	return true
+}
+
+// ErrInvalidSignature tests to see if the error passed is a invalid signature
+// error or not.
+func ErrInvalidSignature(err error) bool {
+	if err != nil {
+		if _, ok := err.(invalidSignature); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type forbidden interface {
+	Forbidden() bool
+}
+
+type errForbidden struct {
+	err error
+}
+
+func (e errForbidden) Error() string {
+	return e.err.Error()
+}
+
+func (e errForbidden) Forbidden() bool {
+	return true
+}
+
+// ErrForbidden tests to see if the error passed is a forbidden error or not.
+func ErrForbidden(err error) bool {
+	if err != nil {
+		if _, ok := err.(forbidden); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type quotaExceeded interface {
+	QuotaExceeded() bool
+}
+
+type errQuotaExceeded struct {
+	err error
+}
+
+func (e errQuotaExceeded) Error() string {
+	return e.err.Error()
+}
+
+func (e errQuotaExceeded) QuotaExceeded() bool {
+	return true
+}
+
+// ErrQuotaExceeded tests to see if the error passed is a quota exceeded error
+// or not, which is when storing more bytes or resources isn't allowed.
+func ErrQuotaExceeded(err error) bool {
+	if err != nil {
+		if _, ok := err.(quotaExceeded); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type dailyQuotaExceeded interface {
+	DailyQuotaExceeded() bool
+}
+
+type errDailyQuotaExceeded struct {
+	err error
+}
+
+func (e errDailyQuotaExceeded) Error() string {
+	return e.err.Error()
+}
+
+func (e errDailyQuotaExceeded) DailyQuotaExceeded() bool {
+	return true
+}
+
+// ErrDailyQuotaExceeded tests to see if the error passed is a daily quota
+// exceeded error or not, which is when no more revisions are allowed until
+// the next day.
+func ErrDailyQuotaExceeded(err error) bool {
+	if err != nil {
+		if _, ok := err.(dailyQuotaExceeded); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type duplicate interface {
+	Duplicate() bool
+}
+
+type errDuplicate struct {
+	err error
+}
+
+func (e errDuplicate) Error() string {
+	return e.err.Error()
+}
+
+func (e errDuplicate) Duplicate() bool {
+	return true
+}
+
+// ErrDuplicate tests to see if the error passed is a duplicate error or not,
+// which is when a ledger with the same id already exists.
+func ErrDuplicate(err error) bool {
+	if err != nil {
+		if _, ok := err.(duplicate); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type notSupported interface {
+	NotSupported() bool
+}
+
+type errNotSupported struct {
+	err error
+}
+
+func (e errNotSupported) Error() string {
+	return e.err.Error()
+}
+
+func (e errNotSupported) NotSupported() bool {
+	return true
+}
+
+// ErrNotSupported tests to see if the error passed is a not supported error
+// or not, which is when the repository isn't configured for the action.
+func ErrNotSupported(err error) bool {
+	if err != nil {
+		if _, ok := err.(notSupported); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+)
+
+// Signer signs the tree heads of checkpoints, so that third parties can verify
+// a checkpoint with only the public key.
+type Signer interface {
+
+	// KeyID returns the identifier of the signing key.
+	KeyID() string
+
+	// PublicKey returns the public key to verify the signatures with.
+	PublicKey() ed25519.PublicKey
+
+	// Sign signs the data, returning the signature.
+	Sign(data []byte) ([]byte, error)
+}
+
+type localSigner struct {
+	id  string
+	key ed25519.PrivateKey
+}
+
+// NewLocalSigner creates a Signer from a Ed25519 seed held in a local key
+// file. The key file is expected to contain a hex encoded 32 byte seed.
+func NewLocalSigner(path string) (Signer, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read key file")
+	}
+
+	seed, err := hex.DecodeString(strings.TrimSpace(string(bytes)))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decode key file")
+	}
+
+	return newLocalSigner(seed)
+}
+
+func newLocalSigner(seed []byte) (Signer, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, errors.Errorf("invalid signing key size %d", len(seed))
+	}
+
+	key := ed25519.NewKeyFromSeed(seed)
+	return &localSigner{
+		id:  signingKeyID(key.Public().(ed25519.PublicKey)),
+		key: key,
+	}, nil
+}
+
+func (s *localSigner) KeyID() string {
+	return s.id
+}
+
+func (s *localSigner) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+func (s *localSigner) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, data), nil
+}
+
+// signingKeyID derives the id of a signing key from the public key.
+func signingKeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return "ed25519:" + hex.EncodeToString(sum[:8])
+}
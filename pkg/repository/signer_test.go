@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestLocalSigner(t *testing.T) {
+	t.Parallel()
+
+	seed := make([]byte, ed25519.SeedSize)
+	for k := range seed {
+		seed[k] = byte(k)
+	}
+
+	t.Run("sign then verify", func(t *testing.T) {
+		signer, err := newLocalSigner(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fn := func(data []byte) bool {
+			signature, err := signer.Sign(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return ed25519.Verify(signer.PublicKey(), data, signature)
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("key id", func(t *testing.T) {
+		signer, err := newLocalSigner(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := true, strings.HasPrefix(signer.KeyID(), "ed25519:"); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("invalid seed size", func(t *testing.T) {
+		_, err := newLocalSigner([]byte("seed"))
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		root, cleanup := tempDir(t)
+		defer cleanup()
+
+		path := filepath.Join(root, "key")
+		if err := ioutil.WriteFile(path, []byte(hex.EncodeToString(seed)+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		signer, err := NewLocalSigner(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		other, err := newLocalSigner(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := other.KeyID(), signer.KeyID(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/snowy/pkg/trace"
+	"github.com/trussle/uuid"
+)
+
+// contextual is implemented by the repositories that can be bound to a
+// context.
+type contextual interface {
+	withContext(context.Context) Repository
+}
+
+// WithContext returns the repository bound to the context, so that the spans
+// and the logs of the repository are correlated with the request of the
+// context. Repositories that can't be bound are returned as they are.
+func WithContext(ctx context.Context, repository Repository) Repository {
+	if c, ok := repository.(contextual); ok {
+		return c.withContext(ctx)
+	}
+	return repository
+}
+
+// withContext binds the store, the blobs and the logger of the repository to
+// the context.
+func (r *realRepository) withContext(ctx context.Context) Repository {
+	repository := *r
+	repository.store = store.WithContext(ctx, r.store)
+	repository.blobs = blobsWithContext(ctx, r.blobs)
+	repository.logger = trace.Logger(ctx, r.logger)
+	return &repository
+}
+
+type tracedRepository struct {
+	repository Repository
+	tracer     *trace.Tracer
+	ctx        context.Context
+}
+
+// NewTracedRepository creates a Repository that records a span for every call
+// to the repository. The spans are children of the span of the context that
+// the repository is bound to, see WithContext. Calls of a repository that isn't
+// bound to a span aren't traced.
+func NewTracedRepository(repository Repository, tracer *trace.Tracer) Repository {
+	return &tracedRepository{
+		repository: repository,
+		tracer:     tracer,
+		ctx:        context.Background(),
+	}
+}
+
+func (r *tracedRepository) withContext(ctx context.Context) Repository {
+	return &tracedRepository{
+		repository: r.repository,
+		tracer:     r.tracer,
+		ctx:        ctx,
+	}
+}
+
+// start starts the span of a call, returning the repository bound to the span,
+// so that the calls it makes are children of the span.
+func (r *tracedRepository) start(name string) (Repository, *trace.Span) {
+	ctx, span := r.tracer.StartChild(r.ctx, name)
+	return WithContext(ctx, r.repository), span
+}
+
+func (r *tracedRepository) SelectLedger(resourceID uuid.UUID, options Query) (res models.Ledger, err error) {
+	inner, span := r.start("repository.SelectLedger")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectLedger(resourceID, options)
+}
+
+func (r *tracedRepository) InsertLedger(doc models.Ledger) (res models.Ledger, err error) {
+	inner, span := r.start("repository.InsertLedger")
+	defer func() { span.Finish(err) }()
+
+	return inner.InsertLedger(doc)
+}
+
+func (r *tracedRepository) AppendLedger(resourceID uuid.UUID, doc models.Ledger, options Query) (res models.Ledger, err error) {
+	inner, span := r.start("repository.AppendLedger")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.AppendLedger(resourceID, doc, options)
+}
+
+func (r *tracedRepository) ForkLedger(resourceID uuid.UUID, doc models.Ledger, options Query) (res models.Ledger, err error) {
+	inner, span := r.start("repository.ForkLedger")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.ForkLedger(resourceID, doc, options)
+}
+
+func (r *tracedRepository) ImportLedgers(docs []models.Ledger, contents []models.Content, options Query) (res []models.Ledger, err error) {
+	inner, span := r.start("repository.ImportLedgers")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.ImportLedgers(docs, contents, options)
+}
+
+func (r *tracedRepository) SelectLedgers(resourceID uuid.UUID, options Query) (res []models.Ledger, err error) {
+	inner, span := r.start("repository.SelectLedgers")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectLedgers(resourceID, options)
+}
+
+func (r *tracedRepository) SelectForkLedgers(resourceID uuid.UUID, options Query) (res []models.Ledger, err error) {
+	inner, span := r.start("repository.SelectForkLedgers")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectForkLedgers(resourceID, options)
+}
+
+func (r *tracedRepository) SelectResources(options Query) (res []uuid.UUID, err error) {
+	inner, span := r.start("repository.SelectResources")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectResources(options)
+}
+
+func (r *tracedRepository) LedgerStatistics(options Query) (res models.LedgerStatistics, err error) {
+	inner, span := r.start("repository.LedgerStatistics")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.LedgerStatistics(options)
+}
+
+func (r *tracedRepository) VerifyLedger(resourceID uuid.UUID, options Query) (res models.LedgerVerification, err error) {
+	inner, span := r.start("repository.VerifyLedger")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.VerifyLedger(resourceID, options)
+}
+
+func (r *tracedRepository) SelectACL(resourceID uuid.UUID, options Query) (res models.ACL, err error) {
+	inner, span := r.start("repository.SelectACL")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectACL(resourceID, options)
+}
+
+func (r *tracedRepository) UpdateACL(resourceID uuid.UUID, acl models.ACL, options Query) (res models.ACL, err error) {
+	inner, span := r.start("repository.UpdateACL")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.UpdateACL(resourceID, acl, options)
+}
+
+func (r *tracedRepository) Authorize(resourceID uuid.UUID, access models.Access, options Query) (err error) {
+	inner, span := r.start("repository.Authorize")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.Authorize(resourceID, access, options)
+}
+
+func (r *tracedRepository) SelectACLRevisions(resourceID uuid.UUID, options Query) (res []models.ACL, err error) {
+	inner, span := r.start("repository.SelectACLRevisions")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectACLRevisions(resourceID, options)
+}
+
+func (r *tracedRepository) InsertAuthorKey(authorID string, publicKey []byte, options Query) (res models.AuthorKey, err error) {
+	inner, span := r.start("repository.InsertAuthorKey")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.InsertAuthorKey(authorID, publicKey, options)
+}
+
+func (r *tracedRepository) SelectAuthorKeys(authorID string, options Query) (res []models.AuthorKey, err error) {
+	inner, span := r.start("repository.SelectAuthorKeys")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectAuthorKeys(authorID, options)
+}
+
+func (r *tracedRepository) Checkpoint() (res models.Checkpoint, err error) {
+	inner, span := r.start("repository.Checkpoint")
+	defer func() { span.Finish(err) }()
+
+	return inner.Checkpoint()
+}
+
+func (r *tracedRepository) SelectCheckpoint(treeSize int64) (res models.Checkpoint, err error) {
+	inner, span := r.start("repository.SelectCheckpoint")
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectCheckpoint(treeSize)
+}
+
+func (r *tracedRepository) SelectCheckpointKey() (res models.CheckpointKey, err error) {
+	inner, span := r.start("repository.SelectCheckpointKey")
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectCheckpointKey()
+}
+
+func (r *tracedRepository) SelectLedgerProof(ledgerID uuid.UUID, treeSize, fromTreeSize int64, options Query) (res models.LedgerProof, err error) {
+	inner, span := r.start("repository.SelectLedgerProof")
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectLedgerProof(ledgerID, treeSize, fromTreeSize, options)
+}
+
+func (r *tracedRepository) SelectContent(resourceID uuid.UUID, options Query) (res models.Content, err error) {
+	inner, span := r.start("repository.SelectContent")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectContent(resourceID, options)
+}
+
+func (r *tracedRepository) PutContent(content models.Content, options Query) (res models.Content, err error) {
+	inner, span := r.start("repository.PutContent")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.PutContent(content, options)
+}
+
+func (r *tracedRepository) SelectContents(resourceID uuid.UUID, options Query) (res []models.Content, err error) {
+	inner, span := r.start("repository.SelectContents")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectContents(resourceID, options)
+}
+
+func (r *tracedRepository) SelectRevisionContent(doc models.Ledger, options Query) (res models.Content, err error) {
+	inner, span := r.start("repository.SelectRevisionContent")
+	span.SetAttribute("resource_id", doc.ResourceID().String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectRevisionContent(doc, options)
+}
+
+func (r *tracedRepository) EraseLedger(resourceID uuid.UUID, options Query) (res models.Ledger, err error) {
+	inner, span := r.start("repository.EraseLedger")
+	span.SetAttribute("resource_id", resourceID.String())
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.EraseLedger(resourceID, options)
+}
+
+func (r *tracedRepository) EraseAuthorLedgers(authorID string, options Query) (res []models.Ledger, err error) {
+	inner, span := r.start("repository.EraseAuthorLedgers")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.EraseAuthorLedgers(authorID, options)
+}
+
+func (r *tracedRepository) SelectQuota(authorID string, options Query) (res models.Quota, err error) {
+	inner, span := r.start("repository.SelectQuota")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectQuota(authorID, options)
+}
+
+func (r *tracedRepository) UpdateQuota(quota models.Quota, options Query) (res models.Quota, err error) {
+	inner, span := r.start("repository.UpdateQuota")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.UpdateQuota(quota, options)
+}
+
+func (r *tracedRepository) InsertWebhook(webhook models.Webhook, options Query) (res models.Webhook, err error) {
+	inner, span := r.start("repository.InsertWebhook")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.InsertWebhook(webhook, options)
+}
+
+func (r *tracedRepository) SelectWebhooks(options Query) (res []models.Webhook, err error) {
+	inner, span := r.start("repository.SelectWebhooks")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectWebhooks(options)
+}
+
+func (r *tracedRepository) DeleteWebhook(webhookID uuid.UUID, options Query) (err error) {
+	inner, span := r.start("repository.DeleteWebhook")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.DeleteWebhook(webhookID, options)
+}
+
+func (r *tracedRepository) ClaimPendingDeliveries(limit int, lease time.Duration) (res []models.WebhookDelivery, err error) {
+	inner, span := r.start("repository.ClaimPendingDeliveries")
+	defer func() { span.Finish(err) }()
+
+	return inner.ClaimPendingDeliveries(limit, lease)
+}
+
+func (r *tracedRepository) RecordDeliveryAttempt(delivery models.WebhookDelivery, attempt models.WebhookAttempt) (err error) {
+	inner, span := r.start("repository.RecordDeliveryAttempt")
+	defer func() { span.Finish(err) }()
+
+	return inner.RecordDeliveryAttempt(delivery, attempt)
+}
+
+func (r *tracedRepository) SelectDeliveries(status string, options Query) (res []models.WebhookDelivery, err error) {
+	inner, span := r.start("repository.SelectDeliveries")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectDeliveries(status, options)
+}
+
+func (r *tracedRepository) SelectDeliveryAttempts(deliveryID uuid.UUID, options Query) (res []models.WebhookAttempt, err error) {
+	inner, span := r.start("repository.SelectDeliveryAttempts")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.SelectDeliveryAttempts(deliveryID, options)
+}
+
+func (r *tracedRepository) RedeliverDelivery(deliveryID uuid.UUID, options Query) (res models.WebhookDelivery, err error) {
+	inner, span := r.start("repository.RedeliverDelivery")
+	span.SetAttribute("tenant", options.Tenant)
+	defer func() { span.Finish(err) }()
+
+	return inner.RedeliverDelivery(deliveryID, options)
+}
+
+func (r *tracedRepository) Close() error {
+	return r.repository.Close()
+}
+
+// blobsWithContext returns the blob store bound to the context. Blob stores
+// that aren't traced are returned as they are.
+func blobsWithContext(ctx context.Context, blobs BlobStore) BlobStore {
+	if traced, ok := blobs.(*tracedBlobStore); ok {
+		return traced.withContext(ctx)
+	}
+	return blobs
+}
+
+type tracedBlobStore struct {
+	blobs  BlobStore
+	tracer *trace.Tracer
+	ctx    context.Context
+}
+
+// NewTracedBlobStore creates a BlobStore that records a span for every call to
+// the blob store, while it's bound to a span. Reading a blob isn't part of the
+// span of Get, as the blob is read after Get returns.
+func NewTracedBlobStore(blobs BlobStore, tracer *trace.Tracer) BlobStore {
+	return &tracedBlobStore{
+		blobs:  blobs,
+		tracer: tracer,
+		ctx:    context.Background(),
+	}
+}
+
+func (b *tracedBlobStore) withContext(ctx context.Context) BlobStore {
+	return &tracedBlobStore{
+		blobs:  b.blobs,
+		tracer: b.tracer,
+		ctx:    ctx,
+	}
+}
+
+func (b *tracedBlobStore) start(name string) *trace.Span {
+	_, span := b.tracer.StartChild(b.ctx, name)
+	return span
+}
+
+func (b *tracedBlobStore) Put(reader io.Reader, options BlobOptions) (address string, err error) {
+	span := b.start("blobs.Put")
+	defer func() {
+		span.SetAttribute("address", address)
+		span.Finish(err)
+	}()
+
+	return b.blobs.Put(reader, options)
+}
+
+func (b *tracedBlobStore) Get(address string) (res Blob, err error) {
+	span := b.start("blobs.Get")
+	span.SetAttribute("address", address)
+	defer func() { span.Finish(err) }()
+
+	return b.blobs.Get(address)
+}
+
+func (b *tracedBlobStore) Stat(address string) (res BlobInfo, err error) {
+	span := b.start("blobs.Stat")
+	span.SetAttribute("address", address)
+	defer func() { span.Finish(err) }()
+
+	return b.blobs.Stat(address)
+}
+
+func (b *tracedBlobStore) Delete(address string) (err error) {
+	span := b.start("blobs.Delete")
+	span.SetAttribute("address", address)
+	defer func() { span.Finish(err) }()
+
+	return b.blobs.Delete(address)
+}
+
+func (b *tracedBlobStore) List(fn func(BlobInfo) error) (err error) {
+	span := b.start("blobs.List")
+	defer func() { span.Finish(err) }()
+
+	return b.blobs.List(fn)
+}
+
+func (b *tracedBlobStore) Tenant(tenantID string) BlobStore {
+	return &tracedBlobStore{
+		blobs:  b.blobs.Tenant(tenantID),
+		tracer: b.tracer,
+		ctx:    b.ctx,
+	}
+}
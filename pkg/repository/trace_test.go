@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/trussle/fsys"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/snowy/pkg/trace"
+	"github.com/trussle/uuid"
+)
+
+type spanRecorder struct {
+	mutex sync.Mutex
+	spans []trace.Span
+}
+
+func (r *spanRecorder) Export(span trace.Span) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func (r *spanRecorder) Close() error { return nil }
+
+func (r *spanRecorder) Names() map[string]trace.Span {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	res := make(map[string]trace.Span)
+	for _, span := range r.spans {
+		res[span.Name] = span
+	}
+	return res
+}
+
+func TestTracedRepository(t *testing.T) {
+	t.Parallel()
+
+	newRepository := func(tracer *trace.Tracer) Repository {
+		var (
+			blobs = NewTracedBlobStore(NewFilesystemBlobStore(fsys.NewVirtualFilesystem()), tracer)
+			store = store.NewTracedStore(store.NewVirtualStore(), tracer)
+		)
+		return NewTracedRepository(NewRealRepository(blobs, store, log.NewNopLogger()), tracer)
+	}
+
+	t.Run("traces bound calls", func(t *testing.T) {
+		var (
+			rec    = &spanRecorder{}
+			tracer = trace.NewTracer(trace.WithExporter(rec))
+			repo   = newRepository(tracer)
+		)
+
+		content, err := models.BuildContent(
+			models.WithContentBytes([]byte("hello")),
+			models.WithSize(5),
+			models.WithContentType("text/plain"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, root := tracer.Start(context.Background(), "root")
+		if _, err := WithContext(ctx, repo).PutContent(content, Query{}); err != nil {
+			t.Fatal(err)
+		}
+		root.Finish(nil)
+
+		spans := rec.Names()
+		put, ok := spans["repository.PutContent"]
+		if !ok {
+			t.Fatalf("expected: repository.PutContent span, actual: %v", spans)
+		}
+		if expected, actual := root.SpanID, put.ParentID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		blob, ok := spans["blobs.Put"]
+		if !ok {
+			t.Fatalf("expected: blobs.Put span, actual: %v", spans)
+		}
+		if expected, actual := put.SpanID, blob.ParentID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := content.Address(), blob.Attributes["address"]; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("traces store errors", func(t *testing.T) {
+		var (
+			rec    = &spanRecorder{}
+			tracer = trace.NewTracer(trace.WithExporter(rec))
+			repo   = newRepository(tracer)
+		)
+
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, err := WithContext(ctx, repo).SelectLedger(uuid.MustNew(), Query{})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		root.Finish(nil)
+
+		spans := rec.Names()
+		selectLedger, ok := spans["repository.SelectLedger"]
+		if !ok {
+			t.Fatalf("expected: repository.SelectLedger span, actual: %v", spans)
+		}
+		if selectLedger.Error == "" {
+			t.Errorf("expected: error, actual: %q", selectLedger.Error)
+		}
+
+		selectStore, ok := spans["store.Select"]
+		if !ok {
+			t.Fatalf("expected: store.Select span, actual: %v", spans)
+		}
+		if expected, actual := selectLedger.SpanID, selectStore.ParentID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("unbound calls aren't traced", func(t *testing.T) {
+		var (
+			rec    = &spanRecorder{}
+			tracer = trace.NewTracer(trace.WithExporter(rec))
+			repo   = newRepository(tracer)
+		)
+
+		if _, err := repo.SelectLedger(uuid.MustNew(), Query{}); err == nil {
+			t.Errorf("expected: error, actual: %v", err)
+		}
+
+		if expected, actual := 0, len(rec.Names()); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
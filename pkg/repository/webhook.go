@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/uuid"
+)
+
+// InsertWebhook inserts a webhook subscription into the tenant of the query,
+// owned by the principal of the query.
+func (r *realRepository) InsertWebhook(webhook models.Webhook, options Query) (models.Webhook, error) {
+	id, err := uuid.New()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	if options.Principal != nil {
+		webhook.Owner = options.Principal.ID
+	}
+
+	webhook.ID = id
+	webhook.CreatedOn = time.Now()
+	if err = r.store.InsertWebhook(store.Webhook{
+		ID:         webhook.ID,
+		TenantID:   options.Tenant,
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		ResourceID: webhook.ResourceID,
+		Tag:        webhook.Tag,
+		AuthorID:   webhook.AuthorID,
+		Events:     webhook.Events,
+		Owner:      webhook.Owner,
+		CreatedOn:  webhook.CreatedOn,
+	}); err != nil {
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// SelectWebhooks returns all the webhook subscriptions of the tenant of the
+// query that are owned by the principal of the query.
+func (r *realRepository) SelectWebhooks(options Query) ([]models.Webhook, error) {
+	webhooks, err := r.ownedWebhooks(options)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models.Webhook, 0, len(webhooks))
+	for _, v := range webhooks {
+		res = append(res, webhookToModel(v))
+	}
+	return res, nil
+}
+
+// DeleteWebhook deletes a webhook subscription, which only the owner of the
+// webhook can do. Any pending deliveries to the webhook are no longer
+// attempted.
+func (r *realRepository) DeleteWebhook(webhookID uuid.UUID, options Query) error {
+	webhooks, err := r.ownedWebhooks(options)
+	if err != nil {
+		return err
+	}
+	if _, ok := webhooks[webhookID]; !ok {
+		return errNotFound{errors.Errorf("webhook %q not found", webhookID.String())}
+	}
+
+	if err := r.store.DeleteWebhook(options.Tenant, webhookID); err != nil {
+		if store.ErrNotFound(err) {
+			return errNotFound{err}
+		}
+		return err
+	}
+	return nil
+}
+
+// ClaimPendingDeliveries claims up to limit deliveries, across all the
+// tenants, that are due to be attempted, until the lease runs out.
+func (r *realRepository) ClaimPendingDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	now := time.Now()
+	deliveries, err := r.store.ClaimPendingDeliveries(now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models.WebhookDelivery, len(deliveries))
+	for k, v := range deliveries {
+		res[k] = deliveryToModel(v)
+	}
+	return res, nil
+}
+
+// RecordDeliveryAttempt records the attempt along with the new state of the
+// delivery.
+func (r *realRepository) RecordDeliveryAttempt(delivery models.WebhookDelivery, attempt models.WebhookAttempt) error {
+	if err := r.store.InsertDeliveryAttempt(store.DeliveryAttempt{
+		DeliveryID:  delivery.ID,
+		AttemptedOn: attempt.AttemptedOn,
+		StatusCode:  attempt.StatusCode,
+		Error:       attempt.Error,
+	}); err != nil {
+		return err
+	}
+
+	return r.store.UpdateDelivery(store.Delivery{
+		ID:            delivery.ID,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		LastError:     delivery.LastError,
+		NextAttemptOn: delivery.NextAttemptOn,
+		DeliveredOn:   delivery.DeliveredOn,
+	})
+}
+
+// SelectDeliveries returns all the deliveries of the tenant of the query with
+// the status, to the webhooks owned by the principal of the query, oldest
+// first.
+func (r *realRepository) SelectDeliveries(status string, options Query) ([]models.WebhookDelivery, error) {
+	webhooks, err := r.ownedWebhooks(options)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := r.store.SelectDeliveries(options.Tenant, status)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models.WebhookDelivery, 0, len(deliveries))
+	for _, v := range deliveries {
+		if _, ok := webhooks[v.WebhookID]; ok {
+			res = append(res, deliveryToModel(v))
+		}
+	}
+	return res, nil
+}
+
+// SelectDeliveryAttempts returns all the attempts at a delivery, oldest
+// first.
+func (r *realRepository) SelectDeliveryAttempts(deliveryID uuid.UUID, options Query) ([]models.WebhookAttempt, error) {
+	// Make sure the delivery belongs to the tenant.
+	if _, err := r.selectDelivery(deliveryID, options); err != nil {
+		return nil, err
+	}
+
+	attempts, err := r.store.SelectDeliveryAttempts(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]models.WebhookAttempt, len(attempts))
+	for k, v := range attempts {
+		res[k] = models.WebhookAttempt{
+			DeliveryID:  v.DeliveryID,
+			AttemptedOn: v.AttemptedOn,
+			StatusCode:  v.StatusCode,
+			Error:       v.Error,
+		}
+	}
+	return res, nil
+}
+
+// RedeliverDelivery queues the delivery to be attempted again straight away,
+// with a fresh set of attempts.
+func (r *realRepository) RedeliverDelivery(deliveryID uuid.UUID, options Query) (models.WebhookDelivery, error) {
+	delivery, err := r.selectDelivery(deliveryID, options)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery.Status = store.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptOn = time.Now()
+	delivery.DeliveredOn = time.Time{}
+	if err = r.store.UpdateDelivery(delivery); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return deliveryToModel(delivery), nil
+}
+
+func (r *realRepository) selectDelivery(deliveryID uuid.UUID, options Query) (store.Delivery, error) {
+	delivery, err := r.store.SelectDelivery(options.Tenant, deliveryID)
+	if err != nil {
+		if store.ErrNotFound(err) {
+			return store.Delivery{}, errNotFound{err}
+		}
+		return store.Delivery{}, err
+	}
+
+	// Make sure the delivery is to a webhook of the principal.
+	webhooks, err := r.ownedWebhooks(options)
+	if err != nil {
+		return store.Delivery{}, err
+	}
+	if _, ok := webhooks[delivery.WebhookID]; !ok {
+		return store.Delivery{}, errNotFound{errors.Errorf("delivery %q not found", deliveryID.String())}
+	}
+	return delivery, nil
+}
+
+// ownedWebhooks returns the webhooks of the tenant of the query that are owned
+// by the principal of the query, by id. If there is no principal, then every
+// webhook of the tenant is returned, as are webhooks that were created without
+// a principal, as auth was disabled.
+func (r *realRepository) ownedWebhooks(options Query) (map[uuid.UUID]store.Webhook, error) {
+	webhooks, err := r.store.SelectWebhooks(options.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uuid.UUID]store.Webhook, len(webhooks))
+	for _, v := range webhooks {
+		if options.Principal == nil || v.Owner == "" || v.Owner == options.Principal.ID {
+			res[v.ID] = v
+		}
+	}
+	return res, nil
+}
+
+// canDeliver checks that the owner of the webhook can read the resource of
+// the ledger, so that a webhook is never sent the changes of resources that
+// its owner can't read. Only the access granted to the owner itself is
+// considered, as the groups of the owner are only known when it makes a
+// request, and may have changed since the webhook was created.
+func (r *realRepository) canDeliver(webhook store.Webhook, doc models.Ledger) (bool, error) {
+	if webhook.Owner == "" {
+		return true, nil
+	}
+
+	err := r.authorize(doc.ResourceID(), Query{
+		Tenant: doc.TenantID(),
+		Principal: &Principal{
+			ID: webhook.Owner,
+		},
+	}, models.AccessRead)
+	if err != nil {
+		if ErrForbidden(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *realRepository) enqueueDelivery(webhook store.Webhook, event string, doc models.Ledger, now time.Time) error {
+	id, err := uuid.New()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(struct {
+		ID        string        `json:"id"`
+		Event     string        `json:"event"`
+		Tenant    string        `json:"tenant"`
+		CreatedOn string        `json:"created_on"`
+		Ledger    models.Ledger `json:"ledger"`
+	}{
+		ID:        id.String(),
+		Event:     event,
+		Tenant:    doc.TenantID(),
+		CreatedOn: now.Format(time.RFC3339),
+		Ledger:    doc,
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.store.InsertDelivery(store.Delivery{
+		ID:            id,
+		WebhookID:     webhook.ID,
+		TenantID:      webhook.TenantID,
+		Event:         event,
+		Payload:       payload,
+		Status:        store.DeliveryStatusPending,
+		NextAttemptOn: now,
+		CreatedOn:     now,
+	})
+}
+
+func webhookToModel(webhook store.Webhook) models.Webhook {
+	return models.Webhook{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		ResourceID: webhook.ResourceID,
+		Tag:        webhook.Tag,
+		AuthorID:   webhook.AuthorID,
+		Events:     webhook.Events,
+		Owner:      webhook.Owner,
+		CreatedOn:  webhook.CreatedOn,
+	}
+}
+
+func deliveryToModel(delivery store.Delivery) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		LastError:     delivery.LastError,
+		NextAttemptOn: delivery.NextAttemptOn,
+		CreatedOn:     delivery.CreatedOn,
+		DeliveredOn:   delivery.DeliveredOn,
+		URL:           delivery.URL,
+		Secret:        delivery.Secret,
+	}
+}
@@ -0,0 +1,276 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/rpc/pb"
+	"github.com/trussle/snowy/pkg/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodLabel is the method label of the duration metric for every call, as
+// the path label is the full gRPC method.
+const methodLabel = "GRPC"
+
+// These are the prefixes of the full methods of each service, which is the
+// path of the request that the middleware sees, so that middleware can match
+// calls by service (rate limits for example).
+const (
+	MethodPrefixLedgers  = "/snowy.Ledgers/"
+	MethodPrefixContents = "/snowy.Contents/"
+)
+
+// API serves the gRPC API, which exposes the ledgers and contents on top of
+// the repository, the same as the REST API.
+type API struct {
+	server     *grpc.Server
+	repository repository.Repository
+	logger     log.Logger
+	clients    metrics.Gauge
+	bytes      metrics.Counter
+	records    metrics.Counter
+	duration   metrics.HistogramVec
+	middleware func(http.Handler) http.Handler
+	handler    http.Handler
+}
+
+// APIOption defines a option for configuring the API.
+type APIOption func(*API)
+
+// WithMiddleware runs every call through the middleware before it's served,
+// so that calls are authenticated and scoped to a tenant the same as the REST
+// API. The metadata of the call is passed to the middleware as the headers of
+// a request, so only middleware that relies on the headers alone is supported
+// (api keys, bearer tokens and tenants, but not signed requests). The path of
+// the request is the full method of the call and the remote address is the
+// address of the peer. The middleware is created once, so it can keep state
+// across calls.
+func WithMiddleware(middleware func(http.Handler) http.Handler) APIOption {
+	return func(a *API) {
+		a.middleware = middleware
+	}
+}
+
+// NewAPI creates a API with correct dependencies.
+func NewAPI(repository repository.Repository, logger log.Logger,
+	clients metrics.Gauge,
+	bytes, records metrics.Counter,
+	duration metrics.HistogramVec,
+	opts ...APIOption,
+) *API {
+	api := &API{
+		repository: repository,
+		logger:     logger,
+		clients:    clients,
+		bytes:      bytes,
+		records:    records,
+		duration:   duration,
+	}
+	for _, opt := range opts {
+		opt(api)
+	}
+	if api.middleware != nil {
+		api.handler = api.middleware(http.HandlerFunc(accept))
+	}
+
+	api.server = grpc.NewServer(
+		grpc.UnaryInterceptor(api.interceptUnary),
+		grpc.StreamInterceptor(api.interceptStream),
+	)
+	pb.RegisterLedgersServer(api.server, ledgersServer{api})
+	pb.RegisterContentsServer(api.server, contentsServer{api})
+	return api
+}
+
+// Serve serves the API on the listener, until the API is stopped.
+func (a *API) Serve(listener net.Listener) error {
+	return a.server.Serve(listener)
+}
+
+// Stop stops the API, waiting for any calls in flight.
+func (a *API) Stop() {
+	a.server.GracefulStop()
+}
+
+func (a *API) interceptUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	level.Info(a.logger).Log("method", info.FullMethod)
+
+	// Metrics
+	a.clients.Inc()
+	defer a.clients.Dec()
+
+	begin := time.Now()
+
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		a.observe(info.FullMethod, err, begin)
+		return nil, err
+	}
+
+	resp, err := handler(ctx, req)
+	a.observe(info.FullMethod, err, begin)
+	return resp, err
+}
+
+func (a *API) interceptStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	level.Info(a.logger).Log("method", info.FullMethod)
+
+	// Metrics
+	a.clients.Inc()
+	defer a.clients.Dec()
+
+	begin := time.Now()
+
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		a.observe(info.FullMethod, err, begin)
+		return err
+	}
+
+	err = handler(srv, &serverStream{ss, ctx})
+	a.observe(info.FullMethod, err, begin)
+	return err
+}
+
+func (a *API) observe(method string, err error, begin time.Time) {
+	a.duration.WithLabelValues(
+		methodLabel,
+		method,
+		status.Code(err).String(),
+	).Observe(time.Since(begin).Seconds())
+}
+
+// authenticate runs the call through the middleware, returning the context
+// that the middleware passes on, with the principal and tenant attached. If
+// the middleware rejects the call, then the status code is converted in to
+// the error.
+func (a *API) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if a.handler == nil {
+		return ctx, nil
+	}
+
+	r, err := http.NewRequest("POST", method, nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, values := range md {
+			for _, v := range values {
+				r.Header.Add(k, v)
+			}
+		}
+	}
+
+	var (
+		res = new(context.Context)
+		rec = &responseRecorder{code: http.StatusOK, header: make(http.Header)}
+	)
+	a.handler.ServeHTTP(rec, r.WithContext(context.WithValue(ctx, acceptedKey, res)))
+
+	if *res == nil {
+		return nil, status.Error(httpCode(rec.code), strings.TrimSpace(rec.body.String()))
+	}
+	return *res, nil
+}
+
+type contextKey int
+
+const acceptedKey contextKey = iota
+
+// accept is the last handler of the middleware, which records the context
+// that the middleware passes on, so that the call can be served with it.
+func accept(w http.ResponseWriter, r *http.Request) {
+	if res, ok := r.Context().Value(acceptedKey).(*context.Context); ok {
+		*res = r.Context()
+	}
+}
+
+// serverStream replaces the context of the stream with the authenticated one.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// responseRecorder records the response of the middleware when it rejects a
+// call.
+type responseRecorder struct {
+	code   int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header         { return r.header }
+func (r *responseRecorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *responseRecorder) WriteHeader(code int)        { r.code = code }
+
+// httpCode converts the status code of a rejected call in to a gRPC code.
+func httpCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	default:
+		return codes.Unknown
+	}
+}
+
+// repositoryError converts an error of the repository in to a gRPC error.
+func repositoryError(err error) error {
+	switch {
+	case repository.ErrNotFound(err):
+		return status.Error(codes.NotFound, err.Error())
+	case repository.ErrErased(err):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case repository.ErrForbidden(err):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case repository.ErrInvalidSignature(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case repository.ErrQuotaExceeded(err), repository.ErrDailyQuotaExceeded(err):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// principalQuery returns the query option for the principal of the call, so
+// that the access control list of the resource is checked.
+func principalQuery(ctx context.Context) repository.QueryOption {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	return repository.WithQueryPrincipal(principal.ID, principal.Groups)
+}
+
+// tenantQuery returns the query option for the tenant of the call, so that
+// the repository is scoped to the tenant.
+func tenantQuery(ctx context.Context) repository.QueryOption {
+	return repository.WithQueryTenant(tenant.FromContext(ctx))
+}
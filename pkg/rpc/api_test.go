@@ -0,0 +1,397 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/fsys"
+	"github.com/trussle/harness/matchers"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/ratelimit"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/rpc/pb"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newAPI(t *testing.T, ctrl *gomock.Controller, opts ...APIOption) (*grpc.ClientConn, func()) {
+	var (
+		clients  = metricMocks.NewMockGauge(ctrl)
+		bytes    = metricMocks.NewMockCounter(ctrl)
+		records  = metricMocks.NewMockCounter(ctrl)
+		duration = metricMocks.NewMockHistogramVec(ctrl)
+		observer = metricMocks.NewMockObserver(ctrl)
+		repo     = repository.NewRealRepository(
+			repository.NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+			store.NewVirtualStore(),
+			log.NewNopLogger(),
+		)
+	)
+
+	clients.EXPECT().Inc().AnyTimes()
+	clients.EXPECT().Dec().AnyTimes()
+	bytes.EXPECT().Add(gomock.Any()).AnyTimes()
+	records.EXPECT().Inc().AnyTimes()
+	duration.EXPECT().WithLabelValues(methodLabel, gomock.Any(), gomock.Any()).Return(observer).AnyTimes()
+	observer.EXPECT().Observe(matchers.MatchAnyFloat64()).AnyTimes()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewAPI(repo, log.NewNopLogger(), clients, bytes, records, duration, opts...)
+	go api.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, func() {
+		conn.Close()
+		api.Stop()
+	}
+}
+
+func ledgerInput(address string) *pb.LedgerInput {
+	return &pb.LedgerInput{
+		Name:                "name",
+		ResourceAddress:     address,
+		ResourceSize:        1,
+		ResourceContentType: "application/octet-stream",
+		AuthorId:            "author",
+		Tags:                []string{"a", "b"},
+	}
+}
+
+func TestLedgers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("insert append then select revisions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl)
+		defer stop()
+
+		var (
+			ctx    = context.Background()
+			client = pb.NewLedgersClient(conn)
+		)
+
+		inserted, err := client.Insert(ctx, &pb.InsertRequest{Ledger: ledgerInput("a")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "name", inserted.GetName(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		appended, err := client.Append(ctx, &pb.AppendRequest{
+			ResourceId: inserted.GetResourceId(),
+			Ledger:     ledgerInput("b"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := inserted.GetId(), appended.GetParentId(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		head, err := client.Select(ctx, &pb.SelectRequest{ResourceId: inserted.GetResourceId()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := inserted.GetResourceId(), head.GetResourceId(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		revisions, err := client.SelectRevisions(ctx, &pb.SelectRequest{ResourceId: inserted.GetResourceId()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(revisions.GetLedgers()); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("fork", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl)
+		defer stop()
+
+		var (
+			ctx    = context.Background()
+			client = pb.NewLedgersClient(conn)
+		)
+
+		inserted, err := client.Insert(ctx, &pb.InsertRequest{Ledger: ledgerInput("a")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		forked, err := client.Fork(ctx, &pb.AppendRequest{
+			ResourceId: inserted.GetResourceId(),
+			Ledger:     ledgerInput("b"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := inserted.GetResourceId(), forked.GetResourceId(); expected == actual {
+			t.Errorf("expected: a new resource, actual: %q", actual)
+		}
+		if expected, actual := inserted.GetId(), forked.GetParentId(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("select not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl)
+		defer stop()
+
+		_, err := pb.NewLedgersClient(conn).Select(context.Background(), &pb.SelectRequest{
+			ResourceId: uuid.MustNew().String(),
+		})
+		if expected, actual := codes.NotFound, status.Code(err); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+
+	t.Run("select with invalid resource id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl)
+		defer stop()
+
+		_, err := pb.NewLedgersClient(conn).Select(context.Background(), &pb.SelectRequest{
+			ResourceId: "bad",
+		})
+		if expected, actual := codes.InvalidArgument, status.Code(err); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+
+	t.Run("insert with invalid input", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl)
+		defer stop()
+
+		input := ledgerInput("a")
+		input.Name = ""
+		_, err := pb.NewLedgersClient(conn).Insert(context.Background(), &pb.InsertRequest{Ledger: input})
+		if expected, actual := codes.InvalidArgument, status.Code(err); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+}
+
+func TestContents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("put then select", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl)
+		defer stop()
+
+		var (
+			ctx     = context.Background()
+			ledgers = pb.NewLedgersClient(conn)
+			client  = pb.NewContentsClient(conn)
+			body    = bytes.Repeat([]byte("snowy"), 3*defaultChunkSize/5)
+		)
+
+		put, err := client.Put(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := put.Send(&pb.PutRequest{
+			Body: &pb.PutRequest_Header{Header: &pb.ContentHeader{ContentType: "text/plain"}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		for _, chunk := range [][]byte{body[:100], body[100:]} {
+			if err := put.Send(&pb.PutRequest{
+				Body: &pb.PutRequest_Chunk{Chunk: chunk},
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		content, err := put.CloseAndRecv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(len(body)), content.GetSize(); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		inserted, err := ledgers.Insert(ctx, &pb.InsertRequest{Ledger: ledgerInput(content.GetAddress())})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sel, err := client.Select(ctx, &pb.SelectRequest{ResourceId: inserted.GetResourceId()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			got      bytes.Buffer
+			messages int
+		)
+		for {
+			resp, err := sel.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if messages == 0 {
+				if expected, actual := content.GetAddress(), resp.GetContent().GetAddress(); expected != actual {
+					t.Errorf("expected: %q, actual: %q", expected, actual)
+				}
+			}
+			got.Write(resp.GetChunk())
+			messages++
+		}
+		if expected, actual := body, got.Bytes(); !bytes.Equal(expected, actual) {
+			t.Errorf("expected: %d bytes, actual: %d bytes", len(expected), len(actual))
+		}
+		if messages < 3 {
+			t.Errorf("expected the content in chunks, actual: %d messages", messages)
+		}
+	})
+
+	t.Run("put without header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl)
+		defer stop()
+
+		put, err := pb.NewContentsClient(conn).Put(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := put.Send(&pb.PutRequest{
+			Body: &pb.PutRequest_Chunk{Chunk: []byte("snowy")},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		_, err = put.CloseAndRecv()
+		if expected, actual := codes.InvalidArgument, status.Code(err); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl, WithMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "missing credentials", http.StatusUnauthorized)
+			})
+		}))
+		defer stop()
+
+		_, err := pb.NewLedgersClient(conn).Insert(context.Background(), &pb.InsertRequest{Ledger: ledgerInput("a")})
+		if expected, actual := codes.Unauthenticated, status.Code(err); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+		if expected, actual := "missing credentials", status.Convert(err).Message(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("tenant from metadata", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl, WithMiddleware(func(next http.Handler) http.Handler {
+			return tenant.NewMiddleware(next, log.NewNopLogger())
+		}))
+		defer stop()
+
+		var (
+			client = pb.NewLedgersClient(conn)
+			acme   = metadata.AppendToOutgoingContext(context.Background(), tenant.HTTPHeaderTenant, "acme")
+		)
+
+		inserted, err := client.Insert(acme, &pb.InsertRequest{Ledger: ledgerInput("a")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := client.Select(acme, &pb.SelectRequest{ResourceId: inserted.GetResourceId()}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = client.Select(context.Background(), &pb.SelectRequest{ResourceId: inserted.GetResourceId()})
+		if expected, actual := codes.NotFound, status.Code(err); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		conn, stop := newAPI(t, ctrl, WithMiddleware(func(next http.Handler) http.Handler {
+			return ratelimit.NewMiddleware(next, log.NewNopLogger(),
+				ratelimit.WithRoute("ledgers", MethodPrefixLedgers, ratelimit.Limit{Rate: 0.001, Burst: 1}),
+			)
+		}))
+		defer stop()
+
+		client := pb.NewLedgersClient(conn)
+		if _, err := client.Insert(context.Background(), &pb.InsertRequest{Ledger: ledgerInput("a")}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := client.Insert(context.Background(), &pb.InsertRequest{Ledger: ledgerInput("b")})
+		if expected, actual := codes.ResourceExhausted, status.Code(err); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+
+		// The contents service isn't limited by the ledgers route.
+		put, err := pb.NewContentsClient(conn).Put(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := put.Send(&pb.PutRequest{
+			Body: &pb.PutRequest_Chunk{Chunk: []byte("snowy")},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		_, err = put.CloseAndRecv()
+		if expected, actual := codes.InvalidArgument, status.Code(err); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+}
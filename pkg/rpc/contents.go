@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/rpc/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultKB = 1024
+	defaultMB = 1024 * defaultKB
+
+	// defaultMaxContentLength is the largest content that can be put, the
+	// same as the REST API.
+	defaultMaxContentLength = 10 * defaultMB
+
+	// defaultChunkSize is the size of the chunks that a content is downloaded
+	// in.
+	defaultChunkSize = 64 * defaultKB
+)
+
+// contentsServer serves the contents service.
+type contentsServer struct {
+	*API
+}
+
+func (s contentsServer) Put(stream pb.Contents_PutServer) error {
+	ctx := stream.Context()
+
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	header := req.GetHeader()
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "expected header as the first message")
+	}
+
+	var buf bytes.Buffer
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if req.GetHeader() != nil {
+			return status.Error(codes.InvalidArgument, "unexpected header after the first message")
+		}
+		if buf.Len()+len(req.GetChunk()) > defaultMaxContentLength {
+			return status.Errorf(codes.ResourceExhausted, "content is larger than %d bytes", defaultMaxContentLength)
+		}
+		buf.Write(req.GetChunk())
+	}
+
+	body := buf.Bytes()
+	content, err := models.BuildContent(
+		models.WithContentBytes(body),
+		models.WithSize(int64(len(body))),
+		models.WithContentType(header.GetContentType()),
+	)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	options, err := repository.BuildQuery(tenantQuery(ctx))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := s.repository.PutContent(content, options)
+	if err != nil {
+		return repositoryError(err)
+	}
+
+	s.bytes.Add(float64(len(body)))
+	s.records.Inc()
+
+	return stream.SendAndClose(contentToProto(res))
+}
+
+func (s contentsServer) Select(req *pb.SelectRequest, stream pb.Contents_SelectServer) error {
+	ctx := stream.Context()
+
+	resourceID, err := parseResourceID(req.GetResourceId())
+	if err != nil {
+		return err
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTags(req.GetTags()),
+		repository.WithQueryAuthorID(req.GetAuthorId()),
+		principalQuery(ctx),
+		tenantQuery(ctx),
+	)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	content, err := s.repository.SelectContent(resourceID, options)
+	if err != nil {
+		return repositoryError(err)
+	}
+
+	reader := content.Reader()
+	if reader != nil {
+		defer reader.Close()
+	}
+
+	if err := stream.Send(&pb.SelectResponse{
+		Body: &pb.SelectResponse_Content{Content: contentToProto(content)},
+	}); err != nil {
+		return err
+	}
+	if reader == nil {
+		return nil
+	}
+
+	chunk := make([]byte, defaultChunkSize)
+	for {
+		n, err := reader.Read(chunk)
+		if n > 0 {
+			if err := stream.Send(&pb.SelectResponse{
+				Body: &pb.SelectResponse_Chunk{Chunk: chunk[:n]},
+			}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+}
+
+func contentToProto(content models.Content) *pb.Content {
+	return &pb.Content{
+		Address:     content.Address(),
+		Size:        content.Size(),
+		ContentType: content.ContentType(),
+	}
+}
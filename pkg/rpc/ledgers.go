@@ -0,0 +1,207 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/rpc/pb"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ledgersServer serves the ledgers service.
+type ledgersServer struct {
+	*API
+}
+
+func (s ledgersServer) Select(ctx context.Context, req *pb.SelectRequest) (*pb.Ledger, error) {
+	resourceID, err := parseResourceID(req.GetResourceId())
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTags(req.GetTags()),
+		repository.WithQueryAuthorID(req.GetAuthorId()),
+		principalQuery(ctx),
+		tenantQuery(ctx),
+	)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	doc, err := s.repository.SelectLedger(resourceID, options)
+	if err != nil {
+		return nil, repositoryError(err)
+	}
+	return ledgerToProto(doc), nil
+}
+
+func (s ledgersServer) Insert(ctx context.Context, req *pb.InsertRequest) (*pb.Ledger, error) {
+	doc, err := ingestLedger(ctx, req.GetLedger(), models.WithNewResourceID())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.repository.InsertLedger(doc)
+	if err != nil {
+		return nil, repositoryError(err)
+	}
+	return ledgerToProto(res), nil
+}
+
+func (s ledgersServer) Append(ctx context.Context, req *pb.AppendRequest) (*pb.Ledger, error) {
+	resourceID, err := parseResourceID(req.GetResourceId())
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := ingestLedger(ctx, req.GetLedger(), models.WithResourceID(resourceID))
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := repository.BuildQuery(principalQuery(ctx), tenantQuery(ctx))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := s.repository.AppendLedger(resourceID, doc, options)
+	if err != nil {
+		return nil, repositoryError(err)
+	}
+	return ledgerToProto(res), nil
+}
+
+func (s ledgersServer) Fork(ctx context.Context, req *pb.AppendRequest) (*pb.Ledger, error) {
+	resourceID, err := parseResourceID(req.GetResourceId())
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := ingestLedger(ctx, req.GetLedger(), models.WithNewResourceID())
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := repository.BuildQuery(principalQuery(ctx), tenantQuery(ctx))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := s.repository.ForkLedger(resourceID, doc, options)
+	if err != nil {
+		return nil, repositoryError(err)
+	}
+	return ledgerToProto(res), nil
+}
+
+func (s ledgersServer) SelectRevisions(ctx context.Context, req *pb.SelectRequest) (*pb.LedgerList, error) {
+	resourceID, err := parseResourceID(req.GetResourceId())
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTags(req.GetTags()),
+		repository.WithQueryAuthorID(req.GetAuthorId()),
+		principalQuery(ctx),
+		tenantQuery(ctx),
+	)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ledgers, err := s.repository.SelectLedgers(resourceID, options)
+	if err != nil {
+		return nil, repositoryError(err)
+	}
+
+	res := &pb.LedgerList{
+		Ledgers: make([]*pb.Ledger, len(ledgers)),
+	}
+	for k, v := range ledgers {
+		res.Ledgers[k] = ledgerToProto(v)
+	}
+	return res, nil
+}
+
+// ingestLedger validates the input, the same as the REST API, and builds the
+// ledger for the tenant of the call.
+func ingestLedger(ctx context.Context, in *pb.LedgerInput, opt models.DocOption) (models.Ledger, error) {
+	if in == nil {
+		return models.Ledger{}, status.Error(codes.InvalidArgument, "no ledger")
+	}
+
+	input := models.LedgerInput{
+		Name:                in.GetName(),
+		ResourceAddress:     in.GetResourceAddress(),
+		ResourceSize:        in.GetResourceSize(),
+		ResourceContentType: in.GetResourceContentType(),
+		AuthorID:            in.GetAuthorId(),
+		Tags:                in.GetTags(),
+		CreatedOn:           in.GetCreatedOn(),
+		Signature:           in.GetSignature(),
+		SignatureKeyID:      in.GetSignatureKeyId(),
+	}
+	if err := models.ValidateLedgerInput(input); err != nil {
+		return models.Ledger{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := auth.AuthorizeAuthor(ctx, input.AuthorID); err != nil {
+		return models.Ledger{}, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	doc, err := models.BuildLedger(
+		opt,
+		models.WithTenantID(tenant.FromContext(ctx)),
+		models.WithName(input.Name),
+		models.WithResourceAddress(input.ResourceAddress),
+		models.WithResourceSize(input.ResourceSize),
+		models.WithResourceContentType(input.ResourceContentType),
+		models.WithAuthorID(input.AuthorID),
+		models.WithTags(input.Tags),
+		models.WithCreatedOn(input.CreatedOnTime(time.Now())),
+		models.WithSignature(input.SignatureKeyID, input.Signature),
+	)
+	if err != nil {
+		return models.Ledger{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return doc, nil
+}
+
+func parseResourceID(s string) (uuid.UUID, error) {
+	resourceID, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Empty, status.Errorf(codes.InvalidArgument, "invalid resource_id %q", s)
+	}
+	return resourceID, nil
+}
+
+func ledgerToProto(doc models.Ledger) *pb.Ledger {
+	var deletedOn string
+	if !doc.DeletedOn().IsZero() {
+		deletedOn = doc.DeletedOn().Format(time.RFC3339)
+	}
+
+	return &pb.Ledger{
+		Id:                  doc.ID().String(),
+		ParentId:            doc.ParentID().String(),
+		Name:                doc.Name(),
+		ResourceId:          doc.ResourceID().String(),
+		ResourceAddress:     doc.ResourceAddress(),
+		ResourceSize:        doc.ResourceSize(),
+		ResourceContentType: doc.ResourceContentType(),
+		AuthorId:            doc.AuthorID(),
+		Tags:                doc.Tags(),
+		CreatedOn:           doc.CreatedOn().Format(time.RFC3339),
+		DeletedOn:           deletedOn,
+		Signature:           doc.Signature(),
+		SignatureKeyId:      doc.SignatureKeyID(),
+	}
+}
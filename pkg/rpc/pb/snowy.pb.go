@@ -0,0 +1,1155 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/rpc/pb/snowy.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Ledger struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ParentId             string   `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Name                 string   `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	ResourceId           string   `protobuf:"bytes,4,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	ResourceAddress      string   `protobuf:"bytes,5,opt,name=resource_address,json=resourceAddress,proto3" json:"resource_address,omitempty"`
+	ResourceSize         int64    `protobuf:"varint,6,opt,name=resource_size,json=resourceSize,proto3" json:"resource_size,omitempty"`
+	ResourceContentType  string   `protobuf:"bytes,7,opt,name=resource_content_type,json=resourceContentType,proto3" json:"resource_content_type,omitempty"`
+	AuthorId             string   `protobuf:"bytes,8,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Tags                 []string `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	CreatedOn            string   `protobuf:"bytes,10,opt,name=created_on,json=createdOn,proto3" json:"created_on,omitempty"`
+	DeletedOn            string   `protobuf:"bytes,11,opt,name=deleted_on,json=deletedOn,proto3" json:"deleted_on,omitempty"`
+	Signature            []byte   `protobuf:"bytes,12,opt,name=signature,proto3" json:"signature,omitempty"`
+	SignatureKeyId       string   `protobuf:"bytes,13,opt,name=signature_key_id,json=signatureKeyId,proto3" json:"signature_key_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Ledger) Reset()         { *m = Ledger{} }
+func (m *Ledger) String() string { return proto.CompactTextString(m) }
+func (*Ledger) ProtoMessage()    {}
+func (*Ledger) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{0}
+}
+
+func (m *Ledger) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Ledger.Unmarshal(m, b)
+}
+func (m *Ledger) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Ledger.Marshal(b, m, deterministic)
+}
+func (m *Ledger) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Ledger.Merge(m, src)
+}
+func (m *Ledger) XXX_Size() int {
+	return xxx_messageInfo_Ledger.Size(m)
+}
+func (m *Ledger) XXX_DiscardUnknown() {
+	xxx_messageInfo_Ledger.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Ledger proto.InternalMessageInfo
+
+func (m *Ledger) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Ledger) GetParentId() string {
+	if m != nil {
+		return m.ParentId
+	}
+	return ""
+}
+
+func (m *Ledger) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Ledger) GetResourceId() string {
+	if m != nil {
+		return m.ResourceId
+	}
+	return ""
+}
+
+func (m *Ledger) GetResourceAddress() string {
+	if m != nil {
+		return m.ResourceAddress
+	}
+	return ""
+}
+
+func (m *Ledger) GetResourceSize() int64 {
+	if m != nil {
+		return m.ResourceSize
+	}
+	return 0
+}
+
+func (m *Ledger) GetResourceContentType() string {
+	if m != nil {
+		return m.ResourceContentType
+	}
+	return ""
+}
+
+func (m *Ledger) GetAuthorId() string {
+	if m != nil {
+		return m.AuthorId
+	}
+	return ""
+}
+
+func (m *Ledger) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Ledger) GetCreatedOn() string {
+	if m != nil {
+		return m.CreatedOn
+	}
+	return ""
+}
+
+func (m *Ledger) GetDeletedOn() string {
+	if m != nil {
+		return m.DeletedOn
+	}
+	return ""
+}
+
+func (m *Ledger) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *Ledger) GetSignatureKeyId() string {
+	if m != nil {
+		return m.SignatureKeyId
+	}
+	return ""
+}
+
+type LedgerList struct {
+	Ledgers              []*Ledger `protobuf:"bytes,1,rep,name=ledgers,proto3" json:"ledgers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *LedgerList) Reset()         { *m = LedgerList{} }
+func (m *LedgerList) String() string { return proto.CompactTextString(m) }
+func (*LedgerList) ProtoMessage()    {}
+func (*LedgerList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{1}
+}
+
+func (m *LedgerList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LedgerList.Unmarshal(m, b)
+}
+func (m *LedgerList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LedgerList.Marshal(b, m, deterministic)
+}
+func (m *LedgerList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LedgerList.Merge(m, src)
+}
+func (m *LedgerList) XXX_Size() int {
+	return xxx_messageInfo_LedgerList.Size(m)
+}
+func (m *LedgerList) XXX_DiscardUnknown() {
+	xxx_messageInfo_LedgerList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LedgerList proto.InternalMessageInfo
+
+func (m *LedgerList) GetLedgers() []*Ledger {
+	if m != nil {
+		return m.Ledgers
+	}
+	return nil
+}
+
+type LedgerInput struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ResourceAddress      string   `protobuf:"bytes,2,opt,name=resource_address,json=resourceAddress,proto3" json:"resource_address,omitempty"`
+	ResourceSize         int64    `protobuf:"varint,3,opt,name=resource_size,json=resourceSize,proto3" json:"resource_size,omitempty"`
+	ResourceContentType  string   `protobuf:"bytes,4,opt,name=resource_content_type,json=resourceContentType,proto3" json:"resource_content_type,omitempty"`
+	AuthorId             string   `protobuf:"bytes,5,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Tags                 []string `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	CreatedOn            string   `protobuf:"bytes,7,opt,name=created_on,json=createdOn,proto3" json:"created_on,omitempty"`
+	Signature            []byte   `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	SignatureKeyId       string   `protobuf:"bytes,9,opt,name=signature_key_id,json=signatureKeyId,proto3" json:"signature_key_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LedgerInput) Reset()         { *m = LedgerInput{} }
+func (m *LedgerInput) String() string { return proto.CompactTextString(m) }
+func (*LedgerInput) ProtoMessage()    {}
+func (*LedgerInput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{2}
+}
+
+func (m *LedgerInput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LedgerInput.Unmarshal(m, b)
+}
+func (m *LedgerInput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LedgerInput.Marshal(b, m, deterministic)
+}
+func (m *LedgerInput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LedgerInput.Merge(m, src)
+}
+func (m *LedgerInput) XXX_Size() int {
+	return xxx_messageInfo_LedgerInput.Size(m)
+}
+func (m *LedgerInput) XXX_DiscardUnknown() {
+	xxx_messageInfo_LedgerInput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LedgerInput proto.InternalMessageInfo
+
+func (m *LedgerInput) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *LedgerInput) GetResourceAddress() string {
+	if m != nil {
+		return m.ResourceAddress
+	}
+	return ""
+}
+
+func (m *LedgerInput) GetResourceSize() int64 {
+	if m != nil {
+		return m.ResourceSize
+	}
+	return 0
+}
+
+func (m *LedgerInput) GetResourceContentType() string {
+	if m != nil {
+		return m.ResourceContentType
+	}
+	return ""
+}
+
+func (m *LedgerInput) GetAuthorId() string {
+	if m != nil {
+		return m.AuthorId
+	}
+	return ""
+}
+
+func (m *LedgerInput) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *LedgerInput) GetCreatedOn() string {
+	if m != nil {
+		return m.CreatedOn
+	}
+	return ""
+}
+
+func (m *LedgerInput) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *LedgerInput) GetSignatureKeyId() string {
+	if m != nil {
+		return m.SignatureKeyId
+	}
+	return ""
+}
+
+type SelectRequest struct {
+	ResourceId           string   `protobuf:"bytes,1,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	Tags                 []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	AuthorId             string   `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SelectRequest) Reset()         { *m = SelectRequest{} }
+func (m *SelectRequest) String() string { return proto.CompactTextString(m) }
+func (*SelectRequest) ProtoMessage()    {}
+func (*SelectRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{3}
+}
+
+func (m *SelectRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SelectRequest.Unmarshal(m, b)
+}
+func (m *SelectRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SelectRequest.Marshal(b, m, deterministic)
+}
+func (m *SelectRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SelectRequest.Merge(m, src)
+}
+func (m *SelectRequest) XXX_Size() int {
+	return xxx_messageInfo_SelectRequest.Size(m)
+}
+func (m *SelectRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SelectRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SelectRequest proto.InternalMessageInfo
+
+func (m *SelectRequest) GetResourceId() string {
+	if m != nil {
+		return m.ResourceId
+	}
+	return ""
+}
+
+func (m *SelectRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *SelectRequest) GetAuthorId() string {
+	if m != nil {
+		return m.AuthorId
+	}
+	return ""
+}
+
+type InsertRequest struct {
+	Ledger               *LedgerInput `protobuf:"bytes,1,opt,name=ledger,proto3" json:"ledger,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *InsertRequest) Reset()         { *m = InsertRequest{} }
+func (m *InsertRequest) String() string { return proto.CompactTextString(m) }
+func (*InsertRequest) ProtoMessage()    {}
+func (*InsertRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{4}
+}
+
+func (m *InsertRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InsertRequest.Unmarshal(m, b)
+}
+func (m *InsertRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InsertRequest.Marshal(b, m, deterministic)
+}
+func (m *InsertRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InsertRequest.Merge(m, src)
+}
+func (m *InsertRequest) XXX_Size() int {
+	return xxx_messageInfo_InsertRequest.Size(m)
+}
+func (m *InsertRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_InsertRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InsertRequest proto.InternalMessageInfo
+
+func (m *InsertRequest) GetLedger() *LedgerInput {
+	if m != nil {
+		return m.Ledger
+	}
+	return nil
+}
+
+type AppendRequest struct {
+	ResourceId           string       `protobuf:"bytes,1,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	Ledger               *LedgerInput `protobuf:"bytes,2,opt,name=ledger,proto3" json:"ledger,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *AppendRequest) Reset()         { *m = AppendRequest{} }
+func (m *AppendRequest) String() string { return proto.CompactTextString(m) }
+func (*AppendRequest) ProtoMessage()    {}
+func (*AppendRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{5}
+}
+
+func (m *AppendRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AppendRequest.Unmarshal(m, b)
+}
+func (m *AppendRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AppendRequest.Marshal(b, m, deterministic)
+}
+func (m *AppendRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AppendRequest.Merge(m, src)
+}
+func (m *AppendRequest) XXX_Size() int {
+	return xxx_messageInfo_AppendRequest.Size(m)
+}
+func (m *AppendRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AppendRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AppendRequest proto.InternalMessageInfo
+
+func (m *AppendRequest) GetResourceId() string {
+	if m != nil {
+		return m.ResourceId
+	}
+	return ""
+}
+
+func (m *AppendRequest) GetLedger() *LedgerInput {
+	if m != nil {
+		return m.Ledger
+	}
+	return nil
+}
+
+type Content struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Size                 int64    `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	ContentType          string   `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Content) Reset()         { *m = Content{} }
+func (m *Content) String() string { return proto.CompactTextString(m) }
+func (*Content) ProtoMessage()    {}
+func (*Content) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{6}
+}
+
+func (m *Content) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Content.Unmarshal(m, b)
+}
+func (m *Content) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Content.Marshal(b, m, deterministic)
+}
+func (m *Content) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Content.Merge(m, src)
+}
+func (m *Content) XXX_Size() int {
+	return xxx_messageInfo_Content.Size(m)
+}
+func (m *Content) XXX_DiscardUnknown() {
+	xxx_messageInfo_Content.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Content proto.InternalMessageInfo
+
+func (m *Content) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Content) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Content) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+type ContentHeader struct {
+	ContentType          string   `protobuf:"bytes,1,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContentHeader) Reset()         { *m = ContentHeader{} }
+func (m *ContentHeader) String() string { return proto.CompactTextString(m) }
+func (*ContentHeader) ProtoMessage()    {}
+func (*ContentHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{7}
+}
+
+func (m *ContentHeader) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContentHeader.Unmarshal(m, b)
+}
+func (m *ContentHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContentHeader.Marshal(b, m, deterministic)
+}
+func (m *ContentHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContentHeader.Merge(m, src)
+}
+func (m *ContentHeader) XXX_Size() int {
+	return xxx_messageInfo_ContentHeader.Size(m)
+}
+func (m *ContentHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContentHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContentHeader proto.InternalMessageInfo
+
+func (m *ContentHeader) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+type PutRequest struct {
+	// Types that are valid to be assigned to Body:
+	//	*PutRequest_Header
+	//	*PutRequest_Chunk
+	Body                 isPutRequest_Body `protobuf_oneof:"body"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PutRequest) Reset()         { *m = PutRequest{} }
+func (m *PutRequest) String() string { return proto.CompactTextString(m) }
+func (*PutRequest) ProtoMessage()    {}
+func (*PutRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{8}
+}
+
+func (m *PutRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PutRequest.Unmarshal(m, b)
+}
+func (m *PutRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PutRequest.Marshal(b, m, deterministic)
+}
+func (m *PutRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PutRequest.Merge(m, src)
+}
+func (m *PutRequest) XXX_Size() int {
+	return xxx_messageInfo_PutRequest.Size(m)
+}
+func (m *PutRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PutRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PutRequest proto.InternalMessageInfo
+
+type isPutRequest_Body interface {
+	isPutRequest_Body()
+}
+
+type PutRequest_Header struct {
+	Header *ContentHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type PutRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*PutRequest_Header) isPutRequest_Body() {}
+
+func (*PutRequest_Chunk) isPutRequest_Body() {}
+
+func (m *PutRequest) GetBody() isPutRequest_Body {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+func (m *PutRequest) GetHeader() *ContentHeader {
+	if x, ok := m.GetBody().(*PutRequest_Header); ok {
+		return x.Header
+	}
+	return nil
+}
+
+func (m *PutRequest) GetChunk() []byte {
+	if x, ok := m.GetBody().(*PutRequest_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*PutRequest) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*PutRequest_Header)(nil),
+		(*PutRequest_Chunk)(nil),
+	}
+}
+
+type SelectResponse struct {
+	// Types that are valid to be assigned to Body:
+	//	*SelectResponse_Content
+	//	*SelectResponse_Chunk
+	Body                 isSelectResponse_Body `protobuf_oneof:"body"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *SelectResponse) Reset()         { *m = SelectResponse{} }
+func (m *SelectResponse) String() string { return proto.CompactTextString(m) }
+func (*SelectResponse) ProtoMessage()    {}
+func (*SelectResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_114ac0c856d022e7, []int{9}
+}
+
+func (m *SelectResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SelectResponse.Unmarshal(m, b)
+}
+func (m *SelectResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SelectResponse.Marshal(b, m, deterministic)
+}
+func (m *SelectResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SelectResponse.Merge(m, src)
+}
+func (m *SelectResponse) XXX_Size() int {
+	return xxx_messageInfo_SelectResponse.Size(m)
+}
+func (m *SelectResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SelectResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SelectResponse proto.InternalMessageInfo
+
+type isSelectResponse_Body interface {
+	isSelectResponse_Body()
+}
+
+type SelectResponse_Content struct {
+	Content *Content `protobuf:"bytes,1,opt,name=content,proto3,oneof"`
+}
+
+type SelectResponse_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*SelectResponse_Content) isSelectResponse_Body() {}
+
+func (*SelectResponse_Chunk) isSelectResponse_Body() {}
+
+func (m *SelectResponse) GetBody() isSelectResponse_Body {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+func (m *SelectResponse) GetContent() *Content {
+	if x, ok := m.GetBody().(*SelectResponse_Content); ok {
+		return x.Content
+	}
+	return nil
+}
+
+func (m *SelectResponse) GetChunk() []byte {
+	if x, ok := m.GetBody().(*SelectResponse_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*SelectResponse) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*SelectResponse_Content)(nil),
+		(*SelectResponse_Chunk)(nil),
+	}
+}
+
+func init() {
+	proto.RegisterType((*Ledger)(nil), "snowy.Ledger")
+	proto.RegisterType((*LedgerList)(nil), "snowy.LedgerList")
+	proto.RegisterType((*LedgerInput)(nil), "snowy.LedgerInput")
+	proto.RegisterType((*SelectRequest)(nil), "snowy.SelectRequest")
+	proto.RegisterType((*InsertRequest)(nil), "snowy.InsertRequest")
+	proto.RegisterType((*AppendRequest)(nil), "snowy.AppendRequest")
+	proto.RegisterType((*Content)(nil), "snowy.Content")
+	proto.RegisterType((*ContentHeader)(nil), "snowy.ContentHeader")
+	proto.RegisterType((*PutRequest)(nil), "snowy.PutRequest")
+	proto.RegisterType((*SelectResponse)(nil), "snowy.SelectResponse")
+}
+
+func init() {
+	proto.RegisterFile("pkg/rpc/pb/snowy.proto", fileDescriptor_114ac0c856d022e7)
+}
+
+var fileDescriptor_114ac0c856d022e7 = []byte{
+	// 701 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xdd, 0x6e, 0xd3, 0x4c,
+	0x10, 0xad, 0xed, 0xc4, 0x49, 0x26, 0x3f, 0xed, 0xb7, 0x1f, 0xad, 0xac, 0x02, 0x22, 0x04, 0x09,
+	0x4c, 0x11, 0x09, 0x0a, 0x42, 0x48, 0xf4, 0xaa, 0x45, 0x42, 0x8d, 0xa8, 0xd4, 0xca, 0xe5, 0xaa,
+	0xaa, 0x54, 0x39, 0xde, 0x51, 0x62, 0x25, 0x5d, 0x9b, 0xdd, 0x35, 0x28, 0x7d, 0x1f, 0x1e, 0x83,
+	0x67, 0x03, 0x79, 0xd7, 0x76, 0xea, 0xb4, 0x55, 0xc3, 0x9d, 0xf7, 0xcc, 0x99, 0xd9, 0x99, 0x39,
+	0x27, 0x59, 0xd8, 0x89, 0x67, 0x93, 0x01, 0x8f, 0x83, 0x41, 0x3c, 0x1e, 0x08, 0x16, 0xfd, 0x5c,
+	0xf4, 0x63, 0x1e, 0xc9, 0x88, 0x54, 0xd5, 0xa1, 0xf7, 0xcb, 0x02, 0xfb, 0x18, 0xe9, 0x04, 0x39,
+	0xe9, 0x80, 0x19, 0x52, 0xc7, 0xe8, 0x1a, 0x6e, 0xc3, 0x33, 0x43, 0x4a, 0x1e, 0x43, 0x23, 0xf6,
+	0x39, 0x32, 0x79, 0x19, 0x52, 0xc7, 0x54, 0x70, 0x5d, 0x03, 0x23, 0x4a, 0x08, 0x54, 0x98, 0x7f,
+	0x85, 0x8e, 0xa5, 0x70, 0xf5, 0x4d, 0x9e, 0x41, 0x93, 0xa3, 0x88, 0x12, 0x1e, 0x60, 0x9a, 0x52,
+	0x51, 0x21, 0xc8, 0xa1, 0x11, 0x25, 0xaf, 0x61, 0xab, 0x20, 0xf8, 0x94, 0x72, 0x14, 0xc2, 0xa9,
+	0x2a, 0xd6, 0x66, 0x8e, 0x1f, 0x68, 0x98, 0xbc, 0x80, 0x76, 0x41, 0x15, 0xe1, 0x35, 0x3a, 0x76,
+	0xd7, 0x70, 0x2d, 0xaf, 0x95, 0x83, 0x67, 0xe1, 0x35, 0x92, 0x21, 0x6c, 0x17, 0xa4, 0x20, 0x62,
+	0x32, 0xed, 0x55, 0x2e, 0x62, 0x74, 0x6a, 0xaa, 0xe8, 0xff, 0x79, 0xf0, 0xb3, 0x8e, 0x7d, 0x5b,
+	0xc4, 0x98, 0x4e, 0xe5, 0x27, 0x72, 0x1a, 0xf1, 0xb4, 0xc5, 0xba, 0x9e, 0x4a, 0x03, 0x7a, 0x2a,
+	0xe9, 0x4f, 0x84, 0xd3, 0xe8, 0x5a, 0xe9, 0x54, 0xe9, 0x37, 0x79, 0x0a, 0x10, 0x70, 0xf4, 0x25,
+	0xd2, 0xcb, 0x88, 0x39, 0xa0, 0x32, 0x1a, 0x19, 0x72, 0xc2, 0xd2, 0x30, 0xc5, 0x39, 0x66, 0xe1,
+	0xa6, 0x0e, 0x67, 0xc8, 0x09, 0x23, 0x4f, 0xa0, 0x21, 0xc2, 0x09, 0xf3, 0x65, 0xc2, 0xd1, 0x69,
+	0x75, 0x0d, 0xb7, 0xe5, 0x2d, 0x01, 0xe2, 0xc2, 0x56, 0x71, 0xb8, 0x9c, 0xe1, 0x22, 0xed, 0xa9,
+	0xad, 0x4a, 0x74, 0x0a, 0xfc, 0x2b, 0x2e, 0x46, 0xb4, 0xf7, 0x01, 0x40, 0xcb, 0x74, 0x1c, 0x0a,
+	0x49, 0x5e, 0x41, 0x6d, 0xae, 0x4e, 0xc2, 0x31, 0xba, 0x96, 0xdb, 0x1c, 0xb6, 0xfb, 0x5a, 0x5b,
+	0xcd, 0xf1, 0xf2, 0x68, 0xef, 0xb7, 0x09, 0x4d, 0x8d, 0x8d, 0x58, 0x9c, 0xc8, 0x42, 0x36, 0xe3,
+	0x86, 0x6c, 0x77, 0xa9, 0x62, 0xae, 0xa9, 0x8a, 0xf5, 0x2f, 0xaa, 0x54, 0xd6, 0x54, 0xa5, 0x7a,
+	0x8f, 0x2a, 0xf6, 0xbd, 0xaa, 0xd4, 0x56, 0x55, 0x29, 0xad, 0xbd, 0xbe, 0xce, 0xda, 0x1b, 0x77,
+	0xae, 0xdd, 0x87, 0xf6, 0x19, 0xce, 0x31, 0x90, 0x1e, 0x7e, 0x4f, 0x50, 0xc8, 0x55, 0x8f, 0x1b,
+	0xb7, 0x3c, 0x9e, 0x37, 0x6b, 0xde, 0x68, 0xb6, 0x34, 0x9d, 0x55, 0x9e, 0xae, 0xb7, 0x0f, 0xed,
+	0x11, 0x13, 0xc8, 0x8b, 0x2b, 0xf6, 0xc0, 0xd6, 0xf2, 0xa9, 0xea, 0xcd, 0x21, 0x29, 0x69, 0xab,
+	0x74, 0xf4, 0x32, 0x46, 0xef, 0x02, 0xda, 0x07, 0x71, 0x8c, 0x8c, 0xae, 0xdd, 0xdf, 0xb2, 0xba,
+	0xf9, 0x60, 0xf5, 0x73, 0xa8, 0x65, 0x22, 0x11, 0x07, 0x6a, 0xb9, 0x37, 0x74, 0xcd, 0xfc, 0x98,
+	0x0e, 0xac, 0xac, 0x60, 0x2a, 0x2b, 0xa8, 0x6f, 0xf2, 0x1c, 0x5a, 0x25, 0xe5, 0xf5, 0xcc, 0xcd,
+	0x60, 0xa9, 0x78, 0x6f, 0x08, 0xed, 0xac, 0xf6, 0x11, 0xfa, 0x14, 0xf9, 0xad, 0x1c, 0xe3, 0x76,
+	0xce, 0x05, 0xc0, 0x69, 0x52, 0xec, 0xa9, 0x0f, 0xf6, 0x54, 0xa5, 0x66, 0x7b, 0x7a, 0x94, 0x4d,
+	0x52, 0x2a, 0x7b, 0xb4, 0xe1, 0x65, 0x2c, 0xb2, 0x03, 0xd5, 0x60, 0x9a, 0xb0, 0x99, 0xea, 0xb4,
+	0x75, 0xb4, 0xe1, 0xe9, 0xe3, 0xa1, 0x0d, 0x95, 0x71, 0x44, 0x17, 0xbd, 0x0b, 0xe8, 0xe4, 0x5a,
+	0x8b, 0x38, 0x62, 0x02, 0xc9, 0x1e, 0xd4, 0xb2, 0xeb, 0xb3, 0x2b, 0x3a, 0x2b, 0x57, 0x6c, 0x78,
+	0x39, 0xe1, 0xa1, 0xea, 0xc3, 0x3f, 0x06, 0xd4, 0xf4, 0x8e, 0x05, 0x79, 0x0b, 0xb6, 0xbe, 0x89,
+	0xe4, 0x3d, 0x97, 0x4c, 0xb6, 0x5b, 0xfe, 0x35, 0xa7, 0x74, 0xed, 0x90, 0x82, 0x5e, 0x32, 0xcc,
+	0x1d, 0x74, 0xed, 0x89, 0x82, 0x5e, 0xb2, 0xc8, 0x2a, 0xfd, 0x0d, 0x54, 0xbe, 0x44, 0x7c, 0xb6,
+	0x1e, 0xf9, 0x13, 0x6c, 0xe6, 0xad, 0xfe, 0x08, 0x45, 0x18, 0x31, 0x71, 0xcf, 0x08, 0xff, 0x95,
+	0xf2, 0xd2, 0x3f, 0xad, 0x61, 0x04, 0xf5, 0x6c, 0x6f, 0x82, 0xec, 0x81, 0x75, 0x9a, 0x48, 0x92,
+	0xb3, 0x96, 0xaa, 0xee, 0xae, 0xac, 0xd8, 0x35, 0xc8, 0xc7, 0x07, 0xb6, 0xb5, 0xbd, 0x82, 0x6a,
+	0xf1, 0xde, 0x19, 0x87, 0xee, 0xf9, 0xcb, 0x49, 0x28, 0xa7, 0xc9, 0xb8, 0x1f, 0x44, 0x57, 0x03,
+	0xc9, 0x13, 0x21, 0xe6, 0xa8, 0x1f, 0xc1, 0xc1, 0xf2, 0x55, 0xdc, 0x8f, 0xc7, 0x63, 0x5b, 0xbd,
+	0x89, 0xef, 0xff, 0x0e, 0x00, 0xde, 0xaf, 0x54, 0xed, 0x2d, 0x07, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// LedgersClient is the client API for Ledgers service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LedgersClient interface {
+	Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*Ledger, error)
+	Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*Ledger, error)
+	Append(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*Ledger, error)
+	Fork(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*Ledger, error)
+	SelectRevisions(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*LedgerList, error)
+}
+
+type ledgersClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLedgersClient(cc grpc.ClientConnInterface) LedgersClient {
+	return &ledgersClient{cc}
+}
+
+func (c *ledgersClient) Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*Ledger, error) {
+	out := new(Ledger)
+	err := c.cc.Invoke(ctx, "/snowy.Ledgers/Select", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgersClient) Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*Ledger, error) {
+	out := new(Ledger)
+	err := c.cc.Invoke(ctx, "/snowy.Ledgers/Insert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgersClient) Append(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*Ledger, error) {
+	out := new(Ledger)
+	err := c.cc.Invoke(ctx, "/snowy.Ledgers/Append", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgersClient) Fork(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*Ledger, error) {
+	out := new(Ledger)
+	err := c.cc.Invoke(ctx, "/snowy.Ledgers/Fork", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgersClient) SelectRevisions(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (*LedgerList, error) {
+	out := new(LedgerList)
+	err := c.cc.Invoke(ctx, "/snowy.Ledgers/SelectRevisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LedgersServer is the server API for Ledgers service.
+type LedgersServer interface {
+	Select(context.Context, *SelectRequest) (*Ledger, error)
+	Insert(context.Context, *InsertRequest) (*Ledger, error)
+	Append(context.Context, *AppendRequest) (*Ledger, error)
+	Fork(context.Context, *AppendRequest) (*Ledger, error)
+	SelectRevisions(context.Context, *SelectRequest) (*LedgerList, error)
+}
+
+// UnimplementedLedgersServer can be embedded to have forward compatible implementations.
+type UnimplementedLedgersServer struct {
+}
+
+func (*UnimplementedLedgersServer) Select(ctx context.Context, req *SelectRequest) (*Ledger, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Select not implemented")
+}
+func (*UnimplementedLedgersServer) Insert(ctx context.Context, req *InsertRequest) (*Ledger, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Insert not implemented")
+}
+func (*UnimplementedLedgersServer) Append(ctx context.Context, req *AppendRequest) (*Ledger, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Append not implemented")
+}
+func (*UnimplementedLedgersServer) Fork(ctx context.Context, req *AppendRequest) (*Ledger, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fork not implemented")
+}
+func (*UnimplementedLedgersServer) SelectRevisions(ctx context.Context, req *SelectRequest) (*LedgerList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SelectRevisions not implemented")
+}
+
+func RegisterLedgersServer(s *grpc.Server, srv LedgersServer) {
+	s.RegisterService(&_Ledgers_serviceDesc, srv)
+}
+
+func _Ledgers_Select_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgersServer).Select(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/snowy.Ledgers/Select",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgersServer).Select(ctx, req.(*SelectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledgers_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgersServer).Insert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/snowy.Ledgers/Insert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgersServer).Insert(ctx, req.(*InsertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledgers_Append_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgersServer).Append(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/snowy.Ledgers/Append",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgersServer).Append(ctx, req.(*AppendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledgers_Fork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgersServer).Fork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/snowy.Ledgers/Fork",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgersServer).Fork(ctx, req.(*AppendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledgers_SelectRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgersServer).SelectRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/snowy.Ledgers/SelectRevisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgersServer).SelectRevisions(ctx, req.(*SelectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Ledgers_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "snowy.Ledgers",
+	HandlerType: (*LedgersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Select",
+			Handler:    _Ledgers_Select_Handler,
+		},
+		{
+			MethodName: "Insert",
+			Handler:    _Ledgers_Insert_Handler,
+		},
+		{
+			MethodName: "Append",
+			Handler:    _Ledgers_Append_Handler,
+		},
+		{
+			MethodName: "Fork",
+			Handler:    _Ledgers_Fork_Handler,
+		},
+		{
+			MethodName: "SelectRevisions",
+			Handler:    _Ledgers_SelectRevisions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/rpc/pb/snowy.proto",
+}
+
+// ContentsClient is the client API for Contents service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ContentsClient interface {
+	Put(ctx context.Context, opts ...grpc.CallOption) (Contents_PutClient, error)
+	Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (Contents_SelectClient, error)
+}
+
+type contentsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewContentsClient(cc grpc.ClientConnInterface) ContentsClient {
+	return &contentsClient{cc}
+}
+
+func (c *contentsClient) Put(ctx context.Context, opts ...grpc.CallOption) (Contents_PutClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Contents_serviceDesc.Streams[0], "/snowy.Contents/Put", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contentsPutClient{stream}
+	return x, nil
+}
+
+type Contents_PutClient interface {
+	Send(*PutRequest) error
+	CloseAndRecv() (*Content, error)
+	grpc.ClientStream
+}
+
+type contentsPutClient struct {
+	grpc.ClientStream
+}
+
+func (x *contentsPutClient) Send(m *PutRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *contentsPutClient) CloseAndRecv() (*Content, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Content)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contentsClient) Select(ctx context.Context, in *SelectRequest, opts ...grpc.CallOption) (Contents_SelectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Contents_serviceDesc.Streams[1], "/snowy.Contents/Select", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contentsSelectClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Contents_SelectClient interface {
+	Recv() (*SelectResponse, error)
+	grpc.ClientStream
+}
+
+type contentsSelectClient struct {
+	grpc.ClientStream
+}
+
+func (x *contentsSelectClient) Recv() (*SelectResponse, error) {
+	m := new(SelectResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ContentsServer is the server API for Contents service.
+type ContentsServer interface {
+	Put(Contents_PutServer) error
+	Select(*SelectRequest, Contents_SelectServer) error
+}
+
+// UnimplementedContentsServer can be embedded to have forward compatible implementations.
+type UnimplementedContentsServer struct {
+}
+
+func (*UnimplementedContentsServer) Put(srv Contents_PutServer) error {
+	return status.Errorf(codes.Unimplemented, "method Put not implemented")
+}
+func (*UnimplementedContentsServer) Select(req *SelectRequest, srv Contents_SelectServer) error {
+	return status.Errorf(codes.Unimplemented, "method Select not implemented")
+}
+
+func RegisterContentsServer(s *grpc.Server, srv ContentsServer) {
+	s.RegisterService(&_Contents_serviceDesc, srv)
+}
+
+func _Contents_Put_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContentsServer).Put(&contentsPutServer{stream})
+}
+
+type Contents_PutServer interface {
+	SendAndClose(*Content) error
+	Recv() (*PutRequest, error)
+	grpc.ServerStream
+}
+
+type contentsPutServer struct {
+	grpc.ServerStream
+}
+
+func (x *contentsPutServer) SendAndClose(m *Content) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *contentsPutServer) Recv() (*PutRequest, error) {
+	m := new(PutRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Contents_Select_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SelectRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContentsServer).Select(m, &contentsSelectServer{stream})
+}
+
+type Contents_SelectServer interface {
+	Send(*SelectResponse) error
+	grpc.ServerStream
+}
+
+type contentsSelectServer struct {
+	grpc.ServerStream
+}
+
+func (x *contentsSelectServer) Send(m *SelectResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Contents_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "snowy.Contents",
+	HandlerType: (*ContentsServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Put",
+			Handler:       _Contents_Put_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Select",
+			Handler:       _Contents_Select_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/rpc/pb/snowy.proto",
+}
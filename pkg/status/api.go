@@ -8,14 +8,18 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/trussle/snowy/pkg/auth"
 	errs "github.com/trussle/snowy/pkg/http"
 	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
 )
 
 // These are the status API URL paths.
 const (
-	APIPathLivenessQuery  = "/health"
-	APIPathReadinessQuery = "/ready"
+	APIPathLivenessQuery   = "/health"
+	APIPathReadinessQuery  = "/ready"
+	APIPathStatisticsQuery = "/statistics"
 )
 
 // API serves the status API
@@ -24,19 +28,47 @@ type API struct {
 	clients  metrics.Gauge
 	duration metrics.HistogramVec
 	errors   errs.Error
+
+	repository repository.Repository
+	admins     auth.Admins
+}
+
+// APIOption defines a option for configuring the API.
+type APIOption func(*API)
+
+// WithStatistics serves the statistics of the ledgers of the tenant, from the
+// repository.
+func WithStatistics(repository repository.Repository) APIOption {
+	return func(a *API) {
+		a.repository = repository
+	}
+}
+
+// WithAdmins sets the principals that can read the statistics. The
+// statistics are across every resource of the tenant, regardless of who can
+// read them, so they're only open to the admins when auth is enabled.
+func WithAdmins(admins auth.Admins) APIOption {
+	return func(a *API) {
+		a.admins = admins
+	}
 }
 
 // NewAPI creates a API with the correct dependencies.
 func NewAPI(logger log.Logger,
 	clients metrics.Gauge,
 	duration metrics.HistogramVec,
+	opts ...APIOption,
 ) *API {
-	return &API{
+	api := &API{
 		logger:   logger,
 		clients:  clients,
 		duration: duration,
 		errors:   errs.NewError(logger),
 	}
+	for _, opt := range opts {
+		opt(api)
+	}
+	return api
 }
 
 func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -49,34 +81,50 @@ func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	a.clients.Inc()
 	defer a.clients.Dec()
 
+	method, path := r.Method, r.URL.Path
+
 	defer func(begin time.Time) {
 		a.duration.WithLabelValues(
 			r.Method,
-			r.URL.Path,
+			a.route(path),
 			strconv.Itoa(iw.code),
 		).Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
 	// Routing table
-	method, path := r.Method, r.URL.Path
 	switch {
 	case method == "GET" && path == APIPathLivenessQuery:
 		a.handleLiveness(w, r)
 	case method == "GET" && path == APIPathReadinessQuery:
 		a.handleReadiness(w, r)
+	case method == "GET" && path == APIPathStatisticsQuery && a.repository != nil:
+		a.handleStatistics(w, r)
 	default:
 		// Nothing found
 		a.errors.NotFound(w, r)
 	}
 }
 
+// route returns the route of the path, to label the metrics of the request
+// with, so that the labels are bounded by the routes.
+func (a *API) route(path string) string {
+	switch {
+	case path == APIPathLivenessQuery, path == APIPathReadinessQuery:
+		return path
+	case path == APIPathStatisticsQuery && a.repository != nil:
+		return path
+	default:
+		return metrics.UnmatchedRoute
+	}
+}
+
 func (a *API) handleLiveness(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(struct{}{}); err != nil {
-		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+		a.errors.InternalServerError(w, r, err.Error())
 	}
 }
 
@@ -86,8 +134,51 @@ func (a *API) handleReadiness(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(struct{}{}); err != nil {
-		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+		a.errors.InternalServerError(w, r, err.Error())
+	}
+}
+
+func (a *API) handleStatistics(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	if err := a.admins.Authorize(r.Context()); err != nil {
+		a.errors.Forbidden(w, r, err.Error())
+		return
 	}
+
+	// Validate user input.
+	var qp StatisticsQueryParams
+	if err := qp.DecodeFrom(r.URL); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := repository.BuildQuery(
+		repository.WithQueryTags(qp.Tags),
+		repository.WithQueryAuthorID(qp.AuthorID),
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	statistics, err := repository.WithContext(r.Context(), a.repository).LedgerStatistics(options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the statistics for the result.
+	qr := StatisticsQueryResult{Errors: a.errors, Tenant: options.Tenant}
+	qr.Statistics = statistics
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
 }
 
 type interceptingWriter struct {
@@ -1,6 +1,7 @@
 package status
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -8,8 +9,15 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/golang/mock/gomock"
+	"github.com/trussle/fsys"
 	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/auth"
+	"github.com/trussle/snowy/pkg/metrics"
 	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/uuid"
 )
 
 func TestAPI(t *testing.T) {
@@ -72,4 +80,141 @@ func TestAPI(t *testing.T) {
 			t.Errorf("expected: %d, actual: %d", expected, actual)
 		}
 	})
+
+	t.Run("statistics", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			data     = store.NewVirtualStore()
+			repo     = repository.NewRealRepository(
+				repository.NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+				data,
+				log.NewNopLogger(),
+			)
+			api = NewAPI(log.NewNopLogger(), clients, duration, WithStatistics(repo))
+		)
+
+		for _, entity := range []store.Entity{
+			{ResourceID: uuid.MustNew(), TenantID: "acme", ResourceAddress: "a", ResourceSize: 2, AuthorID: "alice", Tags: []string{"red"}},
+			{ResourceID: uuid.MustNew(), TenantID: "acme", ResourceAddress: "b", ResourceSize: 3, AuthorID: "bob"},
+			{ResourceID: uuid.MustNew(), TenantID: "other", ResourceAddress: "c", ResourceSize: 5, AuthorID: "alice", Tags: []string{"red"}},
+		} {
+			if err := data.Insert(entity); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		clients.EXPECT().Inc().Times(2)
+		clients.EXPECT().Dec().Times(2)
+
+		duration.EXPECT().WithLabelValues("GET", "/statistics", "200").Return(observer).Times(2)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(2)
+
+		for query, expected := range map[string]int{"": 2, "?query.tags=red": 1} {
+			var (
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest("GET", "/statistics"+query, nil)
+			)
+			api.ServeHTTP(w, r.WithContext(tenant.WithTenant(r.Context(), "acme")))
+
+			if expected, actual := http.StatusOK, w.Code; expected != actual {
+				t.Fatalf("expected: %d, actual: %d", expected, actual)
+			}
+
+			var res struct {
+				Tenant       string `json:"tenant"`
+				TotalLedgers int    `json:"total_ledgers"`
+				Authors      []struct {
+					Name string `json:"name"`
+				} `json:"authors"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := "acme", res.Tenant; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+			if actual := res.TotalLedgers; expected != actual {
+				t.Errorf("%q: expected: %d, actual: %d", query, expected, actual)
+			}
+			if expected, actual := "alice", res.Authors[0].Name; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		}
+	})
+
+	t.Run("statistics are only open to the admins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repository.NewRealRepository(
+				repository.NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+				store.NewVirtualStore(),
+				log.NewNopLogger(),
+			)
+			api = NewAPI(log.NewNopLogger(), clients, duration,
+				WithStatistics(repo),
+				WithAdmins(auth.Admins{Groups: []string{"ops"}}),
+			)
+		)
+
+		clients.EXPECT().Inc().Times(2)
+		clients.EXPECT().Dec().Times(2)
+
+		duration.EXPECT().WithLabelValues("GET", "/statistics", "403").Return(observer).Times(1)
+		duration.EXPECT().WithLabelValues("GET", "/statistics", "200").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(2)
+
+		for principal, expected := range map[string]int{
+			"alice": http.StatusForbidden,
+			"ops":   http.StatusOK,
+		} {
+			var (
+				w   = httptest.NewRecorder()
+				r   = httptest.NewRequest("GET", "/statistics", nil)
+				ctx = auth.WithPrincipal(tenant.WithTenant(r.Context(), "acme"), auth.Principal{
+					ID:     principal,
+					Groups: []string{principal},
+				})
+			)
+			api.ServeHTTP(w, r.WithContext(ctx))
+
+			if actual := w.Code; expected != actual {
+				t.Errorf("%s: expected: %d, actual: %d", principal, expected, actual)
+			}
+		}
+	})
+
+	t.Run("statistics without a repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			api      = NewAPI(log.NewNopLogger(), clients, duration)
+			w        = httptest.NewRecorder()
+		)
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues("GET", metrics.UnmatchedRoute, "404").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		api.ServeHTTP(w, httptest.NewRequest("GET", "/statistics", nil))
+
+		if expected, actual := http.StatusNotFound, w.Code; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
 }
@@ -0,0 +1,71 @@
+package status
+
+import "github.com/trussle/snowy/pkg/openapi"
+
+// OpenAPI describes the routes of the API, relative to where the API is
+// mounted.
+func OpenAPI() openapi.Paths {
+	var (
+		tags = []string{"status"}
+		ok   = openapi.Response{Description: "An empty object", Content: openapi.JSON(openapi.Object(nil))}
+	)
+
+	return openapi.Paths{
+		APIPathLivenessQuery: openapi.PathItem{
+			"get": {
+				OperationID: "health",
+				Summary:     "Check that the service is live",
+				Tags:        tags,
+				Responses:   openapi.Responses(ok, "500"),
+			},
+		},
+		APIPathReadinessQuery: openapi.PathItem{
+			"get": {
+				OperationID: "ready",
+				Summary:     "Check that the service is ready to serve requests",
+				Tags:        tags,
+				Responses:   openapi.Responses(ok, "500"),
+			},
+		},
+		APIPathStatisticsQuery: openapi.PathItem{
+			"get": {
+				OperationID: "selectStatistics",
+				Summary:     "Select the statistics of the ledgers of the tenant",
+				Tags:        tags,
+				Parameters:  StatisticsQueryParams{}.Parameters(),
+				Responses: openapi.Responses(openapi.Response{
+					Description: "The statistics",
+					Content:     openapi.JSON(statisticsSchema()),
+				}, "400", "403", "500"),
+			},
+		},
+	}
+}
+
+func statisticsSchema() openapi.Schema {
+	count := openapi.Object(map[string]openapi.Schema{
+		"name":    openapi.String(),
+		"ledgers": openapi.Integer(),
+	})
+	return openapi.Object(map[string]openapi.Schema{
+		"tenant":          openapi.String(),
+		"total_ledgers":   openapi.Integer(),
+		"total_resources": openapi.Integer(),
+		"total_bytes":     openapi.Integer(),
+		"content_types": {
+			Type:        "object",
+			Description: "bytes of the distinct content by the content type",
+		},
+		"revisions": openapi.Array(openapi.Object(map[string]openapi.Schema{
+			"min":       openapi.Integer(),
+			"max":       openapi.Integer(),
+			"resources": openapi.Integer(),
+		}, "max")),
+		"authors": openapi.Array(count),
+		"tags":    openapi.Array(count),
+		"inserts": openapi.Array(openapi.Object(map[string]openapi.Schema{
+			"window":  openapi.String(),
+			"ledgers": openapi.Integer(),
+		})),
+	})
+}
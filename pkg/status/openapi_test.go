@@ -0,0 +1,75 @@
+package status
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/fsys"
+	"github.com/trussle/harness/matchers"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/openapi"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/store"
+)
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	t.Run("routes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			paths    = OpenAPI()
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repository.NewRealRepository(
+				repository.NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+				store.NewVirtualStore(),
+				log.NewNopLogger(),
+			)
+			api = NewAPI(log.NewNopLogger(), clients, duration, WithStatistics(repo))
+		)
+
+		clients.EXPECT().Inc().AnyTimes()
+		clients.EXPECT().Dec().AnyTimes()
+
+		duration.EXPECT().WithLabelValues(gomock.Any(), gomock.Any(), "200").Return(observer).AnyTimes()
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).AnyTimes()
+
+		for _, route := range paths.Routes() {
+			var (
+				parts = strings.SplitN(route, " ", 2)
+				op    = paths[parts[1]][strings.ToLower(parts[0])]
+				w     = httptest.NewRecorder()
+			)
+			api.ServeHTTP(w, httptest.NewRequest(parts[0], parts[1], nil))
+
+			if expected, actual := http.StatusOK, w.Code; expected != actual {
+				t.Errorf("%s: expected: %d, actual: %d", route, expected, actual)
+				continue
+			}
+			if err := openapi.Validate(op.Responses["200"].Content["application/json"].Schema, w.Body.Bytes()); err != nil {
+				t.Errorf("%s: %v", route, err)
+			}
+		}
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		op := OpenAPI()[APIPathStatisticsQuery]["get"]
+
+		decode := func(u *url.URL) error {
+			var qp StatisticsQueryParams
+			return qp.DecodeFrom(u)
+		}
+		if err := openapi.CheckParameters(op.Parameters, decode); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/openapi"
+)
+
+const (
+	defaultContentType = "application/json"
+
+	httpHeaderContentType = "Content-Type"
+	httpHeaderDuration    = "X-Duration"
+)
+
+// StatisticsQueryParams defines all the dimensions of a query.
+type StatisticsQueryParams struct {
+	Tags     []string `json:"query.tags"`
+	AuthorID string   `json:"query.author_id"`
+}
+
+// DecodeFrom populates a StatisticsQueryParams from a URL.
+func (qp *StatisticsQueryParams) DecodeFrom(u *url.URL) error {
+	// Tags are optional here.
+	if tags := u.Query().Get("query.tags"); tags != "" {
+		qp.Tags = strings.Split(tags, ",")
+	}
+
+	// Author ID is optional here.
+	if authorID := u.Query().Get("query.author_id"); authorID != "" {
+		qp.AuthorID = authorID
+	}
+
+	return nil
+}
+
+// Parameters describes the parameters that DecodeFrom reads.
+func (StatisticsQueryParams) Parameters() []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.Query("query.tags", "comma separated tags, of which the ledger has to have any", false, openapi.String()),
+		openapi.Query("query.author_id", "author the ledger has to be written by", false, openapi.String()),
+	}
+}
+
+// StatisticsQueryResult contains statistics about the ledgers of a tenant.
+type StatisticsQueryResult struct {
+	Errors     errs.Error
+	Tenant     string                  `json:"tenant"`
+	Duration   string                  `json:"duration"`
+	Statistics models.LedgerStatistics `json:"statistics"`
+}
+
+type revisionBucket struct {
+	Min       int `json:"min"`
+	Max       int `json:"max,omitempty"`
+	Resources int `json:"resources"`
+}
+
+type ledgerCount struct {
+	Name    string `json:"name"`
+	Ledgers int    `json:"ledgers"`
+}
+
+type insertWindow struct {
+	Window  string `json:"window"`
+	Ledgers int    `json:"ledgers"`
+}
+
+// EncodeTo encodes the StatisticsQueryResult to the HTTP response writer.
+func (qr *StatisticsQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	stats := qr.Statistics
+
+	revisions := make([]revisionBucket, len(stats.Revisions))
+	for k, v := range stats.Revisions {
+		revisions[k] = revisionBucket{Min: v.Min, Max: v.Max, Resources: v.Resources}
+	}
+	authors := make([]ledgerCount, len(stats.Authors))
+	for k, v := range stats.Authors {
+		authors[k] = ledgerCount{Name: v.Name, Ledgers: v.Ledgers}
+	}
+	tags := make([]ledgerCount, len(stats.Tags))
+	for k, v := range stats.Tags {
+		tags[k] = ledgerCount{Name: v.Name, Ledgers: v.Ledgers}
+	}
+	inserts := make([]insertWindow, len(stats.Inserts))
+	for k, v := range stats.Inserts {
+		inserts[k] = insertWindow{Window: v.Window.String(), Ledgers: v.Ledgers}
+	}
+	contentTypes := stats.ContentTypes
+	if contentTypes == nil {
+		contentTypes = make(map[string]int64)
+	}
+
+	if err := json.NewEncoder(w).Encode(struct {
+		Tenant         string           `json:"tenant"`
+		TotalLedgers   int              `json:"total_ledgers"`
+		TotalResources int              `json:"total_resources"`
+		TotalBytes     int64            `json:"total_bytes"`
+		ContentTypes   map[string]int64 `json:"content_types"`
+		Revisions      []revisionBucket `json:"revisions"`
+		Authors        []ledgerCount    `json:"authors"`
+		Tags           []ledgerCount    `json:"tags"`
+		Inserts        []insertWindow   `json:"inserts"`
+	}{
+		Tenant:         qr.Tenant,
+		TotalLedgers:   stats.TotalLedgers,
+		TotalResources: stats.TotalResources,
+		TotalBytes:     stats.TotalBytes,
+		ContentTypes:   contentTypes,
+		Revisions:      revisions,
+		Authors:        authors,
+		Tags:           tags,
+		Inserts:        inserts,
+	}); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
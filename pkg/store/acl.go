@@ -0,0 +1,19 @@
+package store
+
+import (
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// ACL represents a revision of the access control list of a resource. The
+// latest revision is the one in effect.
+type ACL struct {
+	TenantID   string
+	ResourceID uuid.UUID
+	Owner      string
+	Readers    []string
+	Writers    []string
+	UpdatedBy  string
+	CreatedOn  time.Time
+}
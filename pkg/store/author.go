@@ -0,0 +1,13 @@
+package store
+
+import "time"
+
+// AuthorKey represents a public key that has been registered for an author,
+// which is used to verify the signatures of the ledgers by the author.
+type AuthorKey struct {
+	TenantID  string
+	AuthorID  string
+	KeyID     string
+	PublicKey []byte
+	CreatedOn time.Time
+}
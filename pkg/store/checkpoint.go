@@ -0,0 +1,40 @@
+package store
+
+import (
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// Leaf represents a ledger that has been appended to the checkpoint tree. The
+// leaves are numbered in the order that they were appended, starting at zero.
+type Leaf struct {
+	Index    int64
+	LedgerID uuid.UUID
+	Hash     string
+}
+
+// NodeID identifies a complete subtree of the checkpoint tree, made up of the
+// 1<<Level leaves starting at the leaf Index<<Level.
+type NodeID struct {
+	Level int
+	Index int64
+}
+
+// Node represents the hash of a complete subtree of the checkpoint tree. The
+// leaf hashes are the nodes at level zero.
+type Node struct {
+	Level int
+	Index int64
+	Hash  []byte
+}
+
+// Checkpoint represents a signed tree head of the checkpoint tree, covering
+// the first TreeSize leaves.
+type Checkpoint struct {
+	TreeSize  int64
+	RootHash  []byte
+	KeyID     string
+	Signature []byte
+	CreatedOn time.Time
+}
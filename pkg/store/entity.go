@@ -11,6 +11,7 @@ import (
 // models.Ledger without the file content.
 type Entity struct {
 	ID, ParentID         uuid.UUID
+	TenantID             string
 	Name                 string
 	ResourceID           uuid.UUID
 	ResourceAddress      string
@@ -19,6 +20,16 @@ type Entity struct {
 	AuthorID             string
 	Tags                 []string
 	CreatedOn, DeletedOn time.Time
+
+	// Hash chains the entity to its parent, see HashEntity. The hash is
+	// computed by the store when the entity is inserted.
+	Hash string
+
+	// Signature is the optional signature of the author over the canonical
+	// form of the ledger, made with the author key identified by
+	// SignatureKeyID.
+	Signature      []byte
+	SignatureKeyID string
 }
 
 // EntityOption defines a option for generating a entity
@@ -53,6 +64,14 @@ func WithParentID(parentID uuid.UUID) EntityOption {
 	}
 }
 
+// WithTenantID adds a type of tenantID to the entity.
+func WithTenantID(tenantID string) EntityOption {
+	return func(entity *Entity) error {
+		entity.TenantID = tenantID
+		return nil
+	}
+}
+
 // WithName adds a type of name to the entity.
 func WithName(name string) EntityOption {
 	return func(entity *Entity) error {
@@ -124,3 +143,21 @@ func WithDeletedOn(deletedOn time.Time) EntityOption {
 		return nil
 	}
 }
+
+// WithHash adds a type of hash to the entity.
+func WithHash(hash string) EntityOption {
+	return func(entity *Entity) error {
+		entity.Hash = hash
+		return nil
+	}
+}
+
+// WithSignature adds a type of signature to the entity, along with the id of
+// the author key that made it.
+func WithSignature(keyID string, signature []byte) EntityOption {
+	return func(entity *Entity) error {
+		entity.SignatureKeyID = keyID
+		entity.Signature = signature
+		return nil
+	}
+}
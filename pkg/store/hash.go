@@ -0,0 +1,57 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"time"
+)
+
+const hashVersion = "v2"
+
+// HashEntity computes the hash of the entity, chained to the hash of the
+// parent entity. The hash covers the canonical fields of the entity, along
+// with its tenant and signature, so any change to a stored revision, or to any
+// of its ancestors, can be detected by recomputing the chain.
+func HashEntity(entity Entity, parentHash string) string {
+	h := sha256.New()
+	writeHashField(h, hashVersion)
+	writeHashField(h, parentHash)
+	writeHashField(h, entity.ParentID.String())
+	writeHashField(h, entity.TenantID)
+	writeHashField(h, entity.Name)
+	writeHashField(h, entity.ResourceID.String())
+	writeHashField(h, entity.ResourceAddress)
+	writeHashField(h, strconv.FormatInt(entity.ResourceSize, 10))
+	writeHashField(h, entity.ResourceContentType)
+	writeHashField(h, entity.AuthorID)
+
+	tags := sortTags(entity.Tags)
+	writeHashField(h, strconv.Itoa(len(tags)))
+	for _, tag := range tags {
+		writeHashField(h, tag)
+	}
+
+	writeHashField(h, canonicalTime(entity.CreatedOn))
+	writeHashField(h, canonicalTime(entity.DeletedOn))
+	writeHashField(h, hex.EncodeToString(entity.Signature))
+	writeHashField(h, entity.SignatureKeyID)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHashField writes the field prefixed with its length, so that the
+// boundaries between fields are unambiguous.
+func writeHashField(h hash.Hash, field string) {
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(field)))
+	h.Write(size[:])
+	h.Write([]byte(field))
+}
+
+// canonicalTime formats the time in UTC at microsecond precision, which is
+// the precision that the datastore keeps.
+func canonicalTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/trussle/harness/generators"
+	"github.com/trussle/uuid"
+)
+
+func TestHashEntity(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deterministic", func(t *testing.T) {
+		fn := func(parentID, resourceID uuid.UUID, name, authorID string, tags generators.ASCIISlice) bool {
+			entity := Entity{
+				ParentID:   parentID,
+				ResourceID: resourceID,
+				Name:       name,
+				AuthorID:   authorID,
+				Tags:       tags,
+				CreatedOn:  time.Now(),
+			}
+			return HashEntity(entity, "parent") == HashEntity(entity, "parent")
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("chained to parent hash", func(t *testing.T) {
+		fn := func(resourceID uuid.UUID, a, b string) bool {
+			if a == b {
+				return true
+			}
+			entity := Entity{
+				ResourceID: resourceID,
+			}
+			return HashEntity(entity, a) != HashEntity(entity, b)
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("covers fields", func(t *testing.T) {
+		entity := Entity{
+			ParentID:            uuid.MustNew(),
+			TenantID:            "tenant",
+			Name:                "name",
+			ResourceID:          uuid.MustNew(),
+			ResourceAddress:     "address",
+			ResourceSize:        10,
+			ResourceContentType: "text/plain",
+			AuthorID:            "author",
+			Tags:                []string{"a", "b"},
+			CreatedOn:           time.Now(),
+			Signature:           []byte("signature"),
+			SignatureKeyID:      "key",
+		}
+		hash := HashEntity(entity, "")
+
+		for name, fn := range map[string]func(*Entity){
+			"parent_id":             func(e *Entity) { e.ParentID = uuid.MustNew() },
+			"tenant_id":             func(e *Entity) { e.TenantID = "other" },
+			"name":                  func(e *Entity) { e.Name = "other" },
+			"resource_id":           func(e *Entity) { e.ResourceID = uuid.MustNew() },
+			"resource_address":      func(e *Entity) { e.ResourceAddress = "other" },
+			"resource_size":         func(e *Entity) { e.ResourceSize = 11 },
+			"resource_content_type": func(e *Entity) { e.ResourceContentType = "text/html" },
+			"author_id":             func(e *Entity) { e.AuthorID = "other" },
+			"tags":                  func(e *Entity) { e.Tags = []string{"a", "c"} },
+			"created_on":            func(e *Entity) { e.CreatedOn = e.CreatedOn.Add(time.Second) },
+			"deleted_on":            func(e *Entity) { e.DeletedOn = e.CreatedOn },
+			"signature":             func(e *Entity) { e.Signature = []byte("other") },
+			"signature_key_id":      func(e *Entity) { e.SignatureKeyID = "other" },
+		} {
+			modified := entity
+			fn(&modified)
+
+			if HashEntity(modified, "") == hash {
+				t.Errorf("%s: expected hash to change", name)
+			}
+		}
+	})
+
+	t.Run("ambiguous fields", func(t *testing.T) {
+		var (
+			a = Entity{Name: "ab", AuthorID: "c"}
+			b = Entity{Name: "a", AuthorID: "bc"}
+		)
+		if HashEntity(a, "") == HashEntity(b, "") {
+			t.Errorf("expected hashes to differ")
+		}
+	})
+
+	t.Run("canonical", func(t *testing.T) {
+		var (
+			now = time.Now()
+			a   = Entity{Tags: []string{"b", "a"}, CreatedOn: now}
+			b   = Entity{Tags: []string{"a", "b"}, CreatedOn: now.In(time.FixedZone("test", 3600)).Truncate(time.Microsecond)}
+		)
+		if expected, actual := HashEntity(a, ""), HashEntity(b, ""); expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+}
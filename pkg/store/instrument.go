@@ -0,0 +1,328 @@
+package store
+
+import (
+	"time"
+
+	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/uuid"
+)
+
+type instrumentedStore struct {
+	store    Store
+	duration metrics.HistogramVec
+	errors   metrics.CounterVec
+}
+
+// NewInstrumentedStore creates a Store that records the latency of every call
+// to the store, and the errors, by the operation. Not found errors are the
+// expected result of some calls, so they aren't recorded as errors.
+func NewInstrumentedStore(store Store, duration metrics.HistogramVec, errors metrics.CounterVec) Store {
+	return &instrumentedStore{
+		store:    store,
+		duration: duration,
+		errors:   errors,
+	}
+}
+
+func (s *instrumentedStore) observe(operation string, begin time.Time, err error) {
+	s.duration.WithLabelValues(operation).Observe(time.Since(begin).Seconds())
+	if err != nil && !ErrNotFound(err) {
+		s.errors.WithLabelValues(operation).Inc()
+	}
+}
+
+func (s *instrumentedStore) Select(resourceID uuid.UUID, options Query) (res Entity, err error) {
+	defer func(begin time.Time) { s.observe("Select", begin, err) }(time.Now())
+
+	return s.store.Select(resourceID, options)
+}
+
+func (s *instrumentedStore) SelectEntity(id uuid.UUID, options Query) (res Entity, err error) {
+	defer func(begin time.Time) { s.observe("SelectEntity", begin, err) }(time.Now())
+
+	return s.store.SelectEntity(id, options)
+}
+
+func (s *instrumentedStore) InsertLedgers(entities []Entity, acls []ACL) (err error) {
+	defer func(begin time.Time) { s.observe("InsertLedgers", begin, err) }(time.Now())
+
+	return s.store.InsertLedgers(entities, acls)
+}
+
+func (s *instrumentedStore) SelectLedgerIDs(ids []uuid.UUID) (res []uuid.UUID, err error) {
+	defer func(begin time.Time) { s.observe("SelectLedgerIDs", begin, err) }(time.Now())
+
+	return s.store.SelectLedgerIDs(ids)
+}
+
+func (s *instrumentedStore) Insert(entity Entity) (err error) {
+	defer func(begin time.Time) { s.observe("Insert", begin, err) }(time.Now())
+
+	return s.store.Insert(entity)
+}
+
+func (s *instrumentedStore) SelectRevisions(resourceID uuid.UUID, options Query) (res []Entity, err error) {
+	defer func(begin time.Time) { s.observe("SelectRevisions", begin, err) }(time.Now())
+
+	return s.store.SelectRevisions(resourceID, options)
+}
+
+func (s *instrumentedStore) SelectForkRevisions(resourceID uuid.UUID, options Query) (res []Entity, err error) {
+	defer func(begin time.Time) { s.observe("SelectForkRevisions", begin, err) }(time.Now())
+
+	return s.store.SelectForkRevisions(resourceID, options)
+}
+
+func (s *instrumentedStore) InsertKey(key Key) (err error) {
+	defer func(begin time.Time) { s.observe("InsertKey", begin, err) }(time.Now())
+
+	return s.store.InsertKey(key)
+}
+
+func (s *instrumentedStore) SelectKey(tenantID string, resourceID uuid.UUID, resourceAddress string) (res Key, err error) {
+	defer func(begin time.Time) { s.observe("SelectKey", begin, err) }(time.Now())
+
+	return s.store.SelectKey(tenantID, resourceID, resourceAddress)
+}
+
+func (s *instrumentedStore) SelectAddressKeys(tenantID, resourceAddress string) (res []Key, err error) {
+	defer func(begin time.Time) { s.observe("SelectAddressKeys", begin, err) }(time.Now())
+
+	return s.store.SelectAddressKeys(tenantID, resourceAddress)
+}
+
+func (s *instrumentedStore) DestroyKey(tenantID string, resourceID uuid.UUID, resourceAddress string, destroyedOn time.Time) (err error) {
+	defer func(begin time.Time) { s.observe("DestroyKey", begin, err) }(time.Now())
+
+	return s.store.DestroyKey(tenantID, resourceID, resourceAddress, destroyedOn)
+}
+
+func (s *instrumentedStore) SelectAuthorResources(authorID string, options Query) (res []uuid.UUID, err error) {
+	defer func(begin time.Time) { s.observe("SelectAuthorResources", begin, err) }(time.Now())
+
+	return s.store.SelectAuthorResources(authorID, options)
+}
+
+func (s *instrumentedStore) SelectAddressResources(tenantID, resourceAddress string) (res []uuid.UUID, err error) {
+	defer func(begin time.Time) { s.observe("SelectAddressResources", begin, err) }(time.Now())
+
+	return s.store.SelectAddressResources(tenantID, resourceAddress)
+}
+
+func (s *instrumentedStore) SelectResources(options Query) (res []uuid.UUID, err error) {
+	defer func(begin time.Time) { s.observe("SelectResources", begin, err) }(time.Now())
+
+	return s.store.SelectResources(options)
+}
+
+func (s *instrumentedStore) InsertAuthorKey(authorKey AuthorKey) (err error) {
+	defer func(begin time.Time) { s.observe("InsertAuthorKey", begin, err) }(time.Now())
+
+	return s.store.InsertAuthorKey(authorKey)
+}
+
+func (s *instrumentedStore) SelectAuthorKey(tenantID, authorID, keyID string) (res AuthorKey, err error) {
+	defer func(begin time.Time) { s.observe("SelectAuthorKey", begin, err) }(time.Now())
+
+	return s.store.SelectAuthorKey(tenantID, authorID, keyID)
+}
+
+func (s *instrumentedStore) SelectAuthorKeys(tenantID, authorID string) (res []AuthorKey, err error) {
+	defer func(begin time.Time) { s.observe("SelectAuthorKeys", begin, err) }(time.Now())
+
+	return s.store.SelectAuthorKeys(tenantID, authorID)
+}
+
+func (s *instrumentedStore) InsertACL(acl ACL) (err error) {
+	defer func(begin time.Time) { s.observe("InsertACL", begin, err) }(time.Now())
+
+	return s.store.InsertACL(acl)
+}
+
+func (s *instrumentedStore) SelectACL(resourceID uuid.UUID, options Query) (res ACL, err error) {
+	defer func(begin time.Time) { s.observe("SelectACL", begin, err) }(time.Now())
+
+	return s.store.SelectACL(resourceID, options)
+}
+
+func (s *instrumentedStore) SelectACLRevisions(resourceID uuid.UUID, options Query) (res []ACL, err error) {
+	defer func(begin time.Time) { s.observe("SelectACLRevisions", begin, err) }(time.Now())
+
+	return s.store.SelectACLRevisions(resourceID, options)
+}
+
+func (s *instrumentedStore) AppendLeaves() (res int64, err error) {
+	defer func(begin time.Time) { s.observe("AppendLeaves", begin, err) }(time.Now())
+
+	return s.store.AppendLeaves()
+}
+
+func (s *instrumentedStore) SelectLeaves(start, end int64) (res []Leaf, err error) {
+	defer func(begin time.Time) { s.observe("SelectLeaves", begin, err) }(time.Now())
+
+	return s.store.SelectLeaves(start, end)
+}
+
+func (s *instrumentedStore) SelectLeaf(ledgerID uuid.UUID) (res Leaf, err error) {
+	defer func(begin time.Time) { s.observe("SelectLeaf", begin, err) }(time.Now())
+
+	return s.store.SelectLeaf(ledgerID)
+}
+
+func (s *instrumentedStore) InsertNodes(nodes []Node) (err error) {
+	defer func(begin time.Time) { s.observe("InsertNodes", begin, err) }(time.Now())
+
+	return s.store.InsertNodes(nodes)
+}
+
+func (s *instrumentedStore) SelectNodes(ids []NodeID) (res []Node, err error) {
+	defer func(begin time.Time) { s.observe("SelectNodes", begin, err) }(time.Now())
+
+	return s.store.SelectNodes(ids)
+}
+
+func (s *instrumentedStore) SelectNodesTreeSize() (res int64, err error) {
+	defer func(begin time.Time) { s.observe("SelectNodesTreeSize", begin, err) }(time.Now())
+
+	return s.store.SelectNodesTreeSize()
+}
+
+func (s *instrumentedStore) InsertCheckpoint(checkpoint Checkpoint) (err error) {
+	defer func(begin time.Time) { s.observe("InsertCheckpoint", begin, err) }(time.Now())
+
+	return s.store.InsertCheckpoint(checkpoint)
+}
+
+func (s *instrumentedStore) SelectCheckpoint(treeSize int64) (res Checkpoint, err error) {
+	defer func(begin time.Time) { s.observe("SelectCheckpoint", begin, err) }(time.Now())
+
+	return s.store.SelectCheckpoint(treeSize)
+}
+
+func (s *instrumentedStore) Statistics(options Query) (res Statistics, err error) {
+	defer func(begin time.Time) { s.observe("Statistics", begin, err) }(time.Now())
+
+	return s.store.Statistics(options)
+}
+
+func (s *instrumentedStore) InsertQuota(quota Quota) (err error) {
+	defer func(begin time.Time) { s.observe("InsertQuota", begin, err) }(time.Now())
+
+	return s.store.InsertQuota(quota)
+}
+
+func (s *instrumentedStore) SelectQuota(tenantID, authorID string) (res Quota, err error) {
+	defer func(begin time.Time) { s.observe("SelectQuota", begin, err) }(time.Now())
+
+	return s.store.SelectQuota(tenantID, authorID)
+}
+
+func (s *instrumentedStore) SelectUsage(options Query, since time.Time) (res Usage, err error) {
+	defer func(begin time.Time) { s.observe("SelectUsage", begin, err) }(time.Now())
+
+	return s.store.SelectUsage(options, since)
+}
+
+func (s *instrumentedStore) InsertWebhook(webhook Webhook) (err error) {
+	defer func(begin time.Time) { s.observe("InsertWebhook", begin, err) }(time.Now())
+
+	return s.store.InsertWebhook(webhook)
+}
+
+func (s *instrumentedStore) SelectWebhooks(tenantID string) (res []Webhook, err error) {
+	defer func(begin time.Time) { s.observe("SelectWebhooks", begin, err) }(time.Now())
+
+	return s.store.SelectWebhooks(tenantID)
+}
+
+func (s *instrumentedStore) DeleteWebhook(tenantID string, webhookID uuid.UUID) (err error) {
+	defer func(begin time.Time) { s.observe("DeleteWebhook", begin, err) }(time.Now())
+
+	return s.store.DeleteWebhook(tenantID, webhookID)
+}
+
+func (s *instrumentedStore) InsertDelivery(delivery Delivery) (err error) {
+	defer func(begin time.Time) { s.observe("InsertDelivery", begin, err) }(time.Now())
+
+	return s.store.InsertDelivery(delivery)
+}
+
+func (s *instrumentedStore) UpdateDelivery(delivery Delivery) (err error) {
+	defer func(begin time.Time) { s.observe("UpdateDelivery", begin, err) }(time.Now())
+
+	return s.store.UpdateDelivery(delivery)
+}
+
+func (s *instrumentedStore) SelectDelivery(tenantID string, deliveryID uuid.UUID) (res Delivery, err error) {
+	defer func(begin time.Time) { s.observe("SelectDelivery", begin, err) }(time.Now())
+
+	return s.store.SelectDelivery(tenantID, deliveryID)
+}
+
+func (s *instrumentedStore) SelectDeliveries(tenantID, status string) (res []Delivery, err error) {
+	defer func(begin time.Time) { s.observe("SelectDeliveries", begin, err) }(time.Now())
+
+	return s.store.SelectDeliveries(tenantID, status)
+}
+
+func (s *instrumentedStore) ClaimPendingDeliveries(before, until time.Time, limit int) (res []Delivery, err error) {
+	defer func(begin time.Time) { s.observe("ClaimPendingDeliveries", begin, err) }(time.Now())
+
+	return s.store.ClaimPendingDeliveries(before, until, limit)
+}
+
+func (s *instrumentedStore) InsertDeliveryAttempt(deliveryAttempt DeliveryAttempt) (err error) {
+	defer func(begin time.Time) { s.observe("InsertDeliveryAttempt", begin, err) }(time.Now())
+
+	return s.store.InsertDeliveryAttempt(deliveryAttempt)
+}
+
+func (s *instrumentedStore) SelectDeliveryAttempts(deliveryID uuid.UUID) (res []DeliveryAttempt, err error) {
+	defer func(begin time.Time) { s.observe("SelectDeliveryAttempts", begin, err) }(time.Now())
+
+	return s.store.SelectDeliveryAttempts(deliveryID)
+}
+
+func (s *instrumentedStore) SelectOutbox(after int64, limit int) (res []OutboxRecord, err error) {
+	defer func(begin time.Time) { s.observe("SelectOutbox", begin, err) }(time.Now())
+
+	return s.store.SelectOutbox(after, limit)
+}
+
+func (s *instrumentedStore) SelectOutboxCheckpoint(sink string) (res int64, err error) {
+	defer func(begin time.Time) { s.observe("SelectOutboxCheckpoint", begin, err) }(time.Now())
+
+	return s.store.SelectOutboxCheckpoint(sink)
+}
+
+func (s *instrumentedStore) InsertOutboxCheckpoint(sink string, sequence int64) (err error) {
+	defer func(begin time.Time) { s.observe("InsertOutboxCheckpoint", begin, err) }(time.Now())
+
+	return s.store.InsertOutboxCheckpoint(sink, sequence)
+}
+
+func (s *instrumentedStore) DeleteOutbox(through int64) (err error) {
+	defer func(begin time.Time) { s.observe("DeleteOutbox", begin, err) }(time.Now())
+
+	return s.store.DeleteOutbox(through)
+}
+
+func (s *instrumentedStore) LockOutboxRelay(fn func() error) (locked bool, err error) {
+	defer func(begin time.Time) { s.observe("LockOutboxRelay", begin, err) }(time.Now())
+
+	return s.store.LockOutboxRelay(fn)
+}
+
+func (s *instrumentedStore) Drop() (err error) {
+	defer func(begin time.Time) { s.observe("Drop", begin, err) }(time.Now())
+
+	return s.store.Drop()
+}
+
+func (s *instrumentedStore) Run() error {
+	return s.store.Run()
+}
+
+func (s *instrumentedStore) Stop() {
+	s.store.Stop()
+}
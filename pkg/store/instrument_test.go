@@ -0,0 +1,97 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/trussle/harness/matchers"
+	"github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/uuid"
+)
+
+// failingStore fails every insert.
+type failingStore struct {
+	Store
+}
+
+func (failingStore) Insert(Entity) error {
+	return errors.New("bad")
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestInstrumentedStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records latency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			duration = mocks.NewMockHistogramVec(ctrl)
+			errs     = mocks.NewMockCounterVec(ctrl)
+			observer = mocks.NewMockObserver(ctrl)
+			store    = NewInstrumentedStore(NewVirtualStore(), duration, errs)
+		)
+
+		duration.EXPECT().WithLabelValues("Insert").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		if err := store.Insert(Entity{ResourceID: uuid.MustNew()}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("not found isn't an error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			duration = mocks.NewMockHistogramVec(ctrl)
+			errs     = mocks.NewMockCounterVec(ctrl)
+			observer = mocks.NewMockObserver(ctrl)
+			store    = NewInstrumentedStore(NewVirtualStore(), duration, errs)
+		)
+
+		duration.EXPECT().WithLabelValues("Select").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		if _, err := store.Select(uuid.MustNew(), Query{}); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+	})
+
+	t.Run("records errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var (
+			duration = mocks.NewMockHistogramVec(ctrl)
+			errs     = mocks.NewMockCounterVec(ctrl)
+			observer = mocks.NewMockObserver(ctrl)
+			counter  = prometheus.NewCounter(prometheus.CounterOpts{Name: "errors"})
+			store    = NewInstrumentedStore(failingStore{NewVirtualStore()}, duration, errs)
+		)
+
+		duration.EXPECT().WithLabelValues("Insert").Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+		errs.EXPECT().WithLabelValues("Insert").Return(counter).Times(1)
+
+		if err := store.Insert(Entity{ResourceID: uuid.MustNew()}); err == nil {
+			t.Errorf("expected: error, actual: %v", err)
+		}
+
+		if expected, actual := 1.0, counterValue(t, counter); expected != actual {
+			t.Errorf("expected: %f, actual: %f", expected, actual)
+		}
+	})
+}
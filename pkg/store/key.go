@@ -0,0 +1,29 @@
+package store
+
+import (
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// Key represents a wrapped data key with in the persistent store. The data key
+// encrypts the content at the resource address and is wrapped by the master
+// key that is identified by KeyID, so the data key is never stored in the
+// clear. Every resource that refers to the content has its own copy of the
+// key, so that erasing one resource never erases another, and content that
+// isn't referred to by any resource yet has its key under the empty resource
+// id. Once a key is destroyed, the wrapped key is removed and DestroyedOn is
+// set.
+type Key struct {
+	TenantID               string
+	ResourceID             uuid.UUID
+	ResourceAddress        string
+	KeyID                  string
+	WrappedKey             []byte
+	CreatedOn, DestroyedOn time.Time
+}
+
+// Destroyed returns if the key material has been destroyed.
+func (k Key) Destroyed() bool {
+	return !k.DestroyedOn.IsZero()
+}
@@ -9,6 +9,7 @@ import (
 	store "github.com/trussle/snowy/pkg/store"
 	uuid "github.com/trussle/uuid"
 	reflect "reflect"
+	time "time"
 )
 
 // MockStore is a mock of Store interface
@@ -34,6 +35,68 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AppendLeaves mocks base method
+func (m *MockStore) AppendLeaves() (int64, error) {
+	ret := m.ctrl.Call(m, "AppendLeaves")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendLeaves indicates an expected call of AppendLeaves
+func (mr *MockStoreMockRecorder) AppendLeaves() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendLeaves", reflect.TypeOf((*MockStore)(nil).AppendLeaves))
+}
+
+// ClaimPendingDeliveries mocks base method
+func (m *MockStore) ClaimPendingDeliveries(arg0, arg1 time.Time, arg2 int) ([]store.Delivery, error) {
+	ret := m.ctrl.Call(m, "ClaimPendingDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]store.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingDeliveries indicates an expected call of ClaimPendingDeliveries
+func (mr *MockStoreMockRecorder) ClaimPendingDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingDeliveries", reflect.TypeOf((*MockStore)(nil).ClaimPendingDeliveries), arg0, arg1, arg2)
+}
+
+// DeleteOutbox mocks base method
+func (m *MockStore) DeleteOutbox(arg0 int64) error {
+	ret := m.ctrl.Call(m, "DeleteOutbox", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutbox indicates an expected call of DeleteOutbox
+func (mr *MockStoreMockRecorder) DeleteOutbox(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutbox", reflect.TypeOf((*MockStore)(nil).DeleteOutbox), arg0)
+}
+
+// DeleteWebhook mocks base method
+func (m *MockStore) DeleteWebhook(arg0 string, arg1 uuid.UUID) error {
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook
+func (mr *MockStoreMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockStore)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// DestroyKey mocks base method
+func (m *MockStore) DestroyKey(arg0 string, arg1 uuid.UUID, arg2 string, arg3 time.Time) error {
+	ret := m.ctrl.Call(m, "DestroyKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyKey indicates an expected call of DestroyKey
+func (mr *MockStoreMockRecorder) DestroyKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyKey", reflect.TypeOf((*MockStore)(nil).DestroyKey), arg0, arg1, arg2, arg3)
+}
+
 // Drop mocks base method
 func (m *MockStore) Drop() error {
 	ret := m.ctrl.Call(m, "Drop")
@@ -58,6 +121,151 @@ func (mr *MockStoreMockRecorder) Insert(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockStore)(nil).Insert), arg0)
 }
 
+// InsertACL mocks base method
+func (m *MockStore) InsertACL(arg0 store.ACL) error {
+	ret := m.ctrl.Call(m, "InsertACL", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertACL indicates an expected call of InsertACL
+func (mr *MockStoreMockRecorder) InsertACL(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertACL", reflect.TypeOf((*MockStore)(nil).InsertACL), arg0)
+}
+
+// InsertAuthorKey mocks base method
+func (m *MockStore) InsertAuthorKey(arg0 store.AuthorKey) error {
+	ret := m.ctrl.Call(m, "InsertAuthorKey", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAuthorKey indicates an expected call of InsertAuthorKey
+func (mr *MockStoreMockRecorder) InsertAuthorKey(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuthorKey", reflect.TypeOf((*MockStore)(nil).InsertAuthorKey), arg0)
+}
+
+// InsertCheckpoint mocks base method
+func (m *MockStore) InsertCheckpoint(arg0 store.Checkpoint) error {
+	ret := m.ctrl.Call(m, "InsertCheckpoint", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertCheckpoint indicates an expected call of InsertCheckpoint
+func (mr *MockStoreMockRecorder) InsertCheckpoint(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCheckpoint", reflect.TypeOf((*MockStore)(nil).InsertCheckpoint), arg0)
+}
+
+// InsertDelivery mocks base method
+func (m *MockStore) InsertDelivery(arg0 store.Delivery) error {
+	ret := m.ctrl.Call(m, "InsertDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertDelivery indicates an expected call of InsertDelivery
+func (mr *MockStoreMockRecorder) InsertDelivery(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDelivery", reflect.TypeOf((*MockStore)(nil).InsertDelivery), arg0)
+}
+
+// InsertDeliveryAttempt mocks base method
+func (m *MockStore) InsertDeliveryAttempt(arg0 store.DeliveryAttempt) error {
+	ret := m.ctrl.Call(m, "InsertDeliveryAttempt", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertDeliveryAttempt indicates an expected call of InsertDeliveryAttempt
+func (mr *MockStoreMockRecorder) InsertDeliveryAttempt(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDeliveryAttempt", reflect.TypeOf((*MockStore)(nil).InsertDeliveryAttempt), arg0)
+}
+
+// InsertKey mocks base method
+func (m *MockStore) InsertKey(arg0 store.Key) error {
+	ret := m.ctrl.Call(m, "InsertKey", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertKey indicates an expected call of InsertKey
+func (mr *MockStoreMockRecorder) InsertKey(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertKey", reflect.TypeOf((*MockStore)(nil).InsertKey), arg0)
+}
+
+// InsertLedgers mocks base method
+func (m *MockStore) InsertLedgers(arg0 []store.Entity, arg1 []store.ACL) error {
+	ret := m.ctrl.Call(m, "InsertLedgers", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertLedgers indicates an expected call of InsertLedgers
+func (mr *MockStoreMockRecorder) InsertLedgers(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLedgers", reflect.TypeOf((*MockStore)(nil).InsertLedgers), arg0, arg1)
+}
+
+// InsertNodes mocks base method
+func (m *MockStore) InsertNodes(arg0 []store.Node) error {
+	ret := m.ctrl.Call(m, "InsertNodes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertNodes indicates an expected call of InsertNodes
+func (mr *MockStoreMockRecorder) InsertNodes(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNodes", reflect.TypeOf((*MockStore)(nil).InsertNodes), arg0)
+}
+
+// InsertOutboxCheckpoint mocks base method
+func (m *MockStore) InsertOutboxCheckpoint(arg0 string, arg1 int64) error {
+	ret := m.ctrl.Call(m, "InsertOutboxCheckpoint", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertOutboxCheckpoint indicates an expected call of InsertOutboxCheckpoint
+func (mr *MockStoreMockRecorder) InsertOutboxCheckpoint(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOutboxCheckpoint", reflect.TypeOf((*MockStore)(nil).InsertOutboxCheckpoint), arg0, arg1)
+}
+
+// InsertQuota mocks base method
+func (m *MockStore) InsertQuota(arg0 store.Quota) error {
+	ret := m.ctrl.Call(m, "InsertQuota", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertQuota indicates an expected call of InsertQuota
+func (mr *MockStoreMockRecorder) InsertQuota(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertQuota", reflect.TypeOf((*MockStore)(nil).InsertQuota), arg0)
+}
+
+// InsertWebhook mocks base method
+func (m *MockStore) InsertWebhook(arg0 store.Webhook) error {
+	ret := m.ctrl.Call(m, "InsertWebhook", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWebhook indicates an expected call of InsertWebhook
+func (mr *MockStoreMockRecorder) InsertWebhook(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWebhook", reflect.TypeOf((*MockStore)(nil).InsertWebhook), arg0)
+}
+
+// LockOutboxRelay mocks base method
+func (m *MockStore) LockOutboxRelay(arg0 func() error) (bool, error) {
+	ret := m.ctrl.Call(m, "LockOutboxRelay", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockOutboxRelay indicates an expected call of LockOutboxRelay
+func (mr *MockStoreMockRecorder) LockOutboxRelay(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOutboxRelay", reflect.TypeOf((*MockStore)(nil).LockOutboxRelay), arg0)
+}
+
 // Run mocks base method
 func (m *MockStore) Run() error {
 	ret := m.ctrl.Call(m, "Run")
@@ -83,17 +291,303 @@ func (mr *MockStoreMockRecorder) Select(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockStore)(nil).Select), arg0, arg1)
 }
 
+// SelectACL mocks base method
+func (m *MockStore) SelectACL(arg0 uuid.UUID, arg1 store.Query) (store.ACL, error) {
+	ret := m.ctrl.Call(m, "SelectACL", arg0, arg1)
+	ret0, _ := ret[0].(store.ACL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectACL indicates an expected call of SelectACL
+func (mr *MockStoreMockRecorder) SelectACL(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectACL", reflect.TypeOf((*MockStore)(nil).SelectACL), arg0, arg1)
+}
+
+// SelectACLRevisions mocks base method
+func (m *MockStore) SelectACLRevisions(arg0 uuid.UUID, arg1 store.Query) ([]store.ACL, error) {
+	ret := m.ctrl.Call(m, "SelectACLRevisions", arg0, arg1)
+	ret0, _ := ret[0].([]store.ACL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectACLRevisions indicates an expected call of SelectACLRevisions
+func (mr *MockStoreMockRecorder) SelectACLRevisions(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectACLRevisions", reflect.TypeOf((*MockStore)(nil).SelectACLRevisions), arg0, arg1)
+}
+
+// SelectAddressKeys mocks base method
+func (m *MockStore) SelectAddressKeys(arg0, arg1 string) ([]store.Key, error) {
+	ret := m.ctrl.Call(m, "SelectAddressKeys", arg0, arg1)
+	ret0, _ := ret[0].([]store.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAddressKeys indicates an expected call of SelectAddressKeys
+func (mr *MockStoreMockRecorder) SelectAddressKeys(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAddressKeys", reflect.TypeOf((*MockStore)(nil).SelectAddressKeys), arg0, arg1)
+}
+
+// SelectAuthorKey mocks base method
+func (m *MockStore) SelectAuthorKey(arg0, arg1, arg2 string) (store.AuthorKey, error) {
+	ret := m.ctrl.Call(m, "SelectAuthorKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(store.AuthorKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAuthorKey indicates an expected call of SelectAuthorKey
+func (mr *MockStoreMockRecorder) SelectAuthorKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAuthorKey", reflect.TypeOf((*MockStore)(nil).SelectAuthorKey), arg0, arg1, arg2)
+}
+
+// SelectAuthorKeys mocks base method
+func (m *MockStore) SelectAuthorKeys(arg0, arg1 string) ([]store.AuthorKey, error) {
+	ret := m.ctrl.Call(m, "SelectAuthorKeys", arg0, arg1)
+	ret0, _ := ret[0].([]store.AuthorKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAuthorKeys indicates an expected call of SelectAuthorKeys
+func (mr *MockStoreMockRecorder) SelectAuthorKeys(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAuthorKeys", reflect.TypeOf((*MockStore)(nil).SelectAuthorKeys), arg0, arg1)
+}
+
+// SelectAuthorResources mocks base method
+func (m *MockStore) SelectAuthorResources(arg0 string, arg1 store.Query) ([]uuid.UUID, error) {
+	ret := m.ctrl.Call(m, "SelectAuthorResources", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAuthorResources indicates an expected call of SelectAuthorResources
+func (mr *MockStoreMockRecorder) SelectAuthorResources(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAuthorResources", reflect.TypeOf((*MockStore)(nil).SelectAuthorResources), arg0, arg1)
+}
+
+// SelectAddressResources mocks base method
+func (m *MockStore) SelectAddressResources(arg0, arg1 string) ([]uuid.UUID, error) {
+	ret := m.ctrl.Call(m, "SelectAddressResources", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAddressResources indicates an expected call of SelectAddressResources
+func (mr *MockStoreMockRecorder) SelectAddressResources(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAddressResources", reflect.TypeOf((*MockStore)(nil).SelectAddressResources), arg0, arg1)
+}
+
+// SelectResources mocks base method
+func (m *MockStore) SelectResources(arg0 store.Query) ([]uuid.UUID, error) {
+	ret := m.ctrl.Call(m, "SelectResources", arg0)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectResources indicates an expected call of SelectResources
+func (mr *MockStoreMockRecorder) SelectResources(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectResources", reflect.TypeOf((*MockStore)(nil).SelectResources), arg0)
+}
+
+// SelectCheckpoint mocks base method
+func (m *MockStore) SelectCheckpoint(arg0 int64) (store.Checkpoint, error) {
+	ret := m.ctrl.Call(m, "SelectCheckpoint", arg0)
+	ret0, _ := ret[0].(store.Checkpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectCheckpoint indicates an expected call of SelectCheckpoint
+func (mr *MockStoreMockRecorder) SelectCheckpoint(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectCheckpoint", reflect.TypeOf((*MockStore)(nil).SelectCheckpoint), arg0)
+}
+
+// SelectDeliveries mocks base method
+func (m *MockStore) SelectDeliveries(arg0, arg1 string) ([]store.Delivery, error) {
+	ret := m.ctrl.Call(m, "SelectDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]store.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectDeliveries indicates an expected call of SelectDeliveries
+func (mr *MockStoreMockRecorder) SelectDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectDeliveries", reflect.TypeOf((*MockStore)(nil).SelectDeliveries), arg0, arg1)
+}
+
+// SelectDelivery mocks base method
+func (m *MockStore) SelectDelivery(arg0 string, arg1 uuid.UUID) (store.Delivery, error) {
+	ret := m.ctrl.Call(m, "SelectDelivery", arg0, arg1)
+	ret0, _ := ret[0].(store.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectDelivery indicates an expected call of SelectDelivery
+func (mr *MockStoreMockRecorder) SelectDelivery(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectDelivery", reflect.TypeOf((*MockStore)(nil).SelectDelivery), arg0, arg1)
+}
+
+// SelectDeliveryAttempts mocks base method
+func (m *MockStore) SelectDeliveryAttempts(arg0 uuid.UUID) ([]store.DeliveryAttempt, error) {
+	ret := m.ctrl.Call(m, "SelectDeliveryAttempts", arg0)
+	ret0, _ := ret[0].([]store.DeliveryAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectDeliveryAttempts indicates an expected call of SelectDeliveryAttempts
+func (mr *MockStoreMockRecorder) SelectDeliveryAttempts(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectDeliveryAttempts", reflect.TypeOf((*MockStore)(nil).SelectDeliveryAttempts), arg0)
+}
+
+// SelectEntity mocks base method
+func (m *MockStore) SelectEntity(arg0 uuid.UUID, arg1 store.Query) (store.Entity, error) {
+	ret := m.ctrl.Call(m, "SelectEntity", arg0, arg1)
+	ret0, _ := ret[0].(store.Entity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectEntity indicates an expected call of SelectEntity
+func (mr *MockStoreMockRecorder) SelectEntity(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectEntity", reflect.TypeOf((*MockStore)(nil).SelectEntity), arg0, arg1)
+}
+
 // SelectForkRevisions mocks base method
-func (m *MockStore) SelectForkRevisions(arg0 uuid.UUID) ([]store.Entity, error) {
-	ret := m.ctrl.Call(m, "SelectForkRevisions", arg0)
+func (m *MockStore) SelectForkRevisions(arg0 uuid.UUID, arg1 store.Query) ([]store.Entity, error) {
+	ret := m.ctrl.Call(m, "SelectForkRevisions", arg0, arg1)
 	ret0, _ := ret[0].([]store.Entity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SelectForkRevisions indicates an expected call of SelectForkRevisions
-func (mr *MockStoreMockRecorder) SelectForkRevisions(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectForkRevisions", reflect.TypeOf((*MockStore)(nil).SelectForkRevisions), arg0)
+func (mr *MockStoreMockRecorder) SelectForkRevisions(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectForkRevisions", reflect.TypeOf((*MockStore)(nil).SelectForkRevisions), arg0, arg1)
+}
+
+// SelectKey mocks base method
+func (m *MockStore) SelectKey(arg0 string, arg1 uuid.UUID, arg2 string) (store.Key, error) {
+	ret := m.ctrl.Call(m, "SelectKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(store.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectKey indicates an expected call of SelectKey
+func (mr *MockStoreMockRecorder) SelectKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectKey", reflect.TypeOf((*MockStore)(nil).SelectKey), arg0, arg1, arg2)
+}
+
+// SelectLeaf mocks base method
+func (m *MockStore) SelectLeaf(arg0 uuid.UUID) (store.Leaf, error) {
+	ret := m.ctrl.Call(m, "SelectLeaf", arg0)
+	ret0, _ := ret[0].(store.Leaf)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectLeaf indicates an expected call of SelectLeaf
+func (mr *MockStoreMockRecorder) SelectLeaf(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectLeaf", reflect.TypeOf((*MockStore)(nil).SelectLeaf), arg0)
+}
+
+// SelectLeaves mocks base method
+func (m *MockStore) SelectLeaves(arg0, arg1 int64) ([]store.Leaf, error) {
+	ret := m.ctrl.Call(m, "SelectLeaves", arg0, arg1)
+	ret0, _ := ret[0].([]store.Leaf)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectLeaves indicates an expected call of SelectLeaves
+func (mr *MockStoreMockRecorder) SelectLeaves(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectLeaves", reflect.TypeOf((*MockStore)(nil).SelectLeaves), arg0, arg1)
+}
+
+// SelectLedgerIDs mocks base method
+func (m *MockStore) SelectLedgerIDs(arg0 []uuid.UUID) ([]uuid.UUID, error) {
+	ret := m.ctrl.Call(m, "SelectLedgerIDs", arg0)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectLedgerIDs indicates an expected call of SelectLedgerIDs
+func (mr *MockStoreMockRecorder) SelectLedgerIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectLedgerIDs", reflect.TypeOf((*MockStore)(nil).SelectLedgerIDs), arg0)
+}
+
+// SelectNodes mocks base method
+func (m *MockStore) SelectNodes(arg0 []store.NodeID) ([]store.Node, error) {
+	ret := m.ctrl.Call(m, "SelectNodes", arg0)
+	ret0, _ := ret[0].([]store.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectNodes indicates an expected call of SelectNodes
+func (mr *MockStoreMockRecorder) SelectNodes(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectNodes", reflect.TypeOf((*MockStore)(nil).SelectNodes), arg0)
+}
+
+// SelectNodesTreeSize mocks base method
+func (m *MockStore) SelectNodesTreeSize() (int64, error) {
+	ret := m.ctrl.Call(m, "SelectNodesTreeSize")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectNodesTreeSize indicates an expected call of SelectNodesTreeSize
+func (mr *MockStoreMockRecorder) SelectNodesTreeSize() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectNodesTreeSize", reflect.TypeOf((*MockStore)(nil).SelectNodesTreeSize))
+}
+
+// SelectOutbox mocks base method
+func (m *MockStore) SelectOutbox(arg0 int64, arg1 int) ([]store.OutboxRecord, error) {
+	ret := m.ctrl.Call(m, "SelectOutbox", arg0, arg1)
+	ret0, _ := ret[0].([]store.OutboxRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectOutbox indicates an expected call of SelectOutbox
+func (mr *MockStoreMockRecorder) SelectOutbox(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectOutbox", reflect.TypeOf((*MockStore)(nil).SelectOutbox), arg0, arg1)
+}
+
+// SelectOutboxCheckpoint mocks base method
+func (m *MockStore) SelectOutboxCheckpoint(arg0 string) (int64, error) {
+	ret := m.ctrl.Call(m, "SelectOutboxCheckpoint", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectOutboxCheckpoint indicates an expected call of SelectOutboxCheckpoint
+func (mr *MockStoreMockRecorder) SelectOutboxCheckpoint(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectOutboxCheckpoint", reflect.TypeOf((*MockStore)(nil).SelectOutboxCheckpoint), arg0)
+}
+
+// SelectQuota mocks base method
+func (m *MockStore) SelectQuota(arg0, arg1 string) (store.Quota, error) {
+	ret := m.ctrl.Call(m, "SelectQuota", arg0, arg1)
+	ret0, _ := ret[0].(store.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectQuota indicates an expected call of SelectQuota
+func (mr *MockStoreMockRecorder) SelectQuota(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectQuota", reflect.TypeOf((*MockStore)(nil).SelectQuota), arg0, arg1)
 }
 
 // SelectRevisions mocks base method
@@ -109,17 +603,43 @@ func (mr *MockStoreMockRecorder) SelectRevisions(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRevisions", reflect.TypeOf((*MockStore)(nil).SelectRevisions), arg0, arg1)
 }
 
+// SelectUsage mocks base method
+func (m *MockStore) SelectUsage(arg0 store.Query, arg1 time.Time) (store.Usage, error) {
+	ret := m.ctrl.Call(m, "SelectUsage", arg0, arg1)
+	ret0, _ := ret[0].(store.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectUsage indicates an expected call of SelectUsage
+func (mr *MockStoreMockRecorder) SelectUsage(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectUsage", reflect.TypeOf((*MockStore)(nil).SelectUsage), arg0, arg1)
+}
+
+// SelectWebhooks mocks base method
+func (m *MockStore) SelectWebhooks(arg0 string) ([]store.Webhook, error) {
+	ret := m.ctrl.Call(m, "SelectWebhooks", arg0)
+	ret0, _ := ret[0].([]store.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectWebhooks indicates an expected call of SelectWebhooks
+func (mr *MockStoreMockRecorder) SelectWebhooks(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectWebhooks", reflect.TypeOf((*MockStore)(nil).SelectWebhooks), arg0)
+}
+
 // Statistics mocks base method
-func (m *MockStore) Statistics() (store.Statistics, error) {
-	ret := m.ctrl.Call(m, "Statistics")
+func (m *MockStore) Statistics(arg0 store.Query) (store.Statistics, error) {
+	ret := m.ctrl.Call(m, "Statistics", arg0)
 	ret0, _ := ret[0].(store.Statistics)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Statistics indicates an expected call of Statistics
-func (mr *MockStoreMockRecorder) Statistics() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statistics", reflect.TypeOf((*MockStore)(nil).Statistics))
+func (mr *MockStoreMockRecorder) Statistics(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statistics", reflect.TypeOf((*MockStore)(nil).Statistics), arg0)
 }
 
 // Stop mocks base method
@@ -131,3 +651,15 @@ func (m *MockStore) Stop() {
 func (mr *MockStoreMockRecorder) Stop() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockStore)(nil).Stop))
 }
+
+// UpdateDelivery mocks base method
+func (m *MockStore) UpdateDelivery(arg0 store.Delivery) error {
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery
+func (mr *MockStoreMockRecorder) UpdateDelivery(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockStore)(nil).UpdateDelivery), arg0)
+}
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"time"
+
 	"github.com/trussle/uuid"
 )
 
@@ -14,15 +16,97 @@ func NewNopStore() Store {
 
 func (nop) Select(resourceID uuid.UUID, query Query) (Entity, error) { return Entity{}, nil }
 func (nop) Insert(entity Entity) error                               { return nil }
+func (nop) InsertLedgers(entities []Entity, acls []ACL) error        { return nil }
+func (nop) SelectEntity(id uuid.UUID, query Query) (Entity, error)   { return Entity{}, nil }
+func (nop) SelectLedgerIDs(ids []uuid.UUID) ([]uuid.UUID, error) {
+	return make([]uuid.UUID, 0), nil
+}
 func (nop) SelectRevisions(resourceID uuid.UUID, query Query) ([]Entity, error) {
 	return make([]Entity, 0), nil
 }
-func (nop) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, error) {
+func (nop) SelectForkRevisions(resourceID uuid.UUID, query Query) ([]Entity, error) {
 	return make([]Entity, 0), nil
 }
-func (nop) Statistics() (Statistics, error) {
-	return Statistics{}, nil
+func (nop) InsertKey(key Key) error { return nil }
+func (nop) SelectKey(tenantID string, resourceID uuid.UUID, resourceAddress string) (Key, error) {
+	return Key{}, nil
+}
+func (nop) SelectAddressKeys(tenantID, resourceAddress string) ([]Key, error) {
+	return make([]Key, 0), nil
+}
+func (nop) DestroyKey(tenantID string, resourceID uuid.UUID, resourceAddress string, destroyedOn time.Time) error {
+	return nil
+}
+func (nop) SelectAuthorResources(authorID string, query Query) ([]uuid.UUID, error) {
+	return make([]uuid.UUID, 0), nil
+}
+func (nop) SelectAddressResources(tenantID, resourceAddress string) ([]uuid.UUID, error) {
+	return make([]uuid.UUID, 0), nil
+}
+func (nop) SelectResources(query Query) ([]uuid.UUID, error) {
+	return make([]uuid.UUID, 0), nil
+}
+func (nop) InsertAuthorKey(key AuthorKey) error { return nil }
+func (nop) SelectAuthorKey(tenantID, authorID, keyID string) (AuthorKey, error) {
+	return AuthorKey{}, nil
+}
+func (nop) SelectAuthorKeys(tenantID, authorID string) ([]AuthorKey, error) {
+	return make([]AuthorKey, 0), nil
+}
+func (nop) InsertACL(acl ACL) error                                  { return nil }
+func (nop) SelectACL(resourceID uuid.UUID, query Query) (ACL, error) { return ACL{}, nil }
+func (nop) SelectACLRevisions(resourceID uuid.UUID, query Query) ([]ACL, error) {
+	return make([]ACL, 0), nil
+}
+func (nop) AppendLeaves() (int64, error) { return 0, nil }
+func (nop) SelectLeaves(start, end int64) ([]Leaf, error) {
+	return make([]Leaf, 0), nil
+}
+func (nop) SelectLeaf(ledgerID uuid.UUID) (Leaf, error)         { return Leaf{}, nil }
+func (nop) InsertNodes(nodes []Node) error                      { return nil }
+func (nop) SelectNodes(ids []NodeID) ([]Node, error)            { return make([]Node, 0), nil }
+func (nop) SelectNodesTreeSize() (int64, error)                 { return 0, nil }
+func (nop) InsertCheckpoint(checkpoint Checkpoint) error        { return nil }
+func (nop) SelectCheckpoint(treeSize int64) (Checkpoint, error) { return Checkpoint{}, nil }
+func (nop) Statistics(query Query) (Statistics, error) {
+	return Statistics{
+		ContentTypes: make(map[string]int64),
+		Revisions:    bucketRevisions(nil),
+		Authors:      make([]Count, 0),
+		Tags:         make([]Count, 0),
+		Inserts:      insertWindows(),
+	}, nil
+}
+func (nop) InsertQuota(quota Quota) error                        { return nil }
+func (nop) SelectQuota(tenantID, authorID string) (Quota, error) { return Quota{}, nil }
+func (nop) SelectUsage(query Query, since time.Time) (Usage, error) {
+	return Usage{}, nil
+}
+func (nop) InsertWebhook(webhook Webhook) error                      { return nil }
+func (nop) SelectWebhooks(tenantID string) ([]Webhook, error)        { return make([]Webhook, 0), nil }
+func (nop) DeleteWebhook(tenantID string, webhookID uuid.UUID) error { return nil }
+func (nop) InsertDelivery(delivery Delivery) error                   { return nil }
+func (nop) UpdateDelivery(delivery Delivery) error                   { return nil }
+func (nop) SelectDelivery(tenantID string, deliveryID uuid.UUID) (Delivery, error) {
+	return Delivery{}, nil
+}
+func (nop) SelectDeliveries(tenantID, status string) ([]Delivery, error) {
+	return make([]Delivery, 0), nil
+}
+func (nop) ClaimPendingDeliveries(before, until time.Time, limit int) ([]Delivery, error) {
+	return make([]Delivery, 0), nil
+}
+func (nop) InsertDeliveryAttempt(attempt DeliveryAttempt) error { return nil }
+func (nop) SelectDeliveryAttempts(deliveryID uuid.UUID) ([]DeliveryAttempt, error) {
+	return make([]DeliveryAttempt, 0), nil
+}
+func (nop) SelectOutbox(after int64, limit int) ([]OutboxRecord, error) {
+	return make([]OutboxRecord, 0), nil
 }
-func (nop) Run() error  { return nil }
-func (nop) Stop()       {}
-func (nop) Drop() error { return nil }
+func (nop) SelectOutboxCheckpoint(sink string) (int64, error)        { return 0, nil }
+func (nop) InsertOutboxCheckpoint(sink string, sequence int64) error { return nil }
+func (nop) DeleteOutbox(through int64) error                         { return nil }
+func (nop) LockOutboxRelay(fn func() error) (bool, error)            { return true, fn() }
+func (nop) Run() error                                               { return nil }
+func (nop) Stop()                                                    {}
+func (nop) Drop() error                                              { return nil }
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// These are the events of an outbox record.
+const (
+	OutboxEventInsert = "insert"
+	OutboxEventAppend = "append"
+	OutboxEventFork   = "fork"
+	OutboxEventDelete = "delete"
+)
+
+// OutboxRecord represents a change to a ledger, that is written with in the
+// same transaction as the ledger itself, so that no change is ever lost. The
+// sequence is assigned by the store and always increases in the order the
+// records are committed. The payload is the JSON encoded ledger.
+type OutboxRecord struct {
+	Sequence   int64
+	Event      string
+	LedgerID   uuid.UUID
+	TenantID   string
+	ResourceID uuid.UUID
+	Payload    []byte
+	CreatedOn  time.Time
+}
+
+// outboxEvent works out the event of the entity from its parent. A entity
+// without a parent is a new resource, a entity with a different resource to
+// its parent is a fork.
+func outboxEvent(entity Entity, parentResourceID uuid.UUID) string {
+	switch {
+	case entity.ParentID.Zero():
+		return OutboxEventInsert
+	case !entity.DeletedOn.IsZero():
+		return OutboxEventDelete
+	case !parentResourceID.Zero() && !parentResourceID.Equals(entity.ResourceID):
+		return OutboxEventFork
+	default:
+		return OutboxEventAppend
+	}
+}
+
+// newOutboxRecord creates the outbox record for a entity that is being
+// inserted, once the id and hash of the entity are known.
+func newOutboxRecord(entity Entity, event string, createdOn time.Time) (OutboxRecord, error) {
+	var deletedOn string
+	if !entity.DeletedOn.IsZero() {
+		deletedOn = entity.DeletedOn.Format(time.RFC3339)
+	}
+
+	payload, err := json.Marshal(struct {
+		ID                  string   `json:"id"`
+		ParentID            string   `json:"parent_id"`
+		TenantID            string   `json:"tenant"`
+		Name                string   `json:"name"`
+		ResourceID          string   `json:"resource_id"`
+		ResourceAddress     string   `json:"resource_address"`
+		ResourceSize        int64    `json:"resource_size"`
+		ResourceContentType string   `json:"resource_content_type"`
+		AuthorID            string   `json:"author_id"`
+		Tags                []string `json:"tags"`
+		CreatedOn           string   `json:"created_on"`
+		DeletedOn           string   `json:"deleted_on"`
+		Hash                string   `json:"hash"`
+		Signature           []byte   `json:"signature,omitempty"`
+		SignatureKeyID      string   `json:"signature_key_id,omitempty"`
+	}{
+		ID:                  entity.ID.String(),
+		ParentID:            entity.ParentID.String(),
+		TenantID:            entity.TenantID,
+		Name:                entity.Name,
+		ResourceID:          entity.ResourceID.String(),
+		ResourceAddress:     entity.ResourceAddress,
+		ResourceSize:        entity.ResourceSize,
+		ResourceContentType: entity.ResourceContentType,
+		AuthorID:            entity.AuthorID,
+		Tags:                sortTags(entity.Tags),
+		CreatedOn:           entity.CreatedOn.Format(time.RFC3339),
+		DeletedOn:           deletedOn,
+		Hash:                entity.Hash,
+		Signature:           entity.Signature,
+		SignatureKeyID:      entity.SignatureKeyID,
+	})
+	if err != nil {
+		return OutboxRecord{}, err
+	}
+
+	return OutboxRecord{
+		Event:      event,
+		LedgerID:   entity.ID,
+		TenantID:   entity.TenantID,
+		ResourceID: entity.ResourceID,
+		Payload:    payload,
+		CreatedOn:  createdOn,
+	}, nil
+}
@@ -0,0 +1,24 @@
+package store
+
+import "time"
+
+// Quota represents the storage limits of an author with in a tenant, or of
+// the whole tenant when the author is empty. A limit of zero means that there
+// is no limit.
+type Quota struct {
+	TenantID           string
+	AuthorID           string
+	MaxBytes           int64
+	MaxResources       int64
+	MaxRevisionsPerDay int64
+	UpdatedOn          time.Time
+}
+
+// Usage represents the storage used by an author with in a tenant, or by the
+// whole tenant. Bytes counts the size of each distinct resource address and
+// Revisions only counts the revisions created since the time requested.
+type Usage struct {
+	Bytes     int64
+	Resources int64
+	Revisions int64
+}
@@ -18,6 +18,7 @@ import (
 const (
 	defaultSelectQuery = `SELECT id,
 	parent_id,
+	tenant_id,
 	name,
 	resource_id,
 	resource_address,
@@ -26,13 +27,36 @@ const (
 	author_id,
 	tags,
 	created_on,
-	deleted_on
+	deleted_on,
+	hash,
+	signature,
+	signature_key_id
 FROM   ledgers
 WHERE  resource_id = $1
+	AND tenant_id = $2
 ORDER  BY created_on DESC,
 		 resource_address DESC;`
+	defaultSelectEntityQuery = `SELECT id,
+	parent_id,
+	tenant_id,
+	name,
+	resource_id,
+	resource_address,
+	resource_size,
+	resource_content_type,
+	author_id,
+	tags,
+	created_on,
+	deleted_on,
+	hash,
+	signature,
+	signature_key_id
+FROM   ledgers
+WHERE  id = $1
+	AND tenant_id = $2;`
 	defaultInsertQuery = `INSERT INTO ledgers
-	(parent_id,
+	(id,
+	 parent_id,
 	 name,
 	 resource_id,
 	 resource_address,
@@ -41,7 +65,11 @@ ORDER  BY created_on DESC,
 	 author_id,
 	 tags,
 	 created_on,
-	 deleted_on)
+	 deleted_on,
+	 hash,
+	 signature,
+	 signature_key_id,
+	 tenant_id)
 VALUES      ($1,
 	 $2,
 	 $3,
@@ -51,9 +79,20 @@ VALUES      ($1,
 	 $7,
 	 $8,
 	 $9,
-	 $10);`
+	 $10,
+	 $11,
+	 $12,
+	 $13,
+	 $14,
+	 $15);`
+	defaultSelectHashQuery = `SELECT hash,
+	resource_id
+FROM   ledgers
+WHERE  id = $1
+	AND tenant_id = $2;`
 	defaultSelectQueryTags = `SELECT id,
 	parent_id,
+	tenant_id,
 	name,
 	resource_id,
 	resource_address,
@@ -62,14 +101,19 @@ VALUES      ($1,
 	author_id,
 	tags,
 	created_on,
-	deleted_on
+	deleted_on,
+	hash,
+	signature,
+	signature_key_id
 FROM   ledgers
 WHERE  resource_id = $1
-	AND (tags = '{}' OR tags && $2)
+	AND tenant_id = $2
+	AND (tags = '{}' OR tags && $3)
 ORDER  BY created_on DESC,
 		 resource_address DESC;`
 	defaultSelectQueryTagsAuthorID = `SELECT id,
 	parent_id,
+	tenant_id,
 	name,
 	resource_id,
 	resource_address,
@@ -78,11 +122,15 @@ ORDER  BY created_on DESC,
 	author_id,
 	tags,
 	created_on,
-	deleted_on
+	deleted_on,
+	hash,
+	signature,
+	signature_key_id
 FROM   ledgers
 WHERE  resource_id = $1
-	AND author_id = $2
-	AND (tags = '{}' OR tags && $3)
+	AND tenant_id = $2
+	AND author_id = $3
+	AND (tags = '{}' OR tags && $4)
 ORDER  BY created_on DESC,
 		 resource_address DESC;`
 	defaultForkSelectQuery = `WITH recursive tree AS
@@ -104,6 +152,7 @@ ORDER  BY created_on DESC,
 	WHERE  id = $2;`
 	defaultForkSelectRevisionsQuery = `SELECT id,
 	parent_id,
+	tenant_id,
 	name,
 	resource_id,
 	resource_address,
@@ -112,12 +161,502 @@ ORDER  BY created_on DESC,
 	author_id,
 	tags,
 	created_on,
-	deleted_on
+	deleted_on,
+	hash,
+	signature,
+	signature_key_id
 FROM   ledgers
 WHERE id = ANY($1)
+	AND tenant_id = $2
+ORDER  BY created_on ASC;`
+	defaultInsertKeyQuery = `INSERT INTO ledger_keys
+	(tenant_id,
+	 resource_id,
+	 resource_address,
+	 key_id,
+	 wrapped_key,
+	 created_on,
+	 destroyed_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4,
+	 $5,
+	 $6,
+	 $7)
+ON CONFLICT (tenant_id, resource_id, resource_address) DO UPDATE
+SET    key_id = EXCLUDED.key_id,
+	wrapped_key = EXCLUDED.wrapped_key,
+	created_on = EXCLUDED.created_on,
+	destroyed_on = EXCLUDED.destroyed_on
+WHERE  ledger_keys.destroyed_on <> $8;`
+	defaultSelectKeyQuery = `SELECT tenant_id,
+	resource_id,
+	resource_address,
+	key_id,
+	wrapped_key,
+	created_on,
+	destroyed_on
+FROM   ledger_keys
+WHERE  tenant_id = $1
+	AND resource_id = $2
+	AND resource_address = $3;`
+	defaultSelectAddressKeysQuery = `SELECT tenant_id,
+	resource_id,
+	resource_address,
+	key_id,
+	wrapped_key,
+	created_on,
+	destroyed_on
+FROM   ledger_keys
+WHERE  tenant_id = $1
+	AND resource_address = $2
+ORDER  BY created_on ASC, resource_id ASC;`
+	defaultDestroyKeyQuery = `INSERT INTO ledger_keys
+	(tenant_id,
+	 resource_id,
+	 resource_address,
+	 key_id,
+	 wrapped_key,
+	 created_on,
+	 destroyed_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 '',
+	 '',
+	 $4,
+	 $4)
+ON CONFLICT (tenant_id, resource_id, resource_address) DO UPDATE
+SET    key_id = '',
+	wrapped_key = '',
+	destroyed_on = $4
+WHERE  ledger_keys.destroyed_on = $5;`
+	defaultSelectAuthorResourcesQuery = `SELECT DISTINCT resource_id
+FROM   ledgers
+WHERE  author_id = $1
+	AND tenant_id = $2;`
+	defaultSelectLedgerIDsQuery = `SELECT id
+FROM   ledgers
+WHERE  id = ANY($1);`
+	defaultSelectAddressResourcesQuery = `SELECT DISTINCT resource_id
+FROM   ledgers
+WHERE  tenant_id = $1
+	AND resource_address = $2;`
+	defaultSelectResourcesQuery = `SELECT resource_id
+FROM   ledgers
+WHERE  tenant_id = $1
+	AND resource_id IN (SELECT resource_id
+		FROM   ledgers
+		WHERE  tenant_id = $1
+			AND ( $2 = '' OR author_id = $2 )
+			AND ( cardinality($3::text[]) = 0 OR tags && $3 ))
+GROUP  BY resource_id
+ORDER  BY MIN(created_on) ASC,
+	resource_id ASC;`
+	defaultLockLeavesQuery   = `LOCK TABLE ledger_leaves IN EXCLUSIVE MODE;`
+	defaultAppendLeavesQuery = `INSERT INTO ledger_leaves
+	(leaf_index,
+	 ledger_id,
+	 hash)
+SELECT (SELECT COALESCE(MAX(leaf_index) + 1, 0)
+	FROM   ledger_leaves)
+	+ ROW_NUMBER() OVER (ORDER BY created_on ASC, id ASC) - 1,
+	id,
+	hash
+FROM   ledgers
+WHERE  NOT EXISTS (SELECT 1
+	FROM   ledger_leaves
+	WHERE  ledger_leaves.ledger_id = ledgers.id);`
+	defaultTreeSizeQuery     = `SELECT COUNT(*) FROM ledger_leaves;`
+	defaultSelectLeavesQuery = `SELECT leaf_index,
+	ledger_id,
+	hash
+FROM   ledger_leaves
+WHERE  leaf_index >= $1
+	AND leaf_index < $2
+ORDER  BY leaf_index ASC;`
+	defaultSelectLeafQuery = `SELECT leaf_index,
+	ledger_id,
+	hash
+FROM   ledger_leaves
+WHERE  ledger_id = $1;`
+	defaultInsertNodesQuery = `INSERT INTO ledger_tree_nodes
+	(level,
+	 node_index,
+	 hash)
+SELECT *
+FROM   unnest($1::INT[], $2::BIGINT[], $3::BYTEA[])
+ON CONFLICT (level, node_index) DO NOTHING;`
+	defaultSelectNodesQuery = `SELECT level,
+	node_index,
+	hash
+FROM   ledger_tree_nodes
+WHERE  (level, node_index) IN (SELECT *
+	FROM   unnest($1::INT[], $2::BIGINT[]));`
+	defaultSelectNodesTreeSizeQuery = `SELECT COALESCE(MAX(node_index) + 1, 0)
+FROM   ledger_tree_nodes
+WHERE  level = 0;`
+	defaultInsertCheckpointQuery = `INSERT INTO ledger_checkpoints
+	(tree_size,
+	 root_hash,
+	 key_id,
+	 signature,
+	 created_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4,
+	 $5)
+ON CONFLICT (tree_size) DO NOTHING;`
+	defaultSelectCheckpointQuery = `SELECT tree_size,
+	root_hash,
+	key_id,
+	signature,
+	created_on
+FROM   ledger_checkpoints
+WHERE  tree_size = $1;`
+	defaultSelectLatestCheckpointQuery = `SELECT tree_size,
+	root_hash,
+	key_id,
+	signature,
+	created_on
+FROM   ledger_checkpoints
+ORDER  BY tree_size DESC
+LIMIT  1;`
+	defaultInsertAuthorKeyQuery = `INSERT INTO author_keys
+	(tenant_id,
+	 author_id,
+	 key_id,
+	 public_key,
+	 created_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4,
+	 $5)
+ON CONFLICT (tenant_id, author_id, key_id) DO NOTHING;`
+	defaultSelectAuthorKeyQuery = `SELECT tenant_id,
+	author_id,
+	key_id,
+	public_key,
+	created_on
+FROM   author_keys
+WHERE  tenant_id = $1
+	AND author_id = $2
+	AND key_id = $3;`
+	defaultSelectAuthorKeysQuery = `SELECT tenant_id,
+	author_id,
+	key_id,
+	public_key,
+	created_on
+FROM   author_keys
+WHERE  tenant_id = $1
+	AND author_id = $2
+ORDER  BY created_on ASC;`
+	defaultInsertACLQuery = `INSERT INTO ledger_acls
+	(tenant_id,
+	 resource_id,
+	 owner,
+	 readers,
+	 writers,
+	 updated_by,
+	 created_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4,
+	 $5,
+	 $6,
+	 $7);`
+	defaultSelectACLQuery = `SELECT tenant_id,
+	resource_id,
+	owner,
+	readers,
+	writers,
+	updated_by,
+	created_on
+FROM   ledger_acls
+WHERE  resource_id = $1
+	AND tenant_id = $2
+ORDER  BY id DESC
+LIMIT  1;`
+	defaultSelectACLRevisionsQuery = `SELECT tenant_id,
+	resource_id,
+	owner,
+	readers,
+	writers,
+	updated_by,
+	created_on
+FROM   ledger_acls
+WHERE  resource_id = $1
+	AND tenant_id = $2
+ORDER  BY id ASC;`
+	defaultInsertQuotaQuery = `INSERT INTO ledger_quotas
+	(tenant_id,
+	 author_id,
+	 max_bytes,
+	 max_resources,
+	 max_revisions_per_day,
+	 updated_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4,
+	 $5,
+	 $6)
+ON CONFLICT (tenant_id, author_id) DO UPDATE
+SET    max_bytes = EXCLUDED.max_bytes,
+	max_resources = EXCLUDED.max_resources,
+	max_revisions_per_day = EXCLUDED.max_revisions_per_day,
+	updated_on = EXCLUDED.updated_on;`
+	defaultSelectQuotaQuery = `SELECT tenant_id,
+	author_id,
+	max_bytes,
+	max_resources,
+	max_revisions_per_day,
+	updated_on
+FROM   ledger_quotas
+WHERE  tenant_id = $1
+	AND author_id = $2;`
+	defaultSelectUsageQuery = `SELECT (SELECT COALESCE(SUM(resource_size), 0)
+	FROM   (SELECT DISTINCT resource_address,
+		resource_size
+	FROM   ledgers
+	WHERE  tenant_id = $1
+		AND ( $2 = '' OR author_id = $2 )) AS addresses),
+	COUNT(DISTINCT resource_id),
+	COALESCE(SUM(CASE WHEN created_on >= $3 THEN 1 ELSE 0 END), 0)
+FROM   ledgers
+WHERE  tenant_id = $1
+	AND ( $2 = '' OR author_id = $2 );`
+	defaultInsertWebhookQuery = `INSERT INTO webhooks
+	(id,
+	 tenant_id,
+	 url,
+	 secret,
+	 resource_id,
+	 tag,
+	 author_id,
+	 events,
+	 owner,
+	 created_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4,
+	 $5,
+	 $6,
+	 $7,
+	 $8,
+	 $9,
+	 $10);`
+	defaultSelectWebhooksQuery = `SELECT id,
+	tenant_id,
+	url,
+	secret,
+	resource_id,
+	tag,
+	author_id,
+	events,
+	owner,
+	created_on
+FROM   webhooks
+WHERE  tenant_id = $1
+ORDER  BY created_on ASC;`
+	defaultDeleteWebhookQuery = `DELETE FROM webhooks
+WHERE  tenant_id = $1
+	AND id = $2;`
+	defaultInsertDeliveryQuery = `INSERT INTO webhook_deliveries
+	(id,
+	 webhook_id,
+	 tenant_id,
+	 event,
+	 payload,
+	 status,
+	 attempts,
+	 last_error,
+	 next_attempt_on,
+	 created_on,
+	 delivered_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4,
+	 $5,
+	 $6,
+	 $7,
+	 $8,
+	 $9,
+	 $10,
+	 $11);`
+	defaultUpdateDeliveryQuery = `UPDATE webhook_deliveries
+SET    status = $2,
+	attempts = $3,
+	last_error = $4,
+	next_attempt_on = $5,
+	delivered_on = $6
+WHERE  id = $1;`
+	defaultSelectDeliveryQuery = `SELECT id,
+	webhook_id,
+	tenant_id,
+	event,
+	payload,
+	status,
+	attempts,
+	last_error,
+	next_attempt_on,
+	created_on,
+	delivered_on
+FROM   webhook_deliveries
+WHERE  tenant_id = $1
+	AND id = $2;`
+	defaultSelectDeliveriesQuery = `SELECT id,
+	webhook_id,
+	tenant_id,
+	event,
+	payload,
+	status,
+	attempts,
+	last_error,
+	next_attempt_on,
+	created_on,
+	delivered_on
+FROM   webhook_deliveries
+WHERE  tenant_id = $1
+	AND status = $2
 ORDER  BY created_on ASC;`
-	defaultStatisticsQuery = `SELECT COUNT(*) FROM ledgers;`
-	defaultDropQuery       = `TRUNCATE TABLE ledgers;`
+	defaultClaimPendingDeliveriesQuery = `UPDATE webhook_deliveries d
+SET    next_attempt_on = $3
+FROM   webhooks w
+WHERE  w.id = d.webhook_id
+	AND d.id IN (SELECT pending.id
+		FROM   webhook_deliveries pending
+			INNER JOIN webhooks hooks
+				ON hooks.id = pending.webhook_id
+		WHERE  pending.status = $1
+			AND pending.next_attempt_on <= $2
+		ORDER  BY pending.next_attempt_on ASC
+		LIMIT  $4
+		FOR UPDATE OF pending SKIP LOCKED)
+RETURNING d.id,
+	d.webhook_id,
+	d.tenant_id,
+	d.event,
+	d.payload,
+	d.status,
+	d.attempts,
+	d.last_error,
+	d.next_attempt_on,
+	d.created_on,
+	d.delivered_on,
+	w.url,
+	w.secret;`
+	defaultInsertDeliveryAttemptQuery = `INSERT INTO webhook_attempts
+	(delivery_id,
+	 attempted_on,
+	 status_code,
+	 error)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4);`
+	defaultSelectDeliveryAttemptsQuery = `SELECT delivery_id,
+	attempted_on,
+	status_code,
+	error
+FROM   webhook_attempts
+WHERE  delivery_id = $1
+ORDER  BY id ASC;`
+	defaultLockOutboxQuery      = `SELECT pg_advisory_xact_lock(hashtext('ledger_outbox'));`
+	defaultLockOutboxRelayQuery = `SELECT pg_try_advisory_xact_lock(hashtext('ledger_outbox_relay'));`
+	defaultInsertOutboxQuery    = `INSERT INTO ledger_outbox
+	(event,
+	 ledger_id,
+	 tenant_id,
+	 resource_id,
+	 payload,
+	 created_on)
+VALUES      ($1,
+	 $2,
+	 $3,
+	 $4,
+	 $5,
+	 $6);`
+	defaultSelectOutboxQuery = `SELECT sequence,
+	event,
+	ledger_id,
+	tenant_id,
+	resource_id,
+	payload,
+	created_on
+FROM   ledger_outbox
+WHERE  sequence > $1
+ORDER  BY sequence ASC
+LIMIT  $2;`
+	defaultSelectOutboxCheckpointQuery = `SELECT sequence
+FROM   ledger_outbox_checkpoints
+WHERE  sink = $1;`
+	defaultInsertOutboxCheckpointQuery = `INSERT INTO ledger_outbox_checkpoints
+	(sink,
+	 sequence,
+	 updated_on)
+VALUES      ($1,
+	 $2,
+	 $3)
+ON CONFLICT (sink) DO UPDATE
+SET    sequence = $2,
+	updated_on = $3;`
+	defaultDeleteOutboxQuery = `DELETE FROM ledger_outbox
+WHERE  sequence <= $1;`
+	defaultStatisticsRevisionsQuery = `SELECT revisions,
+	COUNT(*)
+FROM   (SELECT COUNT(*) AS revisions
+	FROM   ledgers
+	WHERE  tenant_id = $1
+		AND ( $2 = '' OR author_id = $2 )
+		AND ( cardinality($3::text[]) = 0 OR tags && $3 )
+	GROUP  BY resource_id) AS resources
+GROUP  BY revisions;`
+	defaultStatisticsContentTypesQuery = `SELECT resource_content_type,
+	COALESCE(SUM(resource_size), 0)
+FROM   (SELECT DISTINCT ON (resource_address) resource_address,
+		resource_content_type,
+		resource_size
+	FROM   ledgers
+	WHERE  tenant_id = $1
+		AND ( $2 = '' OR author_id = $2 )
+		AND ( cardinality($3::text[]) = 0 OR tags && $3 )
+	ORDER  BY resource_address) AS addresses
+GROUP  BY resource_content_type;`
+	defaultStatisticsAuthorsQuery = `SELECT author_id,
+	COUNT(*)
+FROM   ledgers
+WHERE  tenant_id = $1
+	AND ( $2 = '' OR author_id = $2 )
+	AND ( cardinality($3::text[]) = 0 OR tags && $3 )
+GROUP  BY author_id
+ORDER  BY COUNT(*) DESC,
+	author_id
+LIMIT  $4;`
+	defaultStatisticsTagsQuery = `SELECT tag,
+	COUNT(*)
+FROM   ledgers,
+	unnest(tags) AS tag
+WHERE  tenant_id = $1
+	AND ( $2 = '' OR author_id = $2 )
+	AND ( cardinality($3::text[]) = 0 OR tags && $3 )
+GROUP  BY tag
+ORDER  BY COUNT(*) DESC,
+	tag
+LIMIT  $4;`
+	defaultStatisticsInsertsQuery = `SELECT COUNT(*)
+FROM   ledgers
+WHERE  tenant_id = $1
+	AND ( $2 = '' OR author_id = $2 )
+	AND ( cardinality($3::text[]) = 0 OR tags && $3 )
+	AND created_on >= $4;`
+	defaultDropQuery = `TRUNCATE TABLE ledgers, ledger_keys, ledger_leaves, ledger_tree_nodes, ledger_checkpoints, author_keys, ledger_acls, ledger_quotas, webhooks, webhook_deliveries, webhook_attempts, ledger_outbox, ledger_outbox_checkpoints;`
 )
 
 // RealConfig holds the options for connecting to the DB
@@ -148,16 +687,26 @@ func NewRealStore(config *RealConfig, logger log.Logger) Store {
 }
 
 func (r *realStore) Select(resource uuid.UUID, query Query) (Entity, error) {
+	statement, args := buildSQLFromQuery(resource, query)
+	return scanEntity(r.db.QueryRow(statement, args...))
+}
+
+func (r *realStore) SelectEntity(id uuid.UUID, query Query) (Entity, error) {
+	return scanEntity(r.db.QueryRow(defaultSelectEntityQuery, id.String(), query.Tenant))
+}
+
+// scanEntity scans a single ledger row, returning a not found error if there
+// is no row.
+func scanEntity(row scanner) (Entity, error) {
 	var (
-		entity          Entity
-		statement, args = buildSQLFromQuery(resource, query)
-		row             = r.db.QueryRow(statement, args...)
+		entity Entity
 
 		id, parentID, resourceID string
 	)
 	err := row.Scan(
 		&id,
 		&parentID,
+		&entity.TenantID,
 		&entity.Name,
 		&resourceID,
 		&entity.ResourceAddress,
@@ -167,6 +716,9 @@ func (r *realStore) Select(resource uuid.UUID, query Query) (Entity, error) {
 		pq.Array(&entity.Tags),
 		&entity.CreatedOn,
 		&entity.DeletedOn,
+		&entity.Hash,
+		&entity.Signature,
+		&entity.SignatureKeyID,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -192,34 +744,146 @@ func (r *realStore) Select(resource uuid.UUID, query Query) (Entity, error) {
 
 func (r *realStore) Insert(entity Entity) error {
 	return r.Transaction(func(txn *sql.Tx) error {
-		stmt, err := txn.Prepare(defaultInsertQuery)
-		if err != nil {
-			return err
+		return insertEntity(txn, entity)
+	})
+}
+
+func (r *realStore) InsertLedgers(entities []Entity, acls []ACL) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		for _, entity := range entities {
+			if err := insertEntity(txn, entity); err != nil {
+				return err
+			}
 		}
-		defer stmt.Close()
-
-		// Normalize the tags of the entity
-		tags := sortTags(entity.Tags)
-
-		if _, err = stmt.Exec(
-			entity.ParentID.String(),
-			entity.Name,
-			entity.ResourceID.String(),
-			entity.ResourceAddress,
-			entity.ResourceSize,
-			entity.ResourceContentType,
-			entity.AuthorID,
-			pq.Array(tags),
-			entity.CreatedOn,
-			entity.DeletedOn,
-		); err != nil {
-			return errors.Wrap(err, "unable to exec statement")
+		for _, acl := range acls {
+			if err := insertACL(txn, acl); err != nil {
+				return err
+			}
 		}
-
 		return nil
 	})
 }
 
+// insertEntity inserts the entity, chained to its parent, along with the
+// change in the outbox, with in the transaction.
+func insertEntity(txn *sql.Tx, entity Entity) error {
+	stmt, err := txn.Prepare(defaultInsertQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	// Normalize the tags of the entity
+	tags := sortTags(entity.Tags)
+
+	// Assign an id if there isn't one, so that the entity can be linked
+	// to before it's read back.
+	if entity.ID.Zero() {
+		if entity.ID, err = uuid.New(); err != nil {
+			return err
+		}
+	}
+
+	// Chain the entity to the hash of the parent, with in the same
+	// transaction. The parent has to be in the same tenant, so that a
+	// ledger can never be chained on to the ledger of another tenant, and
+	// it has to exist, otherwise the chain would silently start over.
+	var (
+		parentHash       string
+		parentResourceID uuid.UUID
+	)
+	if !entity.ParentID.Zero() {
+		var resourceID string
+		err = txn.QueryRow(defaultSelectHashQuery, entity.ParentID.String(), entity.TenantID).Scan(&parentHash, &resourceID)
+		if err == sql.ErrNoRows {
+			return errNotFound{errors.Errorf("parent %q not found", entity.ParentID.String())}
+		} else if err != nil {
+			return errors.Wrap(err, "unable to select parent hash")
+		}
+		if parentResourceID, err = uuid.Parse(resourceID); err != nil {
+			return err
+		}
+	}
+	entity.Hash = HashEntity(entity, parentHash)
+
+	if _, err = stmt.Exec(
+		entity.ID.String(),
+		entity.ParentID.String(),
+		entity.Name,
+		entity.ResourceID.String(),
+		entity.ResourceAddress,
+		entity.ResourceSize,
+		entity.ResourceContentType,
+		entity.AuthorID,
+		pq.Array(tags),
+		entity.CreatedOn,
+		entity.DeletedOn,
+		entity.Hash,
+		entity.Signature,
+		entity.SignatureKeyID,
+		entity.TenantID,
+	); err != nil {
+		return errors.Wrap(err, "unable to exec statement")
+	}
+
+	// Write the change to the outbox with in the same transaction, so that
+	// the change is only ever recorded along with the ledger.
+	record, err := newOutboxRecord(entity, outboxEvent(entity, parentResourceID), time.Now())
+	if err != nil {
+		return err
+	}
+	// Serialize the writers of the outbox until the transaction commits,
+	// otherwise a relay could read a later sequence before an earlier one
+	// is committed and skip over it. The lock is global, so every write of a
+	// ledger, in every tenant and on every replica, waits for the writes
+	// before it to commit, which bounds the write throughput by the latency
+	// of a commit.
+	if _, err = txn.Exec(defaultLockOutboxQuery); err != nil {
+		return errors.Wrap(err, "unable to lock outbox")
+	}
+	if _, err = txn.Exec(
+		defaultInsertOutboxQuery,
+		record.Event,
+		record.LedgerID.String(),
+		record.TenantID,
+		record.ResourceID.String(),
+		record.Payload,
+		record.CreatedOn,
+	); err != nil {
+		return errors.Wrap(err, "unable to insert outbox")
+	}
+
+	return nil
+}
+
+func (r *realStore) SelectLedgerIDs(ids []uuid.UUID) ([]uuid.UUID, error) {
+	values := make([]string, len(ids))
+	for k, v := range ids {
+		values[k] = v.String()
+	}
+
+	rows, err := r.db.Query(defaultSelectLedgerIDsQuery, pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	return res, rows.Err()
+}
+
 func (r *realStore) SelectRevisions(resource uuid.UUID, query Query) ([]Entity, error) {
 	var (
 		statement, args = buildSQLFromQuery(resource, query)
@@ -241,6 +905,7 @@ func (r *realStore) SelectRevisions(resource uuid.UUID, query Query) ([]Entity,
 		err := rows.Scan(
 			&id,
 			&parentID,
+			&entity.TenantID,
 			&entity.Name,
 			&resourceID,
 			&entity.ResourceAddress,
@@ -250,6 +915,9 @@ func (r *realStore) SelectRevisions(resource uuid.UUID, query Query) ([]Entity,
 			pq.Array(&entity.Tags),
 			&entity.CreatedOn,
 			&entity.DeletedOn,
+			&entity.Hash,
+			&entity.Signature,
+			&entity.SignatureKeyID,
 		)
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -276,8 +944,8 @@ func (r *realStore) SelectRevisions(resource uuid.UUID, query Query) ([]Entity,
 	return res, rows.Err()
 }
 
-func (r *realStore) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, error) {
-	entity, err := r.Select(resourceID, Query{})
+func (r *realStore) SelectForkRevisions(resourceID uuid.UUID, query Query) ([]Entity, error) {
+	entity, err := r.Select(resourceID, Query{Tenant: query.Tenant})
 	if err != nil {
 		if ErrNotFound(err) {
 			return make([]Entity, 0), nil
@@ -302,7 +970,11 @@ func (r *realStore) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, error)
 		return nil, err
 	}
 
-	rows, err := r.db.Query(defaultForkSelectRevisionsQuery, pq.Array(append(ancestorIDs, entity.ID.String())))
+	rows, err := r.db.Query(
+		defaultForkSelectRevisionsQuery,
+		pq.Array(append(ancestorIDs, entity.ID.String())),
+		query.Tenant,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -318,6 +990,7 @@ func (r *realStore) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, error)
 		err := rows.Scan(
 			&id,
 			&parentID,
+			&entity.TenantID,
 			&entity.Name,
 			&resourceID,
 			&entity.ResourceAddress,
@@ -327,6 +1000,9 @@ func (r *realStore) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, error)
 			pq.Array(&entity.Tags),
 			&entity.CreatedOn,
 			&entity.DeletedOn,
+			&entity.Hash,
+			&entity.Signature,
+			&entity.SignatureKeyID,
 		)
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -353,63 +1029,1022 @@ func (r *realStore) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, error)
 	return res, rows.Err()
 }
 
-func (r *realStore) Transaction(fn func(*sql.Tx) error) (err error) {
-	if r.db == nil {
-		err = errors.New("db not found")
-		return
+func (r *realStore) InsertKey(key Key) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertKeyQuery,
+			key.TenantID,
+			key.ResourceID.String(),
+			key.ResourceAddress,
+			key.KeyID,
+			key.WrappedKey,
+			key.CreatedOn,
+			key.DestroyedOn,
+			time.Time{},
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) SelectKey(tenantID string, resourceID uuid.UUID, resourceAddress string) (Key, error) {
+	row := r.db.QueryRow(defaultSelectKeyQuery, tenantID, resourceID.String(), resourceAddress)
+	key, err := scanKey(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return key, errNotFound{err}
+		}
+		return key, err
 	}
+	return key, nil
+}
 
-	var txn *sql.Tx
-	txn, err = r.db.Begin()
+func (r *realStore) SelectAddressKeys(tenantID, resourceAddress string) ([]Key, error) {
+	rows, err := r.db.Query(defaultSelectAddressKeysQuery, tenantID, resourceAddress)
 	if err != nil {
-		return
+		return nil, err
 	}
+	defer rows.Close()
 
-	defer func() {
+	res := make([]Key, 0)
+	for rows.Next() {
+		key, err := scanKey(rows)
 		if err != nil {
-			txn.Rollback()
-		} else {
-			if err = txn.Commit(); err != nil {
-				err = errors.Wrap(err, "unable to commit statement")
-			}
+			return nil, err
 		}
-	}()
-
-	err = fn(txn)
-	return
+		res = append(res, key)
+	}
+	return res, rows.Err()
 }
 
-func (r *realStore) Statistics() (Statistics, error) {
-	row := r.db.QueryRow(defaultStatisticsQuery)
+func (r *realStore) DestroyKey(tenantID string, resourceID uuid.UUID, resourceAddress string, destroyedOn time.Time) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultDestroyKeyQuery,
+			tenantID,
+			resourceID.String(),
+			resourceAddress,
+			destroyedOn,
+			time.Time{},
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
 
-	var total int
-	if err := row.Scan(&total); err != nil {
-		return Statistics{}, err
+// scanKey scans a key from a row of the keys queries.
+func scanKey(row scanner) (Key, error) {
+	var (
+		key        Key
+		resourceID string
+	)
+	err := row.Scan(
+		&key.TenantID,
+		&resourceID,
+		&key.ResourceAddress,
+		&key.KeyID,
+		&key.WrappedKey,
+		&key.CreatedOn,
+		&key.DestroyedOn,
+	)
+	if err != nil {
+		return key, err
 	}
-
-	return Statistics{
-		Total: total,
-	}, nil
+	key.ResourceID, err = uuid.Parse(resourceID)
+	return key, err
 }
 
-// Drop removes all of the stored ledgers
-func (r *realStore) Drop() error {
-	if r.db == nil {
-		return errors.New("db not found")
+func (r *realStore) SelectAuthorResources(authorID string, query Query) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(defaultSelectAuthorResourcesQuery, authorID, query.Tenant)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	_, err := r.db.Exec(defaultDropQuery)
-	level.Error(r.logger).Log("err", err)
-	return err
+	res := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		resourceID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resourceID)
+	}
+	return res, rows.Err()
 }
 
-// Run the store
-func (r *realStore) Run() error {
-	var err error
-	r.db, err = sql.Open("postgres", ConnectionString(r.config))
+func (r *realStore) SelectAddressResources(tenantID, resourceAddress string) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(defaultSelectAddressResourcesQuery, tenantID, resourceAddress)
 	if err != nil {
-		level.Error(r.logger).Log("err", err)
-		return err
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		resourceID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resourceID)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) SelectResources(query Query) ([]uuid.UUID, error) {
+	var authorID string
+	if query.AuthorID != nil {
+		authorID = *query.AuthorID
+	}
+	tags := query.Tags
+	if tags == nil {
+		tags = make([]string, 0)
+	}
+
+	rows, err := r.db.Query(defaultSelectResourcesQuery, query.Tenant, authorID, pq.Array(tags))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		resourceID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resourceID)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) InsertAuthorKey(key AuthorKey) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertAuthorKeyQuery,
+			key.TenantID,
+			key.AuthorID,
+			key.KeyID,
+			key.PublicKey,
+			key.CreatedOn,
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) SelectAuthorKey(tenantID, authorID, keyID string) (AuthorKey, error) {
+	var (
+		key AuthorKey
+		row = r.db.QueryRow(defaultSelectAuthorKeyQuery, tenantID, authorID, keyID)
+	)
+	err := row.Scan(
+		&key.TenantID,
+		&key.AuthorID,
+		&key.KeyID,
+		&key.PublicKey,
+		&key.CreatedOn,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return key, errNotFound{err}
+		}
+		return key, err
+	}
+	return key, nil
+}
+
+func (r *realStore) SelectAuthorKeys(tenantID, authorID string) ([]AuthorKey, error) {
+	rows, err := r.db.Query(defaultSelectAuthorKeysQuery, tenantID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]AuthorKey, 0)
+	for rows.Next() {
+		var key AuthorKey
+		if err := rows.Scan(
+			&key.TenantID,
+			&key.AuthorID,
+			&key.KeyID,
+			&key.PublicKey,
+			&key.CreatedOn,
+		); err != nil {
+			return nil, err
+		}
+		res = append(res, key)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) InsertACL(acl ACL) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		return insertACL(txn, acl)
+	})
+}
+
+// insertACL inserts a revision of the access control list, with in the
+// transaction.
+func insertACL(txn *sql.Tx, acl ACL) error {
+	if _, err := txn.Exec(
+		defaultInsertACLQuery,
+		acl.TenantID,
+		acl.ResourceID.String(),
+		acl.Owner,
+		pq.Array(acl.Readers),
+		pq.Array(acl.Writers),
+		acl.UpdatedBy,
+		acl.CreatedOn,
+	); err != nil {
+		return errors.Wrap(err, "unable to exec statement")
+	}
+	return nil
+}
+
+func (r *realStore) SelectACL(resourceID uuid.UUID, query Query) (ACL, error) {
+	acl, err := scanACL(r.db.QueryRow(defaultSelectACLQuery, resourceID.String(), query.Tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return acl, errNotFound{err}
+		}
+		return acl, err
+	}
+	return acl, nil
+}
+
+func (r *realStore) SelectACLRevisions(resourceID uuid.UUID, query Query) ([]ACL, error) {
+	rows, err := r.db.Query(defaultSelectACLRevisionsQuery, resourceID.String(), query.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]ACL, 0)
+	for rows.Next() {
+		acl, err := scanACL(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, acl)
+	}
+	return res, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanACL(row scanner) (ACL, error) {
+	var (
+		acl        ACL
+		resourceID string
+	)
+	if err := row.Scan(
+		&acl.TenantID,
+		&resourceID,
+		&acl.Owner,
+		pq.Array(&acl.Readers),
+		pq.Array(&acl.Writers),
+		&acl.UpdatedBy,
+		&acl.CreatedOn,
+	); err != nil {
+		return ACL{}, err
+	}
+
+	var err error
+	acl.ResourceID, err = uuid.Parse(resourceID)
+	return acl, err
+}
+
+func (r *realStore) AppendLeaves() (size int64, err error) {
+	err = r.Transaction(func(txn *sql.Tx) error {
+		// Appending has to be serialized, so that the leaf indexes don't
+		// collide.
+		if _, err := txn.Exec(defaultLockLeavesQuery); err != nil {
+			return errors.Wrap(err, "unable to lock leaves")
+		}
+		if _, err := txn.Exec(defaultAppendLeavesQuery); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return txn.QueryRow(defaultTreeSizeQuery).Scan(&size)
+	})
+	return
+}
+
+func (r *realStore) SelectLeaves(start, end int64) ([]Leaf, error) {
+	rows, err := r.db.Query(defaultSelectLeavesQuery, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]Leaf, 0)
+	for rows.Next() {
+		var (
+			leaf     Leaf
+			ledgerID string
+		)
+		if err := rows.Scan(&leaf.Index, &ledgerID, &leaf.Hash); err != nil {
+			return nil, err
+		}
+		if leaf.LedgerID, err = uuid.Parse(ledgerID); err != nil {
+			return nil, err
+		}
+		res = append(res, leaf)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) SelectLeaf(ledgerID uuid.UUID) (Leaf, error) {
+	var (
+		leaf Leaf
+		row  = r.db.QueryRow(defaultSelectLeafQuery, ledgerID.String())
+
+		id string
+	)
+	if err := row.Scan(&leaf.Index, &id, &leaf.Hash); err != nil {
+		if err == sql.ErrNoRows {
+			return leaf, errNotFound{err}
+		}
+		return leaf, err
+	}
+
+	var err error
+	if leaf.LedgerID, err = uuid.Parse(id); err != nil {
+		return leaf, err
+	}
+	return leaf, nil
+}
+
+func (r *realStore) InsertNodes(nodes []Node) error {
+	var (
+		levels  = make([]int64, len(nodes))
+		indexes = make([]int64, len(nodes))
+		hashes  = make([][]byte, len(nodes))
+	)
+	for k, v := range nodes {
+		levels[k], indexes[k], hashes[k] = int64(v.Level), v.Index, v.Hash
+	}
+
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertNodesQuery,
+			pq.Array(levels),
+			pq.Array(indexes),
+			pq.Array(hashes),
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) SelectNodes(ids []NodeID) ([]Node, error) {
+	var (
+		levels  = make([]int64, len(ids))
+		indexes = make([]int64, len(ids))
+	)
+	for k, v := range ids {
+		levels[k], indexes[k] = int64(v.Level), v.Index
+	}
+
+	rows, err := r.db.Query(defaultSelectNodesQuery, pq.Array(levels), pq.Array(indexes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]Node, 0, len(ids))
+	for rows.Next() {
+		var node Node
+		if err := rows.Scan(&node.Level, &node.Index, &node.Hash); err != nil {
+			return nil, err
+		}
+		res = append(res, node)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) SelectNodesTreeSize() (int64, error) {
+	var size int64
+	if err := r.db.QueryRow(defaultSelectNodesTreeSizeQuery).Scan(&size); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (r *realStore) InsertCheckpoint(checkpoint Checkpoint) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertCheckpointQuery,
+			checkpoint.TreeSize,
+			checkpoint.RootHash,
+			checkpoint.KeyID,
+			checkpoint.Signature,
+			checkpoint.CreatedOn,
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) SelectCheckpoint(treeSize int64) (Checkpoint, error) {
+	var row *sql.Row
+	if treeSize == 0 {
+		row = r.db.QueryRow(defaultSelectLatestCheckpointQuery)
+	} else {
+		row = r.db.QueryRow(defaultSelectCheckpointQuery, treeSize)
+	}
+
+	var checkpoint Checkpoint
+	err := row.Scan(
+		&checkpoint.TreeSize,
+		&checkpoint.RootHash,
+		&checkpoint.KeyID,
+		&checkpoint.Signature,
+		&checkpoint.CreatedOn,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return checkpoint, errNotFound{err}
+		}
+		return checkpoint, err
+	}
+	return checkpoint, nil
+}
+
+func (r *realStore) Transaction(fn func(*sql.Tx) error) (err error) {
+	if r.db == nil {
+		err = errors.New("db not found")
+		return
+	}
+
+	var txn *sql.Tx
+	txn, err = r.db.Begin()
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			txn.Rollback()
+		} else {
+			if err = txn.Commit(); err != nil {
+				err = errors.Wrap(err, "unable to commit statement")
+			}
+		}
+	}()
+
+	err = fn(txn)
+	return
+}
+
+func (r *realStore) Statistics(query Query) (Statistics, error) {
+	var authorID string
+	if query.AuthorID != nil {
+		authorID = *query.AuthorID
+	}
+
+	var (
+		tags  = pq.Array(query.Tags)
+		stats = Statistics{
+			ContentTypes: make(map[string]int64),
+			Inserts:      insertWindows(),
+		}
+		revisions = make(map[int]int)
+	)
+	if query.Tags == nil {
+		tags = pq.Array([]string{})
+	}
+
+	if err := r.scanStatistics(func(rows *sql.Rows) error {
+		var number, resources int
+		if err := rows.Scan(&number, &resources); err != nil {
+			return err
+		}
+		revisions[number] = resources
+		stats.Total += number * resources
+		stats.Resources += resources
+		return nil
+	}, defaultStatisticsRevisionsQuery, query.Tenant, authorID, tags); err != nil {
+		return Statistics{}, err
+	}
+	stats.Revisions = bucketRevisions(revisions)
+
+	if err := r.scanStatistics(func(rows *sql.Rows) error {
+		var (
+			contentType string
+			bytes       int64
+		)
+		if err := rows.Scan(&contentType, &bytes); err != nil {
+			return err
+		}
+		stats.ContentTypes[contentType] = bytes
+		stats.Bytes += bytes
+		return nil
+	}, defaultStatisticsContentTypesQuery, query.Tenant, authorID, tags); err != nil {
+		return Statistics{}, err
+	}
+
+	stats.Authors = make([]Count, 0)
+	if err := r.scanStatistics(func(rows *sql.Rows) error {
+		var count Count
+		if err := rows.Scan(&count.Name, &count.Ledgers); err != nil {
+			return err
+		}
+		stats.Authors = append(stats.Authors, count)
+		return nil
+	}, defaultStatisticsAuthorsQuery, query.Tenant, authorID, tags, statisticsTop); err != nil {
+		return Statistics{}, err
+	}
+
+	stats.Tags = make([]Count, 0)
+	if err := r.scanStatistics(func(rows *sql.Rows) error {
+		var count Count
+		if err := rows.Scan(&count.Name, &count.Ledgers); err != nil {
+			return err
+		}
+		stats.Tags = append(stats.Tags, count)
+		return nil
+	}, defaultStatisticsTagsQuery, query.Tenant, authorID, tags, statisticsTop); err != nil {
+		return Statistics{}, err
+	}
+
+	now := time.Now()
+	for k, window := range stats.Inserts {
+		row := r.db.QueryRow(defaultStatisticsInsertsQuery, query.Tenant, authorID, tags, now.Add(-window.Window))
+		if err := row.Scan(&stats.Inserts[k].Ledgers); err != nil {
+			return Statistics{}, err
+		}
+	}
+
+	return stats, nil
+}
+
+// scanStatistics runs a statistics query and calls fn for each of the rows.
+func (r *realStore) scanStatistics(fn func(*sql.Rows) error, query string, args ...interface{}) error {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *realStore) InsertQuota(quota Quota) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertQuotaQuery,
+			quota.TenantID,
+			quota.AuthorID,
+			quota.MaxBytes,
+			quota.MaxResources,
+			quota.MaxRevisionsPerDay,
+			quota.UpdatedOn,
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) SelectQuota(tenantID, authorID string) (Quota, error) {
+	var (
+		quota Quota
+		row   = r.db.QueryRow(defaultSelectQuotaQuery, tenantID, authorID)
+	)
+	err := row.Scan(
+		&quota.TenantID,
+		&quota.AuthorID,
+		&quota.MaxBytes,
+		&quota.MaxResources,
+		&quota.MaxRevisionsPerDay,
+		&quota.UpdatedOn,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return quota, errNotFound{err}
+		}
+		return quota, err
+	}
+	return quota, nil
+}
+
+func (r *realStore) SelectUsage(query Query, since time.Time) (Usage, error) {
+	var authorID string
+	if query.AuthorID != nil {
+		authorID = *query.AuthorID
+	}
+
+	var (
+		usage Usage
+		row   = r.db.QueryRow(defaultSelectUsageQuery, query.Tenant, authorID, since)
+	)
+	if err := row.Scan(
+		&usage.Bytes,
+		&usage.Resources,
+		&usage.Revisions,
+	); err != nil {
+		return Usage{}, err
+	}
+	return usage, nil
+}
+
+func (r *realStore) InsertWebhook(webhook Webhook) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertWebhookQuery,
+			webhook.ID.String(),
+			webhook.TenantID,
+			webhook.URL,
+			webhook.Secret,
+			webhook.ResourceID.String(),
+			webhook.Tag,
+			webhook.AuthorID,
+			pq.Array(webhook.Events),
+			webhook.Owner,
+			webhook.CreatedOn,
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) SelectWebhooks(tenantID string) ([]Webhook, error) {
+	rows, err := r.db.Query(defaultSelectWebhooksQuery, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]Webhook, 0)
+	for rows.Next() {
+		var (
+			webhook        Webhook
+			id, resourceID string
+		)
+		if err := rows.Scan(
+			&id,
+			&webhook.TenantID,
+			&webhook.URL,
+			&webhook.Secret,
+			&resourceID,
+			&webhook.Tag,
+			&webhook.AuthorID,
+			pq.Array(&webhook.Events),
+			&webhook.Owner,
+			&webhook.CreatedOn,
+		); err != nil {
+			return nil, err
+		}
+		if webhook.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if webhook.ResourceID, err = uuid.Parse(resourceID); err != nil {
+			return nil, err
+		}
+		res = append(res, webhook)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) DeleteWebhook(tenantID string, webhookID uuid.UUID) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		res, err := txn.Exec(defaultDeleteWebhookQuery, tenantID, webhookID.String())
+		if err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return errNotFound{errors.New("not found")}
+		}
+		return nil
+	})
+}
+
+func (r *realStore) InsertDelivery(delivery Delivery) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertDeliveryQuery,
+			delivery.ID.String(),
+			delivery.WebhookID.String(),
+			delivery.TenantID,
+			delivery.Event,
+			delivery.Payload,
+			delivery.Status,
+			delivery.Attempts,
+			delivery.LastError,
+			delivery.NextAttemptOn,
+			delivery.CreatedOn,
+			delivery.DeliveredOn,
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) UpdateDelivery(delivery Delivery) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		res, err := txn.Exec(
+			defaultUpdateDeliveryQuery,
+			delivery.ID.String(),
+			delivery.Status,
+			delivery.Attempts,
+			delivery.LastError,
+			delivery.NextAttemptOn,
+			delivery.DeliveredOn,
+		)
+		if err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return errNotFound{errors.New("not found")}
+		}
+		return nil
+	})
+}
+
+func (r *realStore) SelectDelivery(tenantID string, deliveryID uuid.UUID) (Delivery, error) {
+	row := r.db.QueryRow(defaultSelectDeliveryQuery, tenantID, deliveryID.String())
+
+	delivery, err := scanDelivery(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return delivery, errNotFound{err}
+		}
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (r *realStore) SelectDeliveries(tenantID, status string) ([]Delivery, error) {
+	rows, err := r.db.Query(defaultSelectDeliveriesQuery, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]Delivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, delivery)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) ClaimPendingDeliveries(before, until time.Time, limit int) ([]Delivery, error) {
+	// The deliveries are claimed in the one statement, skipping any that
+	// another worker is claiming, so that two workers never claim the same
+	// delivery.
+	rows, err := r.db.Query(defaultClaimPendingDeliveriesQuery, DeliveryStatusPending, before, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]Delivery, 0)
+	for rows.Next() {
+		var (
+			delivery      Delivery
+			id, webhookID string
+		)
+		if err := rows.Scan(
+			&id,
+			&webhookID,
+			&delivery.TenantID,
+			&delivery.Event,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.LastError,
+			&delivery.NextAttemptOn,
+			&delivery.CreatedOn,
+			&delivery.DeliveredOn,
+			&delivery.URL,
+			&delivery.Secret,
+		); err != nil {
+			return nil, err
+		}
+		if delivery.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if delivery.WebhookID, err = uuid.Parse(webhookID); err != nil {
+			return nil, err
+		}
+		res = append(res, delivery)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) InsertDeliveryAttempt(attempt DeliveryAttempt) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertDeliveryAttemptQuery,
+			attempt.DeliveryID.String(),
+			attempt.AttemptedOn,
+			attempt.StatusCode,
+			attempt.Error,
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) SelectDeliveryAttempts(deliveryID uuid.UUID) ([]DeliveryAttempt, error) {
+	rows, err := r.db.Query(defaultSelectDeliveryAttemptsQuery, deliveryID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]DeliveryAttempt, 0)
+	for rows.Next() {
+		var (
+			attempt DeliveryAttempt
+			id      string
+		)
+		if err := rows.Scan(
+			&id,
+			&attempt.AttemptedOn,
+			&attempt.StatusCode,
+			&attempt.Error,
+		); err != nil {
+			return nil, err
+		}
+		if attempt.DeliveryID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		res = append(res, attempt)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) SelectOutbox(after int64, limit int) ([]OutboxRecord, error) {
+	rows, err := r.db.Query(defaultSelectOutboxQuery, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]OutboxRecord, 0)
+	for rows.Next() {
+		var (
+			record               OutboxRecord
+			ledgerID, resourceID string
+		)
+		if err := rows.Scan(
+			&record.Sequence,
+			&record.Event,
+			&ledgerID,
+			&record.TenantID,
+			&resourceID,
+			&record.Payload,
+			&record.CreatedOn,
+		); err != nil {
+			return nil, err
+		}
+		if record.LedgerID, err = uuid.Parse(ledgerID); err != nil {
+			return nil, err
+		}
+		if record.ResourceID, err = uuid.Parse(resourceID); err != nil {
+			return nil, err
+		}
+		res = append(res, record)
+	}
+	return res, rows.Err()
+}
+
+func (r *realStore) SelectOutboxCheckpoint(sink string) (int64, error) {
+	var sequence int64
+	if err := r.db.QueryRow(defaultSelectOutboxCheckpointQuery, sink).Scan(&sequence); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return sequence, nil
+}
+
+func (r *realStore) InsertOutboxCheckpoint(sink string, sequence int64) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(
+			defaultInsertOutboxCheckpointQuery,
+			sink,
+			sequence,
+			time.Now(),
+		); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) DeleteOutbox(through int64) error {
+	return r.Transaction(func(txn *sql.Tx) error {
+		if _, err := txn.Exec(defaultDeleteOutboxQuery, through); err != nil {
+			return errors.Wrap(err, "unable to exec statement")
+		}
+		return nil
+	})
+}
+
+func (r *realStore) LockOutboxRelay(fn func() error) (locked bool, err error) {
+	// The lock is released when the transaction ends, which is also when the
+	// lock is released if the replica goes away.
+	err = r.Transaction(func(txn *sql.Tx) error {
+		if err := txn.QueryRow(defaultLockOutboxRelayQuery).Scan(&locked); err != nil {
+			return errors.Wrap(err, "unable to lock outbox relay")
+		}
+		if !locked {
+			return nil
+		}
+		return fn()
+	})
+	return
+}
+
+func scanDelivery(row scanner) (Delivery, error) {
+	var (
+		delivery      Delivery
+		id, webhookID string
+	)
+	err := row.Scan(
+		&id,
+		&webhookID,
+		&delivery.TenantID,
+		&delivery.Event,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.LastError,
+		&delivery.NextAttemptOn,
+		&delivery.CreatedOn,
+		&delivery.DeliveredOn,
+	)
+	if err != nil {
+		return delivery, err
+	}
+	if delivery.ID, err = uuid.Parse(id); err != nil {
+		return delivery, err
+	}
+	if delivery.WebhookID, err = uuid.Parse(webhookID); err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+// Drop removes all of the stored ledgers
+func (r *realStore) Drop() error {
+	if r.db == nil {
+		return errors.New("db not found")
+	}
+
+	_, err := r.db.Exec(defaultDropQuery)
+	level.Error(r.logger).Log("err", err)
+	return err
+}
+
+// Run the store
+func (r *realStore) Run() error {
+	var err error
+	r.db, err = sql.Open("postgres", ConnectionString(r.config))
+	if err != nil {
+		level.Error(r.logger).Log("err", err)
+		return err
 	}
 
 	// Sync db check to validate conn-opts
@@ -450,18 +2085,23 @@ func buildSQLFromQuery(resourceID uuid.UUID, query Query) (string, []interface{}
 	numTags, authorID := len(query.Tags), query.AuthorID
 
 	if numTags == 0 && (authorID == nil || *authorID == "") {
-		return defaultSelectQuery, []interface{}{resourceID.String()}
+		return defaultSelectQuery, []interface{}{
+			resourceID.String(),
+			query.Tenant,
+		}
 	}
 
 	if numTags > 0 && (authorID == nil || *authorID == "") {
 		return defaultSelectQueryTags, []interface{}{
 			resourceID.String(),
+			query.Tenant,
 			pq.Array(query.Tags),
 		}
 	}
 
 	return defaultSelectQueryTagsAuthorID, []interface{}{
 		resourceID.String(),
+		query.Tenant,
 		*authorID,
 		pq.Array(query.Tags),
 	}
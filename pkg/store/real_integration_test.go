@@ -3,7 +3,9 @@
 package store
 
 import (
+	"bytes"
 	"fmt"
+	"reflect"
 	"sync"
 	"testing"
 	"testing/quick"
@@ -99,6 +101,8 @@ func TestRealStore_Integration(t *testing.T) {
 		) bool {
 			defer store.Drop()
 
+			insertParent(t, store, parentID)
+
 			err := store.Insert(Entity{
 				ParentID:            parentID,
 				ResourceID:          resourceID,
@@ -131,6 +135,8 @@ func TestRealStore_Integration(t *testing.T) {
 		) bool {
 			defer store.Drop()
 
+			insertParent(t, store, parentID)
+
 			if err := store.Insert(Entity{
 				ParentID:            parentID,
 				ResourceID:          resourceID,
@@ -163,11 +169,378 @@ func TestRealStore_Integration(t *testing.T) {
 		}
 	})
 
+	t.Run("insert key then select", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		fn := func(resourceID uuid.UUID, address, keyID string, wrapped []byte) bool {
+			defer store.Drop()
+
+			if err := store.InsertKey(Key{
+				ResourceID:      resourceID,
+				ResourceAddress: address,
+				KeyID:           keyID,
+				WrappedKey:      wrapped,
+				CreatedOn:       time.Now(),
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := store.SelectKey("", resourceID, address)
+			if err != nil {
+				return false
+			}
+			return key.ResourceID.Equals(resourceID) &&
+				key.ResourceAddress == address &&
+				key.KeyID == keyID &&
+				bytes.Equal(key.WrappedKey, wrapped)
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("select key not found", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		_, err := store.SelectKey("", uuid.Empty, "address")
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert author key then select", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		fn := func(authorID, keyID string, publicKey []byte) bool {
+			defer store.Drop()
+
+			if err := store.InsertAuthorKey(AuthorKey{
+				AuthorID:  authorID,
+				KeyID:     keyID,
+				PublicKey: publicKey,
+				CreatedOn: time.Now(),
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := store.SelectAuthorKey("", authorID, keyID)
+			if err != nil {
+				return false
+			}
+
+			keys, err := store.SelectAuthorKeys("", authorID)
+			if err != nil {
+				return false
+			}
+
+			return key.AuthorID == authorID &&
+				key.KeyID == keyID &&
+				bytes.Equal(key.PublicKey, publicKey) &&
+				len(keys) == 1
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("insert acls then select", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		fn := func(res uuid.UUID, owner string, readers, writers generators.ASCIISlice) bool {
+			defer store.Drop()
+
+			for _, acl := range []ACL{
+				{ResourceID: res, Owner: owner, Readers: []string{}, Writers: []string{}},
+				{ResourceID: res, Owner: owner, Readers: readers.Slice(), Writers: writers.Slice()},
+			} {
+				acl.UpdatedBy = owner
+				acl.CreatedOn = time.Now()
+				if err := store.InsertACL(acl); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			acl, err := store.SelectACL(res, Query{})
+			if err != nil {
+				return false
+			}
+
+			acls, err := store.SelectACLRevisions(res, Query{})
+			if err != nil {
+				return false
+			}
+
+			return acl.ResourceID.Equals(res) &&
+				acl.Owner == owner &&
+				reflect.DeepEqual(acl.Readers, readers.Slice()) &&
+				reflect.DeepEqual(acl.Writers, writers.Slice()) &&
+				len(acls) == 2
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("insert quota then select", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		fn := func(authorID string, maxBytes, maxResources int64) bool {
+			defer store.Drop()
+
+			for _, max := range []int64{1, maxBytes} {
+				if err := store.InsertQuota(Quota{
+					AuthorID:     authorID,
+					MaxBytes:     max,
+					MaxResources: maxResources,
+					UpdatedOn:    time.Now(),
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			quota, err := store.SelectQuota("", authorID)
+			if err != nil {
+				return false
+			}
+
+			return quota.AuthorID == authorID &&
+				quota.MaxBytes == maxBytes &&
+				quota.MaxResources == maxResources
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("insert webhook then deliver", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		defer store.Drop()
+
+		var (
+			now     = time.Now()
+			webhook = Webhook{
+				ID:         uuid.MustNew(),
+				URL:        "http://example.com",
+				Secret:     "secret",
+				ResourceID: uuid.Empty,
+				Events:     []string{"insert"},
+				Owner:      "alice",
+				CreatedOn:  now,
+			}
+			delivery = Delivery{
+				ID:            uuid.MustNew(),
+				WebhookID:     webhook.ID,
+				Event:         "insert",
+				Payload:       []byte(`{}`),
+				Status:        DeliveryStatusPending,
+				NextAttemptOn: now,
+				CreatedOn:     now,
+			}
+		)
+		if err := store.InsertWebhook(webhook); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertDelivery(delivery); err != nil {
+			t.Fatal(err)
+		}
+
+		webhooks, err := store.SelectWebhooks("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(webhooks); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := webhook.Owner, webhooks[0].Owner; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		deliveries, err := store.ClaimPendingDeliveries(now.Add(time.Second), now.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := webhook.URL, deliveries[0].URL; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		if err := store.InsertDeliveryAttempt(DeliveryAttempt{
+			DeliveryID:  delivery.ID,
+			AttemptedOn: now,
+			StatusCode:  200,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		delivery.Status = DeliveryStatusDelivered
+		delivery.Attempts = 1
+		delivery.DeliveredOn = now
+		if err := store.UpdateDelivery(delivery); err != nil {
+			t.Fatal(err)
+		}
+
+		delivered, err := store.SelectDelivery("", delivery.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := DeliveryStatusDelivered, delivered.Status; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		attempts, err := store.SelectDeliveryAttempts(delivery.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("insert then select outbox", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		defer store.Drop()
+
+		entity := Entity{
+			ID:         uuid.MustNew(),
+			ResourceID: uuid.MustNew(),
+			Name:       "name",
+			CreatedOn:  time.Now(),
+		}
+		if err := store.Insert(entity); err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := store.SelectOutbox(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(records); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := OutboxEventInsert, records[0].Event; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := entity.ID, records[0].LedgerID; !expected.Equals(actual) {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+
+		if err := store.InsertOutboxCheckpoint("stdout", records[0].Sequence); err != nil {
+			t.Fatal(err)
+		}
+		sequence, err := store.SelectOutboxCheckpoint("stdout")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := records[0].Sequence, sequence; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		if err := store.DeleteOutbox(sequence); err != nil {
+			t.Fatal(err)
+		}
+		records, err = store.SelectOutbox(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(records); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("insert then select usage", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		defer store.Drop()
+
+		var (
+			now      = time.Now()
+			authorID = "author"
+		)
+		for _, entity := range []Entity{
+			{ResourceID: uuid.MustNew(), ResourceAddress: "a", ResourceSize: 10, AuthorID: authorID, CreatedOn: now.Add(-48 * time.Hour)},
+			{ResourceID: uuid.MustNew(), ResourceAddress: "a", ResourceSize: 10, AuthorID: authorID, CreatedOn: now},
+			{ResourceID: uuid.MustNew(), ResourceAddress: "b", ResourceSize: 5, AuthorID: "other", CreatedOn: now},
+		} {
+			if err := store.Insert(entity); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		usage, err := store.SelectUsage(Query{AuthorID: &authorID}, now.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := (Usage{Bytes: 10, Resources: 2, Revisions: 1}), usage; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		usage, err = store.SelectUsage(Query{}, now.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := (Usage{Bytes: 15, Resources: 3, Revisions: 2}), usage; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("insert signed then select", func(t *testing.T) {
+		store := runStore(config)
+		defer store.Stop()
+
+		fn := func(keyID string, signature []byte) bool {
+			defer store.Drop()
+
+			parentID := uuid.MustNew()
+			insertParent(t, store, parentID)
+
+			entity := Entity{
+				ParentID:       parentID,
+				ResourceID:     uuid.MustNew(),
+				Name:           "name",
+				AuthorID:       "author",
+				Tags:           []string{},
+				CreatedOn:      time.Now(),
+				Signature:      signature,
+				SignatureKeyID: keyID,
+			}
+			if err := store.Insert(entity); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := store.Select(entity.ResourceID, Query{})
+			if err != nil {
+				return false
+			}
+			return res.SignatureKeyID == keyID &&
+				bytes.Equal(res.Signature, signature)
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
 	t.Run("select fork revisions not found failure", func(t *testing.T) {
 		store := runStore(config)
 		defer store.Stop()
 
-		entities, err := store.SelectForkRevisions(uuid.MustNew())
+		entities, err := store.SelectForkRevisions(uuid.MustNew(), Query{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -239,7 +612,7 @@ func TestRealStore_Integration(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			entities, err := store.SelectForkRevisions(resourceID)
+			entities, err := store.SelectForkRevisions(resourceID, Query{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -254,6 +627,14 @@ func TestRealStore_Integration(t *testing.T) {
 				t.Errorf("expected: %q, actual: %q", expected, actual)
 			}
 
+			// The hashes are chained from the root.
+			if expected, actual := HashEntity(entities[0], ""), entities[0].Hash; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+			if expected, actual := HashEntity(entities[1], entities[0].Hash), entities[1].Hash; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+
 			return true
 		}
 		if err := quick.Check(fn, nil); err != nil {
@@ -305,6 +686,8 @@ func TestRealStore_IntegrationQuery(t *testing.T) {
 		) bool {
 			defer store.Drop()
 
+			insertParent(t, store, parentID)
+
 			if err := store.Insert(Entity{
 				ParentID:            parentID,
 				ResourceID:          resourceID,
@@ -345,6 +728,8 @@ func TestRealStore_IntegrationQuery(t *testing.T) {
 		) bool {
 			defer store.Drop()
 
+			insertParent(t, store, parentID)
+
 			entity := Entity{
 				ParentID:            parentID,
 				ResourceID:          resourceID,
@@ -389,6 +774,8 @@ func TestRealStore_IntegrationQuery(t *testing.T) {
 		) bool {
 			defer store.Drop()
 
+			insertParent(t, store, parentID)
+
 			want := make([]Entity, 10)
 			for k := range want {
 				entity := Entity{
@@ -436,6 +823,8 @@ func TestRealStore_IntegrationQuery(t *testing.T) {
 		) bool {
 			defer store.Drop()
 
+			insertParent(t, store, parentID)
+
 			want := make([]Entity, 10)
 			for k := range want {
 				entity := Entity{
@@ -483,6 +872,8 @@ func TestRealStore_IntegrationQuery(t *testing.T) {
 		) bool {
 			defer store.Drop()
 
+			insertParent(t, store, parentID)
+
 			entity := Entity{
 				ParentID:            parentID,
 				ResourceID:          resourceID,
@@ -529,6 +920,8 @@ func TestRealStore_IntegrationQuery(t *testing.T) {
 		) bool {
 			defer store.Drop()
 
+			insertParent(t, store, parentID)
+
 			want := make([]Entity, 10)
 			for k := range want {
 				entity := Entity{
@@ -571,6 +964,20 @@ func TestRealStore_IntegrationQuery(t *testing.T) {
 	})
 }
 
+// insertParent inserts a ledger with the id, so that ledgers can be inserted
+// with it as their parent.
+func insertParent(t *testing.T, store Store, id uuid.UUID) {
+	if err := store.Insert(Entity{
+		ID:         id,
+		ResourceID: uuid.MustNew(),
+		Name:       "parent",
+		Tags:       []string{},
+		CreatedOn:  time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func runStore(config *RealConfig) Store {
 	var wg sync.WaitGroup
 	wg.Add(1)
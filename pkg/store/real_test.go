@@ -133,7 +133,7 @@ func TestSQLBuilder(t *testing.T) {
 		fn := func(resourceID uuid.UUID) bool {
 			statement, args := buildSQLFromQuery(resourceID, Query{})
 			return statement == defaultSelectQuery &&
-				reflect.DeepEqual(args, []interface{}{resourceID.String()})
+				reflect.DeepEqual(args, []interface{}{resourceID.String(), ""})
 		}
 		if err := quick.Check(fn, nil); err != nil {
 			t.Error(err)
@@ -147,7 +147,7 @@ func TestSQLBuilder(t *testing.T) {
 				AuthorID: &s,
 			})
 			return statement == defaultSelectQuery &&
-				reflect.DeepEqual(args, []interface{}{resourceID.String()})
+				reflect.DeepEqual(args, []interface{}{resourceID.String(), ""})
 		}
 		if err := quick.Check(fn, nil); err != nil {
 			t.Error(err)
@@ -162,6 +162,7 @@ func TestSQLBuilder(t *testing.T) {
 			return statement == defaultSelectQueryTags &&
 				reflect.DeepEqual(args, []interface{}{
 					resourceID.String(),
+					"",
 					pq.Array(tags.Slice()),
 				})
 		}
@@ -180,6 +181,7 @@ func TestSQLBuilder(t *testing.T) {
 			return statement == defaultSelectQueryTags &&
 				reflect.DeepEqual(args, []interface{}{
 					resourceID.String(),
+					"",
 					pq.Array(tags.Slice()),
 				})
 		}
@@ -198,6 +200,7 @@ func TestSQLBuilder(t *testing.T) {
 			return statement == defaultSelectQueryTagsAuthorID &&
 				reflect.DeepEqual(args, []interface{}{
 					resourceID.String(),
+					"",
 					authorID.String(),
 					pq.Array(tags.Slice()),
 				})
@@ -206,6 +209,22 @@ func TestSQLBuilder(t *testing.T) {
 			t.Error(err)
 		}
 	})
+
+	t.Run("select with tenant", func(t *testing.T) {
+		fn := func(resourceID uuid.UUID, tenant generators.ASCII) bool {
+			statement, args := buildSQLFromQuery(resourceID, Query{
+				Tenant: tenant.String(),
+			})
+			return statement == defaultSelectQuery &&
+				reflect.DeepEqual(args, []interface{}{
+					resourceID.String(),
+					tenant.String(),
+				})
+		}
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
 }
 
 func TestSortTags(t *testing.T) {
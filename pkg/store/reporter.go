@@ -0,0 +1,95 @@
+package store
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/trussle/snowy/pkg/metrics"
+)
+
+const defaultReporterInterval = time.Minute
+
+// Reporter periodically reports the totals of the ledgers of the default
+// tenant, the number of ledgers, the number of resources and the bytes of the
+// resources, to the gauges.
+type Reporter struct {
+	store     Store
+	ledgers   metrics.Gauge
+	resources metrics.Gauge
+	bytes     metrics.Gauge
+	interval  time.Duration
+	logger    log.Logger
+	stop      chan chan struct{}
+}
+
+// ReporterOption defines a option for configuring the reporter.
+type ReporterOption func(*Reporter)
+
+// WithReporterInterval sets how often the totals are reported.
+func WithReporterInterval(interval time.Duration) ReporterOption {
+	return func(r *Reporter) {
+		r.interval = interval
+	}
+}
+
+// NewReporter creates a Reporter with correct dependencies.
+func NewReporter(store Store, ledgers, resources, bytes metrics.Gauge, logger log.Logger, opts ...ReporterOption) *Reporter {
+	r := &Reporter{
+		store:     store,
+		ledgers:   ledgers,
+		resources: resources,
+		bytes:     bytes,
+		interval:  defaultReporterInterval,
+		logger:    logger,
+		stop:      make(chan chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run reports the totals straight away and then periodically, until the
+// reporter is stopped.
+func (r *Reporter) Run() error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.report(); err != nil {
+			level.Error(r.logger).Log("action", "report", "err", err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case c := <-r.stop:
+			close(c)
+			return nil
+		}
+	}
+}
+
+// Stop stops the reporter, waiting for any report in flight.
+func (r *Reporter) Stop() {
+	c := make(chan struct{})
+	r.stop <- c
+	<-c
+}
+
+func (r *Reporter) report() error {
+	statistics, err := r.store.Statistics(Query{})
+	if err != nil {
+		return err
+	}
+
+	usage, err := r.store.SelectUsage(Query{}, time.Now())
+	if err != nil {
+		return err
+	}
+
+	r.ledgers.Set(float64(statistics.Total))
+	r.resources.Set(float64(usage.Resources))
+	r.bytes.Set(float64(usage.Bytes))
+	return nil
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/uuid"
+)
+
+func TestReporter(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ledgers   = mocks.NewMockGauge(ctrl)
+		resources = mocks.NewMockGauge(ctrl)
+		bytes     = mocks.NewMockGauge(ctrl)
+		store     = NewVirtualStore()
+	)
+
+	for _, entity := range []Entity{
+		{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), ResourceAddress: "a", ResourceSize: 10},
+		{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), ResourceAddress: "b", ResourceSize: 5},
+	} {
+		if err := store.Insert(entity); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan struct{})
+	ledgers.EXPECT().Set(float64(2)).Times(1)
+	resources.EXPECT().Set(float64(2)).Times(1)
+	bytes.EXPECT().Set(float64(15)).Times(1).Do(func(float64) {
+		close(done)
+	})
+
+	reporter := NewReporter(store, ledgers, resources, bytes, log.NewNopLogger(),
+		WithReporterInterval(time.Hour),
+	)
+	go reporter.Run()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected: report")
+	}
+	reporter.Stop()
+}
@@ -1,6 +1,121 @@
 package store
 
+import (
+	"sort"
+	"time"
+)
+
+// StatisticsWindows are the windows of time, up to now, that the inserts of
+// ledgers are counted over.
+var StatisticsWindows = []time.Duration{
+	time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// statisticsTop is how many of the top authors and tags are kept.
+const statisticsTop = 10
+
+// revisionBounds are the upper bounds of the buckets of the revisions per
+// resource. The last bucket has no upper bound.
+var revisionBounds = []int{1, 2, 5, 10, 50, 100}
+
 // Statistics represents a series of statistics about the ledgers
 type Statistics struct {
+	// Total is the number of ledgers, every revision of every resource.
 	Total int
+
+	// Resources is the number of distinct resources.
+	Resources int
+
+	// Bytes is the size of the distinct content, as content with the same
+	// address is only stored once.
+	Bytes int64
+
+	// ContentTypes is the size of the distinct content by the content type.
+	ContentTypes map[string]int64
+
+	// Revisions is the distribution of the revisions per resource.
+	Revisions []RevisionBucket
+
+	// Authors are the authors with the most ledgers, most first.
+	Authors []Count
+
+	// Tags are the tags with the most ledgers, most first.
+	Tags []Count
+
+	// Inserts are the ledgers inserted with in each of the StatisticsWindows.
+	Inserts []InsertWindow
+}
+
+// RevisionBucket is the number of resources that have between Min and Max
+// revisions, inclusive. A Max of zero has no upper bound.
+type RevisionBucket struct {
+	Min, Max  int
+	Resources int
+}
+
+// Count is the number of ledgers of an author or a tag.
+type Count struct {
+	Name    string
+	Ledgers int
+}
+
+// InsertWindow is the number of ledgers inserted with in the window of time,
+// up to now.
+type InsertWindow struct {
+	Window  time.Duration
+	Ledgers int
+}
+
+// bucketRevisions groups the number of resources by their number of
+// revisions, into the buckets of the revisionBounds.
+func bucketRevisions(resources map[int]int) []RevisionBucket {
+	buckets := make([]RevisionBucket, 0, len(revisionBounds)+1)
+	min := 1
+	for _, max := range revisionBounds {
+		buckets = append(buckets, RevisionBucket{Min: min, Max: max})
+		min = max + 1
+	}
+	buckets = append(buckets, RevisionBucket{Min: min})
+
+	for revisions, total := range resources {
+		for k, bucket := range buckets {
+			if revisions >= bucket.Min && (bucket.Max == 0 || revisions <= bucket.Max) {
+				buckets[k].Resources += total
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// topCounts returns the names with the most ledgers, most first. Names with
+// the same number of ledgers are ordered by name, so that the order is
+// stable.
+func topCounts(ledgers map[string]int, n int) []Count {
+	counts := make([]Count, 0, len(ledgers))
+	for name, total := range ledgers {
+		counts = append(counts, Count{Name: name, Ledgers: total})
+	}
+	sort.Slice(counts, func(a, b int) bool {
+		if counts[a].Ledgers != counts[b].Ledgers {
+			return counts[a].Ledgers > counts[b].Ledgers
+		}
+		return counts[a].Name < counts[b].Name
+	})
+	if len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// insertWindows returns the windows of the StatisticsWindows, without any
+// ledgers counted yet.
+func insertWindows() []InsertWindow {
+	windows := make([]InsertWindow, len(StatisticsWindows))
+	for k, window := range StatisticsWindows {
+		windows[k].Window = window
+	}
+	return windows
 }
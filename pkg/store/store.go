@@ -2,6 +2,7 @@ package store
 
 import (
 	"strings"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/pkg/errors"
@@ -12,6 +13,10 @@ import (
 type Query struct {
 	Tags     []string
 	AuthorID *string
+
+	// Tenant scopes the query to the ledgers of a single tenant, the empty
+	// tenant is the default namespace.
+	Tenant string
 }
 
 // Store represents a API over a persistent store.
@@ -21,19 +26,201 @@ type Store interface {
 	// query options as qualifiers, minus the actual content
 	Select(resourceID uuid.UUID, options Query) (Entity, error)
 
-	// Insert inserts a entity with in the datastore.
+	// Insert inserts a entity with in the datastore. If the entity has a
+	// parent that doesn't exist in the same tenant, it will return a not
+	// found error.
 	Insert(Entity) error
 
+	// InsertLedgers inserts the entities, in order, and then the access
+	// control lists, with in one transaction, so that either all of them are
+	// inserted or none are. A parent has to be inserted before its children.
+	InsertLedgers(entities []Entity, acls []ACL) error
+
+	// SelectEntity returns the stored ledger with the id, with in the tenant
+	// of the query. If no ledger exists it will return a not found error.
+	SelectEntity(id uuid.UUID, options Query) (Entity, error)
+
+	// SelectLedgerIDs returns the ids, out of the ids, of the ledgers that
+	// exist in any tenant. The id of a ledger is unique across the tenants,
+	// so a ledger can't be inserted with the id of a ledger of another tenant.
+	SelectLedgerIDs(ids []uuid.UUID) ([]uuid.UUID, error)
+
 	// SelectRevisions returns a set of stored ledgers from the datastore based
 	// on the query options as qualifiers, minus the actual content.
 	SelectRevisions(resourceID uuid.UUID, options Query) ([]Entity, error)
 
 	// SelectForkRevisions returns a set of stored ledgers from the datastore
 	// based on the query options as qualifiers, minus the actual content.
-	SelectForkRevisions(resourceID uuid.UUID) ([]Entity, error)
+	SelectForkRevisions(resourceID uuid.UUID, options Query) ([]Entity, error)
+
+	// InsertKey inserts a wrapped data key for the content of a resource. If
+	// a key already exists for the resource and address, then the existing
+	// key is kept, unless it's been destroyed, in which case it's replaced.
+	InsertKey(Key) error
+
+	// SelectKey returns the wrapped data key for the content of a resource.
+	// If no key exists it will return a not found error.
+	SelectKey(tenantID string, resourceID uuid.UUID, resourceAddress string) (Key, error)
+
+	// SelectAddressKeys returns the keys of the content at the address, for
+	// every resource with in the tenant, oldest first.
+	SelectAddressKeys(tenantID, resourceAddress string) ([]Key, error)
+
+	// DestroyKey destroys the key material for the content of a resource, so
+	// that the content can never be read through the resource again. If no
+	// key exists, then a destroyed key is recorded for the resource.
+	DestroyKey(tenantID string, resourceID uuid.UUID, resourceAddress string, destroyedOn time.Time) error
+
+	// SelectAuthorResources returns all the resource ids that have revisions
+	// by the author.
+	SelectAuthorResources(authorID string, options Query) ([]uuid.UUID, error)
+
+	// SelectAddressResources returns the ids of the resources with a revision
+	// of the content at the address, with in the tenant.
+	SelectAddressResources(tenantID, resourceAddress string) ([]uuid.UUID, error)
+
+	// SelectResources returns all the resource ids that have a revision by the
+	// author of the query, and with any of the tags of the query, in the order
+	// that the resources were first created.
+	SelectResources(options Query) ([]uuid.UUID, error)
+
+	// InsertAuthorKey registers a public key for an author. If the key is
+	// already registered for the author, then the existing key is kept.
+	InsertAuthorKey(AuthorKey) error
+
+	// SelectAuthorKey returns the public key of an author by the key id. If no
+	// key exists it will return a not found error.
+	SelectAuthorKey(tenantID, authorID, keyID string) (AuthorKey, error)
+
+	// SelectAuthorKeys returns all the public keys registered for an author.
+	SelectAuthorKeys(tenantID, authorID string) ([]AuthorKey, error)
+
+	// InsertACL records a new revision of the access control list of a
+	// resource, which then replaces any previous revision.
+	InsertACL(ACL) error
+
+	// SelectACL returns the latest revision of the access control list of a
+	// resource with in the tenant of the query. If no access control list
+	// exists it will return a not found error.
+	SelectACL(resourceID uuid.UUID, options Query) (ACL, error)
+
+	// SelectACLRevisions returns all the revisions of the access control list
+	// of a resource with in the tenant of the query, oldest first.
+	SelectACLRevisions(resourceID uuid.UUID, options Query) ([]ACL, error)
+
+	// AppendLeaves appends all the ledgers that aren't yet in the checkpoint
+	// tree as leaves, returning the new size of the tree.
+	AppendLeaves() (int64, error)
+
+	// SelectLeaves returns the leaves from the start index up to, but not
+	// including, the end index.
+	SelectLeaves(start, end int64) ([]Leaf, error)
+
+	// SelectLeaf returns the leaf for a ledger. If the ledger hasn't been
+	// appended to the tree it will return a not found error.
+	SelectLeaf(ledgerID uuid.UUID) (Leaf, error)
+
+	// InsertNodes inserts the nodes of the checkpoint tree, all together. As
+	// the hash of a node never changes, nodes that already exist are kept.
+	InsertNodes(nodes []Node) error
+
+	// SelectNodes returns the nodes of the checkpoint tree with the ids, out
+	// of those that exist.
+	SelectNodes(ids []NodeID) ([]Node, error)
+
+	// SelectNodesTreeSize returns the size of the checkpoint tree that the
+	// nodes have been inserted for.
+	SelectNodesTreeSize() (int64, error)
+
+	// InsertCheckpoint inserts a checkpoint. If a checkpoint already exists
+	// for the tree size, then the existing checkpoint is kept.
+	InsertCheckpoint(Checkpoint) error
 
-	// Statistics returns some statistics about the ledgers
-	Statistics() (Statistics, error)
+	// SelectCheckpoint returns the checkpoint for the tree size, or the latest
+	// checkpoint if the tree size is zero. If no checkpoint exists it will
+	// return a not found error.
+	SelectCheckpoint(treeSize int64) (Checkpoint, error)
+
+	// Statistics returns the statistics of the ledgers of the tenant. If the
+	// query has an author, then only the ledgers of the author are counted,
+	// and if the query has tags, then only the ledgers tagged with any of
+	// the tags are counted.
+	Statistics(options Query) (Statistics, error)
+
+	// InsertQuota records the quota of an author with in a tenant, replacing
+	// any existing quota for the same tenant and author.
+	InsertQuota(Quota) error
+
+	// SelectQuota returns the quota of an author with in a tenant. If no quota
+	// exists it will return a not found error.
+	SelectQuota(tenantID, authorID string) (Quota, error)
+
+	// SelectUsage returns the storage used by the ledgers matching the tenant
+	// and author of the query options, counting the revisions created since
+	// the time passed.
+	SelectUsage(options Query, since time.Time) (Usage, error)
+
+	// InsertWebhook inserts a webhook subscription.
+	InsertWebhook(Webhook) error
+
+	// SelectWebhooks returns all the webhook subscriptions of a tenant.
+	SelectWebhooks(tenantID string) ([]Webhook, error)
+
+	// DeleteWebhook deletes a webhook subscription of a tenant. If no webhook
+	// exists it will return a not found error.
+	DeleteWebhook(tenantID string, webhookID uuid.UUID) error
+
+	// InsertDelivery inserts a delivery of an event to a webhook.
+	InsertDelivery(Delivery) error
+
+	// UpdateDelivery updates the status, attempts and timings of a delivery.
+	// If no delivery exists it will return a not found error.
+	UpdateDelivery(Delivery) error
+
+	// SelectDelivery returns a delivery of a tenant. If no delivery exists it
+	// will return a not found error.
+	SelectDelivery(tenantID string, deliveryID uuid.UUID) (Delivery, error)
+
+	// SelectDeliveries returns all the deliveries of a tenant with the status,
+	// oldest first.
+	SelectDeliveries(tenantID, status string) ([]Delivery, error)
+
+	// ClaimPendingDeliveries claims up to limit pending deliveries, across all
+	// the tenants, that are due to be attempted before the time, by moving
+	// their next attempt on to until. A claimed delivery isn't claimed again
+	// until then, so that workers never attempt the same delivery at the same
+	// time, and a delivery whose worker stopped is attempted again once the
+	// claim runs out. Only deliveries of webhooks that still exist are claimed.
+	ClaimPendingDeliveries(before, until time.Time, limit int) ([]Delivery, error)
+
+	// InsertDeliveryAttempt records an attempt at delivering an event.
+	InsertDeliveryAttempt(DeliveryAttempt) error
+
+	// SelectDeliveryAttempts returns all the attempts at delivering an event,
+	// oldest first.
+	SelectDeliveryAttempts(deliveryID uuid.UUID) ([]DeliveryAttempt, error)
+
+	// SelectOutbox returns up to limit outbox records with a sequence after
+	// the one passed, in the order of the sequence.
+	SelectOutbox(after int64, limit int) ([]OutboxRecord, error)
+
+	// SelectOutboxCheckpoint returns the sequence of the last outbox record
+	// that the sink has received. If the sink has no checkpoint it returns
+	// zero, so that the sink receives every record.
+	SelectOutboxCheckpoint(sink string) (int64, error)
+
+	// InsertOutboxCheckpoint records the sequence of the last outbox record
+	// that the sink has received, replacing any existing checkpoint.
+	InsertOutboxCheckpoint(sink string, sequence int64) error
+
+	// DeleteOutbox deletes the outbox records up to and including the
+	// sequence, once every sink has received them.
+	DeleteOutbox(through int64) error
+
+	// LockOutboxRelay runs fn while holding the lock of the outbox relay, so
+	// that only one replica relays the outbox at a time. If another replica
+	// holds the lock, then fn isn't run and it returns false.
+	LockOutboxRelay(fn func() error) (bool, error)
 
 	// Drop removes all of the stored ledgers
 	Drop() error
@@ -131,6 +318,14 @@ func WithQueryAuthorID(authorID *string) QueryOption {
 	}
 }
 
+// WithQueryTenant adds tenant to the Query to use for the configuration.
+func WithQueryTenant(tenant string) QueryOption {
+	return func(query *Query) error {
+		query.Tenant = tenant
+		return nil
+	}
+}
+
 type notFound interface {
 	NotFound() bool
 }
@@ -0,0 +1,402 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/trussle/snowy/pkg/trace"
+	"github.com/trussle/uuid"
+)
+
+// WithContext returns the store bound to the context, so that the spans of the
+// store are children of the span of the context. Stores that aren't traced are
+// returned as they are.
+func WithContext(ctx context.Context, store Store) Store {
+	if traced, ok := store.(*tracedStore); ok {
+		return traced.withContext(ctx)
+	}
+	return store
+}
+
+type tracedStore struct {
+	store  Store
+	tracer *trace.Tracer
+	ctx    context.Context
+}
+
+// NewTracedStore creates a Store that records a span for every call to the
+// store. The spans are children of the span of the context that the store is
+// bound to, see WithContext. Calls of a store that isn't bound to a span aren't
+// traced.
+func NewTracedStore(store Store, tracer *trace.Tracer) Store {
+	return &tracedStore{
+		store:  store,
+		tracer: tracer,
+		ctx:    context.Background(),
+	}
+}
+
+func (s *tracedStore) withContext(ctx context.Context) Store {
+	return &tracedStore{
+		store:  s.store,
+		tracer: s.tracer,
+		ctx:    ctx,
+	}
+}
+
+func (s *tracedStore) start(name string) *trace.Span {
+	_, span := s.tracer.StartChild(s.ctx, name)
+	return span
+}
+
+func (s *tracedStore) Select(resourceID uuid.UUID, options Query) (res Entity, err error) {
+	span := s.start("store.Select")
+	span.SetAttribute("resource_id", resourceID.String())
+	defer func() { span.Finish(err) }()
+
+	return s.store.Select(resourceID, options)
+}
+
+func (s *tracedStore) SelectEntity(id uuid.UUID, options Query) (res Entity, err error) {
+	span := s.start("store.SelectEntity")
+	span.SetAttribute("ledger_id", id.String())
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectEntity(id, options)
+}
+
+func (s *tracedStore) InsertLedgers(entities []Entity, acls []ACL) (err error) {
+	span := s.start("store.InsertLedgers")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertLedgers(entities, acls)
+}
+
+func (s *tracedStore) SelectLedgerIDs(ids []uuid.UUID) (res []uuid.UUID, err error) {
+	span := s.start("store.SelectLedgerIDs")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectLedgerIDs(ids)
+}
+
+func (s *tracedStore) Insert(entity Entity) (err error) {
+	span := s.start("store.Insert")
+	defer func() { span.Finish(err) }()
+
+	return s.store.Insert(entity)
+}
+
+func (s *tracedStore) SelectRevisions(resourceID uuid.UUID, options Query) (res []Entity, err error) {
+	span := s.start("store.SelectRevisions")
+	span.SetAttribute("resource_id", resourceID.String())
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectRevisions(resourceID, options)
+}
+
+func (s *tracedStore) SelectForkRevisions(resourceID uuid.UUID, options Query) (res []Entity, err error) {
+	span := s.start("store.SelectForkRevisions")
+	span.SetAttribute("resource_id", resourceID.String())
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectForkRevisions(resourceID, options)
+}
+
+func (s *tracedStore) InsertKey(key Key) (err error) {
+	span := s.start("store.InsertKey")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertKey(key)
+}
+
+func (s *tracedStore) SelectKey(tenantID string, resourceID uuid.UUID, resourceAddress string) (res Key, err error) {
+	span := s.start("store.SelectKey")
+	span.SetAttribute("resource_id", resourceID.String())
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectKey(tenantID, resourceID, resourceAddress)
+}
+
+func (s *tracedStore) SelectAddressKeys(tenantID, resourceAddress string) (res []Key, err error) {
+	span := s.start("store.SelectAddressKeys")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectAddressKeys(tenantID, resourceAddress)
+}
+
+func (s *tracedStore) DestroyKey(tenantID string, resourceID uuid.UUID, resourceAddress string, destroyedOn time.Time) (err error) {
+	span := s.start("store.DestroyKey")
+	span.SetAttribute("resource_id", resourceID.String())
+	defer func() { span.Finish(err) }()
+
+	return s.store.DestroyKey(tenantID, resourceID, resourceAddress, destroyedOn)
+}
+
+func (s *tracedStore) SelectAuthorResources(authorID string, options Query) (res []uuid.UUID, err error) {
+	span := s.start("store.SelectAuthorResources")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectAuthorResources(authorID, options)
+}
+
+func (s *tracedStore) SelectAddressResources(tenantID, resourceAddress string) (res []uuid.UUID, err error) {
+	span := s.start("store.SelectAddressResources")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectAddressResources(tenantID, resourceAddress)
+}
+
+func (s *tracedStore) SelectResources(options Query) (res []uuid.UUID, err error) {
+	span := s.start("store.SelectResources")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectResources(options)
+}
+
+func (s *tracedStore) InsertAuthorKey(authorKey AuthorKey) (err error) {
+	span := s.start("store.InsertAuthorKey")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertAuthorKey(authorKey)
+}
+
+func (s *tracedStore) SelectAuthorKey(tenantID, authorID, keyID string) (res AuthorKey, err error) {
+	span := s.start("store.SelectAuthorKey")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectAuthorKey(tenantID, authorID, keyID)
+}
+
+func (s *tracedStore) SelectAuthorKeys(tenantID, authorID string) (res []AuthorKey, err error) {
+	span := s.start("store.SelectAuthorKeys")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectAuthorKeys(tenantID, authorID)
+}
+
+func (s *tracedStore) InsertACL(acl ACL) (err error) {
+	span := s.start("store.InsertACL")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertACL(acl)
+}
+
+func (s *tracedStore) SelectACL(resourceID uuid.UUID, options Query) (res ACL, err error) {
+	span := s.start("store.SelectACL")
+	span.SetAttribute("resource_id", resourceID.String())
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectACL(resourceID, options)
+}
+
+func (s *tracedStore) SelectACLRevisions(resourceID uuid.UUID, options Query) (res []ACL, err error) {
+	span := s.start("store.SelectACLRevisions")
+	span.SetAttribute("resource_id", resourceID.String())
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectACLRevisions(resourceID, options)
+}
+
+func (s *tracedStore) AppendLeaves() (res int64, err error) {
+	span := s.start("store.AppendLeaves")
+	defer func() { span.Finish(err) }()
+
+	return s.store.AppendLeaves()
+}
+
+func (s *tracedStore) SelectLeaves(start, end int64) (res []Leaf, err error) {
+	span := s.start("store.SelectLeaves")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectLeaves(start, end)
+}
+
+func (s *tracedStore) SelectLeaf(ledgerID uuid.UUID) (res Leaf, err error) {
+	span := s.start("store.SelectLeaf")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectLeaf(ledgerID)
+}
+
+func (s *tracedStore) InsertNodes(nodes []Node) (err error) {
+	span := s.start("store.InsertNodes")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertNodes(nodes)
+}
+
+func (s *tracedStore) SelectNodes(ids []NodeID) (res []Node, err error) {
+	span := s.start("store.SelectNodes")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectNodes(ids)
+}
+
+func (s *tracedStore) SelectNodesTreeSize() (res int64, err error) {
+	span := s.start("store.SelectNodesTreeSize")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectNodesTreeSize()
+}
+
+func (s *tracedStore) InsertCheckpoint(checkpoint Checkpoint) (err error) {
+	span := s.start("store.InsertCheckpoint")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertCheckpoint(checkpoint)
+}
+
+func (s *tracedStore) SelectCheckpoint(treeSize int64) (res Checkpoint, err error) {
+	span := s.start("store.SelectCheckpoint")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectCheckpoint(treeSize)
+}
+
+func (s *tracedStore) Statistics(options Query) (res Statistics, err error) {
+	span := s.start("store.Statistics")
+	defer func() { span.Finish(err) }()
+
+	return s.store.Statistics(options)
+}
+
+func (s *tracedStore) InsertQuota(quota Quota) (err error) {
+	span := s.start("store.InsertQuota")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertQuota(quota)
+}
+
+func (s *tracedStore) SelectQuota(tenantID, authorID string) (res Quota, err error) {
+	span := s.start("store.SelectQuota")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectQuota(tenantID, authorID)
+}
+
+func (s *tracedStore) SelectUsage(options Query, since time.Time) (res Usage, err error) {
+	span := s.start("store.SelectUsage")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectUsage(options, since)
+}
+
+func (s *tracedStore) InsertWebhook(webhook Webhook) (err error) {
+	span := s.start("store.InsertWebhook")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertWebhook(webhook)
+}
+
+func (s *tracedStore) SelectWebhooks(tenantID string) (res []Webhook, err error) {
+	span := s.start("store.SelectWebhooks")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectWebhooks(tenantID)
+}
+
+func (s *tracedStore) DeleteWebhook(tenantID string, webhookID uuid.UUID) (err error) {
+	span := s.start("store.DeleteWebhook")
+	defer func() { span.Finish(err) }()
+
+	return s.store.DeleteWebhook(tenantID, webhookID)
+}
+
+func (s *tracedStore) InsertDelivery(delivery Delivery) (err error) {
+	span := s.start("store.InsertDelivery")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertDelivery(delivery)
+}
+
+func (s *tracedStore) UpdateDelivery(delivery Delivery) (err error) {
+	span := s.start("store.UpdateDelivery")
+	defer func() { span.Finish(err) }()
+
+	return s.store.UpdateDelivery(delivery)
+}
+
+func (s *tracedStore) SelectDelivery(tenantID string, deliveryID uuid.UUID) (res Delivery, err error) {
+	span := s.start("store.SelectDelivery")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectDelivery(tenantID, deliveryID)
+}
+
+func (s *tracedStore) SelectDeliveries(tenantID, status string) (res []Delivery, err error) {
+	span := s.start("store.SelectDeliveries")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectDeliveries(tenantID, status)
+}
+
+func (s *tracedStore) ClaimPendingDeliveries(before, until time.Time, limit int) (res []Delivery, err error) {
+	span := s.start("store.ClaimPendingDeliveries")
+	defer func() { span.Finish(err) }()
+
+	return s.store.ClaimPendingDeliveries(before, until, limit)
+}
+
+func (s *tracedStore) InsertDeliveryAttempt(deliveryAttempt DeliveryAttempt) (err error) {
+	span := s.start("store.InsertDeliveryAttempt")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertDeliveryAttempt(deliveryAttempt)
+}
+
+func (s *tracedStore) SelectDeliveryAttempts(deliveryID uuid.UUID) (res []DeliveryAttempt, err error) {
+	span := s.start("store.SelectDeliveryAttempts")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectDeliveryAttempts(deliveryID)
+}
+
+func (s *tracedStore) SelectOutbox(after int64, limit int) (res []OutboxRecord, err error) {
+	span := s.start("store.SelectOutbox")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectOutbox(after, limit)
+}
+
+func (s *tracedStore) SelectOutboxCheckpoint(sink string) (res int64, err error) {
+	span := s.start("store.SelectOutboxCheckpoint")
+	defer func() { span.Finish(err) }()
+
+	return s.store.SelectOutboxCheckpoint(sink)
+}
+
+func (s *tracedStore) InsertOutboxCheckpoint(sink string, sequence int64) (err error) {
+	span := s.start("store.InsertOutboxCheckpoint")
+	defer func() { span.Finish(err) }()
+
+	return s.store.InsertOutboxCheckpoint(sink, sequence)
+}
+
+func (s *tracedStore) DeleteOutbox(through int64) (err error) {
+	span := s.start("store.DeleteOutbox")
+	defer func() { span.Finish(err) }()
+
+	return s.store.DeleteOutbox(through)
+}
+
+func (s *tracedStore) LockOutboxRelay(fn func() error) (locked bool, err error) {
+	span := s.start("store.LockOutboxRelay")
+	defer func() { span.Finish(err) }()
+
+	return s.store.LockOutboxRelay(fn)
+}
+
+func (s *tracedStore) Drop() (err error) {
+	span := s.start("store.Drop")
+	defer func() { span.Finish(err) }()
+
+	return s.store.Drop()
+}
+
+func (s *tracedStore) Run() error {
+	return s.store.Run()
+}
+
+func (s *tracedStore) Stop() {
+	s.store.Stop()
+}
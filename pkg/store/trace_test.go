@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/trussle/snowy/pkg/trace"
+	"github.com/trussle/uuid"
+)
+
+type spanRecorder struct {
+	mutex sync.Mutex
+	spans []trace.Span
+}
+
+func (r *spanRecorder) Export(span trace.Span) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func (r *spanRecorder) Close() error { return nil }
+
+func (r *spanRecorder) Spans() []trace.Span {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]trace.Span(nil), r.spans...)
+}
+
+func TestTracedStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("traces bound calls", func(t *testing.T) {
+		var (
+			rec    = &spanRecorder{}
+			tracer = trace.NewTracer(trace.WithExporter(rec))
+			store  = NewTracedStore(NewVirtualStore(), tracer)
+			id     = uuid.MustNew()
+		)
+
+		ctx, root := tracer.Start(context.Background(), "root")
+		bound := WithContext(ctx, store)
+
+		if err := bound.Insert(Entity{ResourceID: id}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bound.Select(uuid.MustNew(), Query{}); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+
+		spans := rec.Spans()
+		if expected, actual := 2, len(spans); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		for i, name := range []string{"store.Insert", "store.Select"} {
+			if expected, actual := name, spans[i].Name; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+			if expected, actual := root.SpanID, spans[i].ParentID; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		}
+		if spans[1].Error == "" {
+			t.Errorf("expected: error, actual: %q", spans[1].Error)
+		}
+	})
+
+	t.Run("unbound calls aren't traced", func(t *testing.T) {
+		var (
+			rec    = &spanRecorder{}
+			tracer = trace.NewTracer(trace.WithExporter(rec))
+			store  = NewTracedStore(NewVirtualStore(), tracer)
+		)
+
+		if err := store.Insert(Entity{ResourceID: uuid.MustNew()}); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := 0, len(rec.Spans()); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("untraced stores are returned as they are", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		if expected, actual := store, WithContext(context.Background(), store); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
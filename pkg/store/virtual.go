@@ -3,6 +3,7 @@ package store
 import (
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/trussle/uuid"
@@ -10,19 +11,40 @@ import (
 
 // virtualStore keeps track of a entity objects.
 type virtualStore struct {
-	mutex    sync.RWMutex
-	entities map[string][]Entity
-	links    map[string]Entity
-	stop     chan chan struct{}
+	mutex       sync.RWMutex
+	entities    map[string][]Entity
+	links       map[string]Entity
+	keys        map[string]Key
+	authorKeys  map[string][]AuthorKey
+	acls        map[string][]ACL
+	quotas      map[string]Quota
+	webhooks    []Webhook
+	deliveries  []Delivery
+	attempts    []DeliveryAttempt
+	outbox      []OutboxRecord
+	sequence    int64
+	relaying    bool
+	sinks       map[string]int64
+	leaves      []Leaf
+	nodes       map[NodeID]Node
+	checkpoints map[int64]Checkpoint
+	stop        chan chan struct{}
 }
 
 // NewVirtualStore creates a new Store with the correct dependencies
 func NewVirtualStore() Store {
 	return &virtualStore{
-		mutex:    sync.RWMutex{},
-		entities: make(map[string][]Entity),
-		links:    make(map[string]Entity),
-		stop:     make(chan chan struct{}),
+		mutex:       sync.RWMutex{},
+		entities:    make(map[string][]Entity),
+		links:       make(map[string]Entity),
+		keys:        make(map[string]Key),
+		authorKeys:  make(map[string][]AuthorKey),
+		acls:        make(map[string][]ACL),
+		quotas:      make(map[string]Quota),
+		sinks:       make(map[string]int64),
+		nodes:       make(map[NodeID]Node),
+		checkpoints: make(map[int64]Checkpoint),
+		stop:        make(chan chan struct{}),
 	}
 }
 
@@ -34,22 +56,115 @@ func (r *virtualStore) Select(resourceID uuid.UUID, query Query) (Entity, error)
 	if len(entities) == 0 {
 		return Entity{}, errNotFound{errors.New("not found")}
 	}
-	return entities[0], nil
+	// The head is the latest revision, as it is in the real store.
+	return entities[len(entities)-1], nil
 }
 
 func (r *virtualStore) Insert(entity Entity) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	return r.insert(entity)
+}
+
+func (r *virtualStore) InsertLedgers(entities []Entity, acls []ACL) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Check every parent before inserting anything, as there's no transaction
+	// to roll back, so that either all of the ledgers are inserted or none.
+	inserted := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		if !entity.ParentID.Zero() {
+			tenantID, ok := inserted[entity.ParentID.String()]
+			if parent, exists := r.links[entity.ParentID.String()]; exists {
+				tenantID, ok = parent.TenantID, true
+			}
+			if !ok || tenantID != entity.TenantID {
+				return errNotFound{errors.Errorf("parent %q not found", entity.ParentID.String())}
+			}
+		}
+		inserted[entity.ID.String()] = entity.TenantID
+	}
+
+	for _, entity := range entities {
+		if err := r.insert(entity); err != nil {
+			return err
+		}
+	}
+	for _, acl := range acls {
+		index := aclIndex(acl.TenantID, acl.ResourceID)
+		r.acls[index] = append(r.acls[index], acl)
+	}
+	return nil
+}
+
+// insert inserts the entity, chained to its parent, along with the change in
+// the outbox. The mutex has to be held.
+func (r *virtualStore) insert(entity Entity) error {
 	// Normalize the tags of the entity
 	entity.Tags = sortTags(entity.Tags)
 
+	// Assign an id if there isn't one, as the real store does, so that the
+	// entity can be linked to.
+	if entity.ID.Zero() {
+		var err error
+		if entity.ID, err = uuid.New(); err != nil {
+			return err
+		}
+	}
+
+	var (
+		parentHash       string
+		parentResourceID uuid.UUID
+	)
+	if !entity.ParentID.Zero() {
+		parent, ok := r.links[entity.ParentID.String()]
+		if !ok || parent.TenantID != entity.TenantID {
+			return errNotFound{errors.Errorf("parent %q not found", entity.ParentID.String())}
+		}
+		parentHash, parentResourceID = parent.Hash, parent.ResourceID
+	}
+	entity.Hash = HashEntity(entity, parentHash)
+
+	record, err := newOutboxRecord(entity, outboxEvent(entity, parentResourceID), time.Now())
+	if err != nil {
+		return err
+	}
+
 	id := entity.ResourceID.String()
 	r.entities[id] = append(r.entities[id], entity)
 	r.links[entity.ID.String()] = entity
+
+	r.sequence++
+	record.Sequence = r.sequence
+	r.outbox = append(r.outbox, record)
 	return nil
 }
 
+func (r *virtualStore) SelectEntity(id uuid.UUID, query Query) (Entity, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if entity, ok := r.links[id.String()]; ok && entity.TenantID == query.Tenant {
+		return entity, nil
+	}
+	return Entity{}, errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) SelectLedgerIDs(ids []uuid.UUID) ([]uuid.UUID, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]uuid.UUID, 0)
+	for _, id := range ids {
+		if _, ok := r.links[id.String()]; ok {
+			res = append(res, id)
+		}
+	}
+	return res, nil
+}
+
 func (r *virtualStore) SelectRevisions(resourceID uuid.UUID, query Query) ([]Entity, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -61,6 +176,10 @@ func (r *virtualStore) SelectRevisions(resourceID uuid.UUID, query Query) ([]Ent
 			return entities[a].CreatedOn.Before(entities[b].CreatedOn)
 		})
 
+		// Filter by tenant before anything else, so that no other tenant is
+		// ever visible.
+		entities = filterTenant(entities, query.Tenant)
+
 		// Filter by authorID before filtering by tags
 		if query.AuthorID != nil && *query.AuthorID != "" {
 			var (
@@ -94,11 +213,12 @@ func (r *virtualStore) SelectRevisions(resourceID uuid.UUID, query Query) ([]Ent
 	return make([]Entity, 0), nil
 }
 
-func (r *virtualStore) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, error) {
+func (r *virtualStore) SelectForkRevisions(resourceID uuid.UUID, query Query) ([]Entity, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	if entities, ok := r.entities[resourceID.String()]; ok {
+		entities = filterTenant(entities, query.Tenant)
 		if len(entities) == 0 {
 			return entities, nil
 		}
@@ -118,7 +238,7 @@ func (r *virtualStore) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, erro
 				break
 			}
 
-			if entity, ok := r.links[last.ParentID.String()]; ok {
+			if entity, ok := r.links[last.ParentID.String()]; ok && entity.TenantID == query.Tenant {
 				last = entity
 				res = append(res, last)
 				continue
@@ -137,17 +257,640 @@ func (r *virtualStore) SelectForkRevisions(resourceID uuid.UUID) ([]Entity, erro
 	return make([]Entity, 0), nil
 }
 
-func (r *virtualStore) Statistics() (Statistics, error) {
+func (r *virtualStore) InsertKey(key Key) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := keyIndex(key.TenantID, key.ResourceID, key.ResourceAddress)
+	if existing, ok := r.keys[index]; !ok || existing.Destroyed() {
+		r.keys[index] = key
+	}
+	return nil
+}
+
+func (r *virtualStore) SelectKey(tenantID string, resourceID uuid.UUID, resourceAddress string) (Key, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if key, ok := r.keys[keyIndex(tenantID, resourceID, resourceAddress)]; ok {
+		return key, nil
+	}
+	return Key{}, errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) SelectAddressKeys(tenantID, resourceAddress string) ([]Key, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]Key, 0)
+	for _, key := range r.keys {
+		if key.TenantID == tenantID && key.ResourceAddress == resourceAddress {
+			res = append(res, key)
+		}
+	}
+	sort.Slice(res, func(a, b int) bool {
+		if !res[a].CreatedOn.Equal(res[b].CreatedOn) {
+			return res[a].CreatedOn.Before(res[b].CreatedOn)
+		}
+		return res[a].ResourceID.String() < res[b].ResourceID.String()
+	})
+	return res, nil
+}
+
+func (r *virtualStore) DestroyKey(tenantID string, resourceID uuid.UUID, resourceAddress string, destroyedOn time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := keyIndex(tenantID, resourceID, resourceAddress)
+	key, ok := r.keys[index]
+	if !ok {
+		key = Key{
+			TenantID:        tenantID,
+			ResourceID:      resourceID,
+			ResourceAddress: resourceAddress,
+			CreatedOn:       destroyedOn,
+		}
+	}
+	if !key.Destroyed() {
+		key.KeyID = ""
+		key.WrappedKey = nil
+		key.DestroyedOn = destroyedOn
+	}
+	r.keys[index] = key
+	return nil
+}
+
+func (r *virtualStore) SelectAuthorResources(authorID string, query Query) ([]uuid.UUID, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]uuid.UUID, 0)
+	for _, entities := range r.entities {
+		for _, v := range entities {
+			if v.AuthorID == authorID && v.TenantID == query.Tenant {
+				res = append(res, v.ResourceID)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func (r *virtualStore) SelectAddressResources(tenantID, resourceAddress string) ([]uuid.UUID, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]uuid.UUID, 0)
+	for _, entities := range r.entities {
+		for _, v := range entities {
+			if v.ResourceAddress == resourceAddress && v.TenantID == tenantID {
+				res = append(res, v.ResourceID)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func (r *virtualStore) SelectResources(query Query) ([]uuid.UUID, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var (
+		res       = make([]uuid.UUID, 0)
+		createdOn = make(map[string]time.Time)
+	)
+	for _, entities := range r.entities {
+		var matched bool
+		for _, v := range filterTenant(entities, query.Tenant) {
+			if query.AuthorID != nil && *query.AuthorID != "" && v.AuthorID != *query.AuthorID {
+				continue
+			}
+			if len(query.Tags) > 0 && !intersection(v.Tags, query.Tags) {
+				continue
+			}
+			matched = true
+			break
+		}
+		if !matched {
+			continue
+		}
+
+		// The resource is ordered by its first revision, not the first one
+		// that matched.
+		for _, v := range filterTenant(entities, query.Tenant) {
+			id := v.ResourceID.String()
+			if first, ok := createdOn[id]; !ok {
+				res = append(res, v.ResourceID)
+				createdOn[id] = v.CreatedOn
+			} else if v.CreatedOn.Before(first) {
+				createdOn[id] = v.CreatedOn
+			}
+		}
+	}
+
+	sort.Slice(res, func(a, b int) bool {
+		x, y := createdOn[res[a].String()], createdOn[res[b].String()]
+		if x.Equal(y) {
+			return res[a].String() < res[b].String()
+		}
+		return x.Before(y)
+	})
+	return res, nil
+}
+
+func (r *virtualStore) InsertAuthorKey(key AuthorKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := authorKeyIndex(key.TenantID, key.AuthorID)
+	for _, v := range r.authorKeys[index] {
+		if v.KeyID == key.KeyID {
+			return nil
+		}
+	}
+	r.authorKeys[index] = append(r.authorKeys[index], key)
+	return nil
+}
+
+func (r *virtualStore) SelectAuthorKey(tenantID, authorID, keyID string) (AuthorKey, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	var stats Statistics
-	for range r.entities {
-		stats.Total++
+	for _, key := range r.authorKeys[authorKeyIndex(tenantID, authorID)] {
+		if key.KeyID == keyID {
+			return key, nil
+		}
 	}
+	return AuthorKey{}, errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) SelectAuthorKeys(tenantID, authorID string) ([]AuthorKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]AuthorKey, 0)
+	return append(res, r.authorKeys[authorKeyIndex(tenantID, authorID)]...), nil
+}
+
+func (r *virtualStore) InsertACL(acl ACL) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := aclIndex(acl.TenantID, acl.ResourceID)
+	r.acls[index] = append(r.acls[index], acl)
+	return nil
+}
+
+func (r *virtualStore) SelectACL(resourceID uuid.UUID, query Query) (ACL, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if acls := r.acls[aclIndex(query.Tenant, resourceID)]; len(acls) > 0 {
+		return acls[len(acls)-1], nil
+	}
+	return ACL{}, errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) SelectACLRevisions(resourceID uuid.UUID, query Query) ([]ACL, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]ACL, 0)
+	return append(res, r.acls[aclIndex(query.Tenant, resourceID)]...), nil
+}
+
+func (r *virtualStore) AppendLeaves() (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	appended := make(map[uuid.UUID]struct{}, len(r.leaves))
+	for _, leaf := range r.leaves {
+		appended[leaf.LedgerID] = struct{}{}
+	}
+
+	var pending []Entity
+	for _, entity := range r.links {
+		if _, ok := appended[entity.ID]; !ok {
+			pending = append(pending, entity)
+		}
+	}
+
+	sort.Slice(pending, func(a, b int) bool {
+		if pending[a].CreatedOn.Equal(pending[b].CreatedOn) {
+			return pending[a].ID.String() < pending[b].ID.String()
+		}
+		return pending[a].CreatedOn.Before(pending[b].CreatedOn)
+	})
+
+	for _, entity := range pending {
+		r.leaves = append(r.leaves, Leaf{
+			Index:    int64(len(r.leaves)),
+			LedgerID: entity.ID,
+			Hash:     entity.Hash,
+		})
+	}
+	return int64(len(r.leaves)), nil
+}
+
+func (r *virtualStore) SelectLeaves(start, end int64) ([]Leaf, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if size := int64(len(r.leaves)); end > size {
+		end = size
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	res := make([]Leaf, 0)
+	if start < end {
+		res = append(res, r.leaves[start:end]...)
+	}
+	return res, nil
+}
+
+func (r *virtualStore) SelectLeaf(ledgerID uuid.UUID) (Leaf, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, leaf := range r.leaves {
+		if leaf.LedgerID.Equals(ledgerID) {
+			return leaf, nil
+		}
+	}
+	return Leaf{}, errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) InsertNodes(nodes []Node) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, v := range nodes {
+		id := NodeID{Level: v.Level, Index: v.Index}
+		if _, ok := r.nodes[id]; !ok {
+			r.nodes[id] = v
+		}
+	}
+	return nil
+}
+
+func (r *virtualStore) SelectNodes(ids []NodeID) ([]Node, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]Node, 0, len(ids))
+	for _, id := range ids {
+		if node, ok := r.nodes[id]; ok {
+			res = append(res, node)
+		}
+	}
+	return res, nil
+}
+
+func (r *virtualStore) SelectNodesTreeSize() (int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var size int64
+	for id := range r.nodes {
+		if id.Level == 0 && id.Index >= size {
+			size = id.Index + 1
+		}
+	}
+	return size, nil
+}
+
+func (r *virtualStore) InsertCheckpoint(checkpoint Checkpoint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.checkpoints[checkpoint.TreeSize]; !ok {
+		r.checkpoints[checkpoint.TreeSize] = checkpoint
+	}
+	return nil
+}
+
+func (r *virtualStore) SelectCheckpoint(treeSize int64) (Checkpoint, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if treeSize == 0 {
+		for size := range r.checkpoints {
+			if size > treeSize {
+				treeSize = size
+			}
+		}
+	}
+
+	if checkpoint, ok := r.checkpoints[treeSize]; ok {
+		return checkpoint, nil
+	}
+	return Checkpoint{}, errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) Statistics(query Query) (Statistics, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var (
+		now   = time.Now()
+		stats = Statistics{
+			ContentTypes: make(map[string]int64),
+			Inserts:      insertWindows(),
+		}
+		addresses = make(map[string]struct{})
+		revisions = make(map[int]int)
+		authors   = make(map[string]int)
+		tags      = make(map[string]int)
+	)
+	for _, entities := range r.entities {
+		var matched int
+		for _, v := range filterTenant(entities, query.Tenant) {
+			if query.AuthorID != nil && *query.AuthorID != "" && v.AuthorID != *query.AuthorID {
+				continue
+			}
+			if len(query.Tags) > 0 && !intersection(v.Tags, query.Tags) {
+				continue
+			}
+			matched++
+
+			stats.Total++
+			if _, ok := addresses[v.ResourceAddress]; !ok {
+				addresses[v.ResourceAddress] = struct{}{}
+				stats.Bytes += v.ResourceSize
+				stats.ContentTypes[v.ResourceContentType] += v.ResourceSize
+			}
+			authors[v.AuthorID]++
+			for _, tag := range v.Tags {
+				tags[tag]++
+			}
+			for k, window := range stats.Inserts {
+				if !v.CreatedOn.Before(now.Add(-window.Window)) {
+					stats.Inserts[k].Ledgers++
+				}
+			}
+		}
+		if matched > 0 {
+			stats.Resources++
+			revisions[matched]++
+		}
+	}
+
+	stats.Revisions = bucketRevisions(revisions)
+	stats.Authors = topCounts(authors, statisticsTop)
+	stats.Tags = topCounts(tags, statisticsTop)
 	return stats, nil
 }
 
+func (r *virtualStore) InsertQuota(quota Quota) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.quotas[authorKeyIndex(quota.TenantID, quota.AuthorID)] = quota
+	return nil
+}
+
+func (r *virtualStore) SelectQuota(tenantID, authorID string) (Quota, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	quota, ok := r.quotas[authorKeyIndex(tenantID, authorID)]
+	if !ok {
+		return Quota{}, errNotFound{errors.New("not found")}
+	}
+	return quota, nil
+}
+
+func (r *virtualStore) SelectUsage(query Query, since time.Time) (Usage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var (
+		usage     Usage
+		addresses = make(map[string]struct{})
+	)
+	for _, entities := range r.entities {
+		var matched bool
+		for _, v := range filterTenant(entities, query.Tenant) {
+			if query.AuthorID != nil && *query.AuthorID != "" && v.AuthorID != *query.AuthorID {
+				continue
+			}
+			matched = true
+
+			if _, ok := addresses[v.ResourceAddress]; !ok {
+				addresses[v.ResourceAddress] = struct{}{}
+				usage.Bytes += v.ResourceSize
+			}
+			if !v.CreatedOn.Before(since) {
+				usage.Revisions++
+			}
+		}
+		if matched {
+			usage.Resources++
+		}
+	}
+	return usage, nil
+}
+
+func (r *virtualStore) InsertWebhook(webhook Webhook) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.webhooks = append(r.webhooks, webhook)
+	return nil
+}
+
+func (r *virtualStore) SelectWebhooks(tenantID string) ([]Webhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]Webhook, 0)
+	for _, v := range r.webhooks {
+		if v.TenantID == tenantID {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+func (r *virtualStore) DeleteWebhook(tenantID string, webhookID uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for k, v := range r.webhooks {
+		if v.TenantID == tenantID && v.ID.Equals(webhookID) {
+			r.webhooks = append(r.webhooks[:k], r.webhooks[k+1:]...)
+			return nil
+		}
+	}
+	return errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) InsertDelivery(delivery Delivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delivery.URL, delivery.Secret = "", ""
+	r.deliveries = append(r.deliveries, delivery)
+	return nil
+}
+
+func (r *virtualStore) UpdateDelivery(delivery Delivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for k, v := range r.deliveries {
+		if v.ID.Equals(delivery.ID) {
+			v.Status = delivery.Status
+			v.Attempts = delivery.Attempts
+			v.LastError = delivery.LastError
+			v.NextAttemptOn = delivery.NextAttemptOn
+			v.DeliveredOn = delivery.DeliveredOn
+			r.deliveries[k] = v
+			return nil
+		}
+	}
+	return errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) SelectDelivery(tenantID string, deliveryID uuid.UUID) (Delivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, v := range r.deliveries {
+		if v.TenantID == tenantID && v.ID.Equals(deliveryID) {
+			return v, nil
+		}
+	}
+	return Delivery{}, errNotFound{errors.New("not found")}
+}
+
+func (r *virtualStore) SelectDeliveries(tenantID, status string) ([]Delivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]Delivery, 0)
+	for _, v := range r.deliveries {
+		if v.TenantID == tenantID && v.Status == status {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+func (r *virtualStore) ClaimPendingDeliveries(before, until time.Time, limit int) ([]Delivery, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	webhooks := make(map[uuid.UUID]Webhook, len(r.webhooks))
+	for _, v := range r.webhooks {
+		webhooks[v.ID] = v
+	}
+
+	res := make([]Delivery, 0)
+	for k, v := range r.deliveries {
+		if len(res) >= limit {
+			break
+		}
+		webhook, ok := webhooks[v.WebhookID]
+		if !ok || v.Status != DeliveryStatusPending || v.NextAttemptOn.After(before) {
+			continue
+		}
+		v.NextAttemptOn = until
+		r.deliveries[k] = v
+
+		v.URL, v.Secret = webhook.URL, webhook.Secret
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func (r *virtualStore) InsertDeliveryAttempt(attempt DeliveryAttempt) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+func (r *virtualStore) SelectDeliveryAttempts(deliveryID uuid.UUID) ([]DeliveryAttempt, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]DeliveryAttempt, 0)
+	for _, v := range r.attempts {
+		if v.DeliveryID.Equals(deliveryID) {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+func (r *virtualStore) SelectOutbox(after int64, limit int) ([]OutboxRecord, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]OutboxRecord, 0)
+	for _, v := range r.outbox {
+		if len(res) >= limit {
+			break
+		}
+		if v.Sequence > after {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+func (r *virtualStore) SelectOutboxCheckpoint(sink string) (int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.sinks[sink], nil
+}
+
+func (r *virtualStore) InsertOutboxCheckpoint(sink string, sequence int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sinks[sink] = sequence
+	return nil
+}
+
+func (r *virtualStore) DeleteOutbox(through int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	res := make([]OutboxRecord, 0, len(r.outbox))
+	for _, v := range r.outbox {
+		if v.Sequence > through {
+			res = append(res, v)
+		}
+	}
+	r.outbox = res
+	return nil
+}
+
+func (r *virtualStore) LockOutboxRelay(fn func() error) (bool, error) {
+	r.mutex.Lock()
+	if r.relaying {
+		r.mutex.Unlock()
+		return false, nil
+	}
+	r.relaying = true
+	r.mutex.Unlock()
+
+	defer func() {
+		r.mutex.Lock()
+		r.relaying = false
+		r.mutex.Unlock()
+	}()
+
+	return true, fn()
+}
+
 // Run manages the store, keeping the store reliable.
 func (r *virtualStore) Run() error {
 	for {
@@ -174,6 +917,18 @@ func (r *virtualStore) Drop() error {
 
 	r.entities = make(map[string][]Entity)
 	r.links = make(map[string]Entity)
+	r.keys = make(map[string]Key)
+	r.authorKeys = make(map[string][]AuthorKey)
+	r.acls = make(map[string][]ACL)
+	r.quotas = make(map[string]Quota)
+	r.webhooks = nil
+	r.deliveries = nil
+	r.attempts = nil
+	r.outbox = nil
+	r.sinks = make(map[string]int64)
+	r.leaves = nil
+	r.nodes = make(map[NodeID]Node)
+	r.checkpoints = make(map[int64]Checkpoint)
 	return nil
 }
 
@@ -191,3 +946,32 @@ func intersection(a, b []string) bool {
 	}
 	return false
 }
+
+// filterTenant returns the entities that belong to the tenant.
+func filterTenant(entities []Entity, tenant string) []Entity {
+	res := make([]Entity, 0, len(entities))
+	for _, v := range entities {
+		if v.TenantID == tenant {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// aclIndex returns the index of the access control lists of a resource with
+// in a tenant.
+func aclIndex(tenantID string, resourceID uuid.UUID) string {
+	return tenantID + ":" + resourceID.String()
+}
+
+// keyIndex returns the index of the data key for the content of a resource
+// with in a tenant.
+func keyIndex(tenantID string, resourceID uuid.UUID, resourceAddress string) string {
+	return tenantID + ":" + resourceID.String() + ":" + resourceAddress
+}
+
+// authorKeyIndex returns the index of the author keys for an author with in a
+// tenant. Tenant ids can never contain a colon, so the index is unambiguous.
+func authorKeyIndex(tenantID, authorID string) string {
+	return tenantID + ":" + authorID
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"reflect"
 	"testing"
 	"testing/quick"
 	"time"
@@ -142,7 +143,7 @@ func TestVirtualStore(t *testing.T) {
 		store.Insert(b)
 		store.Insert(c)
 
-		res, err := store.SelectForkRevisions(c.ResourceID)
+		res, err := store.SelectForkRevisions(c.ResourceID, Query{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -160,6 +161,99 @@ func TestVirtualStore(t *testing.T) {
 			t.Errorf("expected: %s, actual: %s", expected, actual)
 		}
 	})
+
+	t.Run("hash chain", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			a = Entity{ID: uuid.MustNew(), ParentID: uuid.Empty, ResourceID: uuid.MustNew(), CreatedOn: time.Now().Add(-time.Minute)}
+			b = Entity{ID: uuid.MustNew(), ParentID: a.ID, ResourceID: a.ResourceID, CreatedOn: time.Now()}
+		)
+
+		store.Insert(a)
+		store.Insert(b)
+
+		res, err := store.SelectForkRevisions(b.ResourceID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := 2, len(res); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := HashEntity(a, ""), res[0].Hash; expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+		if expected, actual := HashEntity(b, res[0].Hash), res[1].Hash; expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+	})
+
+	t.Run("insert ledgers", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			a   = Entity{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), CreatedOn: time.Now()}
+			b   = Entity{ID: uuid.MustNew(), ParentID: a.ID, ResourceID: a.ResourceID, CreatedOn: time.Now()}
+			acl = ACL{ResourceID: a.ResourceID, Owner: "owner", CreatedOn: time.Now()}
+		)
+		if err := store.InsertLedgers([]Entity{a, b}, []ACL{acl}); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := store.Select(a.ResourceID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := b.ID, res.ID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if _, err := store.SelectACL(a.ResourceID, Query{}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("insert ledgers with a missing parent inserts nothing", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			a   = Entity{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), CreatedOn: time.Now()}
+			b   = Entity{ID: uuid.MustNew(), ParentID: uuid.MustNew(), ResourceID: a.ResourceID, CreatedOn: time.Now()}
+			acl = ACL{ResourceID: a.ResourceID, Owner: "owner", CreatedOn: time.Now()}
+		)
+		if expected, actual := true, ErrNotFound(store.InsertLedgers([]Entity{a, b}, []ACL{acl})); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		if _, err := store.SelectEntity(a.ID, Query{}); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+		if _, err := store.SelectACL(a.ResourceID, Query{}); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			a = Entity{ID: uuid.MustNew(), ParentID: uuid.Empty, ResourceID: uuid.MustNew(), TenantID: "a", CreatedOn: time.Now()}
+			b = Entity{ID: uuid.MustNew(), ParentID: a.ID, ResourceID: a.ResourceID, TenantID: "b", CreatedOn: time.Now()}
+			c = Entity{ID: uuid.MustNew(), ParentID: uuid.MustNew(), ResourceID: uuid.MustNew(), TenantID: "a", CreatedOn: time.Now()}
+		)
+
+		if err := store.Insert(a); err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range []Entity{b, c} {
+			if expected, actual := true, ErrNotFound(store.Insert(v)); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+		}
+		if _, err := store.SelectEntity(c.ID, Query{Tenant: "a"}); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+	})
 }
 
 func TestVirtualStoreWithQuery(t *testing.T) {
@@ -390,3 +484,1155 @@ func TestVirtualStoreWithQuery(t *testing.T) {
 		}
 	})
 }
+
+func TestVirtualStoreKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("select key when empty", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		_, err := store.SelectKey("", uuid.MustNew(), "address")
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert key then select", func(t *testing.T) {
+		fn := func(resourceID uuid.UUID, address, keyID string, wrapped, other []byte) bool {
+			store := NewVirtualStore()
+
+			if err := store.InsertKey(Key{
+				ResourceID:      resourceID,
+				ResourceAddress: address,
+				KeyID:           keyID,
+				WrappedKey:      wrapped,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			// Inserting a key for the same resource and address keeps the
+			// existing key.
+			if err := store.InsertKey(Key{
+				ResourceID:      resourceID,
+				ResourceAddress: address,
+				KeyID:           keyID,
+				WrappedKey:      other,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := store.SelectKey("", resourceID, address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return key.ResourceID.Equals(resourceID) &&
+				key.ResourceAddress == address &&
+				key.KeyID == keyID &&
+				reflect.DeepEqual(key.WrappedKey, wrapped) &&
+				!key.Destroyed()
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("keys are scoped to the tenant and resource", func(t *testing.T) {
+		var (
+			store = NewVirtualStore()
+			a     = uuid.MustNew()
+			b     = uuid.MustNew()
+		)
+
+		for _, key := range []Key{
+			{TenantID: "acme", ResourceID: a, ResourceAddress: "address", KeyID: "a"},
+			{TenantID: "acme", ResourceID: b, ResourceAddress: "address", KeyID: "b"},
+			{TenantID: "other", ResourceID: a, ResourceAddress: "address", KeyID: "c"},
+		} {
+			if err := store.InsertKey(key); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		key, err := store.SelectKey("acme", b, "address")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "b", key.KeyID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		keys, err := store.SelectAddressKeys("acme", "address")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(keys); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		// Destroying the key of one resource leaves the others alone.
+		if err := store.DestroyKey("acme", a, "address", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if key, err = store.SelectKey("acme", b, "address"); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := false, key.Destroyed(); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if key, err = store.SelectKey("other", a, "address"); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := false, key.Destroyed(); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("destroy key", func(t *testing.T) {
+		fn := func(resourceID uuid.UUID, address, keyID string, wrapped []byte) bool {
+			var (
+				store       = NewVirtualStore()
+				destroyedOn = time.Now()
+			)
+
+			if err := store.InsertKey(Key{
+				ResourceID:      resourceID,
+				ResourceAddress: address,
+				KeyID:           keyID,
+				WrappedKey:      wrapped,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.DestroyKey("", resourceID, address, destroyedOn); err != nil {
+				t.Fatal(err)
+			}
+
+			// Destroying a key again keeps the original destruction time.
+			if err := store.DestroyKey("", resourceID, address, destroyedOn.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := store.SelectKey("", resourceID, address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return key.Destroyed() &&
+				key.DestroyedOn.Equal(destroyedOn) &&
+				len(key.WrappedKey) == 0
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("insert replaces destroyed key", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		if err := store.DestroyKey("", uuid.Empty, "address", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertKey(Key{
+			ResourceAddress: "address",
+			KeyID:           "key",
+			WrappedKey:      []byte("wrapped"),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		key, err := store.SelectKey("", uuid.Empty, "address")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := false, key.Destroyed(); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+		if expected, actual := "key", key.KeyID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("destroy missing key", func(t *testing.T) {
+		var (
+			store      = NewVirtualStore()
+			resourceID = uuid.MustNew()
+		)
+
+		if err := store.DestroyKey("", resourceID, "address", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+
+		key, err := store.SelectKey("", resourceID, "address")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := true, key.Destroyed(); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("select author resources", func(t *testing.T) {
+		fn := func(a, b uuid.UUID, authorID string) bool {
+			store := NewVirtualStore()
+
+			for _, entity := range []Entity{
+				{ResourceID: a, AuthorID: authorID},
+				{ResourceID: a, AuthorID: authorID},
+				{ResourceID: b, AuthorID: authorID + "-other"},
+			} {
+				if err := store.Insert(entity); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			resourceIDs, err := store.SelectAuthorResources(authorID, Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return len(resourceIDs) == 1 && resourceIDs[0].Equals(a)
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestVirtualStoreCheckpoints(t *testing.T) {
+	t.Parallel()
+
+	insert := func(t *testing.T, store Store, n int) []uuid.UUID {
+		now := time.Now()
+
+		res := make([]uuid.UUID, n)
+		for k := range res {
+			entity := Entity{
+				ID:         uuid.MustNew(),
+				ResourceID: uuid.MustNew(),
+				Name:       "name",
+				CreatedOn:  now.Add(time.Duration(k) * time.Second),
+			}
+			if err := store.Insert(entity); err != nil {
+				t.Fatal(err)
+			}
+			res[k] = entity.ID
+		}
+		return res
+	}
+
+	t.Run("append leaves when empty", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		size, err := store.AppendLeaves()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(0), size; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("append leaves", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		ids := insert(t, store, 3)
+		size, err := store.AppendLeaves()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(3), size; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		// New leaves are appended after the existing leaves.
+		more := insert(t, store, 2)
+		if size, err = store.AppendLeaves(); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(5), size; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		leaves, err := store.SelectLeaves(0, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, id := range append(ids, more...) {
+			if expected, actual := int64(k), leaves[k].Index; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+			if expected, actual := id, leaves[k].LedgerID; !expected.Equals(actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+
+		leaf, err := store.SelectLeaf(more[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(3), leaf.Index; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("select leaf not found", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		_, err := store.SelectLeaf(uuid.MustNew())
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("select checkpoint when empty", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		_, err := store.SelectCheckpoint(0)
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert checkpoints then select", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		for _, size := range []int64{2, 5} {
+			if err := store.InsertCheckpoint(Checkpoint{
+				TreeSize: size,
+				KeyID:    "key",
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Inserting a checkpoint for the same size keeps the existing one.
+		if err := store.InsertCheckpoint(Checkpoint{
+			TreeSize: 2,
+			KeyID:    "other",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		latest, err := store.SelectCheckpoint(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(5), latest.TreeSize; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		checkpoint, err := store.SelectCheckpoint(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "key", checkpoint.KeyID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+	t.Run("insert nodes then select", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		if err := store.InsertNodes([]Node{
+			{Level: 0, Index: 0, Hash: []byte("a")},
+			{Level: 0, Index: 1, Hash: []byte("b")},
+			{Level: 1, Index: 0, Hash: []byte("ab")},
+			{Level: 0, Index: 2, Hash: []byte("c")},
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Inserting a node that exists keeps the existing one.
+		if err := store.InsertNodes([]Node{{Level: 1, Index: 0, Hash: []byte("other")}}); err != nil {
+			t.Fatal(err)
+		}
+
+		size, err := store.SelectNodesTreeSize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(3), size; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		nodes, err := store.SelectNodes([]NodeID{{Level: 1, Index: 0}, {Level: 1, Index: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(nodes); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "ab", string(nodes[0].Hash); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+}
+
+func TestVirtualStoreAuthorKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("select author key when empty", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		_, err := store.SelectAuthorKey("", "author", "key")
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert author keys then select", func(t *testing.T) {
+		fn := func(authorID, keyID string, publicKey, other []byte) bool {
+			store := NewVirtualStore()
+
+			if err := store.InsertAuthorKey(AuthorKey{
+				AuthorID:  authorID,
+				KeyID:     keyID,
+				PublicKey: publicKey,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			// Inserting the same key id keeps the existing key.
+			if err := store.InsertAuthorKey(AuthorKey{
+				AuthorID:  authorID,
+				KeyID:     keyID,
+				PublicKey: other,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.InsertAuthorKey(AuthorKey{
+				AuthorID:  authorID,
+				KeyID:     keyID + "-other",
+				PublicKey: other,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := store.SelectAuthorKey("", authorID, keyID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			keys, err := store.SelectAuthorKeys("", authorID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return key.AuthorID == authorID &&
+				reflect.DeepEqual(key.PublicKey, publicKey) &&
+				len(keys) == 2
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestVirtualStoreACLs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("select acl when empty", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		_, err := store.SelectACL(uuid.MustNew(), Query{})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert acls then select", func(t *testing.T) {
+		fn := func(res uuid.UUID, owner string, readers, writers generators.ASCIISlice) bool {
+			store := NewVirtualStore()
+
+			if err := store.InsertACL(ACL{
+				ResourceID: res,
+				Owner:      owner,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.InsertACL(ACL{
+				ResourceID: res,
+				Owner:      owner,
+				Readers:    readers.Slice(),
+				Writers:    writers.Slice(),
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			acl, err := store.SelectACL(res, Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			acls, err := store.SelectACLRevisions(res, Query{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return acl.Owner == owner &&
+				reflect.DeepEqual(acl.Readers, readers.Slice()) &&
+				reflect.DeepEqual(acl.Writers, writers.Slice()) &&
+				len(acls) == 2 &&
+				len(acls[0].Readers) == 0
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("acls are scoped to the tenant", func(t *testing.T) {
+		var (
+			store = NewVirtualStore()
+			res   = uuid.MustNew()
+		)
+
+		if err := store.InsertACL(ACL{
+			TenantID:   "acme",
+			ResourceID: res,
+			Owner:      "owner",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := store.SelectACL(res, Query{Tenant: "other"})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		acls, err := store.SelectACLRevisions(res, Query{Tenant: "other"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(acls); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		acl, err := store.SelectACL(res, Query{Tenant: "acme"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "owner", acl.Owner; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+}
+
+func TestVirtualStoreTenants(t *testing.T) {
+	t.Parallel()
+
+	t.Run("select is scoped to the tenant", func(t *testing.T) {
+		fn := func(resourceID uuid.UUID) bool {
+			store := NewVirtualStore()
+
+			if err := store.Insert(Entity{ResourceID: resourceID, TenantID: "acme"}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := store.Select(resourceID, Query{Tenant: "acme"}); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := store.Select(resourceID, Query{Tenant: "other"})
+			if expected, actual := true, ErrNotFound(err); expected != actual {
+				t.Errorf("expected: %t, actual: %t", expected, actual)
+			}
+
+			_, err = store.Select(resourceID, Query{})
+			return ErrNotFound(err)
+		}
+
+		if err := quick.Check(fn, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("select entity is scoped to the tenant", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		entity := Entity{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), TenantID: "acme"}
+		if err := store.Insert(entity); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := store.SelectEntity(entity.ID, Query{Tenant: "acme"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := entity.ResourceID, res.ResourceID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		_, err = store.SelectEntity(entity.ID, Query{Tenant: "other"})
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("parents are scoped to the tenant", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		parent := Entity{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), TenantID: "acme"}
+		if err := store.Insert(parent); err != nil {
+			t.Fatal(err)
+		}
+
+		// A child in another tenant claiming the parent isn't chained to it.
+		child := Entity{ID: uuid.MustNew(), ParentID: parent.ID, ResourceID: uuid.MustNew(), TenantID: "other"}
+		if expected, actual := true, ErrNotFound(store.Insert(child)); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		if _, err := store.SelectEntity(child.ID, Query{Tenant: "other"}); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+	})
+
+	t.Run("ledger ids are across the tenants", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			a = Entity{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), TenantID: "acme"}
+			b = Entity{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), TenantID: "other"}
+		)
+		for _, v := range []Entity{a, b} {
+			if err := store.Insert(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		res, err := store.SelectLedgerIDs([]uuid.UUID{a.ID, uuid.MustNew(), b.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := []uuid.UUID{a.ID, b.ID}, res; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("fork revisions are scoped to the tenant", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			a = Entity{ID: uuid.MustNew(), ResourceID: uuid.MustNew(), TenantID: "acme", CreatedOn: time.Now().Add(-time.Second)}
+			b = Entity{ID: uuid.MustNew(), ParentID: a.ID, ResourceID: uuid.MustNew(), TenantID: "acme", CreatedOn: time.Now()}
+		)
+
+		store.Insert(a)
+		store.Insert(b)
+
+		res, err := store.SelectForkRevisions(b.ResourceID, Query{Tenant: "acme"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(res); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		res, err = store.SelectForkRevisions(b.ResourceID, Query{Tenant: "other"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(res); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("author resources are scoped to the tenant", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		store.Insert(Entity{ResourceID: uuid.MustNew(), AuthorID: "author", TenantID: "acme"})
+		store.Insert(Entity{ResourceID: uuid.MustNew(), AuthorID: "author", TenantID: "other"})
+
+		resourceIDs, err := store.SelectAuthorResources("author", Query{Tenant: "acme"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(resourceIDs); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("resources are filtered and ordered by their first revision", func(t *testing.T) {
+		var (
+			store    = NewVirtualStore()
+			now      = time.Now()
+			a, b, c  = uuid.MustNew(), uuid.MustNew(), uuid.MustNew()
+			authorID = "bob"
+		)
+
+		store.Insert(Entity{ResourceID: a, TenantID: "acme", AuthorID: "alice", Tags: []string{"red"}, CreatedOn: now.Add(2 * time.Minute)})
+		store.Insert(Entity{ResourceID: b, TenantID: "acme", AuthorID: "alice", CreatedOn: now.Add(time.Minute)})
+		store.Insert(Entity{ResourceID: a, TenantID: "acme", AuthorID: "bob", CreatedOn: now.Add(3 * time.Minute)})
+		store.Insert(Entity{ResourceID: c, TenantID: "other", AuthorID: "bob", Tags: []string{"red"}, CreatedOn: now})
+
+		for _, test := range []struct {
+			query    Query
+			expected []uuid.UUID
+		}{
+			{Query{Tenant: "acme"}, []uuid.UUID{b, a}},
+			{Query{Tenant: "acme", AuthorID: &authorID}, []uuid.UUID{a}},
+			{Query{Tenant: "acme", Tags: []string{"red"}}, []uuid.UUID{a}},
+			{Query{Tenant: "other"}, []uuid.UUID{c}},
+			{Query{}, []uuid.UUID{}},
+		} {
+			resourceIDs, err := store.SelectResources(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := test.expected, resourceIDs; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+	})
+
+	t.Run("author keys are scoped to the tenant", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		if err := store.InsertAuthorKey(AuthorKey{TenantID: "acme", AuthorID: "author", KeyID: "key"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.SelectAuthorKey("acme", "author", "key"); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := store.SelectAuthorKey("other", "author", "key")
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		keys, err := store.SelectAuthorKeys("", "author")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(keys); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("statistics are per tenant", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		store.Insert(Entity{ResourceID: uuid.MustNew(), TenantID: "acme"})
+		store.Insert(Entity{ResourceID: uuid.MustNew(), TenantID: "acme"})
+		store.Insert(Entity{ResourceID: uuid.MustNew()})
+
+		for tenant, total := range map[string]int{"acme": 2, "": 1, "other": 0} {
+			stats, err := store.Statistics(Query{Tenant: tenant})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := total, stats.Total; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+		}
+	})
+
+	t.Run("statistics", func(t *testing.T) {
+		var (
+			store = NewVirtualStore()
+			now   = time.Now()
+			a, b  = uuid.MustNew(), uuid.MustNew()
+		)
+
+		store.Insert(Entity{ResourceID: a, ResourceAddress: "x", ResourceSize: 2, ResourceContentType: "text/plain", AuthorID: "alice", Tags: []string{"red"}, CreatedOn: now.Add(-48 * time.Hour)})
+		store.Insert(Entity{ResourceID: a, ResourceAddress: "y", ResourceSize: 3, ResourceContentType: "text/plain", AuthorID: "alice", Tags: []string{"red", "blue"}, CreatedOn: now})
+		store.Insert(Entity{ResourceID: b, ResourceAddress: "x", ResourceSize: 2, ResourceContentType: "text/plain", AuthorID: "bob", Tags: []string{"blue"}, CreatedOn: now})
+		store.Insert(Entity{ResourceID: b, ResourceAddress: "z", ResourceSize: 5, ResourceContentType: "image/png", AuthorID: "bob", CreatedOn: now})
+		store.Insert(Entity{ResourceID: b, ResourceAddress: "z", ResourceSize: 5, ResourceContentType: "image/png", AuthorID: "bob", CreatedOn: now})
+
+		stats, err := store.Statistics(Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 5, stats.Total; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 2, stats.Resources; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int64(10), stats.Bytes; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := map[string]int64{"text/plain": 5, "image/png": 5}, stats.ContentTypes; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []RevisionBucket{
+			{Min: 1, Max: 1},
+			{Min: 2, Max: 2, Resources: 1},
+			{Min: 3, Max: 5, Resources: 1},
+			{Min: 6, Max: 10},
+			{Min: 11, Max: 50},
+			{Min: 51, Max: 100},
+			{Min: 101},
+		}, stats.Revisions; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []Count{{"bob", 3}, {"alice", 2}}, stats.Authors; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []Count{{"blue", 2}, {"red", 2}}, stats.Tags; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []InsertWindow{
+			{Window: time.Hour, Ledgers: 4},
+			{Window: 24 * time.Hour, Ledgers: 4},
+			{Window: 7 * 24 * time.Hour, Ledgers: 5},
+		}, stats.Inserts; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("statistics filtered by author and tags", func(t *testing.T) {
+		var (
+			store    = NewVirtualStore()
+			a, b     = uuid.MustNew(), uuid.MustNew()
+			authorID = "alice"
+		)
+
+		store.Insert(Entity{ResourceID: a, ResourceAddress: "x", ResourceSize: 2, AuthorID: "alice", Tags: []string{"red"}})
+		store.Insert(Entity{ResourceID: a, ResourceAddress: "y", ResourceSize: 3, AuthorID: "alice"})
+		store.Insert(Entity{ResourceID: b, ResourceAddress: "z", ResourceSize: 5, AuthorID: "bob", Tags: []string{"red"}})
+
+		stats, err := store.Statistics(Query{AuthorID: &authorID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, stats.Total; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 1, stats.Resources; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		stats, err = store.Statistics(Query{Tags: []string{"red"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, stats.Total; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 2, stats.Resources; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int64(7), stats.Bytes; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func TestVirtualStoreQuotas(t *testing.T) {
+	t.Parallel()
+
+	t.Run("select quota not found", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		_, err := store.SelectQuota("acme", "author")
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("insert quota replaces", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		if err := store.InsertQuota(Quota{TenantID: "acme", AuthorID: "author", MaxBytes: 10}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.InsertQuota(Quota{TenantID: "acme", AuthorID: "author", MaxBytes: 20}); err != nil {
+			t.Fatal(err)
+		}
+
+		quota, err := store.SelectQuota("acme", "author")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(20), quota.MaxBytes; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		_, err = store.SelectQuota("other", "author")
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			now        = time.Now()
+			resourceID = uuid.MustNew()
+		)
+		for _, entity := range []Entity{
+			{ResourceID: resourceID, ResourceAddress: "a", ResourceSize: 10, AuthorID: "author", TenantID: "acme", CreatedOn: now.Add(-48 * time.Hour)},
+			{ResourceID: resourceID, ResourceAddress: "a", ResourceSize: 10, AuthorID: "author", TenantID: "acme", CreatedOn: now},
+			{ResourceID: uuid.MustNew(), ResourceAddress: "b", ResourceSize: 5, AuthorID: "other", TenantID: "acme", CreatedOn: now},
+			{ResourceID: uuid.MustNew(), ResourceAddress: "c", ResourceSize: 7, AuthorID: "author", TenantID: "other", CreatedOn: now},
+		} {
+			if err := store.Insert(entity); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		authorID := "author"
+		usage, err := store.SelectUsage(Query{Tenant: "acme", AuthorID: &authorID}, now.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := (Usage{Bytes: 10, Resources: 1, Revisions: 1}), usage; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		usage, err = store.SelectUsage(Query{Tenant: "acme"}, now.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := (Usage{Bytes: 15, Resources: 2, Revisions: 2}), usage; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestVirtualStoreWebhooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("delete webhook not found", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		err := store.DeleteWebhook("acme", uuid.MustNew())
+		if expected, actual := true, ErrNotFound(err); expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+	})
+
+	t.Run("webhooks are scoped by tenant", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		webhook := Webhook{ID: uuid.MustNew(), TenantID: "acme", URL: "http://example.com"}
+		if err := store.InsertWebhook(webhook); err != nil {
+			t.Fatal(err)
+		}
+
+		webhooks, err := store.SelectWebhooks("other")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(webhooks); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		if err := store.DeleteWebhook("other", webhook.ID); !ErrNotFound(err) {
+			t.Errorf("expected: not found, actual: %v", err)
+		}
+		if err := store.DeleteWebhook("acme", webhook.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		webhooks, err = store.SelectWebhooks("acme")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(webhooks); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("pending deliveries", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			now     = time.Now()
+			webhook = Webhook{ID: uuid.MustNew(), TenantID: "acme", URL: "http://example.com", Secret: "secret"}
+		)
+		if err := store.InsertWebhook(webhook); err != nil {
+			t.Fatal(err)
+		}
+
+		due := Delivery{ID: uuid.MustNew(), WebhookID: webhook.ID, TenantID: "acme", Status: DeliveryStatusPending, NextAttemptOn: now.Add(-time.Minute)}
+		for _, delivery := range []Delivery{
+			due,
+			{ID: uuid.MustNew(), WebhookID: webhook.ID, TenantID: "acme", Status: DeliveryStatusPending, NextAttemptOn: now.Add(time.Minute)},
+			{ID: uuid.MustNew(), WebhookID: webhook.ID, TenantID: "acme", Status: DeliveryStatusDead, NextAttemptOn: now.Add(-time.Minute)},
+			{ID: uuid.MustNew(), WebhookID: uuid.MustNew(), TenantID: "acme", Status: DeliveryStatusPending, NextAttemptOn: now.Add(-time.Minute)},
+		} {
+			if err := store.InsertDelivery(delivery); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		deliveries, err := store.ClaimPendingDeliveries(now, now.Add(time.Second), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := due.ID, deliveries[0].ID; !expected.Equals(actual) {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+		if expected, actual := webhook.Secret, deliveries[0].Secret; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		// The delivery is claimed, until the claim runs out.
+		deliveries, err = store.ClaimPendingDeliveries(now, now.Add(time.Second), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(deliveries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		deliveries, err = store.ClaimPendingDeliveries(now.Add(time.Second), now.Add(2*time.Second), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		due.Status = DeliveryStatusDelivered
+		if err := store.UpdateDelivery(due); err != nil {
+			t.Fatal(err)
+		}
+
+		deliveries, err = store.ClaimPendingDeliveries(now.Add(2*time.Second), now.Add(3*time.Second), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(deliveries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		delivered, err := store.SelectDeliveries("acme", DeliveryStatusDelivered)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(delivered); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("delivery attempts", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		deliveryID := uuid.MustNew()
+		for _, code := range []int{500, 200} {
+			if err := store.InsertDeliveryAttempt(DeliveryAttempt{DeliveryID: deliveryID, StatusCode: code}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		attempts, err := store.SelectDeliveryAttempts(deliveryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(attempts); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 200, attempts[1].StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+func TestVirtualStoreOutbox(t *testing.T) {
+	t.Parallel()
+
+	t.Run("insert records the events in order", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		var (
+			resourceID = uuid.MustNew()
+			forkID     = uuid.MustNew()
+			head       = Entity{ID: uuid.MustNew(), ResourceID: resourceID, TenantID: "acme", Name: "a"}
+			revision   = Entity{ID: uuid.MustNew(), ParentID: head.ID, ResourceID: resourceID, TenantID: "acme", Name: "b"}
+			fork       = Entity{ID: uuid.MustNew(), ParentID: revision.ID, ResourceID: forkID, TenantID: "acme", Name: "c"}
+			tombstone  = Entity{ID: uuid.MustNew(), ParentID: revision.ID, ResourceID: resourceID, TenantID: "acme", DeletedOn: time.Now()}
+		)
+		for _, entity := range []Entity{head, revision, fork, tombstone} {
+			if err := store.Insert(entity); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		records, err := store.SelectOutbox(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var events []string
+		for k, v := range records {
+			if expected, actual := int64(k+1), v.Sequence; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+			events = append(events, v.Event)
+		}
+		want := []string{OutboxEventInsert, OutboxEventAppend, OutboxEventFork, OutboxEventDelete}
+		if expected, actual := want, events; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := fork.ID, records[2].LedgerID; !expected.Equals(actual) {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+		}
+
+		records, err = store.SelectOutbox(2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(records); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int64(3), records[0].Sequence; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("checkpoints then delete", func(t *testing.T) {
+		store := NewVirtualStore()
+
+		for i := 0; i < 3; i++ {
+			if err := store.Insert(Entity{ResourceID: uuid.MustNew()}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		sequence, err := store.SelectOutboxCheckpoint("stdout")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(0), sequence; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		if err = store.InsertOutboxCheckpoint("stdout", 2); err != nil {
+			t.Fatal(err)
+		}
+		sequence, err = store.SelectOutboxCheckpoint("stdout")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := int64(2), sequence; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		if err = store.DeleteOutbox(2); err != nil {
+			t.Fatal(err)
+		}
+		records, err := store.SelectOutbox(0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(records); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := int64(3), records[0].Sequence; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
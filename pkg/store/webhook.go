@@ -0,0 +1,60 @@
+package store
+
+import (
+	"time"
+
+	"github.com/trussle/uuid"
+)
+
+// These are the statuses of a delivery.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+)
+
+// Webhook represents a subscription to the changes of the ledgers with in a
+// tenant. The ledgers can be filtered by resource id, tag and author, where
+// an empty filter matches every ledger. If there are no events, then every
+// event is delivered. The owner is the principal that created the webhook,
+// which is only sent the changes of the resources that it can read.
+type Webhook struct {
+	ID         uuid.UUID
+	TenantID   string
+	URL        string
+	Secret     string
+	ResourceID uuid.UUID
+	Tag        string
+	AuthorID   string
+	Events     []string
+	Owner      string
+	CreatedOn  time.Time
+}
+
+// Delivery represents an event that is to be delivered to a webhook. The URL
+// and Secret are those of the webhook, which are only populated when
+// selecting pending deliveries.
+type Delivery struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	TenantID      string
+	Event         string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptOn time.Time
+	CreatedOn     time.Time
+	DeliveredOn   time.Time
+	URL           string
+	Secret        string
+}
+
+// DeliveryAttempt represents a single attempt at delivering an event to a
+// webhook. StatusCode is zero if no response was received.
+type DeliveryAttempt struct {
+	DeliveryID  uuid.UUID
+	AttemptedOn time.Time
+	StatusCode  int
+	Error       string
+}
@@ -0,0 +1,119 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/trussle/snowy/pkg/auth"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/models"
+)
+
+// HTTPHeaderTenant is the header a request names its tenant with.
+const HTTPHeaderTenant = "X-Snowy-Tenant"
+
+type contextKey int
+
+const tenantKey contextKey = iota
+
+// WithTenant returns a copy of the context with the tenant attached.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// FromContext returns the tenant attached to the context. If there is no
+// tenant, then the empty (default) tenant is returned.
+func FromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey).(string)
+	return tenantID
+}
+
+type middleware struct {
+	next     http.Handler
+	required bool
+	open     []string
+	logger   log.Logger
+	errors   errs.Error
+}
+
+// MiddlewareOption defines a option for configuring the middleware.
+type MiddlewareOption func(*middleware)
+
+// WithRequired rejects any request that doesn't resolve to a tenant, so that
+// nothing can be stored in the default tenant.
+func WithRequired() MiddlewareOption {
+	return func(m *middleware) {
+		m.required = true
+	}
+}
+
+// WithOpenPath allows any request with a path that has the prefix through,
+// even if the tenant is required.
+func WithOpenPath(prefix string) MiddlewareOption {
+	return func(m *middleware) {
+		m.open = append(m.open, prefix)
+	}
+}
+
+// NewMiddleware creates a http.Handler that resolves the tenant of every
+// request before passing the request on to the next handler with the tenant
+// attached to the request context.
+//
+// The tenant of an authenticated principal always takes precedence, a request
+// naming a different tenant in the X-Snowy-Tenant header is forbidden. A
+// principal without a tenant is held to the default tenant, only a principal
+// with auth.AnyTenant as its tenant may choose the tenant with the header.
+// Without a principal, the tenant is taken from the header.
+func NewMiddleware(next http.Handler, logger log.Logger, opts ...MiddlewareOption) http.Handler {
+	m := &middleware{
+		next:   next,
+		logger: logger,
+		errors: errs.NewError(logger),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Header.Get(HTTPHeaderTenant)
+
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.Tenant != auth.AnyTenant {
+		if tenantID != "" && tenantID != principal.Tenant {
+			level.Warn(m.logger).Log("principal", principal.ID, "tenant", tenantID)
+			m.errors.Forbidden(w, r, fmt.Sprintf("principal %q can not access tenant %q", principal.ID, tenantID))
+			return
+		}
+		tenantID = principal.Tenant
+	}
+
+	if tenantID == "" {
+		if m.required && !m.isOpen(r) {
+			m.errors.BadRequest(w, r, "missing tenant")
+			return
+		}
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	if !models.ValidTenantID(tenantID) {
+		m.errors.BadRequest(w, r, fmt.Sprintf("invalid tenant %q", tenantID))
+		return
+	}
+
+	m.next.ServeHTTP(w, r.WithContext(WithTenant(r.Context(), tenantID)))
+}
+
+func (m *middleware) isOpen(r *http.Request) bool {
+	for _, prefix := range m.open {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/trussle/snowy/pkg/auth"
+)
+
+func TestContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no tenant", func(t *testing.T) {
+		if expected, actual := "", FromContext(context.Background()); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("tenant", func(t *testing.T) {
+		ctx := WithTenant(context.Background(), "acme")
+		if expected, actual := "acme", FromContext(ctx); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	serve := func(handler func(http.Handler) http.Handler, r *http.Request) (int, string) {
+		var tenantID string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID = FromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		handler(next).ServeHTTP(w, r)
+		return w.Code, tenantID
+	}
+
+	optional := func(next http.Handler) http.Handler {
+		return NewMiddleware(next, log.NewNopLogger())
+	}
+	required := func(next http.Handler) http.Handler {
+		return NewMiddleware(next, log.NewNopLogger(), WithRequired(), WithOpenPath("/status/"))
+	}
+
+	withPrincipal := func(r *http.Request, principal auth.Principal) *http.Request {
+		return r.WithContext(auth.WithPrincipal(r.Context(), principal))
+	}
+
+	for _, tc := range []struct {
+		name    string
+		handler func(http.Handler) http.Handler
+		request func() *http.Request
+		code    int
+		tenant  string
+	}{
+		{
+			name:    "no tenant",
+			handler: optional,
+			request: func() *http.Request {
+				return httptest.NewRequest("GET", "/ledgers/", nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:    "tenant header",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				r.Header.Set(HTTPHeaderTenant, "acme")
+				return r
+			},
+			code:   http.StatusOK,
+			tenant: "acme",
+		},
+		{
+			name:    "invalid tenant header",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				r.Header.Set(HTTPHeaderTenant, "../acme")
+				return r
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:    "principal tenant",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				return withPrincipal(r, auth.Principal{ID: "bob", Tenant: "acme"})
+			},
+			code:   http.StatusOK,
+			tenant: "acme",
+		},
+		{
+			name:    "principal tenant with matching header",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				r.Header.Set(HTTPHeaderTenant, "acme")
+				return withPrincipal(r, auth.Principal{ID: "bob", Tenant: "acme"})
+			},
+			code:   http.StatusOK,
+			tenant: "acme",
+		},
+		{
+			name:    "principal tenant with other header",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				r.Header.Set(HTTPHeaderTenant, "other")
+				return withPrincipal(r, auth.Principal{ID: "bob", Tenant: "acme"})
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:    "principal without tenant uses default tenant",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				return withPrincipal(r, auth.Principal{ID: "bob"})
+			},
+			code:   http.StatusOK,
+			tenant: "",
+		},
+		{
+			name:    "principal without tenant with header",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				r.Header.Set(HTTPHeaderTenant, "other")
+				return withPrincipal(r, auth.Principal{ID: "bob"})
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:    "principal without tenant when required",
+			handler: required,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				return withPrincipal(r, auth.Principal{ID: "bob"})
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:    "principal of any tenant uses header",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				r.Header.Set(HTTPHeaderTenant, "other")
+				return withPrincipal(r, auth.Principal{ID: "bob", Tenant: auth.AnyTenant})
+			},
+			code:   http.StatusOK,
+			tenant: "other",
+		},
+		{
+			name:    "principal of any tenant without header",
+			handler: optional,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/ledgers/", nil)
+				return withPrincipal(r, auth.Principal{ID: "bob", Tenant: auth.AnyTenant})
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:    "required tenant missing",
+			handler: required,
+			request: func() *http.Request {
+				return httptest.NewRequest("GET", "/ledgers/", nil)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:    "required tenant on open path",
+			handler: required,
+			request: func() *http.Request {
+				return httptest.NewRequest("GET", "/status/health", nil)
+			},
+			code: http.StatusOK,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			code, tenantID := serve(tc.handler, tc.request())
+			if expected, actual := tc.code, code; expected != actual {
+				t.Errorf("expected: %d, actual: %d", expected, actual)
+			}
+			if expected, actual := tc.tenant, tenantID; expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Exporter exports the finished spans, for example to a collector.
+type Exporter interface {
+
+	// Export exports a finished span.
+	Export(Span) error
+
+	// Close flushes and closes the exporter.
+	Close() error
+}
+
+// ExporterConfig encapsulates the requirements for generating an Exporter
+type ExporterConfig struct {
+	name string
+	path string
+}
+
+// ExporterOption defines a option for generating an ExporterConfig
+type ExporterOption func(*ExporterConfig) error
+
+// BuildExporterConfig ingests configuration options to then yield an
+// ExporterConfig and return an error if it fails during setup.
+func BuildExporterConfig(opts ...ExporterOption) (*ExporterConfig, error) {
+	var config ExporterConfig
+	for _, opt := range opts {
+		err := opt(&config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// WithExporterName adds a type of exporter to use for the configuration.
+func WithExporterName(name string) ExporterOption {
+	return func(config *ExporterConfig) error {
+		config.name = name
+		return nil
+	}
+}
+
+// WithExporterPath adds a file path to export to, for the file exporter.
+func WithExporterPath(path string) ExporterOption {
+	return func(config *ExporterConfig) error {
+		config.path = path
+		return nil
+	}
+}
+
+// NewExporter returns a new Exporter with the correct configuration: "none"
+// drops every span, "stdout" and "file" write every span as a line of JSON.
+func NewExporter(config *ExporterConfig) (Exporter, error) {
+	switch config.name {
+	case "", "none":
+		return NewNopExporter(), nil
+	case "stdout":
+		return NewWriterExporter(nopCloser{os.Stdout}), nil
+	case "file":
+		if config.path == "" {
+			return nil, errors.New("no path for the file exporter")
+		}
+		file, err := os.OpenFile(config.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening the file exporter")
+		}
+		return NewWriterExporter(file), nil
+	default:
+		return nil, errors.Errorf("unexpected exporter %q", config.name)
+	}
+}
+
+type nopExporter struct{}
+
+// NewNopExporter creates an Exporter that drops every span.
+func NewNopExporter() Exporter {
+	return nopExporter{}
+}
+
+func (nopExporter) Export(Span) error { return nil }
+func (nopExporter) Close() error      { return nil }
+
+type writerExporter struct {
+	mutex   sync.Mutex
+	writer  io.WriteCloser
+	encoder *json.Encoder
+}
+
+// NewWriterExporter creates an Exporter that writes every span to the writer,
+// as a line of JSON. The writer is closed along with the exporter.
+func NewWriterExporter(writer io.WriteCloser) Exporter {
+	return &writerExporter{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+func (e *writerExporter) Export(span Span) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.encoder.Encode(span)
+}
+
+func (e *writerExporter) Close() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.writer.Close()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
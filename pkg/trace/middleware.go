@@ -0,0 +1,105 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/trussle/uuid"
+)
+
+const (
+	// HTTPHeaderRequestID is the header the id of a request is read from, and
+	// sent back with.
+	HTTPHeaderRequestID = "X-Request-ID"
+
+	// HTTPHeaderTraceParent is the W3C trace context header, that a request
+	// continues the remote trace of.
+	HTTPHeaderTraceParent = "Traceparent"
+
+	// maxRequestIDLength bounds the request ids taken from requests, so that
+	// the logs can't be flooded through the header.
+	maxRequestIDLength = 128
+)
+
+var traceParent = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+type middleware struct {
+	next   http.Handler
+	tracer *Tracer
+	logger log.Logger
+}
+
+// NewMiddleware creates a http.Handler that gives every request an id, before
+// passing the request on to the next handler with the id and a span of the
+// request attached to the request context.
+//
+// The id is taken from the X-Request-ID header if the request has one,
+// otherwise a new id is created. Either way the id is set on the header of the
+// request and the response. A request with a traceparent header continues the
+// remote trace.
+func NewMiddleware(next http.Handler, tracer *Tracer, logger log.Logger) http.Handler {
+	return &middleware{
+		next:   next,
+		tracer: tracer,
+		logger: logger,
+	}
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(HTTPHeaderRequestID)
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		requestID = uuid.MustNew().String()
+	}
+	r.Header.Set(HTTPHeaderRequestID, requestID)
+	w.Header().Set(HTTPHeaderRequestID, requestID)
+
+	ctx := WithRequestID(r.Context(), requestID)
+	if match := traceParent.FindStringSubmatch(r.Header.Get(HTTPHeaderTraceParent)); match != nil {
+		ctx = context.WithValue(ctx, remoteKey, remoteParent{
+			traceID: match[1],
+			spanID:  match[2],
+		})
+	}
+
+	ctx, span := m.tracer.Start(ctx, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+	span.SetAttribute("http.method", r.Method)
+	span.SetAttribute("http.target", r.URL.Path)
+
+	iw := &interceptingWriter{http.StatusOK, w}
+	defer func() {
+		span.SetAttribute("http.status_code", strconv.Itoa(iw.code))
+
+		var err error
+		if iw.code >= http.StatusInternalServerError {
+			err = fmt.Errorf("%d %s", iw.code, http.StatusText(iw.code))
+		}
+		span.Finish(err)
+
+		level.Debug(Logger(ctx, m.logger)).Log("method", r.Method, "url", r.URL.String(), "code", iw.code)
+	}()
+
+	m.next.ServeHTTP(iw, r.WithContext(ctx))
+}
+
+type interceptingWriter struct {
+	code int
+	http.ResponseWriter
+}
+
+func (iw *interceptingWriter) WriteHeader(code int) {
+	iw.code = code
+	iw.ResponseWriter.WriteHeader(code)
+}
+
+// Flush flushes the underlying writer, so that streams can be served through
+// the interceptingWriter.
+func (iw *interceptingWriter) Flush() {
+	if flusher, ok := iw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,109 @@
+package trace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	serve := func(next http.HandlerFunc, r *http.Request) (*httptest.ResponseRecorder, []Span) {
+		rec := &recorder{}
+		m := NewMiddleware(next, NewTracer(WithExporter(rec)), log.NewNopLogger())
+
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		return w, rec.Spans()
+	}
+
+	t.Run("creates request id", func(t *testing.T) {
+		var requestID string
+		w, spans := serve(func(w http.ResponseWriter, r *http.Request) {
+			requestID = RequestIDFromContext(r.Context())
+		}, httptest.NewRequest("GET", "/ledgers/", nil))
+
+		if requestID == "" {
+			t.Errorf("expected: request id, actual: %q", requestID)
+		}
+		if expected, actual := requestID, w.Header().Get(HTTPHeaderRequestID); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := 1, len(spans); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := requestID, spans[0].RequestID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "GET /ledgers/", spans[0].Name; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("honours request id", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		r.Header.Set(HTTPHeaderRequestID, "abc")
+
+		var requestID string
+		w, _ := serve(func(w http.ResponseWriter, r *http.Request) {
+			requestID = r.Header.Get(HTTPHeaderRequestID)
+		}, r)
+
+		if expected, actual := "abc", requestID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "abc", w.Header().Get(HTTPHeaderRequestID); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("replaces long request id", func(t *testing.T) {
+		long := strings.Repeat("a", maxRequestIDLength+1)
+
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		r.Header.Set(HTTPHeaderRequestID, long)
+
+		w, _ := serve(func(w http.ResponseWriter, r *http.Request) {}, r)
+
+		if actual := w.Header().Get(HTTPHeaderRequestID); actual == "" || actual == long {
+			t.Errorf("expected: new request id, actual: %q", actual)
+		}
+	})
+
+	t.Run("continues remote trace", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/ledgers/", nil)
+		r.Header.Set(HTTPHeaderTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		_, spans := serve(func(w http.ResponseWriter, r *http.Request) {}, r)
+
+		if expected, actual := 1, len(spans); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].TraceID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "00f067aa0ba902b7", spans[0].ParentID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("records server errors", func(t *testing.T) {
+		_, spans := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}, httptest.NewRequest("POST", "/journals/", nil))
+
+		if expected, actual := 1, len(spans); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "500", spans[0].Attributes["http.status_code"]; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if spans[0].Error == "" {
+			t.Errorf("expected: error, actual: %q", spans[0].Error)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// Span is a timed operation with in a trace. Spans are identified the same as
+// OpenTelemetry, by a 16 byte trace id and a 8 byte span id, hex encoded.
+type Span struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	RequestID  string            `json:"request_id,omitempty"`
+	Name       string            `json:"name"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+
+	state *spanState
+}
+
+// spanState is the state of a span that's shared between the copies of the
+// span, so that the span can be passed to the exporter by value.
+type spanState struct {
+	mutex    sync.Mutex
+	tracer   *Tracer
+	finished bool
+}
+
+// SetAttribute sets an attribute of the span. Setting an attribute of a nil
+// span does nothing.
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+
+	s.state.mutex.Lock()
+	defer s.state.mutex.Unlock()
+
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]string)
+	}
+	s.Attributes[key] = value
+}
+
+// Finish ends the span, recording the error if there is one, and then exports
+// the span. Finishing a span more than once, or a nil span, does nothing.
+func (s *Span) Finish(err error) {
+	if s == nil {
+		return
+	}
+
+	s.state.mutex.Lock()
+	if s.state.finished {
+		s.state.mutex.Unlock()
+		return
+	}
+	s.state.finished = true
+	s.End = s.state.tracer.now()
+	if err != nil {
+		s.Error = err.Error()
+	}
+	span := *s
+	s.state.mutex.Unlock()
+
+	s.state.tracer.export(span)
+}
+
+// Tracer starts spans and exports them once they're finished.
+type Tracer struct {
+	exporter Exporter
+	logger   log.Logger
+	now      func() time.Time
+}
+
+// TracerOption defines a option for configuring the tracer.
+type TracerOption func(*Tracer)
+
+// WithExporter exports the finished spans with the exporter. By default the
+// spans aren't exported.
+func WithExporter(exporter Exporter) TracerOption {
+	return func(t *Tracer) {
+		t.exporter = exporter
+	}
+}
+
+// WithLogger logs the errors of exporting spans to the logger.
+func WithLogger(logger log.Logger) TracerOption {
+	return func(t *Tracer) {
+		t.logger = logger
+	}
+}
+
+// NewTracer creates a Tracer with the correct dependencies.
+func NewTracer(opts ...TracerOption) *Tracer {
+	t := &Tracer{
+		exporter: NewNopExporter(),
+		logger:   log.NewNopLogger(),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Start starts a span, as the child of the span of the context. If there is
+// no span, then the span starts a new trace, or continues the remote trace of
+// the context. The returned context holds the new span.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	span := &Span{
+		SpanID:    newID(8),
+		RequestID: RequestIDFromContext(ctx),
+		Name:      name,
+		Start:     t.now(),
+		state:     &spanState{tracer: t},
+	}
+
+	if parent, ok := FromContext(ctx); ok {
+		span.TraceID, span.ParentID = parent.TraceID, parent.SpanID
+	} else if remote, ok := ctx.Value(remoteKey).(remoteParent); ok {
+		span.TraceID, span.ParentID = remote.traceID, remote.spanID
+	} else {
+		span.TraceID = newID(16)
+	}
+
+	return NewContext(ctx, span), span
+}
+
+// StartChild starts a span, as the child of the span of the context. If there
+// is no span, then no span is started and the returned span is nil, so that
+// work outside of a request, like the background jobs, isn't traced.
+func (t *Tracer) StartChild(ctx context.Context, name string) (context.Context, *Span) {
+	if _, ok := FromContext(ctx); !ok {
+		return ctx, nil
+	}
+	return t.Start(ctx, name)
+}
+
+func (t *Tracer) export(span Span) {
+	if err := t.exporter.Export(span); err != nil {
+		t.logger.Log("err", err, "span", span.Name)
+	}
+}
+
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	remoteKey
+	requestIDKey
+)
+
+type remoteParent struct {
+	traceID, spanID string
+}
+
+// NewContext returns a copy of the context with the span attached.
+func NewContext(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey, span)
+}
+
+// FromContext returns the span attached to the context, if there is one.
+func FromContext(ctx context.Context) (*Span, bool) {
+	span, ok := ctx.Value(spanKey).(*Span)
+	return span, ok
+}
+
+// WithRequestID returns a copy of the context with the request id attached.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request id attached to the context. If
+// there is no request id, then the empty string is returned.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// Logger returns the logger with the request id and trace id of the context,
+// so that the log lines of every component can be correlated with a request.
+func Logger(ctx context.Context, logger log.Logger) log.Logger {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		logger = log.With(logger, "request_id", requestID)
+	}
+	if span, ok := FromContext(ctx); ok {
+		logger = log.With(logger, "trace_id", span.TraceID)
+	}
+	return logger
+}
+
+func newID(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
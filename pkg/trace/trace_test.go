@@ -0,0 +1,198 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+type recorder struct {
+	mutex sync.Mutex
+	spans []Span
+}
+
+func (r *recorder) Export(span Span) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func (r *recorder) Close() error { return nil }
+
+func (r *recorder) Spans() []Span {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Span(nil), r.spans...)
+}
+
+func TestTracer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("start root span", func(t *testing.T) {
+		rec := &recorder{}
+		tracer := NewTracer(WithExporter(rec))
+
+		ctx, span := tracer.Start(WithRequestID(context.Background(), "abc"), "root")
+		span.SetAttribute("key", "value")
+		span.Finish(nil)
+
+		if actual, ok := FromContext(ctx); !ok || actual != span {
+			t.Errorf("expected: span in context, actual: %v", actual)
+		}
+
+		spans := rec.Spans()
+		if expected, actual := 1, len(spans); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 32, len(spans[0].TraceID); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := 16, len(spans[0].SpanID); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "", spans[0].ParentID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "abc", spans[0].RequestID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "value", spans[0].Attributes["key"]; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("start child span", func(t *testing.T) {
+		rec := &recorder{}
+		tracer := NewTracer(WithExporter(rec))
+
+		ctx, parent := tracer.Start(context.Background(), "parent")
+		_, child := tracer.StartChild(ctx, "child")
+		child.Finish(errors.New("bad"))
+		parent.Finish(nil)
+
+		spans := rec.Spans()
+		if expected, actual := 2, len(spans); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := spans[1].TraceID, spans[0].TraceID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := spans[1].SpanID, spans[0].ParentID; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+		if expected, actual := "bad", spans[0].Error; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("start child without parent", func(t *testing.T) {
+		rec := &recorder{}
+		tracer := NewTracer(WithExporter(rec))
+
+		_, span := tracer.StartChild(context.Background(), "child")
+		span.SetAttribute("key", "value")
+		span.Finish(nil)
+
+		if span != nil {
+			t.Errorf("expected: nil span, actual: %v", span)
+		}
+		if expected, actual := 0, len(rec.Spans()); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("finish twice", func(t *testing.T) {
+		rec := &recorder{}
+		tracer := NewTracer(WithExporter(rec))
+
+		_, span := tracer.Start(context.Background(), "root")
+		span.Finish(nil)
+		span.Finish(errors.New("bad"))
+
+		spans := rec.Spans()
+		if expected, actual := 1, len(spans); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := "", spans[0].Error; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+}
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	ctx, span := NewTracer().Start(WithRequestID(context.Background(), "abc"), "root")
+
+	Logger(ctx, log.NewLogfmtLogger(buf)).Log("msg", "hello")
+
+	for _, expected := range []string{"request_id=abc", "trace_id=" + span.TraceID} {
+		if actual := buf.String(); !strings.Contains(actual, expected) {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	exporter := NewWriterExporter(nopCloser{buf})
+	tracer := NewTracer(WithExporter(exporter))
+
+	_, span := tracer.Start(context.Background(), "root")
+	span.Finish(nil)
+
+	if err := exporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var res Span
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := span.SpanID, res.SpanID; expected != actual {
+		t.Errorf("expected: %q, actual: %q", expected, actual)
+	}
+	if expected, actual := "root", res.Name; expected != actual {
+		t.Errorf("expected: %q, actual: %q", expected, actual)
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name, path string
+		err        bool
+	}{
+		{"", "", false},
+		{"none", "", false},
+		{"stdout", "", false},
+		{"file", "", true},
+		{"zipkin", "", true},
+	} {
+		config, err := BuildExporterConfig(
+			WithExporterName(tc.name),
+			WithExporterPath(tc.path),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = NewExporter(config)
+		if expected, actual := tc.err, err != nil; expected != actual {
+			t.Errorf("%q expected: %t, actual: %t", tc.name, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/auth"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/metrics"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/tenant"
+	"github.com/trussle/snowy/pkg/trace"
+	"github.com/trussle/uuid"
+)
+
+// These are the webhooks API URL paths.
+const (
+	APIPathSelectQuery     = "/"
+	APIPathInsertQuery     = "/"
+	APIPathDeleteQuery     = "/"
+	APIPathDeliveriesQuery = "/deliveries/"
+	APIPathAttemptsQuery   = "/deliveries/attempts/"
+	APIPathRedeliverQuery  = "/deliveries/redeliver/"
+)
+
+// API serves the webhooks API
+type API struct {
+	handler    *mux.Router
+	repository repository.Repository
+	logger     log.Logger
+	clients    metrics.Gauge
+	duration   metrics.HistogramVec
+	errors     errs.Error
+}
+
+// NewAPI creates a API with correct dependencies.
+func NewAPI(repository repository.Repository, logger log.Logger,
+	clients metrics.Gauge,
+	duration metrics.HistogramVec,
+) *API {
+	api := &API{
+		repository: repository,
+		logger:     logger,
+		clients:    clients,
+		duration:   duration,
+		errors:     errs.NewError(logger),
+	}
+	{
+		router := mux.NewRouter().StrictSlash(true)
+		router.Methods("GET").Path(APIPathSelectQuery).HandlerFunc(api.handleSelect)
+		router.Methods("POST").Path(APIPathInsertQuery).HandlerFunc(api.handleInsert)
+		router.Methods("DELETE").Path(APIPathDeleteQuery).HandlerFunc(api.handleDelete)
+		router.Methods("GET").Path(APIPathDeliveriesQuery).HandlerFunc(api.handleDeliveries)
+		router.Methods("GET").Path(APIPathAttemptsQuery).HandlerFunc(api.handleAttempts)
+		router.Methods("POST").Path(APIPathRedeliverQuery).HandlerFunc(api.handleRedeliver)
+		router.NotFoundHandler = http.HandlerFunc(api.errors.NotFound)
+
+		api.handler = router
+	}
+	return api
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	level.Info(trace.Logger(r.Context(), a.logger)).Log("url", r.URL.String())
+
+	iw := &interceptingWriter{http.StatusOK, w}
+	w = iw
+
+	// Metrics
+	a.clients.Inc()
+	defer a.clients.Dec()
+
+	defer func(begin time.Time) {
+		a.duration.WithLabelValues(
+			r.Method,
+			metrics.Route(a.handler, r),
+			strconv.Itoa(iw.code),
+		).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) handleSelect(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	options, err := a.buildQuery(r)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	webhooks, err := a.repo(r).SelectWebhooks(options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the webhooks for the result.
+	qr := WebhooksQueryResult{Errors: a.errors}
+	qr.Webhooks = webhooks
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleInsert(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	input, err := ingestWebhook(r.Body)
+	if err != nil {
+		a.errors.InvalidInput(w, r, err)
+		return
+	}
+
+	options, err := a.buildQuery(r)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	webhook := models.Webhook{
+		URL:      input.URL,
+		Secret:   input.Secret,
+		Tag:      input.Tag,
+		AuthorID: input.AuthorID,
+		Events:   input.Events,
+	}
+	if input.ResourceID != "" {
+		if webhook.ResourceID, err = uuid.Parse(input.ResourceID); err != nil {
+			a.errors.BadRequest(w, r, err.Error())
+			return
+		}
+	}
+
+	webhook, err = a.repo(r).InsertWebhook(webhook, options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	level.Info(a.logger).Log("action", "insert webhook", "webhook_id", webhook.ID.String(), "url", webhook.URL)
+
+	// Make sure we collect the webhook for the result.
+	qr := WebhookQueryResult{Errors: a.errors}
+	qr.Webhook = webhook
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleDelete(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp WebhookQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := a.buildQuery(r)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	if err := a.repo(r).DeleteWebhook(qp.ID, options); err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	level.Info(a.logger).Log("action", "delete webhook", "webhook_id", qp.ID.String())
+
+	qr := DeleteQueryResult{Errors: a.errors, Params: qp}
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleDeliveries(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp DeliveriesQueryParams
+	if err := qp.DecodeFrom(r.URL, queryOptional); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := a.buildQuery(r)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	deliveries, err := a.repo(r).SelectDeliveries(qp.Status, options)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the deliveries for the result.
+	qr := DeliveriesQueryResult{Errors: a.errors, Params: qp}
+	qr.Deliveries = deliveries
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleAttempts(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp WebhookQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := a.buildQuery(r)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	attempts, err := a.repo(r).SelectDeliveryAttempts(qp.ID, options)
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	// Make sure we collect the attempts for the result.
+	qr := AttemptsQueryResult{Errors: a.errors, Params: qp}
+	qr.Attempts = attempts
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+func (a *API) handleRedeliver(w http.ResponseWriter, r *http.Request) {
+	// useful metrics
+	begin := time.Now()
+
+	defer r.Body.Close()
+
+	// Validate user input.
+	var qp WebhookQueryParams
+	if err := qp.DecodeFrom(r.URL, queryRequired); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	options, err := a.buildQuery(r)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	delivery, err := a.repo(r).RedeliverDelivery(qp.ID, options)
+	if err != nil {
+		if repository.ErrNotFound(err) {
+			a.errors.ResourceNotFound(w, r, err.Error())
+			return
+		}
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	level.Info(a.logger).Log("action", "redeliver", "delivery_id", qp.ID.String())
+
+	// Make sure we collect the delivery for the result.
+	qr := DeliveryQueryResult{Errors: a.errors, Params: qp}
+	qr.Delivery = delivery
+
+	// Finish
+	qr.Duration = time.Since(begin).String()
+	qr.EncodeTo(w)
+}
+
+// buildQuery returns the query of the request, so that the webhooks are
+// owned by, and only visible to, the principal of the request.
+func (a *API) buildQuery(r *http.Request) (repository.Query, error) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	return repository.BuildQuery(
+		repository.WithQueryPrincipal(principal.ID, principal.Groups),
+		repository.WithQueryTenant(tenant.FromContext(r.Context())),
+	)
+}
+
+type interceptingWriter struct {
+	code int
+	http.ResponseWriter
+}
+
+func (iw *interceptingWriter) WriteHeader(code int) {
+	iw.code = code
+	iw.ResponseWriter.WriteHeader(code)
+}
+
+func ingestWebhook(reader io.ReadCloser) (models.WebhookInput, error) {
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return models.WebhookInput{}, err
+	}
+
+	if len(bytes) < 1 {
+		return models.WebhookInput{}, errors.New("no body content")
+	}
+
+	var input models.WebhookInput
+	if err = json.Unmarshal(bytes, &input); err != nil {
+		return models.WebhookInput{}, err
+	}
+	if err = models.ValidateWebhookInput(input); err != nil {
+		return models.WebhookInput{}, err
+	}
+	return input, nil
+}
+
+// repo returns the repository bound to the context of the request, so that
+// the calls to the repository are traced as part of the request.
+func (a *API) repo(r *http.Request) repository.Repository {
+	return repository.WithContext(r.Context(), a.repository)
+}
@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/trussle/harness/matchers"
+	metricMocks "github.com/trussle/snowy/pkg/metrics/mocks"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	repoMocks "github.com/trussle/snowy/pkg/repository/mocks"
+	"github.com/trussle/uuid"
+)
+
+func TestWebhooksAPI(t *testing.T) {
+	t.Parallel()
+
+	newAPI := func(ctrl *gomock.Controller, method, path, code string) (*repoMocks.MockRepository, *httptest.Server) {
+		var (
+			clients  = metricMocks.NewMockGauge(ctrl)
+			duration = metricMocks.NewMockHistogramVec(ctrl)
+			observer = metricMocks.NewMockObserver(ctrl)
+			repo     = repoMocks.NewMockRepository(ctrl)
+		)
+
+		clients.EXPECT().Inc().Times(1)
+		clients.EXPECT().Dec().Times(1)
+
+		duration.EXPECT().WithLabelValues(method, path, code).Return(observer).Times(1)
+		observer.EXPECT().Observe(matchers.MatchAnyFloat64()).Times(1)
+
+		return repo, httptest.NewServer(NewAPI(repo, log.NewNopLogger(), clients, duration))
+	}
+
+	t.Run("post with invalid url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		_, server := newAPI(ctrl, "POST", "/", "400")
+		defer server.Close()
+
+		body := []byte(`{"url":"/hook","secret":"secret"}`)
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusBadRequest, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo, server := newAPI(ctrl, "POST", "/", "200")
+		defer server.Close()
+
+		var (
+			id         = uuid.MustNew()
+			resourceID = uuid.MustNew()
+			input      = models.Webhook{
+				URL:        "http://example.com",
+				Secret:     "secret",
+				ResourceID: resourceID,
+				Events:     []string{models.WebhookEventInsert},
+			}
+			output = input
+		)
+		output.ID = id
+		output.CreatedOn = time.Now()
+
+		repo.EXPECT().InsertWebhook(input, repository.Query{}).Return(output, nil).Times(1)
+
+		body := []byte(fmt.Sprintf(`{"url":"http://example.com","secret":"secret","resource_id":%q,"events":["insert"]}`, resourceID))
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+
+		var res map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := id.String(), res["id"]; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if _, ok := res["secret"]; ok {
+			t.Errorf("expected no secret")
+		}
+	})
+
+	t.Run("delete not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo, server := newAPI(ctrl, "DELETE", "/", "404")
+		defer server.Close()
+
+		id := uuid.MustNew()
+		repo.EXPECT().DeleteWebhook(id, repository.Query{}).Return(errNotFound{errors.New("not found")}).Times(1)
+
+		req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/?id=%s", server.URL, id), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusNotFound, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("get dead deliveries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo, server := newAPI(ctrl, "GET", "/deliveries/", "200")
+		defer server.Close()
+
+		repo.EXPECT().SelectDeliveries(models.DeliveryStatusDead, repository.Query{}).Return([]models.WebhookDelivery{
+			{ID: uuid.MustNew(), Status: models.DeliveryStatusDead, Payload: []byte(`{"event":"insert"}`)},
+		}, nil).Times(1)
+
+		resp, err := http.Get(fmt.Sprintf("%s/deliveries/", server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+
+		var res []map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(res); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo, server := newAPI(ctrl, "POST", "/deliveries/redeliver/", "200")
+		defer server.Close()
+
+		id := uuid.MustNew()
+		repo.EXPECT().RedeliverDelivery(id, repository.Query{}).Return(models.WebhookDelivery{
+			ID:     id,
+			Status: models.DeliveryStatusPending,
+		}, nil).Times(1)
+
+		resp, err := http.Post(fmt.Sprintf("%s/deliveries/redeliver/?id=%s", server.URL, id), "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if expected, actual := http.StatusOK, resp.StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+}
+
+type errNotFound struct {
+	err error
+}
+
+func (e errNotFound) Error() string {
+	return e.err.Error()
+}
+
+func (e errNotFound) NotFound() bool {
+	return true
+}
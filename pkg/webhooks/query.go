@@ -0,0 +1,214 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	errs "github.com/trussle/snowy/pkg/http"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+const (
+	defaultContentType = "application/json"
+)
+
+// WebhookQueryParams defines all the dimensions of a query.
+type WebhookQueryParams struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// DecodeFrom populates a WebhookQueryParams from a URL.
+func (qp *WebhookQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	id, err := decodeID(u, rb)
+	if err != nil {
+		return err
+	}
+	qp.ID = id
+
+	return nil
+}
+
+// WebhooksQueryResult contains statistics about the query.
+type WebhooksQueryResult struct {
+	Errors   errs.Error
+	Duration string           `json:"duration"`
+	Webhooks []models.Webhook `json:"webhooks"`
+}
+
+// EncodeTo encodes the WebhooksQueryResult to the HTTP response writer.
+func (qr *WebhooksQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	// Make sure that we encode empty webhooks correctly (i.e. they're not null
+	// in the json output)
+	webhooks := qr.Webhooks
+	if qr.Webhooks == nil {
+		webhooks = make([]models.Webhook, 0)
+	}
+
+	if err := json.NewEncoder(w).Encode(webhooks); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WebhookQueryResult contains statistics about the query.
+type WebhookQueryResult struct {
+	Errors   errs.Error
+	Duration string         `json:"duration"`
+	Webhook  models.Webhook `json:"webhook"`
+}
+
+// EncodeTo encodes the WebhookQueryResult to the HTTP response writer.
+func (qr *WebhookQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(qr.Webhook); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DeleteQueryResult contains statistics about the query.
+type DeleteQueryResult struct {
+	Errors   errs.Error
+	Params   WebhookQueryParams `json:"query"`
+	Duration string             `json:"duration"`
+}
+
+// EncodeTo encodes the DeleteQueryResult to the HTTP response writer.
+func (qr *DeleteQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(struct {
+		ID uuid.UUID `json:"id"`
+	}{
+		ID: qr.Params.ID,
+	}); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DeliveriesQueryParams defines all the dimensions of a query.
+type DeliveriesQueryParams struct {
+	Status string `json:"status"`
+}
+
+// DecodeFrom populates a DeliveriesQueryParams from a URL. If no status is
+// given, then the dead deliveries are queried.
+func (qp *DeliveriesQueryParams) DecodeFrom(u *url.URL, rb queryBehavior) error {
+	status := u.Query().Get("status")
+	if status == "" {
+		if rb == queryRequired {
+			return errors.New("error reading 'status' (required) query")
+		}
+		status = models.DeliveryStatusDead
+	}
+
+	switch status {
+	case models.DeliveryStatusPending, models.DeliveryStatusDelivered, models.DeliveryStatusDead:
+	default:
+		return errors.Errorf("error parsing 'status' query, unknown status %q", status)
+	}
+	qp.Status = status
+
+	return nil
+}
+
+// DeliveriesQueryResult contains statistics about the query.
+type DeliveriesQueryResult struct {
+	Errors     errs.Error
+	Params     DeliveriesQueryParams    `json:"query"`
+	Duration   string                   `json:"duration"`
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+// EncodeTo encodes the DeliveriesQueryResult to the HTTP response writer.
+func (qr *DeliveriesQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+	w.Header().Set(httpHeaderQueryStatus, qr.Params.Status)
+
+	deliveries := qr.Deliveries
+	if qr.Deliveries == nil {
+		deliveries = make([]models.WebhookDelivery, 0)
+	}
+
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DeliveryQueryResult contains statistics about the query.
+type DeliveryQueryResult struct {
+	Errors   errs.Error
+	Params   WebhookQueryParams     `json:"query"`
+	Duration string                 `json:"duration"`
+	Delivery models.WebhookDelivery `json:"delivery"`
+}
+
+// EncodeTo encodes the DeliveryQueryResult to the HTTP response writer.
+func (qr *DeliveryQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	if err := json.NewEncoder(w).Encode(qr.Delivery); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AttemptsQueryResult contains statistics about the query.
+type AttemptsQueryResult struct {
+	Errors   errs.Error
+	Params   WebhookQueryParams      `json:"query"`
+	Duration string                  `json:"duration"`
+	Attempts []models.WebhookAttempt `json:"attempts"`
+}
+
+// EncodeTo encodes the AttemptsQueryResult to the HTTP response writer.
+func (qr *AttemptsQueryResult) EncodeTo(w http.ResponseWriter) {
+	w.Header().Set(httpHeaderContentType, defaultContentType)
+	w.Header().Set(httpHeaderDuration, qr.Duration)
+
+	attempts := qr.Attempts
+	if qr.Attempts == nil {
+		attempts = make([]models.WebhookAttempt, 0)
+	}
+
+	if err := json.NewEncoder(w).Encode(attempts); err != nil {
+		qr.Errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func decodeID(u *url.URL, rb queryBehavior) (uuid.UUID, error) {
+	id := u.Query().Get("id")
+	if id == "" {
+		if rb == queryRequired {
+			return uuid.Empty, errors.New("error reading 'id' (required) query")
+		}
+		return uuid.Empty, nil
+	}
+
+	res, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Empty, errors.Wrap(err, "error parsing 'id' query")
+	}
+	return res, nil
+}
+
+const (
+	httpHeaderContentType = "Content-Type"
+	httpHeaderDuration    = "X-Duration"
+	httpHeaderQueryStatus = "X-Query-Status"
+)
+
+type queryBehavior int
+
+const (
+	queryRequired queryBehavior = iota
+	queryOptional
+)
@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/uuid"
+)
+
+func TestWebhookQueryParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DecodeFrom with required empty url", func(t *testing.T) {
+		var qp WebhookQueryParams
+
+		u, err := url.Parse("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with invalid id", func(t *testing.T) {
+		var qp WebhookQueryParams
+
+		u, err := url.Parse("/?id=bad")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryRequired)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with id", func(t *testing.T) {
+		var qp WebhookQueryParams
+
+		id := uuid.MustNew()
+		u, err := url.Parse("/?id=" + id.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := qp.DecodeFrom(u, queryRequired); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := id, qp.ID; !expected.Equals(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestDeliveriesQueryParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DecodeFrom defaults to dead", func(t *testing.T) {
+		var qp DeliveriesQueryParams
+
+		u, err := url.Parse("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := qp.DecodeFrom(u, queryOptional); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := models.DeliveryStatusDead, qp.Status; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("DecodeFrom with unknown status", func(t *testing.T) {
+		var qp DeliveriesQueryParams
+
+		u, err := url.Parse("/?status=lost")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = qp.DecodeFrom(u, queryOptional)
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
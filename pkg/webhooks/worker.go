@@ -0,0 +1,266 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+)
+
+// These are the headers that are sent along with every delivery.
+const (
+	HTTPHeaderEvent     = "X-Snowy-Event"
+	HTTPHeaderDelivery  = "X-Snowy-Delivery"
+	HTTPHeaderTimestamp = "X-Snowy-Timestamp"
+	HTTPHeaderSignature = "X-Snowy-Signature"
+)
+
+const (
+	defaultInterval    = 5 * time.Second
+	defaultMaxAttempts = 8
+	defaultTimeout     = 10 * time.Second
+	defaultBackoff     = 30 * time.Second
+	defaultMaxBackoff  = time.Hour
+	defaultBatchSize   = 100
+
+	// maxErrorBody is how much of the body of a failed response is kept as
+	// the error of the attempt.
+	maxErrorBody = 512
+)
+
+// Worker delivers the pending deliveries to the webhooks, retrying failed
+// deliveries with an exponential backoff until they run out of attempts, at
+// which point they're dead.
+type Worker struct {
+	repository  repository.Repository
+	client      *http.Client
+	interval    time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	logger      log.Logger
+	stop        chan chan struct{}
+	now         func() time.Time
+}
+
+// WorkerOption defines a option for configuring the worker.
+type WorkerOption func(*Worker)
+
+// WithInterval sets how often the worker looks for pending deliveries.
+func WithInterval(interval time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.interval = interval
+	}
+}
+
+// WithMaxAttempts sets how many times a delivery is attempted, before it's
+// considered dead.
+func WithMaxAttempts(maxAttempts int) WorkerOption {
+	return func(w *Worker) {
+		w.maxAttempts = maxAttempts
+	}
+}
+
+// WithTimeout sets how long a single attempt at a delivery can take.
+func WithTimeout(timeout time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.client.Timeout = timeout
+	}
+}
+
+// WithBackoff sets how long to wait before retrying a failed delivery for
+// the first time, which doubles with every failed attempt.
+func WithBackoff(backoff time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.backoff = backoff
+	}
+}
+
+// NewWorker creates a Worker with correct dependencies.
+func NewWorker(repository repository.Repository, logger log.Logger, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		repository: repository,
+		client: &http.Client{
+			Timeout: defaultTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialPublic,
+				TLSHandshakeTimeout: defaultTimeout,
+			},
+		},
+		interval:    defaultInterval,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		logger:      logger,
+		stop:        make(chan chan struct{}),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run delivers the pending deliveries periodically, until the worker is
+// stopped.
+func (w *Worker) Run() error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.deliverPending(); err != nil {
+				level.Error(w.logger).Log("action", "deliver", "err", err.Error())
+			}
+		case c := <-w.stop:
+			close(c)
+			return nil
+		}
+	}
+}
+
+// Stop stops the worker, waiting for any deliveries in flight.
+func (w *Worker) Stop() {
+	c := make(chan struct{})
+	w.stop <- c
+	<-c
+}
+
+// deliverPending attempts every delivery that is due, a batch at a time. The
+// batch is claimed for as long as it can take to attempt every delivery of
+// it, so that no other worker attempts them in the meantime.
+func (w *Worker) deliverPending() error {
+	lease := time.Duration(defaultBatchSize) * w.client.Timeout
+	deliveries, err := w.repository.ClaimPendingDeliveries(defaultBatchSize, lease)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		delivery, attempt := w.deliver(delivery)
+		if err := w.repository.RecordDeliveryAttempt(delivery, attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver attempts the delivery, returning the new state of the delivery
+// along with the attempt.
+func (w *Worker) deliver(delivery models.WebhookDelivery) (models.WebhookDelivery, models.WebhookAttempt) {
+	now := w.now()
+
+	attempt := models.WebhookAttempt{
+		DeliveryID:  delivery.ID,
+		AttemptedOn: now,
+	}
+	attempt.StatusCode, attempt.Error = w.post(delivery, now)
+
+	delivery.Attempts++
+	delivery.LastError = attempt.Error
+
+	switch {
+	case attempt.Error == "":
+		delivery.Status = models.DeliveryStatusDelivered
+		delivery.DeliveredOn = now
+	case delivery.Attempts >= w.maxAttempts:
+		delivery.Status = models.DeliveryStatusDead
+		level.Warn(w.logger).Log("action", "deliver", "delivery_id", delivery.ID.String(), "attempts", delivery.Attempts, "err", attempt.Error)
+	default:
+		delivery.NextAttemptOn = now.Add(w.retryAfter(delivery.Attempts))
+	}
+
+	return delivery, attempt
+}
+
+// post posts the payload of the delivery to the webhook, returning the status
+// code and the error if the delivery failed. Any 2xx response is a success.
+func (w *Worker) post(delivery models.WebhookDelivery, now time.Time) (int, string) {
+	req, err := http.NewRequest("POST", delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set(httpHeaderContentType, defaultContentType)
+	req.Header.Set(HTTPHeaderEvent, delivery.Event)
+	req.Header.Set(HTTPHeaderDelivery, delivery.ID.String())
+	req.Header.Set(HTTPHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HTTPHeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Sprintf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return resp.StatusCode, ""
+}
+
+// dialPublic dials the address once every address that the host resolves to
+// has been checked to be public, so that a webhook can never be used to reach
+// this host or the private network, even by a name that resolves to them. The
+// address that was checked is the one dialed, so the name can't be resolved to
+// another address in between.
+func dialPublic(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, errors.Errorf("host %q has no addresses", host)
+	}
+	for _, addr := range addrs {
+		if models.PrivateIP(addr.IP) {
+			return nil, errors.Errorf("host %q is not a public host", host)
+		}
+	}
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, net.JoinHostPort(addrs[0].IP.String(), port))
+}
+
+// retryAfter returns how long to wait after the number of failed attempts,
+// doubling with every attempt up to a maximum.
+func (w *Worker) retryAfter(attempts int) time.Duration {
+	d := w.backoff
+	for i := 1; i < attempts; i++ {
+		if d *= 2; d >= defaultMaxBackoff {
+			return defaultMaxBackoff
+		}
+	}
+	return d
+}
+
+// Sign signs the body of a delivery sent at the timestamp (unix seconds), so
+// that the receiver can verify the delivery came from the webhook. The
+// signature is the hex encoded HMAC-SHA256 of "timestamp.body" keyed by the
+// secret of the webhook, prefixed by "sha256=".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,236 @@
+package webhooks
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/trussle/fsys"
+	"github.com/trussle/snowy/pkg/models"
+	"github.com/trussle/snowy/pkg/repository"
+	"github.com/trussle/snowy/pkg/store"
+	"github.com/trussle/uuid"
+)
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	var (
+		body      = []byte(`{"event":"insert"}`)
+		signature = Sign("secret", 1500000000, body)
+	)
+
+	if expected, actual := signature, Sign("secret", 1500000000, body); expected != actual {
+		t.Errorf("expected: %s, actual: %s", expected, actual)
+	}
+	if other := Sign("other", 1500000000, body); signature == other {
+		t.Errorf("expected different signatures for different secrets")
+	}
+	if other := Sign("secret", 1500000001, body); signature == other {
+		t.Errorf("expected different signatures for different timestamps")
+	}
+}
+
+func TestWorker(t *testing.T) {
+	t.Parallel()
+
+	newRepository := func(t *testing.T, url string) repository.Repository {
+		repo := repository.NewRealRepository(
+			repository.NewFilesystemBlobStore(fsys.NewVirtualFilesystem()),
+			store.NewVirtualStore(),
+			log.NewNopLogger(),
+			repository.WithWebhooks(),
+		)
+
+		if _, err := repo.InsertWebhook(models.Webhook{
+			URL:    url,
+			Secret: "secret",
+		}, repository.Query{}); err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := models.BuildLedger(
+			models.WithNewResourceID(),
+			models.WithName("name"),
+			models.WithAuthorID("author"),
+			models.WithResourceAddress(uuid.MustNew().String()),
+			models.WithResourceSize(1),
+			models.WithResourceContentType("application/octet-stream"),
+			models.WithCreatedOn(time.Now()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.InsertLedger(doc); err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	}
+
+	t.Run("delivers signed events", func(t *testing.T) {
+		var (
+			mutex    sync.Mutex
+			requests []*http.Request
+			bodies   [][]byte
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+
+			mutex.Lock()
+			requests = append(requests, r)
+			bodies = append(bodies, body)
+			mutex.Unlock()
+		}))
+		defer server.Close()
+
+		repo := newRepository(t, server.URL)
+		worker := NewWorker(repo, log.NewNopLogger())
+		// The test server is on the loopback, which deliveries are otherwise
+		// never sent to.
+		worker.client.Transport = http.DefaultTransport
+		if err := worker.deliverPending(); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := 1, len(requests); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+
+		req := requests[0]
+		if expected, actual := models.WebhookEventInsert, req.Header.Get(HTTPHeaderEvent); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		timestamp, err := strconv.ParseInt(req.Header.Get(HTTPHeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := Sign("secret", timestamp, bodies[0]), req.Header.Get(HTTPHeaderSignature); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+
+		deliveries, err := repo.SelectDeliveries(models.DeliveryStatusDelivered, repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("retries with backoff until dead", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		repo := newRepository(t, server.URL)
+		worker := NewWorker(repo, log.NewNopLogger(), WithMaxAttempts(2), WithBackoff(time.Hour))
+		worker.client.Transport = http.DefaultTransport
+
+		now := time.Now()
+		worker.now = func() time.Time { return now }
+
+		if err := worker.deliverPending(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The retry isn't due for another hour.
+		deliveries, err := repo.SelectDeliveries(models.DeliveryStatusPending, repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		delivery := deliveries[0]
+		if expected, actual := now.Add(time.Hour), delivery.NextAttemptOn; !expected.Equal(actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		pending, err := repo.ClaimPendingDeliveries(10, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 0, len(pending); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+
+		// Redelivering resets the attempts, so make it the last attempt.
+		if _, err := repo.RedeliverDelivery(delivery.ID, repository.Query{}); err != nil {
+			t.Fatal(err)
+		}
+		worker.maxAttempts = 1
+		if err := worker.deliverPending(); err != nil {
+			t.Fatal(err)
+		}
+
+		dead, err := repo.SelectDeliveries(models.DeliveryStatusDead, repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(dead); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+
+		attempts, err := repo.SelectDeliveryAttempts(delivery.ID, repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 2, len(attempts); expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := http.StatusInternalServerError, attempts[0].StatusCode; expected != actual {
+			t.Errorf("expected: %d, actual: %d", expected, actual)
+		}
+	})
+
+	t.Run("refuses private hosts", func(t *testing.T) {
+		var delivered bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delivered = true
+		}))
+		defer server.Close()
+
+		repo := newRepository(t, server.URL)
+		if err := NewWorker(repo, log.NewNopLogger()).deliverPending(); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := false, delivered; expected != actual {
+			t.Errorf("expected: %t, actual: %t", expected, actual)
+		}
+
+		deliveries, err := repo.SelectDeliveries(models.DeliveryStatusPending, repository.Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := 1, len(deliveries); expected != actual {
+			t.Fatalf("expected: %d, actual: %d", expected, actual)
+		}
+		if expected, actual := true, strings.Contains(deliveries[0].LastError, "not a public host"); expected != actual {
+			t.Errorf("expected: %t, actual: %t, %q", expected, actual, deliveries[0].LastError)
+		}
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		worker := NewWorker(nil, log.NewNopLogger(), WithBackoff(time.Second))
+
+		for attempts, expected := range map[int]time.Duration{
+			1:  time.Second,
+			2:  2 * time.Second,
+			3:  4 * time.Second,
+			30: defaultMaxBackoff,
+		} {
+			if actual := worker.retryAfter(attempts); expected != actual {
+				t.Errorf("attempts %d, expected: %v, actual: %v", attempts, expected, actual)
+			}
+		}
+	})
+}